@@ -22,7 +22,9 @@ var (
 func main() {
 	appConf := config.MustLoadConfig()
 
-	a := app.Must(app.New(*appConf))
+	a := app.Must(app.New(*appConf, func(o *app.Options) {
+		o.BuildMeta = bmeta.New(buildVersion, buildDate, buildCommit)
+	}))
 
 	bmeta.Print(buildVersion, buildDate, buildCommit)
 	a.Logger.Info("Starting server", zap.Any("config", appConf))
@@ -2,20 +2,30 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/fsdevblog/shorturl/internal/bmeta"
+	"github.com/fsdevblog/shorturl/internal/grpcapi"
+	"github.com/fsdevblog/shorturl/internal/metrics"
+	"github.com/fsdevblog/shorturl/internal/oidc"
 	"github.com/fsdevblog/shorturl/internal/services/svccert"
+	"github.com/fsdevblog/shorturl/internal/tokens"
+	"github.com/fsdevblog/shorturl/internal/tracing"
 
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 
 	"github.com/fsdevblog/shorturl/internal/logs"
 
 	"github.com/fsdevblog/shorturl/internal/config"
 	"github.com/fsdevblog/shorturl/internal/controllers"
+	"github.com/fsdevblog/shorturl/internal/controllers/middlewares"
 	"github.com/fsdevblog/shorturl/internal/db"
 	"github.com/fsdevblog/shorturl/internal/services"
 )
@@ -29,15 +39,19 @@ const (
 
 // Options структура опций.
 type Options struct {
-	ReadHeaderTimeout time.Duration // таймаут чтения заголовков, во избежание Slowloris Attack
-	BackupTimeout     time.Duration // таймаут создания бекапа
-	ShutdownTimeout   time.Duration // таймаут graceful shutdown
+	ReadHeaderTimeout time.Duration   // таймаут чтения заголовков, во избежание Slowloris Attack
+	BackupTimeout     time.Duration   // таймаут создания бекапа
+	ShutdownTimeout   time.Duration   // таймаут graceful shutdown
+	BuildMeta         bmeta.BuildMeta // информация о сборке для метрик
 }
 
 // App представляет собой основной объект приложения.
 type App struct {
 	config     config.Config      // Конфигурация приложения
 	dbServices *services.Services // Сервисный слой для работы с БД
+	metrics    *metrics.Metrics   // Метрики приложения
+	tracing    *tracing.Provider  // Провайдер трассировки
+	keyring    *tokens.Keyring    // Ключи подписи JWT токенов посетителей
 	Logger     *zap.Logger        // Логгер приложения
 
 	readHeaderTimeout time.Duration
@@ -60,24 +74,60 @@ func New(config config.Config, opts ...func(*Options)) (*App, error) {
 		return nil, fmt.Errorf("init logger: %s", errLogger.Error())
 	}
 
-	ctx := context.Background()
-	dbServices, servicesErr := initServices(ctx, config)
-
-	if servicesErr != nil {
-		return nil, fmt.Errorf("init services: %w", servicesErr)
+	keyring, keyringErr := visitorKeyring(config)
+	if keyringErr != nil {
+		return nil, fmt.Errorf("init visitor jwt keyring: %w", keyringErr)
+	}
+	if usesDefaultVisitorSecret(config) && len(config.AdminVisitorUUIDs) > 0 {
+		// С секретом по умолчанию любой может подписать токен с UUID администратора.
+		return nil, errors.New("admin visitor uuids require VISITOR_JWT_SECRET or VISITOR_JWT_KEYS_FILE " +
+			"instead of the default secret")
+	}
+	if usesDefaultVisitorSecret(config) {
+		logger.Warn("visitor jwt is signed with the default secret, set VISITOR_JWT_SECRET or VISITOR_JWT_KEYS_FILE")
 	}
 
 	options := &Options{
 		ReadHeaderTimeout: defaultReadHeaderTimeout,
 		BackupTimeout:     defaultBackupTimeout,
 		ShutdownTimeout:   defaultShutdownTimeout,
+		BuildMeta:         bmeta.New("", "", ""),
 	}
 	for _, opt := range opts {
 		opt(options)
 	}
+
+	appMetrics := metrics.New(func(o *metrics.Options) {
+		o.BuildMeta = options.BuildMeta
+	})
+
+	ctx := context.Background()
+	// Трассировку настраиваем до подключения к БД, т.к. трейсер pgx использует глобальный провайдер.
+	tracingProvider, tracingErr := tracing.New(ctx, func(o *tracing.Options) {
+		o.ServiceVersion = options.BuildMeta.Version
+		o.Exporter = tracing.ExporterType(config.TracingExporter)
+		o.FilePath = config.TracingFilePath
+		o.OTLPEndpoint = config.TracingOTLPEndpoint
+		if config.TracingSampleRatio != nil {
+			o.SampleRatio = *config.TracingSampleRatio
+		}
+	})
+	if tracingErr != nil {
+		return nil, fmt.Errorf("init tracing: %w", tracingErr)
+	}
+
+	dbServices, servicesErr := initServices(ctx, config, logger, appMetrics)
+
+	if servicesErr != nil {
+		return nil, fmt.Errorf("init services: %w", servicesErr)
+	}
+
 	app := &App{
 		config:            config,
 		dbServices:        dbServices,
+		metrics:           appMetrics,
+		tracing:           tracingProvider,
+		keyring:           keyring,
 		Logger:            logger,
 		readHeaderTimeout: options.ReadHeaderTimeout,
 		backupTimeout:     options.BackupTimeout,
@@ -113,7 +163,7 @@ func (a *App) restoreBackup() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) //nolint:mnd
 	defer cancel()
 
-	if err := a.dbServices.URLService.RestoreBackup(ctx, a.config.FileStoragePath); err != nil {
+	if err := a.dbServices.BackupService.RestoreBackup(ctx, a.config.FileStoragePath); err != nil {
 		return fmt.Errorf("restore backup from file `%s`: %w", a.config.FileStoragePath, err)
 	}
 	return nil
@@ -121,8 +171,9 @@ func (a *App) restoreBackup() error {
 
 // Run запускает web сервер и обрабатывает сигналы завершения.
 // При получении сигнала SIGINT или SIGTERM выполняет корректное завершение:
-//   - Создает резервную копию данных, если используется in-memory хранилище.
 //   - Завершает работу сервера.
+//   - Дожидается выполнения задач из очереди удаления.
+//   - Создает резервную копию данных, если используется in-memory хранилище.
 //
 // Возвращает:
 //   - error: ошибка работы сервера
@@ -136,18 +187,73 @@ func (a *App) Run() error {
 
 	errChan := make(chan error, 1)
 
-	router := controllers.SetupRouter(controllers.RouterParams{
+	// Доставка вебхуков и очистка ключей идемпотентности и отзывов токенов работают до получения сигнала завершения.
+	go a.dbServices.WebhookService.Run(ctx)
+	go a.dbServices.IdempotencyService.Run(ctx)
+	go a.dbServices.TokenRevocationService.Run(ctx)
+
+	// Очередь удаления останавливаем только после серверов, чтобы принять задачи из завершающихся запросов.
+	deletionCtx, stopDeletion := context.WithCancel(context.Background())
+	defer stopDeletion()
+	deletionDone := make(chan struct{})
+	go func() {
+		defer close(deletionDone)
+		a.dbServices.DeletionService.Run(deletionCtx)
+	}()
+
+	routerParams := controllers.RouterParams{
 		URLService:  a.dbServices.URLService,
 		PingService: a.dbServices.PingService,
+		Events:      a.dbServices.EventsHub,
+		Webhooks:    a.dbServices.WebhookService,
+		Idempotency: a.dbServices.IdempotencyService,
+		Deletions:   a.dbServices.DeletionService,
+		APIKeys:     a.dbServices.APIKeyService,
+		Stats:       a.dbServices.URLService,
+		Admin:       a.dbServices.AdminService,
+		Workspaces:  a.dbServices.WorkspaceService,
+		Revocations: a.dbServices.TokenRevocationService,
+		Metrics:     a.metrics,
 		AppConf:     a.config,
+		Keyring:     a.keyring,
 		Logger:      a.Logger,
-	})
+	}
+	// С провайдером OpenID Connect вход выполняется только через него, вход по паролю не регистрируется.
+	if a.dbServices.OIDCService != nil {
+		routerParams.OIDC = a.dbServices.OIDCService
+	} else {
+		routerParams.Accounts = a.dbServices.UserService
+	}
+	// Без отдельного адреса метрики отдаются основным сервером.
+	var metricsSrv *http.Server
+	if a.config.MetricsAddress == "" {
+		routerParams.MetricsHandler = a.metrics.Handler()
+	} else {
+		metricsSrv = &http.Server{
+			Addr:              a.config.MetricsAddress,
+			Handler:           a.metrics.Handler(),
+			ReadHeaderTimeout: a.readHeaderTimeout,
+		}
+		go func() {
+			if err := metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errChan <- fmt.Errorf("metrics server: %w", err)
+			}
+		}()
+	}
+	router := controllers.SetupRouter(routerParams)
+
+	grpcSrv, grpcErr := a.startGRPCServer(errChan)
+	if grpcErr != nil {
+		return fmt.Errorf("run app: %w", grpcErr)
+	}
 
 	httpSrv := &http.Server{
 		Addr:              a.config.ServerAddress,
 		Handler:           router,
 		ReadHeaderTimeout: a.readHeaderTimeout,
 	}
+	// Закрываем SSE потоки, иначе Shutdown будет ждать их до истечения таймаута.
+	httpSrv.RegisterOnShutdown(a.dbServices.EventsHub.Close)
 
 	go func() {
 		if a.config.EnableHTTPS {
@@ -183,18 +289,33 @@ func (a *App) Run() error {
 		if err := httpSrv.Shutdown(shutdownCtx); err != nil {
 			a.Logger.Error("shutdown error", zap.Error(err))
 		}
+		if metricsSrv != nil {
+			if err := metricsSrv.Shutdown(shutdownCtx); err != nil {
+				a.Logger.Error("metrics server shutdown error", zap.Error(err))
+			}
+		}
+		if grpcSrv != nil {
+			gracefulStopGRPC(shutdownCtx, grpcSrv)
+		}
 		errServer = ctx.Err()
 	case errServer = <-errChan:
 		a.Logger.Error("router error", zap.Error(errServer))
+		if grpcSrv != nil {
+			grpcSrv.Stop()
+		}
 	}
 
+	// Дожидаемся выполнения принятых задач удаления до бекапа.
+	stopDeletion()
+	a.waitDeletionQueue(deletionDone)
+
 	backupCtx, backupCancel := context.WithTimeout(context.Background(), a.backupTimeout)
 	defer backupCancel()
 
 	// Делаем бекап
 	// Из ТЗ не ясно, стоит делать бекап при подключении к БД или нет (такой бекап не имеет никакого смысла)
 	// Лучше трогать не буду, проходят тесты и слава богу.
-	if errBackup := a.dbServices.URLService.Backup(backupCtx, a.config.FileStoragePath); errBackup != nil {
+	if errBackup := a.dbServices.BackupService.Backup(backupCtx, a.config.FileStoragePath); errBackup != nil {
 		a.Logger.Error("Making backup to file error",
 			zap.String("file", a.config.FileStoragePath),
 			zap.Error(errBackup),
@@ -205,20 +326,109 @@ func (a *App) Run() error {
 		)
 	}
 
+	tracingCtx, tracingCancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer tracingCancel()
+	if err := a.tracing.Shutdown(tracingCtx); err != nil {
+		a.Logger.Error("tracing shutdown error", zap.Error(err))
+	}
+
 	return errServer
 }
 
+// waitDeletionQueue дожидается завершения очереди удаления, но не дольше таймаута graceful shutdown.
+//
+// Параметры:
+//   - done: канал, закрываемый по завершении очереди
+func (a *App) waitDeletionQueue(done <-chan struct{}) {
+	select {
+	case <-done:
+		a.Logger.Info("Deletion queue drained")
+	case <-time.After(a.shutdownTimeout):
+		a.Logger.Error("deletion queue drain timeout exceeded, pending jobs are lost")
+	}
+}
+
+// startGRPCServer запускает gRPC сервер, если задан config.Config.GRPCAddress.
+// Ошибки работы сервера отправляются в errChan.
+//
+// Параметры:
+//   - errChan: канал ошибок серверов
+//
+// Возвращает:
+//   - *grpc.Server: запущенный сервер или nil, если gRPC отключен
+//   - error: ошибка открытия адреса
+func (a *App) startGRPCServer(errChan chan<- error) (*grpc.Server, error) {
+	if a.config.GRPCAddress == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	listener, err := net.Listen("tcp", a.config.GRPCAddress)
+	if err != nil {
+		return nil, fmt.Errorf("listen grpc address `%s`: %w", a.config.GRPCAddress, err)
+	}
+
+	baseURL := a.config.BaseURL
+	if baseURL == "" {
+		scheme := "http"
+		if a.config.EnableHTTPS {
+			scheme = "https"
+		}
+		baseURL = scheme + "://" + a.config.ServerAddress
+	}
+
+	grpcSrv := grpcapi.New(a.dbServices.URLService, func(o *grpcapi.Options) {
+		o.BaseURL = baseURL
+		o.Keyring = a.keyring
+		o.Logger = a.Logger
+		o.Bans = a.dbServices.AdminService
+		o.Revocations = a.dbServices.TokenRevocationService
+	})
+	go func() {
+		if serveErr := grpcSrv.Serve(listener); serveErr != nil {
+			errChan <- fmt.Errorf("grpc server: %w", serveErr)
+		}
+	}()
+	return grpcSrv, nil
+}
+
+// gracefulStopGRPC дожидается завершения активных вызовов gRPC сервера,
+// а по истечении ctx принудительно останавливает сервер.
+//
+// Параметры:
+//   - ctx: контекст с таймаутом завершения
+//   - srv: gRPC сервер
+func gracefulStopGRPC(ctx context.Context, srv *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		srv.Stop()
+		<-stopped
+	}
+}
+
 // initServices инициализирует сервисный слой приложения.
 // Определяет тип хранилища (PostgreSQL или in-memory) на основе конфигурации.
 //
 // Параметры:
 //   - ctx: контекст выполнения
 //   - appConf: конфигурация приложения
+//   - logger: логгер приложения
+//   - appMetrics: метрики приложения
 //
 // Возвращает:
 //   - *services.Services: инициализированный сервисный слой
 //   - error: ошибка инициализации
-func initServices(ctx context.Context, appConf config.Config) (*services.Services, error) {
+func initServices(
+	ctx context.Context,
+	appConf config.Config,
+	logger *zap.Logger,
+	appMetrics *metrics.Metrics,
+) (*services.Services, error) {
 	// Нужно определить тип хранилища
 
 	dbConn, connErr := db.NewConnectionFactory(ctx, db.FactoryConfig{
@@ -230,14 +440,98 @@ func initServices(ctx context.Context, appConf config.Config) (*services.Service
 	if connErr != nil {
 		return nil, connErr //nolint:wrapcheck
 	}
+	if err := appMetrics.RegisterStorage(dbConn); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
 
-	dbServices, dbServErr := services.Factory(dbConn, whatIsServiceType(&appConf))
+	dbServices, dbServErr := services.Factory(dbConn, whatIsServiceType(&appConf), func(o *services.FactoryOptions) {
+		o.BaseURL = appConf.BaseURL
+		o.Metrics = appMetrics
+		o.OnWebhookError = func(err error) {
+			logger.Error("webhook delivery error", zap.Error(err))
+		}
+		o.IdempotencyTTL = appConf.IdempotencyTTL
+		o.RevocationTTL = visitorTokenLifetime(appConf)
+		if appConf.OIDCIssuer != "" {
+			o.OIDCClient = oidc.NewClient(oidc.Config{
+				Issuer:       appConf.OIDCIssuer,
+				ClientID:     appConf.OIDCClientID,
+				ClientSecret: appConf.OIDCClientSecret,
+				RedirectURL:  appConf.OIDCRedirectURL,
+				Scopes:       appConf.OIDCScopes,
+			})
+		}
+		o.OnError = func(err error) {
+			logger.Error("background task error", zap.Error(err))
+		}
+	})
 	if dbServErr != nil {
 		return nil, dbServErr //nolint:wrapcheck
 	}
 	return dbServices, nil
 }
 
+// visitorTokenLifetime возвращает время, в течение которого принимается токен посетителя:
+// срок его действия и время продления истекшего токена. Столько же хранится отзыв токена.
+//
+// Параметры:
+//   - appConf: конфигурация приложения
+//
+// Возвращает:
+//   - time.Duration: время жизни токена посетителя
+func visitorTokenLifetime(appConf config.Config) time.Duration {
+	expire, grace := middlewares.VisitorJWTExpireDuration, middlewares.DefaultVisitorSessionGrace
+	if appConf.VisitorSessionExpire > 0 {
+		expire = appConf.VisitorSessionExpire
+	}
+	if appConf.VisitorSessionGrace > 0 {
+		grace = appConf.VisitorSessionGrace
+	}
+	return expire + grace
+}
+
+// visitorKeyring создает набор ключей подписи JWT токенов посетителей.
+// Без ключей VisitorJWTKeys используется единственный секрет VisitorJWTSecret.
+// Ключи с private_key_file подписывают токены EdDSA или RS256, их открытая часть публикуется в JWKS.
+// Для перехода на ротацию прежний секрет указывается ключом с ID "default" и valid_until:
+// токены без kid проверяются именно им до наступления valid_until.
+//
+// Параметры:
+//   - appConf: конфигурация приложения
+//
+// Возвращает:
+//   - *tokens.Keyring: набор ключей
+//   - error: ошибка в описании ключей
+func visitorKeyring(appConf config.Config) (*tokens.Keyring, error) {
+	if len(appConf.VisitorJWTKeys) == 0 {
+		return tokens.NewStaticKeyring([]byte(appConf.VisitorJWTSecret)), nil
+	}
+
+	keys := make([]tokens.Key, 0, len(appConf.VisitorJWTKeys))
+	for _, k := range appConf.VisitorJWTKeys {
+		key := tokens.Key{ID: k.ID, Secret: []byte(k.Secret), ValidUntil: k.ValidUntil}
+		if k.PrivateKeyFile != "" {
+			privateKey, err := tokens.LoadPrivateKeyPEM(k.PrivateKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("visitor jwt key %q: %w", k.ID, err)
+			}
+			key.PrivateKey = privateKey
+		}
+		keys = append(keys, key)
+	}
+
+	activeID := appConf.VisitorJWTActiveKey
+	if activeID == "" {
+		activeID = keys[0].ID
+	}
+	return tokens.NewKeyring(activeID, keys...)
+}
+
+// usesDefaultVisitorSecret сообщает, подписываются ли токены посетителей секретом по умолчанию.
+func usesDefaultVisitorSecret(appConf config.Config) bool {
+	return len(appConf.VisitorJWTKeys) == 0 && appConf.VisitorJWTSecret == config.DefaultVisitorJWTSecret
+}
+
 // whatIsDBStorageType определяет тип хранилища на основе конфигурации.
 //
 // Параметры:
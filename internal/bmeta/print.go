@@ -4,28 +4,45 @@ import "fmt"
 
 const defaultBuildMeta = "N/A" // Значение по умолчанию
 
-// Print Распечатывает версию, дату и комит сборки.
-func Print(version, date, commit string) {
-	meta := struct {
-		version string
-		date    string
-		commit  string
-	}{
-		version: defaultBuildMeta,
-		date:    defaultBuildMeta,
-		commit:  defaultBuildMeta,
+// BuildMeta информация о сборке.
+type BuildMeta struct {
+	Version string // Версия
+	Date    string // Дата сборки
+	Commit  string // Комит
+}
+
+// New создает информацию о сборке, подставляя значение по умолчанию вместо пустых полей.
+//
+// Параметры:
+//   - version: версия сборки
+//   - date: дата сборки
+//   - commit: комит сборки
+//
+// Возвращает:
+//   - BuildMeta: информация о сборке
+func New(version, date, commit string) BuildMeta {
+	meta := BuildMeta{
+		Version: defaultBuildMeta,
+		Date:    defaultBuildMeta,
+		Commit:  defaultBuildMeta,
 	}
 	if version != "" {
-		meta.version = version
+		meta.Version = version
 	}
 	if date != "" {
-		meta.date = date
+		meta.Date = date
 	}
 	if commit != "" {
-		meta.commit = commit
+		meta.Commit = commit
 	}
+	return meta
+}
+
+// Print Распечатывает версию, дату и комит сборки.
+func Print(version, date, commit string) {
+	meta := New(version, date, commit)
 
-	fmt.Printf("Build version: %s\n", meta.version) //nolint:forbidigo
-	fmt.Printf("Build date: %s\n", meta.date)       //nolint:forbidigo
-	fmt.Printf("Build commit: %s\n", meta.commit)   //nolint:forbidigo
+	fmt.Printf("Build version: %s\n", meta.Version) //nolint:forbidigo
+	fmt.Printf("Build date: %s\n", meta.Date)       //nolint:forbidigo
+	fmt.Printf("Build commit: %s\n", meta.Commit)   //nolint:forbidigo
 }
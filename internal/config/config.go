@@ -2,14 +2,35 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"net/http"
+	"net/netip"
 	"net/url"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v11"
+	"github.com/google/uuid"
 )
 
+// DefaultVisitorJWTSecret секрет JWT токенов посетителей по умолчанию. Подходит только для разработки.
+const DefaultVisitorJWTSecret = "super_secret_key"
+
+// JWTKey ключ подписи токенов посетителей.
+type JWTKey struct {
+	ID         string `json:"id"`          // Идентификатор ключа (заголовок kid токена)
+	Secret     string `json:"secret"`      // Секрет HMAC
+	SecretFile string `json:"secret_file"` // Файл с секретом, если Secret не задан
+	// Файл с закрытым ключом ed25519 (EdDSA) или RSA (RS256) в формате PEM вместо секрета HMAC.
+	PrivateKeyFile string `json:"private_key_file"`
+	// Момент, после которого выведенный из оборота ключ больше не принимается. Не задается для активного ключа.
+	ValidUntil time.Time `json:"valid_until"`
+}
+
 // Config содержит параметры конфигурации приложения.
 type Config struct {
 	// Путь для бекапа (актуально мемори хранилища).
@@ -24,8 +45,84 @@ type Config struct {
 	BaseURL string `env:"BASE_URL" json:"base_url"`
 	// DSN базы данных
 	DatabaseDSN string `env:"DATABASE_DSN" json:"database_dsn"`
-	// Секретный ключ для JWT токена посетителей.
+	// Секретный ключ для JWT токена посетителей. Используется, если не заданы ключи VisitorJWTKeys.
 	VisitorJWTSecret string `env:"VISITOR_JWT_SECRET" envDefault:"super_secret_key" json:"-"`
+	// Ключи подписи токенов посетителей для ротации. Токены без kid проверяются ключом с ID "default".
+	VisitorJWTKeys []JWTKey `env:"-" json:"visitor_jwt_keys"`
+	// Файл с ключами подписи токенов посетителей (JSON массив JWTKey). Заменяет VisitorJWTKeys.
+	VisitorJWTKeysFile string `env:"VISITOR_JWT_KEYS_FILE" json:"visitor_jwt_keys_file"`
+	// Идентификатор ключа для подписи новых токенов. По умолчанию первый ключ VisitorJWTKeys.
+	VisitorJWTActiveKey string `env:"VISITOR_JWT_ACTIVE_KEY" json:"visitor_jwt_active_key"`
+	// Доверенная подсеть в CIDR нотации для внутренних эндпоинтов. Пустое значение запрещает доступ.
+	TrustedSubnet string `env:"TRUSTED_SUBNET" json:"trusted_subnet"`
+	// UUID посетителей с ролью администратора. Роль попадает в токен посетителя и открывает /api/admin.
+	AdminVisitorUUIDs []string `env:"ADMIN_VISITOR_UUIDS" envSeparator:"," json:"admin_visitor_uuids"`
+	// Отдельный адрес для /metrics. Если не задан, метрики отдаются основным сервером.
+	MetricsAddress string `env:"METRICS_ADDRESS" json:"metrics_address"`
+	// Адрес gRPC сервера. Если не задан, gRPC API не запускается.
+	GRPCAddress string `env:"GRPC_ADDRESS" json:"grpc_address"`
+	// Экспортер трассировки: stdout, file, otlp. Пустое значение отключает экспорт спанов.
+	TracingExporter string `env:"TRACING_EXPORTER" json:"tracing_exporter"`
+	// Файл для экспортера file.
+	TracingFilePath string `env:"TRACING_FILE" json:"tracing_file"`
+	// Адрес OTLP/HTTP коллектора для экспортера otlp (например, http://localhost:4318).
+	TracingOTLPEndpoint string `env:"TRACING_OTLP_ENDPOINT" json:"tracing_otlp_endpoint"`
+	// Доля сэмплируемых трасс от 0 до 1. Указатель отличает явный 0 от незаданного значения (по умолчанию 1).
+	TracingSampleRatio *float64 `env:"TRACING_SAMPLE_RATIO" json:"tracing_sample_ratio"`
+	// Время хранения ответов на запросы с заголовком Idempotency-Key. 0 - значение по умолчанию (24 часа).
+	IdempotencyTTL time.Duration `env:"IDEMPOTENCY_TTL" json:"idempotency_ttl"`
+	// Источники, которым разрешены запросы из браузера (CORS), например https://*.example.com.
+	// Пустой список отключает CORS.
+	CORSAllowedOrigins []string `env:"CORS_ALLOWED_ORIGINS" envSeparator:"," json:"cors_allowed_origins"`
+	// Методы, разрешенные для CORS запросов. Пустой список - значение по умолчанию.
+	CORSAllowedMethods []string `env:"CORS_ALLOWED_METHODS" envSeparator:"," json:"cors_allowed_methods"`
+	// Заголовки, разрешенные для CORS запросов. Пустой список - значение по умолчанию.
+	CORSAllowedHeaders []string `env:"CORS_ALLOWED_HEADERS" envSeparator:"," json:"cors_allowed_headers"`
+	// Разрешить CORS запросы с cookie посетителя. Несовместимо с источником "*".
+	CORSAllowCredentials bool `env:"CORS_ALLOW_CREDENTIALS" json:"cors_allow_credentials"`
+	// Время кеширования браузером ответа на preflight запрос.
+	CORSMaxAge time.Duration `env:"CORS_MAX_AGE" json:"cors_max_age"`
+	// Срок действия токена посетителя. Активный посетитель получает продленный токен с тем же UUID.
+	// 0 - значение по умолчанию (24 часа).
+	VisitorSessionExpire time.Duration `env:"VISITOR_SESSION_EXPIRE" json:"visitor_session_expire"`
+	// Время после истечения токена, в течение которого он продлевается, а не заменяется новым посетителем.
+	// 0 - значение по умолчанию (7 суток).
+	VisitorSessionGrace time.Duration `env:"VISITOR_SESSION_GRACE" json:"visitor_session_grace"`
+	// Домен cookie посетителя, например example.com для всех поддоменов. Пустое значение - текущий хост.
+	VisitorCookieDomain string `env:"VISITOR_COOKIE_DOMAIN" json:"visitor_cookie_domain"`
+	// Отправлять cookie посетителя только по HTTPS. Включается автоматически вместе с EnableHTTPS.
+	VisitorCookieSecure bool `env:"VISITOR_COOKIE_SECURE" json:"visitor_cookie_secure"`
+	// Атрибут SameSite cookie посетителя: lax, strict или none. Пустое значение - не указывать.
+	VisitorCookieSameSite string `env:"VISITOR_COOKIE_SAMESITE" json:"visitor_cookie_samesite"`
+
+	// Провайдер OpenID Connect (issuer), например https://sso.example.com/realms/main.
+	// Если задан, вход выполняется только через провайдера: регистрация и вход по паролю отключаются.
+	OIDCIssuer string `env:"OIDC_ISSUER" json:"oidc_issuer"`
+	// Идентификатор клиента, зарегистрированного у провайдера.
+	OIDCClientID string `env:"OIDC_CLIENT_ID" json:"oidc_client_id"`
+	// Секрет клиента. Пустое значение - публичный клиент, защищенный только PKCE.
+	OIDCClientSecret string `env:"OIDC_CLIENT_SECRET" json:"-"`
+	// Адрес возврата, зарегистрированный у провайдера, например https://short.example.com/api/oidc/callback.
+	OIDCRedirectURL string `env:"OIDC_REDIRECT_URL" json:"oidc_redirect_url"`
+	// Области доступа. Пустой список - openid, email, profile.
+	OIDCScopes []string `env:"OIDC_SCOPES" envSeparator:"," json:"oidc_scopes"`
+	// Адрес, куда перенаправляется пользователь после входа. Пустое значение - "/".
+	OIDCPostLoginURL string `env:"OIDC_POST_LOGIN_URL" json:"oidc_post_login_url"`
+}
+
+// VisitorCookieSameSiteMode возвращает атрибут SameSite cookie посетителя.
+// Для пустого или неизвестного значения возвращает 0 (атрибут не указывается).
+func (c Config) VisitorCookieSameSiteMode() http.SameSite {
+	switch strings.ToLower(c.VisitorCookieSameSite) {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return 0
+	}
 }
 
 // readConfigFile читает и парсит файл конфигурации в структуру Config.
@@ -58,6 +155,33 @@ func parseConfigFile(cfgBytes []byte) (*Config, error) {
 //   - BASE_URL: базовый URL для сокращенных ссылок
 //   - DATABASE_DSN: строка подключения к БД
 //   - VISITOR_JWT_SECRET: секрет для JWT (по умолчанию "super_secret_key")
+//   - VISITOR_JWT_KEYS_FILE: файл с ключами подписи JWT для ротации (JSON массив)
+//   - VISITOR_JWT_ACTIVE_KEY: идентификатор ключа для подписи новых JWT
+//   - TRUSTED_SUBNET: доверенная подсеть (CIDR)
+//   - ADMIN_VISITOR_UUIDS: UUID посетителей с ролью администратора через запятую
+//   - METRICS_ADDRESS: отдельный адрес для /metrics
+//   - GRPC_ADDRESS: адрес gRPC сервера
+//   - TRACING_EXPORTER: экспортер трассировки (stdout, file, otlp)
+//   - TRACING_FILE: файл для экспортера file
+//   - TRACING_OTLP_ENDPOINT: адрес OTLP/HTTP коллектора
+//   - TRACING_SAMPLE_RATIO: доля сэмплируемых трасс (по умолчанию 1, 0 отключает сэмплирование)
+//   - IDEMPOTENCY_TTL: время хранения ответов на запросы с Idempotency-Key (например, 24h)
+//   - CORS_ALLOWED_ORIGINS: разрешенные источники CORS через запятую
+//   - CORS_ALLOWED_METHODS: разрешенные методы CORS через запятую
+//   - CORS_ALLOWED_HEADERS: разрешенные заголовки CORS через запятую
+//   - CORS_ALLOW_CREDENTIALS: разрешить CORS запросы с cookie (true/false)
+//   - CORS_MAX_AGE: время кеширования ответа на preflight запрос (например, 10m)
+//   - VISITOR_SESSION_EXPIRE: срок действия токена посетителя (по умолчанию 24h)
+//   - VISITOR_SESSION_GRACE: время продления истекшего токена посетителя (по умолчанию 168h)
+//   - VISITOR_COOKIE_DOMAIN: домен cookie посетителя
+//   - VISITOR_COOKIE_SECURE: cookie посетителя только по HTTPS (true/false, включается с ENABLE_HTTPS)
+//   - VISITOR_COOKIE_SAMESITE: атрибут SameSite cookie посетителя (lax, strict, none)
+//   - OIDC_ISSUER: провайдер OpenID Connect; если задан, вход по паролю отключается
+//   - OIDC_CLIENT_ID: идентификатор клиента у провайдера
+//   - OIDC_CLIENT_SECRET: секрет клиента
+//   - OIDC_REDIRECT_URL: адрес возврата после входа у провайдера
+//   - OIDC_SCOPES: области доступа через запятую
+//   - OIDC_POST_LOGIN_URL: адрес перехода после входа
 //
 // Поддерживаемые флаги:
 //   - -f: путь к файлу хранилища (по умолчанию "backup.json")
@@ -66,6 +190,9 @@ func parseConfigFile(cfgBytes []byte) (*Config, error) {
 //   - -a: адрес сервера (по умолчанию "localhost:8080")
 //   - -d: строка подключения к БД
 //   - -b: базовый URL для сокращенных ссылок
+//   - -t: доверенная подсеть (CIDR)
+//   - -m: отдельный адрес для /metrics
+//   - -g: адрес gRPC сервера
 //
 // Возвращает:
 //   - *Config: загруженная конфигурация
@@ -90,6 +217,55 @@ func LoadConfig() (*Config, error) {
 	}
 
 	conf := mergeConfigs(&envConfig, &flagsConfig, fileConfig)
+	applyDefaults(conf)
+
+	if ratio := *conf.TracingSampleRatio; ratio < 0 || ratio > 1 {
+		return nil, fmt.Errorf("load config: tracing sample ratio must be in [0, 1], got %v", ratio)
+	}
+
+	if conf.IdempotencyTTL < 0 {
+		return nil, fmt.Errorf("load config: idempotency ttl must not be negative, got %v", conf.IdempotencyTTL)
+	}
+
+	if conf.CORSMaxAge < 0 {
+		return nil, fmt.Errorf("load config: cors max age must not be negative, got %v", conf.CORSMaxAge)
+	}
+
+	if conf.CORSAllowCredentials && slices.Contains(conf.CORSAllowedOrigins, "*") {
+		return nil, errors.New("load config: cors credentials are not allowed with origin \"*\"")
+	}
+
+	if conf.VisitorSessionExpire < 0 || conf.VisitorSessionGrace < 0 {
+		return nil, fmt.Errorf("load config: visitor session expire and grace must not be negative, got %v and %v",
+			conf.VisitorSessionExpire, conf.VisitorSessionGrace)
+	}
+
+	if err = validateSameSite(conf); err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+
+	if err = loadJWTKeys(conf); err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+
+	if err = validateOIDC(conf); err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+
+	// UUID приводятся к каноническому виду, в котором они выдаются посетителям.
+	for i, visitorUUID := range conf.AdminVisitorUUIDs {
+		u, parseErr := uuid.Parse(visitorUUID)
+		if parseErr != nil {
+			return nil, fmt.Errorf("load config: parse admin visitor uuid %q: %w", visitorUUID, parseErr)
+		}
+		conf.AdminVisitorUUIDs[i] = u.String()
+	}
+
+	if conf.TrustedSubnet != "" {
+		if _, parseErr := netip.ParsePrefix(conf.TrustedSubnet); parseErr != nil {
+			return nil, fmt.Errorf("load config: parse trusted subnet: %w", parseErr)
+		}
+	}
 
 	return conf, nil
 }
@@ -107,7 +283,147 @@ func mergeConfigs(fgc, envc, flc *Config) *Config {
 		FileStoragePath:  firstNonEmpty(fgc.FileStoragePath, envc.FileStoragePath, flc.FileStoragePath),
 		EnableHTTPS:      firstNonEmpty(fgc.EnableHTTPS, envc.EnableHTTPS, flc.EnableHTTPS),
 		VisitorJWTSecret: firstNonEmpty(fgc.VisitorJWTSecret, envc.VisitorJWTSecret, flc.VisitorJWTSecret),
+		VisitorJWTKeys:   firstNonEmptySlice(fgc.VisitorJWTKeys, envc.VisitorJWTKeys, flc.VisitorJWTKeys),
+		VisitorJWTKeysFile: firstNonEmpty(
+			fgc.VisitorJWTKeysFile, envc.VisitorJWTKeysFile, flc.VisitorJWTKeysFile,
+		),
+		VisitorJWTActiveKey: firstNonEmpty(
+			fgc.VisitorJWTActiveKey, envc.VisitorJWTActiveKey, flc.VisitorJWTActiveKey,
+		),
+		OIDCIssuer:       firstNonEmpty(fgc.OIDCIssuer, envc.OIDCIssuer, flc.OIDCIssuer),
+		OIDCClientID:     firstNonEmpty(fgc.OIDCClientID, envc.OIDCClientID, flc.OIDCClientID),
+		OIDCClientSecret: firstNonEmpty(fgc.OIDCClientSecret, envc.OIDCClientSecret, flc.OIDCClientSecret),
+		OIDCRedirectURL:  firstNonEmpty(fgc.OIDCRedirectURL, envc.OIDCRedirectURL, flc.OIDCRedirectURL),
+		OIDCScopes:       firstNonEmptySlice(fgc.OIDCScopes, envc.OIDCScopes, flc.OIDCScopes),
+		OIDCPostLoginURL: firstNonEmpty(fgc.OIDCPostLoginURL, envc.OIDCPostLoginURL, flc.OIDCPostLoginURL),
+		TrustedSubnet:    firstNonEmpty(fgc.TrustedSubnet, envc.TrustedSubnet, flc.TrustedSubnet),
+		AdminVisitorUUIDs: firstNonEmptySlice(
+			fgc.AdminVisitorUUIDs, envc.AdminVisitorUUIDs, flc.AdminVisitorUUIDs,
+		),
+		MetricsAddress:  firstNonEmpty(fgc.MetricsAddress, envc.MetricsAddress, flc.MetricsAddress),
+		GRPCAddress:     firstNonEmpty(fgc.GRPCAddress, envc.GRPCAddress, flc.GRPCAddress),
+		TracingExporter: firstNonEmpty(fgc.TracingExporter, envc.TracingExporter, flc.TracingExporter),
+		TracingFilePath: firstNonEmpty(fgc.TracingFilePath, envc.TracingFilePath, flc.TracingFilePath),
+		TracingOTLPEndpoint: firstNonEmpty(
+			fgc.TracingOTLPEndpoint, envc.TracingOTLPEndpoint, flc.TracingOTLPEndpoint,
+		),
+		TracingSampleRatio: firstNonEmpty(fgc.TracingSampleRatio, envc.TracingSampleRatio, flc.TracingSampleRatio),
+		IdempotencyTTL:     firstNonEmpty(fgc.IdempotencyTTL, envc.IdempotencyTTL, flc.IdempotencyTTL),
+		CORSAllowedOrigins: firstNonEmptySlice(
+			fgc.CORSAllowedOrigins, envc.CORSAllowedOrigins, flc.CORSAllowedOrigins,
+		),
+		CORSAllowedMethods: firstNonEmptySlice(
+			fgc.CORSAllowedMethods, envc.CORSAllowedMethods, flc.CORSAllowedMethods,
+		),
+		CORSAllowedHeaders: firstNonEmptySlice(
+			fgc.CORSAllowedHeaders, envc.CORSAllowedHeaders, flc.CORSAllowedHeaders,
+		),
+		CORSAllowCredentials: firstNonEmpty(
+			fgc.CORSAllowCredentials, envc.CORSAllowCredentials, flc.CORSAllowCredentials,
+		),
+		CORSMaxAge: firstNonEmpty(fgc.CORSMaxAge, envc.CORSMaxAge, flc.CORSMaxAge),
+		VisitorSessionExpire: firstNonEmpty(
+			fgc.VisitorSessionExpire, envc.VisitorSessionExpire, flc.VisitorSessionExpire,
+		),
+		VisitorSessionGrace: firstNonEmpty(
+			fgc.VisitorSessionGrace, envc.VisitorSessionGrace, flc.VisitorSessionGrace,
+		),
+		VisitorCookieDomain: firstNonEmpty(
+			fgc.VisitorCookieDomain, envc.VisitorCookieDomain, flc.VisitorCookieDomain,
+		),
+		VisitorCookieSecure: firstNonEmpty(
+			fgc.VisitorCookieSecure, envc.VisitorCookieSecure, flc.VisitorCookieSecure,
+		),
+		VisitorCookieSameSite: firstNonEmpty(
+			fgc.VisitorCookieSameSite, envc.VisitorCookieSameSite, flc.VisitorCookieSameSite,
+		),
+	}
+}
+
+// applyDefaults задает значения по умолчанию параметрам, не заданным ни в одном источнике.
+// Применяется после mergeConfigs, чтобы значение по умолчанию не перекрывало файл конфигурации.
+func applyDefaults(conf *Config) {
+	if conf.TracingSampleRatio == nil {
+		ratio := 1.0
+		conf.TracingSampleRatio = &ratio
+	}
+}
+
+// loadJWTKeys читает ключи подписи токенов посетителей из VisitorJWTKeysFile
+// и секреты ключей из их SecretFile.
+func loadJWTKeys(conf *Config) error {
+	if conf.VisitorJWTKeysFile != "" {
+		b, err := os.ReadFile(conf.VisitorJWTKeysFile)
+		if err != nil {
+			return fmt.Errorf("read visitor jwt keys file: %w", err)
+		}
+		var keys []JWTKey
+		if err = json.Unmarshal(b, &keys); err != nil {
+			return fmt.Errorf("unmarshal visitor jwt keys file: %w", err)
+		}
+		conf.VisitorJWTKeys = keys
+	}
+
+	for i, k := range conf.VisitorJWTKeys {
+		if k.Secret != "" || k.SecretFile == "" {
+			continue
+		}
+		b, err := os.ReadFile(k.SecretFile)
+		if err != nil {
+			return fmt.Errorf("read secret of visitor jwt key %q: %w", k.ID, err)
+		}
+		conf.VisitorJWTKeys[i].Secret = strings.TrimSpace(string(b))
+	}
+
+	if len(conf.VisitorJWTKeys) > 0 && conf.VisitorJWTActiveKey == "" {
+		conf.VisitorJWTActiveKey = conf.VisitorJWTKeys[0].ID
+	}
+	return nil
+}
+
+// validateOIDC проверяет, что для провайдера OpenID Connect заданы клиент и адрес возврата.
+func validateOIDC(conf *Config) error {
+	if conf.OIDCIssuer == "" {
+		return nil
+	}
+	issuer, err := url.Parse(conf.OIDCIssuer)
+	if err != nil || issuer.Host == "" || (issuer.Scheme != "https" && issuer.Scheme != "http") {
+		return fmt.Errorf("oidc issuer must be an absolute url, got %q", conf.OIDCIssuer)
+	}
+	if conf.OIDCClientID == "" {
+		return errors.New("oidc client id is required with oidc issuer")
+	}
+	redirect, err := url.Parse(conf.OIDCRedirectURL)
+	if err != nil || redirect.Host == "" {
+		return fmt.Errorf("oidc redirect url must be an absolute url, got %q", conf.OIDCRedirectURL)
+	}
+	return nil
+}
+
+// validateSameSite проверяет атрибут SameSite cookie посетителя.
+// Браузеры отклоняют cookie с SameSite=None без Secure, поэтому такая комбинация считается ошибкой.
+func validateSameSite(conf *Config) error {
+	switch strings.ToLower(conf.VisitorCookieSameSite) {
+	case "", "lax", "strict":
+		return nil
+	case "none":
+		if !conf.VisitorCookieSecure && !conf.EnableHTTPS {
+			return errors.New("visitor cookie samesite none requires secure cookie or https")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown visitor cookie samesite %q, expected lax, strict or none", conf.VisitorCookieSameSite)
+	}
+}
+
+// firstNonEmptySlice возвращает первый непустой слайс из списка или nil.
+func firstNonEmptySlice[T any](values ...[]T) []T {
+	for _, v := range values {
+		if len(v) > 0 {
+			return v
+		}
 	}
+	return nil
 }
 
 // firstNonEmpty возвращает первое непустое значение из списка или значение типа T по умолчанию.
@@ -144,6 +460,9 @@ func MustLoadConfig() *Config {
 //   - -f: путь к файлу хранилища (по умолчанию "backup.json")
 //   - -d: строка подключения к БД
 //   - -b: базовый URL для сокращенных ссылок (scheme://host)
+//   - -t: доверенная подсеть (CIDR)
+//   - -m: отдельный адрес для /metrics
+//   - -g: адрес gRPC сервера
 //
 // Параметры:
 //   - flagsConfig: указатель на структуру для сохранения значений флагов
@@ -153,6 +472,9 @@ func loadsFlags(flagsConfig *Config) {
 	flag.StringVar(&flagsConfig.ConfigJSON, "c", "config.json", "Имя файла конфигурации")
 	flag.StringVar(&flagsConfig.FileStoragePath, "f", "backup.json", "Путь до файла бекапа")
 	flag.StringVar(&flagsConfig.DatabaseDSN, "d", "", "DSN подключения к СУБД")
+	flag.StringVar(&flagsConfig.TrustedSubnet, "t", "", "Доверенная подсеть (CIDR) для внутренних эндпоинтов")
+	flag.StringVar(&flagsConfig.MetricsAddress, "m", "", "Отдельный адрес для /metrics")
+	flag.StringVar(&flagsConfig.GRPCAddress, "g", "", "Адрес gRPC сервера")
 
 	bDesc := "Базовый адрес результирующего сокращенного URL (по умолчанию Scheme://Host запущенного сервера)"
 	flag.Func("b", bDesc, func(rawURL string) error {
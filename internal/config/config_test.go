@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeConfigs_TracingSampleRatio(t *testing.T) {
+	ratio := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name string
+		file string
+		env  *float64
+		want float64
+	}{
+		{name: "default", file: `{}`, want: 1},
+		{name: "file only", file: `{"tracing_sample_ratio":0.25}`, want: 0.25},
+		{name: "explicit zero in file", file: `{"tracing_sample_ratio":0}`, want: 0},
+		{name: "env overrides file", file: `{"tracing_sample_ratio":0.25}`, env: ratio(0.5), want: 0.5},
+		{name: "explicit zero in env", file: `{"tracing_sample_ratio":0.25}`, env: ratio(0), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileConfig, err := parseConfigFile([]byte(tt.file))
+			require.NoError(t, err)
+
+			conf := mergeConfigs(&Config{TracingSampleRatio: tt.env}, &Config{}, fileConfig)
+			applyDefaults(conf)
+
+			require.NotNil(t, conf.TracingSampleRatio)
+			assert.InDelta(t, tt.want, *conf.TracingSampleRatio, 0)
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/middlewares"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/fsdevblog/shorturl/internal/tokens"
+	"github.com/gin-gonic/gin"
+)
+
+// revocationRetryAfter значение заголовка Retry-After (в секундах) при недоступности проверки отзыва токенов.
+const revocationRetryAfter = "5"
+
+// AccountsController обрабатывает регистрацию, вход и выход пользователей.
+// После регистрации или входа посетитель получает cookie с UUID аккаунта.
+type AccountsController struct {
+	accountService AccountManager
+	keyring        *tokens.Keyring
+	revocations    TokenRevoker
+	cookieOpts     []func(*middlewares.VisitorCookieOptions)
+}
+
+// NewAccountsController создает новый экземпляр AccountsController.
+//
+// Параметры:
+//   - accountService: сервис пользователей
+//   - keyring: набор ключей подписи JWT токенов посетителя
+//   - revocations: сервис отзыва токенов (если nil, выход только удаляет cookie)
+//   - cookieOpts: опции cookie посетителя, те же, что у VisitorCookieMiddleware
+//
+// Возвращает:
+//   - *AccountsController: новый экземпляр контроллера
+func NewAccountsController(
+	accountService AccountManager,
+	keyring *tokens.Keyring,
+	revocations TokenRevoker,
+	cookieOpts ...func(*middlewares.VisitorCookieOptions),
+) *AccountsController {
+	return &AccountsController{
+		accountService: accountService,
+		keyring:        keyring,
+		revocations:    revocations,
+		cookieOpts:     cookieOpts,
+	}
+}
+
+// CredentialsParams учетные данные пользователя.
+type CredentialsParams struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// AccountResponse структура ответа с данными аккаунта.
+type AccountResponse struct {
+	ID         string    `json:"id"`
+	Email      string    `json:"email"`
+	CreatedAt  time.Time `json:"created_at"`
+	MergedURLs int       `json:"merged_urls"` // Количество ссылок анонимного посетителя, переданных аккаунту
+}
+
+// Register создает аккаунт. Ссылки текущего анонимного посетителя остаются за аккаунтом.
+//
+// Коды ответа:
+//   - 201: аккаунт создан
+//   - 400: некорректный запрос
+//   - 403: посетитель не определен
+//   - 409: адрес уже зарегистрирован
+//   - 422: некорректный адрес или пароль
+//   - 500: внутренняя ошибка сервера
+func (a *AccountsController) Register(c *gin.Context) {
+	visitorUUID, params, ok := a.bindCredentials(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	user, err := a.accountService.Register(ctx, visitorUUID, params.Email, params.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidArgument):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrEmailTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": "email already registered"})
+		default:
+			_ = c.Error(fmt.Errorf("register: %w", err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+		}
+		return
+	}
+	if !a.signIn(c, user) {
+		return
+	}
+	c.JSON(http.StatusCreated, accountResponse(user, 0))
+}
+
+// Login выполняет вход в аккаунт и передает ему ссылки текущего анонимного посетителя.
+//
+// Коды ответа:
+//   - 200: вход выполнен
+//   - 400: некорректный запрос
+//   - 401: неверный адрес или пароль
+//   - 403: посетитель не определен
+//   - 500: внутренняя ошибка сервера
+func (a *AccountsController) Login(c *gin.Context) {
+	visitorUUID, params, ok := a.bindCredentials(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	res, err := a.accountService.Login(ctx, visitorUUID, params.Email, params.Password)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid email or password"})
+			return
+		}
+		_ = c.Error(fmt.Errorf("login: %w", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+		return
+	}
+	if !a.signIn(c, res.User) {
+		return
+	}
+	c.JSON(http.StatusOK, accountResponse(res.User, res.MergedURLs))
+}
+
+// Logout отзывает сессию предъявленного токена (если задан сервис отзыва) и удаляет cookie.
+// Вместе с сессией отзываются все токены, выпущенные при ее продлении; у токенов без
+// идентификатора сессии отзывается только сам токен. Сессии на других устройствах
+// не отзываются - для этого предназначен RevokeAll. Следующий запрос получит нового анонимного посетителя.
+//
+// Коды ответа:
+//   - 204: выход выполнен
+//   - 500: внутренняя ошибка сервера
+//   - 503: отзыв токена не удалось проверить, см. requireVerifiedToken
+func (a *AccountsController) Logout(c *gin.Context) {
+	if !requireVerifiedToken(c) {
+		return
+	}
+	if jti := c.GetString(middlewares.VisitorTokenIDKey); a.revocations != nil && jti != "" {
+		visitorUUID, _ := visitorUUIDFromContext(c)
+		revokeID := jti
+		if sessionID := c.GetString(middlewares.VisitorSessionIDKey); sessionID != "" {
+			revokeID = sessionID
+		}
+
+		ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+		defer cancel()
+
+		if err := a.revocations.Revoke(ctx, visitorUUID, revokeID); err != nil {
+			_ = c.Error(fmt.Errorf("logout: %w", err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+			return
+		}
+	}
+	middlewares.ClearVisitorCookie(c, a.cookieOpts...)
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeAll отзывает все токены посетителя, в том числе выданные на других устройствах, и удаляет cookie.
+//
+// Коды ответа:
+//   - 204: все сессии завершены
+//   - 403: посетитель не определен
+//   - 500: внутренняя ошибка сервера
+//   - 503: отзыв токена не удалось проверить, см. requireVerifiedToken
+func (a *AccountsController) RevokeAll(c *gin.Context) {
+	if !requireVerifiedToken(c) {
+		return
+	}
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	if err := a.revocations.RevokeAll(ctx, visitorUUID); err != nil {
+		_ = c.Error(fmt.Errorf("revoke all sessions: %w", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+		return
+	}
+	middlewares.ClearVisitorCookie(c, a.cookieOpts...)
+	c.Status(http.StatusNoContent)
+}
+
+// requireVerifiedToken отклоняет запрос, если отзыв токена посетителя не удалось проверить
+// (см. middlewares.VisitorUnverifiedKey). Такой токен может оказаться отозванным, поэтому
+// управлять сессиями он не должен. При отказе отправляет ответ и возвращает false.
+func requireVerifiedToken(c *gin.Context) bool {
+	if !c.GetBool(middlewares.VisitorUnverifiedKey) {
+		return true
+	}
+	c.Header("Retry-After", revocationRetryAfter)
+	c.JSON(http.StatusServiceUnavailable, gin.H{"error": "token revocation check is unavailable, retry later"})
+	return false
+}
+
+// bindCredentials читает учетные данные из тела запроса. При ошибке отправляет ответ и возвращает false.
+func (a *AccountsController) bindCredentials(c *gin.Context) (string, CredentialsParams, bool) {
+	var params CredentialsParams
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusForbidden)
+		return "", params, false
+	}
+	if bindErr := c.ShouldBindJSON(&params); bindErr != nil {
+		_ = c.Error(fmt.Errorf("bind params: %w", bindErr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request. Only json is supported"})
+		return "", params, false
+	}
+	return visitorUUID, params, true
+}
+
+// signIn выдает cookie с UUID аккаунта. При ошибке отправляет ответ и возвращает false.
+func (a *AccountsController) signIn(c *gin.Context, user *models.User) bool {
+	if err := middlewares.SetVisitorCookie(c, user.ID, a.keyring, a.cookieOpts...); err != nil {
+		_ = c.Error(fmt.Errorf("sign in: %w", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+		return false
+	}
+	return true
+}
+
+// accountResponse преобразует модель пользователя в ответ без хеша пароля.
+func accountResponse(user *models.User, mergedURLs int) AccountResponse {
+	return AccountResponse{ID: user.ID, Email: user.Email, CreatedAt: user.CreatedAt, MergedURLs: mergedURLs}
+}
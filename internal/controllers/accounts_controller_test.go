@@ -0,0 +1,401 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/middlewares"
+	"github.com/fsdevblog/shorturl/internal/controllers/mocksctrl"
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories/memstore"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/fsdevblog/shorturl/internal/tokens"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAccountBody = `{"email":"alice@example.com","password":"correct horse"}`
+
+// visitorCookieUUID возвращает UUID из выданной cookie посетителя или пустую строку.
+func visitorCookieUUID(t *testing.T, res *http.Response) string {
+	t.Helper()
+	for _, cookie := range res.Cookies() {
+		if cookie.Name != middlewares.VisitorCookieName || cookie.Value == "" {
+			continue
+		}
+		token, err := tokens.ValidateVisitorJWT(cookie.Value, []byte(jwtSecret))
+		require.NoError(t, err)
+		return token.Claims.(*tokens.VisitorClaims).UUID //nolint:errcheck
+	}
+	return ""
+}
+
+func TestAccountsController_Register(t *testing.T) {
+	visitor := uuid.NewString()
+	tests := []struct {
+		name        string
+		body        string
+		registerErr error
+		wantStatus  int
+	}{
+		{
+			name:       "created",
+			body:       testAccountBody,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "invalid json",
+			body:       `{"email":`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "invalid email",
+			body:        testAccountBody,
+			registerErr: services.ErrInvalidArgument,
+			wantStatus:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "email taken",
+			body:        testAccountBody,
+			registerErr: services.ErrEmailTaken,
+			wantStatus:  http.StatusConflict,
+		},
+		{
+			name:        "storage error",
+			body:        testAccountBody,
+			registerErr: errors.New("boom"),
+			wantStatus:  http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			accounts := mocksctrl.NewMockAccountManager(ctrl)
+			if tt.wantStatus != http.StatusBadRequest {
+				var user *models.User
+				if tt.registerErr == nil {
+					user = &models.User{ID: visitor, Email: "alice@example.com", CreatedAt: time.Now()}
+				}
+				accounts.EXPECT().Register(gomock.Any(), visitor, "alice@example.com", "correct horse").
+					Return(user, tt.registerErr)
+			}
+
+			token, err := tokens.GenerateVisitorJWT(visitor, middlewares.VisitorJWTExpireDuration, []byte(jwtSecret))
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/api/register", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.AddCookie(&http.Cookie{Name: middlewares.VisitorCookieName, Value: token})
+			w := httptest.NewRecorder()
+			newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) {
+				p.Accounts = accounts
+			}).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+			if tt.wantStatus != http.StatusCreated {
+				assert.Empty(t, visitorCookieUUID(t, w.Result()))
+				return
+			}
+			assert.Equal(t, visitor, visitorCookieUUID(t, w.Result()))
+			assert.NotContains(t, w.Body.String(), "password")
+		})
+	}
+}
+
+func TestAccountsController_Login(t *testing.T) {
+	account := uuid.NewString()
+	tests := []struct {
+		name       string
+		loginErr   error
+		wantStatus int
+	}{
+		{
+			name:       "logged in with merged links",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid credentials",
+			loginErr:   services.ErrInvalidCredentials,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "storage error",
+			loginErr:   errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			accounts := mocksctrl.NewMockAccountManager(ctrl)
+			var res *services.LoginResult
+			if tt.loginErr == nil {
+				res = &services.LoginResult{
+					User:       &models.User{ID: account, Email: "alice@example.com", CreatedAt: time.Now()},
+					MergedURLs: 3,
+				}
+			}
+			// Анонимный посетитель без cookie получает UUID от middleware, и его ссылки передаются аккаунту.
+			accounts.EXPECT().Login(gomock.Any(), gomock.Not(account), "alice@example.com", "correct horse").
+				Return(res, tt.loginErr)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/login", strings.NewReader(testAccountBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) {
+				p.Accounts = accounts
+			}).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			// Последняя cookie перекрывает cookie анонимного посетителя.
+			cookies := w.Result().Cookies()
+			require.NotEmpty(t, cookies)
+			last, err := tokens.ValidateVisitorJWT(cookies[len(cookies)-1].Value, []byte(jwtSecret))
+			require.NoError(t, err)
+			assert.Equal(t, account, last.Claims.(*tokens.VisitorClaims).UUID) //nolint:errcheck
+
+			var body AccountResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, 3, body.MergedURLs)
+		})
+	}
+}
+
+func TestAccountsController_Logout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	token, err := tokens.GenerateVisitorJWT(uuid.NewString(), middlewares.VisitorJWTExpireDuration, []byte(jwtSecret))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/logout", nil)
+	req.AddCookie(&http.Cookie{Name: middlewares.VisitorCookieName, Value: token})
+	w := httptest.NewRecorder()
+	newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) {
+		p.Accounts = mocksctrl.NewMockAccountManager(ctrl)
+	}).ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assertResponseMatchesSpec(t, req, w.Result())
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, middlewares.VisitorCookieName, cookies[0].Name)
+	assert.Negative(t, cookies[0].MaxAge)
+}
+
+// visitorTokenClaims возвращает данные токена из cookie посетителя.
+func visitorTokenClaims(t *testing.T, cookie *http.Cookie) *tokens.VisitorClaims {
+	t.Helper()
+	token, err := tokens.ValidateVisitorJWT(cookie.Value, []byte(jwtSecret))
+	require.NoError(t, err)
+	claims := token.Claims.(*tokens.VisitorClaims) //nolint:errcheck
+	require.NotEmpty(t, claims.ID)
+	require.NotEmpty(t, claims.SessionID)
+	return claims
+}
+
+func TestAccountsController_LogoutRevokesToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		revokeErr  error
+		wantStatus int
+	}{
+		{name: "token revoked", wantStatus: http.StatusNoContent},
+		{name: "revoke error", revokeErr: errLeaked, wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			visitor := uuid.NewString()
+			token, err := tokens.GenerateVisitorJWT(visitor, middlewares.VisitorJWTExpireDuration, []byte(jwtSecret))
+			require.NoError(t, err)
+			cookie := &http.Cookie{Name: middlewares.VisitorCookieName, Value: token}
+			claims := visitorTokenClaims(t, cookie)
+			revocations := mocksctrl.NewMockTokenRevoker(ctrl)
+			revocations.EXPECT().
+				IsRevoked(gomock.Any(), visitor, claims.ID, claims.SessionID, gomock.Any()).
+				Return(false, nil)
+			// Отзывается сессия целиком, а не только предъявленный токен.
+			revocations.EXPECT().Revoke(gomock.Any(), visitor, claims.SessionID).Return(tt.revokeErr)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/logout", nil)
+			req.AddCookie(cookie)
+			w := httptest.NewRecorder()
+			newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) {
+				p.Revocations = revocations
+			}).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+			assert.NotContains(t, w.Body.String(), errLeaked.Error())
+			if tt.revokeErr == nil {
+				cookies := w.Result().Cookies()
+				require.Len(t, cookies, 1)
+				assert.Negative(t, cookies[0].MaxAge)
+			}
+		})
+	}
+}
+
+func TestAccountsController_LogoutRevokesSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	visitor := uuid.NewString()
+	store := mocksctrl.NewMockShortURLStore(ctrl)
+	store.EXPECT().GetAllByVisitorUUID(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	revocations := services.NewTokenRevocationService(memstore.NewTokenRevocationRepo(db.NewMemStorage()))
+	router := newTestRouter(store, func(p *RouterParams) { p.Revocations = revocations })
+
+	// visitorUUIDOf выполняет запрос с cookie и возвращает посетителя, от имени которого он обработан.
+	visitorUUIDOf := func(cookie *http.Cookie) (string, *http.Cookie) {
+		req := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		for _, issued := range w.Result().Cookies() {
+			if issued.Name == middlewares.VisitorCookieName {
+				return visitorTokenClaims(t, issued).UUID, issued
+			}
+		}
+		return visitorTokenClaims(t, cookie).UUID, nil
+	}
+
+	// Токен, до истечения которого меньше RefreshBefore, продлевается в рамках той же сессии.
+	original := visitorCookie(t, visitor)
+	got, refreshed := visitorUUIDOf(original)
+	require.Equal(t, visitor, got)
+	require.NotNil(t, refreshed)
+	assert.Equal(t, visitorTokenClaims(t, original).SessionID, visitorTokenClaims(t, refreshed).SessionID)
+	assert.NotEqual(t, visitorTokenClaims(t, original).ID, visitorTokenClaims(t, refreshed).ID)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/logout", nil)
+	req.AddCookie(refreshed)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	// Выход отзывает и токен, выпущенный до продления.
+	for _, cookie := range []*http.Cookie{original, refreshed} {
+		got, _ = visitorUUIDOf(cookie)
+		assert.NotEqual(t, visitor, got)
+	}
+}
+
+func TestAccountsController_RevokeAll(t *testing.T) {
+	tests := []struct {
+		name       string
+		revokeErr  error
+		wantStatus int
+	}{
+		{name: "sessions revoked", wantStatus: http.StatusNoContent},
+		{name: "revoke error", revokeErr: errLeaked, wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			visitor := uuid.NewString()
+			revocations := mocksctrl.NewMockTokenRevoker(ctrl)
+			revocations.EXPECT().IsRevoked(gomock.Any(), visitor, gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(false, nil)
+			revocations.EXPECT().RevokeAll(gomock.Any(), visitor).Return(tt.revokeErr)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/logout/all", nil)
+			req.AddCookie(visitorCookie(t, visitor))
+			w := httptest.NewRecorder()
+			newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) {
+				p.Revocations = revocations
+			}).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+			assert.NotContains(t, w.Body.String(), errLeaked.Error())
+		})
+	}
+}
+
+func TestVisitorCookieMiddleware_Revocation(t *testing.T) {
+	tests := []struct {
+		name        string
+		revoked     bool
+		wantVisitor bool // Сохраняется ли посетитель из предъявленного токена
+	}{
+		{name: "not revoked", wantVisitor: true},
+		{name: "revoked", revoked: true, wantVisitor: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			visitor := uuid.NewString()
+			revocations := mocksctrl.NewMockTokenRevoker(ctrl)
+			revocations.EXPECT().
+				IsRevoked(gomock.Any(), visitor, gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(tt.revoked, nil)
+			var gotVisitor string
+			revocations.EXPECT().RevokeAll(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, visitorUUID string) error {
+					gotVisitor = visitorUUID
+					return nil
+				})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/logout/all", nil)
+			req.AddCookie(visitorCookie(t, visitor))
+			w := httptest.NewRecorder()
+			newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) {
+				p.Revocations = revocations
+			}).ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusNoContent, w.Code)
+			assert.Equal(t, tt.wantVisitor, gotVisitor == visitor)
+			assert.NotEmpty(t, gotVisitor)
+		})
+	}
+}
+
+func TestVisitorCookieMiddleware_RevocationCheckError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	visitor := uuid.NewString()
+	store := mocksctrl.NewMockShortURLStore(ctrl)
+	revocations := mocksctrl.NewMockTokenRevoker(ctrl)
+	revocations.EXPECT().IsRevoked(gomock.Any(), visitor, gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(false, errLeaked).AnyTimes()
+	router := newTestRouter(store, func(p *RouterParams) { p.Revocations = revocations })
+	// Токен близок к истечению: при успешной проверке он был бы продлен.
+	cookie := visitorCookie(t, visitor)
+
+	// Посетитель сохраняет доступ к своим ссылкам, но токен не продлевается.
+	store.EXPECT().GetAllByVisitorUUID(gomock.Any(), visitor).Return(nil, nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+	req.AddCookie(cookie)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Result().Cookies())
+
+	// Управлять сессиями непроверенный токен не может: Revoke и RevokeAll не вызываются.
+	for _, path := range []string{"/api/logout", "/api/logout/all"} {
+		t.Run(path, func(t *testing.T) {
+			req = httptest.NewRequest(http.MethodPost, path, nil)
+			req.AddCookie(cookie)
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusServiceUnavailable, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+			assert.NotEmpty(t, w.Header().Get("Retry-After"))
+			assert.NotContains(t, w.Body.String(), errLeaked.Error())
+			assert.Empty(t, w.Result().Cookies())
+		})
+	}
+}
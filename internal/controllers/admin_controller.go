@@ -0,0 +1,399 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrVisitorBanned ошибка запроса заблокированного посетителя.
+var ErrVisitorBanned = errors.New("visitor is banned")
+
+// AdminController обрабатывает HTTP запросы администратора: поиск и отключение ссылок,
+// блокировку посетителей и просмотр журнала аудита.
+// Доступ к маршрутам ограничивается middlewares.RequireRoleMiddleware.
+type AdminController struct {
+	adminService AdminManager
+	baseURL      string
+}
+
+// NewAdminController создает новый экземпляр AdminController.
+//
+// Параметры:
+//   - adminService: сервис модерации
+//   - baseURL: базовый адрес коротких ссылок
+//
+// Возвращает:
+//   - *AdminController: новый экземпляр контроллера
+func NewAdminController(adminService AdminManager, baseURL string) *AdminController {
+	return &AdminController{adminService: adminService, baseURL: baseURL}
+}
+
+// AdminURLResponse описание ссылки для администратора, включая владельца и состояние модерации.
+type AdminURLResponse struct {
+	ShortID        string     `json:"short_id"`
+	ShortURL       string     `json:"short_url"`
+	OriginalURL    string     `json:"original_url"`
+	VisitorUUID    string     `json:"visitor_uuid"`
+	State          string     `json:"state"` // active, deleted или disabled
+	CreatedAt      time.Time  `json:"created_at"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+	DisabledAt     *time.Time `json:"disabled_at,omitempty"`
+	DisabledReason string     `json:"disabled_reason,omitempty"`
+	DisabledLegal  bool       `json:"disabled_legal,omitempty"`
+	Tags           []string   `json:"tags,omitempty"`
+}
+
+// SearchURLsParams параметры поиска ссылок.
+type SearchURLsParams struct {
+	Query       string `form:"q"`            // Подстрока оригинального URL или короткий идентификатор
+	VisitorUUID string `form:"visitor_uuid"` // Владелец ссылок
+	Disabled    bool   `form:"disabled"`     // Только отключенные ссылки
+	Limit       int    `form:"limit"`
+	Offset      int    `form:"offset"`
+}
+
+// DisableURLParams параметры отключения ссылки.
+type DisableURLParams struct {
+	Reason string `json:"reason"` // Причина, показывается при переходе по ссылке
+	Legal  bool   `json:"legal"`  // Отключение по требованию закона: переход отвечает 451 вместо 410
+}
+
+// BanVisitorParams параметры блокировки посетителя.
+type BanVisitorParams struct {
+	Reason       string `json:"reason"`
+	DisableLinks bool   `json:"disable_links"` // Отключить все действующие ссылки посетителя
+}
+
+// BanResponse структура ответа о блокировке посетителя.
+type BanResponse struct {
+	VisitorUUID  string    `json:"visitor_uuid"`
+	Reason       string    `json:"reason"`
+	BannedBy     string    `json:"banned_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	DisabledURLs int       `json:"disabled_urls"` // Количество отключенных ссылок посетителя
+}
+
+// AuditLogParams параметры выборки журнала аудита.
+type AuditLogParams struct {
+	Target string `form:"target"` // Короткий идентификатор ссылки или UUID посетителя
+	Limit  int    `form:"limit"`
+}
+
+// AuditEntryResponse запись журнала аудита.
+type AuditEntryResponse struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ActorUUID string    `json:"actor_uuid"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// SearchURLs ищет ссылки всех посетителей, включая удаленные и отключенные, от новых к старым.
+//
+// Параметры запроса:
+//   - q: подстрока оригинального URL без учета регистра или короткий идентификатор
+//   - visitor_uuid: владелец ссылок
+//   - disabled: только отключенные ссылки
+//   - limit, offset: страница результатов (по умолчанию 50 записей, не больше 500)
+//
+// Коды ответа:
+//   - 200: найденные ссылки
+//   - 204: ничего не найдено
+//   - 400: некорректные параметры запроса
+//   - 500: внутренняя ошибка сервера
+func (a *AdminController) SearchURLs(c *gin.Context) {
+	var params SearchURLsParams
+	if bindErr := c.ShouldBindQuery(&params); bindErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	urls, err := a.adminService.SearchURLs(ctx, repositories.URLSearchFilter{
+		Query:        strings.TrimSpace(params.Query),
+		VisitorUUID:  params.VisitorUUID,
+		OnlyDisabled: params.Disabled,
+		Limit:        params.Limit,
+		Offset:       params.Offset,
+	})
+	if err != nil {
+		_ = c.Error(fmt.Errorf("search urls: %w", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+		return
+	}
+	if len(urls) == 0 {
+		c.AbortWithStatus(http.StatusNoContent)
+		return
+	}
+
+	var r = make([]AdminURLResponse, len(urls))
+	for i := range urls {
+		r[i] = a.urlResponse(c.Request, &urls[i])
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+// DisableURL отключает ссылку. Переход по ней отвечает 410, а при legal 451, и показывает причину.
+// Повторное отключение обновляет причину.
+//
+// Параметры URL:
+//   - shortID: короткий идентификатор ссылки
+//
+// Коды ответа:
+//   - 200: ссылка отключена
+//   - 400: некорректный запрос
+//   - 404: ссылка не найдена
+//   - 422: причина не задана или слишком длинная
+//   - 500: внутренняя ошибка сервера
+func (a *AdminController) DisableURL(c *gin.Context) {
+	actorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var params DisableURLParams
+	if bindErr := c.ShouldBindJSON(&params); bindErr != nil {
+		_ = c.Error(fmt.Errorf("bind params: %w", bindErr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request. Only json is supported"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	sURL, err := a.adminService.DisableURL(ctx, actorUUID, c.Param("shortID"), params.Reason, params.Legal)
+	if err != nil {
+		a.abortWithError(c, fmt.Errorf("disable url: %w", err))
+		return
+	}
+	c.JSON(http.StatusOK, a.urlResponse(c.Request, sURL))
+}
+
+// EnableURL снимает отключение ссылки.
+//
+// Параметры URL:
+//   - shortID: короткий идентификатор ссылки
+//
+// Коды ответа:
+//   - 200: ссылка включена
+//   - 404: ссылка не найдена
+//   - 500: внутренняя ошибка сервера
+func (a *AdminController) EnableURL(c *gin.Context) {
+	actorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	sURL, err := a.adminService.EnableURL(ctx, actorUUID, c.Param("shortID"))
+	if err != nil {
+		a.abortWithError(c, fmt.Errorf("enable url: %w", err))
+		return
+	}
+	c.JSON(http.StatusOK, a.urlResponse(c.Request, sURL))
+}
+
+// BanVisitor блокирует посетителя. Заблокированный посетитель получает 403 на запросы,
+// изменяющие данные, но может переходить по ссылкам.
+//
+// Параметры URL:
+//   - visitorUUID: UUID посетителя
+//
+// Коды ответа:
+//   - 201: посетитель заблокирован
+//   - 400: некорректный запрос
+//   - 409: посетитель уже заблокирован
+//   - 422: некорректный UUID, причина не задана или попытка заблокировать себя
+//   - 500: внутренняя ошибка сервера
+func (a *AdminController) BanVisitor(c *gin.Context) {
+	actorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var params BanVisitorParams
+	if bindErr := c.ShouldBindJSON(&params); bindErr != nil {
+		_ = c.Error(fmt.Errorf("bind params: %w", bindErr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request. Only json is supported"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	res, err := a.adminService.BanVisitor(ctx, actorUUID, c.Param("visitorUUID"), params.Reason, params.DisableLinks)
+	if err != nil {
+		a.abortWithError(c, fmt.Errorf("ban visitor: %w", err))
+		return
+	}
+	c.JSON(http.StatusCreated, BanResponse{
+		VisitorUUID:  res.Ban.VisitorUUID,
+		Reason:       res.Ban.Reason,
+		BannedBy:     res.Ban.BannedBy,
+		CreatedAt:    res.Ban.CreatedAt,
+		DisabledURLs: res.DisabledURLs,
+	})
+}
+
+// UnbanVisitor снимает блокировку посетителя. Отключенные при блокировке ссылки остаются отключенными.
+//
+// Параметры URL:
+//   - visitorUUID: UUID посетителя
+//
+// Коды ответа:
+//   - 204: блокировка снята
+//   - 404: посетитель не заблокирован
+//   - 500: внутренняя ошибка сервера
+func (a *AdminController) UnbanVisitor(c *gin.Context) {
+	actorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	if err := a.adminService.UnbanVisitor(ctx, actorUUID, c.Param("visitorUUID")); err != nil {
+		a.abortWithError(c, fmt.Errorf("unban visitor: %w", err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// AuditLog возвращает журнал действий администраторов от новых к старым.
+//
+// Параметры запроса:
+//   - target: короткий идентификатор ссылки или UUID посетителя
+//   - limit: количество записей (по умолчанию 50, не больше 500)
+//
+// Коды ответа:
+//   - 200: записи журнала
+//   - 204: записей нет
+//   - 400: некорректные параметры запроса
+//   - 500: внутренняя ошибка сервера
+func (a *AdminController) AuditLog(c *gin.Context) {
+	var params AuditLogParams
+	if bindErr := c.ShouldBindQuery(&params); bindErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	entries, err := a.adminService.AuditLog(ctx, repositories.AuditFilter{Target: params.Target, Limit: params.Limit})
+	if err != nil {
+		_ = c.Error(fmt.Errorf("audit log: %w", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+		return
+	}
+	if len(entries) == 0 {
+		c.AbortWithStatus(http.StatusNoContent)
+		return
+	}
+
+	var r = make([]AuditEntryResponse, len(entries))
+	for i, e := range entries {
+		r[i] = AuditEntryResponse{
+			ID:        e.ID,
+			CreatedAt: e.CreatedAt,
+			ActorUUID: e.ActorUUID,
+			Action:    string(e.Action),
+			Target:    e.Target,
+			Reason:    e.Reason,
+		}
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+// abortWithError отвечает кодом, соответствующим ошибке сервиса модерации.
+func (a *AdminController) abortWithError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidArgument):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrRecordNotFound.Error()})
+	case errors.Is(err, services.ErrDuplicateKey):
+		c.JSON(http.StatusConflict, gin.H{"error": "visitor is already banned"})
+	default:
+		_ = c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+	}
+}
+
+// urlResponse преобразует модель ссылки в ответ администратору.
+func (a *AdminController) urlResponse(r *http.Request, sURL *models.URL) AdminURLResponse {
+	return AdminURLResponse{
+		ShortID:        sURL.ShortIdentifier,
+		ShortURL:       buildShortURL(a.baseURL, r, sURL.ShortIdentifier),
+		OriginalURL:    sURL.URL,
+		VisitorUUID:    sURL.VisitorUUID,
+		State:          string(sURL.State()),
+		CreatedAt:      sURL.CreatedAt,
+		DeletedAt:      sURL.DeletedAt,
+		DisabledAt:     sURL.DisabledAt,
+		DisabledReason: sURL.DisabledReason,
+		DisabledLegal:  sURL.DisabledLegal,
+		Tags:           sURL.Tags,
+	}
+}
+
+// BanMiddleware отклоняет запросы заблокированных посетителей, изменяющие данные (все методы,
+// кроме GET, HEAD и OPTIONS). Переходы по ссылкам и чтение остаются доступны.
+// Для /api/v2 ошибка отдается в формате application/problem+json.
+//
+// Параметры:
+//   - checker: источник блокировок посетителей
+//
+// Возвращает:
+//   - gin.HandlerFunc: middleware функция, отвечающая 403 заблокированным посетителям
+func BanMiddleware(checker AdminManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		visitorUUID, ok := visitorUUIDFromContext(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+		banned, err := checker.IsBanned(ctx, visitorUUID)
+		cancel()
+		isV2 := strings.HasPrefix(c.Request.URL.Path, "/api/v2/")
+		switch {
+		case err != nil && isV2:
+			abortWithInternalProblem(c, fmt.Errorf("check visitor ban: %w", err))
+		case err != nil:
+			_ = c.Error(fmt.Errorf("check visitor ban: %w", err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+		case banned && isV2:
+			abortWithProblem(c, http.StatusForbidden, ProblemForbidden, ErrVisitorBanned.Error())
+		case banned:
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": ErrVisitorBanned.Error()})
+		default:
+			c.Next()
+		}
+	}
+}
@@ -0,0 +1,424 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/middlewares"
+	"github.com/fsdevblog/shorturl/internal/controllers/mocksctrl"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/fsdevblog/shorturl/internal/tokens"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAdminUUID   = "5f0c6a3e-8b1d-4c2a-9e7f-0a1b2c3d4e5f"
+	testBannedUUID  = "7a2b3c4d-5e6f-4a1b-8c9d-0e1f2a3b4c5d"
+	testAdminReason = "phishing"
+)
+
+// visitorCookie возвращает cookie посетителя, подписанную тестовым секретом.
+func visitorCookie(t *testing.T, visitorUUID string, roles ...string) *http.Cookie {
+	t.Helper()
+	token, err := tokens.NewStaticKeyring([]byte(jwtSecret)).GenerateVisitorJWT(visitorUUID, time.Hour, roles...)
+	require.NoError(t, err)
+	return &http.Cookie{Name: middlewares.VisitorCookieName, Value: token}
+}
+
+func TestAdminController_RoleRequired(t *testing.T) {
+	tests := []struct {
+		name       string
+		cookie     *http.Cookie
+		wantStatus int
+		wantAdmin  bool
+	}{
+		{
+			name:       "new visitor",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "visitor without role",
+			cookie:     visitorCookie(t, uuid.NewString()),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "revoked role in token",
+			cookie:     visitorCookie(t, uuid.NewString(), tokens.RoleAdmin),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "admin",
+			cookie:     visitorCookie(t, testAdminUUID),
+			wantStatus: http.StatusNoContent,
+			wantAdmin:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			admin := mocksctrl.NewMockAdminManager(ctrl)
+			if tt.wantAdmin {
+				admin.EXPECT().SearchURLs(gomock.Any(), gomock.Any()).Return(nil, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/urls", nil)
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+			w := httptest.NewRecorder()
+			newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) {
+				p.Admin = admin
+				p.AppConf.AdminVisitorUUIDs = []string{testAdminUUID}
+			}).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+
+			// Роли в токене пересчитываются при каждом запросе: выданная cookie отражает текущий список администраторов.
+			for _, cookie := range w.Result().Cookies() {
+				if cookie.Name != middlewares.VisitorCookieName {
+					continue
+				}
+				token, err := tokens.ValidateVisitorJWT(cookie.Value, []byte(jwtSecret))
+				require.NoError(t, err)
+				claims := token.Claims.(*tokens.VisitorClaims) //nolint:errcheck
+				assert.Equal(t, tt.wantAdmin, claims.HasRole(tokens.RoleAdmin))
+			}
+		})
+	}
+}
+
+func TestAdminController(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	disabledURL := &models.URL{
+		ShortIdentifier: "abcdefgh",
+		URL:             "https://example.com",
+		VisitorUUID:     testBannedUUID,
+		CreatedAt:       now,
+		DisabledAt:      &now,
+		DisabledReason:  testAdminReason,
+		DisabledLegal:   true,
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		body       string
+		mock       func(admin *mocksctrl.MockAdminManager)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:   "search urls",
+			method: http.MethodGet,
+			url:    "/api/admin/urls?q=Example&visitor_uuid=" + testBannedUUID + "&disabled=true&limit=10&offset=20",
+			mock: func(admin *mocksctrl.MockAdminManager) {
+				admin.EXPECT().SearchURLs(gomock.Any(), repositories.URLSearchFilter{
+					Query:        "Example",
+					VisitorUUID:  testBannedUUID,
+					OnlyDisabled: true,
+					Limit:        10,
+					Offset:       20,
+				}).Return([]models.URL{*disabledURL}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "search urls nothing found",
+			method: http.MethodGet,
+			url:    "/api/admin/urls?q=none",
+			mock: func(admin *mocksctrl.MockAdminManager) {
+				admin.EXPECT().SearchURLs(gomock.Any(), gomock.Any()).Return(nil, nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "search urls invalid limit",
+			method:     http.MethodGet,
+			url:        "/api/admin/urls?limit=many",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "search urls storage error",
+			method: http.MethodGet,
+			url:    "/api/admin/urls",
+			mock: func(admin *mocksctrl.MockAdminManager) {
+				admin.EXPECT().SearchURLs(gomock.Any(), gomock.Any()).Return(nil, services.ErrUnknown)
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:   "disable url",
+			method: http.MethodPost,
+			url:    "/api/admin/urls/abcdefgh/disable",
+			body:   `{"reason":"phishing","legal":true}`,
+			mock: func(admin *mocksctrl.MockAdminManager) {
+				admin.EXPECT().DisableURL(gomock.Any(), testAdminUUID, "abcdefgh", testAdminReason, true).
+					Return(disabledURL, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "disable url invalid json",
+			method:     http.MethodPost,
+			url:        "/api/admin/urls/abcdefgh/disable",
+			body:       `{"reason":`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "disable url without reason",
+			method: http.MethodPost,
+			url:    "/api/admin/urls/abcdefgh/disable",
+			body:   `{}`,
+			mock: func(admin *mocksctrl.MockAdminManager) {
+				admin.EXPECT().DisableURL(gomock.Any(), testAdminUUID, "abcdefgh", "", false).
+					Return(nil, services.ErrInvalidArgument)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "disable unknown url",
+			method: http.MethodPost,
+			url:    "/api/admin/urls/unknown1/disable",
+			body:   `{"reason":"phishing"}`,
+			mock: func(admin *mocksctrl.MockAdminManager) {
+				admin.EXPECT().DisableURL(gomock.Any(), testAdminUUID, "unknown1", testAdminReason, false).
+					Return(nil, services.ErrRecordNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "enable url",
+			method: http.MethodPost,
+			url:    "/api/admin/urls/abcdefgh/enable",
+			mock: func(admin *mocksctrl.MockAdminManager) {
+				admin.EXPECT().EnableURL(gomock.Any(), testAdminUUID, "abcdefgh").
+					Return(&models.URL{ShortIdentifier: "abcdefgh", URL: "https://example.com", CreatedAt: now}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "enable url storage error",
+			method: http.MethodPost,
+			url:    "/api/admin/urls/abcdefgh/enable",
+			mock: func(admin *mocksctrl.MockAdminManager) {
+				admin.EXPECT().EnableURL(gomock.Any(), testAdminUUID, "abcdefgh").Return(nil, errLeaked)
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error":"internal error"}`,
+		},
+		{
+			name:   "ban visitor",
+			method: http.MethodPost,
+			url:    "/api/admin/visitors/" + testBannedUUID + "/ban",
+			body:   `{"reason":"phishing","disable_links":true}`,
+			mock: func(admin *mocksctrl.MockAdminManager) {
+				admin.EXPECT().BanVisitor(gomock.Any(), testAdminUUID, testBannedUUID, testAdminReason, true).
+					Return(&services.BanResult{
+						Ban: &models.VisitorBan{
+							VisitorUUID: testBannedUUID,
+							Reason:      testAdminReason,
+							BannedBy:    testAdminUUID,
+							CreatedAt:   now,
+						},
+						DisabledURLs: 3,
+					}, nil)
+			},
+			wantStatus: http.StatusCreated,
+			wantBody: `{"visitor_uuid":"` + testBannedUUID + `","reason":"phishing","banned_by":"` + testAdminUUID +
+				`","created_at":"2025-01-02T03:04:05Z","disabled_urls":3}`,
+		},
+		{
+			name:   "ban visitor twice",
+			method: http.MethodPost,
+			url:    "/api/admin/visitors/" + testBannedUUID + "/ban",
+			body:   `{"reason":"phishing"}`,
+			mock: func(admin *mocksctrl.MockAdminManager) {
+				admin.EXPECT().BanVisitor(gomock.Any(), testAdminUUID, testBannedUUID, testAdminReason, false).
+					Return(nil, services.ErrDuplicateKey)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:   "ban invalid visitor",
+			method: http.MethodPost,
+			url:    "/api/admin/visitors/nobody/ban",
+			body:   `{"reason":"phishing"}`,
+			mock: func(admin *mocksctrl.MockAdminManager) {
+				admin.EXPECT().BanVisitor(gomock.Any(), testAdminUUID, "nobody", testAdminReason, false).
+					Return(nil, services.ErrInvalidArgument)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "unban visitor",
+			method: http.MethodDelete,
+			url:    "/api/admin/visitors/" + testBannedUUID + "/ban",
+			mock: func(admin *mocksctrl.MockAdminManager) {
+				admin.EXPECT().UnbanVisitor(gomock.Any(), testAdminUUID, testBannedUUID).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:   "unban visitor not banned",
+			method: http.MethodDelete,
+			url:    "/api/admin/visitors/" + testBannedUUID + "/ban",
+			mock: func(admin *mocksctrl.MockAdminManager) {
+				admin.EXPECT().UnbanVisitor(gomock.Any(), testAdminUUID, testBannedUUID).
+					Return(services.ErrRecordNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "audit log",
+			method: http.MethodGet,
+			url:    "/api/admin/audit?target=abcdefgh&limit=5",
+			mock: func(admin *mocksctrl.MockAdminManager) {
+				admin.EXPECT().AuditLog(gomock.Any(), repositories.AuditFilter{Target: "abcdefgh", Limit: 5}).
+					Return([]models.AuditEntry{{
+						ID:        uuid.NewString(),
+						CreatedAt: now,
+						ActorUUID: testAdminUUID,
+						Action:    models.AuditURLDisabled,
+						Target:    "abcdefgh",
+						Reason:    testAdminReason,
+					}}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "audit log empty",
+			method: http.MethodGet,
+			url:    "/api/admin/audit",
+			mock: func(admin *mocksctrl.MockAdminManager) {
+				admin.EXPECT().AuditLog(gomock.Any(), repositories.AuditFilter{}).Return(nil, nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			admin := mocksctrl.NewMockAdminManager(ctrl)
+			if tt.method != http.MethodGet {
+				admin.EXPECT().IsBanned(gomock.Any(), testAdminUUID).Return(false, nil)
+			}
+			if tt.mock != nil {
+				tt.mock(admin)
+			}
+
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.AddCookie(visitorCookie(t, testAdminUUID, tokens.RoleAdmin))
+			w := httptest.NewRecorder()
+			newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) {
+				p.Admin = admin
+				p.AppConf.AdminVisitorUUIDs = []string{testAdminUUID}
+			}).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			assertResponseMatchesSpec(t, req, w.Result())
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && tt.method == http.MethodPost {
+				var res AdminURLResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+				assert.Equal(t, "http://test.com/abcdefgh", res.ShortURL)
+			}
+		})
+	}
+}
+
+func TestBanMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		body       string
+		banned     bool
+		checkErr   error
+		skipCheck  bool
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "banned visitor cannot shorten",
+			method:     http.MethodPost,
+			url:        "/api/shorten",
+			body:       `{"url":"https://example.com"}`,
+			banned:     true,
+			wantStatus: http.StatusForbidden,
+			wantBody:   `{"error":"visitor is banned"}`,
+		},
+		{
+			name:       "banned visitor gets problem in v2",
+			method:     http.MethodPost,
+			url:        "/api/v2/shorten",
+			body:       `{"url":"https://example.com"}`,
+			banned:     true,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "banned visitor still follows links",
+			method:     http.MethodGet,
+			url:        "/abcdefgh",
+			skipCheck:  true,
+			wantStatus: http.StatusTemporaryRedirect,
+		},
+		{
+			name:       "ban check error",
+			method:     http.MethodPost,
+			url:        "/api/shorten",
+			body:       `{"url":"https://example.com"}`,
+			checkErr:   errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			admin := mocksctrl.NewMockAdminManager(ctrl)
+			store := mocksctrl.NewMockShortURLStore(ctrl)
+			if !tt.skipCheck {
+				admin.EXPECT().IsBanned(gomock.Any(), testBannedUUID).Return(tt.banned, tt.checkErr)
+			}
+			if tt.wantStatus == http.StatusTemporaryRedirect {
+				store.EXPECT().Visit(gomock.Any(), "abcdefgh").
+					Return(&models.URL{ShortIdentifier: "abcdefgh", URL: "https://example.com"}, nil)
+			}
+
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.AddCookie(visitorCookie(t, testBannedUUID))
+			w := httptest.NewRecorder()
+			newTestRouter(store, func(p *RouterParams) {
+				p.Admin = admin
+				p.AppConf.AdminVisitorUUIDs = []string{testAdminUUID}
+			}).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+			if strings.HasPrefix(tt.url, "/api/v2/") {
+				assert.Contains(t, w.Body.String(), string(ProblemForbidden))
+			}
+		})
+	}
+}
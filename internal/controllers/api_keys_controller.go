@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// APIKeysController обрабатывает HTTP запросы управления ключами API посетителя.
+type APIKeysController struct {
+	apiKeyService APIKeyManager
+}
+
+// NewAPIKeysController создает новый экземпляр APIKeysController.
+//
+// Параметры:
+//   - apiKeyService: сервис ключей API
+//
+// Возвращает:
+//   - *APIKeysController: новый экземпляр контроллера
+func NewAPIKeysController(apiKeyService APIKeyManager) *APIKeysController {
+	return &APIKeysController{apiKeyService: apiKeyService}
+}
+
+// CreateAPIKeyParams параметры выпуска ключа API.
+type CreateAPIKeyParams struct {
+	// Name произвольное название ключа
+	Name string `json:"name"`
+}
+
+// APIKeyResponse структура ответа с данными ключа API.
+type APIKeyResponse struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`        // Открытая часть ключа для опознания в списке
+	Key       string     `json:"key,omitempty"` // Ключ целиком, отдается только при создании
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Create выпускает ключ API текущего посетителя.
+// Ключ целиком возвращается только в этом ответе. Тело запроса необязательно.
+//
+// Коды ответа:
+//   - 201: ключ создан
+//   - 400: некорректный запрос
+//   - 403: посетитель не определен
+//   - 422: слишком длинное название
+//   - 500: внутренняя ошибка сервера
+func (a *APIKeysController) Create(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	var params CreateAPIKeyParams
+	if bindErr := c.ShouldBindJSON(&params); bindErr != nil && !errors.Is(bindErr, io.EOF) {
+		_ = c.Error(fmt.Errorf("bind params: %w", bindErr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request. Only json is supported"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	key, token, err := a.apiKeyService.Create(ctx, visitorUUID, params.Name)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidArgument) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		_ = c.Error(fmt.Errorf("create api key: %w", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+		return
+	}
+
+	res := apiKeyResponse(key)
+	res.Key = token
+	c.JSON(http.StatusCreated, res)
+}
+
+// List возвращает ключи API текущего посетителя, включая отозванные. Ключи целиком не возвращаются.
+//
+// Коды ответа:
+//   - 200: список ключей
+//   - 204: у посетителя нет ключей
+//   - 403: посетитель не определен
+//   - 500: внутренняя ошибка сервера
+func (a *APIKeysController) List(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	keys, err := a.apiKeyService.GetAllByVisitorUUID(ctx, visitorUUID)
+	if err != nil {
+		_ = c.Error(fmt.Errorf("get api keys: %w", err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if len(keys) == 0 {
+		c.AbortWithStatus(http.StatusNoContent)
+		return
+	}
+
+	var r = make([]APIKeyResponse, len(keys))
+	for i := range keys {
+		r[i] = apiKeyResponse(&keys[i])
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+// Revoke отзывает ключ API текущего посетителя.
+//
+// Параметры URL:
+//   - id: идентификатор ключа
+//
+// Коды ответа:
+//   - 204: ключ отозван
+//   - 403: посетитель не определен
+//   - 404: действующий ключ не найден
+//   - 500: внутренняя ошибка сервера
+func (a *APIKeysController) Revoke(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	id := c.Param("id")
+	if uuid.Validate(id) != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	if err := a.apiKeyService.Revoke(ctx, visitorUUID, id); err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		_ = c.Error(fmt.Errorf("revoke api key: %w", err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// apiKeyResponse преобразует модель ключа API в ответ без ключа целиком.
+func apiKeyResponse(key *models.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		CreatedAt: key.CreatedAt,
+		RevokedAt: key.RevokedAt,
+	}
+}
@@ -0,0 +1,279 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/middlewares"
+	"github.com/fsdevblog/shorturl/internal/controllers/mocksctrl"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAPIKeyID    = "0b8f7c2e-3d4a-4e5f-8a6b-1c2d3e4f5a6b"
+	testAPIKeyToken = "surl_0123456789ab_secret"
+)
+
+func TestAPIKeysController_Create(t *testing.T) {
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name       string
+		body       string
+		wantName   string
+		createErr  error
+		wantStatus int
+		skipCreate bool
+	}{
+		{
+			name:       "with name",
+			body:       `{"name":"ci"}`,
+			wantName:   "ci",
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "empty body",
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "invalid json",
+			body:       `{"name":`,
+			wantStatus: http.StatusBadRequest,
+			skipCreate: true,
+		},
+		{
+			name:       "name too long",
+			body:       `{"name":"long"}`,
+			wantName:   "long",
+			createErr:  services.ErrInvalidArgument,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "storage error",
+			body:       `{"name":"ci"}`,
+			wantName:   "ci",
+			createErr:  errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			keys := mocksctrl.NewMockAPIKeyManager(ctrl)
+			if !tt.skipCreate {
+				var key *models.APIKey
+				var token string
+				if tt.createErr == nil {
+					key = &models.APIKey{ID: testAPIKeyID, Name: tt.wantName, Prefix: "surl_0123456789ab", CreatedAt: createdAt}
+					token = testAPIKeyToken
+				}
+				keys.EXPECT().Create(gomock.Any(), gomock.Any(), tt.wantName).Return(key, token, tt.createErr)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/user/api-keys", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) { p.APIKeys = keys }).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+			var res APIKeyResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			assert.Equal(t, testAPIKeyID, res.ID)
+			assert.Equal(t, testAPIKeyToken, res.Key)
+		})
+	}
+}
+
+func TestAPIKeysController_List(t *testing.T) {
+	revokedAt := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		keys       []models.APIKey
+		listErr    error
+		wantStatus int
+	}{
+		{
+			name: "keys without secrets",
+			keys: []models.APIKey{
+				{ID: testAPIKeyID, Name: "ci", Prefix: "surl_0123456789ab", Hash: "hash"},
+				{ID: uuid.NewString(), Prefix: "surl_ba9876543210", Hash: "hash", RevokedAt: &revokedAt},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "no keys",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "storage error",
+			listErr:    errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			keys := mocksctrl.NewMockAPIKeyManager(ctrl)
+			keys.EXPECT().GetAllByVisitorUUID(gomock.Any(), gomock.Any()).Return(tt.keys, tt.listErr)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/api-keys", nil)
+			w := httptest.NewRecorder()
+			newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) { p.APIKeys = keys }).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			assert.NotContains(t, w.Body.String(), "hash")
+			var res []APIKeyResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			require.Len(t, res, len(tt.keys))
+			assert.Empty(t, res[0].Key)
+			assert.Equal(t, &revokedAt, res[1].RevokedAt)
+		})
+	}
+}
+
+func TestAPIKeysController_Revoke(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		revokeErr  error
+		wantStatus int
+	}{
+		{
+			name:       "revoked",
+			id:         testAPIKeyID,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "not found",
+			id:         testAPIKeyID,
+			revokeErr:  services.ErrRecordNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "invalid id",
+			id:         "not-a-uuid",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "storage error",
+			id:         testAPIKeyID,
+			revokeErr:  errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			keys := mocksctrl.NewMockAPIKeyManager(ctrl)
+			if uuid.Validate(tt.id) == nil {
+				keys.EXPECT().Revoke(gomock.Any(), gomock.Any(), tt.id).Return(tt.revokeErr)
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/user/api-keys/"+tt.id, nil)
+			w := httptest.NewRecorder()
+			newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) { p.APIKeys = keys }).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+		})
+	}
+}
+
+func TestSetupRouter_APIKeyAuth(t *testing.T) {
+	visitorUUID := uuid.NewString()
+	tests := []struct {
+		name       string
+		header     string
+		value      string
+		authErr    error
+		wantAuth   bool
+		wantStatus int
+	}{
+		{
+			name:       "x-api-key header",
+			header:     middlewares.APIKeyHeader,
+			value:      testAPIKeyToken,
+			wantAuth:   true,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "bearer token",
+			header:     "Authorization",
+			value:      "Bearer " + testAPIKeyToken,
+			wantAuth:   true,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "invalid key",
+			header:     middlewares.APIKeyHeader,
+			value:      testAPIKeyToken,
+			authErr:    services.ErrInvalidAPIKey,
+			wantAuth:   true,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "storage error",
+			header:     middlewares.APIKeyHeader,
+			value:      testAPIKeyToken,
+			authErr:    errors.New("boom"),
+			wantAuth:   true,
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "foreign bearer token falls back to cookie",
+			header:     "Authorization",
+			value:      "Bearer something-else",
+			wantStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mocksctrl.NewMockShortURLStore(ctrl)
+			keys := mocksctrl.NewMockAPIKeyManager(ctrl)
+			if tt.wantAuth {
+				keys.EXPECT().Authenticate(gomock.Any(), testAPIKeyToken).Return(visitorUUID, tt.authErr)
+			}
+			if tt.wantStatus == http.StatusNoContent {
+				wantUUID := gomock.Any()
+				if tt.wantAuth {
+					wantUUID = gomock.Eq(visitorUUID)
+				}
+				store.EXPECT().GetAllByVisitorUUID(gomock.Any(), wantUUID).Return(nil, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+			req.Header.Set(tt.header, tt.value)
+			w := httptest.NewRecorder()
+			newTestRouter(store, func(p *RouterParams) { p.APIKeys = keys }).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			// Запросы с ключом API не должны получать cookie посетителя.
+			assert.Equal(t, !tt.wantAuth, w.Header().Get("Set-Cookie") != "")
+			if tt.wantStatus == http.StatusUnauthorized {
+				assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
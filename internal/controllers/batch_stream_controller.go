@@ -0,0 +1,300 @@
+package controllers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// NDJSONContentType тип содержимого потокового пакетного создания URL.
+const NDJSONContentType = "application/x-ndjson"
+
+// Значения по умолчанию для BatchStreamController.
+const (
+	DefaultStreamChunkSize    = 1000      // Количество строк, обрабатываемых одним вызовом BatchCreate
+	DefaultStreamMaxLineBytes = 64 * 1024 // Максимальная длина строки запроса
+)
+
+// Коды ошибок строк потока (StreamLineError.Code), помимо FieldRequired и FieldInvalidURL.
+const (
+	StreamErrInvalidJSON = "invalid_json"  // Строка не является JSON объектом BatchCreateParams
+	StreamErrLineTooLong = "line_too_long" // Строка длиннее допустимого, обработка потока прекращена
+	StreamErrRead        = "read_error"    // Ошибка чтения тела запроса, обработка потока прекращена
+	StreamErrInternal    = "internal"      // Внутренняя ошибка при сохранении
+)
+
+// BatchStreamControllerOptions опции BatchStreamController.
+type BatchStreamControllerOptions struct {
+	ChunkSize    int // Количество строк, обрабатываемых одним вызовом BatchCreate
+	MaxLineBytes int // Максимальная длина строки запроса
+}
+
+// BatchStreamController обрабатывает потоковое пакетное создание коротких URL в формате NDJSON.
+// В отличие от ShortURLController.BatchCreate ни запрос, ни ответ целиком в памяти не хранятся.
+type BatchStreamController struct {
+	urlService   ShortURLStore
+	baseURL      string
+	chunkSize    int
+	maxLineBytes int
+}
+
+// NewBatchStreamController создает новый экземпляр BatchStreamController.
+//
+// Параметры:
+//   - urlService: сервис для работы с URL
+//   - baseURL: базовый URL для генерации коротких ссылок
+//   - opts: функции для настройки опций
+//
+// Возвращает:
+//   - *BatchStreamController: новый экземпляр контроллера
+func NewBatchStreamController(
+	urlService ShortURLStore,
+	baseURL string,
+	opts ...func(*BatchStreamControllerOptions),
+) *BatchStreamController {
+	options := BatchStreamControllerOptions{
+		ChunkSize:    DefaultStreamChunkSize,
+		MaxLineBytes: DefaultStreamMaxLineBytes,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &BatchStreamController{
+		urlService:   urlService,
+		baseURL:      baseURL,
+		chunkSize:    max(options.ChunkSize, 1),
+		maxLineBytes: options.MaxLineBytes,
+	}
+}
+
+// StreamLineError ошибка обработки строки потока.
+type StreamLineError struct {
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// StreamLineResult результат обработки одной строки потока.
+type StreamLineResult struct {
+	Line          int              `json:"line"` // Номер строки запроса, начиная с 1
+	CorrelationID string           `json:"correlation_id,omitempty"`
+	ShortURL      string           `json:"short_url,omitempty"`
+	Created       bool             `json:"created"` // false, если URL был сокращен ранее или произошла ошибка
+	Error         *StreamLineError `json:"error,omitempty"`
+}
+
+// streamItem строка потока, ожидающая сохранения.
+type streamItem struct {
+	result StreamLineResult
+	url    string
+}
+
+// Stream создает короткие URL из потока NDJSON.
+// Каждая строка запроса - JSON объект BatchCreateParams. Строки обрабатываются порциями
+// по ChunkSize через BatchCreate, результаты порции отправляются клиенту сразу после сохранения
+// в порядке строк запроса. Ошибки сообщаются в строке результата, пустые строки пропускаются.
+// Тело запроса читается после начала ответа, поэтому для HTTP/1.x включается полнодуплексный режим
+// (http.ResponseController.EnableFullDuplex), без которого сервер закрывает тело запроса.
+//
+// Коды ответа:
+//   - 200: поток результатов в формате NDJSON (StreamLineResult)
+//   - 401: пользователь не авторизован
+//   - 415: тип содержимого запроса не application/x-ndjson
+//   - 500: сервер не поддерживает одновременное чтение запроса и запись ответа
+func (s *BatchStreamController) Stream(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	if !isNDJSONRequest(c) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "only " + NDJSONContentType + " is supported"})
+		return
+	}
+
+	if err := enableFullDuplex(c); err != nil {
+		_ = c.Error(fmt.Errorf("stream batch create: %w", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+		return
+	}
+
+	c.Header("Content-Type", NDJSONContentType)
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+
+	scanner := bufio.NewScanner(c.Request.Body)
+	scanner.Buffer(make([]byte, 0, min(s.maxLineBytes, bufio.MaxScanTokenSize)), s.maxLineBytes)
+	enc := json.NewEncoder(c.Writer)
+
+	chunk := make([]streamItem, 0, s.chunkSize)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		chunk = append(chunk, parseStreamLine(lineNo, line))
+		if len(chunk) < s.chunkSize {
+			continue
+		}
+		if err := s.flushChunk(c, enc, visitorUUID, chunk); err != nil {
+			_ = c.Error(fmt.Errorf("stream batch create: %w", err))
+			return
+		}
+		chunk = chunk[:0]
+	}
+
+	if scanErr := scanner.Err(); scanErr != nil {
+		lineErr := &StreamLineError{Code: StreamErrRead, Detail: "read request body failed"}
+		if errors.Is(scanErr, bufio.ErrTooLong) {
+			lineErr = &StreamLineError{
+				Code:   StreamErrLineTooLong,
+				Detail: fmt.Sprintf("line exceeds %d bytes", s.maxLineBytes),
+			}
+		} else {
+			_ = c.Error(fmt.Errorf("stream batch create: read body: %w", scanErr))
+		}
+		chunk = append(chunk, streamItem{result: StreamLineResult{Line: lineNo + 1, Error: lineErr}})
+	}
+	if err := s.flushChunk(c, enc, visitorUUID, chunk); err != nil {
+		_ = c.Error(fmt.Errorf("stream batch create: %w", err))
+	}
+}
+
+// flushChunk сохраняет корректные строки порции и отправляет результаты всех строк порции.
+//
+// Параметры:
+//   - c: контекст gin
+//   - enc: кодировщик ответа
+//   - visitorUUID: UUID посетителя
+//   - chunk: строки порции
+//
+// Возвращает:
+//   - error: ошибка отправки ответа (клиент отключился), дальнейшая обработка бессмысленна
+func (s *BatchStreamController) flushChunk(
+	c *gin.Context,
+	enc *json.Encoder,
+	visitorUUID string,
+	chunk []streamItem,
+) error {
+	if len(chunk) == 0 {
+		return nil
+	}
+	if err := c.Request.Context().Err(); err != nil {
+		return fmt.Errorf("request canceled: %w", err)
+	}
+
+	var pending []int
+	var rawURLs []string
+	for i, item := range chunk {
+		if item.result.Error == nil {
+			pending = append(pending, i)
+			rawURLs = append(rawURLs, item.url)
+		}
+	}
+
+	if len(rawURLs) > 0 {
+		s.createChunk(c, visitorUUID, chunk, pending, rawURLs)
+	}
+
+	for _, item := range chunk {
+		if err := enc.Encode(item.result); err != nil {
+			return fmt.Errorf("write result: %w", err)
+		}
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+// createChunk сохраняет URL порции и заполняет результаты соответствующих строк.
+//
+// Параметры:
+//   - c: контекст gin
+//   - visitorUUID: UUID посетителя
+//   - chunk: строки порции
+//   - pending: индексы корректных строк в chunk
+//   - rawURLs: URL корректных строк в том же порядке
+func (s *BatchStreamController) createChunk(
+	c *gin.Context,
+	visitorUUID string,
+	chunk []streamItem,
+	pending []int,
+	rawURLs []string,
+) {
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	batchResponse, err := s.urlService.BatchCreate(ctx, visitorUUID, rawURLs)
+	if err != nil {
+		_ = c.Error(fmt.Errorf("batch create urls: %w", err))
+		for _, idx := range pending {
+			chunk[idx].result.Error = &StreamLineError{Code: StreamErrInternal, Detail: ErrInternal.Error()}
+		}
+		return
+	}
+
+	batchResponse.ReadResponse(func(i int, m models.URL, err error) {
+		if i >= len(pending) {
+			return
+		}
+		result := &chunk[pending[i]].result
+		if err != nil && !errors.Is(err, services.ErrDuplicateKey) {
+			_ = c.Error(fmt.Errorf("batch create url: %w", err))
+			result.Error = &StreamLineError{Code: StreamErrInternal, Detail: ErrInternal.Error()}
+			return
+		}
+		result.ShortURL = buildShortURL(s.baseURL, c.Request, m.ShortIdentifier)
+		result.Created = err == nil
+	})
+}
+
+// parseStreamLine разбирает и проверяет строку потока.
+//
+// Параметры:
+//   - lineNo: номер строки
+//   - line: содержимое строки
+//
+// Возвращает:
+//   - streamItem: строка, ожидающая сохранения, или строка с заполненной ошибкой
+func parseStreamLine(lineNo int, line []byte) streamItem {
+	item := streamItem{result: StreamLineResult{Line: lineNo}}
+
+	var params BatchCreateParams
+	if err := json.Unmarshal(line, &params); err != nil {
+		item.result.Error = &StreamLineError{Code: StreamErrInvalidJSON, Detail: "line is not a JSON object"}
+		return item
+	}
+	item.result.CorrelationID = params.CorrelationID
+
+	if fieldErr := urlFieldError("/original_url", params.OriginalURL); fieldErr != nil {
+		item.result.Error = &StreamLineError{Code: fieldErr.Code, Detail: fieldErr.Detail}
+		return item
+	}
+	item.url = params.OriginalURL
+	return item
+}
+
+// enableFullDuplex разрешает читать тело запроса после начала ответа.
+// HTTP/2 поддерживает это всегда, поэтому отсутствие поддержки у его ResponseWriter ошибкой не считается.
+func enableFullDuplex(c *gin.Context) error {
+	err := http.NewResponseController(c.Writer).EnableFullDuplex()
+	if err == nil || (errors.Is(err, http.ErrNotSupported) && c.Request.ProtoMajor >= 2) {
+		return nil
+	}
+	return fmt.Errorf("enable full duplex: %w", err)
+}
+
+// isNDJSONRequest определяет, передано ли тело запроса в формате NDJSON.
+func isNDJSONRequest(c *gin.Context) bool {
+	ct := c.Request.Header.Get("Content-Type")
+	return strings.HasPrefix(ct, NDJSONContentType) || strings.HasPrefix(ct, "application/ndjson")
+}
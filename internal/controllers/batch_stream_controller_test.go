@@ -0,0 +1,258 @@
+package controllers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/middlewares"
+	"github.com/fsdevblog/shorturl/internal/controllers/mocksctrl"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchCreateReturning настраивает мок BatchCreate, возвращающий идентификатор short<N> для каждого URL.
+// URL из duplicates отмечаются как ранее сокращенные.
+func batchCreateReturning(store *mocksctrl.MockShortURLStore, rawURLs []string, duplicates ...string) *gomock.Call {
+	return store.EXPECT().BatchCreate(gomock.Any(), gomock.Any(), rawURLs).
+		DoAndReturn(func(_ any, _ string, urls []string) (*services.BatchCreateShortURLsResponse, error) {
+			res := services.NewBatchExecResponse[models.URL](len(urls))
+			for i, u := range urls {
+				item := services.BatchResponseItem[models.URL]{
+					Item: models.URL{URL: u, ShortIdentifier: "short" + u[len(u)-3:]},
+				}
+				for _, d := range duplicates {
+					if d == u {
+						item.Err = services.ErrDuplicateKey
+					}
+				}
+				res.Set(item, i)
+			}
+			return services.NewBatchExecResponseURL(res), nil
+		})
+}
+
+// readStreamResults разбирает NDJSON ответ, проверяя каждую строку по схеме StreamLineResult.
+func readStreamResults(t *testing.T, body []byte) []StreamLineResult {
+	t.Helper()
+	var results []StreamLineResult
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		assertMatchesSchema(t, "StreamLineResult", scanner.Bytes())
+		var r StreamLineResult
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		results = append(results, r)
+	}
+	require.NoError(t, scanner.Err())
+	return results
+}
+
+// newVisitorRouter создает маршрутизатор, в котором посетитель всегда определен.
+func newVisitorRouter() *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(middlewares.VisitorUUIDKey, "visitor")
+		c.Next()
+	})
+	return router
+}
+
+// startStreamServer запускает настоящий HTTP сервер с обработчиком потока: в отличие от
+// httptest.ResponseRecorder он закрывает тело запроса после начала ответа без полнодуплексного режима.
+func startStreamServer(t *testing.T, store ShortURLStore, opts ...func(*BatchStreamControllerOptions)) string {
+	t.Helper()
+	router := newVisitorRouter()
+	router.POST("/stream", NewBatchStreamController(store, "http://test.com", opts...).Stream)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv.URL + "/stream"
+}
+
+// postStream отправляет поток NDJSON и возвращает ответ.
+func postStream(t *testing.T, target string, body io.Reader, header http.Header) *http.Response {
+	t.Helper()
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, target, body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", NDJSONContentType)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = res.Body.Close() })
+	return res
+}
+
+func TestBatchStreamController_Stream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mocksctrl.NewMockShortURLStore(ctrl)
+
+	// Порции по 3 строки: некорректные строки занимают место в порции, но не уходят в BatchCreate.
+	gomock.InOrder(
+		batchCreateReturning(store, []string{"https://a.com/001", "https://a.com/002"}, "https://a.com/002"),
+		batchCreateReturning(store, []string{"https://a.com/004"}),
+		store.EXPECT().BatchCreate(gomock.Any(), gomock.Any(), []string{"https://a.com/006"}).
+			Return(nil, errors.New("db is down")),
+	)
+
+	body := strings.Join([]string{
+		`{"correlation_id":"1","original_url":"https://a.com/001"}`,
+		`{"correlation_id":"2","original_url":"https://a.com/002"}`,
+		`not json`,
+		``,
+		`{"correlation_id":"4","original_url":"https://a.com/004"}`,
+		`{"correlation_id":"5","original_url":"ftp://a.com/005"}`,
+		`{"correlation_id":"6"}`,
+		`{"correlation_id":"7","original_url":"https://a.com/006"}`,
+	}, "\n")
+
+	target := startStreamServer(t, store, func(o *BatchStreamControllerOptions) { o.ChunkSize = 3 })
+	res := postStream(t, target, strings.NewReader(body), nil)
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, NDJSONContentType, res.Header.Get("Content-Type"))
+
+	raw, err := readBody(res.Body, false)
+	require.NoError(t, err)
+	results := readStreamResults(t, raw)
+	require.Len(t, results, 7)
+
+	assert.Equal(t, StreamLineResult{Line: 1, CorrelationID: "1", ShortURL: "http://test.com/short001", Created: true},
+		results[0])
+	assert.Equal(t, StreamLineResult{Line: 2, CorrelationID: "2", ShortURL: "http://test.com/short002"}, results[1])
+
+	wantErrors := map[int]string{
+		2: StreamErrInvalidJSON,
+		4: FieldInvalidURL,
+		5: FieldRequired,
+		6: StreamErrInternal,
+	}
+	for i, code := range wantErrors {
+		require.NotNil(t, results[i].Error, "строка %d", results[i].Line)
+		assert.Equal(t, code, results[i].Error.Code)
+		assert.False(t, results[i].Created)
+		assert.Empty(t, results[i].ShortURL)
+	}
+	assert.Equal(t, 3, results[2].Line)
+	assert.Equal(t, 5, results[3].Line, "пустая строка пропускается, но учитывается в нумерации")
+	assert.True(t, results[3].Created)
+	assert.NotContains(t, string(raw), "db is down")
+}
+
+func TestBatchStreamController_LargeStream(t *testing.T) {
+	const lines = 20000
+
+	ctrl := gomock.NewController(t)
+	store := mocksctrl.NewMockShortURLStore(ctrl)
+	store.EXPECT().BatchCreate(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ any, _ string, urls []string) (*services.BatchCreateShortURLsResponse, error) {
+			res := services.NewBatchExecResponse[models.URL](len(urls))
+			for i, u := range urls {
+				res.Set(services.BatchResponseItem[models.URL]{Item: models.URL{URL: u, ShortIdentifier: u[len(u)-8:]}}, i)
+			}
+			return services.NewBatchExecResponseURL(res), nil
+		}).
+		Times(lines / DefaultStreamChunkSize)
+
+	// Тело пишется по мере чтения ответа: первые результаты приходят клиенту,
+	// пока большая часть запроса еще не отправлена.
+	pr, pw := io.Pipe()
+	go func() {
+		for i := range lines {
+			if _, err := fmt.Fprintf(pw, `{"correlation_id":"%d","original_url":"https://a.com/%08d"}`+"\n", i, i); err != nil {
+				return
+			}
+		}
+		_ = pw.Close()
+	}()
+
+	res := postStream(t, startStreamServer(t, store), pr, nil)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	scanner := bufio.NewScanner(res.Body)
+	n := 0
+	for scanner.Scan() {
+		var r StreamLineResult
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		require.Nil(t, r.Error, "строка %d", r.Line)
+		require.Equal(t, n+1, r.Line)
+		require.True(t, r.Created)
+		n++
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, lines, n)
+}
+
+func TestBatchStreamController_LineTooLong(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mocksctrl.NewMockShortURLStore(ctrl)
+	batchCreateReturning(store, []string{"https://a.com/001"})
+
+	body := `{"correlation_id":"1","original_url":"https://a.com/001"}` + "\n" +
+		`{"correlation_id":"2","original_url":"https://a.com/` + strings.Repeat("x", 200) + `"}` + "\n" +
+		`{"correlation_id":"3","original_url":"https://a.com/003"}`
+
+	target := startStreamServer(t, store, func(o *BatchStreamControllerOptions) { o.MaxLineBytes = 100 })
+	res := postStream(t, target, strings.NewReader(body), nil)
+
+	raw, err := readBody(res.Body, false)
+	require.NoError(t, err)
+	results := readStreamResults(t, raw)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Created)
+	require.NotNil(t, results[1].Error)
+	assert.Equal(t, StreamErrLineTooLong, results[1].Error.Code)
+	assert.Equal(t, 2, results[1].Line)
+}
+
+func TestBatchStreamController_Router(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mocksctrl.NewMockShortURLStore(ctrl)
+	batchCreateReturning(store, []string{"https://a.com/001"})
+	srv := httptest.NewServer(newTestRouter(store))
+	defer srv.Close()
+	target := srv.URL + "/api/shorten/stream"
+
+	// Неподдерживаемый тип содержимого.
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten/stream", strings.NewReader(`[]`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	srv.Config.Handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assertResponseMatchesSpec(t, req, w.Result())
+
+	// Сжатые запрос и ответ: полнодуплексный режим включается сквозь обертку сжатия.
+	var gzBody bytes.Buffer
+	gzw := gzip.NewWriter(&gzBody)
+	_, err := gzw.Write([]byte(`{"correlation_id":"1","original_url":"https://a.com/001"}` + "\n"))
+	require.NoError(t, err)
+	require.NoError(t, gzw.Close())
+
+	res := postStream(t, target, &gzBody, http.Header{
+		"Content-Encoding": {"gzip"},
+		"Accept-Encoding":  {"gzip"},
+	})
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "gzip", res.Header.Get("Content-Encoding"))
+	assertResponseMatchesSpec(t, httptest.NewRequest(http.MethodPost, "/api/shorten/stream", nil), res)
+
+	body, err := unGzip(res.Body)
+	require.NoError(t, err)
+	results := readStreamResults(t, body)
+	require.Len(t, results, 1)
+	assert.Equal(t, "1", results[0].CorrelationID)
+	assert.True(t, results[0].Created)
+}
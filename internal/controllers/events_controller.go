@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/middlewares"
+	"github.com/fsdevblog/shorturl/internal/events"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultHeartbeatInterval интервал отправки heartbeat событий в SSE потоке по умолчанию.
+const DefaultHeartbeatInterval = 15 * time.Second
+
+// EventsControllerOptions опции контроллера событий.
+type EventsControllerOptions struct {
+	HeartbeatInterval time.Duration // Интервал отправки heartbeat событий
+}
+
+// EventsController отдает события ссылок посетителя в формате Server-Sent Events.
+type EventsController struct {
+	subscriber EventSubscriber
+	baseURL    string
+	heartbeat  time.Duration
+}
+
+// NewEventsController создает новый экземпляр EventsController.
+//
+// Параметры:
+//   - subscriber: источник событий
+//   - baseURL: базовый URL для генерации коротких ссылок
+//   - opts: функции для настройки опций
+//
+// Возвращает:
+//   - *EventsController: новый экземпляр контроллера
+func NewEventsController(
+	subscriber EventSubscriber,
+	baseURL string,
+	opts ...func(*EventsControllerOptions),
+) *EventsController {
+	options := EventsControllerOptions{HeartbeatInterval: DefaultHeartbeatInterval}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &EventsController{
+		subscriber: subscriber,
+		baseURL:    baseURL,
+		heartbeat:  options.HeartbeatInterval,
+	}
+}
+
+// URLEventResponse данные SSE события о ссылке.
+type URLEventResponse struct {
+	ShortURL    string    `json:"short_url"`
+	OriginalURL string    `json:"original_url,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// Stream отдает поток событий о ссылках текущего посетителя.
+// Имя SSE события совпадает с типом события (url.created, url.clicked, ...).
+// Периодически отправляется событие ping, чтобы соединение не закрывалось прокси.
+// Поток завершается при отключении клиента или остановке хаба событий.
+//
+// Коды ответа:
+//   - 200: поток событий
+//   - 403: отсутствует или недействителен VisitorUUID
+func (e *EventsController) Stream(c *gin.Context) {
+	vu, _ := c.Get(middlewares.VisitorUUIDKey)
+	visitorUUID, vOK := vu.(string)
+	if !vOK {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	sub := e.subscriber.Subscribe(visitorUUID)
+	defer sub.Close()
+
+	ticker := time.NewTicker(e.heartbeat)
+	defer ticker.Stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // отключаем буферизацию nginx
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case ev, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			c.SSEvent(string(ev.Type), e.eventResponse(c.Request, ev))
+		case t := <-ticker.C:
+			c.SSEvent("ping", t.Unix())
+		}
+		c.Writer.Flush()
+	}
+}
+
+// eventResponse формирует данные SSE события.
+func (e *EventsController) eventResponse(r *http.Request, ev events.Event) URLEventResponse {
+	return URLEventResponse{
+		ShortURL:    buildShortURL(e.baseURL, r, ev.ShortIdentifier),
+		OriginalURL: ev.URL,
+		OccurredAt:  ev.OccurredAt,
+	}
+}
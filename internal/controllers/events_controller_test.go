@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/events"
+	"github.com/fsdevblog/shorturl/internal/logs"
+	"github.com/fsdevblog/shorturl/internal/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventsController_Stream(t *testing.T) {
+	hub := events.NewHub()
+	defer hub.Close()
+
+	router := newTestRouter(nil, func(p *RouterParams) {
+		p.Events = hub
+		p.Logger = logs.MustNew(func(o *logs.LoggerOptions) {
+			o.Level = logs.LevelTypeError
+		})
+	})
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	visitorUUID := "8c0a5fa4-5a5e-4a43-a3c1-2b0d5d0c2a11"
+	token, tokenErr := tokens.GenerateVisitorJWT(visitorUUID, time.Hour, []byte(jwtSecret))
+	require.NoError(t, tokenErr)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/user/urls/stream", nil)
+	require.NoError(t, reqErr)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.AddCookie(&http.Cookie{Name: "visitor", Value: token})
+
+	res, resErr := srv.Client().Do(req)
+	require.NoError(t, resErr)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+	assert.Empty(t, res.Header.Get("Content-Encoding"))
+
+	// Заголовки ответа отправлены, значит подписка уже создана.
+	hub.Publish(events.Event{Type: events.TypeURLClicked, VisitorUUID: "someone else", ShortIdentifier: "aaaaaaaa"})
+	hub.Publish(events.Event{
+		Type:            events.TypeURLClicked,
+		VisitorUUID:     visitorUUID,
+		ShortIdentifier: "12345678",
+		URL:             "https://example.com",
+	})
+
+	reader := bufio.NewReader(res.Body)
+	eventLine, readErr := reader.ReadString('\n')
+	require.NoError(t, readErr)
+	assert.Equal(t, "event:url.clicked\n", eventLine)
+
+	dataLine, readErr := reader.ReadString('\n')
+	require.NoError(t, readErr)
+	assert.True(t, strings.HasPrefix(dataLine, "data:"))
+	assert.Contains(t, dataLine, `"short_url":"http://test.com/12345678"`)
+	assert.Contains(t, dataLine, `"original_url":"https://example.com"`)
+}
+
+func TestEventsController_Heartbeat(t *testing.T) {
+	hub := events.NewHub()
+	defer hub.Close()
+
+	router := newTestRouter(nil)
+	router.GET("/test/stream", NewEventsController(hub, "", func(o *EventsControllerOptions) {
+		o.HeartbeatInterval = 10 * time.Millisecond
+	}).Stream)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/test/stream", nil)
+	require.NoError(t, reqErr)
+	req.Header.Set("Accept", "text/event-stream")
+
+	res, resErr := srv.Client().Do(req)
+	require.NoError(t, resErr)
+
+	eventLine, readErr := bufio.NewReader(res.Body).ReadString('\n')
+	require.NoError(t, readErr)
+	assert.Equal(t, "event:ping\n", eventLine)
+
+	// После отключения клиента подписка должна быть освобождена.
+	require.NoError(t, res.Body.Close())
+	cancel()
+	assert.Eventually(t, func() bool {
+		return hub.Subscribers() == 0
+	}, time.Second, 10*time.Millisecond)
+}
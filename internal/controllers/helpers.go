@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
@@ -17,3 +19,30 @@ func isJSONRequest(ctx *gin.Context) bool {
 	ct := ctx.Request.Header.Get("Content-Type")
 	return strings.HasPrefix(ct, "application/json")
 }
+
+// prefersJSON определяет по заголовку Accept, что клиент предпочитает JSON, а не перенаправление.
+// Без заголовка Accept или с Accept: */* клиент считается браузером.
+func prefersJSON(ctx *gin.Context) bool {
+	return ctx.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON
+}
+
+// buildShortURL формирует полный короткий URL на основе идентификатора.
+// Если базовый адрес не задан, используются схема и хост запроса.
+//
+// Параметры:
+//   - baseURL: базовый адрес коротких ссылок
+//   - r: HTTP запрос
+//   - shortID: короткий идентификатор URL
+//
+// Возвращает:
+//   - string: полный короткий URL
+func buildShortURL(baseURL string, r *http.Request, shortID string) string {
+	var scheme = "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if baseURL == "" {
+		return fmt.Sprintf("%s://%s/%s", scheme, r.Host, shortID)
+	}
+	return fmt.Sprintf("%s/%s", baseURL, shortID)
+}
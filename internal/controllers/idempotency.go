@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// Заголовки идемпотентных запросов.
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"     // Ключ идемпотентности, задается клиентом
+	IdempotentReplayedHeader = "Idempotent-Replayed" // Признак ответа, возвращенного из сохраненных
+)
+
+// MaxIdempotencyKeyLength максимальная длина ключа идемпотентности.
+const MaxIdempotencyKeyLength = 255
+
+// IdempotencyMiddleware создает middleware, возвращающий сохраненный ответ на повтор запроса
+// с тем же заголовком Idempotency-Key вместо его повторного выполнения.
+// Ключи хранятся отдельно для каждого посетителя. Отпечаток запроса включает метод, маршрут и тело,
+// поэтому повтор ключа с другим запросом отклоняется. Ответы 5xx не сохраняются,
+// чтобы клиент мог повторить запрос после внутренней ошибки. Запросы без ключа обрабатываются как обычно.
+//
+// Коды ответа, помимо ответов обработчика:
+//   - 400: ключ длиннее MaxIdempotencyKeyLength
+//   - 409: запрос с тем же ключом еще обрабатывается
+//   - 422: ключ использован с другим запросом
+//   - 500: ошибка хранилища ключей
+//
+// Параметры:
+//   - store: хранилище ответов
+//
+// Возвращает:
+//   - gin.HandlerFunc: middleware функция
+func IdempotencyMiddleware(store IdempotencyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > MaxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("%s must not exceed %d characters", IdempotencyKeyHeader, MaxIdempotencyKeyLength),
+			})
+			return
+		}
+		visitorUUID, ok := visitorUUIDFromContext(c)
+		if !ok {
+			// Обработчик сам ответит 401.
+			c.Next()
+			return
+		}
+
+		body, readErr := io.ReadAll(c.Request.Body)
+		if readErr != nil {
+			_ = c.Error(fmt.Errorf("idempotency: read body: %w", readErr))
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+		record, err := store.Begin(ctx, visitorUUID, key, idempotencyFingerprint(c.Request.Method, c.FullPath(), body))
+		cancel()
+		switch {
+		case errors.Is(err, services.ErrIdempotencyKeyReused):
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+				"error": IdempotencyKeyHeader + " was already used with a different request",
+			})
+			return
+		case errors.Is(err, services.ErrIdempotencyInProgress):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+				"error": "a request with this " + IdempotencyKeyHeader + " is still in progress",
+			})
+			return
+		case err != nil:
+			_ = c.Error(fmt.Errorf("idempotency: begin: %w", err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+			return
+		case record != nil:
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(record.StatusCode, record.ContentType, record.Body)
+			c.Abort()
+			return
+		}
+
+		// Ключ освобождается, если ответ не сохранен: при ошибке сервера, ошибке сохранения
+		// или панике обработчика, чтобы клиент мог повторить запрос, не дожидаясь истечения резерва.
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			releaseCtx, releaseCancel := context.WithTimeout(context.WithoutCancel(c), DefaultRequestTimeout)
+			defer releaseCancel()
+			if releaseErr := store.Release(releaseCtx, visitorUUID, key); releaseErr != nil {
+				_ = c.Error(fmt.Errorf("idempotency: release: %w", releaseErr))
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+
+		// Ответ сохраняем и после отключения клиента: именно в этом случае он повторит запрос.
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(c), DefaultRequestTimeout)
+		defer cancel()
+
+		contentType := recorder.Header().Get("Content-Type")
+		completeErr := store.Complete(ctx, visitorUUID, key, status, contentType, recorder.body.Bytes())
+		if completeErr != nil {
+			_ = c.Error(fmt.Errorf("idempotency: complete: %w", completeErr))
+			return
+		}
+		completed = true
+	}
+}
+
+// idempotencyFingerprint вычисляет отпечаток запроса.
+//
+// Параметры:
+//   - method: HTTP метод
+//   - route: шаблон маршрута
+//   - body: тело запроса
+//
+// Возвращает:
+//   - string: SHA-256 в шестнадцатеричном виде
+func idempotencyFingerprint(method, route string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(route))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder обертка над gin.ResponseWriter, копирующая тело ответа.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write записывает данные в ответ и в копию тела.
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data) //nolint:wrapcheck
+}
+
+// WriteString записывает строку в ответ и в копию тела.
+// Запись идет через Write, чтобы не миновать Write вложенных оберток (например, сжатия).
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	return r.Write([]byte(s))
+}
@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/mocksctrl"
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories/memstore"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/fsdevblog/shorturl/internal/tokens"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	const visitorUUID = "0b6f3e52-8f1d-4a39-9d53-6f3c2a7e9b10"
+	token, tokenErr := tokens.GenerateVisitorJWT(visitorUUID, time.Hour, []byte(jwtSecret))
+	require.NoError(t, tokenErr)
+
+	type request struct {
+		key  string
+		body string
+	}
+	tests := []struct {
+		name         string
+		requests     []request
+		createErrs   []error // Ошибки последовательных вызовов Create
+		wantCodes    []int
+		wantReplayed []bool
+	}{
+		{
+			name:         "replay returns stored response",
+			requests:     []request{{key: "k1", body: "https://a.com"}, {key: "k1", body: "https://a.com"}},
+			createErrs:   []error{nil},
+			wantCodes:    []int{http.StatusCreated, http.StatusCreated},
+			wantReplayed: []bool{false, true},
+		},
+		{
+			name:         "key reused with another body",
+			requests:     []request{{key: "k1", body: "https://a.com"}, {key: "k1", body: "https://b.com"}},
+			createErrs:   []error{nil},
+			wantCodes:    []int{http.StatusCreated, http.StatusUnprocessableEntity},
+			wantReplayed: []bool{false, false},
+		},
+		{
+			name:         "server error releases key",
+			requests:     []request{{key: "k1", body: "https://a.com"}, {key: "k1", body: "https://a.com"}},
+			createErrs:   []error{errors.New("db is down"), nil},
+			wantCodes:    []int{http.StatusInternalServerError, http.StatusCreated},
+			wantReplayed: []bool{false, false},
+		},
+		{
+			name:         "no key",
+			requests:     []request{{body: "https://a.com"}, {body: "https://a.com"}},
+			createErrs:   []error{nil, nil},
+			wantCodes:    []int{http.StatusCreated, http.StatusCreated},
+			wantReplayed: []bool{false, false},
+		},
+		{
+			name:         "key too long",
+			requests:     []request{{key: strings.Repeat("k", MaxIdempotencyKeyLength+1), body: "https://a.com"}},
+			wantCodes:    []int{http.StatusBadRequest},
+			wantReplayed: []bool{false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mocksctrl.NewMockShortURLStore(ctrl)
+			for _, createErr := range tt.createErrs {
+				store.EXPECT().Create(gomock.Any(), visitorUUID, gomock.Any()).
+					Return(&models.URL{ShortIdentifier: "abc"}, true, createErr)
+			}
+
+			router := newTestRouter(store, func(p *RouterParams) {
+				p.Idempotency = services.NewIdempotencyService(memstore.NewIdempotencyRepo(db.NewMemStorage()))
+			})
+
+			var firstBody string
+			for i, r := range tt.requests {
+				req := httptest.NewRequest(http.MethodPost, "/api/shorten",
+					strings.NewReader(`{"url":"`+r.body+`"}`))
+				req.Header.Set("Content-Type", "application/json")
+				req.AddCookie(&http.Cookie{Name: "visitor", Value: token})
+				if r.key != "" {
+					req.Header.Set(IdempotencyKeyHeader, r.key)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				assert.Equal(t, tt.wantCodes[i], w.Code, "запрос %d", i)
+				assertResponseMatchesSpec(t, req, w.Result())
+				if tt.wantReplayed[i] {
+					assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+					assert.Equal(t, firstBody, w.Body.String())
+				} else {
+					assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+				}
+				if i == 0 {
+					firstBody = w.Body.String()
+				}
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddleware_InProgress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mocksctrl.NewMockShortURLStore(ctrl)
+	idempotency := mocksctrl.NewMockIdempotencyStore(ctrl)
+	idempotency.EXPECT().Begin(gomock.Any(), gomock.Any(), "k1", gomock.Any()).
+		Return(nil, services.ErrIdempotencyInProgress)
+
+	router := newTestRouter(store, func(p *RouterParams) { p.Idempotency = idempotency })
+
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten/batch",
+		strings.NewReader(`[{"correlation_id":"1","original_url":"https://a.com"}]`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IdempotencyKeyHeader, "k1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assertResponseMatchesSpec(t, req, w.Result())
+}
+
+func TestIdempotencyMiddleware_PanicReleasesKey(t *testing.T) {
+	const visitorUUID = "0b6f3e52-8f1d-4a39-9d53-6f3c2a7e9b10"
+	ctrl := gomock.NewController(t)
+	store := mocksctrl.NewMockShortURLStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().Create(gomock.Any(), visitorUUID, gomock.Any()).
+			DoAndReturn(func(context.Context, string, string) (*models.URL, bool, error) {
+				panic("handler panic")
+			}),
+		store.EXPECT().Create(gomock.Any(), visitorUUID, gomock.Any()).
+			Return(&models.URL{ShortIdentifier: "abc"}, true, nil),
+	)
+
+	router := newTestRouter(store, func(p *RouterParams) {
+		p.Idempotency = services.NewIdempotencyService(memstore.NewIdempotencyRepo(db.NewMemStorage()))
+	})
+
+	// После паники ключ освобожден, и повтор выполняется, а не получает 409.
+	for _, wantCode := range []int{http.StatusInternalServerError, http.StatusCreated} {
+		req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url":"https://a.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(IdempotencyKeyHeader, "k1")
+		req.AddCookie(visitorCookie(t, visitorUUID))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, wantCode, w.Code)
+	}
+}
+
+func TestIdempotencyMiddleware_LockExpires(t *testing.T) {
+	const visitorUUID = "0b6f3e52-8f1d-4a39-9d53-6f3c2a7e9b10"
+	tests := []struct {
+		name       string
+		reservedAt time.Duration // Насколько раньше текущего момента ключ зарезервирован незавершенным запросом
+		wantCode   int
+	}{
+		{name: "lock held", reservedAt: 0, wantCode: http.StatusConflict},
+		{name: "lock expired", reservedAt: 2 * services.DefaultIdempotencyLockTTL, wantCode: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mocksctrl.NewMockShortURLStore(ctrl)
+			if tt.wantCode == http.StatusCreated {
+				store.EXPECT().Create(gomock.Any(), visitorUUID, gomock.Any()).
+					Return(&models.URL{ShortIdentifier: "abc"}, true, nil)
+			}
+			repo := memstore.NewIdempotencyRepo(db.NewMemStorage())
+
+			// Запрос, обработка которого прервалась без сохранения ответа и освобождения ключа.
+			crashed := services.NewIdempotencyService(repo, func(o *services.IdempotencyServiceOptions) {
+				o.Now = func() time.Time { return time.Now().Add(-tt.reservedAt) }
+			})
+			body := `{"url":"https://a.com"}`
+			_, err := crashed.Begin(t.Context(), visitorUUID, "k1",
+				idempotencyFingerprint(http.MethodPost, "/api/shorten", []byte(body)))
+			require.NoError(t, err)
+
+			router := newTestRouter(store, func(p *RouterParams) { p.Idempotency = services.NewIdempotencyService(repo) })
+			req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(IdempotencyKeyHeader, "k1")
+			req.AddCookie(visitorCookie(t, visitorUUID))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
@@ -2,8 +2,11 @@ package controllers
 
 import (
 	"context"
+	"time"
 
+	"github.com/fsdevblog/shorturl/internal/events"
 	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
 	"github.com/fsdevblog/shorturl/internal/services"
 )
 
@@ -24,10 +27,152 @@ type ShortURLStore interface {
 	Create(ctx context.Context, visitorUUID string, rawURL string) (*models.URL, bool, error)
 	// GetByShortIdentifier возвращает оригинальный URL по его короткому идентификатору.
 	GetByShortIdentifier(ctx context.Context, shortID string) (*models.URL, error)
-	// GetByURL ищет запись по её URL.
-	GetByURL(ctx context.Context, rawURL string) (*models.URL, error)
+	// Visit возвращает URL для перехода по короткой ссылке и фиксирует переход.
+	Visit(ctx context.Context, shortID string) (*models.URL, error)
+	// GetByURL ищет запись посетителя по её URL.
+	GetByURL(ctx context.Context, visitorUUID string, rawURL string) (*models.URL, error)
+	// Resolve возвращает состояния и записи нескольких коротких идентификаторов в порядке запроса.
+	Resolve(ctx context.Context, shortIDs []string) ([]services.ResolveResult, error)
 	// GetAllByVisitorUUID возвращает все URL, созданные определенным посетителем.
 	GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.URL, error)
-	// MarkAsDeleted помечает указанные URL как удаленные.
-	MarkAsDeleted(ctx context.Context, shortIDs []string, visitorUUID string) error
+	// MarkAsDeleted помечает указанные URL как удаленные. Возвращает удаленные и не найденные идентификаторы.
+	MarkAsDeleted(ctx context.Context, shortIDs []string, visitorUUID string) (*services.DeleteResult, error)
+	// Import импортирует URL с необязательными алиасами и метками. Результаты в порядке строк.
+	Import(
+		ctx context.Context,
+		visitorUUID string,
+		rows []services.ImportRow,
+	) (*services.BatchCreateShortURLsResponse, error)
+	// ExportByVisitorUUID обходит все URL посетителя, не загружая их в память целиком.
+	ExportByVisitorUUID(ctx context.Context, visitorUUID string, fn func(models.URL) error) error
+}
+
+// EventSubscriber определяет интерфейс подписки на события ссылок посетителя.
+type EventSubscriber interface {
+	// Subscribe подписывает на события ссылок посетителя. Подписку необходимо закрыть.
+	Subscribe(visitorUUID string) *events.Subscription
+}
+
+// WebhookManager определяет интерфейс управления вебхуками посетителя.
+type WebhookManager interface {
+	// Register регистрирует вебхук на указанные типы событий.
+	Register(ctx context.Context, visitorUUID string, rawURL string, eventTypes []string) (*models.Webhook, error)
+	// GetAllByVisitorUUID возвращает вебхуки посетителя.
+	GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.Webhook, error)
+	// Delete удаляет вебхук посетителя.
+	Delete(ctx context.Context, id string, visitorUUID string) error
+	// Deliveries возвращает последние попытки доставки вебхука посетителя.
+	Deliveries(ctx context.Context, id string, visitorUUID string, limit int) ([]models.WebhookDelivery, error)
+}
+
+// StatsProvider определяет интерфейс получения агрегированной статистики сервиса.
+type StatsProvider interface {
+	// Stats возвращает количество неудаленных URL и уникальных посетителей.
+	Stats(ctx context.Context) (*models.Stats, error)
+}
+
+// IdempotencyStore определяет интерфейс хранения ответов на запросы с заголовком Idempotency-Key.
+type IdempotencyStore interface {
+	// Begin начинает обработку запроса. Возвращает сохраненный ответ, если запрос уже выполнен,
+	// или nil, если запрос нужно выполнить.
+	Begin(ctx context.Context, visitorUUID, key, fingerprint string) (*models.IdempotencyRecord, error)
+	// Complete сохраняет ответ на запрос, начатый Begin.
+	Complete(ctx context.Context, visitorUUID, key string, status int, contentType string, body []byte) error
+	// Release освобождает ключ запроса, начатого Begin, без сохранения ответа.
+	Release(ctx context.Context, visitorUUID, key string) error
+}
+
+// DeletionQueue определяет интерфейс фонового удаления ссылок посетителя.
+type DeletionQueue interface {
+	// Enqueue ставит удаление ссылок в очередь и возвращает созданную задачу.
+	Enqueue(ctx context.Context, visitorUUID string, shortIDs []string) (*models.DeletionJob, error)
+	// Job возвращает задачу удаления посетителя.
+	Job(ctx context.Context, visitorUUID string, id string) (*models.DeletionJob, error)
+}
+
+// APIKeyManager определяет интерфейс управления ключами API посетителя.
+type APIKeyManager interface {
+	// Create выпускает ключ. Возвращает модель ключа и ключ целиком, который больше нигде не хранится.
+	Create(ctx context.Context, visitorUUID string, name string) (*models.APIKey, string, error)
+	// GetAllByVisitorUUID возвращает ключи посетителя, включая отозванные.
+	GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.APIKey, error)
+	// Revoke отзывает ключ посетителя.
+	Revoke(ctx context.Context, visitorUUID string, id string) error
+	// Authenticate возвращает UUID посетителя, которому выдан ключ.
+	Authenticate(ctx context.Context, token string) (string, error)
+}
+
+// OIDCAuthenticator определяет интерфейс входа через провайдера OpenID Connect.
+type OIDCAuthenticator interface {
+	// AuthCodeURL формирует адрес страницы входа провайдера.
+	AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error)
+	// Login обменивает код авторизации на UUID пользователя провайдера и передает ему ссылки посетителя.
+	Login(ctx context.Context, visitorUUID, code, verifier, nonce string) (*services.OIDCLoginResult, error)
+}
+
+// AccountManager определяет интерфейс регистрации и входа пользователей.
+type AccountManager interface {
+	// Register создает аккаунт. Анонимный посетитель становится аккаунтом вместе со своими ссылками.
+	Register(ctx context.Context, visitorUUID string, email string, password string) (*models.User, error)
+	// Login проверяет учетные данные и передает аккаунту ссылки анонимного посетителя.
+	Login(ctx context.Context, visitorUUID string, email string, password string) (*services.LoginResult, error)
+}
+
+// TokenRevoker определяет интерфейс отзыва токенов посетителей.
+type TokenRevoker interface {
+	// IsRevoked проверяет, отозван ли токен по идентификатору, отзывом его сессии
+	// или отзывом всех сессий посетителя.
+	IsRevoked(ctx context.Context, visitorUUID string, jti string, sessionID string, issuedAt time.Time) (bool, error)
+	// Revoke отзывает токен посетителя по его идентификатору (jti) или сессию по ее идентификатору (sid).
+	Revoke(ctx context.Context, visitorUUID string, jti string) error
+	// RevokeAll отзывает все выпущенные к этому моменту токены посетителя.
+	RevokeAll(ctx context.Context, visitorUUID string) error
+}
+
+// AdminManager определяет интерфейс модерации ссылок и посетителей администратором.
+type AdminManager interface {
+	// SearchURLs ищет ссылки всех посетителей, включая удаленные и отключенные.
+	SearchURLs(ctx context.Context, filter repositories.URLSearchFilter) ([]models.URL, error)
+	// DisableURL отключает ссылку с указанной причиной.
+	DisableURL(ctx context.Context, actorUUID, shortID, reason string, legal bool) (*models.URL, error)
+	// EnableURL снимает отключение ссылки.
+	EnableURL(ctx context.Context, actorUUID, shortID string) (*models.URL, error)
+	// BanVisitor блокирует посетителя и, если disableLinks, отключает его ссылки.
+	BanVisitor(ctx context.Context, actorUUID, visitorUUID, reason string, disableLinks bool) (*services.BanResult, error)
+	// UnbanVisitor снимает блокировку посетителя.
+	UnbanVisitor(ctx context.Context, actorUUID, visitorUUID string) error
+	// IsBanned проверяет, заблокирован ли посетитель.
+	IsBanned(ctx context.Context, visitorUUID string) (bool, error)
+	// AuditLog возвращает записи журнала аудита от новых к старым.
+	AuditLog(ctx context.Context, filter repositories.AuditFilter) ([]models.AuditEntry, error)
+}
+
+// WorkspaceManager определяет интерфейс рабочих пространств и ссылок команд.
+type WorkspaceManager interface {
+	// Create создает рабочее пространство, создатель становится владельцем.
+	Create(ctx context.Context, visitorUUID, name string) (*models.Workspace, error)
+	// List возвращает пространства посетителя с его ролью в каждом.
+	List(ctx context.Context, visitorUUID string) ([]models.WorkspaceMembership, error)
+	// Get возвращает пространство с участниками и ролью посетителя.
+	Get(ctx context.Context, visitorUUID, workspaceID string) (*services.WorkspaceDetails, error)
+	// SetMember добавляет участника пространства или меняет его роль.
+	SetMember(
+		ctx context.Context,
+		actorUUID, workspaceID, visitorUUID string,
+		role models.WorkspaceRole,
+	) (*models.WorkspaceMember, error)
+	// RemoveMember удаляет участника пространства.
+	RemoveMember(ctx context.Context, actorUUID, workspaceID, visitorUUID string) error
+	// CreateURL сокращает URL в пространстве. Возвращает true, если ссылка создана.
+	CreateURL(ctx context.Context, actorUUID, workspaceID, rawURL string) (*models.URL, bool, error)
+	// ListURLs возвращает ссылки пространства.
+	ListURLs(ctx context.Context, actorUUID, workspaceID string) ([]models.URL, error)
+	// UpdateURL изменяет оригинальный URL и (или) метки ссылки пространства.
+	UpdateURL(
+		ctx context.Context,
+		actorUUID, workspaceID, shortID string,
+		upd repositories.URLUpdate,
+	) (*models.URL, error)
+	// DeleteURL помечает удаленной ссылку пространства.
+	DeleteURL(ctx context.Context, actorUUID, workspaceID, shortID string) error
 }
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// deletionRetryAfter значение заголовка Retry-After (в секундах) при недоступности очереди удаления.
+const deletionRetryAfter = "1"
+
+// JobsController обрабатывает HTTP запросы состояния фоновых задач посетителя.
+type JobsController struct {
+	deletions DeletionQueue
+}
+
+// NewJobsController создает новый экземпляр JobsController.
+//
+// Параметры:
+//   - deletions: очередь фонового удаления
+//
+// Возвращает:
+//   - *JobsController: новый экземпляр контроллера
+func NewJobsController(deletions DeletionQueue) *JobsController {
+	return &JobsController{deletions: deletions}
+}
+
+// DeletionJobResponse структура ответа с состоянием задачи удаления.
+type DeletionJobResponse struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`    // pending, running, completed или failed
+	Requested  int        `json:"requested"` // Количество уникальных идентификаторов в запросе
+	Deleted    int        `json:"deleted"`   // Количество ссылок, помеченных удаленными
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Get возвращает состояние задачи текущего посетителя.
+// Завершенные задачи хранятся ограниченное время, после чего возвращается 404.
+//
+// Параметры URL:
+//   - id: идентификатор задачи
+//
+// Коды ответа:
+//   - 200: состояние задачи (DeletionJobResponse)
+//   - 401: посетитель не определен
+//   - 404: задача не найдена
+//   - 500: внутренняя ошибка сервера
+func (j *JobsController) Get(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	id := c.Param("id")
+	if uuid.Validate(id) != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	job, err := j.deletions.Job(ctx, visitorUUID, id)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		_ = c.Error(fmt.Errorf("get deletion job: %w", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, newDeletionJobResponse(job))
+}
+
+// newDeletionJobResponse преобразует модель задачи удаления в ответ.
+func newDeletionJobResponse(job *models.DeletionJob) DeletionJobResponse {
+	return DeletionJobResponse{
+		ID:         job.ID,
+		Status:     string(job.Status),
+		Requested:  job.Requested,
+		Deleted:    job.Deleted,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
+
+// deletionJobLocation возвращает путь состояния задачи удаления.
+func deletionJobLocation(id string) string {
+	return "/api/jobs/" + id
+}
+
+// isDeletionQueueUnavailable проверяет, что задача не принята из-за переполнения или остановки очереди.
+func isDeletionQueueUnavailable(err error) bool {
+	return errors.Is(err, services.ErrDeletionQueueFull) || errors.Is(err, services.ErrDeletionQueueClosed)
+}
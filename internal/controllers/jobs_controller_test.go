@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/mocksctrl"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testJobID = "5d1c8a34-2f6e-4b7a-9c0d-7e8f9a0b1c2d"
+
+func TestShortURLController_DeleteUserURLsQueued(t *testing.T) {
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name           string
+		url            string
+		enqueueErr     error
+		wantStatus     int
+		wantRetryAfter bool
+	}{
+		{
+			name:       "accepted",
+			url:        "/api/user/urls",
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "accepted v2",
+			url:        "/api/v2/user/urls",
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:           "queue full",
+			url:            "/api/user/urls",
+			enqueueErr:     services.ErrDeletionQueueFull,
+			wantStatus:     http.StatusServiceUnavailable,
+			wantRetryAfter: true,
+		},
+		{
+			name:           "queue closed v2",
+			url:            "/api/v2/user/urls",
+			enqueueErr:     services.ErrDeletionQueueClosed,
+			wantStatus:     http.StatusServiceUnavailable,
+			wantRetryAfter: true,
+		},
+		{
+			name:       "unknown error",
+			url:        "/api/user/urls",
+			enqueueErr: errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mocksctrl.NewMockShortURLStore(ctrl)
+			deletions := mocksctrl.NewMockDeletionQueue(ctrl)
+
+			var job *models.DeletionJob
+			if tt.enqueueErr == nil {
+				job = &models.DeletionJob{
+					ID:        testJobID,
+					Status:    models.DeletionJobPending,
+					Requested: 2,
+					CreatedAt: createdAt,
+				}
+			}
+			deletions.EXPECT().Enqueue(gomock.Any(), gomock.Any(), []string{"aaaaaaaa", "bbbbbbbb"}).
+				Return(job, tt.enqueueErr)
+
+			req := httptest.NewRequest(http.MethodDelete, tt.url, strings.NewReader(`["aaaaaaaa","bbbbbbbb"]`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			newTestRouter(store, func(p *RouterParams) { p.Deletions = deletions }).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+			assert.Equal(t, tt.wantRetryAfter, w.Header().Get("Retry-After") != "")
+			if tt.wantStatus != http.StatusAccepted {
+				return
+			}
+			assert.Equal(t, "/api/jobs/"+testJobID, w.Header().Get("Location"))
+			var res DeletionJobResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			assert.Equal(t, testJobID, res.ID)
+			assert.Equal(t, string(models.DeletionJobPending), res.Status)
+			assert.Equal(t, 2, res.Requested)
+		})
+	}
+}
+
+func TestJobsController_Get(t *testing.T) {
+	finishedAt := time.Date(2025, 1, 2, 3, 4, 6, 0, time.UTC)
+	tests := []struct {
+		name       string
+		id         string
+		job        *models.DeletionJob
+		jobErr     error
+		wantStatus int
+	}{
+		{
+			name: "completed",
+			id:   testJobID,
+			job: &models.DeletionJob{
+				ID:         testJobID,
+				Status:     models.DeletionJobCompleted,
+				Requested:  2,
+				Deleted:    1,
+				CreatedAt:  finishedAt.Add(-time.Second),
+				FinishedAt: &finishedAt,
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "not found",
+			id:         testJobID,
+			jobErr:     services.ErrRecordNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "invalid id",
+			id:         "not-a-uuid",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "storage error",
+			id:         testJobID,
+			jobErr:     errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			deletions := mocksctrl.NewMockDeletionQueue(ctrl)
+			if tt.job != nil || tt.jobErr != nil {
+				deletions.EXPECT().Job(gomock.Any(), gomock.Any(), tt.id).Return(tt.job, tt.jobErr)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/jobs/"+tt.id, nil)
+			w := httptest.NewRecorder()
+			newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) {
+				p.Deletions = deletions
+			}).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+			if tt.job == nil {
+				return
+			}
+			var res DeletionJobResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			assert.Equal(t, 1, res.Deleted)
+			assert.Equal(t, string(models.DeletionJobCompleted), res.Status)
+			require.NotNil(t, res.FinishedAt)
+		})
+	}
+}
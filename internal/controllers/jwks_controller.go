@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/fsdevblog/shorturl/internal/tokens"
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSCacheControl заголовок Cache-Control ответа с открытыми ключами.
+// Новый ключ следует добавлять в набор заранее, чтобы проверяющие сервисы успели обновить кеш.
+const JWKSCacheControl = "public, max-age=300"
+
+// JWKSController контроллер открытых ключей подписи токенов посетителей.
+type JWKSController struct {
+	keyring *tokens.Keyring // Ключи подписи токенов посетителей
+}
+
+// NewJWKSController создает новый экземпляр JWKSController.
+//
+// Параметры:
+//   - keyring: ключи подписи токенов посетителей
+//
+// Возвращает:
+//   - *JWKSController: новый экземпляр контроллера
+func NewJWKSController(keyring *tokens.Keyring) *JWKSController {
+	return &JWKSController{keyring: keyring}
+}
+
+// JWKS обрабатывает GET /.well-known/jwks.json запрос.
+// Отдает открытые ключи EdDSA и RS256, которыми другие сервисы проверяют токены посетителей.
+// Секреты HMAC не публикуются, поэтому при подписи HS256 набор пуст.
+//
+// Коды ответа:
+//   - 200: набор ключей JWKS
+//
+// Параметры:
+//   - c: контекст Gin
+func (j *JWKSController) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", JWKSCacheControl)
+	c.JSON(http.StatusOK, j.keyring.JWKS())
+}
@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/middlewares"
+	"github.com/fsdevblog/shorturl/internal/controllers/mocksctrl"
+	"github.com/fsdevblog/shorturl/internal/tokens"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWKSController_JWKS(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	asymmetric, err := tokens.NewKeyring("ed",
+		tokens.Key{ID: "ed", PrivateKey: edKey},
+		tokens.Key{ID: tokens.DefaultKeyID, Secret: []byte(jwtSecret), ValidUntil: time.Now().Add(time.Hour)},
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		keyring  *tokens.Keyring
+		wantKids []string
+	}{
+		{
+			name:     "public keys without hmac secrets",
+			keyring:  asymmetric,
+			wantKids: []string{"ed"},
+		},
+		{
+			name:     "hmac only",
+			keyring:  tokens.NewStaticKeyring([]byte(jwtSecret)),
+			wantKids: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			router := newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) { p.Keyring = tt.keyring })
+
+			req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+			assert.Equal(t, JWKSCacheControl, w.Header().Get("Cache-Control"))
+			assert.Empty(t, w.Header().Get("Set-Cookie"))
+			assert.NotContains(t, w.Body.String(), jwtSecret)
+
+			var set tokens.JWKSet
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &set))
+			kids := make([]string, 0, len(set.Keys))
+			for _, k := range set.Keys {
+				kids = append(kids, k.KeyID)
+			}
+			assert.Equal(t, tt.wantKids, kids)
+		})
+	}
+}
+
+func TestSetupRouter_AsymmetricVisitorCookie(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keyring, err := tokens.NewKeyring("ed", tokens.Key{ID: "ed", PrivateKey: edKey})
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	store := mocksctrl.NewMockShortURLStore(ctrl)
+	store.EXPECT().GetAllByVisitorUUID(gomock.Any(), gomock.Any()).Return(nil, nil)
+	router := newTestRouter(store, func(p *RouterParams) { p.Keyring = keyring })
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	require.Equal(t, middlewares.VisitorCookieName, cookies[0].Name)
+	token, err := keyring.ValidateVisitorJWT(cookies[0].Value)
+	require.NoError(t, err)
+	assert.Equal(t, "EdDSA", token.Header["alg"])
+
+	// Секрет HS256 из конфигурации не используется, если задан набор ключей.
+	_, err = tokens.ValidateVisitorJWT(cookies[0].Value, []byte(jwtSecret))
+	require.Error(t, err)
+}
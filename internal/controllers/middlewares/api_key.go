@@ -0,0 +1,77 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader заголовок с ключом API.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator описывает проверку ключей API.
+type APIKeyAuthenticator interface {
+	// Authenticate возвращает UUID посетителя, которому выдан ключ.
+	// Для неизвестного, отозванного или неверного ключа возвращает services.ErrInvalidAPIKey.
+	Authenticate(ctx context.Context, token string) (string, error)
+}
+
+// APIKeyMiddleware создает middleware для аутентификации скриптов по ключу API.
+// Ключ передается в заголовке X-API-Key или Authorization: Bearer. Для действительного ключа
+// в контекст записывается UUID посетителя, как это делает VisitorCookieMiddleware, поэтому
+// обработчикам не важно, как посетитель аутентифицирован. Запросы без ключа пропускаются без изменений.
+//
+// Параметры:
+//   - auth: сервис проверки ключей
+//
+// Возвращает:
+//   - gin.HandlerFunc: middleware функция, отвечающая 401 для недействительного ключа
+//
+// Устанавливает в контексте:
+//   - VisitorUUIDKey: UUID посетителя (string)
+func APIKeyMiddleware(auth APIKeyAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := apiKeyFromRequest(c.Request)
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		visitorUUID, err := auth.Authenticate(c, token)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidAPIKey) {
+				c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid api key"})
+				return
+			}
+			_ = c.Error(fmt.Errorf("api key middleware: %w", err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+			return
+		}
+
+		c.Set(VisitorUUIDKey, visitorUUID)
+		c.Next()
+	}
+}
+
+// apiKeyFromRequest возвращает ключ API из заголовков запроса или пустую строку.
+// Из заголовка Authorization берутся только токены с префиксом ключа API.
+func apiKeyFromRequest(r *http.Request) string {
+	if key := strings.TrimSpace(r.Header.Get(APIKeyHeader)); key != "" {
+		return key
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	token = strings.TrimSpace(token)
+	if !strings.HasPrefix(token, services.APIKeyTokenPrefix) {
+		return ""
+	}
+	return token
+}
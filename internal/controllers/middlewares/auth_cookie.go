@@ -1,64 +1,188 @@
 package middlewares
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"slices"
 	"time"
 
 	"github.com/fsdevblog/shorturl/internal/tokens"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
 // VisitorUUIDKey Имя ключа для хранения UUID посетителя.
+// VisitorRolesKey Имя ключа для хранения ролей посетителя из токена.
+// VisitorTokenIDKey Имя ключа для хранения идентификатора (jti) принятого токена посетителя.
+// VisitorSessionIDKey Имя ключа для хранения идентификатора сессии (sid) посетителя.
+// VisitorUnverifiedKey Имя ключа признака того, что отзыв предъявленного токена не удалось проверить.
 // VisitorCookieName Имя куки.
-// VisitorJWTExpireDuration Срок годности JWT ключа.
+// VisitorJWTExpireDuration Срок годности JWT ключа по умолчанию.
+// DefaultVisitorSessionGrace Время после истечения токена, в течение которого он продлевается с тем же UUID.
 const (
-	VisitorUUIDKey           = "visitorUUID"
-	VisitorCookieName        = "visitor"
-	VisitorJWTExpireDuration = 24 * time.Hour
+	VisitorUUIDKey             = "visitorUUID"
+	VisitorRolesKey            = "visitorRoles"
+	VisitorTokenIDKey          = "visitorTokenID"
+	VisitorSessionIDKey        = "visitorSessionID"
+	VisitorUnverifiedKey       = "visitorUnverified"
+	VisitorCookieName          = "visitor"
+	VisitorJWTExpireDuration   = 24 * time.Hour
+	DefaultVisitorSessionGrace = 7 * 24 * time.Hour
 )
 
+// RevocationChecker проверяет, отозван ли токен посетителя.
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, visitorUUID string, jti string, sessionID string, issuedAt time.Time) (bool, error)
+}
+
+// VisitorCookieOptions опции cookie посетителя.
+type VisitorCookieOptions struct {
+	Expire time.Duration // Срок действия токена и cookie
+	// Токен, до истечения которого осталось меньше RefreshBefore, перевыпускается с тем же UUID.
+	// По умолчанию половина Expire.
+	RefreshBefore time.Duration
+	Grace         time.Duration // Истекший не более Grace назад токен продлевается, а не заменяется новым
+	Domain        string        // Домен cookie. Пустое значение - только текущий хост
+	Secure        bool          // Отправлять cookie только по HTTPS
+	SameSite      http.SameSite // Атрибут SameSite. 0 - не указывать
+	// Roles возвращает роли посетителя для нового токена. nil - токены выдаются без ролей.
+	Roles func(visitorUUID string) []string
+	// Revocations проверяет отзыв принятых токенов. nil - токены не отзываются.
+	Revocations RevocationChecker
+}
+
+// roles возвращает роли посетителя, которые должны быть в его токене.
+func (o VisitorCookieOptions) roles(visitorUUID string) []string {
+	if o.Roles == nil {
+		return nil
+	}
+	return o.Roles(visitorUUID)
+}
+
+// revoked проверяет, отозван ли токен. Ошибка проверки возвращается вызывающему, который решает,
+// насколько доверять непроверенному токену.
+func (o VisitorCookieOptions) revoked(c *gin.Context, claims *tokens.VisitorClaims) (bool, error) {
+	if o.Revocations == nil {
+		return false, nil
+	}
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	revoked, err := o.Revocations.IsRevoked(c.Request.Context(), claims.UUID, claims.ID, claims.SessionID, issuedAt)
+	if err != nil {
+		_ = c.Error(fmt.Errorf("visitor cookie middleware: %s", err.Error()))
+		return false, err
+	}
+	if revoked {
+		_ = c.Error(fmt.Errorf("visitor cookie middleware: token %s of visitor %s is revoked", claims.ID, claims.UUID))
+	}
+	return revoked, nil
+}
+
+// newVisitorCookieOptions применяет функции настройки к опциям по умолчанию.
+func newVisitorCookieOptions(opts []func(*VisitorCookieOptions)) VisitorCookieOptions {
+	options := VisitorCookieOptions{
+		Expire: VisitorJWTExpireDuration,
+		Grace:  DefaultVisitorSessionGrace,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.RefreshBefore <= 0 {
+		options.RefreshBefore = options.Expire / 2
+	}
+	return options
+}
+
 // VisitorCookieMiddleware создает middleware для аутентификации посетителей через cookie.
 // Проверяет наличие и валидность JWT токена в cookie. Если токен отсутствует или невалиден,
 // генерирует новый UUID посетителя и создает новый JWT токен.
+// Если посетитель уже определен предыдущим middleware (например, APIKeyMiddleware),
+// cookie не проверяется и не выдается.
+//
+// Сессия скользящая: токен, близкий к истечению или истекший не более Grace назад,
+// перевыпускается с тем же UUID и идентификатором сессии, поэтому активный посетитель
+// не теряет свои ссылки, а отзыв сессии действует и на продленные токены.
+// Токен перевыпускается и тогда, когда его роли разошлись с VisitorCookieOptions.Roles,
+// поэтому выданная или отозванная роль действует со следующего запроса.
+// Отозванный токен (см. VisitorCookieOptions.Revocations) считается невалидным.
+//
+// Если отзыв проверить не удалось (хранилище недоступно), токен принимается частично: посетитель
+// сохраняет UUID и доступ к своим ссылкам, но токен не продлевается, роли не выдаются, а вместо
+// VisitorTokenIDKey устанавливается VisitorUnverifiedKey, поэтому управлять сессиями (выход, отзыв
+// всех токенов) такой токен не может. Отклонять токен целиком нельзя: при сбое хранилища каждый
+// посетитель получал бы новый UUID и терял свои ссылки. Цена такого решения - отозванный токен
+// сохраняет доступ к ссылкам на время сбоя, но не дольше своего срока действия.
 //
 // Алгоритм работы:
 //  1. Проверяет наличие cookie с JWT токеном
-//  2. Если токен есть - проверяет его валидность
-//  3. Если токен отсутствует или невалиден - генерирует новый UUID и JWT токен
-//  4. Сохраняет UUID посетителя в контексте запроса
-//  5. При необходимости устанавливает новую cookie с JWT токеном
+//  2. Если токен есть - проверяет его валидность с учетом Grace и отзыв
+//  3. Если токен близок к истечению или истек в пределах Grace - выпускает новый токен с тем же UUID
+//  4. Если токен отсутствует или невалиден - генерирует новый UUID и JWT токен
+//  5. Сохраняет UUID посетителя в контексте запроса
+//  6. При необходимости устанавливает новую cookie с JWT токеном
 //
 // Параметры:
-//   - jwtSecret: секретный ключ для подписи JWT токенов
+//   - keyring: набор ключей подписи JWT токенов
+//   - opts: функции для настройки опций cookie
 //
 // Возвращает:
 //   - gin.HandlerFunc: middleware функция
 //
 // Устанавливает в контексте:
 //   - VisitorUUIDKey: UUID посетителя (string)
-func VisitorCookieMiddleware(jwtSecret []byte) gin.HandlerFunc {
+//   - VisitorRolesKey: роли посетителя из токена ([]string)
+//   - VisitorTokenIDKey: идентификатор принятого токена (string, только если токен принят)
+//   - VisitorSessionIDKey: идентификатор сессии (string, если известен)
+//   - VisitorUnverifiedKey: true, если отзыв токена не удалось проверить
+func VisitorCookieMiddleware(keyring *tokens.Keyring, opts ...func(*VisitorCookieOptions)) gin.HandlerFunc {
+	options := newVisitorCookieOptions(opts)
+
 	return func(c *gin.Context) {
+		if _, ok := c.Get(VisitorUUIDKey); ok {
+			c.Next()
+			return
+		}
+
 		visitorAuthCookie, _ := c.Request.Cookie(VisitorCookieName)
 
 		var visitorUUID string
+		var roles []string
+		var tokenID, sessionID string
 		needGenerateJWT := true
+		unverified := false
 
 		if visitorAuthCookie != nil {
-			// Проверяем токен
-			token, validateErr := tokens.ValidateVisitorJWT(visitorAuthCookie.Value, jwtSecret)
+			// Проверяем токен. Истекшие в пределах Grace токены принимаются и продлеваются.
+			token, validateErr := keyring.ValidateVisitorJWT(visitorAuthCookie.Value, jwt.WithLeeway(options.Grace))
 			if validateErr != nil {
 				// отправляем ошибку и будем выставлять новый токен.
 				_ = c.Error(fmt.Errorf("visitor cookie middleware: %s", validateErr.Error()))
 			} else if token.Valid {
-				needGenerateJWT = false
-
-				// Безопасная операция, т.к. проверка типа происходит в tokens.ValidateVisitorJWT.
-				visitorUUID = token.Claims.(*tokens.VisitorClaims).UUID //nolint:errcheck
+				// Безопасная операция, т.к. проверка типа происходит в Keyring.ValidateVisitorJWT.
+				claims := token.Claims.(*tokens.VisitorClaims) //nolint:errcheck
+				revoked, checkErr := options.revoked(c, claims)
+				switch {
+				case checkErr != nil:
+					visitorUUID = claims.UUID
+					needGenerateJWT = false
+					unverified = true
+				case !revoked:
+					visitorUUID = claims.UUID
+					tokenID = claims.ID
+					sessionID = claims.SessionID
+					roles = options.roles(visitorUUID)
+					needGenerateJWT = claims.ExpiresAt == nil ||
+						time.Until(claims.ExpiresAt.Time) < options.RefreshBefore ||
+						!slices.Equal(claims.Roles, roles)
+				}
 			}
 		}
 
-		if needGenerateJWT {
+		if visitorUUID == "" {
 			var uErr error
 			visitorUUID, uErr = generateUUID()
 			if uErr != nil {
@@ -66,29 +190,95 @@ func VisitorCookieMiddleware(jwtSecret []byte) gin.HandlerFunc {
 				c.Next()
 				return
 			}
-			tokenString, tokenErr := tokens.GenerateVisitorJWT(visitorUUID, VisitorJWTExpireDuration, jwtSecret)
-			if tokenErr != nil {
-				_ = c.Error(fmt.Errorf("visitor cookie middleware: %s", tokenErr.Error()))
+			roles = options.roles(visitorUUID)
+		}
+
+		if needGenerateJWT {
+			if sessionID == "" {
+				sessionID = tokens.NewSessionID()
+			}
+			if cookieErr := setVisitorCookie(c, visitorUUID, sessionID, roles, keyring, options); cookieErr != nil {
+				_ = c.Error(fmt.Errorf("visitor cookie middleware: %s", cookieErr.Error()))
 				c.Next()
 				return
 			}
-			c.SetCookie(
-				VisitorCookieName,
-				tokenString,
-				int(VisitorJWTExpireDuration.Seconds()),
-				"/",
-				"",
-				false,
-				true,
-			)
 		}
 
-		// Устанавливаем UUID посетителя в контекст gin.
+		// Устанавливаем UUID и роли посетителя в контекст gin.
 		c.Set(VisitorUUIDKey, visitorUUID)
+		c.Set(VisitorRolesKey, roles)
+		if tokenID != "" {
+			c.Set(VisitorTokenIDKey, tokenID)
+		}
+		if sessionID != "" {
+			c.Set(VisitorSessionIDKey, sessionID)
+		}
+		if unverified {
+			c.Set(VisitorUnverifiedKey, true)
+		}
 		c.Next()
 	}
 }
 
+// SetVisitorCookie выдает посетителю cookie с JWT токеном для указанного UUID.
+// Используется также при входе в аккаунт, когда UUID посетителя меняется на UUID аккаунта.
+// Роли токена определяются VisitorCookieOptions.Roles. Токен начинает новую сессию.
+//
+// Параметры:
+//   - c: контекст запроса
+//   - visitorUUID: UUID посетителя
+//   - keyring: набор ключей подписи JWT токенов
+//   - opts: функции для настройки опций cookie (те же, что у VisitorCookieMiddleware)
+//
+// Возвращает:
+//   - error: ошибка генерации токена
+func SetVisitorCookie(
+	c *gin.Context,
+	visitorUUID string,
+	keyring *tokens.Keyring,
+	opts ...func(*VisitorCookieOptions),
+) error {
+	options := newVisitorCookieOptions(opts)
+	return setVisitorCookie(c, visitorUUID, tokens.NewSessionID(), options.roles(visitorUUID), keyring, options)
+}
+
+// ClearVisitorCookie удаляет cookie посетителя. Следующий запрос получит нового анонимного посетителя.
+//
+// Параметры:
+//   - c: контекст запроса
+//   - opts: функции для настройки опций cookie (домен должен совпадать с доменом выданной cookie)
+func ClearVisitorCookie(c *gin.Context, opts ...func(*VisitorCookieOptions)) {
+	options := newVisitorCookieOptions(opts)
+	c.SetSameSite(options.SameSite)
+	c.SetCookie(VisitorCookieName, "", -1, "/", options.Domain, options.Secure, true)
+}
+
+// setVisitorCookie выпускает токен сессии sessionID с ролями roles и устанавливает cookie с заданными опциями.
+func setVisitorCookie(
+	c *gin.Context,
+	visitorUUID string,
+	sessionID string,
+	roles []string,
+	keyring *tokens.Keyring,
+	options VisitorCookieOptions,
+) error {
+	tokenString, err := keyring.GenerateVisitorSessionJWT(visitorUUID, sessionID, options.Expire, roles...)
+	if err != nil {
+		return fmt.Errorf("set visitor cookie: %w", err)
+	}
+	c.SetSameSite(options.SameSite)
+	c.SetCookie(
+		VisitorCookieName,
+		tokenString,
+		int(options.Expire.Seconds()),
+		"/",
+		options.Domain,
+		options.Secure,
+		true,
+	)
+	return nil
+}
+
 // generateUUID генерирует случайный UUID v4.
 //
 // Возвращает:
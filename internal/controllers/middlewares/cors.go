@@ -0,0 +1,130 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Значения CORS по умолчанию.
+var (
+	DefaultCORSAllowedMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodDelete}
+	DefaultCORSAllowedHeaders = []string{
+		"Accept", "Authorization", "Content-Type", "Content-Encoding", "Idempotency-Key", APIKeyHeader, RequestIDHeader,
+	}
+	DefaultCORSExposedHeaders = []string{"Location", "Retry-After", "Idempotent-Replayed", RequestIDHeader}
+)
+
+// CORSOptions опции CORSMiddleware.
+type CORSOptions struct {
+	AllowedMethods   []string      // Методы, разрешенные в ответе на preflight запрос
+	AllowedHeaders   []string      // Заголовки запроса, разрешенные в ответе на preflight запрос
+	ExposedHeaders   []string      // Заголовки ответа, доступные скрипту
+	AllowCredentials bool          // Разрешить запросы с cookie (cookie посетителя)
+	MaxAge           time.Duration // Время кеширования ответа на preflight запрос. 0 - не кешировать
+}
+
+// CORSMiddleware создает middleware, разрешающий запросы из других источников (CORS).
+// Источник задается как scheme://host[:port]. Поддерживаются шаблон поддоменов
+// (https://*.example.com, не включает сам example.com) и "*" для любого источника.
+// Preflight запросы (OPTIONS с Access-Control-Request-Method) обрабатываются целиком
+// и не доходят до следующих middleware, поэтому, например, cookie посетителя им не выдается.
+// Preflight из неразрешенного источника отклоняется с кодом 403.
+//
+// Параметры:
+//   - allowedOrigins: разрешенные источники
+//   - opts: функции для настройки опций
+//
+// Возвращает:
+//   - gin.HandlerFunc: middleware функция
+func CORSMiddleware(allowedOrigins []string, opts ...func(*CORSOptions)) gin.HandlerFunc {
+	options := CORSOptions{
+		AllowedMethods: DefaultCORSAllowedMethods,
+		AllowedHeaders: DefaultCORSAllowedHeaders,
+		ExposedHeaders: DefaultCORSExposedHeaders,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	allowMethods := strings.Join(options.AllowedMethods, ", ")
+	allowHeaders := strings.Join(options.AllowedHeaders, ", ")
+	exposeHeaders := strings.Join(options.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(options.MaxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		c.Writer.Header().Add("Vary", "Origin")
+		if !originAllowed(allowedOrigins, origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Origin", origin)
+		if options.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if exposeHeaders != "" {
+				c.Header("Access-Control-Expose-Headers", exposeHeaders)
+			}
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		c.Header("Access-Control-Allow-Methods", allowMethods)
+		if allowHeaders != "" {
+			c.Header("Access-Control-Allow-Headers", allowHeaders)
+		}
+		if options.MaxAge > 0 {
+			c.Header("Access-Control-Max-Age", maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// originAllowed проверяет, что источник входит в список разрешенных.
+//
+// Параметры:
+//   - allowed: разрешенные источники, в том числе шаблоны поддоменов и "*"
+//   - origin: значение заголовка Origin
+//
+// Возвращает:
+//   - bool: true, если источник разрешен
+func originAllowed(allowed []string, origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(strings.TrimSuffix(pattern, "/"))
+		if pattern == "*" || pattern == origin {
+			return true
+		}
+		prefix, suffix, found := strings.Cut(pattern, "*")
+		if !found || len(origin) <= len(prefix)+len(suffix) {
+			continue
+		}
+		if !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+			continue
+		}
+		// Подставленная часть может быть только поддоменом: без схемы, порта и пути.
+		if sub := origin[len(prefix) : len(origin)-len(suffix)]; !strings.ContainsAny(sub, ":/@") {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,6 @@
 package middlewares
 
 import (
-	"bytes"
 	"compress/gzip"
 	"fmt"
 	"io"
@@ -19,6 +18,7 @@ const (
 	headerAcceptEncoding  = "Accept-Encoding"
 	headerContentLength   = "Content-Length"
 	headerVary            = "Vary"
+	headerAccept          = "Accept"
 )
 
 // GzipMiddleware создает middleware для автоматического сжатия ответов
@@ -29,18 +29,21 @@ const (
 //   - При поддержке сжимает ответ и устанавливает соответствующие заголовки
 //   - Content-Encoding: gzip
 //   - Vary: Accept-Encoding
+//   - Потоки Server-Sent Events (Accept: text/event-stream) не сжимаются,
+//     т.к. сжатие буферизует события
 //
 // Для запросов:
 //   - Обрабатывает только POST, PUT, PATCH запросы
 //   - Проверяет наличие заголовка Content-Encoding: gzip
-//   - При наличии распаковывает тело запроса
+//   - При наличии распаковывает тело запроса на лету, не загружая его целиком в память
 //
 // Возвращает:
 //   - gin.HandlerFunc: middleware функция
 func GzipMiddleware() gin.HandlerFunc {
 	pool := newGzipHandler()
 	return func(ctx *gin.Context) {
-		pool.handleRead(ctx)
+		release := pool.handleRead(ctx)
+		defer release()
 		pool.handleWrite(ctx)
 	}
 }
@@ -65,6 +68,21 @@ func (g *gzipWriter) Write(data []byte) (int, error) {
 	return g.writer.Write(data) //nolint:wrapcheck
 }
 
+// Flush сбрасывает накопленные сжатые данные клиенту.
+// Необходим для потоковых ответов, иначе данные остаются в буфере gzip до конца запроса.
+func (g *gzipWriter) Flush() {
+	if err := g.writer.Flush(); err != nil {
+		return
+	}
+	g.ResponseWriter.Flush()
+}
+
+// Unwrap возвращает исходный ResponseWriter для http.ResponseController
+// (например, чтобы включить полнодуплексный режим потоковых обработчиков).
+func (g *gzipWriter) Unwrap() http.ResponseWriter {
+	return g.ResponseWriter
+}
+
 type poolHandler struct {
 	writePool sync.Pool
 	readPool  sync.Pool
@@ -86,7 +104,8 @@ func newGzipHandler() *poolHandler {
 }
 
 func (g *poolHandler) handleWrite(c *gin.Context) {
-	if !strings.Contains(c.Request.Header.Get(headerAcceptEncoding), "gzip") {
+	if !strings.Contains(c.Request.Header.Get(headerAcceptEncoding), "gzip") ||
+		strings.Contains(c.Request.Header.Get(headerAccept), "text/event-stream") {
 		c.Next()
 		return
 	}
@@ -122,13 +141,17 @@ func (g *poolHandler) handleWrite(c *gin.Context) {
 	c.Next()
 }
 
-func (g *poolHandler) handleRead(c *gin.Context) {
+// handleRead подменяет тело сжатого запроса распаковывающим читателем.
+//
+// Возвращает:
+//   - func(): функция возврата читателя в пул, вызывается после обработки запроса
+func (g *poolHandler) handleRead(c *gin.Context) func() {
 	if !slices.Contains([]string{http.MethodPost, http.MethodPut, http.MethodPatch}, c.Request.Method) {
-		return
+		return func() {}
 	}
 
 	if !strings.Contains(c.Request.Header.Get(headerContentEncoding), "gzip") {
-		return
+		return func() {}
 	}
 
 	gzReader := g.readPool.Get().(*gzip.Reader) //nolint:errcheck
@@ -138,19 +161,14 @@ func (g *poolHandler) handleRead(c *gin.Context) {
 
 		_ = c.Error(fmt.Errorf("reset gzip reader: %s", errReset.Error()))
 		c.AbortWithStatus(http.StatusBadRequest)
-		return
+		return func() {}
 	}
-	defer func() {
+	c.Request.Body = io.NopCloser(gzReader)
+
+	return func() {
 		if errClose := gzReader.Close(); errClose != nil {
 			_ = c.Error(fmt.Errorf("close gzip reader: %s", errClose.Error()))
 		}
 		g.readPool.Put(gzReader)
-	}()
-	bodyBytes, err := io.ReadAll(gzReader)
-	if err != nil {
-		_ = c.Error(fmt.Errorf("read gzip: %s", err.Error()))
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
 	}
-	c.Request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
 }
@@ -7,6 +7,7 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/fsdevblog/shorturl/internal/tracing"
 	"github.com/gin-gonic/gin"
 )
 
@@ -22,6 +23,8 @@ import (
 //   - Content-Encoding заголовок
 //   - Accept-Encoding заголовок
 //   - Ошибки, возникшие при обработке запроса
+//   - Идентификаторы трассы и спана (trace_id, span_id), если запрос трассируется
+//   - Идентификатор запроса (request_id), если подключен RequestIDMiddleware
 //
 // Уровни логирования:
 //   - ERROR: для статусов 5xx
@@ -53,7 +56,10 @@ func LoggerMiddleware(logger *zap.Logger) gin.HandlerFunc {
 			zap.String("content-type", c.Request.Header.Get("Content-Type")),
 			zap.String("content-encoding", c.Request.Header.Get("Content-Encoding")),
 			zap.String("accept-encoding", c.Request.Header.Get("Accept-Encoding")),
-		)
+		).With(tracing.ZapFields(c.Request.Context())...)
+		if requestID := c.GetString(RequestIDKey); requestID != "" {
+			l = l.With(zap.String("request_id", requestID))
+		}
 		errorMessage := c.Errors.ByType(gin.ErrorTypePrivate).String()
 
 		if errorMessage != "" {
@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute значение метки маршрута для запросов, не попавших ни в один маршрут.
+// Использование исходного пути привело бы к неограниченному росту количества меток.
+const unmatchedRoute = "unmatched"
+
+// HTTPObserver описывает сборщик метрик HTTP запросов.
+type HTTPObserver interface {
+	// ObserveHTTPRequest учитывает обработанный запрос.
+	ObserveHTTPRequest(method, route string, status int, d time.Duration)
+}
+
+// MetricsMiddleware создает middleware для сбора метрик HTTP запросов.
+// В качестве метки маршрута используется шаблон gin (например, /api/:shortID), а не исходный путь.
+//
+// Параметры:
+//   - observer: сборщик метрик
+//
+// Возвращает:
+//   - gin.HandlerFunc: middleware функция
+func MetricsMiddleware(observer HTTPObserver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		observer.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
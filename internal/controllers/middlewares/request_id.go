@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader заголовок с идентификатором запроса.
+// RequestIDKey Имя ключа для хранения идентификатора запроса в контексте gin.
+const (
+	RequestIDHeader = "X-Request-ID"
+	RequestIDKey    = "requestID"
+)
+
+// requestIDRegex допустимый идентификатор запроса от клиента или прокси.
+// Ограничение не дает подставить в логи и ответы произвольные данные.
+var requestIDRegex = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestIDMiddleware создает middleware, присваивающий запросу идентификатор.
+// Идентификатор берется из заголовка X-Request-ID, если он задан и корректен,
+// иначе генерируется UUID. Идентификатор возвращается в заголовке ответа X-Request-ID.
+//
+// Возвращает:
+//   - gin.HandlerFunc: middleware функция
+//
+// Устанавливает в контексте:
+//   - RequestIDKey: идентификатор запроса (string)
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !requestIDRegex.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Set(RequestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
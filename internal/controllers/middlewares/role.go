@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRoleMiddleware создает middleware, пропускающий только посетителей с ролью role.
+// Роли берутся из токена посетителя (VisitorRolesKey). Посетители, определенные по ключу API,
+// ролей не имеют, поэтому ключом API нельзя воспользоваться вместо cookie администратора.
+//
+// Параметры:
+//   - role: требуемая роль, например tokens.RoleAdmin
+//
+// Возвращает:
+//   - gin.HandlerFunc: middleware функция, отвечающая 401 без посетителя и 403 без роли
+func RequireRoleMiddleware(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString(VisitorUUIDKey) == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		if !slices.Contains(c.GetStringSlice(VisitorRolesKey), role) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,37 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// headerRealIP заголовок с IP адресом клиента, выставляемый доверенным прокси.
+const headerRealIP = "X-Real-IP"
+
+// TrustedSubnetMiddleware создает middleware, пропускающий только запросы из доверенной подсети.
+// IP адрес клиента берется из заголовка X-Real-IP. Если подсеть не задана или некорректна,
+// доступ запрещен для всех.
+//
+// Параметры:
+//   - cidr: доверенная подсеть в CIDR нотации
+//
+// Возвращает:
+//   - gin.HandlerFunc: middleware функция, отвечающая 403 для недоверенных запросов
+func TrustedSubnetMiddleware(cidr string) gin.HandlerFunc {
+	subnet, parseErr := netip.ParsePrefix(cidr)
+	return func(c *gin.Context) {
+		if parseErr != nil {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		ip, err := netip.ParseAddr(strings.TrimSpace(c.GetHeader(headerRealIP)))
+		if err != nil || !subnet.Contains(ip.Unmap()) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Next()
+	}
+}
@@ -7,8 +7,11 @@ package mocksctrl
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	events "github.com/fsdevblog/shorturl/internal/events"
 	models "github.com/fsdevblog/shorturl/internal/models"
+	repositories "github.com/fsdevblog/shorturl/internal/repositories"
 	services "github.com/fsdevblog/shorturl/internal/services"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -104,6 +107,20 @@ func (mr *MockShortURLStoreMockRecorder) Create(ctx, visitorUUID, rawURL interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockShortURLStore)(nil).Create), ctx, visitorUUID, rawURL)
 }
 
+// ExportByVisitorUUID mocks base method.
+func (m *MockShortURLStore) ExportByVisitorUUID(ctx context.Context, visitorUUID string, fn func(models.URL) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportByVisitorUUID", ctx, visitorUUID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportByVisitorUUID indicates an expected call of ExportByVisitorUUID.
+func (mr *MockShortURLStoreMockRecorder) ExportByVisitorUUID(ctx, visitorUUID, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportByVisitorUUID", reflect.TypeOf((*MockShortURLStore)(nil).ExportByVisitorUUID), ctx, visitorUUID, fn)
+}
+
 // GetAllByVisitorUUID mocks base method.
 func (m *MockShortURLStore) GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.URL, error) {
 	m.ctrl.T.Helper()
@@ -135,26 +152,42 @@ func (mr *MockShortURLStoreMockRecorder) GetByShortIdentifier(ctx, shortID inter
 }
 
 // GetByURL mocks base method.
-func (m *MockShortURLStore) GetByURL(ctx context.Context, rawURL string) (*models.URL, error) {
+func (m *MockShortURLStore) GetByURL(ctx context.Context, visitorUUID, rawURL string) (*models.URL, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByURL", ctx, rawURL)
+	ret := m.ctrl.Call(m, "GetByURL", ctx, visitorUUID, rawURL)
 	ret0, _ := ret[0].(*models.URL)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByURL indicates an expected call of GetByURL.
-func (mr *MockShortURLStoreMockRecorder) GetByURL(ctx, rawURL interface{}) *gomock.Call {
+func (mr *MockShortURLStoreMockRecorder) GetByURL(ctx, visitorUUID, rawURL interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByURL", reflect.TypeOf((*MockShortURLStore)(nil).GetByURL), ctx, rawURL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByURL", reflect.TypeOf((*MockShortURLStore)(nil).GetByURL), ctx, visitorUUID, rawURL)
+}
+
+// Import mocks base method.
+func (m *MockShortURLStore) Import(ctx context.Context, visitorUUID string, rows []services.ImportRow) (*services.BatchCreateShortURLsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", ctx, visitorUUID, rows)
+	ret0, _ := ret[0].(*services.BatchCreateShortURLsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockShortURLStoreMockRecorder) Import(ctx, visitorUUID, rows interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockShortURLStore)(nil).Import), ctx, visitorUUID, rows)
 }
 
 // MarkAsDeleted mocks base method.
-func (m *MockShortURLStore) MarkAsDeleted(ctx context.Context, shortIDs []string, visitorUUID string) error {
+func (m *MockShortURLStore) MarkAsDeleted(ctx context.Context, shortIDs []string, visitorUUID string) (*services.DeleteResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "MarkAsDeleted", ctx, shortIDs, visitorUUID)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*services.DeleteResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // MarkAsDeleted indicates an expected call of MarkAsDeleted.
@@ -162,3 +195,848 @@ func (mr *MockShortURLStoreMockRecorder) MarkAsDeleted(ctx, shortIDs, visitorUUI
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAsDeleted", reflect.TypeOf((*MockShortURLStore)(nil).MarkAsDeleted), ctx, shortIDs, visitorUUID)
 }
+
+// Resolve mocks base method.
+func (m *MockShortURLStore) Resolve(ctx context.Context, shortIDs []string) ([]services.ResolveResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resolve", ctx, shortIDs)
+	ret0, _ := ret[0].([]services.ResolveResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Resolve indicates an expected call of Resolve.
+func (mr *MockShortURLStoreMockRecorder) Resolve(ctx, shortIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockShortURLStore)(nil).Resolve), ctx, shortIDs)
+}
+
+// Visit mocks base method.
+func (m *MockShortURLStore) Visit(ctx context.Context, shortID string) (*models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Visit", ctx, shortID)
+	ret0, _ := ret[0].(*models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Visit indicates an expected call of Visit.
+func (mr *MockShortURLStoreMockRecorder) Visit(ctx, shortID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Visit", reflect.TypeOf((*MockShortURLStore)(nil).Visit), ctx, shortID)
+}
+
+// MockEventSubscriber is a mock of EventSubscriber interface.
+type MockEventSubscriber struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventSubscriberMockRecorder
+}
+
+// MockEventSubscriberMockRecorder is the mock recorder for MockEventSubscriber.
+type MockEventSubscriberMockRecorder struct {
+	mock *MockEventSubscriber
+}
+
+// NewMockEventSubscriber creates a new mock instance.
+func NewMockEventSubscriber(ctrl *gomock.Controller) *MockEventSubscriber {
+	mock := &MockEventSubscriber{ctrl: ctrl}
+	mock.recorder = &MockEventSubscriberMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventSubscriber) EXPECT() *MockEventSubscriberMockRecorder {
+	return m.recorder
+}
+
+// Subscribe mocks base method.
+func (m *MockEventSubscriber) Subscribe(visitorUUID string) *events.Subscription {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", visitorUUID)
+	ret0, _ := ret[0].(*events.Subscription)
+	return ret0
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockEventSubscriberMockRecorder) Subscribe(visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockEventSubscriber)(nil).Subscribe), visitorUUID)
+}
+
+// MockWebhookManager is a mock of WebhookManager interface.
+type MockWebhookManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookManagerMockRecorder
+}
+
+// MockWebhookManagerMockRecorder is the mock recorder for MockWebhookManager.
+type MockWebhookManagerMockRecorder struct {
+	mock *MockWebhookManager
+}
+
+// NewMockWebhookManager creates a new mock instance.
+func NewMockWebhookManager(ctrl *gomock.Controller) *MockWebhookManager {
+	mock := &MockWebhookManager{ctrl: ctrl}
+	mock.recorder = &MockWebhookManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookManager) EXPECT() *MockWebhookManagerMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockWebhookManager) Delete(ctx context.Context, id, visitorUUID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, visitorUUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockWebhookManagerMockRecorder) Delete(ctx, id, visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockWebhookManager)(nil).Delete), ctx, id, visitorUUID)
+}
+
+// Deliveries mocks base method.
+func (m *MockWebhookManager) Deliveries(ctx context.Context, id, visitorUUID string, limit int) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deliveries", ctx, id, visitorUUID, limit)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Deliveries indicates an expected call of Deliveries.
+func (mr *MockWebhookManagerMockRecorder) Deliveries(ctx, id, visitorUUID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deliveries", reflect.TypeOf((*MockWebhookManager)(nil).Deliveries), ctx, id, visitorUUID, limit)
+}
+
+// GetAllByVisitorUUID mocks base method.
+func (m *MockWebhookManager) GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByVisitorUUID", ctx, visitorUUID)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByVisitorUUID indicates an expected call of GetAllByVisitorUUID.
+func (mr *MockWebhookManagerMockRecorder) GetAllByVisitorUUID(ctx, visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByVisitorUUID", reflect.TypeOf((*MockWebhookManager)(nil).GetAllByVisitorUUID), ctx, visitorUUID)
+}
+
+// Register mocks base method.
+func (m *MockWebhookManager) Register(ctx context.Context, visitorUUID, rawURL string, eventTypes []string) (*models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, visitorUUID, rawURL, eventTypes)
+	ret0, _ := ret[0].(*models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockWebhookManagerMockRecorder) Register(ctx, visitorUUID, rawURL, eventTypes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockWebhookManager)(nil).Register), ctx, visitorUUID, rawURL, eventTypes)
+}
+
+// MockStatsProvider is a mock of StatsProvider interface.
+type MockStatsProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatsProviderMockRecorder
+}
+
+// MockStatsProviderMockRecorder is the mock recorder for MockStatsProvider.
+type MockStatsProviderMockRecorder struct {
+	mock *MockStatsProvider
+}
+
+// NewMockStatsProvider creates a new mock instance.
+func NewMockStatsProvider(ctrl *gomock.Controller) *MockStatsProvider {
+	mock := &MockStatsProvider{ctrl: ctrl}
+	mock.recorder = &MockStatsProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatsProvider) EXPECT() *MockStatsProviderMockRecorder {
+	return m.recorder
+}
+
+// Stats mocks base method.
+func (m *MockStatsProvider) Stats(ctx context.Context) (*models.Stats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats", ctx)
+	ret0, _ := ret[0].(*models.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockStatsProviderMockRecorder) Stats(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockStatsProvider)(nil).Stats), ctx)
+}
+
+// MockIdempotencyStore is a mock of IdempotencyStore interface.
+type MockIdempotencyStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyStoreMockRecorder
+}
+
+// MockIdempotencyStoreMockRecorder is the mock recorder for MockIdempotencyStore.
+type MockIdempotencyStoreMockRecorder struct {
+	mock *MockIdempotencyStore
+}
+
+// NewMockIdempotencyStore creates a new mock instance.
+func NewMockIdempotencyStore(ctrl *gomock.Controller) *MockIdempotencyStore {
+	mock := &MockIdempotencyStore{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyStore) EXPECT() *MockIdempotencyStoreMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockIdempotencyStore) Begin(ctx context.Context, visitorUUID, key, fingerprint string) (*models.IdempotencyRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", ctx, visitorUUID, key, fingerprint)
+	ret0, _ := ret[0].(*models.IdempotencyRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockIdempotencyStoreMockRecorder) Begin(ctx, visitorUUID, key, fingerprint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockIdempotencyStore)(nil).Begin), ctx, visitorUUID, key, fingerprint)
+}
+
+// Complete mocks base method.
+func (m *MockIdempotencyStore) Complete(ctx context.Context, visitorUUID, key string, status int, contentType string, body []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, visitorUUID, key, status, contentType, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockIdempotencyStoreMockRecorder) Complete(ctx, visitorUUID, key, status, contentType, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockIdempotencyStore)(nil).Complete), ctx, visitorUUID, key, status, contentType, body)
+}
+
+// Release mocks base method.
+func (m *MockIdempotencyStore) Release(ctx context.Context, visitorUUID, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, visitorUUID, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockIdempotencyStoreMockRecorder) Release(ctx, visitorUUID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockIdempotencyStore)(nil).Release), ctx, visitorUUID, key)
+}
+
+// MockDeletionQueue is a mock of DeletionQueue interface.
+type MockDeletionQueue struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeletionQueueMockRecorder
+}
+
+// MockDeletionQueueMockRecorder is the mock recorder for MockDeletionQueue.
+type MockDeletionQueueMockRecorder struct {
+	mock *MockDeletionQueue
+}
+
+// NewMockDeletionQueue creates a new mock instance.
+func NewMockDeletionQueue(ctrl *gomock.Controller) *MockDeletionQueue {
+	mock := &MockDeletionQueue{ctrl: ctrl}
+	mock.recorder = &MockDeletionQueueMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeletionQueue) EXPECT() *MockDeletionQueueMockRecorder {
+	return m.recorder
+}
+
+// Enqueue mocks base method.
+func (m *MockDeletionQueue) Enqueue(ctx context.Context, visitorUUID string, shortIDs []string) (*models.DeletionJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", ctx, visitorUUID, shortIDs)
+	ret0, _ := ret[0].(*models.DeletionJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockDeletionQueueMockRecorder) Enqueue(ctx, visitorUUID, shortIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockDeletionQueue)(nil).Enqueue), ctx, visitorUUID, shortIDs)
+}
+
+// Job mocks base method.
+func (m *MockDeletionQueue) Job(ctx context.Context, visitorUUID, id string) (*models.DeletionJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Job", ctx, visitorUUID, id)
+	ret0, _ := ret[0].(*models.DeletionJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Job indicates an expected call of Job.
+func (mr *MockDeletionQueueMockRecorder) Job(ctx, visitorUUID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Job", reflect.TypeOf((*MockDeletionQueue)(nil).Job), ctx, visitorUUID, id)
+}
+
+// MockAPIKeyManager is a mock of APIKeyManager interface.
+type MockAPIKeyManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyManagerMockRecorder
+}
+
+// MockAPIKeyManagerMockRecorder is the mock recorder for MockAPIKeyManager.
+type MockAPIKeyManagerMockRecorder struct {
+	mock *MockAPIKeyManager
+}
+
+// NewMockAPIKeyManager creates a new mock instance.
+func NewMockAPIKeyManager(ctrl *gomock.Controller) *MockAPIKeyManager {
+	mock := &MockAPIKeyManager{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyManager) EXPECT() *MockAPIKeyManagerMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockAPIKeyManager) Authenticate(ctx context.Context, token string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, token)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockAPIKeyManagerMockRecorder) Authenticate(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAPIKeyManager)(nil).Authenticate), ctx, token)
+}
+
+// Create mocks base method.
+func (m *MockAPIKeyManager) Create(ctx context.Context, visitorUUID, name string) (*models.APIKey, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, visitorUUID, name)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAPIKeyManagerMockRecorder) Create(ctx, visitorUUID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAPIKeyManager)(nil).Create), ctx, visitorUUID, name)
+}
+
+// GetAllByVisitorUUID mocks base method.
+func (m *MockAPIKeyManager) GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByVisitorUUID", ctx, visitorUUID)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByVisitorUUID indicates an expected call of GetAllByVisitorUUID.
+func (mr *MockAPIKeyManagerMockRecorder) GetAllByVisitorUUID(ctx, visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByVisitorUUID", reflect.TypeOf((*MockAPIKeyManager)(nil).GetAllByVisitorUUID), ctx, visitorUUID)
+}
+
+// Revoke mocks base method.
+func (m *MockAPIKeyManager) Revoke(ctx context.Context, visitorUUID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, visitorUUID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockAPIKeyManagerMockRecorder) Revoke(ctx, visitorUUID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockAPIKeyManager)(nil).Revoke), ctx, visitorUUID, id)
+}
+
+// MockOIDCAuthenticator is a mock of OIDCAuthenticator interface.
+type MockOIDCAuthenticator struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCAuthenticatorMockRecorder
+}
+
+// MockOIDCAuthenticatorMockRecorder is the mock recorder for MockOIDCAuthenticator.
+type MockOIDCAuthenticatorMockRecorder struct {
+	mock *MockOIDCAuthenticator
+}
+
+// NewMockOIDCAuthenticator creates a new mock instance.
+func NewMockOIDCAuthenticator(ctrl *gomock.Controller) *MockOIDCAuthenticator {
+	mock := &MockOIDCAuthenticator{ctrl: ctrl}
+	mock.recorder = &MockOIDCAuthenticatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDCAuthenticator) EXPECT() *MockOIDCAuthenticatorMockRecorder {
+	return m.recorder
+}
+
+// AuthCodeURL mocks base method.
+func (m *MockOIDCAuthenticator) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthCodeURL", ctx, state, nonce, verifier)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthCodeURL indicates an expected call of AuthCodeURL.
+func (mr *MockOIDCAuthenticatorMockRecorder) AuthCodeURL(ctx, state, nonce, verifier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthCodeURL", reflect.TypeOf((*MockOIDCAuthenticator)(nil).AuthCodeURL), ctx, state, nonce, verifier)
+}
+
+// Login mocks base method.
+func (m *MockOIDCAuthenticator) Login(ctx context.Context, visitorUUID, code, verifier, nonce string) (*services.OIDCLoginResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", ctx, visitorUUID, code, verifier, nonce)
+	ret0, _ := ret[0].(*services.OIDCLoginResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockOIDCAuthenticatorMockRecorder) Login(ctx, visitorUUID, code, verifier, nonce interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockOIDCAuthenticator)(nil).Login), ctx, visitorUUID, code, verifier, nonce)
+}
+
+// MockAccountManager is a mock of AccountManager interface.
+type MockAccountManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountManagerMockRecorder
+}
+
+// MockAccountManagerMockRecorder is the mock recorder for MockAccountManager.
+type MockAccountManagerMockRecorder struct {
+	mock *MockAccountManager
+}
+
+// NewMockAccountManager creates a new mock instance.
+func NewMockAccountManager(ctrl *gomock.Controller) *MockAccountManager {
+	mock := &MockAccountManager{ctrl: ctrl}
+	mock.recorder = &MockAccountManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountManager) EXPECT() *MockAccountManagerMockRecorder {
+	return m.recorder
+}
+
+// Login mocks base method.
+func (m *MockAccountManager) Login(ctx context.Context, visitorUUID, email, password string) (*services.LoginResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", ctx, visitorUUID, email, password)
+	ret0, _ := ret[0].(*services.LoginResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockAccountManagerMockRecorder) Login(ctx, visitorUUID, email, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockAccountManager)(nil).Login), ctx, visitorUUID, email, password)
+}
+
+// Register mocks base method.
+func (m *MockAccountManager) Register(ctx context.Context, visitorUUID, email, password string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, visitorUUID, email, password)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockAccountManagerMockRecorder) Register(ctx, visitorUUID, email, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockAccountManager)(nil).Register), ctx, visitorUUID, email, password)
+}
+
+// MockTokenRevoker is a mock of TokenRevoker interface.
+type MockTokenRevoker struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenRevokerMockRecorder
+}
+
+// MockTokenRevokerMockRecorder is the mock recorder for MockTokenRevoker.
+type MockTokenRevokerMockRecorder struct {
+	mock *MockTokenRevoker
+}
+
+// NewMockTokenRevoker creates a new mock instance.
+func NewMockTokenRevoker(ctrl *gomock.Controller) *MockTokenRevoker {
+	mock := &MockTokenRevoker{ctrl: ctrl}
+	mock.recorder = &MockTokenRevokerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenRevoker) EXPECT() *MockTokenRevokerMockRecorder {
+	return m.recorder
+}
+
+// IsRevoked mocks base method.
+func (m *MockTokenRevoker) IsRevoked(ctx context.Context, visitorUUID, jti, sessionID string, issuedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRevoked", ctx, visitorUUID, jti, sessionID, issuedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsRevoked indicates an expected call of IsRevoked.
+func (mr *MockTokenRevokerMockRecorder) IsRevoked(ctx, visitorUUID, jti, sessionID, issuedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRevoked", reflect.TypeOf((*MockTokenRevoker)(nil).IsRevoked), ctx, visitorUUID, jti, sessionID, issuedAt)
+}
+
+// Revoke mocks base method.
+func (m *MockTokenRevoker) Revoke(ctx context.Context, visitorUUID, jti string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, visitorUUID, jti)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockTokenRevokerMockRecorder) Revoke(ctx, visitorUUID, jti interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockTokenRevoker)(nil).Revoke), ctx, visitorUUID, jti)
+}
+
+// RevokeAll mocks base method.
+func (m *MockTokenRevoker) RevokeAll(ctx context.Context, visitorUUID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAll", ctx, visitorUUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAll indicates an expected call of RevokeAll.
+func (mr *MockTokenRevokerMockRecorder) RevokeAll(ctx, visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAll", reflect.TypeOf((*MockTokenRevoker)(nil).RevokeAll), ctx, visitorUUID)
+}
+
+// MockAdminManager is a mock of AdminManager interface.
+type MockAdminManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminManagerMockRecorder
+}
+
+// MockAdminManagerMockRecorder is the mock recorder for MockAdminManager.
+type MockAdminManagerMockRecorder struct {
+	mock *MockAdminManager
+}
+
+// NewMockAdminManager creates a new mock instance.
+func NewMockAdminManager(ctrl *gomock.Controller) *MockAdminManager {
+	mock := &MockAdminManager{ctrl: ctrl}
+	mock.recorder = &MockAdminManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminManager) EXPECT() *MockAdminManagerMockRecorder {
+	return m.recorder
+}
+
+// AuditLog mocks base method.
+func (m *MockAdminManager) AuditLog(ctx context.Context, filter repositories.AuditFilter) ([]models.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditLog", ctx, filter)
+	ret0, _ := ret[0].([]models.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuditLog indicates an expected call of AuditLog.
+func (mr *MockAdminManagerMockRecorder) AuditLog(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditLog", reflect.TypeOf((*MockAdminManager)(nil).AuditLog), ctx, filter)
+}
+
+// BanVisitor mocks base method.
+func (m *MockAdminManager) BanVisitor(ctx context.Context, actorUUID, visitorUUID, reason string, disableLinks bool) (*services.BanResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BanVisitor", ctx, actorUUID, visitorUUID, reason, disableLinks)
+	ret0, _ := ret[0].(*services.BanResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BanVisitor indicates an expected call of BanVisitor.
+func (mr *MockAdminManagerMockRecorder) BanVisitor(ctx, actorUUID, visitorUUID, reason, disableLinks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BanVisitor", reflect.TypeOf((*MockAdminManager)(nil).BanVisitor), ctx, actorUUID, visitorUUID, reason, disableLinks)
+}
+
+// DisableURL mocks base method.
+func (m *MockAdminManager) DisableURL(ctx context.Context, actorUUID, shortID, reason string, legal bool) (*models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableURL", ctx, actorUUID, shortID, reason, legal)
+	ret0, _ := ret[0].(*models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableURL indicates an expected call of DisableURL.
+func (mr *MockAdminManagerMockRecorder) DisableURL(ctx, actorUUID, shortID, reason, legal interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableURL", reflect.TypeOf((*MockAdminManager)(nil).DisableURL), ctx, actorUUID, shortID, reason, legal)
+}
+
+// EnableURL mocks base method.
+func (m *MockAdminManager) EnableURL(ctx context.Context, actorUUID, shortID string) (*models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableURL", ctx, actorUUID, shortID)
+	ret0, _ := ret[0].(*models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableURL indicates an expected call of EnableURL.
+func (mr *MockAdminManagerMockRecorder) EnableURL(ctx, actorUUID, shortID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableURL", reflect.TypeOf((*MockAdminManager)(nil).EnableURL), ctx, actorUUID, shortID)
+}
+
+// IsBanned mocks base method.
+func (m *MockAdminManager) IsBanned(ctx context.Context, visitorUUID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBanned", ctx, visitorUUID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBanned indicates an expected call of IsBanned.
+func (mr *MockAdminManagerMockRecorder) IsBanned(ctx, visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBanned", reflect.TypeOf((*MockAdminManager)(nil).IsBanned), ctx, visitorUUID)
+}
+
+// SearchURLs mocks base method.
+func (m *MockAdminManager) SearchURLs(ctx context.Context, filter repositories.URLSearchFilter) ([]models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchURLs", ctx, filter)
+	ret0, _ := ret[0].([]models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchURLs indicates an expected call of SearchURLs.
+func (mr *MockAdminManagerMockRecorder) SearchURLs(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchURLs", reflect.TypeOf((*MockAdminManager)(nil).SearchURLs), ctx, filter)
+}
+
+// UnbanVisitor mocks base method.
+func (m *MockAdminManager) UnbanVisitor(ctx context.Context, actorUUID, visitorUUID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbanVisitor", ctx, actorUUID, visitorUUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnbanVisitor indicates an expected call of UnbanVisitor.
+func (mr *MockAdminManagerMockRecorder) UnbanVisitor(ctx, actorUUID, visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbanVisitor", reflect.TypeOf((*MockAdminManager)(nil).UnbanVisitor), ctx, actorUUID, visitorUUID)
+}
+
+// MockWorkspaceManager is a mock of WorkspaceManager interface.
+type MockWorkspaceManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceManagerMockRecorder
+}
+
+// MockWorkspaceManagerMockRecorder is the mock recorder for MockWorkspaceManager.
+type MockWorkspaceManagerMockRecorder struct {
+	mock *MockWorkspaceManager
+}
+
+// NewMockWorkspaceManager creates a new mock instance.
+func NewMockWorkspaceManager(ctrl *gomock.Controller) *MockWorkspaceManager {
+	mock := &MockWorkspaceManager{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWorkspaceManager) EXPECT() *MockWorkspaceManagerMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockWorkspaceManager) Create(ctx context.Context, visitorUUID, name string) (*models.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, visitorUUID, name)
+	ret0, _ := ret[0].(*models.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWorkspaceManagerMockRecorder) Create(ctx, visitorUUID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWorkspaceManager)(nil).Create), ctx, visitorUUID, name)
+}
+
+// CreateURL mocks base method.
+func (m *MockWorkspaceManager) CreateURL(ctx context.Context, actorUUID, workspaceID, rawURL string) (*models.URL, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateURL", ctx, actorUUID, workspaceID, rawURL)
+	ret0, _ := ret[0].(*models.URL)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateURL indicates an expected call of CreateURL.
+func (mr *MockWorkspaceManagerMockRecorder) CreateURL(ctx, actorUUID, workspaceID, rawURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateURL", reflect.TypeOf((*MockWorkspaceManager)(nil).CreateURL), ctx, actorUUID, workspaceID, rawURL)
+}
+
+// DeleteURL mocks base method.
+func (m *MockWorkspaceManager) DeleteURL(ctx context.Context, actorUUID, workspaceID, shortID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteURL", ctx, actorUUID, workspaceID, shortID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteURL indicates an expected call of DeleteURL.
+func (mr *MockWorkspaceManagerMockRecorder) DeleteURL(ctx, actorUUID, workspaceID, shortID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteURL", reflect.TypeOf((*MockWorkspaceManager)(nil).DeleteURL), ctx, actorUUID, workspaceID, shortID)
+}
+
+// Get mocks base method.
+func (m *MockWorkspaceManager) Get(ctx context.Context, visitorUUID, workspaceID string) (*services.WorkspaceDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, visitorUUID, workspaceID)
+	ret0, _ := ret[0].(*services.WorkspaceDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockWorkspaceManagerMockRecorder) Get(ctx, visitorUUID, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockWorkspaceManager)(nil).Get), ctx, visitorUUID, workspaceID)
+}
+
+// List mocks base method.
+func (m *MockWorkspaceManager) List(ctx context.Context, visitorUUID string) ([]models.WorkspaceMembership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, visitorUUID)
+	ret0, _ := ret[0].([]models.WorkspaceMembership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockWorkspaceManagerMockRecorder) List(ctx, visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockWorkspaceManager)(nil).List), ctx, visitorUUID)
+}
+
+// ListURLs mocks base method.
+func (m *MockWorkspaceManager) ListURLs(ctx context.Context, actorUUID, workspaceID string) ([]models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListURLs", ctx, actorUUID, workspaceID)
+	ret0, _ := ret[0].([]models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListURLs indicates an expected call of ListURLs.
+func (mr *MockWorkspaceManagerMockRecorder) ListURLs(ctx, actorUUID, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListURLs", reflect.TypeOf((*MockWorkspaceManager)(nil).ListURLs), ctx, actorUUID, workspaceID)
+}
+
+// RemoveMember mocks base method.
+func (m *MockWorkspaceManager) RemoveMember(ctx context.Context, actorUUID, workspaceID, visitorUUID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, actorUUID, workspaceID, visitorUUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockWorkspaceManagerMockRecorder) RemoveMember(ctx, actorUUID, workspaceID, visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockWorkspaceManager)(nil).RemoveMember), ctx, actorUUID, workspaceID, visitorUUID)
+}
+
+// SetMember mocks base method.
+func (m *MockWorkspaceManager) SetMember(ctx context.Context, actorUUID, workspaceID, visitorUUID string, role models.WorkspaceRole) (*models.WorkspaceMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMember", ctx, actorUUID, workspaceID, visitorUUID, role)
+	ret0, _ := ret[0].(*models.WorkspaceMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetMember indicates an expected call of SetMember.
+func (mr *MockWorkspaceManagerMockRecorder) SetMember(ctx, actorUUID, workspaceID, visitorUUID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMember", reflect.TypeOf((*MockWorkspaceManager)(nil).SetMember), ctx, actorUUID, workspaceID, visitorUUID, role)
+}
+
+// UpdateURL mocks base method.
+func (m *MockWorkspaceManager) UpdateURL(ctx context.Context, actorUUID, workspaceID, shortID string, upd repositories.URLUpdate) (*models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateURL", ctx, actorUUID, workspaceID, shortID, upd)
+	ret0, _ := ret[0].(*models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateURL indicates an expected call of UpdateURL.
+func (mr *MockWorkspaceManagerMockRecorder) UpdateURL(ctx, actorUUID, workspaceID, shortID, upd interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateURL", reflect.TypeOf((*MockWorkspaceManager)(nil).UpdateURL), ctx, actorUUID, workspaceID, shortID, upd)
+}
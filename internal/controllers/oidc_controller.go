@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/middlewares"
+	"github.com/fsdevblog/shorturl/internal/oidc"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/fsdevblog/shorturl/internal/tokens"
+	"github.com/gin-gonic/gin"
+)
+
+// OIDCFlowCookieName имя cookie с параметрами незавершенного входа: state, nonce и code_verifier.
+// OIDCFlowCookiePath путь cookie: она нужна только адресу возврата.
+// OIDCFlowTTL время, за которое пользователь должен войти у провайдера.
+const (
+	OIDCFlowCookieName = "oidc_flow"
+	OIDCFlowCookiePath = "/api/oidc"
+	OIDCFlowTTL        = 10 * time.Minute
+)
+
+// OIDCControllerOptions опции OIDCController.
+type OIDCControllerOptions struct {
+	PostLoginURL string // Адрес перехода после входа (по умолчанию "/")
+	SecureCookie bool   // Отправлять cookie входа только по HTTPS
+	// Опции cookie посетителя, те же, что у VisitorCookieMiddleware.
+	VisitorCookie []func(*middlewares.VisitorCookieOptions)
+}
+
+// OIDCController выполняет вход через провайдера OpenID Connect (authorization code с PKCE).
+type OIDCController struct {
+	authenticator OIDCAuthenticator
+	keyring       *tokens.Keyring
+	opts          OIDCControllerOptions
+}
+
+// NewOIDCController создает новый экземпляр OIDCController.
+//
+// Параметры:
+//   - authenticator: сервис входа через провайдера
+//   - keyring: набор ключей подписи JWT токенов посетителя
+//   - opts: функции для настройки опций
+//
+// Возвращает:
+//   - *OIDCController: новый экземпляр контроллера
+func NewOIDCController(
+	authenticator OIDCAuthenticator,
+	keyring *tokens.Keyring,
+	opts ...func(*OIDCControllerOptions),
+) *OIDCController {
+	options := OIDCControllerOptions{PostLoginURL: "/"}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.PostLoginURL == "" {
+		options.PostLoginURL = "/"
+	}
+	return &OIDCController{authenticator: authenticator, keyring: keyring, opts: options}
+}
+
+// Login начинает вход: сохраняет state, nonce и code_verifier в cookie и перенаправляет на страницу провайдера.
+//
+// Коды ответа:
+//   - 302: переход на страницу входа провайдера
+//   - 500: внутренняя ошибка сервера
+//   - 502: провайдер недоступен
+func (o *OIDCController) Login(c *gin.Context) {
+	var flow [3]string // state, nonce, code_verifier
+	for i := range flow {
+		value, err := oidc.RandomString()
+		if err != nil {
+			_ = c.Error(fmt.Errorf("oidc login: %w", err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+			return
+		}
+		flow[i] = value
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	authURL, err := o.authenticator.AuthCodeURL(ctx, flow[0], flow[1], flow[2])
+	if err != nil {
+		o.abortWithError(c, fmt.Errorf("oidc login: %w", err))
+		return
+	}
+
+	// Cookie отправляется при возврате с сайта провайдера, поэтому SameSite=Lax, а не Strict.
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(OIDCFlowCookieName, strings.Join(flow[:], "."), int(OIDCFlowTTL.Seconds()),
+		OIDCFlowCookiePath, "", o.opts.SecureCookie, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback завершает вход: проверяет state, обменивает код на ID токен и выдает cookie
+// посетителя с постоянным UUID пользователя провайдера. Ссылки анонимного посетителя
+// передаются пользователю.
+//
+// Коды ответа:
+//   - 303: вход выполнен, переход на PostLoginURL
+//   - 400: state отсутствует или не совпадает
+//   - 401: провайдер отказал во входе или ID токен не прошел проверку
+//   - 403: посетитель не определен
+//   - 500: внутренняя ошибка сервера
+//   - 502: провайдер недоступен
+func (o *OIDCController) Callback(c *gin.Context) {
+	flowCookie, _ := c.Cookie(OIDCFlowCookieName)
+	// Параметры входа одноразовые, поэтому cookie удаляется при любом исходе.
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(OIDCFlowCookieName, "", -1, OIDCFlowCookiePath, "", o.opts.SecureCookie, true)
+
+	flow := strings.Split(flowCookie, ".")
+	state := c.Query("state")
+	if len(flow) != 3 || state == "" || subtle.ConstantTimeCompare([]byte(flow[0]), []byte(state)) != 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired login state"})
+		return
+	}
+	if providerErr := c.Query("error"); providerErr != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "identity provider denied login: " + providerErr})
+		return
+	}
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	res, err := o.authenticator.Login(ctx, visitorUUID, c.Query("code"), flow[2], flow[1])
+	if err != nil {
+		o.abortWithError(c, fmt.Errorf("oidc callback: %w", err))
+		return
+	}
+	if err = middlewares.SetVisitorCookie(c, res.Identity.UserID, o.keyring, o.opts.VisitorCookie...); err != nil {
+		_ = c.Error(fmt.Errorf("oidc callback: %w", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+		return
+	}
+	c.Redirect(http.StatusSeeOther, o.opts.PostLoginURL)
+}
+
+// abortWithError отправляет ответ, соответствующий ошибке сервиса входа.
+func (o *OIDCController) abortWithError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "identity provider login failed"})
+	case errors.Is(err, services.ErrIdentityProviderUnavailable):
+		_ = c.Error(err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "identity provider unavailable"})
+	default:
+		_ = c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+	}
+}
@@ -0,0 +1,247 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/middlewares"
+	"github.com/fsdevblog/shorturl/internal/controllers/mocksctrl"
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/oidc"
+	"github.com/fsdevblog/shorturl/internal/oidc/oidctest"
+	"github.com/fsdevblog/shorturl/internal/repositories/memstore"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/fsdevblog/shorturl/internal/tokens"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOIDCPostLoginURL = "/dashboard"
+
+// responseCookie возвращает последнюю cookie с именем name.
+func responseCookie(res *http.Response, name string) *http.Cookie {
+	var found *http.Cookie
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == name {
+			found = cookie
+		}
+	}
+	return found
+}
+
+func TestOIDCController_Login(t *testing.T) {
+	tests := []struct {
+		name       string
+		authErr    error
+		wantStatus int
+	}{
+		{
+			name:       "redirect to provider",
+			wantStatus: http.StatusFound,
+		},
+		{
+			name:       "provider unavailable",
+			authErr:    services.ErrIdentityProviderUnavailable,
+			wantStatus: http.StatusBadGateway,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			authenticator := mocksctrl.NewMockOIDCAuthenticator(ctrl)
+			var state, nonce, verifier string
+			authenticator.EXPECT().AuthCodeURL(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, s, n, v string) (string, error) {
+					state, nonce, verifier = s, n, v
+					if tt.authErr != nil {
+						return "", tt.authErr
+					}
+					return "https://sso.example.com/authorize?state=" + s, nil
+				})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/oidc/login", nil)
+			w := httptest.NewRecorder()
+			newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) {
+				p.OIDC = authenticator
+				p.AppConf.OIDCPostLoginURL = testOIDCPostLoginURL
+			}).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+			flow := responseCookie(w.Result(), OIDCFlowCookieName)
+			if tt.wantStatus != http.StatusFound {
+				assert.Nil(t, flow)
+				return
+			}
+			assert.Equal(t, "https://sso.example.com/authorize?state="+state, w.Header().Get("Location"))
+			require.NotNil(t, flow)
+			assert.Equal(t, strings.Join([]string{state, nonce, verifier}, "."), flow.Value)
+			assert.Equal(t, OIDCFlowCookiePath, flow.Path)
+			assert.True(t, flow.HttpOnly)
+			assert.Equal(t, http.SameSiteLaxMode, flow.SameSite)
+			assert.NotEqual(t, state, nonce)
+		})
+	}
+}
+
+func TestOIDCController_Callback(t *testing.T) {
+	account := uuid.NewString()
+	const flowCookie = "state.nonce.verifier"
+	tests := []struct {
+		name       string
+		cookie     string
+		query      string
+		loginErr   error
+		wantLogin  bool
+		wantStatus int
+	}{
+		{
+			name:       "logged in",
+			cookie:     flowCookie,
+			query:      "code=abc&state=state",
+			wantLogin:  true,
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name:       "no flow cookie",
+			query:      "code=abc&state=state",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "state mismatch",
+			cookie:     flowCookie,
+			query:      "code=abc&state=forged",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "denied by provider",
+			cookie:     flowCookie,
+			query:      "error=access_denied&state=state",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "rejected id token",
+			cookie:     flowCookie,
+			query:      "code=abc&state=state",
+			loginErr:   services.ErrInvalidCredentials,
+			wantLogin:  true,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "provider unavailable",
+			cookie:     flowCookie,
+			query:      "code=abc&state=state",
+			loginErr:   services.ErrIdentityProviderUnavailable,
+			wantLogin:  true,
+			wantStatus: http.StatusBadGateway,
+		},
+		{
+			name:       "storage error",
+			cookie:     flowCookie,
+			query:      "code=abc&state=state",
+			loginErr:   errors.New("boom"),
+			wantLogin:  true,
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			authenticator := mocksctrl.NewMockOIDCAuthenticator(ctrl)
+			if tt.wantLogin {
+				var res *services.OIDCLoginResult
+				if tt.loginErr == nil {
+					res = &services.OIDCLoginResult{Identity: &models.OIDCIdentity{UserID: account}}
+				}
+				authenticator.EXPECT().Login(gomock.Any(), gomock.Any(), "abc", "verifier", "nonce").
+					Return(res, tt.loginErr)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/oidc/callback?"+tt.query, nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: OIDCFlowCookieName, Value: tt.cookie})
+			}
+			w := httptest.NewRecorder()
+			newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) {
+				p.OIDC = authenticator
+				p.AppConf.OIDCPostLoginURL = testOIDCPostLoginURL
+			}).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+			// Параметры входа одноразовые.
+			flow := responseCookie(w.Result(), OIDCFlowCookieName)
+			require.NotNil(t, flow)
+			assert.Negative(t, flow.MaxAge)
+			if tt.wantStatus != http.StatusSeeOther {
+				return
+			}
+			assert.Equal(t, testOIDCPostLoginURL, w.Header().Get("Location"))
+			// Последняя cookie перекрывает cookie анонимного посетителя.
+			visitor := responseCookie(w.Result(), middlewares.VisitorCookieName)
+			require.NotNil(t, visitor)
+			token, err := tokens.ValidateVisitorJWT(visitor.Value, []byte(jwtSecret))
+			require.NoError(t, err)
+			assert.Equal(t, account, token.Claims.(*tokens.VisitorClaims).UUID) //nolint:errcheck
+		})
+	}
+}
+
+// TestOIDC_EndToEnd проходит вход у локального провайдера с двух устройств.
+func TestOIDC_EndToEnd(t *testing.T) {
+	provider := oidctest.NewProvider(t, "shorturl")
+	client := oidc.NewClient(oidc.Config{
+		Issuer:      provider.Issuer(),
+		ClientID:    "shorturl",
+		RedirectURL: "http://test.com/api/oidc/callback",
+	})
+	store := db.NewMemStorage()
+	urlService := services.NewURLService(memstore.NewURLRepo(store))
+	oidcService := services.NewOIDCService(
+		client, memstore.NewOIDCIdentityRepo(store), memstore.NewUserRepo(store), urlService,
+	)
+	router := newTestRouter(urlService, func(p *RouterParams) {
+		p.OIDC = oidcService
+		p.AppConf.OIDCPostLoginURL = testOIDCPostLoginURL
+	})
+
+	// signIn выполняет вход на устройстве без cookie и возвращает UUID посетителя после входа.
+	signIn := func(t *testing.T) string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/oidc/login", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusFound, w.Code)
+		visitor := responseCookie(w.Result(), middlewares.VisitorCookieName)
+		flow := responseCookie(w.Result(), OIDCFlowCookieName)
+		require.NotNil(t, visitor)
+		require.NotNil(t, flow)
+
+		callback, err := provider.Authorize(w.Header().Get("Location"), "alice", "alice@example.com")
+		require.NoError(t, err)
+		callbackURL, err := url.Parse(callback)
+		require.NoError(t, err)
+
+		req = httptest.NewRequest(http.MethodGet, callbackURL.RequestURI(), nil)
+		req.AddCookie(visitor)
+		req.AddCookie(flow)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusSeeOther, w.Code)
+		return visitorCookieUUID(t, w.Result())
+	}
+
+	laptop := signIn(t)
+	require.NotEmpty(t, laptop)
+	assert.Equal(t, laptop, signIn(t), "пользователь провайдера получает один UUID на всех устройствах")
+}
@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// openAPISpec спецификация OpenAPI 3 HTTP API. При добавлении маршрута в SetupRouter
+// его необходимо описать в openapi.json, иначе не пройдет TestOpenAPI_CoversRoutes.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// OpenAPISpec возвращает спецификацию OpenAPI 3 HTTP API в формате JSON.
+//
+// Возвращает:
+//   - []byte: документ OpenAPI
+func OpenAPISpec() []byte {
+	return openAPISpec
+}
+
+// OpenAPI отдает спецификацию OpenAPI 3 HTTP API.
+//
+// Коды ответа:
+//   - 200: документ OpenAPI в формате JSON
+func OpenAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openAPISpec)
+}
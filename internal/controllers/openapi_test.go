@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fsdevblog/shorturl/internal/config"
+	"github.com/fsdevblog/shorturl/internal/controllers/mocksctrl"
+	"github.com/fsdevblog/shorturl/internal/metrics"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// undocumentedRoutePrefixes маршруты, не входящие в API и не описываемые в спецификации.
+//
+//nolint:gochecknoglobals
+var undocumentedRoutePrefixes = []string{"/debug/pprof"}
+
+// ginParamRegex параметр пути gin (:id), который в OpenAPI записывается как {id}.
+var ginParamRegex = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+//nolint:gochecknoglobals
+var (
+	specOnce   sync.Once
+	specDoc    *openapi3.T
+	specRouter routers.Router
+	specErr    error
+)
+
+// loadSpec загружает и проверяет спецификацию OpenAPI один раз на все тесты пакета.
+func loadSpec(t *testing.T) (*openapi3.T, routers.Router) {
+	t.Helper()
+	specOnce.Do(func() {
+		// Потоковые ответы проверяются построчно в тестах, здесь тело принимается как строка.
+		openapi3filter.RegisterBodyDecoder(NDJSONContentType, decodeRawBody)
+		specDoc, specErr = openapi3.NewLoader().LoadFromData(OpenAPISpec())
+		if specErr != nil {
+			return
+		}
+		if specErr = specDoc.Validate(t.Context()); specErr != nil {
+			return
+		}
+		specRouter, specErr = gorillamux.NewRouter(specDoc)
+	})
+	require.NoError(t, specErr, "openapi.json должен быть валидной спецификацией OpenAPI 3")
+	return specDoc, specRouter
+}
+
+// assertResponseMatchesSpec проверяет, что ответ описан в спецификации и соответствует схеме.
+// Тело ответа вычитывается и подменяется копией, поэтому ответ можно читать повторно.
+func assertResponseMatchesSpec(t *testing.T, req *http.Request, res *http.Response) {
+	t.Helper()
+	_, router := loadSpec(t)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	if res.Header.Get("Content-Encoding") == "gzip" && len(body) > 0 {
+		gzr, gzErr := gzip.NewReader(bytes.NewReader(body))
+		require.NoError(t, gzErr)
+		body, err = io.ReadAll(gzr)
+		require.NoError(t, err)
+	}
+
+	route, pathParams, err := router.FindRoute(req)
+	if errors.Is(err, routers.ErrPathNotFound) || errors.Is(err, routers.ErrMethodNotAllowed) {
+		// Все зарегистрированные маршруты описаны (TestOpenAPI_CoversRoutes),
+		// значит запрос не попал ни в один маршрут gin.
+		assert.Equal(t, http.StatusNotFound, res.StatusCode,
+			"%s %s не описан в openapi.json, но обработан маршрутизатором", req.Method, req.URL.Path)
+		return
+	}
+	require.NoError(t, err)
+
+	err = openapi3filter.ValidateResponse(t.Context(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+		},
+		Status: res.StatusCode,
+		Header: res.Header,
+		Body:   io.NopCloser(bytes.NewReader(body)),
+		Options: &openapi3filter.Options{
+			IncludeResponseStatus: true,
+		},
+	})
+	assert.NoError(t, err, "ответ %d на %s %s не соответствует openapi.json", res.StatusCode, req.Method, req.URL.Path)
+}
+
+// decodeRawBody декодер тела, возвращающий содержимое как строку.
+func decodeRawBody(body io.Reader, _ http.Header, _ *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (any, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// assertMatchesSchema проверяет JSON значение по схеме components/schemas спецификации.
+func assertMatchesSchema(t *testing.T, schemaName string, data []byte) {
+	t.Helper()
+	doc, _ := loadSpec(t)
+
+	schemaRef := doc.Components.Schemas[schemaName]
+	require.NotNil(t, schemaRef, "схема %s не описана в openapi.json", schemaName)
+
+	var value any
+	require.NoError(t, json.Unmarshal(data, &value))
+	assert.NoError(t, schemaRef.Value.VisitJSON(value), "%s не соответствует схеме %s", data, schemaName)
+}
+
+// fullRouter создает маршрутизатор со всеми опциональными маршрутами.
+func fullRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	m := metrics.New()
+	return SetupRouter(RouterParams{
+		URLService:     mocksctrl.NewMockShortURLStore(ctrl),
+		PingService:    mocksctrl.NewMockConnectionChecker(ctrl),
+		Events:         mocksctrl.NewMockEventSubscriber(ctrl),
+		Webhooks:       mocksctrl.NewMockWebhookManager(ctrl),
+		Deletions:      mocksctrl.NewMockDeletionQueue(ctrl),
+		Stats:          mocksctrl.NewMockStatsProvider(ctrl),
+		APIKeys:        mocksctrl.NewMockAPIKeyManager(ctrl),
+		Accounts:       mocksctrl.NewMockAccountManager(ctrl),
+		OIDC:           mocksctrl.NewMockOIDCAuthenticator(ctrl),
+		Admin:          mocksctrl.NewMockAdminManager(ctrl),
+		Workspaces:     mocksctrl.NewMockWorkspaceManager(ctrl),
+		Revocations:    mocksctrl.NewMockTokenRevoker(ctrl),
+		Metrics:        m,
+		MetricsHandler: m.Handler(),
+		AppConf:        config.Config{VisitorJWTSecret: jwtSecret},
+	})
+}
+
+func TestOpenAPI_CoversRoutes(t *testing.T) {
+	doc, _ := loadSpec(t)
+	router := fullRouter(t)
+
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		if hasAnyPrefix(route.Path, undocumentedRoutePrefixes) {
+			continue
+		}
+		path := ginParamRegex.ReplaceAllString(route.Path, "{$1}")
+		registered[route.Method+" "+path] = true
+
+		item := doc.Paths.Value(path)
+		if !assert.NotNil(t, item, "маршрут %s %s не описан в openapi.json", route.Method, route.Path) {
+			continue
+		}
+		assert.NotNil(t, item.GetOperation(route.Method),
+			"метод %s маршрута %s не описан в openapi.json", route.Method, route.Path)
+	}
+
+	// Обратная проверка: в спецификации нет операций, которых нет в маршрутизаторе.
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			assert.True(t, registered[method+" "+path], "операция %s %s описана, но не зарегистрирована", method, path)
+		}
+	}
+}
+
+func TestOpenAPI_Served(t *testing.T) {
+	router := fullRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.True(t, strings.HasPrefix(res.Header.Get("Content-Type"), "application/json"))
+	assert.Empty(t, res.Cookies(), "спецификация не должна выдавать cookie посетителя")
+	assert.JSONEq(t, string(OpenAPISpec()), w.Body.String())
+	assertResponseMatchesSpec(t, req, res)
+}
+
+// hasAnyPrefix проверяет, начинается ли строка с одного из префиксов.
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType тип содержимого ошибок /api/v2 (RFC 7807).
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix префикс URI типа проблемы. К нему добавляется ProblemCode.
+const problemTypePrefix = "urn:shorturl:problem:"
+
+// ProblemCode стабильный машиночитаемый код ошибки /api/v2.
+// Коды не меняются между версиями и могут использоваться клиентами для ветвления логики.
+type ProblemCode string
+
+// Коды ошибок /api/v2.
+const (
+	ProblemInvalidRequest ProblemCode = "invalid_request"   // Тело запроса не разобрано
+	ProblemValidation     ProblemCode = "validation_failed" // Поля запроса не прошли проверку (см. Problem.Errors)
+	ProblemUnauthorized   ProblemCode = "unauthorized"      // Посетитель не определен
+	ProblemForbidden      ProblemCode = "forbidden"         // Посетитель заблокирован администратором
+	ProblemNotFound       ProblemCode = "not_found"         // Ресурс не найден
+	ProblemGone           ProblemCode = "gone"              // Ссылка удалена или отключена администратором
+	ProblemInternal       ProblemCode = "internal"          // Внутренняя ошибка, детали не раскрываются
+	ProblemUnavailable    ProblemCode = "unavailable"       // Сервис временно не принимает запрос, см. Retry-After
+
+	// Ссылка отключена администратором по требованию закона (451)
+	ProblemLegal ProblemCode = "unavailable_for_legal_reasons"
+)
+
+// Коды ошибок полей запроса (FieldError.Code).
+const (
+	FieldRequired     = "required"      // Поле не задано
+	FieldInvalidURL   = "invalid_url"   // Некорректный URL
+	FieldInvalidValue = "invalid_value" // Недопустимое значение
+)
+
+// FieldError ошибка валидации конкретного поля запроса.
+type FieldError struct {
+	Pointer string `json:"pointer"` // JSON Pointer (RFC 6901) на поле в теле запроса
+	Code    string `json:"code"`    // Стабильный код ошибки поля
+	Detail  string `json:"detail"`  // Описание ошибки для человека
+}
+
+// Problem тело ошибки в формате RFC 7807 с расширениями code, request_id и errors.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      ProblemCode  `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// problemTitles заголовки проблем по коду.
+//
+//nolint:gochecknoglobals
+var problemTitles = map[ProblemCode]string{
+	ProblemInvalidRequest: "Invalid request",
+	ProblemValidation:     "Validation failed",
+	ProblemUnauthorized:   "Unauthorized",
+	ProblemForbidden:      "Forbidden",
+	ProblemNotFound:       "Not found",
+	ProblemGone:           "Gone",
+	ProblemLegal:          "Unavailable for legal reasons",
+	ProblemInternal:       "Internal server error",
+	ProblemUnavailable:    "Service unavailable",
+}
+
+// abortWithProblem прерывает обработку запроса и отдает ошибку в формате application/problem+json.
+// Тип, заголовок, путь запроса и идентификатор запроса заполняются автоматически.
+//
+// Параметры:
+//   - c: контекст gin
+//   - status: HTTP статус ответа
+//   - code: код ошибки
+//   - detail: описание ошибки для клиента; не должно содержать внутренних деталей
+//   - fieldErrors: ошибки полей запроса
+func abortWithProblem(c *gin.Context, status int, code ProblemCode, detail string, fieldErrors ...FieldError) {
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(status, Problem{
+		Type:      problemTypePrefix + string(code),
+		Title:     problemTitles[code],
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		Code:      code,
+		RequestID: c.GetString(middlewares.RequestIDKey),
+		Errors:    fieldErrors,
+	})
+}
+
+// abortWithInternalProblem сохраняет ошибку для логирования и отдает клиенту
+// ошибку без внутренних деталей.
+func abortWithInternalProblem(c *gin.Context, err error) {
+	_ = c.Error(err)
+	abortWithProblem(c, http.StatusInternalServerError, ProblemInternal, "")
+}
+
+// apiV2NoRoute отдает ошибку 404 в формате application/problem+json для неизвестных маршрутов /api/v2.
+// Для остальных путей сохраняется стандартный ответ gin.
+func apiV2NoRoute(c *gin.Context) {
+	if strings.HasPrefix(c.Request.URL.Path, "/api/v2/") {
+		abortWithProblem(c, http.StatusNotFound, ProblemNotFound, "route not found")
+	}
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// MaxResolveItems максимальное количество элементов в одном запросе разрешения ссылок.
+const MaxResolveItems = 1000
+
+// ResolveController обрабатывает запросы массового разрешения коротких ссылок
+// и обратного поиска по оригинальному URL.
+type ResolveController struct {
+	urlService ShortURLStore
+	baseURL    string
+}
+
+// NewResolveController создает новый экземпляр ResolveController.
+//
+// Параметры:
+//   - urlService: сервис для работы с URL
+//   - baseURL: базовый URL коротких ссылок
+//
+// Возвращает:
+//   - *ResolveController: новый экземпляр контроллера
+func NewResolveController(urlService ShortURLStore, baseURL string) *ResolveController {
+	return &ResolveController{urlService: urlService, baseURL: baseURL}
+}
+
+// ResolveItemResponse состояние одной короткой ссылки.
+type ResolveItemResponse struct {
+	Input       string `json:"input"`                  // Элемент запроса как есть
+	ShortID     string `json:"short_id,omitempty"`     // Короткий идентификатор, извлеченный из элемента
+	State       string `json:"state"`                  // active, deleted, disabled, expired или unknown
+	OriginalURL string `json:"original_url,omitempty"` // Адрес перехода, только для действующих ссылок
+}
+
+// LookupResponse результат поиска ссылки посетителя по оригинальному URL.
+type LookupResponse struct {
+	ShortURL    string `json:"short_url"`
+	OriginalURL string `json:"original_url"`
+	State       string `json:"state"`
+}
+
+// Resolve возвращает адреса перехода и состояния нескольких коротких ссылок.
+// Принимает JSON массив коротких идентификаторов или полных коротких URL.
+// Переходы при этом не учитываются. Результаты возвращаются в порядке запроса.
+//
+// Коды ответа:
+//   - 200: состояния ссылок ([]ResolveItemResponse)
+//   - 400: некорректный запрос или больше MaxResolveItems элементов
+//   - 500: внутренняя ошибка сервера
+func (r *ResolveController) Resolve(c *gin.Context) {
+	var inputs []string
+	if bindErr := c.ShouldBindJSON(&inputs); bindErr != nil || len(inputs) == 0 {
+		_ = c.Error(fmt.Errorf("bind params: %w", bindErr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request. Only json array of strings is supported"})
+		return
+	}
+	if len(inputs) > MaxResolveItems {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("too many items, max %d", MaxResolveItems)})
+		return
+	}
+
+	shortIDs := make([]string, len(inputs))
+	var valid []string
+	for i, input := range inputs {
+		shortIDs[i] = parseShortID(input)
+		if shortIDs[i] != "" {
+			valid = append(valid, shortIDs[i])
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	results, err := r.urlService.Resolve(ctx, valid)
+	if err != nil {
+		_ = c.Error(fmt.Errorf("resolve: %w", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+		return
+	}
+
+	response := make([]ResolveItemResponse, len(inputs))
+	for i, input := range inputs {
+		response[i] = ResolveItemResponse{Input: input, State: string(models.URLStateUnknown)}
+		if shortIDs[i] == "" {
+			continue
+		}
+		res := results[0]
+		results = results[1:]
+		response[i].ShortID = res.ShortID
+		response[i].State = string(res.State)
+		if res.State == models.URLStateActive {
+			response[i].OriginalURL = res.URL.URL
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Lookup ищет короткую ссылку посетителя на оригинальный URL из параметра url.
+// Ссылки других посетителей не учитываются.
+//
+// Коды ответа:
+//   - 200: ссылка найдена (LookupResponse), в том числе удаленная
+//   - 400: параметр url не задан
+//   - 401: посетитель не определен
+//   - 404: посетитель не сокращал этот URL
+//   - 422: некорректный URL
+//   - 500: внутренняя ошибка сервера
+func (r *ResolveController) Lookup(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	rawURL := c.Query("url")
+	if rawURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url query parameter is required"})
+		return
+	}
+	parsedURL, parseErr := validateURL(rawURL)
+	if parseErr != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": parseErr.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	m, err := r.urlService.GetByURL(ctx, visitorUUID, parsedURL.String())
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": ErrRecordNotFound.Error()})
+			return
+		}
+		_ = c.Error(fmt.Errorf("lookup: %w", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, LookupResponse{
+		ShortURL:    buildShortURL(r.baseURL, c.Request, m.ShortIdentifier),
+		OriginalURL: m.URL,
+		State:       string(m.State()),
+	})
+}
+
+// parseShortID извлекает короткий идентификатор из идентификатора или полного короткого URL.
+// Для полного URL идентификатором считается последний сегмент пути.
+//
+// Параметры:
+//   - input: короткий идентификатор или короткий URL
+//
+// Возвращает:
+//   - string: короткий идентификатор или пустая строка, если его не удалось извлечь
+func parseShortID(input string) string {
+	shortID := strings.TrimSpace(input)
+	if strings.Contains(shortID, "/") {
+		u, err := url.Parse(shortID)
+		if err != nil {
+			return ""
+		}
+		path := strings.TrimSuffix(u.Path, "/")
+		shortID = path[strings.LastIndex(path, "/")+1:]
+	}
+	if shortID == "" || len(shortID) > models.MaxAliasLength {
+		return ""
+	}
+	return shortID
+}
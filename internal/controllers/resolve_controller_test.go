@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/mocksctrl"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/fsdevblog/shorturl/internal/tokens"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveController_Resolve(t *testing.T) {
+	deletedAt := time.Now()
+	tests := []struct {
+		name        string
+		body        string
+		wantIDs     []string // Идентификаторы, переданные в сервис
+		results     []services.ResolveResult
+		resolveErr  error
+		wantStatus  int
+		wantStates  []string
+		wantOrigURL []string
+	}{
+		{
+			name:    "ids and short urls",
+			body:    `["aaaaaaaa","http://test.com/bbbbbbbb","https://other.host/api/cccccccc/","",".."]`,
+			wantIDs: []string{"aaaaaaaa", "bbbbbbbb", "cccccccc", ".."},
+			results: []services.ResolveResult{
+				{
+					ShortID: "aaaaaaaa",
+					State:   models.URLStateActive,
+					URL:     &models.URL{ShortIdentifier: "aaaaaaaa", URL: "https://a.com"},
+				},
+				{
+					ShortID: "bbbbbbbb",
+					State:   models.URLStateDeleted,
+					URL:     &models.URL{ShortIdentifier: "bbbbbbbb", URL: "https://b.com", DeletedAt: &deletedAt},
+				},
+				{ShortID: "cccccccc", State: models.URLStateUnknown},
+				{ShortID: "..", State: models.URLStateUnknown},
+			},
+			wantStatus:  http.StatusOK,
+			wantStates:  []string{"active", "deleted", "unknown", "unknown", "unknown"},
+			wantOrigURL: []string{"https://a.com", "", "", "", ""},
+		},
+		{
+			name:       "empty list",
+			body:       `[]`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "not an array",
+			body:       `{"id":"aaaaaaaa"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "too many items",
+			body:       `["` + strings.Repeat(`a","`, MaxResolveItems) + `a"]`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "storage error",
+			body:       `["aaaaaaaa"]`,
+			wantIDs:    []string{"aaaaaaaa"},
+			resolveErr: errors.New("db is down"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mocksctrl.NewMockShortURLStore(ctrl)
+			if tt.wantIDs != nil {
+				store.EXPECT().Resolve(gomock.Any(), tt.wantIDs).Return(tt.results, tt.resolveErr)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/resolve", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			newTestRouter(store).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var res []ResolveItemResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			require.Len(t, res, len(tt.wantStates))
+			for i, item := range res {
+				assert.Equal(t, tt.wantStates[i], item.State, "элемент %d", i)
+				assert.Equal(t, tt.wantOrigURL[i], item.OriginalURL, "элемент %d", i)
+			}
+			assert.Equal(t, "http://test.com/bbbbbbbb", res[1].Input)
+			assert.Equal(t, "bbbbbbbb", res[1].ShortID)
+		})
+	}
+}
+
+func TestResolveController_Lookup(t *testing.T) {
+	const visitorUUID = "0b6f3e52-8f1d-4a39-9d53-6f3c2a7e9b10"
+	token, tokenErr := tokens.GenerateVisitorJWT(visitorUUID, time.Hour, []byte(jwtSecret))
+	require.NoError(t, tokenErr)
+
+	tests := []struct {
+		name       string
+		rawURL     string
+		found      *models.URL
+		lookupErr  error
+		wantStatus int
+	}{
+		{
+			name:       "found",
+			rawURL:     "https://a.com/path",
+			found:      &models.URL{ShortIdentifier: "aaaaaaaa", URL: "https://a.com/path"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "not found",
+			rawURL:     "https://a.com/path",
+			lookupErr:  services.ErrRecordNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "missing url",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid url",
+			rawURL:     "ftp://a.com",
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "storage error",
+			rawURL:     "https://a.com/path",
+			lookupErr:  errors.New("db is down"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mocksctrl.NewMockShortURLStore(ctrl)
+			if tt.found != nil || tt.lookupErr != nil {
+				store.EXPECT().GetByURL(gomock.Any(), visitorUUID, tt.rawURL).Return(tt.found, tt.lookupErr)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/lookup?url="+url.QueryEscape(tt.rawURL), nil)
+			req.AddCookie(&http.Cookie{Name: "visitor", Value: token})
+			w := httptest.NewRecorder()
+			newTestRouter(store).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+			if tt.found == nil {
+				return
+			}
+			var res LookupResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			assert.Equal(t, "http://test.com/aaaaaaaa", res.ShortURL)
+			assert.Equal(t, string(models.URLStateActive), res.State)
+		})
+	}
+}
@@ -1,8 +1,12 @@
 package controllers
 
 import (
+	"net/http"
+	"slices"
+
 	"github.com/fsdevblog/shorturl/internal/config"
 	"github.com/fsdevblog/shorturl/internal/controllers/middlewares"
+	"github.com/fsdevblog/shorturl/internal/tokens"
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -10,10 +14,24 @@ import (
 
 // RouterParams определяет параметры для настройки маршрутизатора.
 type RouterParams struct {
-	URLService  ShortURLStore     // Сервис для работы с короткими URL
-	PingService ConnectionChecker // Сервис для проверки работоспособности системы
-	AppConf     config.Config     // Конфигурация приложения
-	Logger      *zap.Logger       // Логгер приложения
+	URLService     ShortURLStore            // Сервис для работы с короткими URL
+	PingService    ConnectionChecker        // Сервис для проверки работоспособности системы
+	Events         EventSubscriber          // Источник событий о ссылках (если nil, SSE поток не регистрируется)
+	Webhooks       WebhookManager           // Сервис вебхуков (если nil, маршруты вебхуков не регистрируются)
+	Idempotency    IdempotencyStore         // Хранилище ответов для Idempotency-Key (если nil, заголовок игнорируется)
+	Deletions      DeletionQueue            // Очередь фонового удаления (если nil, маршруты удаления не регистрируются)
+	APIKeys        APIKeyManager            // Сервис ключей API (если nil, ключи API не принимаются)
+	Accounts       AccountManager           // Сервис пользователей (если nil, маршруты аккаунтов не регистрируются)
+	OIDC           OIDCAuthenticator        // Вход через OpenID Connect (если nil, маршруты /api/oidc не регистрируются)
+	Admin          AdminManager             // Модерация (если nil, /api/admin и проверка блокировок отключены)
+	Workspaces     WorkspaceManager         // Рабочие пространства (если nil, /api/workspaces не регистрируется)
+	Revocations    TokenRevoker             // Отзыв токенов посетителей (если nil, токены не отзываются)
+	Stats          StatsProvider            // Источник статистики (если nil, /api/internal/stats не регистрируется)
+	Metrics        middlewares.HTTPObserver // Сборщик метрик HTTP запросов (если nil, не собираются)
+	MetricsHandler http.Handler             // Обработчик /metrics (если nil, маршрут не регистрируется)
+	Keyring        *tokens.Keyring          // Ключи подписи токенов посетителей (если nil, из AppConf.VisitorJWTSecret)
+	AppConf        config.Config            // Конфигурация приложения
+	Logger         *zap.Logger              // Логгер приложения
 }
 
 // SetupRouter настраивает и возвращает маршрутизатор приложения.
@@ -21,24 +39,87 @@ type RouterParams struct {
 //
 // Регистрируемые middleware:
 //   - gin.Recovery() для восстановления после паник
+//   - TracingMiddleware для трассировки запросов (W3C traceparent)
+//   - RequestIDMiddleware для идентификатора запроса (X-Request-ID)
 //   - LoggerMiddleware для логирования запросов (если Logger != nil)
+//   - MetricsMiddleware для сбора метрик запросов (если Metrics != nil)
+//   - CORSMiddleware для запросов из других источников (если заданы CORSAllowedOrigins)
 //   - pprof для профилирования
-//   - VisitorCookieMiddleware для идентификации пользователей
+//   - APIKeyMiddleware для идентификации скриптов по ключу API (если APIKeys != nil)
+//   - VisitorCookieMiddleware для идентификации пользователей (с проверкой отзыва токенов, если задан Revocations)
 //   - GzipMiddleware для сжатия ответов
+//   - BanMiddleware для отказа заблокированным посетителям в изменении данных (если Admin != nil)
 //
 // Регистрируемые маршруты:
 //
-//	GET /:shortID - редирект по короткому URL
+//	GET /:shortID - редирект по короткому URL (с Accept: application/json - описание ссылки)
+//	GET /:shortID/info - описание короткой ссылки
 //	POST / - создание короткого URL
 //	GET /ping - проверка работоспособности
+//	GET /metrics - метрики в формате Prometheus (если задан MetricsHandler)
+//	GET /.well-known/jwks.json - открытые ключи подписи токенов посетителей
 //
 // API маршруты (/api/...):
 //
+//	GET /openapi.json - спецификация OpenAPI 3
+//	POST /shorten - создание короткого URL (поддерживает Idempotency-Key, если задан Idempotency)
+//	POST /shorten/batch - пакетное создание коротких URL (поддерживает Idempotency-Key)
+//	POST /shorten/stream - потоковое пакетное создание коротких URL (NDJSON)
+//	GET /:shortID - редирект по короткому URL (с Accept: application/json - описание ссылки)
+//	GET /:shortID/info - описание короткой ссылки
+//	POST /resolve - состояния и адреса перехода нескольких коротких ссылок
+//	GET /lookup?url= - поиск короткой ссылки пользователя по оригинальному URL
+//	POST /register - регистрация аккаунта (если задан Accounts)
+//	POST /login - вход в аккаунт с передачей ему ссылок анонимного посетителя
+//	POST /logout - выход из аккаунта с отзывом токена (если задан Accounts, OIDC или Revocations)
+//	POST /logout/all - выход со всех устройств с отзывом всех токенов посетителя (если задан Revocations)
+//	GET /oidc/login - вход через провайдера OpenID Connect (если задан OIDC)
+//	GET /oidc/callback - адрес возврата от провайдера OpenID Connect
+//	GET /user/urls - получение URL пользователя
+//	DELETE /user/urls - фоновое удаление URL пользователя (если задан Deletions)
+//	GET /jobs/:id - состояние задачи удаления
+//	POST /user/urls/import - импорт URL пользователя из CSV
+//	GET /user/urls/export - экспорт URL пользователя в CSV, JSON или NDJSON
+//	GET /user/urls/stream - SSE поток событий о ссылках пользователя (если задан Events)
+//	POST /user/api-keys - выпуск ключа API (если задан APIKeys)
+//	GET /user/api-keys - список ключей API пользователя
+//	DELETE /user/api-keys/:id - отзыв ключа API
+//	POST /user/webhooks - регистрация вебхука (если задан Webhooks)
+//	GET /user/webhooks - список вебхуков пользователя
+//	DELETE /user/webhooks/:id - удаление вебхука
+//	GET /user/webhooks/:id/deliveries - последние попытки доставки вебхука
+//	POST /workspaces - создание рабочего пространства (если задан Workspaces)
+//	GET /workspaces - рабочие пространства пользователя
+//	GET /workspaces/:id - рабочее пространство с участниками
+//	PUT /workspaces/:id/members/:visitorUUID - добавление участника или изменение его роли
+//	DELETE /workspaces/:id/members/:visitorUUID - удаление участника
+//	POST /workspaces/:id/urls - создание ссылки в пространстве
+//	GET /workspaces/:id/urls - ссылки пространства
+//	PATCH /workspaces/:id/urls/:shortID - изменение ссылки пространства
+//	DELETE /workspaces/:id/urls/:shortID - удаление ссылки пространства
+//	GET /internal/stats - статистика сервиса, только из доверенной подсети (если задан Stats)
+//
+// API администратора (/api/admin/...), только для посетителей с ролью tokens.RoleAdmin (если задан Admin):
+//
+//	GET /urls - поиск ссылок всех посетителей
+//	POST /urls/:shortID/disable - отключение ссылки с причиной (переход отвечает 410 или 451)
+//	POST /urls/:shortID/enable - снятие отключения ссылки
+//	POST /visitors/:visitorUUID/ban - блокировка посетителя
+//	DELETE /visitors/:visitorUUID/ban - снятие блокировки посетителя
+//	GET /audit - журнал действий администраторов
+//
+// API v2 (/api/v2/...) повторяет маршруты /api, но все ошибки отдает в формате
+// application/problem+json (RFC 7807), включая ошибку 404 для неизвестных маршрутов /api/v2:
+//
 //	POST /shorten - создание короткого URL
 //	POST /shorten/batch - пакетное создание коротких URL
 //	GET /:shortID - редирект по короткому URL
 //	GET /user/urls - получение URL пользователя
-//	DELETE /user/urls - удаление URL пользователя
+//	DELETE /user/urls - фоновое удаление URL пользователя (если задан Deletions)
+//	POST /user/webhooks - регистрация вебхука (если задан Webhooks)
+//	GET /user/webhooks - список вебхуков пользователя
+//	DELETE /user/webhooks/:id - удаление вебхука
+//	GET /user/webhooks/:id/deliveries - последние попытки доставки вебхука
 //
 // Параметры:
 //   - params: параметры для настройки маршрутизатора
@@ -47,30 +128,221 @@ type RouterParams struct {
 //   - *gin.Engine: настроенный маршрутизатор
 func SetupRouter(params RouterParams) *gin.Engine {
 	r := gin.New()
+	// Контекст gin.Context делегирует контексту запроса, чтобы спан трассировки
+	// доходил до сервисов через context.WithTimeout(c, ...).
+	r.ContextWithFallback = true
 	r.Use(gin.Recovery())
+	r.Use(middlewares.TracingMiddleware())
+	r.Use(middlewares.RequestIDMiddleware())
 
 	if params.Logger != nil {
 		r.Use(middlewares.LoggerMiddleware(params.Logger))
 	}
 
+	if params.Metrics != nil {
+		r.Use(middlewares.MetricsMiddleware(params.Metrics))
+	}
+
+	// CORS подключаем до middleware посетителей, чтобы preflight запросы не получали cookie.
+	if len(params.AppConf.CORSAllowedOrigins) > 0 {
+		r.Use(middlewares.CORSMiddleware(params.AppConf.CORSAllowedOrigins, corsOptions(params.AppConf)))
+	}
+
 	// подключаем pprof. Т.к. задачи защищать роут в продакшн окружении не стоит, не делаем этого.
 	pprof.Register(r)
 
-	r.Use(middlewares.VisitorCookieMiddleware([]byte(params.AppConf.VisitorJWTSecret)))
+	// Метрики, спецификацию и ключи JWKS регистрируем до middleware посетителей, чтобы клиенты не получали cookie.
+	if params.MetricsHandler != nil {
+		r.GET("/metrics", gin.WrapH(params.MetricsHandler))
+	}
+	r.GET("/api/openapi.json", middlewares.GzipMiddleware(), OpenAPI)
+
+	keyring := params.Keyring
+	if keyring == nil {
+		keyring = tokens.NewStaticKeyring([]byte(params.AppConf.VisitorJWTSecret))
+	}
+	r.GET("/.well-known/jwks.json", NewJWKSController(keyring).JWKS)
+
+	// Посетитель, определенный по ключу API, не получает cookie.
+	if params.APIKeys != nil {
+		r.Use(middlewares.APIKeyMiddleware(params.APIKeys))
+	}
+	cookieOpts := visitorCookieOptions(params.AppConf)
+	if params.Revocations != nil {
+		confCookieOpts := cookieOpts
+		cookieOpts = func(o *middlewares.VisitorCookieOptions) {
+			confCookieOpts(o)
+			o.Revocations = params.Revocations
+		}
+	}
+	r.Use(middlewares.VisitorCookieMiddleware(keyring, cookieOpts))
 	r.Use(middlewares.GzipMiddleware())
+	if params.Admin != nil {
+		r.Use(BanMiddleware(params.Admin))
+	}
 
-	shortURLController := NewShortURLController(params.URLService, params.AppConf.BaseURL)
+	withDeletions := func(o *ShortURLControllerOptions) {
+		o.Deletions = params.Deletions
+	}
+	shortURLController := NewShortURLController(params.URLService, params.AppConf.BaseURL, withDeletions)
 	pingController := NewPingController(params.PingService)
 
 	r.GET("/:shortID", shortURLController.Redirect)
+	r.GET("/:shortID/info", shortURLController.Info)
 	r.POST("/", shortURLController.CreateShortURL)
 	r.GET("/ping", pingController.Ping)
 
+	// Без хранилища ключей заголовок Idempotency-Key игнорируется.
+	idempotent := func(c *gin.Context) { c.Next() }
+	if params.Idempotency != nil {
+		idempotent = IdempotencyMiddleware(params.Idempotency)
+	}
+
 	api := r.Group("/api")
-	api.POST("/shorten", shortURLController.CreateShortURL)
-	api.POST("/shorten/batch", shortURLController.BatchCreate)
+	api.POST("/shorten", idempotent, shortURLController.CreateShortURL)
+	api.POST("/shorten/batch", idempotent, shortURLController.BatchCreate)
+	api.POST("/shorten/stream", NewBatchStreamController(params.URLService, params.AppConf.BaseURL).Stream)
 	api.GET("/:shortID", shortURLController.Redirect)
+	api.GET("/:shortID/info", shortURLController.Info)
+	resolveController := NewResolveController(params.URLService, params.AppConf.BaseURL)
+	api.POST("/resolve", resolveController.Resolve)
+	api.GET("/lookup", resolveController.Lookup)
+	if params.Accounts != nil || params.OIDC != nil || params.Revocations != nil {
+		accountsController := NewAccountsController(params.Accounts, keyring, params.Revocations, cookieOpts)
+		if params.Accounts != nil {
+			api.POST("/register", accountsController.Register)
+			api.POST("/login", accountsController.Login)
+		}
+		api.POST("/logout", accountsController.Logout)
+		if params.Revocations != nil {
+			api.POST("/logout/all", accountsController.RevokeAll)
+		}
+	}
+	if params.OIDC != nil {
+		oidcController := NewOIDCController(params.OIDC, keyring, func(o *OIDCControllerOptions) {
+			o.PostLoginURL = params.AppConf.OIDCPostLoginURL
+			o.SecureCookie = params.AppConf.VisitorCookieSecure || params.AppConf.EnableHTTPS
+			o.VisitorCookie = []func(*middlewares.VisitorCookieOptions){cookieOpts}
+		})
+		api.GET("/oidc/login", oidcController.Login)
+		api.GET("/oidc/callback", oidcController.Callback)
+	}
 	api.GET("/user/urls", shortURLController.UserURLs)
-	api.DELETE("/user/urls", shortURLController.DeleteUserURLs)
+	if params.Deletions != nil {
+		api.DELETE("/user/urls", shortURLController.DeleteUserURLs)
+		api.GET("/jobs/:id", NewJobsController(params.Deletions).Get)
+	}
+
+	urlTransferController := NewURLTransferController(params.URLService, params.AppConf.BaseURL)
+	api.POST("/user/urls/import", urlTransferController.Import)
+	api.GET("/user/urls/export", urlTransferController.Export)
+
+	if params.Events != nil {
+		eventsController := NewEventsController(params.Events, params.AppConf.BaseURL)
+		api.GET("/user/urls/stream", eventsController.Stream)
+	}
+
+	if params.APIKeys != nil {
+		apiKeysController := NewAPIKeysController(params.APIKeys)
+		api.POST("/user/api-keys", apiKeysController.Create)
+		api.GET("/user/api-keys", apiKeysController.List)
+		api.DELETE("/user/api-keys/:id", apiKeysController.Revoke)
+	}
+
+	if params.Webhooks != nil {
+		webhooksController := NewWebhooksController(params.Webhooks)
+		api.POST("/user/webhooks", webhooksController.Create)
+		api.GET("/user/webhooks", webhooksController.List)
+		api.DELETE("/user/webhooks/:id", webhooksController.Delete)
+		api.GET("/user/webhooks/:id/deliveries", webhooksController.Deliveries)
+	}
+
+	shortURLV2Controller := NewShortURLV2Controller(params.URLService, params.AppConf.BaseURL, withDeletions)
+	v2 := r.Group("/api/v2")
+	v2.POST("/shorten", shortURLV2Controller.CreateShortURL)
+	v2.POST("/shorten/batch", shortURLV2Controller.BatchCreate)
+	v2.GET("/:shortID", shortURLV2Controller.Redirect)
+	v2.GET("/user/urls", shortURLV2Controller.UserURLs)
+	if params.Deletions != nil {
+		v2.DELETE("/user/urls", shortURLV2Controller.DeleteUserURLs)
+	}
+
+	if params.Webhooks != nil {
+		webhooksV2Controller := NewWebhooksV2Controller(params.Webhooks)
+		v2.POST("/user/webhooks", webhooksV2Controller.Create)
+		v2.GET("/user/webhooks", webhooksV2Controller.List)
+		v2.DELETE("/user/webhooks/:id", webhooksV2Controller.Delete)
+		v2.GET("/user/webhooks/:id/deliveries", webhooksV2Controller.Deliveries)
+	}
+	r.NoRoute(apiV2NoRoute)
+
+	if params.Workspaces != nil {
+		workspacesController := NewWorkspacesController(params.Workspaces, params.AppConf.BaseURL)
+		api.POST("/workspaces", workspacesController.Create)
+		api.GET("/workspaces", workspacesController.List)
+		api.GET("/workspaces/:id", workspacesController.Get)
+		api.PUT("/workspaces/:id/members/:visitorUUID", workspacesController.SetMember)
+		api.DELETE("/workspaces/:id/members/:visitorUUID", workspacesController.RemoveMember)
+		api.POST("/workspaces/:id/urls", workspacesController.CreateURL)
+		api.GET("/workspaces/:id/urls", workspacesController.ListURLs)
+		api.PATCH("/workspaces/:id/urls/:shortID", workspacesController.UpdateURL)
+		api.DELETE("/workspaces/:id/urls/:shortID", workspacesController.DeleteURL)
+	}
+
+	if params.Admin != nil {
+		adminController := NewAdminController(params.Admin, params.AppConf.BaseURL)
+		admin := api.Group("/admin", middlewares.RequireRoleMiddleware(tokens.RoleAdmin))
+		admin.GET("/urls", adminController.SearchURLs)
+		admin.POST("/urls/:shortID/disable", adminController.DisableURL)
+		admin.POST("/urls/:shortID/enable", adminController.EnableURL)
+		admin.POST("/visitors/:visitorUUID/ban", adminController.BanVisitor)
+		admin.DELETE("/visitors/:visitorUUID/ban", adminController.UnbanVisitor)
+		admin.GET("/audit", adminController.AuditLog)
+	}
+
+	if params.Stats != nil {
+		statsController := NewStatsController(params.Stats)
+		internal := api.Group("/internal", middlewares.TrustedSubnetMiddleware(params.AppConf.TrustedSubnet))
+		internal.GET("/stats", statsController.Stats)
+	}
 	return r
 }
+
+// corsOptions переносит параметры CORS из конфигурации в опции CORSMiddleware.
+func corsOptions(conf config.Config) func(*middlewares.CORSOptions) {
+	return func(o *middlewares.CORSOptions) {
+		if len(conf.CORSAllowedMethods) > 0 {
+			o.AllowedMethods = conf.CORSAllowedMethods
+		}
+		if len(conf.CORSAllowedHeaders) > 0 {
+			o.AllowedHeaders = conf.CORSAllowedHeaders
+		}
+		o.AllowCredentials = conf.CORSAllowCredentials
+		o.MaxAge = conf.CORSMaxAge
+	}
+}
+
+// visitorCookieOptions переносит параметры сессии посетителя из конфигурации в опции cookie.
+// Cookie помечается Secure, если это задано явно или сервер работает по HTTPS.
+// Посетители из AdminVisitorUUIDs получают в токене роль tokens.RoleAdmin.
+func visitorCookieOptions(conf config.Config) func(*middlewares.VisitorCookieOptions) {
+	return func(o *middlewares.VisitorCookieOptions) {
+		if len(conf.AdminVisitorUUIDs) > 0 {
+			o.Roles = func(visitorUUID string) []string {
+				if slices.Contains(conf.AdminVisitorUUIDs, visitorUUID) {
+					return []string{tokens.RoleAdmin}
+				}
+				return nil
+			}
+		}
+		if conf.VisitorSessionExpire > 0 {
+			o.Expire = conf.VisitorSessionExpire
+		}
+		if conf.VisitorSessionGrace > 0 {
+			o.Grace = conf.VisitorSessionGrace
+		}
+		o.Domain = conf.VisitorCookieDomain
+		o.Secure = conf.VisitorCookieSecure || conf.EnableHTTPS
+		o.SameSite = conf.VisitorCookieSameSiteMode()
+	}
+}
@@ -0,0 +1,265 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/config"
+	"github.com/fsdevblog/shorturl/internal/controllers/middlewares"
+	"github.com/fsdevblog/shorturl/internal/controllers/mocksctrl"
+	"github.com/fsdevblog/shorturl/internal/metrics"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/fsdevblog/shorturl/internal/tokens"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRouter создает маршрутизатор с тестовой конфигурацией: BaseURL и секретом JWT посетителей.
+// Опции дополняют параметры маршрутизатора, например подключают необязательные сервисы.
+func newTestRouter(store ShortURLStore, opts ...func(*RouterParams)) *gin.Engine {
+	params := RouterParams{
+		URLService: store,
+		AppConf:    config.Config{BaseURL: "http://test.com", VisitorJWTSecret: jwtSecret},
+	}
+	for _, opt := range opts {
+		opt(&params)
+	}
+	return SetupRouter(params)
+}
+
+func TestSetupRouter_Metrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mocksctrl.NewMockShortURLStore(ctrl)
+	store.EXPECT().Visit(gomock.Any(), "abcdefgh").Return(&models.URL{URL: "https://example.com"}, nil).Times(2)
+
+	m := metrics.New()
+	router := newTestRouter(store, func(p *RouterParams) {
+		p.Metrics = m
+		p.MetricsHandler = m.Handler()
+	})
+
+	for range 2 {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/abcdefgh", nil))
+		require.Equal(t, http.StatusTemporaryRedirect, w.Code)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/unknown/path", nil))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Result().Cookies(), "/metrics не должен выдавать cookie посетителя")
+
+	body := w.Body.String()
+	assert.Contains(t, body, `shorturl_http_requests_total{method="GET",route="/api/:shortID",status="307"} 2`)
+	assert.Contains(t, body, `shorturl_http_requests_total{method="POST",route="unmatched",status="404"} 1`)
+}
+
+func TestSetupRouter_CORS(t *testing.T) {
+	tests := []struct {
+		name            string
+		method          string
+		origin          string
+		preflight       bool
+		wantStatus      int
+		wantAllowOrigin string
+		wantCookie      bool
+	}{
+		{
+			name:            "preflight from subdomain",
+			method:          http.MethodOptions,
+			origin:          "https://app.example.com",
+			preflight:       true,
+			wantStatus:      http.StatusNoContent,
+			wantAllowOrigin: "https://app.example.com",
+		},
+		{
+			name:       "preflight from bare domain is not matched by wildcard",
+			method:     http.MethodOptions,
+			origin:     "https://example.com",
+			preflight:  true,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "preflight from unknown origin",
+			method:     http.MethodOptions,
+			origin:     "https://evil.com",
+			preflight:  true,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:            "simple request from exact origin",
+			method:          http.MethodGet,
+			origin:          "http://localhost:3000",
+			wantStatus:      http.StatusNoContent,
+			wantAllowOrigin: "http://localhost:3000",
+			wantCookie:      true,
+		},
+		{
+			name:       "simple request from unknown origin",
+			method:     http.MethodGet,
+			origin:     "https://app.example.com.evil.com",
+			wantStatus: http.StatusNoContent,
+			wantCookie: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mocksctrl.NewMockShortURLStore(ctrl)
+			store.EXPECT().GetAllByVisitorUUID(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+
+			router := newTestRouter(store, func(p *RouterParams) {
+				p.AppConf.CORSAllowedOrigins = []string{"https://*.example.com", "http://localhost:3000"}
+				p.AppConf.CORSAllowCredentials = true
+				p.AppConf.CORSMaxAge = 10 * time.Minute
+			})
+
+			req := httptest.NewRequest(tt.method, "/api/user/urls", nil)
+			req.Header.Set("Origin", tt.origin)
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantAllowOrigin, w.Header().Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, tt.wantCookie, len(w.Result().Cookies()) > 0)
+			if tt.wantAllowOrigin == "" {
+				return
+			}
+			assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+			if tt.preflight {
+				assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+				assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Idempotency-Key")
+			} else {
+				assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "Location")
+			}
+		})
+	}
+}
+
+func TestSetupRouter_VisitorSession(t *testing.T) {
+	const visitor = "8a4f3c2e-1b0d-4e9f-a7c6-5d4e3f2a1b0c"
+	tests := []struct {
+		name        string
+		expire      time.Duration // Оставшийся срок токена в cookie запроса; 0 - без cookie
+		wantCookie  bool
+		wantVisitor bool // Посетитель сохраняет UUID
+	}{
+		{
+			name:        "fresh token is kept",
+			expire:      23 * time.Hour,
+			wantVisitor: true,
+		},
+		{
+			name:        "token near expiry is refreshed",
+			expire:      time.Hour,
+			wantCookie:  true,
+			wantVisitor: true,
+		},
+		{
+			name:        "token expired within grace is refreshed",
+			expire:      -time.Hour,
+			wantCookie:  true,
+			wantVisitor: true,
+		},
+		{
+			name:       "token expired beyond grace is replaced",
+			expire:     -3 * time.Hour,
+			wantCookie: true,
+		},
+		{
+			name:       "no token",
+			wantCookie: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mocksctrl.NewMockShortURLStore(ctrl)
+			var gotVisitor string
+			store.EXPECT().GetAllByVisitorUUID(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, visitorUUID string) ([]models.URL, error) {
+					gotVisitor = visitorUUID
+					return nil, nil
+				})
+
+			router := newTestRouter(store, func(p *RouterParams) {
+				p.AppConf.EnableHTTPS = true
+				p.AppConf.VisitorSessionExpire = 24 * time.Hour
+				p.AppConf.VisitorSessionGrace = 2 * time.Hour
+				p.AppConf.VisitorCookieDomain = "example.com"
+				p.AppConf.VisitorCookieSameSite = "lax"
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+			if tt.expire != 0 {
+				token, err := tokens.GenerateVisitorJWT(visitor, tt.expire, []byte(jwtSecret))
+				require.NoError(t, err)
+				req.AddCookie(&http.Cookie{Name: middlewares.VisitorCookieName, Value: token})
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusNoContent, w.Code)
+			assert.Equal(t, tt.wantVisitor, gotVisitor == visitor)
+			cookies := w.Result().Cookies()
+			if !tt.wantCookie {
+				assert.Empty(t, cookies)
+				return
+			}
+			require.Len(t, cookies, 1)
+			cookie := cookies[0]
+			assert.True(t, cookie.Secure, "Secure включается вместе с HTTPS")
+			assert.True(t, cookie.HttpOnly)
+			assert.Equal(t, "example.com", cookie.Domain)
+			assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+			assert.Equal(t, int((24 * time.Hour).Seconds()), cookie.MaxAge)
+
+			token, err := tokens.ValidateVisitorJWT(cookie.Value, []byte(jwtSecret))
+			require.NoError(t, err)
+			assert.Equal(t, gotVisitor, token.Claims.(*tokens.VisitorClaims).UUID) //nolint:errcheck
+		})
+	}
+}
+
+// TestSetupRouter_ReservedAliases проверяет, что пользовательский короткий идентификатор не может совпасть
+// со статическим сегментом маршрута на уровне, где маршрутизатор принимает :shortID.
+func TestSetupRouter_ReservedAliases(t *testing.T) {
+	routes := fullRouter(t).Routes()
+
+	var prefixes []string
+	for _, route := range routes {
+		if prefix, ok := strings.CutSuffix(route.Path, "/:shortID"); ok && route.Method == http.MethodGet {
+			prefixes = append(prefixes, prefix+"/")
+		}
+	}
+	require.NotEmpty(t, prefixes)
+
+	for _, route := range routes {
+		for _, prefix := range prefixes {
+			rest, ok := strings.CutPrefix(route.Path, prefix)
+			if !ok {
+				continue
+			}
+			segment, _, _ := strings.Cut(rest, "/")
+			if segment == "" || strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+				continue
+			}
+			assert.ErrorIs(t, services.ValidateAlias(segment), services.ErrInvalidArgument,
+				"сегмент %q маршрута %s %s доступен как короткий идентификатор", segment, route.Method, route.Path)
+		}
+	}
+}
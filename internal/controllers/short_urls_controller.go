@@ -8,25 +8,28 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"regexp"
+	"time"
 
 	"github.com/fsdevblog/shorturl/internal/controllers/middlewares"
 
 	"github.com/fsdevblog/shorturl/internal/models"
 	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/fsdevblog/shorturl/internal/urlvalidate"
 
 	"github.com/gin-gonic/gin"
 )
 
-// hostnameRegex регулярное выражение для проверки hostname в соответствии с RFC 1123.
-// Исключает корневые доменные имена (без зоны).
-var hostnameRegex = regexp.MustCompile(`^([a-zA-Z0-9](-?[a-zA-Z0-9])*\.)+([a-zA-Z0-9](-?[a-zA-Z0-9])*)$`)
-
 // ShortURLController обрабатывает HTTP запросы для работы с короткими URL.
 // Предоставляет методы для создания, получения и управления короткими URL.
 type ShortURLController struct {
 	urlService ShortURLStore
 	baseURL    string
+	deletions  DeletionQueue
+}
+
+// ShortURLControllerOptions опции ShortURLController и ShortURLV2Controller.
+type ShortURLControllerOptions struct {
+	Deletions DeletionQueue // Очередь фонового удаления, необходима для DeleteUserURLs
 }
 
 // NewShortURLController создает новый экземпляр ShortURLController.
@@ -34,13 +37,23 @@ type ShortURLController struct {
 // Параметры:
 //   - urlService: сервис для работы с URL
 //   - baseURL: базовый URL для генерации коротких ссылок
+//   - opts: функции для настройки опций
 //
 // Возвращает:
 //   - *ShortURLController: новый экземпляр контроллера
-func NewShortURLController(urlService ShortURLStore, baseURL string) *ShortURLController {
+func NewShortURLController(
+	urlService ShortURLStore,
+	baseURL string,
+	opts ...func(*ShortURLControllerOptions),
+) *ShortURLController {
+	var options ShortURLControllerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 	return &ShortURLController{
 		urlService: urlService,
 		baseURL:    baseURL,
+		deletions:  options.Deletions,
 	}
 }
 
@@ -193,19 +206,30 @@ func (s *ShortURLController) BatchCreate(c *gin.Context) {
 }
 
 // Redirect выполняет перенаправление с короткого URL на оригинальный.
+// Если клиент предпочитает application/json (заголовок Accept), вместо перенаправления
+// возвращается описание ссылки, как в Info. Поэтому любой ответ, включая ошибки,
+// содержит Vary: Accept, чтобы кеши не отдавали перенаправление клиенту, ожидающему JSON, и наоборот.
 //
 // Параметры URL:
 //   - shortID: короткий идентификатор URL
 //
 // Коды ответа:
+//   - 200: описание ссылки (URLInfoResponse), только для Accept: application/json
 //   - 307: временное перенаправление
 //   - 404: URL не найден
-//   - 410: URL был удален
+//   - 410: URL был удален или отключен администратором (в теле причина отключения)
+//   - 451: URL отключен по требованию закона (в теле причина отключения)
 //   - 500: внутренняя ошибка сервера
 func (s *ShortURLController) Redirect(c *gin.Context) {
+	c.Writer.Header().Add("Vary", "Accept")
+	if prefersJSON(c) {
+		s.Info(c)
+		return
+	}
+
 	sIdentifier := c.Param("shortID")
 
-	if len(sIdentifier) != models.ShortIdentifierLength {
+	if !services.IsShortIdentifier(sIdentifier) {
 		c.String(http.StatusNotFound, ErrRecordNotFound.Error())
 		return
 	}
@@ -213,7 +237,7 @@ func (s *ShortURLController) Redirect(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
 	defer cancel()
 
-	sURL, err := s.urlService.GetByShortIdentifier(ctx, sIdentifier)
+	sURL, err := s.urlService.Visit(ctx, sIdentifier)
 
 	if err != nil {
 		if errors.Is(err, services.ErrRecordNotFound) {
@@ -225,14 +249,91 @@ func (s *ShortURLController) Redirect(c *gin.Context) {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
 	}
-	if sURL.DeletedAt != nil {
+	if sURL.State() == models.URLStateDeleted {
 		c.AbortWithStatus(http.StatusGone)
 		return
 	}
+	if sURL.State() == models.URLStateDisabled {
+		c.String(inactiveURLStatus(sURL), sURL.DisabledReason)
+		return
+	}
 
 	c.Redirect(http.StatusTemporaryRedirect, sURL.URL)
 }
 
+// URLInfoResponse публичное описание короткой ссылки. Владелец ссылки не раскрывается.
+type URLInfoResponse struct {
+	ShortID      string    `json:"short_id"`
+	ShortURL     string    `json:"short_url"`
+	OriginalURL  string    `json:"original_url,omitempty"` // Адрес перехода, только для действующих ссылок
+	State        string    `json:"state"`                  // active, deleted или disabled
+	RedirectCode int       `json:"redirect_code"`          // Код ответа при переходе по ссылке
+	CreatedAt    time.Time `json:"created_at"`
+	Tags         []string  `json:"tags,omitempty"`
+	// Причина отключения ссылки администратором, только для отключенных ссылок
+	DisabledReason string `json:"disabled_reason,omitempty"`
+}
+
+// Info возвращает описание короткой ссылки без перехода по ней.
+// Переход при этом не учитывается.
+//
+// Параметры URL:
+//   - shortID: короткий идентификатор URL
+//
+// Коды ответа:
+//   - 200: описание ссылки (URLInfoResponse), в том числе удаленной
+//   - 404: URL не найден
+//   - 500: внутренняя ошибка сервера
+func (s *ShortURLController) Info(c *gin.Context) {
+	sIdentifier := c.Param("shortID")
+
+	if !services.IsShortIdentifier(sIdentifier) {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrRecordNotFound.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	sURL, err := s.urlService.GetByShortIdentifier(ctx, sIdentifier)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": ErrRecordNotFound.Error()})
+			return
+		}
+		_ = c.Error(fmt.Errorf("get url info: %w", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+		return
+	}
+
+	info := URLInfoResponse{
+		ShortID:      sURL.ShortIdentifier,
+		ShortURL:     s.getShortURL(c.Request, sURL.ShortIdentifier),
+		State:        string(sURL.State()),
+		RedirectCode: http.StatusTemporaryRedirect,
+		CreatedAt:    sURL.CreatedAt,
+		Tags:         sURL.Tags,
+	}
+	if sURL.State() == models.URLStateActive {
+		info.OriginalURL = sURL.URL
+	} else {
+		info.RedirectCode = inactiveURLStatus(sURL)
+	}
+	if sURL.State() == models.URLStateDisabled {
+		info.DisabledReason = sURL.DisabledReason
+	}
+	c.JSON(http.StatusOK, info)
+}
+
+// inactiveURLStatus возвращает код ответа при переходе по недействующей ссылке:
+// 451 для ссылки, отключенной по требованию закона, иначе 410.
+func inactiveURLStatus(sURL *models.URL) int {
+	if sURL.State() == models.URLStateDisabled && sURL.DisabledLegal {
+		return http.StatusUnavailableForLegalReasons
+	}
+	return http.StatusGone
+}
+
 type createParams struct {
 	URL string `json:"url"`
 }
@@ -288,12 +389,15 @@ func (s *ShortURLController) CreateShortURL(c *gin.Context) {
 
 // DeleteUserURLs удаляет URL пользователя.
 // Принимает массив идентификаторов URL в формате JSON.
+// Ссылки удаляются в фоне через очередь удаления, в ответе возвращается задача
+// (DeletionJobResponse) и заголовок Location с адресом её состояния.
 //
 // Коды ответа:
 //   - 202: запрос на удаление принят
 //   - 400: некорректный запрос
 //   - 403: доступ запрещен
 //   - 500: внутренняя ошибка сервера
+//   - 503: очередь удаления переполнена или остановлена
 func (s *ShortURLController) DeleteUserURLs(c *gin.Context) {
 	var ids []string
 	if bindErr := c.ShouldBindJSON(&ids); bindErr != nil || len(ids) == 0 {
@@ -312,12 +416,20 @@ func (s *ShortURLController) DeleteUserURLs(c *gin.Context) {
 
 	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
 	defer cancel()
-	if err := s.urlService.MarkAsDeleted(ctx, ids, visitorUUID); err != nil {
-		_ = c.Error(fmt.Errorf("delete user urls: %w", err))
+
+	job, err := s.deletions.Enqueue(ctx, visitorUUID, ids)
+	if err != nil {
+		_ = c.Error(fmt.Errorf("enqueue user urls deletion: %w", err))
+		if isDeletionQueueUnavailable(err) {
+			c.Header("Retry-After", deletionRetryAfter)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "deletion queue is unavailable, retry later"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
 		return
 	}
-	c.Status(http.StatusAccepted)
+	c.Header("Location", deletionJobLocation(job.ID))
+	c.JSON(http.StatusAccepted, newDeletionJobResponse(job))
 }
 
 // bindCreateParams байндит параметры создания URL из запроса.
@@ -354,47 +466,10 @@ func (s *ShortURLController) bindCreateParams(c *gin.Context) (*createParams, er
 // Возвращает:
 //   - string: полный короткий URL
 func (s *ShortURLController) getShortURL(r *http.Request, shortID string) string {
-	var scheme = "http"
-	if r.TLS != nil {
-		scheme = "https"
-	}
-	if s.baseURL == "" {
-		return fmt.Sprintf("%s://%s/%s", scheme, r.Host, shortID)
-	}
-	return fmt.Sprintf("%s/%s", s.baseURL, shortID)
+	return buildShortURL(s.baseURL, r, shortID)
 }
 
-// validateURL проверяет корректность URL.
-//
-// Параметры:
-//   - rawURL: URL для проверки
-//
-// Возвращает:
-//   - *url.URL: распарсенный URL
-//   - error: ошибка валидации
-//
-// Правила валидации:
-//   - URL должен иметь схему http или https
-//   - URL должен содержать хост
-//   - Hostname должен соответствовать RFC 1123 или быть localhost
+// validateURL проверяет корректность URL (см. urlvalidate.Validate).
 func validateURL(rawURL string) (*url.URL, error) {
-	parsedURL, err := url.ParseRequestURI(rawURL)
-
-	if err != nil {
-		return nil, errors.New("invalid URL format")
-	}
-
-	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-		return nil, errors.New("URL must have http or https scheme")
-	}
-
-	if parsedURL.Host == "" {
-		return nil, errors.New("URL must have a host")
-	}
-
-	if parsedURL.Hostname() != "localhost" && !hostnameRegex.MatchString(parsedURL.Hostname()) {
-		return nil, errors.New("invalid hostname")
-	}
-
-	return parsedURL, nil
+	return urlvalidate.Validate(rawURL) //nolint:wrapcheck
 }
@@ -41,6 +41,7 @@ const (
 type ShortURLControllerSuite struct {
 	suite.Suite
 	mockShortURLStore *mocksctrl.MockShortURLStore
+	mockDeletions     *mocksctrl.MockDeletionQueue
 	router            *gin.Engine
 	config            *config.Config
 }
@@ -54,6 +55,7 @@ func (s *ShortURLControllerSuite) SetupTest() {
 	}
 
 	s.mockShortURLStore = mocksctrl.NewMockShortURLStore(mockShortURL)
+	s.mockDeletions = mocksctrl.NewMockDeletionQueue(mockShortURL)
 
 	appConf := config.Config{
 		ServerAddress:    ":80",
@@ -64,6 +66,7 @@ func (s *ShortURLControllerSuite) SetupTest() {
 	s.router = SetupRouter(RouterParams{
 		URLService:  s.mockShortURLStore,
 		PingService: nil,
+		Deletions:   s.mockDeletions,
 		AppConf:     appConf,
 		Logger: logs.MustNew(func(o *logs.LoggerOptions) {
 			o.Level = logs.LevelTypeError
@@ -339,39 +342,66 @@ func (s *ShortURLControllerSuite) TestShortURLController_CreateShortURL() {
 func (s *ShortURLControllerSuite) TestShortURLController_Redirect() {
 	validShortID := "12345678"
 	notExistShortID := "12345671"
-	inValidShortID := "123"
+	inValidShortID := "bad.id"
 	deletedSID := "deleted1"
+	disabledSID := "disable1"
+	legalSID := "legal123"
 
 	redirectTo := "https://test.com/test/123"
 
 	s.mockShortURLStore.EXPECT().
-		GetByShortIdentifier(gomock.Any(), validShortID).
+		Visit(gomock.Any(), validShortID).
 		Return(&models.URL{ShortIdentifier: validShortID, URL: redirectTo}, nil).
 		Times(1)
 
 	s.mockShortURLStore.EXPECT().
-		GetByShortIdentifier(gomock.Any(), notExistShortID).
+		Visit(gomock.Any(), notExistShortID).
 		Return(nil, services.ErrRecordNotFound).
 		Times(1)
 	now := time.Now()
 	s.mockShortURLStore.EXPECT().
-		GetByShortIdentifier(gomock.Any(), deletedSID).
+		Visit(gomock.Any(), deletedSID).
 		Return(&models.URL{
 			DeletedAt:       &now,
 			URL:             gofakeit.URL(),
 			ShortIdentifier: deletedSID,
 		}, nil)
+	s.mockShortURLStore.EXPECT().
+		Visit(gomock.Any(), disabledSID).
+		Return(&models.URL{
+			DisabledAt:      &now,
+			DisabledReason:  "phishing",
+			URL:             gofakeit.URL(),
+			ShortIdentifier: disabledSID,
+		}, nil)
+	s.mockShortURLStore.EXPECT().
+		Visit(gomock.Any(), legalSID).
+		Return(&models.URL{
+			DisabledAt:      &now,
+			DisabledReason:  "court order",
+			DisabledLegal:   true,
+			URL:             gofakeit.URL(),
+			ShortIdentifier: legalSID,
+		}, nil)
 
 	tests := []struct {
 		name       string
 		requestURI string
 		wantStatus int
+		wantBody   string
 	}{
 		{name: "valid", requestURI: validShortID, wantStatus: http.StatusTemporaryRedirect},
 		{name: "invalid", requestURI: inValidShortID, wantStatus: http.StatusNotFound},
 		{name: "notExistShortID", requestURI: notExistShortID, wantStatus: http.StatusNotFound},
 		{name: "root page", requestURI: "", wantStatus: http.StatusNotFound},
 		{name: "deleted", requestURI: deletedSID, wantStatus: http.StatusGone},
+		{name: "disabled", requestURI: disabledSID, wantStatus: http.StatusGone, wantBody: "phishing"},
+		{
+			name:       "disabled for legal reasons",
+			requestURI: legalSID,
+			wantStatus: http.StatusUnavailableForLegalReasons,
+			wantBody:   "court order",
+		},
 	}
 
 	for _, tt := range tests {
@@ -394,6 +424,135 @@ func (s *ShortURLControllerSuite) TestShortURLController_Redirect() {
 			} else {
 				s.Empty(res.Header.Get("Location"))
 			}
+			if tt.wantBody != "" {
+				s.Equal(tt.wantBody, string(body))
+			}
+			// Ответ зависит от Accept при любом исходе, иначе кеш отдаст перенаправление клиенту JSON.
+			if tt.requestURI != "" {
+				s.Contains(res.Header.Values("Vary"), "Accept")
+			}
+		})
+	}
+}
+
+func (s *ShortURLControllerSuite) TestShortURLController_Info() {
+	activeSID := "12345678"
+	deletedSID := "deleted1"
+	legalSID := "legal123"
+	notExistSID := "12345671"
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	s.mockShortURLStore.EXPECT().
+		GetByShortIdentifier(gomock.Any(), activeSID).
+		Return(&models.URL{
+			ShortIdentifier: activeSID,
+			URL:             "https://test.com/a",
+			VisitorUUID:     "owner-uuid",
+			CreatedAt:       createdAt,
+			Tags:            []string{"news"},
+		}, nil).
+		Times(2)
+	s.mockShortURLStore.EXPECT().
+		GetByShortIdentifier(gomock.Any(), deletedSID).
+		Return(&models.URL{
+			ShortIdentifier: deletedSID,
+			URL:             "https://test.com/b",
+			VisitorUUID:     "owner-uuid",
+			CreatedAt:       createdAt,
+			DeletedAt:       &createdAt,
+		}, nil)
+	s.mockShortURLStore.EXPECT().
+		GetByShortIdentifier(gomock.Any(), legalSID).
+		Return(&models.URL{
+			ShortIdentifier: legalSID,
+			URL:             "https://test.com/c",
+			VisitorUUID:     "owner-uuid",
+			CreatedAt:       createdAt,
+			DisabledAt:      &createdAt,
+			DisabledReason:  "court order",
+			DisabledLegal:   true,
+		}, nil)
+	s.mockShortURLStore.EXPECT().
+		GetByShortIdentifier(gomock.Any(), notExistSID).
+		Return(nil, services.ErrRecordNotFound)
+	s.mockShortURLStore.EXPECT().
+		Visit(gomock.Any(), activeSID).
+		Return(&models.URL{ShortIdentifier: activeSID, URL: "https://test.com/a"}, nil)
+
+	tests := []struct {
+		name             string
+		url              string
+		accept           string
+		wantStatus       int
+		wantState        string
+		wantRedirectCode int
+		wantOriginalURL  string
+		wantReason       string
+	}{
+		{
+			name:             "info",
+			url:              "/" + activeSID + "/info",
+			wantStatus:       http.StatusOK,
+			wantState:        "active",
+			wantRedirectCode: http.StatusTemporaryRedirect,
+			wantOriginalURL:  "https://test.com/a",
+		},
+		{
+			name:             "api with accept json",
+			url:              "/api/" + activeSID,
+			accept:           "application/json",
+			wantStatus:       http.StatusOK,
+			wantState:        "active",
+			wantRedirectCode: http.StatusTemporaryRedirect,
+			wantOriginalURL:  "https://test.com/a",
+		},
+		{
+			name:             "deleted",
+			url:              "/api/" + deletedSID + "/info",
+			wantStatus:       http.StatusOK,
+			wantState:        "deleted",
+			wantRedirectCode: http.StatusGone,
+		},
+		{
+			name:             "disabled for legal reasons",
+			url:              "/" + legalSID + "/info",
+			wantStatus:       http.StatusOK,
+			wantState:        "disabled",
+			wantRedirectCode: http.StatusUnavailableForLegalReasons,
+			wantReason:       "court order",
+		},
+		{name: "not found", url: "/" + notExistSID + "/info", wantStatus: http.StatusNotFound},
+		{name: "invalid", url: "/bad.id/info", wantStatus: http.StatusNotFound},
+		{
+			name:       "browser is redirected",
+			url:        "/" + activeSID,
+			accept:     "text/html,application/xhtml+xml,*/*;q=0.8",
+			wantStatus: http.StatusTemporaryRedirect,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			res := s.makeRequest(requestFields{Method: http.MethodGet, URL: tt.url}, withAccept(tt.accept))
+			defer res.Body.Close()
+
+			body, _ := io.ReadAll(res.Body)
+			s.Require().Equal(tt.wantStatus, res.StatusCode, "Answer:", string(body))
+			if tt.accept != "" {
+				s.Contains(res.Header.Values("Vary"), "Accept")
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			s.NotContains(string(body), "owner-uuid")
+
+			var info URLInfoResponse
+			s.Require().NoError(json.Unmarshal(body, &info))
+			s.Equal(tt.wantState, info.State)
+			s.Equal(tt.wantRedirectCode, info.RedirectCode)
+			s.Equal(tt.wantOriginalURL, info.OriginalURL)
+			s.Equal(tt.wantReason, info.DisabledReason)
+			s.True(createdAt.Equal(info.CreatedAt))
 		})
 	}
 }
@@ -432,8 +591,9 @@ func (s *ShortURLControllerSuite) TestShortURLController_DeleteUserURLs() {
 		},
 	}
 
-	s.mockShortURLStore.EXPECT().MarkAsDeleted(gomock.Any(), validShortIDs, visitorUUID).Return(nil)
-	s.mockShortURLStore.EXPECT().MarkAsDeleted(gomock.Any(), withUnexistShortID, visitorUUID).Return(nil)
+	job := &models.DeletionJob{ID: gofakeit.UUID(), Status: models.DeletionJobPending, CreatedAt: time.Now()}
+	s.mockDeletions.EXPECT().Enqueue(gomock.Any(), visitorUUID, validShortIDs).Return(job, nil)
+	s.mockDeletions.EXPECT().Enqueue(gomock.Any(), visitorUUID, withUnexistShortID).Return(job, nil)
 
 	for _, test := range tests {
 		s.Run(test.name, func() {
@@ -507,6 +667,7 @@ type requestFields struct {
 
 type requestOptions struct {
 	contentType string
+	accept      string
 	gziped      bool
 	cookies     []*http.Cookie
 }
@@ -517,6 +678,12 @@ func withContentType(ct string) func(*requestOptions) {
 	}
 }
 
+func withAccept(accept string) func(*requestOptions) {
+	return func(fn *requestOptions) {
+		fn.accept = accept
+	}
+}
+
 func withGzip(b bool) func(*requestOptions) {
 	return func(fn *requestOptions) {
 		fn.gziped = b
@@ -569,6 +736,9 @@ func (s *ShortURLControllerSuite) makeRequest(fields requestFields, opts ...func
 	if options.contentType != "" {
 		request.Header.Set("Content-Type", options.contentType)
 	}
+	if options.accept != "" {
+		request.Header.Set("Accept", options.accept)
+	}
 	if options.gziped {
 		request.Header.Set("Content-Encoding", "gzip")
 		request.Header.Set("Accept-Encoding", "gzip")
@@ -584,7 +754,9 @@ func (s *ShortURLControllerSuite) makeRequest(fields requestFields, opts ...func
 
 	s.router.ServeHTTP(recorder, request)
 
-	return recorder.Result()
+	res := recorder.Result()
+	assertResponseMatchesSpec(s.T(), request, res)
+	return res
 }
 
 func TestShortURLControllerSuite(t *testing.T) {
@@ -0,0 +1,327 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ShortURLV2Controller обрабатывает запросы /api/v2 для работы с короткими URL.
+// В отличие от ShortURLController все ошибки отдаются в формате application/problem+json
+// (см. Problem), а внутренние детали ошибок клиенту не раскрываются.
+type ShortURLV2Controller struct {
+	urlService ShortURLStore
+	baseURL    string
+	deletions  DeletionQueue
+}
+
+// NewShortURLV2Controller создает новый экземпляр ShortURLV2Controller.
+//
+// Параметры:
+//   - urlService: сервис для работы с URL
+//   - baseURL: базовый URL для генерации коротких ссылок
+//   - opts: функции для настройки опций
+//
+// Возвращает:
+//   - *ShortURLV2Controller: новый экземпляр контроллера
+func NewShortURLV2Controller(
+	urlService ShortURLStore,
+	baseURL string,
+	opts ...func(*ShortURLControllerOptions),
+) *ShortURLV2Controller {
+	var options ShortURLControllerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &ShortURLV2Controller{urlService: urlService, baseURL: baseURL, deletions: options.Deletions}
+}
+
+// ShortenV2Response структура ответа на создание короткого URL.
+type ShortenV2Response struct {
+	Result  string `json:"result"`  // Короткий URL
+	Created bool   `json:"created"` // false, если URL был сокращен ранее
+}
+
+// BatchCreateV2Response структура элемента ответа на пакетное создание коротких URL.
+type BatchCreateV2Response struct {
+	CorrelationID string `json:"correlation_id"`
+	ShortURL      string `json:"short_url"`
+	Created       bool   `json:"created"` // false, если URL был сокращен ранее
+}
+
+// CreateShortURL создает короткий URL. Принимает только JSON вида {"url": "..."}.
+//
+// Коды ответа:
+//   - 201: URL создан
+//   - 200: URL был сокращен ранее, возвращается существующая ссылка
+//   - 400: invalid_request - тело запроса не разобрано
+//   - 401: unauthorized - посетитель не определен
+//   - 422: validation_failed - некорректный URL
+//   - 500: internal - внутренняя ошибка сервера
+func (s *ShortURLV2Controller) CreateShortURL(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		abortWithProblem(c, http.StatusUnauthorized, ProblemUnauthorized, "visitor is not identified")
+		return
+	}
+
+	var params createParams
+	if bindErr := c.ShouldBindJSON(&params); bindErr != nil {
+		_ = c.Error(fmt.Errorf("bind params: %w", bindErr))
+		abortWithProblem(c, http.StatusBadRequest, ProblemInvalidRequest, "request body must be a JSON object")
+		return
+	}
+	if fieldErr := urlFieldError("/url", params.URL); fieldErr != nil {
+		abortWithProblem(c, http.StatusUnprocessableEntity, ProblemValidation, "request is invalid", *fieldErr)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	sURL, isNewRecord, err := s.urlService.Create(ctx, visitorUUID, params.URL)
+	if err != nil {
+		abortWithInternalProblem(c, fmt.Errorf("create url: %w", err))
+		return
+	}
+
+	statusCode := http.StatusCreated
+	if !isNewRecord {
+		statusCode = http.StatusOK
+	}
+	c.JSON(statusCode, ShortenV2Response{
+		Result:  buildShortURL(s.baseURL, c.Request, sURL.ShortIdentifier),
+		Created: isNewRecord,
+	})
+}
+
+// BatchCreate создает несколько коротких URL. Порядок ответа совпадает с порядком запроса.
+// Ошибки всех некорректных элементов возвращаются разом в Problem.Errors.
+//
+// Коды ответа:
+//   - 201: URL созданы (ранее сокращенные отмечены created = false)
+//   - 400: invalid_request - тело запроса не разобрано
+//   - 401: unauthorized - посетитель не определен
+//   - 422: validation_failed - пустой запрос или некорректные элементы
+//   - 500: internal - внутренняя ошибка сервера
+func (s *ShortURLV2Controller) BatchCreate(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		abortWithProblem(c, http.StatusUnauthorized, ProblemUnauthorized, "visitor is not identified")
+		return
+	}
+
+	var params []BatchCreateParams
+	if bindErr := c.ShouldBindJSON(&params); bindErr != nil {
+		_ = c.Error(fmt.Errorf("bind params: %w", bindErr))
+		abortWithProblem(c, http.StatusBadRequest, ProblemInvalidRequest, "request body must be a JSON array")
+		return
+	}
+	if len(params) == 0 {
+		abortWithProblem(c, http.StatusUnprocessableEntity, ProblemValidation, "request is invalid", FieldError{
+			Pointer: "", Code: FieldRequired, Detail: "at least one item is required",
+		})
+		return
+	}
+
+	var fieldErrors []FieldError
+	rawURLs := make([]string, len(params))
+	for i, param := range params {
+		pointer := "/" + strconv.Itoa(i)
+		if param.CorrelationID == "" {
+			fieldErrors = append(fieldErrors, FieldError{
+				Pointer: pointer + "/correlation_id", Code: FieldRequired, Detail: "correlation_id is required",
+			})
+		}
+		if fieldErr := urlFieldError(pointer+"/original_url", param.OriginalURL); fieldErr != nil {
+			fieldErrors = append(fieldErrors, *fieldErr)
+		}
+		rawURLs[i] = param.OriginalURL
+	}
+	if len(fieldErrors) > 0 {
+		abortWithProblem(c, http.StatusUnprocessableEntity, ProblemValidation, "request is invalid", fieldErrors...)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	batchResponse, err := s.urlService.BatchCreate(ctx, visitorUUID, rawURLs)
+	if err != nil {
+		abortWithInternalProblem(c, fmt.Errorf("batch create urls: %w", err))
+		return
+	}
+
+	response := make([]BatchCreateV2Response, batchResponse.Len())
+	var itemsErr error
+	batchResponse.ReadResponse(func(i int, m models.URL, err error) {
+		if err != nil && !errors.Is(err, services.ErrDuplicateKey) {
+			itemsErr = errors.Join(itemsErr, err)
+		}
+		response[i] = BatchCreateV2Response{
+			CorrelationID: params[i].CorrelationID,
+			ShortURL:      buildShortURL(s.baseURL, c.Request, m.ShortIdentifier),
+			Created:       err == nil,
+		}
+	})
+	if itemsErr != nil {
+		abortWithInternalProblem(c, fmt.Errorf("batch create urls: %w", itemsErr))
+		return
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// Redirect выполняет перенаправление с короткого URL на оригинальный.
+//
+// Параметры URL:
+//   - shortID: короткий идентификатор URL
+//
+// Коды ответа:
+//   - 307: временное перенаправление
+//   - 404: not_found - URL не найден
+//   - 410: gone - URL был удален или отключен администратором (в detail причина отключения)
+//   - 451: unavailable_for_legal_reasons - URL отключен по требованию закона
+//   - 500: internal - внутренняя ошибка сервера
+func (s *ShortURLV2Controller) Redirect(c *gin.Context) {
+	shortID := c.Param("shortID")
+	if !services.IsShortIdentifier(shortID) {
+		abortWithProblem(c, http.StatusNotFound, ProblemNotFound, "short url not found")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	sURL, err := s.urlService.Visit(ctx, shortID)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			abortWithProblem(c, http.StatusNotFound, ProblemNotFound, "short url not found")
+			return
+		}
+		abortWithInternalProblem(c, fmt.Errorf("visit url: %w", err))
+		return
+	}
+	if sURL.State() == models.URLStateDeleted {
+		abortWithProblem(c, http.StatusGone, ProblemGone, "short url was deleted")
+		return
+	}
+	if sURL.State() == models.URLStateDisabled {
+		if status := inactiveURLStatus(sURL); status == http.StatusUnavailableForLegalReasons {
+			abortWithProblem(c, status, ProblemLegal, sURL.DisabledReason)
+		} else {
+			abortWithProblem(c, status, ProblemGone, sURL.DisabledReason)
+		}
+		return
+	}
+	c.Redirect(http.StatusTemporaryRedirect, sURL.URL)
+}
+
+// UserURLs возвращает список URL текущего посетителя.
+//
+// Коды ответа:
+//   - 200: список URL
+//   - 204: у посетителя нет URL
+//   - 401: unauthorized - посетитель не определен
+//   - 500: internal - внутренняя ошибка сервера
+func (s *ShortURLV2Controller) UserURLs(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		abortWithProblem(c, http.StatusUnauthorized, ProblemUnauthorized, "visitor is not identified")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	urls, err := s.urlService.GetAllByVisitorUUID(ctx, visitorUUID)
+	if err != nil {
+		abortWithInternalProblem(c, fmt.Errorf("get user urls: %w", err))
+		return
+	}
+	if len(urls) == 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	response := make([]URLResponse, len(urls))
+	for i, u := range urls {
+		response[i] = URLResponse{
+			ShortURL:    buildShortURL(s.baseURL, c.Request, u.ShortIdentifier),
+			OriginalURL: u.URL,
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteUserURLs помечает URL текущего посетителя удаленными.
+// Принимает JSON массив коротких идентификаторов. Ссылки удаляются в фоне через очередь удаления,
+// в ответе возвращается задача (DeletionJobResponse).
+//
+// Коды ответа:
+//   - 202: запрос на удаление принят
+//   - 400: invalid_request - тело запроса не разобрано
+//   - 401: unauthorized - посетитель не определен
+//   - 422: validation_failed - пустой список
+//   - 500: internal - внутренняя ошибка сервера
+//   - 503: unavailable - очередь удаления переполнена или остановлена
+func (s *ShortURLV2Controller) DeleteUserURLs(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		abortWithProblem(c, http.StatusUnauthorized, ProblemUnauthorized, "visitor is not identified")
+		return
+	}
+
+	var ids []string
+	if bindErr := c.ShouldBindJSON(&ids); bindErr != nil {
+		_ = c.Error(fmt.Errorf("bind params: %w", bindErr))
+		abortWithProblem(c, http.StatusBadRequest, ProblemInvalidRequest, "request body must be a JSON array of strings")
+		return
+	}
+	if len(ids) == 0 {
+		abortWithProblem(c, http.StatusUnprocessableEntity, ProblemValidation, "request is invalid", FieldError{
+			Pointer: "", Code: FieldRequired, Detail: "at least one short id is required",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	job, err := s.deletions.Enqueue(ctx, visitorUUID, ids)
+	if err != nil {
+		if isDeletionQueueUnavailable(err) {
+			_ = c.Error(fmt.Errorf("enqueue user urls deletion: %w", err))
+			c.Header("Retry-After", deletionRetryAfter)
+			abortWithProblem(c, http.StatusServiceUnavailable, ProblemUnavailable, "deletion queue is unavailable")
+			return
+		}
+		abortWithInternalProblem(c, fmt.Errorf("enqueue user urls deletion: %w", err))
+		return
+	}
+	c.Header("Location", deletionJobLocation(job.ID))
+	c.JSON(http.StatusAccepted, newDeletionJobResponse(job))
+}
+
+// urlFieldError проверяет URL из поля запроса.
+//
+// Параметры:
+//   - pointer: JSON Pointer на поле
+//   - rawURL: значение поля
+//
+// Возвращает:
+//   - *FieldError: ошибка поля или nil, если URL корректен
+func urlFieldError(pointer string, rawURL string) *FieldError {
+	if rawURL == "" {
+		return &FieldError{Pointer: pointer, Code: FieldRequired, Detail: "url is required"}
+	}
+	if _, err := validateURL(rawURL); err != nil {
+		return &FieldError{Pointer: pointer, Code: FieldInvalidURL, Detail: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/middlewares"
+	"github.com/fsdevblog/shorturl/internal/controllers/mocksctrl"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errLeaked ошибка хранилища, текст которой не должен попасть клиенту.
+var errLeaked = errors.New("pq: connection refused to 10.0.0.5") //nolint:gochecknoglobals
+
+func TestShortURLV2Controller(t *testing.T) {
+	deletedAt := time.Now()
+
+	tests := []struct {
+		name        string
+		method      string
+		url         string
+		body        string
+		requestID   string
+		mock        func(store *mocksctrl.MockShortURLStore)
+		deletions   func(q *mocksctrl.MockDeletionQueue)
+		wantStatus  int
+		wantCode    ProblemCode
+		wantPointer []string
+		wantBody    string
+	}{
+		{
+			name:       "shorten created",
+			method:     http.MethodPost,
+			url:        "/api/v2/shorten",
+			body:       `{"url":"https://example.com"}`,
+			wantStatus: http.StatusCreated,
+			mock: func(store *mocksctrl.MockShortURLStore) {
+				store.EXPECT().Create(gomock.Any(), gomock.Any(), "https://example.com").
+					Return(&models.URL{ShortIdentifier: "abcdefgh"}, true, nil)
+			},
+			wantBody: `{"result":"http://test.com/abcdefgh","created":true}`,
+		},
+		{
+			name:       "shorten existing",
+			method:     http.MethodPost,
+			url:        "/api/v2/shorten",
+			body:       `{"url":"https://example.com"}`,
+			wantStatus: http.StatusOK,
+			mock: func(store *mocksctrl.MockShortURLStore) {
+				store.EXPECT().Create(gomock.Any(), gomock.Any(), "https://example.com").
+					Return(&models.URL{ShortIdentifier: "abcdefgh"}, false, nil)
+			},
+			wantBody: `{"result":"http://test.com/abcdefgh","created":false}`,
+		},
+		{
+			name:       "shorten plain text body",
+			method:     http.MethodPost,
+			url:        "/api/v2/shorten",
+			body:       `https://example.com`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   ProblemInvalidRequest,
+		},
+		{
+			name:        "shorten invalid url",
+			method:      http.MethodPost,
+			url:         "/api/v2/shorten",
+			body:        `{"url":"ftp://example.com"}`,
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantCode:    ProblemValidation,
+			wantPointer: []string{"/url"},
+		},
+		{
+			name:       "shorten internal error is not leaked",
+			method:     http.MethodPost,
+			url:        "/api/v2/shorten",
+			body:       `{"url":"https://example.com"}`,
+			requestID:  "client-request-1",
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   ProblemInternal,
+			mock: func(store *mocksctrl.MockShortURLStore) {
+				store.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, false, errLeaked)
+			},
+		},
+		{
+			name:       "batch created with existing item",
+			method:     http.MethodPost,
+			url:        "/api/v2/shorten/batch",
+			body:       `[{"correlation_id":"1","original_url":"https://a.com"},{"correlation_id":"2","original_url":"https://b.com"}]`,
+			wantStatus: http.StatusCreated,
+			mock: func(store *mocksctrl.MockShortURLStore) {
+				res := services.NewBatchExecResponse[models.URL](2)
+				res.Set(services.BatchResponseItem[models.URL]{
+					Item: models.URL{URL: "https://a.com", ShortIdentifier: "aaaaaaaa"},
+				}, 0)
+				res.Set(services.BatchResponseItem[models.URL]{
+					Item: models.URL{URL: "https://b.com", ShortIdentifier: "bbbbbbbb"},
+					Err:  services.ErrDuplicateKey,
+				}, 1)
+				store.EXPECT().BatchCreate(gomock.Any(), gomock.Any(), []string{"https://a.com", "https://b.com"}).
+					Return(services.NewBatchExecResponseURL(res), nil)
+			},
+			wantBody: `[{"correlation_id":"1","short_url":"http://test.com/aaaaaaaa","created":true},` +
+				`{"correlation_id":"2","short_url":"http://test.com/bbbbbbbb","created":false}]`,
+		},
+		{
+			name:        "batch reports every invalid field",
+			method:      http.MethodPost,
+			url:         "/api/v2/shorten/batch",
+			body:        `[{"correlation_id":"1","original_url":"https://a.com"},{"original_url":"bad"},{"correlation_id":"3"}]`,
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantCode:    ProblemValidation,
+			wantPointer: []string{"/1/correlation_id", "/1/original_url", "/2/original_url"},
+		},
+		{
+			name:        "batch empty",
+			method:      http.MethodPost,
+			url:         "/api/v2/shorten/batch",
+			body:        `[]`,
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantCode:    ProblemValidation,
+			wantPointer: []string{""},
+		},
+		{
+			name:       "redirect",
+			method:     http.MethodGet,
+			url:        "/api/v2/abcdefgh",
+			wantStatus: http.StatusTemporaryRedirect,
+			mock: func(store *mocksctrl.MockShortURLStore) {
+				store.EXPECT().Visit(gomock.Any(), "abcdefgh").Return(&models.URL{URL: "https://example.com"}, nil)
+			},
+		},
+		{
+			name:       "redirect invalid short id",
+			method:     http.MethodGet,
+			url:        "/api/v2/bad.id",
+			wantStatus: http.StatusNotFound,
+			wantCode:   ProblemNotFound,
+		},
+		{
+			name:       "redirect not found",
+			method:     http.MethodGet,
+			url:        "/api/v2/abcdefgh",
+			wantStatus: http.StatusNotFound,
+			wantCode:   ProblemNotFound,
+			mock: func(store *mocksctrl.MockShortURLStore) {
+				store.EXPECT().Visit(gomock.Any(), "abcdefgh").
+					Return(nil, errors.Join(services.ErrRecordNotFound, repositories.ErrNotFound))
+			},
+		},
+		{
+			name:       "redirect deleted",
+			method:     http.MethodGet,
+			url:        "/api/v2/abcdefgh",
+			wantStatus: http.StatusGone,
+			wantCode:   ProblemGone,
+			mock: func(store *mocksctrl.MockShortURLStore) {
+				store.EXPECT().Visit(gomock.Any(), "abcdefgh").
+					Return(&models.URL{URL: "https://example.com", DeletedAt: &deletedAt}, nil)
+			},
+		},
+		{
+			name:       "redirect disabled",
+			method:     http.MethodGet,
+			url:        "/api/v2/abcdefgh",
+			wantStatus: http.StatusGone,
+			wantCode:   ProblemGone,
+			mock: func(store *mocksctrl.MockShortURLStore) {
+				store.EXPECT().Visit(gomock.Any(), "abcdefgh").Return(&models.URL{
+					URL:            "https://example.com",
+					DisabledAt:     &deletedAt,
+					DisabledReason: "phishing",
+				}, nil)
+			},
+		},
+		{
+			name:       "redirect disabled for legal reasons",
+			method:     http.MethodGet,
+			url:        "/api/v2/abcdefgh",
+			wantStatus: http.StatusUnavailableForLegalReasons,
+			wantCode:   ProblemLegal,
+			mock: func(store *mocksctrl.MockShortURLStore) {
+				store.EXPECT().Visit(gomock.Any(), "abcdefgh").Return(&models.URL{
+					URL:            "https://example.com",
+					DisabledAt:     &deletedAt,
+					DisabledReason: "court order",
+					DisabledLegal:  true,
+				}, nil)
+			},
+		},
+		{
+			name:       "redirect internal error is not leaked",
+			method:     http.MethodGet,
+			url:        "/api/v2/abcdefgh",
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   ProblemInternal,
+			mock: func(store *mocksctrl.MockShortURLStore) {
+				store.EXPECT().Visit(gomock.Any(), "abcdefgh").Return(nil, errLeaked)
+			},
+		},
+		{
+			name:       "user urls",
+			method:     http.MethodGet,
+			url:        "/api/v2/user/urls",
+			wantStatus: http.StatusOK,
+			mock: func(store *mocksctrl.MockShortURLStore) {
+				store.EXPECT().GetAllByVisitorUUID(gomock.Any(), gomock.Any()).
+					Return([]models.URL{{URL: "https://example.com", ShortIdentifier: "abcdefgh"}}, nil)
+			},
+			wantBody: `[{"short_url":"http://test.com/abcdefgh","original_url":"https://example.com"}]`,
+		},
+		{
+			name:        "delete user urls empty",
+			method:      http.MethodDelete,
+			url:         "/api/v2/user/urls",
+			body:        `[]`,
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantCode:    ProblemValidation,
+			wantPointer: []string{""},
+		},
+		{
+			name:       "delete user urls",
+			method:     http.MethodDelete,
+			url:        "/api/v2/user/urls",
+			body:       `["abcdefgh"]`,
+			wantStatus: http.StatusAccepted,
+			deletions: func(q *mocksctrl.MockDeletionQueue) {
+				q.EXPECT().Enqueue(gomock.Any(), gomock.Any(), []string{"abcdefgh"}).
+					Return(&models.DeletionJob{ID: testJobID, Status: models.DeletionJobPending}, nil)
+			},
+		},
+		{
+			name:       "unknown v2 route",
+			method:     http.MethodGet,
+			url:        "/api/v2/unknown/route",
+			wantStatus: http.StatusNotFound,
+			wantCode:   ProblemNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mocksctrl.NewMockShortURLStore(ctrl)
+			if tt.mock != nil {
+				tt.mock(store)
+			}
+			deletions := mocksctrl.NewMockDeletionQueue(ctrl)
+			if tt.deletions != nil {
+				tt.deletions(deletions)
+			}
+			router := newTestRouter(store, func(p *RouterParams) { p.Deletions = deletions })
+
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if tt.requestID != "" {
+				req.Header.Set(middlewares.RequestIDHeader, tt.requestID)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			requestID := w.Header().Get(middlewares.RequestIDHeader)
+			require.NotEmpty(t, requestID)
+			if tt.requestID != "" {
+				assert.Equal(t, tt.requestID, requestID)
+			}
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+
+			if tt.wantCode != "" {
+				assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+				assert.NotContains(t, w.Body.String(), errLeaked.Error())
+
+				var problem Problem
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+				assert.Equal(t, tt.wantCode, problem.Code)
+				assert.Equal(t, tt.wantStatus, problem.Status)
+				assert.Equal(t, "urn:shorturl:problem:"+string(tt.wantCode), problem.Type)
+				assert.Equal(t, requestID, problem.RequestID)
+				assert.Equal(t, req.URL.Path, problem.Instance)
+
+				pointers := make([]string, len(problem.Errors))
+				for i, fe := range problem.Errors {
+					pointers[i] = fe.Pointer
+				}
+				assert.ElementsMatch(t, tt.wantPointer, pointers)
+			}
+			assertResponseMatchesSpec(t, req, w.Result())
+		})
+	}
+}
+
+func TestShortURLV2Controller_APIv1Unchanged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mocksctrl.NewMockShortURLStore(ctrl)
+	router := newTestRouter(store)
+
+	// Неизвестный маршрут вне /api/v2 отдает стандартный ответ gin.
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/unknown/route", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NotEqual(t, ProblemContentType, w.Header().Get("Content-Type"))
+
+	// Некорректный URL в /api по-прежнему отдается строкой.
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader("bad")))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "invalid URL format", w.Body.String())
+}
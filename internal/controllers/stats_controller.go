@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatsController обрабатывает запросы внутренней статистики сервиса.
+type StatsController struct {
+	statsProvider StatsProvider
+}
+
+// NewStatsController создает новый экземпляр StatsController.
+//
+// Параметры:
+//   - statsProvider: источник статистики
+//
+// Возвращает:
+//   - *StatsController: новый экземпляр контроллера
+func NewStatsController(statsProvider StatsProvider) *StatsController {
+	return &StatsController{statsProvider: statsProvider}
+}
+
+// StatsResponse структура ответа со статистикой.
+type StatsResponse struct {
+	URLs  int `json:"urls"`  // Количество неудаленных сокращенных URL
+	Users int `json:"users"` // Количество уникальных посетителей
+}
+
+// Stats возвращает количество неудаленных URL и уникальных посетителей.
+// Доступ ограничивается TrustedSubnetMiddleware.
+//
+// Коды ответа:
+//   - 200: статистика
+//   - 403: запрос не из доверенной подсети
+//   - 500: внутренняя ошибка сервера
+func (s *StatsController) Stats(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	stats, err := s.statsProvider.Stats(ctx)
+	if err != nil {
+		_ = c.Error(fmt.Errorf("get stats: %w", err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.JSON(http.StatusOK, StatsResponse{URLs: stats.URLs, Users: stats.Users})
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/mocksctrl"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsController_Stats(t *testing.T) {
+	tests := []struct {
+		name          string
+		trustedSubnet string
+		realIP        string
+		statsErr      error
+		wantCode      int
+		wantBody      string
+	}{
+		{
+			name:          "trusted ip",
+			trustedSubnet: "192.168.1.0/24",
+			realIP:        "192.168.1.15",
+			wantCode:      http.StatusOK,
+			wantBody:      `{"urls":10,"users":3}`,
+		},
+		{
+			name:          "ip outside subnet",
+			trustedSubnet: "192.168.1.0/24",
+			realIP:        "10.0.0.1",
+			wantCode:      http.StatusForbidden,
+		},
+		{
+			name:          "without X-Real-IP",
+			trustedSubnet: "192.168.1.0/24",
+			wantCode:      http.StatusForbidden,
+		},
+		{
+			name:          "invalid X-Real-IP",
+			trustedSubnet: "192.168.1.0/24",
+			realIP:        "not an ip",
+			wantCode:      http.StatusForbidden,
+		},
+		{
+			name:     "empty trusted subnet",
+			realIP:   "192.168.1.15",
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:          "ipv6 subnet",
+			trustedSubnet: "fd00::/8",
+			realIP:        "fd00::1",
+			wantCode:      http.StatusOK,
+			wantBody:      `{"urls":10,"users":3}`,
+		},
+		{
+			name:          "stats error",
+			trustedSubnet: "192.168.1.0/24",
+			realIP:        "192.168.1.15",
+			statsErr:      errors.New("db is down"),
+			wantCode:      http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			statsProvider := mocksctrl.NewMockStatsProvider(ctrl)
+			if tt.wantCode != http.StatusForbidden {
+				var stats *models.Stats
+				if tt.statsErr == nil {
+					stats = &models.Stats{URLs: 10, Users: 3}
+				}
+				statsProvider.EXPECT().Stats(gomock.Any()).Return(stats, tt.statsErr)
+			}
+
+			router := newTestRouter(nil, func(p *RouterParams) {
+				p.Stats = statsProvider
+				p.AppConf.TrustedSubnet = tt.trustedSubnet
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/internal/stats", nil)
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+			assertResponseMatchesSpec(t, req, w.Result())
+		})
+	}
+}
@@ -0,0 +1,577 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CSVContentType тип содержимого импорта и экспорта ссылок в формате CSV.
+const CSVContentType = "text/csv"
+
+// Значения по умолчанию для URLTransferController.
+const (
+	DefaultImportMaxBytes  = 10 * 1024 * 1024 // Максимальный размер импортируемого файла
+	DefaultImportMaxRows   = 10000            // Максимальное количество строк импорта
+	DefaultImportChunkSize = 500              // Количество строк, обрабатываемых одним вызовом Import
+)
+
+// Колонки CSV импорта и экспорта. Экспортированный файл можно импортировать без изменений.
+const (
+	csvColumnOriginalURL = "original_url"
+	csvColumnAlias       = "alias"
+	csvColumnTags        = "tags"
+	csvColumnShortURL    = "short_url"
+	csvColumnCreatedAt   = "created_at"
+	csvColumnDeletedAt   = "deleted_at"
+)
+
+// Форматы экспорта (параметр format).
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatJSON   = "json"
+	ExportFormatNDJSON = "ndjson"
+)
+
+// Статусы строк импорта (ImportRowResult.Status).
+const (
+	ImportStatusCreated   = "created"   // Ссылка создана
+	ImportStatusDuplicate = "duplicate" // URL уже сокращен посетителем, возвращена существующая ссылка
+	ImportStatusError     = "error"     // Строка не импортирована, см. ImportRowResult.Error
+)
+
+// Коды ошибок строк импорта, помимо FieldRequired, FieldInvalidURL и StreamErrInternal.
+const (
+	ImportErrInvalidCSV   = "invalid_csv"   // Строка не разобрана как CSV
+	ImportErrInvalidAlias = "invalid_alias" // Недопустимый алиас
+	ImportErrInvalidTags  = "invalid_tags"  // Недопустимые метки
+	ImportErrAliasTaken   = "alias_taken"   // Алиас занят другой ссылкой
+)
+
+// URLTransferControllerOptions опции URLTransferController.
+type URLTransferControllerOptions struct {
+	MaxBytes  int64 // Максимальный размер импортируемого файла
+	MaxRows   int   // Максимальное количество строк импорта
+	ChunkSize int   // Количество строк, обрабатываемых одним вызовом Import
+}
+
+// URLTransferController обрабатывает импорт и экспорт ссылок посетителя.
+type URLTransferController struct {
+	urlService ShortURLStore
+	baseURL    string
+	maxBytes   int64
+	maxRows    int
+	chunkSize  int
+}
+
+// NewURLTransferController создает новый экземпляр URLTransferController.
+//
+// Параметры:
+//   - urlService: сервис для работы с URL
+//   - baseURL: базовый URL для генерации коротких ссылок
+//   - opts: функции для настройки опций
+//
+// Возвращает:
+//   - *URLTransferController: новый экземпляр контроллера
+func NewURLTransferController(
+	urlService ShortURLStore,
+	baseURL string,
+	opts ...func(*URLTransferControllerOptions),
+) *URLTransferController {
+	options := URLTransferControllerOptions{
+		MaxBytes:  DefaultImportMaxBytes,
+		MaxRows:   DefaultImportMaxRows,
+		ChunkSize: DefaultImportChunkSize,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &URLTransferController{
+		urlService: urlService,
+		baseURL:    baseURL,
+		maxBytes:   options.MaxBytes,
+		maxRows:    options.MaxRows,
+		chunkSize:  max(options.ChunkSize, 1),
+	}
+}
+
+// ImportRowResult результат импорта одной строки CSV.
+type ImportRowResult struct {
+	Line        int              `json:"line"` // Номер строки файла, начиная с 1 (заголовок - строка 1)
+	OriginalURL string           `json:"original_url,omitempty"`
+	Alias       string           `json:"alias,omitempty"`
+	ShortURL    string           `json:"short_url,omitempty"`
+	Status      string           `json:"status"`
+	Error       *StreamLineError `json:"error,omitempty"`
+}
+
+// ImportResponse результат импорта CSV.
+type ImportResponse struct {
+	Created    int               `json:"created"`
+	Duplicates int               `json:"duplicates"`
+	Failed     int               `json:"failed"`
+	Rows       []ImportRowResult `json:"rows"`
+}
+
+// ExportRecord ссылка посетителя в экспорте.
+type ExportRecord struct {
+	OriginalURL string     `json:"original_url"`
+	Alias       string     `json:"alias"` // Короткий идентификатор ссылки
+	ShortURL    string     `json:"short_url"`
+	Tags        []string   `json:"tags"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+// importItem строка импорта, ожидающая сохранения.
+type importItem struct {
+	result ImportRowResult
+	tags   []string
+}
+
+// Import импортирует ссылки посетителя из CSV.
+// Первая строка файла - заголовок с колонками original_url (обязательна), alias и tags,
+// порядок колонок произвольный, прочие колонки игнорируются. Метки в колонке tags разделяются
+// запятой или точкой с запятой. Файл проверяется целиком до сохранения, поэтому превышение
+// ограничений не приводит к частичному импорту. Результат каждой строки сообщается отдельно.
+//
+// Коды ответа:
+//   - 200: результаты импорта по строкам (ImportResponse)
+//   - 400: файл пуст или в заголовке нет колонки original_url
+//   - 401: пользователь не авторизован
+//   - 413: превышен размер файла или количество строк
+//   - 415: тип содержимого запроса не text/csv
+//   - 500: внутренняя ошибка сервера
+func (t *URLTransferController) Import(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	if !isCSVRequest(c) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "only " + CSVContentType + " is supported"})
+		return
+	}
+
+	body, readErr := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, t.maxBytes))
+	if readErr != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(readErr, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": fmt.Sprintf("file exceeds %d bytes", t.maxBytes),
+			})
+			return
+		}
+		_ = c.Error(fmt.Errorf("import: read body: %w", readErr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+		return
+	}
+
+	items, parseErr := t.parseImport(body)
+	if parseErr != nil {
+		status := http.StatusBadRequest
+		if errors.Is(parseErr, errImportTooManyRows) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		c.JSON(status, gin.H{"error": parseErr.Error()})
+		return
+	}
+
+	for start := 0; start < len(items); start += t.chunkSize {
+		if err := c.Request.Context().Err(); err != nil {
+			_ = c.Error(fmt.Errorf("import: request canceled: %w", err))
+			return
+		}
+		t.importChunk(c, visitorUUID, items[start:min(start+t.chunkSize, len(items))])
+	}
+
+	response := ImportResponse{Rows: make([]ImportRowResult, len(items))}
+	for i, item := range items {
+		switch item.result.Status {
+		case ImportStatusCreated:
+			response.Created++
+		case ImportStatusDuplicate:
+			response.Duplicates++
+		default:
+			response.Failed++
+		}
+		response.Rows[i] = item.result
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// errImportTooManyRows превышено количество строк импорта.
+var errImportTooManyRows = errors.New("too many rows")
+
+// parseImport разбирает и проверяет CSV импорта.
+//
+// Параметры:
+//   - body: содержимое файла
+//
+// Возвращает:
+//   - []importItem: строки файла; строки с ошибками имеют статус ImportStatusError
+//   - error: ошибка заголовка файла или errImportTooManyRows
+func (t *URLTransferController) parseImport(body []byte) ([]importItem, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(body, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, headerErr := reader.Read()
+	if errors.Is(headerErr, io.EOF) {
+		return nil, errors.New("empty file")
+	}
+	if headerErr != nil {
+		return nil, errors.New("invalid csv header")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, exists := columns[name]; !exists {
+			columns[name] = i
+		}
+	}
+	if _, exists := columns[csvColumnOriginalURL]; !exists {
+		return nil, fmt.Errorf("header must contain %s column", csvColumnOriginalURL)
+	}
+
+	var items []importItem
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if len(items) == t.maxRows {
+			return nil, fmt.Errorf("%w: file exceeds %d rows", errImportTooManyRows, t.maxRows)
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			items = append(items, importItem{result: ImportRowResult{
+				Line:   parseErr.StartLine,
+				Status: ImportStatusError,
+				Error:  &StreamLineError{Code: ImportErrInvalidCSV, Detail: parseErr.Err.Error()},
+			}})
+			continue
+		}
+		if err != nil {
+			return nil, errors.New("invalid csv")
+		}
+
+		line, _ := reader.FieldPos(0)
+		items = append(items, parseImportRecord(line, record, columns))
+	}
+	return items, nil
+}
+
+// importChunk сохраняет корректные строки порции и заполняет их результаты.
+//
+// Параметры:
+//   - c: контекст gin
+//   - visitorUUID: UUID посетителя
+//   - chunk: строки порции
+func (t *URLTransferController) importChunk(c *gin.Context, visitorUUID string, chunk []importItem) {
+	var pending []int
+	var rows []services.ImportRow
+	for i, item := range chunk {
+		if item.result.Status == ImportStatusError {
+			continue
+		}
+		pending = append(pending, i)
+		rows = append(rows, services.ImportRow{
+			URL:   item.result.OriginalURL,
+			Alias: item.result.Alias,
+			Tags:  item.tags,
+		})
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	importResponse, err := t.urlService.Import(ctx, visitorUUID, rows)
+	if err != nil {
+		_ = c.Error(fmt.Errorf("import urls: %w", err))
+		for _, idx := range pending {
+			setImportError(&chunk[idx].result, StreamErrInternal, ErrInternal.Error())
+		}
+		return
+	}
+
+	importResponse.ReadResponse(func(i int, m models.URL, err error) {
+		if i >= len(pending) {
+			return
+		}
+		result := &chunk[pending[i]].result
+		switch {
+		case err == nil:
+			result.Status = ImportStatusCreated
+		case errors.Is(err, services.ErrDuplicateKey):
+			result.Status = ImportStatusDuplicate
+		case errors.Is(err, services.ErrAliasTaken):
+			setImportError(result, ImportErrAliasTaken, "alias is already used by another link")
+			return
+		case errors.Is(err, services.ErrInvalidArgument):
+			setImportError(result, FieldInvalidValue, "invalid alias or tags")
+			return
+		default:
+			_ = c.Error(fmt.Errorf("import url: %w", err))
+			setImportError(result, StreamErrInternal, ErrInternal.Error())
+			return
+		}
+		result.ShortURL = buildShortURL(t.baseURL, c.Request, m.ShortIdentifier)
+	})
+}
+
+// parseImportRecord проверяет строку CSV импорта.
+//
+// Параметры:
+//   - line: номер строки файла
+//   - record: значения колонок
+//   - columns: индексы колонок по имени
+//
+// Возвращает:
+//   - importItem: строка, ожидающая сохранения, или строка с заполненной ошибкой
+func parseImportRecord(line int, record []string, columns map[string]int) importItem {
+	column := func(name string) string {
+		idx, exists := columns[name]
+		if !exists || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	item := importItem{result: ImportRowResult{
+		Line:        line,
+		OriginalURL: column(csvColumnOriginalURL),
+		Alias:       column(csvColumnAlias),
+	}}
+
+	if fieldErr := urlFieldError("/"+csvColumnOriginalURL, item.result.OriginalURL); fieldErr != nil {
+		setImportError(&item.result, fieldErr.Code, fieldErr.Detail)
+		return item
+	}
+	if item.result.Alias != "" {
+		if err := services.ValidateAlias(item.result.Alias); err != nil {
+			setImportError(&item.result, ImportErrInvalidAlias, invalidArgumentDetail(err))
+			return item
+		}
+	}
+
+	tags, err := services.NormalizeTags(strings.FieldsFunc(column(csvColumnTags), func(r rune) bool {
+		return r == ',' || r == ';'
+	}))
+	if err != nil {
+		setImportError(&item.result, ImportErrInvalidTags, invalidArgumentDetail(err))
+		return item
+	}
+	item.tags = tags
+	return item
+}
+
+// setImportError помечает строку импорта как ошибочную.
+func setImportError(result *ImportRowResult, code string, detail string) {
+	result.Status = ImportStatusError
+	result.ShortURL = ""
+	result.Error = &StreamLineError{Code: code, Detail: detail}
+}
+
+// invalidArgumentDetail возвращает описание ошибки services.ErrInvalidArgument без префикса сервиса.
+func invalidArgumentDetail(err error) string {
+	return strings.TrimPrefix(err.Error(), services.ErrInvalidArgument.Error()+": ")
+}
+
+// Export отдает все ссылки посетителя, включая удаленные, в формате csv (по умолчанию), json или ndjson.
+// Ссылки передаются клиенту по мере чтения из хранилища и целиком в памяти не хранятся.
+// Колонки CSV совпадают с колонками импорта, поэтому файл можно импортировать повторно.
+//
+// Коды ответа:
+//   - 200: ссылки посетителя
+//   - 400: неизвестный формат
+//   - 401: пользователь не авторизован
+//   - 500: внутренняя ошибка сервера (только если ответ еще не начат)
+func (t *URLTransferController) Export(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	format := c.DefaultQuery("format", ExportFormatCSV)
+	var enc exportEncoder
+	switch format {
+	case ExportFormatCSV:
+		c.Header("Content-Type", CSVContentType+"; charset=utf-8")
+		enc = &csvExportEncoder{w: csv.NewWriter(c.Writer)}
+	case ExportFormatJSON:
+		c.Header("Content-Type", "application/json; charset=utf-8")
+		enc = &jsonExportEncoder{w: c.Writer}
+	case ExportFormatNDJSON:
+		c.Header("Content-Type", NDJSONContentType)
+		enc = &ndjsonExportEncoder{enc: json.NewEncoder(c.Writer)}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be one of csv, json, ndjson"})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="urls.%s"`, format))
+
+	err := t.urlService.ExportByVisitorUUID(c, visitorUUID, func(m models.URL) error {
+		return enc.Write(t.exportRecord(c.Request, m))
+	})
+	if err == nil {
+		err = enc.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	_ = c.Error(fmt.Errorf("export user urls: %w", err))
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Disposition")
+		c.Writer.Header().Del("Content-Type")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+	}
+}
+
+// exportRecord формирует запись экспорта из модели.
+func (t *URLTransferController) exportRecord(r *http.Request, m models.URL) ExportRecord {
+	record := ExportRecord{
+		OriginalURL: m.URL,
+		Alias:       m.ShortIdentifier,
+		ShortURL:    buildShortURL(t.baseURL, r, m.ShortIdentifier),
+		Tags:        m.Tags,
+		DeletedAt:   m.DeletedAt,
+	}
+	if record.Tags == nil {
+		record.Tags = []string{}
+	}
+	if !m.CreatedAt.IsZero() {
+		record.CreatedAt = &m.CreatedAt
+	}
+	return record
+}
+
+// exportEncoder записывает ссылки экспорта в ответ в одном из форматов.
+type exportEncoder interface {
+	// Write записывает ссылку.
+	Write(r ExportRecord) error
+	// Close завершает ответ.
+	Close() error
+}
+
+// csvExportEncoder записывает ссылки в формате CSV. Заголовок записывается вместе с первой ссылкой,
+// чтобы до начала ответа можно было сообщить об ошибке статусом 500.
+type csvExportEncoder struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (e *csvExportEncoder) Write(r ExportRecord) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	var createdAt, deletedAt string
+	if r.CreatedAt != nil {
+		createdAt = r.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	if r.DeletedAt != nil {
+		deletedAt = r.DeletedAt.UTC().Format(time.RFC3339)
+	}
+	err := e.w.Write([]string{r.OriginalURL, r.Alias, strings.Join(r.Tags, ","), r.ShortURL, createdAt, deletedAt})
+	if err != nil {
+		return fmt.Errorf("write csv record: %w", err)
+	}
+	return nil
+}
+
+func (e *csvExportEncoder) Close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.w.Flush()
+	if err := e.w.Error(); err != nil {
+		return fmt.Errorf("flush csv: %w", err)
+	}
+	return nil
+}
+
+func (e *csvExportEncoder) writeHeader() error {
+	if e.headerWritten {
+		return nil
+	}
+	e.headerWritten = true
+	err := e.w.Write([]string{
+		csvColumnOriginalURL, csvColumnAlias, csvColumnTags, csvColumnShortURL, csvColumnCreatedAt, csvColumnDeletedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("write csv header: %w", err)
+	}
+	return nil
+}
+
+// jsonExportEncoder записывает ссылки JSON массивом, не собирая его в памяти.
+type jsonExportEncoder struct {
+	w       io.Writer
+	written bool
+}
+
+func (e *jsonExportEncoder) Write(r ExportRecord) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("marshal record: %w", err)
+	}
+	sep := []byte{','}
+	if !e.written {
+		sep = []byte{'['}
+		e.written = true
+	}
+	if _, err = e.w.Write(append(sep, data...)); err != nil {
+		return fmt.Errorf("write record: %w", err)
+	}
+	return nil
+}
+
+func (e *jsonExportEncoder) Close() error {
+	end := "]"
+	if !e.written {
+		end = "[]"
+	}
+	if _, err := io.WriteString(e.w, end); err != nil {
+		return fmt.Errorf("write json end: %w", err)
+	}
+	return nil
+}
+
+// ndjsonExportEncoder записывает ссылки в формате NDJSON.
+type ndjsonExportEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonExportEncoder) Write(r ExportRecord) error {
+	if err := e.enc.Encode(r); err != nil {
+		return fmt.Errorf("write record: %w", err)
+	}
+	return nil
+}
+
+func (e *ndjsonExportEncoder) Close() error {
+	return nil
+}
+
+// isCSVRequest определяет, передано ли тело запроса в формате CSV.
+func isCSVRequest(c *gin.Context) bool {
+	ct := c.Request.Header.Get("Content-Type")
+	return strings.HasPrefix(ct, CSVContentType) || strings.HasPrefix(ct, "application/csv")
+}
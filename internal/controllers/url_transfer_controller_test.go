@@ -0,0 +1,310 @@
+package controllers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/mocksctrl"
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories/memstore"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLTransferController_Import(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mocksctrl.NewMockShortURLStore(ctrl)
+
+	wantRows := []services.ImportRow{
+		{URL: "https://a.com/1", Tags: []string{"news", "go"}},
+		{URL: "https://a.com/2", Alias: "my-link"},
+		{URL: "https://a.com/3"},
+		{URL: "https://a.com/4", Alias: "taken"},
+		{URL: "https://a.com/5"},
+	}
+	store.EXPECT().Import(gomock.Any(), gomock.Any(), wantRows).
+		DoAndReturn(func(_ any, _ string, rows []services.ImportRow) (*services.BatchCreateShortURLsResponse, error) {
+			res := services.NewBatchExecResponse[models.URL](len(rows))
+			res.Set(services.BatchResponseItem[models.URL]{Item: models.URL{ShortIdentifier: "gen1"}}, 0)
+			res.Set(services.BatchResponseItem[models.URL]{Item: models.URL{ShortIdentifier: "my-link"}}, 1)
+			res.Set(services.BatchResponseItem[models.URL]{
+				Item: models.URL{ShortIdentifier: "gen3"}, Err: services.ErrDuplicateKey,
+			}, 2)
+			res.Set(services.BatchResponseItem[models.URL]{Err: services.ErrAliasTaken}, 3)
+			res.Set(services.BatchResponseItem[models.URL]{Err: errors.New("db is down")}, 4)
+			return services.NewBatchExecResponseURL(res), nil
+		})
+
+	// Колонки в произвольном порядке, лишняя колонка игнорируется.
+	body := "\ufefftags,Original_URL,alias,comment\n" +
+		"\"news; go,news\",https://a.com/1,,first\n" +
+		",https://a.com/2,my-link,\n" +
+		",not a url,,\n" +
+		",,,\n" +
+		",https://a.com/3,,\n" +
+		",https://a.com/6,bad alias!,\n" +
+		"x,https://a.com/7\"bad,,\n" +
+		",https://a.com/4,taken,\n" +
+		",https://a.com/5,,\n"
+
+	req := httptest.NewRequest(http.MethodPost, "/api/user/urls/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", CSVContentType)
+	w := httptest.NewRecorder()
+	newTestRouter(store).ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assertResponseMatchesSpec(t, req, w.Result())
+
+	var res ImportResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, 2, res.Created)
+	assert.Equal(t, 1, res.Duplicates)
+	assert.Equal(t, 6, res.Failed)
+	require.Len(t, res.Rows, 9)
+
+	type want struct {
+		line     int
+		status   string
+		shortURL string
+		errCode  string
+	}
+	wants := []want{
+		{line: 2, status: ImportStatusCreated, shortURL: "http://test.com/gen1"},
+		{line: 3, status: ImportStatusCreated, shortURL: "http://test.com/my-link"},
+		{line: 4, status: ImportStatusError, errCode: FieldInvalidURL},
+		{line: 5, status: ImportStatusError, errCode: FieldRequired},
+		{line: 6, status: ImportStatusDuplicate, shortURL: "http://test.com/gen3"},
+		{line: 7, status: ImportStatusError, errCode: ImportErrInvalidAlias},
+		{line: 8, status: ImportStatusError, errCode: ImportErrInvalidCSV},
+		{line: 9, status: ImportStatusError, errCode: ImportErrAliasTaken},
+		{line: 10, status: ImportStatusError, errCode: StreamErrInternal},
+	}
+	for i, wt := range wants {
+		row := res.Rows[i]
+		assert.Equal(t, wt.line, row.Line, "строка %d", i)
+		assert.Equal(t, wt.status, row.Status, "строка %d", wt.line)
+		assert.Equal(t, wt.shortURL, row.ShortURL, "строка %d", wt.line)
+		if wt.errCode == "" {
+			assert.Nil(t, row.Error, "строка %d", wt.line)
+			continue
+		}
+		require.NotNil(t, row.Error, "строка %d", wt.line)
+		assert.Equal(t, wt.errCode, row.Error.Code, "строка %d", wt.line)
+	}
+	assert.NotContains(t, w.Body.String(), "db is down")
+}
+
+// TestURLTransferController_ImportedAliasResolves проверяет, что по импортированному алиасу
+// длиной не models.ShortIdentifierLength можно перейти.
+func TestURLTransferController_ImportedAliasResolves(t *testing.T) {
+	router := newTestRouter(services.NewURLService(memstore.NewURLRepo(db.NewMemStorage())))
+
+	body := "original_url,alias\nhttps://example.com/promo,promo\n"
+	req := httptest.NewRequest(http.MethodPost, "/api/user/urls/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", CSVContentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var res ImportResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Equal(t, 1, res.Created)
+	assert.Equal(t, "http://test.com/promo", res.Rows[0].ShortURL)
+
+	for _, path := range []string{"/promo", "/api/promo", "/api/v2/promo"} {
+		req = httptest.NewRequest(http.MethodGet, path, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusTemporaryRedirect, w.Code, path)
+		assert.Equal(t, "https://example.com/promo", w.Header().Get("Location"), path)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/promo/info", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assertResponseMatchesSpec(t, req, w.Result())
+
+	// Недопустимый идентификатор отклоняется без обращения к хранилищу.
+	req = httptest.NewRequest(http.MethodGet, "/"+strings.Repeat("x", models.MaxAliasLength+1), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestURLTransferController_ImportRejected(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		maxRows     int
+		wantCode    int
+	}{
+		{
+			name:        "not csv",
+			contentType: "application/json",
+			body:        `[]`,
+			wantCode:    http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "empty file",
+			contentType: CSVContentType,
+			wantCode:    http.StatusBadRequest,
+		},
+		{
+			name:        "no original_url column",
+			contentType: CSVContentType,
+			body:        "url,alias\nhttps://a.com,\n",
+			wantCode:    http.StatusBadRequest,
+		},
+		{
+			name:        "too many rows",
+			contentType: CSVContentType,
+			body:        "original_url\nhttps://a.com/1\nhttps://a.com/2\nhttps://a.com/3\n",
+			maxRows:     2,
+			wantCode:    http.StatusRequestEntityTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mocksctrl.NewMockShortURLStore(ctrl)
+
+			router, path := newTestRouter(store), "/api/user/urls/import"
+			if tt.maxRows > 0 {
+				router, path = newVisitorRouter(), "/import"
+				router.POST(path, NewURLTransferController(store, "", func(o *URLTransferControllerOptions) {
+					o.MaxRows = tt.maxRows
+				}).Import)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.maxRows == 0 {
+				assertResponseMatchesSpec(t, req, w.Result())
+			}
+		})
+	}
+}
+
+func TestURLTransferController_Export(t *testing.T) {
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	deletedAt := createdAt.Add(time.Hour)
+	records := []models.URL{
+		{URL: "https://a.com/1", ShortIdentifier: "one", CreatedAt: createdAt, Tags: []string{"news", "go"}},
+		{URL: "https://a.com/2", ShortIdentifier: "two", CreatedAt: createdAt, DeletedAt: &deletedAt},
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		exportErr   error
+		wantCode    int
+		wantType    string
+		checkBodyFn func(t *testing.T, body string)
+	}{
+		{
+			name:     "csv by default",
+			wantCode: http.StatusOK,
+			wantType: CSVContentType,
+			checkBodyFn: func(t *testing.T, body string) {
+				rows, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+				require.NoError(t, err)
+				assert.Equal(t, [][]string{
+					{"original_url", "alias", "tags", "short_url", "created_at", "deleted_at"},
+					{"https://a.com/1", "one", "news,go", "http://test.com/one", "2025-01-02T03:04:05Z", ""},
+					{"https://a.com/2", "two", "", "http://test.com/two", "2025-01-02T03:04:05Z", "2025-01-02T04:04:05Z"},
+				}, rows)
+			},
+		},
+		{
+			name:     "json",
+			query:    "?format=json",
+			wantCode: http.StatusOK,
+			wantType: "application/json",
+			checkBodyFn: func(t *testing.T, body string) {
+				var got []ExportRecord
+				require.NoError(t, json.Unmarshal([]byte(body), &got))
+				require.Len(t, got, 2)
+				assert.Equal(t, []string{"news", "go"}, got[0].Tags)
+				assert.Equal(t, []string{}, got[1].Tags)
+				assert.Nil(t, got[0].DeletedAt)
+				require.NotNil(t, got[1].DeletedAt)
+			},
+		},
+		{
+			name:     "ndjson",
+			query:    "?format=ndjson",
+			wantCode: http.StatusOK,
+			wantType: NDJSONContentType,
+			checkBodyFn: func(t *testing.T, body string) {
+				scanner := bufio.NewScanner(strings.NewReader(body))
+				var lines int
+				for scanner.Scan() {
+					assertMatchesSchema(t, "ExportRecord", scanner.Bytes())
+					lines++
+				}
+				assert.Equal(t, 2, lines)
+			},
+		},
+		{
+			name:     "unknown format",
+			query:    "?format=xml",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:      "storage error",
+			query:     "?format=json",
+			exportErr: errors.New("db is down"),
+			wantCode:  http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mocksctrl.NewMockShortURLStore(ctrl)
+			if tt.wantCode != http.StatusBadRequest {
+				store.EXPECT().ExportByVisitorUUID(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ any, _ string, fn func(models.URL) error) error {
+						if tt.exportErr != nil {
+							return tt.exportErr
+						}
+						for _, m := range records {
+							if err := fn(m); err != nil {
+								return err
+							}
+						}
+						return nil
+					})
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/urls/export"+tt.query, nil)
+			w := httptest.NewRecorder()
+			newTestRouter(store).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+			if tt.wantType != "" {
+				assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), tt.wantType))
+				assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+			}
+			if tt.checkBodyFn != nil {
+				tt.checkBodyFn(t, w.Body.String())
+			}
+		})
+	}
+}
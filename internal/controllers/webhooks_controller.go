@@ -0,0 +1,257 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/middlewares"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// webhookDeliveriesLimit количество последних попыток доставки в ответе.
+const webhookDeliveriesLimit = 50
+
+// WebhooksController обрабатывает HTTP запросы управления вебхуками посетителя.
+type WebhooksController struct {
+	webhookService WebhookManager
+}
+
+// NewWebhooksController создает новый экземпляр WebhooksController.
+//
+// Параметры:
+//   - webhookService: сервис вебхуков
+//
+// Возвращает:
+//   - *WebhooksController: новый экземпляр контроллера
+func NewWebhooksController(webhookService WebhookManager) *WebhooksController {
+	return &WebhooksController{webhookService: webhookService}
+}
+
+// CreateWebhookParams параметры регистрации вебхука.
+type CreateWebhookParams struct {
+	// URL адрес получателя событий
+	URL string `json:"url"`
+	// Events типы событий: url.created, url.deleted, url.clicked
+	Events []string `json:"events"`
+}
+
+// WebhookResponse структура ответа с данными вебхука.
+type WebhookResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"` // отдается только при создании
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookDeliveryResponse структура ответа с данными попытки доставки.
+type WebhookDeliveryResponse struct {
+	ID         string    `json:"id"`
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error,omitempty"`
+	Success    bool      `json:"success"`
+	DeadLetter bool      `json:"dead_letter"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Create регистрирует вебхук текущего посетителя.
+// Секрет для проверки подписи возвращается только в этом ответе.
+//
+// Коды ответа:
+//   - 201: вебхук создан
+//   - 400: некорректный запрос
+//   - 403: отсутствует или недействителен VisitorUUID
+//   - 422: некорректный URL или тип события
+//   - 500: внутренняя ошибка сервера
+func (w *WebhooksController) Create(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	var params CreateWebhookParams
+	if bindErr := c.ShouldBindJSON(&params); bindErr != nil {
+		_ = c.Error(fmt.Errorf("bind params: %w", bindErr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request. Only json is supported"})
+		return
+	}
+	if _, parseErr := validateURL(params.URL); parseErr != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": parseErr.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	hook, err := w.webhookService.Register(ctx, visitorUUID, params.URL, params.Events)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidArgument) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		_ = c.Error(fmt.Errorf("register webhook: %w", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+		return
+	}
+
+	res := webhookResponse(hook)
+	res.Secret = hook.Secret
+	c.JSON(http.StatusCreated, res)
+}
+
+// List возвращает вебхуки текущего посетителя.
+//
+// Коды ответа:
+//   - 200: список вебхуков
+//   - 204: у посетителя нет вебхуков
+//   - 403: отсутствует или недействителен VisitorUUID
+//   - 500: внутренняя ошибка сервера
+func (w *WebhooksController) List(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	hooks, err := w.webhookService.GetAllByVisitorUUID(ctx, visitorUUID)
+	if err != nil {
+		_ = c.Error(fmt.Errorf("get webhooks: %w", err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if len(hooks) == 0 {
+		c.AbortWithStatus(http.StatusNoContent)
+		return
+	}
+
+	var r = make([]WebhookResponse, len(hooks))
+	for i := range hooks {
+		r[i] = webhookResponse(&hooks[i])
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+// Delete удаляет вебхук текущего посетителя.
+//
+// Параметры URL:
+//   - id: идентификатор вебхука
+//
+// Коды ответа:
+//   - 204: вебхук удален
+//   - 403: отсутствует или недействителен VisitorUUID
+//   - 404: вебхук не найден
+//   - 500: внутренняя ошибка сервера
+func (w *WebhooksController) Delete(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	id := c.Param("id")
+	if uuid.Validate(id) != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	if err := w.webhookService.Delete(ctx, id, visitorUUID); err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		_ = c.Error(fmt.Errorf("delete webhook: %w", err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Deliveries возвращает последние попытки доставки вебхука текущего посетителя.
+//
+// Параметры URL:
+//   - id: идентификатор вебхука
+//
+// Коды ответа:
+//   - 200: список попыток доставки (от новых к старым)
+//   - 403: отсутствует или недействителен VisitorUUID
+//   - 404: вебхук не найден
+//   - 500: внутренняя ошибка сервера
+func (w *WebhooksController) Deliveries(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	id := c.Param("id")
+	if uuid.Validate(id) != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	deliveries, err := w.webhookService.Deliveries(ctx, id, visitorUUID, webhookDeliveriesLimit)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		_ = c.Error(fmt.Errorf("get webhook deliveries: %w", err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	var r = make([]WebhookDeliveryResponse, len(deliveries))
+	for i := range deliveries {
+		r[i] = webhookDeliveryResponse(&deliveries[i])
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+// webhookDeliveryResponse преобразует модель попытки доставки в ответ.
+func webhookDeliveryResponse(d *models.WebhookDelivery) WebhookDeliveryResponse {
+	return WebhookDeliveryResponse{
+		ID:         d.ID,
+		EventID:    d.EventID,
+		EventType:  d.EventType,
+		Attempt:    d.Attempt,
+		StatusCode: d.StatusCode,
+		Error:      d.Error,
+		Success:    d.Success,
+		DeadLetter: d.DeadLetter,
+		CreatedAt:  d.CreatedAt,
+	}
+}
+
+// webhookResponse преобразует модель вебхука в ответ без секрета.
+func webhookResponse(hook *models.Webhook) WebhookResponse {
+	return WebhookResponse{
+		ID:        hook.ID,
+		URL:       hook.URL,
+		Events:    hook.Events,
+		CreatedAt: hook.CreatedAt,
+	}
+}
+
+// visitorUUIDFromContext возвращает UUID посетителя, установленный VisitorCookieMiddleware.
+func visitorUUIDFromContext(c *gin.Context) (string, bool) {
+	vu, _ := c.Get(middlewares.VisitorUUIDKey)
+	visitorUUID, ok := vu.(string)
+	return visitorUUID, ok
+}
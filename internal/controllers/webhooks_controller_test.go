@@ -0,0 +1,291 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/mocksctrl"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testWebhookID     = "3c9a1f2e-6b7d-4e8f-9a0b-1c2d3e4f5a6b"
+	testWebhookURL    = "https://hooks.example.com/shorturl"
+	testWebhookSecret = "whsec_0123456789abcdef"
+	testWebhookEvent  = "url.created"
+)
+
+// testWebhook возвращает вебхук с секретом, как его отдает сервис.
+func testWebhook() *models.Webhook {
+	return &models.Webhook{
+		ID:        testWebhookID,
+		URL:       testWebhookURL,
+		Events:    []string{testWebhookEvent},
+		Secret:    testWebhookSecret,
+		CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestWebhooksController_Create(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		registerErr  error
+		wantStatus   int
+		skipRegister bool
+	}{
+		{
+			name:       "created with secret",
+			body:       `{"url":"` + testWebhookURL + `","events":["url.created"]}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:         "invalid json",
+			body:         `{"url":`,
+			wantStatus:   http.StatusBadRequest,
+			skipRegister: true,
+		},
+		{
+			name:         "invalid url",
+			body:         `{"url":"not a url","events":["url.created"]}`,
+			wantStatus:   http.StatusUnprocessableEntity,
+			skipRegister: true,
+		},
+		{
+			name:        "unknown event type",
+			body:        `{"url":"` + testWebhookURL + `","events":["url.created"]}`,
+			registerErr: services.ErrInvalidArgument,
+			wantStatus:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "storage error",
+			body:        `{"url":"` + testWebhookURL + `","events":["url.created"]}`,
+			registerErr: errors.New("boom"),
+			wantStatus:  http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			webhooks := mocksctrl.NewMockWebhookManager(ctrl)
+			if !tt.skipRegister {
+				var hook *models.Webhook
+				if tt.registerErr == nil {
+					hook = testWebhook()
+				}
+				webhooks.EXPECT().
+					Register(gomock.Any(), gomock.Any(), testWebhookURL, []string{testWebhookEvent}).
+					Return(hook, tt.registerErr)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/user/webhooks", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) {
+				p.Webhooks = webhooks
+			}).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			assertResponseMatchesSpec(t, req, w.Result())
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+			var res WebhookResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			assert.Equal(t, testWebhookID, res.ID)
+			assert.Equal(t, testWebhookSecret, res.Secret)
+		})
+	}
+}
+
+func TestWebhooksController_List(t *testing.T) {
+	tests := []struct {
+		name       string
+		hooks      []models.Webhook
+		listErr    error
+		wantStatus int
+	}{
+		{
+			name:       "webhooks without secrets",
+			hooks:      []models.Webhook{*testWebhook()},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "no webhooks",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "storage error",
+			listErr:    errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			webhooks := mocksctrl.NewMockWebhookManager(ctrl)
+			webhooks.EXPECT().GetAllByVisitorUUID(gomock.Any(), gomock.Any()).Return(tt.hooks, tt.listErr)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/webhooks", nil)
+			w := httptest.NewRecorder()
+			newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) {
+				p.Webhooks = webhooks
+			}).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			assert.NotContains(t, w.Body.String(), testWebhookSecret)
+			var res []WebhookResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			require.Len(t, res, len(tt.hooks))
+			assert.Equal(t, testWebhookURL, res[0].URL)
+		})
+	}
+}
+
+func TestWebhooksController_Delete(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		deleteErr  error
+		wantStatus int
+		skipDelete bool
+	}{
+		{
+			name:       "deleted",
+			id:         testWebhookID,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "not found",
+			id:         testWebhookID,
+			deleteErr:  services.ErrRecordNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "invalid id",
+			id:         "not-a-uuid",
+			wantStatus: http.StatusNotFound,
+			skipDelete: true,
+		},
+		{
+			name:       "storage error",
+			id:         testWebhookID,
+			deleteErr:  errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			webhooks := mocksctrl.NewMockWebhookManager(ctrl)
+			if !tt.skipDelete {
+				webhooks.EXPECT().Delete(gomock.Any(), tt.id, gomock.Any()).Return(tt.deleteErr)
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/user/webhooks/"+tt.id, nil)
+			w := httptest.NewRecorder()
+			newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) {
+				p.Webhooks = webhooks
+			}).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+		})
+	}
+}
+
+func TestWebhooksController_Deliveries(t *testing.T) {
+	deliveries := []models.WebhookDelivery{
+		{ID: "d2", WebhookID: testWebhookID, EventID: "e1", EventType: testWebhookEvent, Attempt: 2,
+			StatusCode: http.StatusOK, Success: true},
+		{ID: "d1", WebhookID: testWebhookID, EventID: "e1", EventType: testWebhookEvent, Attempt: 1,
+			StatusCode: http.StatusBadGateway, Error: "unexpected status 502"},
+	}
+	tests := []struct {
+		name           string
+		id             string
+		deliveries     []models.WebhookDelivery
+		deliveriesErr  error
+		wantStatus     int
+		wantLen        int
+		skipDeliveries bool
+	}{
+		{
+			name:       "newest first",
+			id:         testWebhookID,
+			deliveries: deliveries,
+			wantStatus: http.StatusOK,
+			wantLen:    2,
+		},
+		{
+			name:       "no deliveries yet",
+			id:         testWebhookID,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:          "foreign webhook",
+			id:            testWebhookID,
+			deliveriesErr: services.ErrRecordNotFound,
+			wantStatus:    http.StatusNotFound,
+		},
+		{
+			name:           "invalid id",
+			id:             "not-a-uuid",
+			wantStatus:     http.StatusNotFound,
+			skipDeliveries: true,
+		},
+		{
+			name:          "storage error",
+			id:            testWebhookID,
+			deliveriesErr: errors.New("boom"),
+			wantStatus:    http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			webhooks := mocksctrl.NewMockWebhookManager(ctrl)
+			if !tt.skipDeliveries {
+				webhooks.EXPECT().Deliveries(gomock.Any(), tt.id, gomock.Any(), webhookDeliveriesLimit).
+					Return(tt.deliveries, tt.deliveriesErr)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/webhooks/"+tt.id+"/deliveries", nil)
+			w := httptest.NewRecorder()
+			newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) {
+				p.Webhooks = webhooks
+			}).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			assertResponseMatchesSpec(t, req, w.Result())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var res []WebhookDeliveryResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			require.Len(t, res, tt.wantLen)
+			if tt.wantLen > 0 {
+				assert.Equal(t, "d2", res[0].ID)
+				assert.True(t, res[0].Success)
+				assert.Equal(t, "unexpected status 502", res[1].Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/fsdevblog/shorturl/internal/urlvalidate"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// WebhooksV2Controller обрабатывает запросы /api/v2 управления вебхуками посетителя.
+// Ответы совпадают с WebhooksController, ошибки отдаются в формате application/problem+json.
+type WebhooksV2Controller struct {
+	webhookService WebhookManager
+}
+
+// NewWebhooksV2Controller создает новый экземпляр WebhooksV2Controller.
+//
+// Параметры:
+//   - webhookService: сервис вебхуков
+//
+// Возвращает:
+//   - *WebhooksV2Controller: новый экземпляр контроллера
+func NewWebhooksV2Controller(webhookService WebhookManager) *WebhooksV2Controller {
+	return &WebhooksV2Controller{webhookService: webhookService}
+}
+
+// Create регистрирует вебхук текущего посетителя.
+//
+// Коды ответа:
+//   - 201: вебхук создан
+//   - 400: invalid_request - тело запроса не разобрано
+//   - 401: unauthorized - посетитель не определен
+//   - 422: validation_failed - некорректный URL или типы событий
+//   - 500: internal - внутренняя ошибка сервера
+func (w *WebhooksV2Controller) Create(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		abortWithProblem(c, http.StatusUnauthorized, ProblemUnauthorized, "visitor is not identified")
+		return
+	}
+
+	var params CreateWebhookParams
+	if bindErr := c.ShouldBindJSON(&params); bindErr != nil {
+		_ = c.Error(fmt.Errorf("bind params: %w", bindErr))
+		abortWithProblem(c, http.StatusBadRequest, ProblemInvalidRequest, "request body must be a JSON object")
+		return
+	}
+
+	var fieldErrors []FieldError
+	if fieldErr := urlFieldError("/url", params.URL); fieldErr != nil {
+		fieldErrors = append(fieldErrors, *fieldErr)
+	}
+	if len(params.Events) == 0 {
+		fieldErrors = append(fieldErrors, FieldError{
+			Pointer: "/events", Code: FieldRequired, Detail: "at least one event type is required",
+		})
+	}
+	if len(fieldErrors) > 0 {
+		abortWithProblem(c, http.StatusUnprocessableEntity, ProblemValidation, "request is invalid", fieldErrors...)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	hook, err := w.webhookService.Register(ctx, visitorUUID, params.URL, params.Events)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidArgument) {
+			fieldErr := FieldError{Pointer: "/events", Code: FieldInvalidValue, Detail: err.Error()}
+			if errors.Is(err, urlvalidate.ErrPrivateAddress) {
+				fieldErr = FieldError{Pointer: "/url", Code: FieldInvalidURL, Detail: err.Error()}
+			}
+			abortWithProblem(c, http.StatusUnprocessableEntity, ProblemValidation, "request is invalid", fieldErr)
+			return
+		}
+		abortWithInternalProblem(c, fmt.Errorf("register webhook: %w", err))
+		return
+	}
+
+	res := webhookResponse(hook)
+	res.Secret = hook.Secret
+	c.JSON(http.StatusCreated, res)
+}
+
+// List возвращает вебхуки текущего посетителя.
+//
+// Коды ответа:
+//   - 200: список вебхуков
+//   - 204: у посетителя нет вебхуков
+//   - 401: unauthorized - посетитель не определен
+//   - 500: internal - внутренняя ошибка сервера
+func (w *WebhooksV2Controller) List(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		abortWithProblem(c, http.StatusUnauthorized, ProblemUnauthorized, "visitor is not identified")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	hooks, err := w.webhookService.GetAllByVisitorUUID(ctx, visitorUUID)
+	if err != nil {
+		abortWithInternalProblem(c, fmt.Errorf("get webhooks: %w", err))
+		return
+	}
+	if len(hooks) == 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	response := make([]WebhookResponse, len(hooks))
+	for i := range hooks {
+		response[i] = webhookResponse(&hooks[i])
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Delete удаляет вебхук текущего посетителя.
+//
+// Параметры URL:
+//   - id: идентификатор вебхука
+//
+// Коды ответа:
+//   - 204: вебхук удален
+//   - 401: unauthorized - посетитель не определен
+//   - 404: not_found - вебхук не найден
+//   - 500: internal - внутренняя ошибка сервера
+func (w *WebhooksV2Controller) Delete(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		abortWithProblem(c, http.StatusUnauthorized, ProblemUnauthorized, "visitor is not identified")
+		return
+	}
+	id := c.Param("id")
+	if uuid.Validate(id) != nil {
+		abortWithProblem(c, http.StatusNotFound, ProblemNotFound, "webhook not found")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	if err := w.webhookService.Delete(ctx, id, visitorUUID); err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			abortWithProblem(c, http.StatusNotFound, ProblemNotFound, "webhook not found")
+			return
+		}
+		abortWithInternalProblem(c, fmt.Errorf("delete webhook: %w", err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Deliveries возвращает последние попытки доставки вебхука текущего посетителя.
+//
+// Параметры URL:
+//   - id: идентификатор вебхука
+//
+// Коды ответа:
+//   - 200: список попыток доставки (от новых к старым)
+//   - 401: unauthorized - посетитель не определен
+//   - 404: not_found - вебхук не найден
+//   - 500: internal - внутренняя ошибка сервера
+func (w *WebhooksV2Controller) Deliveries(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		abortWithProblem(c, http.StatusUnauthorized, ProblemUnauthorized, "visitor is not identified")
+		return
+	}
+	id := c.Param("id")
+	if uuid.Validate(id) != nil {
+		abortWithProblem(c, http.StatusNotFound, ProblemNotFound, "webhook not found")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	deliveries, err := w.webhookService.Deliveries(ctx, id, visitorUUID, webhookDeliveriesLimit)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordNotFound) {
+			abortWithProblem(c, http.StatusNotFound, ProblemNotFound, "webhook not found")
+			return
+		}
+		abortWithInternalProblem(c, fmt.Errorf("get webhook deliveries: %w", err))
+		return
+	}
+
+	response := make([]WebhookDeliveryResponse, len(deliveries))
+	for i := range deliveries {
+		response[i] = webhookDeliveryResponse(&deliveries[i])
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/mocksctrl"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/fsdevblog/shorturl/internal/urlvalidate"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhooksV2Controller(t *testing.T) {
+	createBody := `{"url":"` + testWebhookURL + `","events":["url.created"]}`
+
+	tests := []struct {
+		name        string
+		method      string
+		url         string
+		body        string
+		mock        func(webhooks *mocksctrl.MockWebhookManager)
+		wantStatus  int
+		wantCode    ProblemCode
+		wantPointer []string
+	}{
+		{
+			name:       "create",
+			method:     http.MethodPost,
+			url:        "/api/v2/user/webhooks",
+			body:       createBody,
+			wantStatus: http.StatusCreated,
+			mock: func(webhooks *mocksctrl.MockWebhookManager) {
+				webhooks.EXPECT().Register(gomock.Any(), gomock.Any(), testWebhookURL, []string{testWebhookEvent}).
+					Return(testWebhook(), nil)
+			},
+		},
+		{
+			name:       "create invalid json",
+			method:     http.MethodPost,
+			url:        "/api/v2/user/webhooks",
+			body:       `{"url":`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   ProblemInvalidRequest,
+		},
+		{
+			name:        "create invalid url and no events",
+			method:      http.MethodPost,
+			url:         "/api/v2/user/webhooks",
+			body:        `{"url":"not a url"}`,
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantCode:    ProblemValidation,
+			wantPointer: []string{"/url", "/events"},
+		},
+		{
+			name:        "create unknown event type",
+			method:      http.MethodPost,
+			url:         "/api/v2/user/webhooks",
+			body:        createBody,
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantCode:    ProblemValidation,
+			wantPointer: []string{"/events"},
+			mock: func(webhooks *mocksctrl.MockWebhookManager) {
+				webhooks.EXPECT().Register(gomock.Any(), gomock.Any(), testWebhookURL, []string{testWebhookEvent}).
+					Return(nil, fmt.Errorf("%w: unknown event type", services.ErrInvalidArgument))
+			},
+		},
+		{
+			name:        "create private address",
+			method:      http.MethodPost,
+			url:         "/api/v2/user/webhooks",
+			body:        createBody,
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantCode:    ProblemValidation,
+			wantPointer: []string{"/url"},
+			mock: func(webhooks *mocksctrl.MockWebhookManager) {
+				webhooks.EXPECT().Register(gomock.Any(), gomock.Any(), testWebhookURL, []string{testWebhookEvent}).
+					Return(nil, fmt.Errorf("%w: %w", services.ErrInvalidArgument, urlvalidate.ErrPrivateAddress))
+			},
+		},
+		{
+			name:       "create storage error",
+			method:     http.MethodPost,
+			url:        "/api/v2/user/webhooks",
+			body:       createBody,
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   ProblemInternal,
+			mock: func(webhooks *mocksctrl.MockWebhookManager) {
+				webhooks.EXPECT().Register(gomock.Any(), gomock.Any(), testWebhookURL, []string{testWebhookEvent}).
+					Return(nil, errLeaked)
+			},
+		},
+		{
+			name:       "list",
+			method:     http.MethodGet,
+			url:        "/api/v2/user/webhooks",
+			wantStatus: http.StatusOK,
+			mock: func(webhooks *mocksctrl.MockWebhookManager) {
+				webhooks.EXPECT().GetAllByVisitorUUID(gomock.Any(), gomock.Any()).
+					Return([]models.Webhook{*testWebhook()}, nil)
+			},
+		},
+		{
+			name:       "list empty",
+			method:     http.MethodGet,
+			url:        "/api/v2/user/webhooks",
+			wantStatus: http.StatusNoContent,
+			mock: func(webhooks *mocksctrl.MockWebhookManager) {
+				webhooks.EXPECT().GetAllByVisitorUUID(gomock.Any(), gomock.Any()).Return(nil, nil)
+			},
+		},
+		{
+			name:       "list storage error",
+			method:     http.MethodGet,
+			url:        "/api/v2/user/webhooks",
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   ProblemInternal,
+			mock: func(webhooks *mocksctrl.MockWebhookManager) {
+				webhooks.EXPECT().GetAllByVisitorUUID(gomock.Any(), gomock.Any()).Return(nil, errLeaked)
+			},
+		},
+		{
+			name:       "delete",
+			method:     http.MethodDelete,
+			url:        "/api/v2/user/webhooks/" + testWebhookID,
+			wantStatus: http.StatusNoContent,
+			mock: func(webhooks *mocksctrl.MockWebhookManager) {
+				webhooks.EXPECT().Delete(gomock.Any(), testWebhookID, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:       "delete invalid id",
+			method:     http.MethodDelete,
+			url:        "/api/v2/user/webhooks/not-a-uuid",
+			wantStatus: http.StatusNotFound,
+			wantCode:   ProblemNotFound,
+		},
+		{
+			name:       "delete foreign webhook",
+			method:     http.MethodDelete,
+			url:        "/api/v2/user/webhooks/" + testWebhookID,
+			wantStatus: http.StatusNotFound,
+			wantCode:   ProblemNotFound,
+			mock: func(webhooks *mocksctrl.MockWebhookManager) {
+				webhooks.EXPECT().Delete(gomock.Any(), testWebhookID, gomock.Any()).Return(services.ErrRecordNotFound)
+			},
+		},
+		{
+			name:       "deliveries",
+			method:     http.MethodGet,
+			url:        "/api/v2/user/webhooks/" + testWebhookID + "/deliveries",
+			wantStatus: http.StatusOK,
+			mock: func(webhooks *mocksctrl.MockWebhookManager) {
+				webhooks.EXPECT().Deliveries(gomock.Any(), testWebhookID, gomock.Any(), webhookDeliveriesLimit).
+					Return([]models.WebhookDelivery{{ID: "d1", EventType: testWebhookEvent, Attempt: 1, Success: true}}, nil)
+			},
+		},
+		{
+			name:       "deliveries foreign webhook",
+			method:     http.MethodGet,
+			url:        "/api/v2/user/webhooks/" + testWebhookID + "/deliveries",
+			wantStatus: http.StatusNotFound,
+			wantCode:   ProblemNotFound,
+			mock: func(webhooks *mocksctrl.MockWebhookManager) {
+				webhooks.EXPECT().Deliveries(gomock.Any(), testWebhookID, gomock.Any(), webhookDeliveriesLimit).
+					Return(nil, services.ErrRecordNotFound)
+			},
+		},
+		{
+			name:       "deliveries storage error",
+			method:     http.MethodGet,
+			url:        "/api/v2/user/webhooks/" + testWebhookID + "/deliveries",
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   ProblemInternal,
+			mock: func(webhooks *mocksctrl.MockWebhookManager) {
+				webhooks.EXPECT().Deliveries(gomock.Any(), testWebhookID, gomock.Any(), webhookDeliveriesLimit).
+					Return(nil, errLeaked)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			webhooks := mocksctrl.NewMockWebhookManager(ctrl)
+			if tt.mock != nil {
+				tt.mock(webhooks)
+			}
+
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			w := httptest.NewRecorder()
+			newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) {
+				p.Webhooks = webhooks
+			}).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			assertResponseMatchesSpec(t, req, w.Result())
+			if tt.wantCode == "" {
+				return
+			}
+			assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+			assert.NotContains(t, w.Body.String(), errLeaked.Error())
+
+			var problem Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.wantCode, problem.Code)
+			pointers := make([]string, len(problem.Errors))
+			for i, fe := range problem.Errors {
+				pointers[i] = fe.Pointer
+			}
+			assert.ElementsMatch(t, tt.wantPointer, pointers)
+		})
+	}
+}
@@ -0,0 +1,479 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// WorkspacesController обрабатывает HTTP запросы рабочих пространств: участники и роли,
+// а также ссылки, принадлежащие команде, а не отдельному посетителю.
+type WorkspacesController struct {
+	workspaceService WorkspaceManager
+	baseURL          string
+}
+
+// NewWorkspacesController создает новый экземпляр WorkspacesController.
+//
+// Параметры:
+//   - workspaceService: сервис рабочих пространств
+//   - baseURL: базовый адрес коротких ссылок
+//
+// Возвращает:
+//   - *WorkspacesController: новый экземпляр контроллера
+func NewWorkspacesController(workspaceService WorkspaceManager, baseURL string) *WorkspacesController {
+	return &WorkspacesController{workspaceService: workspaceService, baseURL: baseURL}
+}
+
+// CreateWorkspaceParams параметры создания рабочего пространства.
+type CreateWorkspaceParams struct {
+	Name string `json:"name"`
+}
+
+// SetWorkspaceMemberParams параметры добавления участника или изменения его роли.
+type SetWorkspaceMemberParams struct {
+	Role string `json:"role"` // owner, editor или viewer
+}
+
+// CreateWorkspaceURLParams параметры создания ссылки в рабочем пространстве.
+type CreateWorkspaceURLParams struct {
+	URL string `json:"url"`
+}
+
+// UpdateWorkspaceURLParams параметры изменения ссылки рабочего пространства.
+// Незаданные поля не изменяются, пустой список меток удаляет все метки.
+type UpdateWorkspaceURLParams struct {
+	URL  *string   `json:"url"`
+	Tags *[]string `json:"tags"`
+}
+
+// WorkspaceResponse описание рабочего пространства.
+type WorkspaceResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	Role      string    `json:"role"` // Роль запросившего посетителя
+}
+
+// WorkspaceMemberResponse участник рабочего пространства.
+type WorkspaceMemberResponse struct {
+	VisitorUUID string    `json:"visitor_uuid"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// WorkspaceDetailsResponse рабочее пространство с участниками.
+type WorkspaceDetailsResponse struct {
+	WorkspaceResponse
+	Members []WorkspaceMemberResponse `json:"members"`
+}
+
+// WorkspaceURLResponse ссылка рабочего пространства.
+type WorkspaceURLResponse struct {
+	ShortID     string     `json:"short_id"`
+	ShortURL    string     `json:"short_url"`
+	OriginalURL string     `json:"original_url"`
+	CreatedBy   string     `json:"created_by"` // UUID участника, создавшего ссылку
+	State       string     `json:"state"`      // active, deleted или disabled
+	CreatedAt   time.Time  `json:"created_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+}
+
+// Create создает рабочее пространство. Создатель становится его владельцем.
+//
+// Коды ответа:
+//   - 201: пространство создано
+//   - 400: некорректный запрос
+//   - 401: пользователь не авторизован
+//   - 422: название не задано или слишком длинное
+//   - 500: внутренняя ошибка сервера
+func (w *WorkspacesController) Create(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var params CreateWorkspaceParams
+	if bindErr := c.ShouldBindJSON(&params); bindErr != nil {
+		_ = c.Error(fmt.Errorf("bind params: %w", bindErr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request. Only json is supported"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	ws, err := w.workspaceService.Create(ctx, visitorUUID, params.Name)
+	if err != nil {
+		w.abortWithError(c, fmt.Errorf("create workspace: %w", err))
+		return
+	}
+	c.JSON(http.StatusCreated, workspaceResponse(ws, models.WorkspaceRoleOwner))
+}
+
+// List возвращает рабочие пространства, участником которых является пользователь.
+//
+// Коды ответа:
+//   - 200: список пространств
+//   - 204: пользователь не состоит в пространствах
+//   - 401: пользователь не авторизован
+//   - 500: внутренняя ошибка сервера
+func (w *WorkspacesController) List(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	list, err := w.workspaceService.List(ctx, visitorUUID)
+	if err != nil {
+		w.abortWithError(c, fmt.Errorf("list workspaces: %w", err))
+		return
+	}
+	if len(list) == 0 {
+		c.AbortWithStatus(http.StatusNoContent)
+		return
+	}
+
+	var r = make([]WorkspaceResponse, len(list))
+	for i := range list {
+		r[i] = workspaceResponse(&list[i].Workspace, list[i].Role)
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+// Get возвращает рабочее пространство с участниками.
+//
+// Параметры URL:
+//   - id: идентификатор пространства
+//
+// Коды ответа:
+//   - 200: пространство
+//   - 401: пользователь не авторизован
+//   - 404: пространство не найдено или пользователь не участник
+//   - 500: внутренняя ошибка сервера
+func (w *WorkspacesController) Get(c *gin.Context) {
+	visitorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	details, err := w.workspaceService.Get(ctx, visitorUUID, c.Param("id"))
+	if err != nil {
+		w.abortWithError(c, fmt.Errorf("get workspace: %w", err))
+		return
+	}
+
+	r := WorkspaceDetailsResponse{
+		WorkspaceResponse: workspaceResponse(details.Workspace, details.Role),
+		Members:           make([]WorkspaceMemberResponse, len(details.Members)),
+	}
+	for i := range details.Members {
+		r.Members[i] = workspaceMemberResponse(&details.Members[i])
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+// SetMember добавляет участника рабочего пространства или меняет его роль. Доступно владельцам.
+//
+// Параметры URL:
+//   - id: идентификатор пространства
+//   - visitorUUID: UUID участника
+//
+// Коды ответа:
+//   - 200: участник сохранен
+//   - 400: некорректный запрос
+//   - 401: пользователь не авторизован
+//   - 403: пользователь не владелец пространства
+//   - 404: пространство не найдено или пользователь не участник
+//   - 422: некорректный UUID или роль, либо понижение последнего владельца
+//   - 500: внутренняя ошибка сервера
+func (w *WorkspacesController) SetMember(c *gin.Context) {
+	actorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var params SetWorkspaceMemberParams
+	if bindErr := c.ShouldBindJSON(&params); bindErr != nil {
+		_ = c.Error(fmt.Errorf("bind params: %w", bindErr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request. Only json is supported"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	member, err := w.workspaceService.SetMember(
+		ctx, actorUUID, c.Param("id"), c.Param("visitorUUID"), models.WorkspaceRole(params.Role),
+	)
+	if err != nil {
+		w.abortWithError(c, fmt.Errorf("set workspace member: %w", err))
+		return
+	}
+	c.JSON(http.StatusOK, workspaceMemberResponse(member))
+}
+
+// RemoveMember удаляет участника рабочего пространства. Владелец может удалить любого участника,
+// остальные участники - только себя.
+//
+// Параметры URL:
+//   - id: идентификатор пространства
+//   - visitorUUID: UUID участника
+//
+// Коды ответа:
+//   - 204: участник удален
+//   - 401: пользователь не авторизован
+//   - 403: пользователь не владелец и удаляет другого участника
+//   - 404: пространство или участник не найдены
+//   - 422: удаление последнего владельца
+//   - 500: внутренняя ошибка сервера
+func (w *WorkspacesController) RemoveMember(c *gin.Context) {
+	actorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	if err := w.workspaceService.RemoveMember(ctx, actorUUID, c.Param("id"), c.Param("visitorUUID")); err != nil {
+		w.abortWithError(c, fmt.Errorf("remove workspace member: %w", err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// CreateURL сокращает URL в рабочем пространстве. Доступно владельцам и редакторам.
+//
+// Параметры URL:
+//   - id: идентификатор пространства
+//
+// Коды ответа:
+//   - 201: ссылка создана
+//   - 409: URL уже сокращен в пространстве, в ответе существующая ссылка
+//   - 400: некорректный запрос
+//   - 401: пользователь не авторизован
+//   - 403: пользователь наблюдатель
+//   - 404: пространство не найдено или пользователь не участник
+//   - 422: некорректный URL
+//   - 500: внутренняя ошибка сервера
+func (w *WorkspacesController) CreateURL(c *gin.Context) {
+	actorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var params CreateWorkspaceURLParams
+	if bindErr := c.ShouldBindJSON(&params); bindErr != nil {
+		_ = c.Error(fmt.Errorf("bind params: %w", bindErr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request. Only json is supported"})
+		return
+	}
+	parsedURL, parseErr := validateURL(params.URL)
+	if parseErr != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": parseErr.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	sURL, created, err := w.workspaceService.CreateURL(ctx, actorUUID, c.Param("id"), parsedURL.String())
+	if err != nil {
+		w.abortWithError(c, fmt.Errorf("create workspace url: %w", err))
+		return
+	}
+
+	var statusCode = http.StatusCreated
+	if !created {
+		statusCode = http.StatusConflict
+	}
+	c.JSON(statusCode, w.urlResponse(c.Request, sURL))
+}
+
+// ListURLs возвращает ссылки рабочего пространства, включая удаленные. Доступно любому участнику.
+//
+// Параметры URL:
+//   - id: идентификатор пространства
+//
+// Коды ответа:
+//   - 200: ссылки пространства
+//   - 204: в пространстве нет ссылок
+//   - 401: пользователь не авторизован
+//   - 404: пространство не найдено или пользователь не участник
+//   - 500: внутренняя ошибка сервера
+func (w *WorkspacesController) ListURLs(c *gin.Context) {
+	actorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	urls, err := w.workspaceService.ListURLs(ctx, actorUUID, c.Param("id"))
+	if err != nil {
+		w.abortWithError(c, fmt.Errorf("list workspace urls: %w", err))
+		return
+	}
+	if len(urls) == 0 {
+		c.AbortWithStatus(http.StatusNoContent)
+		return
+	}
+
+	var r = make([]WorkspaceURLResponse, len(urls))
+	for i := range urls {
+		r[i] = w.urlResponse(c.Request, &urls[i])
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+// UpdateURL изменяет оригинальный URL и (или) метки ссылки рабочего пространства.
+// Короткий идентификатор не меняется. Доступно владельцам и редакторам.
+//
+// Параметры URL:
+//   - id: идентификатор пространства
+//   - shortID: короткий идентификатор ссылки
+//
+// Коды ответа:
+//   - 200: ссылка изменена
+//   - 400: некорректный запрос
+//   - 401: пользователь не авторизован
+//   - 403: пользователь наблюдатель
+//   - 404: пространство или неудаленная ссылка не найдены
+//   - 409: новый URL уже сокращен в пространстве
+//   - 422: некорректный URL или метки
+//   - 500: внутренняя ошибка сервера
+func (w *WorkspacesController) UpdateURL(c *gin.Context) {
+	actorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var params UpdateWorkspaceURLParams
+	if bindErr := c.ShouldBindJSON(&params); bindErr != nil {
+		_ = c.Error(fmt.Errorf("bind params: %w", bindErr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request. Only json is supported"})
+		return
+	}
+	upd := repositories.URLUpdate{Tags: params.Tags}
+	if params.URL != nil {
+		parsedURL, parseErr := validateURL(*params.URL)
+		if parseErr != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": parseErr.Error()})
+			return
+		}
+		normalized := parsedURL.String()
+		upd.URL = &normalized
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	sURL, err := w.workspaceService.UpdateURL(ctx, actorUUID, c.Param("id"), c.Param("shortID"), upd)
+	if err != nil {
+		w.abortWithError(c, fmt.Errorf("update workspace url: %w", err))
+		return
+	}
+	c.JSON(http.StatusOK, w.urlResponse(c.Request, sURL))
+}
+
+// DeleteURL помечает удаленной ссылку рабочего пространства, кем бы из участников она ни была создана.
+// Доступно владельцам и редакторам.
+//
+// Параметры URL:
+//   - id: идентификатор пространства
+//   - shortID: короткий идентификатор ссылки
+//
+// Коды ответа:
+//   - 204: ссылка удалена
+//   - 401: пользователь не авторизован
+//   - 403: пользователь наблюдатель
+//   - 404: пространство или неудаленная ссылка не найдены
+//   - 500: внутренняя ошибка сервера
+func (w *WorkspacesController) DeleteURL(c *gin.Context) {
+	actorUUID, ok := visitorUUIDFromContext(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c, DefaultRequestTimeout)
+	defer cancel()
+
+	if err := w.workspaceService.DeleteURL(ctx, actorUUID, c.Param("id"), c.Param("shortID")); err != nil {
+		w.abortWithError(c, fmt.Errorf("delete workspace url: %w", err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// abortWithError отвечает кодом, соответствующим ошибке сервиса рабочих пространств.
+func (w *WorkspacesController) abortWithError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidArgument):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrRecordNotFound.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient workspace role"})
+	case errors.Is(err, services.ErrDuplicateKey):
+		c.JSON(http.StatusConflict, gin.H{"error": "url is already shortened in the workspace"})
+	default:
+		_ = c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": ErrInternal.Error()})
+	}
+}
+
+// urlResponse преобразует модель ссылки в ответ о ссылке рабочего пространства.
+func (w *WorkspacesController) urlResponse(r *http.Request, sURL *models.URL) WorkspaceURLResponse {
+	return WorkspaceURLResponse{
+		ShortID:     sURL.ShortIdentifier,
+		ShortURL:    buildShortURL(w.baseURL, r, sURL.ShortIdentifier),
+		OriginalURL: sURL.URL,
+		CreatedBy:   sURL.VisitorUUID,
+		State:       string(sURL.State()),
+		CreatedAt:   sURL.CreatedAt,
+		DeletedAt:   sURL.DeletedAt,
+		Tags:        sURL.Tags,
+	}
+}
+
+// workspaceResponse преобразует модель рабочего пространства в ответ.
+func workspaceResponse(ws *models.Workspace, role models.WorkspaceRole) WorkspaceResponse {
+	return WorkspaceResponse{
+		ID:        ws.ID,
+		Name:      ws.Name,
+		CreatedBy: ws.CreatedBy,
+		CreatedAt: ws.CreatedAt,
+		Role:      string(role),
+	}
+}
+
+// workspaceMemberResponse преобразует модель участника рабочего пространства в ответ.
+func workspaceMemberResponse(m *models.WorkspaceMember) WorkspaceMemberResponse {
+	return WorkspaceMemberResponse{VisitorUUID: m.VisitorUUID, Role: string(m.Role), CreatedAt: m.CreatedAt}
+}
@@ -0,0 +1,327 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/controllers/mocksctrl"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testWorkspaceID    = "3d4e5f60-7182-4394-a5b6-c7d8e9f0a1b2"
+	testWorkspaceOwner = "4e5f6071-8293-44a5-b6c7-d8e9f0a1b2c3"
+	testWorkspaceGuest = "5f607182-93a4-45b6-87d8-e9f0a1b2c3d4"
+)
+
+func TestWorkspacesController(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	ws := models.Workspace{ID: testWorkspaceID, Name: "Marketing", CreatedBy: testWorkspaceOwner, CreatedAt: now}
+	wsURL := &models.URL{
+		ShortIdentifier: "abcdefgh",
+		URL:             "https://example.com",
+		VisitorUUID:     testWorkspaceOwner,
+		WorkspaceID:     testWorkspaceID,
+		CreatedAt:       now,
+	}
+	wsPath := "/api/workspaces/" + testWorkspaceID
+
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		body       string
+		mock       func(m *mocksctrl.MockWorkspaceManager)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:   "create workspace",
+			method: http.MethodPost,
+			url:    "/api/workspaces",
+			body:   `{"name":"Marketing"}`,
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().Create(gomock.Any(), testWorkspaceOwner, "Marketing").Return(&ws, nil)
+			},
+			wantStatus: http.StatusCreated,
+			wantBody: `{"id":"` + testWorkspaceID + `","name":"Marketing","created_by":"` + testWorkspaceOwner +
+				`","created_at":"2025-01-02T03:04:05Z","role":"owner"}`,
+		},
+		{
+			name:       "create workspace invalid json",
+			method:     http.MethodPost,
+			url:        "/api/workspaces",
+			body:       `{"name":`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "create workspace without name",
+			method: http.MethodPost,
+			url:    "/api/workspaces",
+			body:   `{}`,
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().Create(gomock.Any(), testWorkspaceOwner, "").Return(nil, services.ErrInvalidArgument)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "list workspaces",
+			method: http.MethodGet,
+			url:    "/api/workspaces",
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().List(gomock.Any(), testWorkspaceOwner).
+					Return([]models.WorkspaceMembership{{Workspace: ws, Role: models.WorkspaceRoleEditor}}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `[{"id":"` + testWorkspaceID + `","name":"Marketing","created_by":"` + testWorkspaceOwner +
+				`","created_at":"2025-01-02T03:04:05Z","role":"editor"}]`,
+		},
+		{
+			name:   "list workspaces empty",
+			method: http.MethodGet,
+			url:    "/api/workspaces",
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().List(gomock.Any(), testWorkspaceOwner).Return(nil, nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:   "get workspace",
+			method: http.MethodGet,
+			url:    wsPath,
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().Get(gomock.Any(), testWorkspaceOwner, testWorkspaceID).Return(&services.WorkspaceDetails{
+					Workspace: &ws,
+					Role:      models.WorkspaceRoleOwner,
+					Members: []models.WorkspaceMember{
+						{VisitorUUID: testWorkspaceOwner, Role: models.WorkspaceRoleOwner, CreatedAt: now},
+						{VisitorUUID: testWorkspaceGuest, Role: models.WorkspaceRoleViewer, CreatedAt: now},
+					},
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "get workspace not a member",
+			method: http.MethodGet,
+			url:    wsPath,
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().Get(gomock.Any(), testWorkspaceOwner, testWorkspaceID).Return(nil, services.ErrRecordNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "set member",
+			method: http.MethodPut,
+			url:    wsPath + "/members/" + testWorkspaceGuest,
+			body:   `{"role":"viewer"}`,
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().SetMember(
+					gomock.Any(), testWorkspaceOwner, testWorkspaceID, testWorkspaceGuest, models.WorkspaceRoleViewer,
+				).Return(&models.WorkspaceMember{
+					WorkspaceID: testWorkspaceID,
+					VisitorUUID: testWorkspaceGuest,
+					Role:        models.WorkspaceRoleViewer,
+					CreatedAt:   now,
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"visitor_uuid":"` + testWorkspaceGuest + `","role":"viewer","created_at":"2025-01-02T03:04:05Z"}`,
+		},
+		{
+			name:   "set member by editor",
+			method: http.MethodPut,
+			url:    wsPath + "/members/" + testWorkspaceGuest,
+			body:   `{"role":"owner"}`,
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().SetMember(gomock.Any(), testWorkspaceOwner, testWorkspaceID, testWorkspaceGuest, gomock.Any()).
+					Return(nil, services.ErrForbidden)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "set member unknown role",
+			method: http.MethodPut,
+			url:    wsPath + "/members/" + testWorkspaceGuest,
+			body:   `{"role":"admin"}`,
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().SetMember(gomock.Any(), testWorkspaceOwner, testWorkspaceID, testWorkspaceGuest, gomock.Any()).
+					Return(nil, services.ErrInvalidArgument)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "remove member",
+			method: http.MethodDelete,
+			url:    wsPath + "/members/" + testWorkspaceGuest,
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().RemoveMember(gomock.Any(), testWorkspaceOwner, testWorkspaceID, testWorkspaceGuest).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:   "remove last owner",
+			method: http.MethodDelete,
+			url:    wsPath + "/members/" + testWorkspaceOwner,
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().RemoveMember(gomock.Any(), testWorkspaceOwner, testWorkspaceID, testWorkspaceOwner).
+					Return(services.ErrInvalidArgument)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "create url",
+			method: http.MethodPost,
+			url:    wsPath + "/urls",
+			body:   `{"url":"https://example.com"}`,
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().CreateURL(gomock.Any(), testWorkspaceOwner, testWorkspaceID, "https://example.com").
+					Return(wsURL, true, nil)
+			},
+			wantStatus: http.StatusCreated,
+			wantBody: `{"short_id":"abcdefgh","short_url":"http://test.com/abcdefgh",` +
+				`"original_url":"https://example.com","created_by":"` + testWorkspaceOwner +
+				`","state":"active","created_at":"2025-01-02T03:04:05Z"}`,
+		},
+		{
+			name:   "create existing url",
+			method: http.MethodPost,
+			url:    wsPath + "/urls",
+			body:   `{"url":"https://example.com"}`,
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().CreateURL(gomock.Any(), testWorkspaceOwner, testWorkspaceID, "https://example.com").
+					Return(wsURL, false, nil)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "create invalid url",
+			method:     http.MethodPost,
+			url:        wsPath + "/urls",
+			body:       `{"url":"ftp:/nowhere"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "create url by viewer",
+			method: http.MethodPost,
+			url:    wsPath + "/urls",
+			body:   `{"url":"https://example.com"}`,
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().CreateURL(gomock.Any(), testWorkspaceOwner, testWorkspaceID, gomock.Any()).
+					Return(nil, false, services.ErrForbidden)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "list urls",
+			method: http.MethodGet,
+			url:    wsPath + "/urls",
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().ListURLs(gomock.Any(), testWorkspaceOwner, testWorkspaceID).Return([]models.URL{*wsURL}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "list urls empty",
+			method: http.MethodGet,
+			url:    wsPath + "/urls",
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().ListURLs(gomock.Any(), testWorkspaceOwner, testWorkspaceID).Return(nil, nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:   "list urls storage error",
+			method: http.MethodGet,
+			url:    wsPath + "/urls",
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().ListURLs(gomock.Any(), testWorkspaceOwner, testWorkspaceID).Return(nil, errLeaked)
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error":"internal error"}`,
+		},
+		{
+			name:   "update url",
+			method: http.MethodPatch,
+			url:    wsPath + "/urls/abcdefgh",
+			body:   `{"url":"https://example.com","tags":["promo"]}`,
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().UpdateURL(gomock.Any(), testWorkspaceOwner, testWorkspaceID, "abcdefgh", gomock.Any()).
+					DoAndReturn(func(_, _, _, _ any, upd repositories.URLUpdate) (*models.URL, error) {
+						assert.Equal(t, "https://example.com", *upd.URL)
+						assert.Equal(t, []string{"promo"}, *upd.Tags)
+						return wsURL, nil
+					})
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "update url duplicate",
+			method: http.MethodPatch,
+			url:    wsPath + "/urls/abcdefgh",
+			body:   `{"url":"https://example.org"}`,
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().UpdateURL(gomock.Any(), testWorkspaceOwner, testWorkspaceID, "abcdefgh", gomock.Any()).
+					Return(nil, services.ErrDuplicateKey)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "update url invalid url",
+			method:     http.MethodPatch,
+			url:        wsPath + "/urls/abcdefgh",
+			body:       `{"url":"not a url"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "delete url",
+			method: http.MethodDelete,
+			url:    wsPath + "/urls/abcdefgh",
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().DeleteURL(gomock.Any(), testWorkspaceOwner, testWorkspaceID, "abcdefgh").Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:   "delete unknown url",
+			method: http.MethodDelete,
+			url:    wsPath + "/urls/unknown1",
+			mock: func(m *mocksctrl.MockWorkspaceManager) {
+				m.EXPECT().DeleteURL(gomock.Any(), testWorkspaceOwner, testWorkspaceID, "unknown1").
+					Return(services.ErrRecordNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			workspaces := mocksctrl.NewMockWorkspaceManager(ctrl)
+			if tt.mock != nil {
+				tt.mock(workspaces)
+			}
+
+			router := newTestRouter(mocksctrl.NewMockShortURLStore(ctrl), func(p *RouterParams) { p.Workspaces = workspaces })
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.AddCookie(visitorCookie(t, testWorkspaceOwner))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			assertResponseMatchesSpec(t, req, w.Result())
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package memory
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 
 	"github.com/goccy/go-json"
@@ -173,6 +174,30 @@ func Set[T any](ctx context.Context, key string, val *T, m *MStorage, opts ...fu
 	}
 }
 
+// Delete удаляет значение по ключу.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - key: ключ
+//
+// Возвращает:
+//   - error: ErrNotFound если ключ не существует
+func (m *MStorage) Delete(ctx context.Context, key string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck
+	default:
+		m.m.Lock()
+		defer m.m.Unlock()
+
+		if _, ok := m.data[key]; !ok {
+			return ErrNotFound
+		}
+		delete(m.data, key)
+		return nil
+	}
+}
+
 // FilterAll возвращает все значения, удовлетворяющие предикату.
 //
 // Параметры:
@@ -204,6 +229,36 @@ func FilterAll[T any](ctx context.Context, m *MStorage, fn func(T) bool) ([]T, e
 	return result, nil
 }
 
+// ForEach вызывает fn для каждого значения хранилища без накопления результата.
+// Удобен для агрегации по всему хранилищу.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - m: хранилище
+//   - fn: функция, вызываемая для каждого значения
+//
+// Возвращает:
+//   - error: ошибка обхода
+func ForEach[T any](ctx context.Context, m *MStorage, fn func(T)) error {
+	m.m.RLock()
+	defer m.m.RUnlock()
+
+	for _, bytes := range m.data {
+		select {
+		case <-ctx.Done():
+			return ctx.Err() //nolint:wrapcheck
+		default:
+		}
+
+		var val T
+		if err := json.Unmarshal(bytes, &val); err != nil {
+			return fmt.Errorf("failed to unmarshal json for object `%+v`: %w", val, err)
+		}
+		fn(val)
+	}
+	return nil
+}
+
 // GetAll возвращает все значения из хранилища.
 //
 // Параметры:
@@ -234,3 +289,52 @@ func GetAll[T any](ctx context.Context, m *MStorage) ([]T, error) {
 	}
 	return result, nil
 }
+
+// ForEachRaw вызывает fn для каждой записи хранилища в сериализованном виде.
+// Используется для резервного копирования без знания типов записей.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - fn: функция, вызываемая для каждой пары ключ-значение; ее ошибка прерывает обход
+//
+// Возвращает:
+//   - error: ошибка обхода или ошибка fn
+func (m *MStorage) ForEachRaw(ctx context.Context, fn func(key string, value []byte) error) error {
+	m.m.RLock()
+	defer m.m.RUnlock()
+
+	for key, bytes := range m.data {
+		select {
+		case <-ctx.Done():
+			return ctx.Err() //nolint:wrapcheck
+		default:
+		}
+		if err := fn(key, bytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetRaw сохраняет сериализованное значение по ключу, перезаписывая существующее.
+// Используется для восстановления из резервной копии.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - key: ключ
+//   - value: значение в формате JSON
+//
+// Возвращает:
+//   - error: ошибка сохранения
+func (m *MStorage) SetRaw(ctx context.Context, key string, value []byte) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck
+	default:
+		m.m.Lock()
+		defer m.m.Unlock()
+
+		m.data[key] = slices.Clone(value)
+		return nil
+	}
+}
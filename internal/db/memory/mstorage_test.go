@@ -69,3 +69,38 @@ func TestSet(t *testing.T) {
 		})
 	}
 }
+
+func TestMStorage_Delete(t *testing.T) {
+	ms := NewMemStorage()
+	if err := Set[int](t.Context(), "key1", new(int), ms); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ms.Delete(t.Context(), "key1"); err != nil {
+		t.Errorf("Delete() error = %+v", err)
+	}
+	if exists, _ := ms.IsExist(t.Context(), "key1"); exists {
+		t.Error("Delete() key still exists")
+	}
+	if err := ms.Delete(t.Context(), "key1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() error = %+v, want %+v", err, ErrNotFound)
+	}
+}
+
+func TestForEach(t *testing.T) {
+	ms := NewMemStorage()
+	for i, key := range []string{"key1", "key2", "key3"} {
+		val := i + 1
+		if err := Set[int](t.Context(), key, &val, ms); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var sum int
+	if err := ForEach[int](t.Context(), ms, func(v int) { sum += v }); err != nil {
+		t.Fatalf("ForEach() error = %+v", err)
+	}
+	if sum != 6 {
+		t.Errorf("ForEach() sum = %d, want 6", sum)
+	}
+}
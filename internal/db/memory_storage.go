@@ -1,12 +1,19 @@
 package db
 
 import (
+	"slices"
+	"sync"
+
 	"github.com/fsdevblog/shorturl/internal/db/memory"
 )
 
 // MemoryStorage представляет собой обертку над внутренним in-memory хранилищем.
+// Встроенное хранилище содержит URL, остальные сущности хранятся в именованных коллекциях.
 type MemoryStorage struct {
 	*memory.MStorage
+
+	mu          sync.Mutex
+	collections map[string]*memory.MStorage
 }
 
 // NewMemStorage создает новый экземпляр in-memory хранилища.
@@ -15,6 +22,43 @@ type MemoryStorage struct {
 //   - *MemoryStorage: инициализированное хранилище в памяти
 func NewMemStorage() *MemoryStorage {
 	return &MemoryStorage{
-		MStorage: memory.NewMemStorage(),
+		MStorage:    memory.NewMemStorage(),
+		collections: make(map[string]*memory.MStorage),
+	}
+}
+
+// Collection возвращает именованную коллекцию, создавая её при первом обращении.
+// Коллекции нужны, чтобы записи разных типов не смешивались в одном хранилище.
+//
+// Параметры:
+//   - name: имя коллекции
+//
+// Возвращает:
+//   - *memory.MStorage: хранилище коллекции
+func (s *MemoryStorage) Collection(name string) *memory.MStorage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.collections[name]
+	if !ok {
+		c = memory.NewMemStorage()
+		s.collections[name] = c
+	}
+	return c
+}
+
+// CollectionNames возвращает имена созданных коллекций в алфавитном порядке.
+//
+// Возвращает:
+//   - []string: имена коллекций
+func (s *MemoryStorage) CollectionNames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.collections))
+	for name := range s.collections {
+		names = append(names, name)
 	}
+	slices.Sort(names)
+	return names
 }
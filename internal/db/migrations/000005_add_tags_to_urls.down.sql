@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_urls_short_identifier;
+ALTER TABLE urls DROP COLUMN tags;
+ALTER TABLE urls ALTER COLUMN short_identifier TYPE VARCHAR(8);
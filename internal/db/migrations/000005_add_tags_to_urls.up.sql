@@ -0,0 +1,3 @@
+ALTER TABLE urls ALTER COLUMN short_identifier TYPE VARCHAR(64);
+ALTER TABLE urls ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_urls_short_identifier ON urls (short_identifier);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY,
+    created_at timestamp with time zone DEFAULT NOW(),
+    visitor_uuid VARCHAR(36) NOT NULL,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    prefix VARCHAR(32) NOT NULL UNIQUE,
+    hash VARCHAR(64) NOT NULL,
+    revoked_at timestamp with time zone
+);
+CREATE INDEX IF NOT EXISTS idx_api_keys_visitor_uuid ON api_keys (visitor_uuid);
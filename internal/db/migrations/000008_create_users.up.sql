@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY,
+    created_at timestamp with time zone DEFAULT NOW(),
+    email VARCHAR(254) NOT NULL UNIQUE,
+    password_hash VARCHAR(60) NOT NULL
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS oidc_identities (
+    issuer VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    user_id UUID NOT NULL,
+    email VARCHAR(254) NOT NULL DEFAULT '',
+    created_at timestamp with time zone DEFAULT NOW(),
+    PRIMARY KEY (issuer, subject)
+);
+CREATE INDEX IF NOT EXISTS idx_oidc_identities_user_id ON oidc_identities (user_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS visitor_bans;
+ALTER TABLE urls DROP COLUMN disabled_legal;
+ALTER TABLE urls DROP COLUMN disabled_reason;
+ALTER TABLE urls DROP COLUMN disabled_at;
@@ -0,0 +1,19 @@
+ALTER TABLE urls ADD COLUMN disabled_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+ALTER TABLE urls ADD COLUMN disabled_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN disabled_legal BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE TABLE IF NOT EXISTS visitor_bans (
+    visitor_uuid VARCHAR(36) PRIMARY KEY,
+    reason TEXT NOT NULL DEFAULT '',
+    banned_by VARCHAR(36) NOT NULL,
+    created_at timestamp with time zone DEFAULT NOW()
+);
+CREATE TABLE IF NOT EXISTS audit_log (
+    id UUID PRIMARY KEY,
+    created_at timestamp with time zone DEFAULT NOW(),
+    actor_uuid VARCHAR(36) NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    target VARCHAR(64) NOT NULL,
+    reason TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log (target, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at);
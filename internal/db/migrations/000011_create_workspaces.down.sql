@@ -0,0 +1,7 @@
+DELETE FROM urls WHERE workspace_id IS NOT NULL;
+DROP INDEX IF EXISTS idx_urls_workspace_id_url;
+DROP INDEX idx_visitor_uuid_url;
+CREATE UNIQUE INDEX idx_visitor_uuid_url ON urls (visitor_uuid, url);
+ALTER TABLE urls DROP COLUMN workspace_id;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
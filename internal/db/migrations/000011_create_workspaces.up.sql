@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS workspaces (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    created_by VARCHAR(36) NOT NULL,
+    created_at timestamp with time zone DEFAULT NOW()
+);
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id UUID NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    visitor_uuid VARCHAR(36) NOT NULL,
+    role VARCHAR(16) NOT NULL,
+    created_at timestamp with time zone DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, visitor_uuid)
+);
+CREATE INDEX IF NOT EXISTS idx_workspace_members_visitor_uuid ON workspace_members (visitor_uuid);
+
+ALTER TABLE urls ADD COLUMN workspace_id UUID REFERENCES workspaces (id);
+-- Личные ссылки уникальны в пределах посетителя, ссылки пространства - в пределах пространства.
+DROP INDEX idx_visitor_uuid_url;
+CREATE UNIQUE INDEX idx_visitor_uuid_url ON urls (visitor_uuid, url) WHERE workspace_id IS NULL;
+CREATE UNIQUE INDEX idx_urls_workspace_id_url ON urls (workspace_id, url) WHERE workspace_id IS NOT NULL;
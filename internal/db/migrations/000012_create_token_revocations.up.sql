@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti VARCHAR(36) PRIMARY KEY,
+    visitor_uuid VARCHAR(36) NOT NULL,
+    created_at timestamp with time zone DEFAULT NOW(),
+    expires_at timestamp with time zone NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
+
+CREATE TABLE IF NOT EXISTS session_revocations (
+    visitor_uuid VARCHAR(36) PRIMARY KEY,
+    revoked_before timestamp with time zone NOT NULL,
+    expires_at timestamp with time zone NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_session_revocations_expires_at ON session_revocations (expires_at);
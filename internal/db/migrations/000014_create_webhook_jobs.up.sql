@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS webhook_jobs (
+    id UUID PRIMARY KEY,
+    created_at timestamp with time zone DEFAULT NOW(),
+    webhook_id UUID NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id UUID NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    attempt INT NOT NULL DEFAULT 0,
+    next_attempt_at timestamp with time zone NOT NULL,
+    payload JSONB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_jobs_next_attempt_at ON webhook_jobs (next_attempt_at);
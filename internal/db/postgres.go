@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/fsdevblog/shorturl/internal/tracing"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // NewPostgresConnection создает новый пул подключений к PostgreSQL.
+// Запросы трассируются: имя спана берется из комментария в первой строке запроса.
 //
 // Параметры:
 //   - ctx: контекст выполнения
@@ -21,6 +23,7 @@ func NewPostgresConnection(ctx context.Context, dsn string) (*pgxpool.Pool, erro
 	if confErr != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", confErr)
 	}
+	poolConfig.ConnConfig.Tracer = tracing.NewPgxTracer()
 	pool, poolErr := pgxpool.NewWithConfig(ctx, poolConfig)
 	if poolErr != nil {
 		return nil, fmt.Errorf("failed to create pool: %w", poolErr)
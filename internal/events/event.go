@@ -0,0 +1,26 @@
+// Package events предоставляет in-process шину событий, связанных со ссылками:
+// создание, удаление и переходы по коротким ссылкам.
+package events
+
+import "time"
+
+// Type тип события.
+type Type string
+
+// TypeURLCreated ссылка создана.
+// TypeURLDeleted ссылка удалена.
+// TypeURLClicked переход по ссылке.
+const (
+	TypeURLCreated Type = "url.created"
+	TypeURLDeleted Type = "url.deleted"
+	TypeURLClicked Type = "url.clicked"
+)
+
+// Event событие, связанное с короткой ссылкой.
+type Event struct {
+	Type            Type      // Тип события
+	VisitorUUID     string    // Владелец ссылки
+	ShortIdentifier string    // Короткий идентификатор ссылки
+	URL             string    // Оригинальный URL (может быть пустым для удаления)
+	OccurredAt      time.Time // Время события
+}
@@ -0,0 +1,229 @@
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+const defaultBufferSize = 64 // Размер буфера подписчика по умолчанию.
+
+// Options опции хаба событий.
+type Options struct {
+	BufferSize int // Размер буфера канала каждого подписчика
+}
+
+// Hub реализует in-process pub/sub для событий ссылок.
+// Каждый подписчик получает собственный ограниченный буфер: если подписчик не успевает
+// вычитывать события, новые события для него отбрасываются, не блокируя издателя.
+// Подписчику, которому нужен буфер больше общего, предназначен SubscribeAllBuffered.
+type Hub struct {
+	mu         sync.RWMutex
+	byVisitor  map[string]map[*Subscription]struct{}
+	all        map[*Subscription]struct{}
+	bufferSize int
+	closed     bool
+}
+
+// NewHub создает новый экземпляр хаба событий.
+//
+// Параметры:
+//   - opts: функции для настройки опций
+//
+// Возвращает:
+//   - *Hub: инициализированный хаб
+func NewHub(opts ...func(*Options)) *Hub {
+	options := Options{BufferSize: defaultBufferSize}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.BufferSize <= 0 {
+		options.BufferSize = defaultBufferSize
+	}
+	return &Hub{
+		byVisitor:  make(map[string]map[*Subscription]struct{}),
+		all:        make(map[*Subscription]struct{}),
+		bufferSize: options.BufferSize,
+	}
+}
+
+// Subscribe подписывает на события ссылок указанного посетителя.
+//
+// Параметры:
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - *Subscription: подписка, которую необходимо закрыть после использования
+func (h *Hub) Subscribe(visitorUUID string) *Subscription {
+	return h.subscribe(visitorUUID, false, h.bufferSize)
+}
+
+// SubscribeAll подписывает на события всех посетителей.
+//
+// Возвращает:
+//   - *Subscription: подписка, которую необходимо закрыть после использования
+func (h *Hub) SubscribeAll() *Subscription {
+	return h.subscribe("", true, h.bufferSize)
+}
+
+// SubscribeAllBuffered подписывает на события всех посетителей с собственным размером буфера.
+// Как и для других подписок, события сверх буфера отбрасываются и учитываются в Subscription.Dropped.
+//
+// Параметры:
+//   - size: размер буфера подписки (если не больше нуля, используется размер буфера хаба)
+//
+// Возвращает:
+//   - *Subscription: подписка, которую необходимо закрыть после использования
+func (h *Hub) SubscribeAllBuffered(size int) *Subscription {
+	if size <= 0 {
+		size = h.bufferSize
+	}
+	return h.subscribe("", true, size)
+}
+
+func (h *Hub) subscribe(visitorUUID string, all bool, bufferSize int) *Subscription {
+	sub := &Subscription{
+		hub:         h,
+		visitorUUID: visitorUUID,
+		all:         all,
+		ch:          make(chan Event, bufferSize),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		// Хаб уже остановлен, возвращаем сразу закрытую подписку.
+		sub.closed = true
+		close(sub.ch)
+		return sub
+	}
+
+	if all {
+		h.all[sub] = struct{}{}
+		return sub
+	}
+	subs, ok := h.byVisitor[visitorUUID]
+	if !ok {
+		subs = make(map[*Subscription]struct{})
+		h.byVisitor[visitorUUID] = subs
+	}
+	subs[sub] = struct{}{}
+	return sub
+}
+
+// Publish рассылает событие подписчикам владельца ссылки и подписчикам на все события.
+// Метод никогда не блокируется.
+//
+// Параметры:
+//   - e: событие
+func (h *Hub) Publish(e Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.closed {
+		return
+	}
+	for sub := range h.byVisitor[e.VisitorUUID] {
+		sub.deliver(e)
+	}
+	for sub := range h.all {
+		sub.deliver(e)
+	}
+}
+
+// Subscribers возвращает текущее количество подписок.
+func (h *Hub) Subscribers() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	n := len(h.all)
+	for _, subs := range h.byVisitor {
+		n += len(subs)
+	}
+	return n
+}
+
+// Close закрывает все подписки и перестает принимать новые события.
+// Используется при остановке сервера, чтобы долгоживущие соединения завершились.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for _, subs := range h.byVisitor {
+		for sub := range subs {
+			sub.closeLocked()
+		}
+	}
+	for sub := range h.all {
+		sub.closeLocked()
+	}
+	h.byVisitor = make(map[string]map[*Subscription]struct{})
+	h.all = make(map[*Subscription]struct{})
+}
+
+// unsubscribe удаляет подписку из хаба.
+func (h *Hub) unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if sub.closed {
+		return
+	}
+	if sub.all {
+		delete(h.all, sub)
+	} else if subs, ok := h.byVisitor[sub.visitorUUID]; ok {
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(h.byVisitor, sub.visitorUUID)
+		}
+	}
+	sub.closeLocked()
+}
+
+// Subscription подписка на события хаба.
+type Subscription struct {
+	hub         *Hub
+	visitorUUID string
+	all         bool
+	ch          chan Event
+	closed      bool // защищено мьютексом хаба
+	dropped     atomic.Uint64
+}
+
+// Events возвращает канал событий. Канал закрывается при закрытии подписки или хаба.
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Dropped возвращает количество событий, отброшенных из-за переполнения буфера.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close отписывается от хаба. Повторный вызов безопасен.
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s)
+}
+
+// deliver неблокирующе отправляет событие подписчику.
+// Вызывается под RLock хаба, поэтому канал не может быть закрыт во время отправки.
+func (s *Subscription) deliver(e Event) {
+	select {
+	case s.ch <- e:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// closeLocked закрывает канал подписки. Вызывается под Lock хаба.
+func (s *Subscription) closeLocked() {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.ch)
+}
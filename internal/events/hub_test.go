@@ -0,0 +1,96 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHub_PublishRouting(t *testing.T) {
+	hub := NewHub()
+	defer hub.Close()
+
+	owner := hub.Subscribe("owner")
+	defer owner.Close()
+	stranger := hub.Subscribe("stranger")
+	defer stranger.Close()
+	all := hub.SubscribeAll()
+	defer all.Close()
+
+	hub.Publish(Event{Type: TypeURLCreated, VisitorUUID: "owner", ShortIdentifier: "12345678"})
+
+	require.Len(t, owner.Events(), 1)
+	require.Len(t, all.Events(), 1)
+	assert.Empty(t, stranger.Events())
+
+	ev := <-owner.Events()
+	assert.Equal(t, TypeURLCreated, ev.Type)
+	assert.Equal(t, "12345678", ev.ShortIdentifier)
+}
+
+func TestHub_DropsWhenBufferFull(t *testing.T) {
+	hub := NewHub(func(o *Options) {
+		o.BufferSize = 2
+	})
+	defer hub.Close()
+
+	sub := hub.Subscribe("owner")
+	defer sub.Close()
+
+	for range 5 {
+		hub.Publish(Event{Type: TypeURLClicked, VisitorUUID: "owner"})
+	}
+
+	assert.Len(t, sub.Events(), 2)
+	assert.Equal(t, uint64(3), sub.Dropped())
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe("owner")
+
+	sub.Close()
+	sub.Close() // повторное закрытие безопасно
+
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+
+	active := hub.Subscribe("owner")
+	hub.Close()
+	_, ok = <-active.Events()
+	assert.False(t, ok)
+
+	// Публикация и подписка после остановки хаба не паникуют.
+	hub.Publish(Event{Type: TypeURLClicked, VisitorUUID: "owner"})
+	late := hub.Subscribe("owner")
+	_, ok = <-late.Events()
+	assert.False(t, ok)
+	active.Close()
+}
+
+func TestHub_SubscribeAllBuffered(t *testing.T) {
+	hub := NewHub(func(o *Options) {
+		o.BufferSize = 2
+	})
+	sub := hub.SubscribeAllBuffered(10)
+	small := hub.SubscribeAll()
+
+	const published = 15
+	for i := range published {
+		hub.Publish(Event{Type: TypeURLClicked, VisitorUUID: "owner", ShortIdentifier: string(rune('a' + i))})
+	}
+
+	for i := range 10 {
+		ev := <-sub.Events()
+		assert.Equal(t, string(rune('a'+i)), ev.ShortIdentifier, "порядок событий сохраняется")
+	}
+	assert.Equal(t, uint64(published-10), sub.Dropped())
+	assert.Equal(t, uint64(published-2), small.Dropped())
+
+	hub.Close()
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	sub.Close() // закрытие после остановки хаба безопасно
+	small.Close()
+}
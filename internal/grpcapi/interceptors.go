@@ -0,0 +1,237 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/grpcapi/pb"
+	"github.com/fsdevblog/shorturl/internal/tokens"
+	"github.com/fsdevblog/shorturl/internal/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AuthorizationMetadataKey ключ метаданных с JWT токеном посетителя.
+// VisitorJWTExpireDuration срок годности выдаваемого JWT токена.
+const (
+	AuthorizationMetadataKey = "authorization"
+	VisitorJWTExpireDuration = 24 * time.Hour
+
+	bearerPrefix = "Bearer "
+)
+
+// visitorUUIDKey ключ контекста для UUID посетителя.
+type visitorUUIDKey struct{}
+
+// issuingMethods методы, которые выдают новый токен посетителю без валидного токена.
+// Для остальных методов, кроме публичных, токен обязателен.
+//
+//nolint:gochecknoglobals
+var issuingMethods = map[string]bool{
+	pb.ShortenerService_Shorten_FullMethodName:      true,
+	pb.ShortenerService_BatchShorten_FullMethodName: true,
+}
+
+// publicMethods методы, доступные без токена.
+//
+//nolint:gochecknoglobals
+var publicMethods = map[string]bool{
+	pb.ShortenerService_Resolve_FullMethodName: true,
+}
+
+// mutatingMethods методы, изменяющие данные. Заблокированным посетителям они недоступны.
+//
+//nolint:gochecknoglobals
+var mutatingMethods = map[string]bool{
+	pb.ShortenerService_Shorten_FullMethodName:        true,
+	pb.ShortenerService_BatchShorten_FullMethodName:   true,
+	pb.ShortenerService_DeleteUserURLs_FullMethodName: true,
+}
+
+// VisitorUUIDFromContext возвращает UUID посетителя, установленный AuthInterceptor.
+//
+// Параметры:
+//   - ctx: контекст вызова
+//
+// Возвращает:
+//   - string: UUID посетителя
+//   - bool: true, если посетитель определен
+func VisitorUUIDFromContext(ctx context.Context) (string, bool) {
+	visitorUUID, ok := ctx.Value(visitorUUIDKey{}).(string)
+	return visitorUUID, ok && visitorUUID != ""
+}
+
+// AuthInterceptor создает перехватчик аутентификации посетителей, аналогичный
+// middlewares.VisitorCookieMiddleware. Токен передается в метаданных
+// `authorization: Bearer <token>`. Отозванный токен считается невалидным.
+//
+// Алгоритм работы:
+//  1. Если токен валиден и не отозван - сохраняет UUID посетителя в контексте вызова
+//  2. Для Shorten и BatchShorten без валидного токена генерирует новый UUID и токен
+//     и отправляет его в заголовке ответа `authorization`
+//  3. Для Resolve токен не требуется
+//  4. Для остальных методов без валидного токена возвращает codes.Unauthenticated
+//
+// Параметры:
+//   - keyring: набор ключей подписи JWT токенов
+//   - revocations: источник отзывов токенов (если nil, токены не отзываются)
+//
+// Возвращает:
+//   - grpc.UnaryServerInterceptor: перехватчик
+func AuthInterceptor(keyring *tokens.Keyring, revocations RevocationChecker) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		if visitorUUID, ok := visitorFromMetadata(ctx, keyring, revocations); ok {
+			return handler(context.WithValue(ctx, visitorUUIDKey{}, visitorUUID), req)
+		}
+
+		switch {
+		case publicMethods[info.FullMethod]:
+			return handler(ctx, req)
+		case issuingMethods[info.FullMethod]:
+			visitorUUID, tokenString, err := issueVisitorToken(keyring)
+			if err != nil {
+				return nil, newInternalError(err)
+			}
+			if err = grpc.SetHeader(ctx, metadata.Pairs(AuthorizationMetadataKey, bearerPrefix+tokenString)); err != nil {
+				return nil, newInternalError(fmt.Errorf("set authorization header: %w", err))
+			}
+			return handler(context.WithValue(ctx, visitorUUIDKey{}, visitorUUID), req)
+		default:
+			return nil, status.Error(codes.Unauthenticated, "visitor token required")
+		}
+	}
+}
+
+// BanInterceptor создает перехватчик, отклоняющий вызовы заблокированных посетителей, изменяющие
+// данные (Shorten, BatchShorten, DeleteUserURLs), аналогично controllers.BanMiddleware.
+// Должен следовать за AuthInterceptor, т.к. использует UUID посетителя из контекста.
+//
+// Параметры:
+//   - checker: источник блокировок посетителей
+//
+// Возвращает:
+//   - grpc.UnaryServerInterceptor: перехватчик, отвечающий codes.PermissionDenied заблокированным посетителям
+func BanInterceptor(checker BanChecker) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		if !mutatingMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		visitorUUID, ok := VisitorUUIDFromContext(ctx)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, DefaultRequestTimeout)
+		banned, err := checker.IsBanned(checkCtx, visitorUUID)
+		cancel()
+		switch {
+		case err != nil:
+			return nil, newInternalError(fmt.Errorf("check visitor ban: %w", err))
+		case banned:
+			return nil, status.Error(codes.PermissionDenied, "visitor is banned")
+		default:
+			return handler(ctx, req)
+		}
+	}
+}
+
+// LoggingInterceptor создает перехватчик, логирующий каждый вызов аналогично
+// middlewares.LoggerMiddleware: метод, код ответа, длительность и идентификаторы трассировки.
+// Для внутренних ошибок в лог пишется исходная ошибка, подробности которой клиент не получает.
+//
+// Параметры:
+//   - logger: логгер
+//
+// Возвращает:
+//   - grpc.UnaryServerInterceptor: перехватчик
+func LoggingInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		fields := append([]zap.Field{
+			zap.String("method", info.FullMethod),
+			zap.String("code", code.String()),
+			zap.Duration("latency", time.Since(start)),
+		}, tracing.ZapFields(ctx)...)
+
+		switch code { //nolint:exhaustive
+		case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+			logger.Error("grpc request", append(fields, zap.Error(err))...)
+		default:
+			logger.Info("grpc request", fields...)
+		}
+		return resp, err
+	}
+}
+
+// visitorFromMetadata извлекает и проверяет токен посетителя из входящих метаданных.
+// Ошибка проверки отзыва не отклоняет токен, как и в middlewares.VisitorCookieMiddleware.
+// Токены gRPC API не продлевает и сессиями не управляет, поэтому непроверенный токен ничего сверх
+// доступа к ссылкам посетителя не получает.
+func visitorFromMetadata(ctx context.Context, keyring *tokens.Keyring, revocations RevocationChecker) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	values := md.Get(AuthorizationMetadataKey)
+	if len(values) == 0 || !strings.HasPrefix(values[0], bearerPrefix) {
+		return "", false
+	}
+
+	token, err := keyring.ValidateVisitorJWT(strings.TrimPrefix(values[0], bearerPrefix))
+	if err != nil || !token.Valid {
+		return "", false
+	}
+	// Безопасная операция, т.к. проверка типа происходит в Keyring.ValidateVisitorJWT.
+	claims := token.Claims.(*tokens.VisitorClaims) //nolint:errcheck
+	if claims.UUID == "" {
+		return "", false
+	}
+	if revocations != nil {
+		var issuedAt time.Time
+		if claims.IssuedAt != nil {
+			issuedAt = claims.IssuedAt.Time
+		}
+		revoked, revokedErr := revocations.IsRevoked(ctx, claims.UUID, claims.ID, claims.SessionID, issuedAt)
+		if revokedErr == nil && revoked {
+			return "", false
+		}
+	}
+	return claims.UUID, true
+}
+
+// issueVisitorToken генерирует новый UUID посетителя и JWT токен для него.
+func issueVisitorToken(keyring *tokens.Keyring) (string, string, error) {
+	u, err := uuid.NewRandom()
+	if err != nil {
+		return "", "", fmt.Errorf("generate uuid: %w", err)
+	}
+	tokenString, err := keyring.GenerateVisitorJWT(u.String(), VisitorJWTExpireDuration)
+	if err != nil {
+		return "", "", fmt.Errorf("issue visitor token: %w", err)
+	}
+	return u.String(), tokenString, nil
+}
@@ -0,0 +1,36 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/services"
+)
+
+// URLService определяет интерфейс сервиса коротких URL, используемый gRPC API.
+type URLService interface {
+	// Create создает запись models.URL. Возвращает модель, булево значение новая записи или нет и ошибку.
+	Create(ctx context.Context, visitorUUID string, rawURL string) (*models.URL, bool, error)
+	// BatchCreate делает пакетную вставку нескольких URL.
+	BatchCreate(ctx context.Context, visitorUUID string, rawURLs []string) (*services.BatchCreateShortURLsResponse, error)
+	// Visit возвращает URL для перехода по короткой ссылке и фиксирует переход.
+	Visit(ctx context.Context, shortID string) (*models.URL, error)
+	// GetAllByVisitorUUID возвращает все URL, созданные определенным посетителем.
+	GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.URL, error)
+	// MarkAsDeleted помечает указанные URL как удаленные. Возвращает удаленные и не найденные идентификаторы.
+	MarkAsDeleted(ctx context.Context, shortIDs []string, visitorUUID string) (*services.DeleteResult, error)
+}
+
+// BanChecker определяет источник блокировок посетителей.
+type BanChecker interface {
+	// IsBanned проверяет, заблокирован ли посетитель.
+	IsBanned(ctx context.Context, visitorUUID string) (bool, error)
+}
+
+// RevocationChecker определяет источник отзывов токенов посетителей.
+type RevocationChecker interface {
+	// IsRevoked проверяет, отозван ли токен по идентификатору, отзывом его сессии
+	// или отзывом всех сессий посетителя.
+	IsRevoked(ctx context.Context, visitorUUID string, jti string, sessionID string, issuedAt time.Time) (bool, error)
+}
@@ -0,0 +1,293 @@
+// Package grpcapi реализует gRPC API сервиса сокращения ссылок (см. api/proto/shortener/v1).
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/grpcapi/pb"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/fsdevblog/shorturl/internal/tokens"
+	"github.com/fsdevblog/shorturl/internal/urlvalidate"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultRequestTimeout таймаут обращения к сервисному слою, аналогичный HTTP API.
+const DefaultRequestTimeout = 3 * time.Second
+
+// Options опции gRPC сервера.
+type Options struct {
+	BaseURL        string          // Базовый адрес результирующего сокращенного URL
+	JWTSecret      []byte          // Секретный ключ JWT токенов посетителей (если не задан Keyring)
+	Keyring        *tokens.Keyring // Набор ключей JWT токенов посетителей
+	Logger         *zap.Logger     // Логгер вызовов (если nil, вызовы не логируются)
+	RequestTimeout time.Duration   // Таймаут обращения к сервисному слою
+	Bans           BanChecker      // Источник блокировок посетителей (если nil, блокировки не проверяются)
+	// Revocations источник отзывов токенов посетителей (если nil, токены не отзываются).
+	Revocations RevocationChecker
+}
+
+// Server реализация pb.ShortenerServiceServer поверх сервиса коротких URL.
+type Server struct {
+	pb.UnimplementedShortenerServiceServer
+
+	urlService     URLService
+	baseURL        string
+	requestTimeout time.Duration
+}
+
+// NewServer создает реализацию gRPC сервиса.
+//
+// Параметры:
+//   - urlService: сервис коротких URL
+//   - opts: функции для настройки опций
+//
+// Возвращает:
+//   - *Server: реализация сервиса
+func NewServer(urlService URLService, opts ...func(*Options)) *Server {
+	options := newOptions(opts...)
+	return &Server{
+		urlService:     urlService,
+		baseURL:        strings.TrimRight(options.BaseURL, "/"),
+		requestTimeout: options.RequestTimeout,
+	}
+}
+
+// New создает gRPC сервер с зарегистрированным сервисом и цепочкой перехватчиков
+// логирования, аутентификации и проверки блокировок.
+//
+// Параметры:
+//   - urlService: сервис коротких URL
+//   - opts: функции для настройки опций
+//
+// Возвращает:
+//   - *grpc.Server: сервер, готовый к запуску через Serve
+func New(urlService URLService, opts ...func(*Options)) *grpc.Server {
+	options := newOptions(opts...)
+
+	interceptors := make([]grpc.UnaryServerInterceptor, 0, 3) //nolint:mnd
+	if options.Logger != nil {
+		interceptors = append(interceptors, LoggingInterceptor(options.Logger))
+	}
+	keyring := options.Keyring
+	if keyring == nil {
+		keyring = tokens.NewStaticKeyring(options.JWTSecret)
+	}
+	interceptors = append(interceptors, AuthInterceptor(keyring, options.Revocations))
+	if options.Bans != nil {
+		interceptors = append(interceptors, BanInterceptor(options.Bans))
+	}
+
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+	pb.RegisterShortenerServiceServer(srv, NewServer(urlService, opts...))
+	return srv
+}
+
+// newOptions применяет функции настройки к опциям по умолчанию.
+func newOptions(opts ...func(*Options)) *Options {
+	options := &Options{RequestTimeout: DefaultRequestTimeout}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// Shorten сокращает URL. Для ранее сокращенного URL возвращает существующую ссылку с Created = false.
+func (s *Server) Shorten(ctx context.Context, req *pb.ShortenRequest) (*pb.ShortenResponse, error) {
+	visitorUUID, err := requireVisitorUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedURL, parseErr := urlvalidate.Validate(req.GetUrl())
+	if parseErr != nil {
+		return nil, status.Error(codes.InvalidArgument, parseErr.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.requestTimeout)
+	defer cancel()
+
+	sURL, isNewRecord, createErr := s.urlService.Create(ctx, visitorUUID, parsedURL.String())
+	if createErr != nil {
+		return nil, serviceError(createErr)
+	}
+	return &pb.ShortenResponse{ShortUrl: s.shortURL(sURL.ShortIdentifier), Created: isNewRecord}, nil
+}
+
+// BatchShorten сокращает несколько URL. Порядок результатов соответствует порядку запроса.
+func (s *Server) BatchShorten(ctx context.Context, req *pb.BatchShortenRequest) (*pb.BatchShortenResponse, error) {
+	visitorUUID, err := requireVisitorUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items := req.GetItems()
+	if len(items) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "empty request")
+	}
+
+	rawURLs := make([]string, len(items))
+	for i, item := range items {
+		if _, parseErr := urlvalidate.Validate(item.GetOriginalUrl()); parseErr != nil {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"%s is invalid URL (correlation_id %s)", item.GetOriginalUrl(), item.GetCorrelationId())
+		}
+		rawURLs[i] = item.GetOriginalUrl()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.requestTimeout)
+	defer cancel()
+
+	batchResponse, batchErr := s.urlService.BatchCreate(ctx, visitorUUID, rawURLs)
+	if batchErr != nil {
+		return nil, serviceError(batchErr)
+	}
+
+	results := make([]*pb.BatchShortenResult, batchResponse.Len())
+	var itemErr error
+	batchResponse.ReadResponse(func(i int, m models.URL, err error) {
+		if err != nil && !errors.Is(err, services.ErrDuplicateKey) {
+			itemErr = errors.Join(itemErr, err)
+		}
+		results[i] = &pb.BatchShortenResult{
+			CorrelationId: items[i].GetCorrelationId(),
+			ShortUrl:      s.shortURL(m.ShortIdentifier),
+			Created:       err == nil,
+		}
+	})
+	if itemErr != nil {
+		return nil, serviceError(itemErr)
+	}
+	return &pb.BatchShortenResponse{Items: results}, nil
+}
+
+// Resolve возвращает оригинальный URL и фиксирует переход, как и HTTP редирект.
+func (s *Server) Resolve(ctx context.Context, req *pb.ResolveRequest) (*pb.ResolveResponse, error) {
+	if !services.IsShortIdentifier(req.GetShortId()) {
+		return nil, status.Error(codes.NotFound, services.ErrRecordNotFound.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.requestTimeout)
+	defer cancel()
+
+	sURL, err := s.urlService.Visit(ctx, req.GetShortId())
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	if sURL.DeletedAt != nil {
+		return nil, status.Error(codes.FailedPrecondition, "url is deleted")
+	}
+	if sURL.DisabledAt != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "url is disabled: %s", sURL.DisabledReason)
+	}
+	return &pb.ResolveResponse{OriginalUrl: sURL.URL}, nil
+}
+
+// ListUserURLs возвращает ссылки посетителя.
+func (s *Server) ListUserURLs(ctx context.Context, _ *pb.ListUserURLsRequest) (*pb.ListUserURLsResponse, error) {
+	visitorUUID, err := requireVisitorUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.requestTimeout)
+	defer cancel()
+
+	urls, listErr := s.urlService.GetAllByVisitorUUID(ctx, visitorUUID)
+	if listErr != nil {
+		return nil, serviceError(listErr)
+	}
+
+	resp := &pb.ListUserURLsResponse{Urls: make([]*pb.UserURL, len(urls))}
+	for i, u := range urls {
+		resp.Urls[i] = &pb.UserURL{ShortUrl: s.shortURL(u.ShortIdentifier), OriginalUrl: u.URL}
+	}
+	return resp, nil
+}
+
+// DeleteUserURLs помечает ссылки посетителя удаленными.
+func (s *Server) DeleteUserURLs(
+	ctx context.Context,
+	req *pb.DeleteUserURLsRequest,
+) (*pb.DeleteUserURLsResponse, error) {
+	visitorUUID, err := requireVisitorUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.GetShortIds()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "empty request")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.requestTimeout)
+	defer cancel()
+
+	if _, delErr := s.urlService.MarkAsDeleted(ctx, req.GetShortIds(), visitorUUID); delErr != nil {
+		return nil, serviceError(delErr)
+	}
+	return &pb.DeleteUserURLsResponse{}, nil
+}
+
+// shortURL формирует полный короткий URL на основе идентификатора.
+func (s *Server) shortURL(shortID string) string {
+	return s.baseURL + "/" + shortID
+}
+
+// requireVisitorUUID возвращает UUID посетителя, установленный AuthInterceptor.
+func requireVisitorUUID(ctx context.Context) (string, error) {
+	visitorUUID, ok := VisitorUUIDFromContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "visitor token required")
+	}
+	return visitorUUID, nil
+}
+
+// serviceError преобразует ошибку сервисного слоя в gRPC статус.
+func serviceError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrRecordNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidArgument):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		return newInternalError(err)
+	}
+}
+
+// internalErrorMessage сообщение, которое получает клиент вместо подробностей внутренней ошибки.
+const internalErrorMessage = "internal error"
+
+// internalError внутренняя ошибка: клиент получает codes.Internal с фиксированным сообщением,
+// а исходная ошибка доступна перехватчикам через Error, поэтому LoggingInterceptor записывает ее в лог.
+type internalError struct {
+	err error
+}
+
+// newInternalError оборачивает err во внутреннюю ошибку, подробности которой не передаются клиенту.
+func newInternalError(err error) error {
+	return &internalError{err: err}
+}
+
+// Error возвращает текст исходной ошибки.
+func (e *internalError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap возвращает исходную ошибку.
+func (e *internalError) Unwrap() error {
+	return e.err
+}
+
+// GRPCStatus возвращает статус, который получит клиент.
+func (e *internalError) GRPCStatus() *status.Status {
+	return status.New(codes.Internal, internalErrorMessage)
+}
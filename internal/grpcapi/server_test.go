@@ -0,0 +1,296 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/grpcapi/pb"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/fsdevblog/shorturl/internal/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	testBaseURL   = "http://short.test"
+	testJWTSecret = "test_secret"
+)
+
+// startTestServer запускает gRPC сервер поверх in-memory сервисов и возвращает клиент к нему.
+func startTestServer(t *testing.T) pb.ShortenerServiceClient {
+	t.Helper()
+
+	svc, err := services.Factory(db.NewMemStorage(), services.ServiceTypeInMemory)
+	require.NoError(t, err)
+	return startServer(t, svc)
+}
+
+// startServer запускает gRPC сервер поверх переданных сервисов и возвращает клиент к нему.
+func startServer(t *testing.T, svc *services.Services, opts ...func(*Options)) pb.ShortenerServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	srv := New(svc.URLService, append([]func(*Options){func(o *Options) {
+		o.BaseURL = testBaseURL
+		o.JWTSecret = []byte(testJWTSecret)
+	}}, opts...)...)
+	go func() { _ = srv.Serve(listener) }()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+		srv.Stop()
+	})
+	return pb.NewShortenerServiceClient(conn)
+}
+
+// withToken добавляет токен посетителя в исходящие метаданные.
+func withToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, AuthorizationMetadataKey, bearerPrefix+token)
+}
+
+// shortID извлекает короткий идентификатор из короткого URL.
+func shortID(shortURL string) string {
+	return shortURL[len(testBaseURL)+1:]
+}
+
+func TestServer_ShortenIssuesToken(t *testing.T) {
+	client := startTestServer(t)
+
+	var header metadata.MD
+	resp, err := client.Shorten(t.Context(), &pb.ShortenRequest{Url: "https://example.com/a"}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.True(t, resp.GetCreated())
+	assert.Contains(t, resp.GetShortUrl(), testBaseURL+"/")
+
+	authValues := header.Get(AuthorizationMetadataKey)
+	require.Len(t, authValues, 1)
+	token := authValues[0][len(bearerPrefix):]
+	_, validateErr := tokens.ValidateVisitorJWT(token, []byte(testJWTSecret))
+	require.NoError(t, validateErr)
+
+	// Повторное сокращение с тем же токеном возвращает ту же ссылку и не выдает новый токен.
+	header = nil
+	again, err := client.Shorten(withToken(t.Context(), token),
+		&pb.ShortenRequest{Url: "https://example.com/a"}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.False(t, again.GetCreated())
+	assert.Equal(t, resp.GetShortUrl(), again.GetShortUrl())
+	assert.Empty(t, header.Get(AuthorizationMetadataKey))
+
+	list, err := client.ListUserURLs(withToken(t.Context(), token), &pb.ListUserURLsRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.GetUrls(), 1)
+	assert.Equal(t, "https://example.com/a", list.GetUrls()[0].GetOriginalUrl())
+}
+
+func TestServer_Auth(t *testing.T) {
+	client := startTestServer(t)
+
+	invalid, err := tokens.GenerateVisitorJWT("visitor", VisitorJWTExpireDuration, []byte("other secret"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{name: "no token", ctx: t.Context()},
+		{name: "invalid signature", ctx: withToken(t.Context(), invalid)},
+		{name: "garbage", ctx: withToken(t.Context(), "garbage")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, listErr := client.ListUserURLs(tt.ctx, &pb.ListUserURLsRequest{})
+			assert.Equal(t, codes.Unauthenticated, status.Code(listErr))
+
+			_, delErr := client.DeleteUserURLs(tt.ctx, &pb.DeleteUserURLsRequest{ShortIds: []string{"abcdefgh"}})
+			assert.Equal(t, codes.Unauthenticated, status.Code(delErr))
+
+			// Resolve публичный.
+			_, resolveErr := client.Resolve(tt.ctx, &pb.ResolveRequest{ShortId: "abcdefgh"})
+			assert.Equal(t, codes.NotFound, status.Code(resolveErr))
+		})
+	}
+}
+
+func TestServer_ShortenInvalidURL(t *testing.T) {
+	client := startTestServer(t)
+
+	_, err := client.Shorten(t.Context(), &pb.ShortenRequest{Url: "not a url"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.BatchShorten(t.Context(), &pb.BatchShortenRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.BatchShorten(t.Context(), &pb.BatchShortenRequest{Items: []*pb.BatchShortenItem{
+		{CorrelationId: "1", OriginalUrl: "https://example.com"},
+		{CorrelationId: "2", OriginalUrl: "ftp://example.com"},
+	}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_BatchResolveDelete(t *testing.T) {
+	client := startTestServer(t)
+
+	token, err := tokens.GenerateVisitorJWT("5d0f3b8e-0a51-4d7c-9b7e-3c4c1f1f9a01",
+		VisitorJWTExpireDuration, []byte(testJWTSecret))
+	require.NoError(t, err)
+	ctx := withToken(t.Context(), token)
+
+	_, err = client.Shorten(ctx, &pb.ShortenRequest{Url: "https://example.com/1"})
+	require.NoError(t, err)
+
+	batch, err := client.BatchShorten(ctx, &pb.BatchShortenRequest{Items: []*pb.BatchShortenItem{
+		{CorrelationId: "first", OriginalUrl: "https://example.com/1"},
+		{CorrelationId: "second", OriginalUrl: "https://example.com/2"},
+	}})
+	require.NoError(t, err)
+	require.Len(t, batch.GetItems(), 2)
+	assert.Equal(t, "first", batch.GetItems()[0].GetCorrelationId())
+	assert.False(t, batch.GetItems()[0].GetCreated())
+	assert.Equal(t, "second", batch.GetItems()[1].GetCorrelationId())
+	assert.True(t, batch.GetItems()[1].GetCreated())
+
+	id := shortID(batch.GetItems()[1].GetShortUrl())
+	resolved, err := client.Resolve(t.Context(), &pb.ResolveRequest{ShortId: id})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/2", resolved.GetOriginalUrl())
+
+	_, err = client.DeleteUserURLs(ctx, &pb.DeleteUserURLsRequest{ShortIds: []string{id}})
+	require.NoError(t, err)
+
+	_, err = client.Resolve(t.Context(), &pb.ResolveRequest{ShortId: id})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = client.Resolve(t.Context(), &pb.ResolveRequest{ShortId: "short"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestServer_Banned(t *testing.T) {
+	svc, err := services.Factory(db.NewMemStorage(), services.ServiceTypeInMemory)
+	require.NoError(t, err)
+	client := startServer(t, svc, func(o *Options) {
+		o.Bans = svc.AdminService
+	})
+
+	var header metadata.MD
+	resp, err := client.Shorten(t.Context(), &pb.ShortenRequest{Url: "https://example.com/a"}, grpc.Header(&header))
+	require.NoError(t, err)
+	token := header.Get(AuthorizationMetadataKey)[0][len(bearerPrefix):]
+	claims, err := tokens.ValidateVisitorJWT(token, []byte(testJWTSecret))
+	require.NoError(t, err)
+	visitorUUID := claims.Claims.(*tokens.VisitorClaims).UUID //nolint:errcheck
+
+	_, err = svc.AdminService.BanVisitor(t.Context(), "00000000-0000-0000-0000-000000000001", visitorUUID,
+		"spam", false)
+	require.NoError(t, err)
+	ctx := withToken(t.Context(), token)
+
+	_, shortenErr := client.Shorten(ctx, &pb.ShortenRequest{Url: "https://example.com/b"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(shortenErr))
+
+	_, batchErr := client.BatchShorten(ctx, &pb.BatchShortenRequest{
+		Items: []*pb.BatchShortenItem{{CorrelationId: "1", OriginalUrl: "https://example.com/c"}},
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(batchErr))
+
+	_, delErr := client.DeleteUserURLs(ctx, &pb.DeleteUserURLsRequest{ShortIds: []string{shortID(resp.GetShortUrl())}})
+	assert.Equal(t, codes.PermissionDenied, status.Code(delErr))
+
+	// Чтение и переходы остаются доступны.
+	list, err := client.ListUserURLs(ctx, &pb.ListUserURLsRequest{})
+	require.NoError(t, err)
+	assert.Len(t, list.GetUrls(), 1)
+	_, err = client.Resolve(ctx, &pb.ResolveRequest{ShortId: shortID(resp.GetShortUrl())})
+	require.NoError(t, err)
+}
+
+func TestServer_RevokedToken(t *testing.T) {
+	svc, err := services.Factory(db.NewMemStorage(), services.ServiceTypeInMemory)
+	require.NoError(t, err)
+	client := startServer(t, svc, func(o *Options) {
+		o.Revocations = svc.TokenRevocationService
+	})
+
+	var header metadata.MD
+	_, err = client.Shorten(t.Context(), &pb.ShortenRequest{Url: "https://example.com/a"}, grpc.Header(&header))
+	require.NoError(t, err)
+	token := header.Get(AuthorizationMetadataKey)[0][len(bearerPrefix):]
+	parsed, err := tokens.ValidateVisitorJWT(token, []byte(testJWTSecret))
+	require.NoError(t, err)
+	claims := parsed.Claims.(*tokens.VisitorClaims) //nolint:errcheck
+	ctx := withToken(t.Context(), token)
+
+	_, err = client.ListUserURLs(ctx, &pb.ListUserURLsRequest{})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.TokenRevocationService.Revoke(t.Context(), claims.UUID, claims.SessionID))
+
+	_, listErr := client.ListUserURLs(ctx, &pb.ListUserURLsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(listErr))
+
+	// Отозванный токен не принимается: Shorten выдает нового посетителя.
+	header = nil
+	_, err = client.Shorten(ctx, &pb.ShortenRequest{Url: "https://example.com/b"}, grpc.Header(&header))
+	require.NoError(t, err)
+	require.Len(t, header.Get(AuthorizationMetadataKey), 1)
+}
+
+func TestServiceError_HidesInternalDetails(t *testing.T) {
+	leaked := errors.New("dial tcp 10.0.0.5:5432: password authentication failed")
+	core, logs := observer.New(zap.InfoLevel)
+	interceptor := LoggingInterceptor(zap.New(core))
+
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{name: "internal", err: leaked, wantCode: codes.Internal, wantMessage: internalErrorMessage},
+		{
+			name:        "wrapped internal",
+			err:         fmt.Errorf("%w: %s", services.ErrUnknown, leaked.Error()),
+			wantCode:    codes.Internal,
+			wantMessage: internalErrorMessage,
+		},
+		{name: "not found", err: services.ErrRecordNotFound, wantCode: codes.NotFound,
+			wantMessage: services.ErrRecordNotFound.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := interceptor(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: "/test"},
+				func(context.Context, any) (any, error) { return nil, serviceError(tt.err) })
+
+			st := status.Convert(err)
+			assert.Equal(t, tt.wantCode, st.Code())
+			assert.Equal(t, tt.wantMessage, st.Message())
+			if tt.wantCode == codes.Internal {
+				assert.NotContains(t, st.Message(), "password")
+				// Подробности остаются в логе.
+				entries := logs.TakeAll()
+				require.Len(t, entries, 1)
+				assert.Contains(t, entries[0].ContextMap()["error"], "password authentication failed")
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+// Package metrics содержит Prometheus метрики приложения.
+//
+// Все метрики регистрируются в собственном реестре, отдаваемом через Metrics.Handler,
+// поэтому несколько экземпляров (например, в тестах) не конфликтуют друг с другом.
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/bmeta"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultNamespace префикс имен метрик по умолчанию.
+const DefaultNamespace = "shorturl"
+
+// Значения метки status для операций репозитория.
+const (
+	repoStatusOK    = "ok"
+	repoStatusError = "error"
+)
+
+// Options опции метрик.
+type Options struct {
+	Namespace string          // Префикс имен метрик
+	BuildMeta bmeta.BuildMeta // Информация о сборке для метрики build_info
+}
+
+// Metrics набор метрик приложения.
+type Metrics struct {
+	namespace string
+	registry  *prometheus.Registry
+
+	httpRequests    *prometheus.CounterVec
+	httpDuration    *prometheus.HistogramVec
+	redirects       *prometheus.CounterVec
+	createConflicts prometheus.Counter
+	batchSize       prometheus.Histogram
+	repoDuration    *prometheus.HistogramVec
+	webhookDropped  prometheus.Counter
+}
+
+// New создает и регистрирует метрики приложения.
+//
+// Параметры:
+//   - opts: функции для настройки опций
+//
+// Возвращает:
+//   - *Metrics: набор метрик
+func New(opts ...func(*Options)) *Metrics {
+	options := Options{
+		Namespace: DefaultNamespace,
+		BuildMeta: bmeta.New("", "", ""),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	ns := options.Namespace
+
+	m := &Metrics{
+		namespace: ns,
+		registry:  prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Количество HTTP запросов по маршруту и статусу ответа.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Время обработки HTTP запросов по маршруту и статусу ответа.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		redirects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "redirects_total",
+			Help:      "Количество переходов по коротким ссылкам (result: hit или miss).",
+		}, []string{"result"}),
+		createConflicts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "create_conflicts_total",
+			Help:      "Количество попыток сократить уже сокращенный URL.",
+		}),
+		batchSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: ns,
+			Name:      "batch_size",
+			Help:      "Размер пакетов при пакетном создании ссылок.",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 8), //nolint:mnd
+		}),
+		repoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns,
+			Subsystem: "repository",
+			Name:      "operation_duration_seconds",
+			Help:      "Время выполнения операций репозитория по хранилищу, операции и результату.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"backend", "operation", "status"}),
+		webhookDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: "webhook",
+			Name:      "events_dropped_total",
+			Help:      "Количество событий, отброшенных из-за переполнения очереди вебхуков.",
+		}),
+	}
+
+	buildInfo := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: ns,
+		Name:      "build_info",
+		Help:      "Информация о сборке приложения.",
+		ConstLabels: prometheus.Labels{
+			"version": options.BuildMeta.Version,
+			"date":    options.BuildMeta.Date,
+			"commit":  options.BuildMeta.Commit,
+		},
+	})
+	buildInfo.Set(1)
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		buildInfo,
+		m.httpRequests,
+		m.httpDuration,
+		m.redirects,
+		m.createConflicts,
+		m.batchSize,
+		m.repoDuration,
+		m.webhookDropped,
+	)
+	return m
+}
+
+// Handler возвращает HTTP обработчик, отдающий метрики в текстовом формате Prometheus.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Registry возвращает реестр метрик.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// ObserveHTTPRequest учитывает обработанный HTTP запрос.
+//
+// Параметры:
+//   - method: HTTP метод
+//   - route: шаблон маршрута gin (например, /api/:shortID)
+//   - status: статус ответа
+//   - d: время обработки
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, d time.Duration) {
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpDuration.WithLabelValues(method, route, code).Observe(d.Seconds())
+}
+
+// RedirectHit учитывает переход по существующей ссылке.
+func (m *Metrics) RedirectHit() {
+	m.redirects.WithLabelValues("hit").Inc()
+}
+
+// RedirectMiss учитывает переход по несуществующей, удаленной или отключенной ссылке.
+func (m *Metrics) RedirectMiss() {
+	m.redirects.WithLabelValues("miss").Inc()
+}
+
+// CreateConflict учитывает попытку повторно сократить URL.
+func (m *Metrics) CreateConflict() {
+	m.createConflicts.Inc()
+}
+
+// ObserveBatchSize учитывает размер пакета при пакетном создании ссылок.
+func (m *Metrics) ObserveBatchSize(n int) {
+	m.batchSize.Observe(float64(n))
+}
+
+// ObserveRepoOp учитывает время выполнения операции репозитория.
+//
+// Параметры:
+//   - backend: тип хранилища (postgres, inMemory)
+//   - op: имя операции
+//   - d: время выполнения
+//   - err: ошибка операции
+func (m *Metrics) ObserveRepoOp(backend, op string, d time.Duration, err error) {
+	status := repoStatusOK
+	if err != nil {
+		status = repoStatusError
+	}
+	m.repoDuration.WithLabelValues(backend, op, status).Observe(d.Seconds())
+}
+
+// WebhookEventsDropped учитывает события, отброшенные из-за переполнения очереди вебхуков.
+func (m *Metrics) WebhookEventsDropped(n uint64) {
+	m.webhookDropped.Add(float64(n))
+}
+
+// RegisterStorage регистрирует метрики хранилища: статистику пула pgx или размер in-memory хранилища.
+//
+// Параметры:
+//   - conn: соединение с хранилищем (*pgxpool.Pool или *db.MemoryStorage)
+//
+// Возвращает:
+//   - error: ошибка регистрации или неизвестный тип соединения
+func (m *Metrics) RegisterStorage(conn any) error {
+	var c prometheus.Collector
+	switch s := conn.(type) {
+	case *pgxpool.Pool:
+		c = newPgxPoolCollector(m.namespace, s)
+	case interface{ Len() int }:
+		c = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: m.namespace,
+			Subsystem: "memstorage",
+			Name:      "records",
+			Help:      "Количество записей в in-memory хранилище.",
+		}, func() float64 { return float64(s.Len()) })
+	default:
+		return fmt.Errorf("register storage metrics: unknown connection type %T", conn)
+	}
+	if err := m.registry.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			return nil
+		}
+		return fmt.Errorf("register storage metrics: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/bmeta"
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	m := New(func(o *Options) {
+		o.BuildMeta = bmeta.New("v1.0.0", "", "abc123")
+	})
+
+	m.ObserveHTTPRequest(http.MethodGet, "/api/:shortID", http.StatusTemporaryRedirect, 10*time.Millisecond)
+	m.RedirectHit()
+	m.RedirectMiss()
+	m.RedirectMiss()
+	m.CreateConflict()
+	m.ObserveBatchSize(3)
+	m.WebhookEventsDropped(2)
+	m.ObserveRepoOp("inMemory", "Create", time.Millisecond, nil)
+	m.ObserveRepoOp("inMemory", "Create", time.Millisecond, errors.New("boom"))
+
+	store := db.NewMemStorage()
+	require.NoError(t, m.RegisterStorage(store))
+	require.NoError(t, m.RegisterStorage(store), "повторная регистрация не должна быть ошибкой")
+	require.Error(t, m.RegisterStorage("unknown"))
+
+	assert.InDelta(t, 1, testutil.ToFloat64(m.redirects.WithLabelValues("hit")), 0)
+	assert.InDelta(t, 2, testutil.ToFloat64(m.redirects.WithLabelValues("miss")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.createConflicts), 0)
+	assert.InDelta(t, 2, testutil.ToFloat64(m.webhookDropped), 0)
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		`shorturl_build_info{commit="abc123",date="N/A",version="v1.0.0"} 1`,
+		`shorturl_http_requests_total{method="GET",route="/api/:shortID",status="307"} 1`,
+		`shorturl_http_request_duration_seconds_count{method="GET",route="/api/:shortID",status="307"} 1`,
+		`shorturl_batch_size_count 1`,
+		`shorturl_repository_operation_duration_seconds_count{backend="inMemory",operation="Create",status="ok"} 1`,
+		`shorturl_repository_operation_duration_seconds_count{backend="inMemory",operation="Create",status="error"} 1`,
+		`shorturl_memstorage_records 0`,
+		`go_goroutines`,
+	} {
+		assert.True(t, strings.Contains(body, want), "metrics output should contain %q", want)
+	}
+}
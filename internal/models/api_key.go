@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// APIKey структура модели ключа API посетителя.
+// Сам ключ не хранится: по открытому префиксу ключ находится, а по хешу проверяется.
+type APIKey struct {
+	ID          string     `json:"id"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	VisitorUUID string     `json:"visitorUUID"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"` // Открытая часть ключа, уникальна
+	Hash        string     `json:"hash"`   // SHA-256 всего ключа в hex
+	RevokedAt   *time.Time `json:"revokedAt"`
+}
+
+// Revoked проверяет, отозван ли ключ.
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// DeletionJobStatus состояние задачи удаления ссылок.
+type DeletionJobStatus string
+
+// Состояния задачи удаления ссылок.
+const (
+	DeletionJobPending   DeletionJobStatus = "pending"   // Задача в очереди
+	DeletionJobRunning   DeletionJobStatus = "running"   // Задача выполняется
+	DeletionJobCompleted DeletionJobStatus = "completed" // Задача выполнена
+	DeletionJobFailed    DeletionJobStatus = "failed"    // Задача завершилась ошибкой
+)
+
+// DeletionJob структура модели задачи фонового удаления ссылок посетителя.
+type DeletionJob struct {
+	ID          string            `json:"id"`
+	VisitorUUID string            `json:"visitorUUID"`
+	Status      DeletionJobStatus `json:"status"`
+	Requested   int               `json:"requested"` // Количество уникальных идентификаторов в запросе
+	Deleted     int               `json:"deleted"`   // Количество ссылок, помеченных удаленными
+	Error       string            `json:"error"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	FinishedAt  *time.Time        `json:"finishedAt"`
+}
+
+// Finished проверяет, завершена ли задача.
+func (j *DeletionJob) Finished() bool {
+	return j.Status == DeletionJobCompleted || j.Status == DeletionJobFailed
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// IdempotencyRecord сохраненный результат запроса с заголовком Idempotency-Key.
+// Пока запрос обрабатывается, StatusCode равен 0.
+type IdempotencyRecord struct {
+	Key         string    `json:"key"`
+	VisitorUUID string    `json:"visitorUUID"`
+	Fingerprint string    `json:"fingerprint"` // Отпечаток метода, маршрута и тела запроса
+	StatusCode  int       `json:"statusCode"`
+	ContentType string    `json:"contentType"`
+	Body        []byte    `json:"body"`
+	CreatedAt   time.Time `json:"createdAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// InProgress сообщает, что запрос с этим ключом еще обрабатывается.
+func (r *IdempotencyRecord) InProgress() bool {
+	return r.StatusCode == 0
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// VisitorBan блокировка посетителя администратором.
+// Заблокированный посетитель может переходить по ссылкам, но не может создавать и изменять их.
+type VisitorBan struct {
+	VisitorUUID string    `json:"visitorUUID"`
+	Reason      string    `json:"reason"`
+	BannedBy    string    `json:"bannedBy"` // UUID администратора
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// AuditAction действие администратора, попадающее в журнал аудита.
+type AuditAction string
+
+// Действия администраторов.
+const (
+	AuditURLDisabled     AuditAction = "url.disabled"     // Ссылка отключена
+	AuditURLEnabled      AuditAction = "url.enabled"      // Ссылка снова включена
+	AuditVisitorBanned   AuditAction = "visitor.banned"   // Посетитель заблокирован
+	AuditVisitorUnbanned AuditAction = "visitor.unbanned" // Блокировка посетителя снята
+)
+
+// AuditEntry запись журнала аудита действий администраторов.
+type AuditEntry struct {
+	ID        string      `json:"id"`
+	CreatedAt time.Time   `json:"createdAt"`
+	ActorUUID string      `json:"actorUUID"` // UUID администратора
+	Action    AuditAction `json:"action"`
+	Target    string      `json:"target"` // Короткий идентификатор ссылки или UUID посетителя
+	Reason    string      `json:"reason"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// OIDCIdentity связывает пользователя внешнего провайдера OpenID Connect с UUID посетителя.
+// Пара Issuer и Subject уникальна и не меняется у провайдера, поэтому пользователь
+// получает один и тот же UUID на любом устройстве.
+type OIDCIdentity struct {
+	Issuer    string    `json:"issuer"`  // Идентификатор провайдера (iss)
+	Subject   string    `json:"subject"` // Идентификатор пользователя у провайдера (sub)
+	UserID    string    `json:"userId"`  // UUID посетителя, которому принадлежат ссылки пользователя
+	Email     string    `json:"email"`   // Адрес из ID токена при первом входе, справочно
+	CreatedAt time.Time `json:"createdAt"`
+}
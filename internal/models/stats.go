@@ -0,0 +1,7 @@
+package models
+
+// Stats агрегированная статистика сервиса.
+type Stats struct {
+	URLs  int `json:"urls"`  // Количество неудаленных сокращенных URL
+	Users int `json:"users"` // Количество уникальных посетителей, создававших ссылки
+}
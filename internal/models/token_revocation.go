@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// RevokedToken отозванный токен посетителя. После ExpiresAt токен не принимается и без отзыва,
+// поэтому запись можно удалить.
+type RevokedToken struct {
+	JTI         string    `json:"jti"` // Идентификатор токена или сессии (sid)
+	VisitorUUID string    `json:"visitorUUID"`
+	CreatedAt   time.Time `json:"createdAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// SessionRevocation отзыв всех сессий посетителя: токены, выпущенные раньше RevokedBefore, не принимаются.
+// После ExpiresAt таких токенов не остается, поэтому запись можно удалить.
+type SessionRevocation struct {
+	VisitorUUID   string    `json:"visitorUUID"`
+	RevokedBefore time.Time `json:"revokedBefore"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+}
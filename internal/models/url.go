@@ -5,6 +5,9 @@ import "time"
 // ShortIdentifierLength длина короткой ссылки.
 const ShortIdentifierLength = 8
 
+// MaxAliasLength максимальная длина пользовательского короткого идентификатора (алиаса).
+const MaxAliasLength = 64
+
 // URL структура модели хранения URL.
 type URL struct {
 	ID              uint       `json:"ID"`
@@ -14,4 +17,36 @@ type URL struct {
 	URL             string     `json:"url"`
 	ShortIdentifier string     `json:"shortIdentifier"`
 	VisitorUUID     string     `json:"visitorUUID"`
+	Tags            []string   `json:"tags,omitempty"`
+	// Рабочее пространство ссылки. Пусто - личная ссылка посетителя VisitorUUID,
+	// иначе VisitorUUID только автор, а распоряжаются ссылкой участники пространства.
+	WorkspaceID string `json:"workspaceID,omitempty"`
+	// Момент отключения ссылки администратором. nil - ссылка не отключена.
+	DisabledAt     *time.Time `json:"disabledAt,omitempty"`
+	DisabledReason string     `json:"disabledReason,omitempty"` // Причина отключения, показывается при переходе
+	// Ссылка отключена по требованию закона: переход отвечает 451 вместо 410.
+	DisabledLegal bool `json:"disabledLegal,omitempty"`
+}
+
+// URLState состояние короткой ссылки.
+type URLState string
+
+// Состояния коротких ссылок.
+const (
+	URLStateActive   URLState = "active"   // Ссылка действует
+	URLStateDeleted  URLState = "deleted"  // Ссылка удалена владельцем
+	URLStateDisabled URLState = "disabled" // Ссылка отключена администратором
+	URLStateExpired  URLState = "expired"  // Истек срок действия ссылки (пока ссылки создаются бессрочными)
+	URLStateUnknown  URLState = "unknown"  // Ссылка не найдена
+)
+
+// State возвращает текущее состояние ссылки. Удаление владельцем важнее отключения.
+func (u *URL) State() URLState {
+	if u.DeletedAt != nil {
+		return URLStateDeleted
+	}
+	if u.DisabledAt != nil {
+		return URLStateDisabled
+	}
+	return URLStateActive
 }
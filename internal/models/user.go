@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// User структура модели зарегистрированного пользователя.
+// ID пользователя используется как UUID посетителя: ссылки аккаунта принадлежат этому UUID.
+type User struct {
+	ID           string    `json:"id"`
+	CreatedAt    time.Time `json:"createdAt"`
+	Email        string    `json:"email"`        // Адрес в нижнем регистре, уникален
+	PasswordHash string    `json:"passwordHash"` // bcrypt хеш пароля
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook структура модели подписки посетителя на события ссылок.
+type Webhook struct {
+	ID          string    `json:"id"`
+	CreatedAt   time.Time `json:"createdAt"`
+	VisitorUUID string    `json:"visitorUUID"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"secret"`
+	Events      []string  `json:"events"`
+}
+
+// HasEvent проверяет, подписан ли вебхук на указанный тип события.
+func (w *Webhook) HasEvent(eventType string) bool {
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery структура модели одной попытки доставки события на вебхук.
+type WebhookDelivery struct {
+	ID         string          `json:"id"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	WebhookID  string          `json:"webhookID"`
+	EventID    string          `json:"eventID"`
+	EventType  string          `json:"eventType"`
+	Attempt    int             `json:"attempt"`
+	StatusCode int             `json:"statusCode"`
+	Error      string          `json:"error"`
+	Success    bool            `json:"success"`
+	DeadLetter bool            `json:"deadLetter"` // последняя неудачная попытка, доставка прекращена
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// WebhookJob структура модели ожидающей доставки события на вебхук. Запись существует,
+// пока событие не доставлено или попытки не исчерпаны, поэтому повторы переживают перезапуск.
+type WebhookJob struct {
+	ID            string          `json:"id"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	WebhookID     string          `json:"webhookID"`
+	EventID       string          `json:"eventID"`
+	EventType     string          `json:"eventType"`
+	Attempt       int             `json:"attempt"`       // количество выполненных попыток
+	NextAttemptAt time.Time       `json:"nextAttemptAt"` // время следующей попытки
+	Payload       json.RawMessage `json:"payload"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Workspace рабочее пространство команды. Ссылки пространства принадлежат ему, а не создавшему их
+// посетителю, поэтому их могут изменять и удалять все участники с ролью editor или owner.
+type Workspace struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedBy string    `json:"createdBy"` // UUID посетителя, создавшего пространство
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WorkspaceRole роль участника рабочего пространства.
+type WorkspaceRole string
+
+// Роли участников рабочего пространства.
+const (
+	WorkspaceRoleOwner  WorkspaceRole = "owner"  // Управляет участниками и ссылками
+	WorkspaceRoleEditor WorkspaceRole = "editor" // Создает, изменяет и удаляет ссылки
+	WorkspaceRoleViewer WorkspaceRole = "viewer" // Только просматривает ссылки
+)
+
+// Valid проверяет, что роль известна.
+func (r WorkspaceRole) Valid() bool {
+	return r == WorkspaceRoleOwner || r == WorkspaceRoleEditor || r == WorkspaceRoleViewer
+}
+
+// CanEditURLs проверяет, может ли участник с ролью создавать, изменять и удалять ссылки.
+func (r WorkspaceRole) CanEditURLs() bool {
+	return r == WorkspaceRoleOwner || r == WorkspaceRoleEditor
+}
+
+// CanManageMembers проверяет, может ли участник с ролью добавлять и удалять участников.
+func (r WorkspaceRole) CanManageMembers() bool {
+	return r == WorkspaceRoleOwner
+}
+
+// WorkspaceMember участник рабочего пространства.
+type WorkspaceMember struct {
+	WorkspaceID string        `json:"workspaceID"`
+	VisitorUUID string        `json:"visitorUUID"`
+	Role        WorkspaceRole `json:"role"`
+	CreatedAt   time.Time     `json:"createdAt"`
+}
+
+// WorkspaceMembership рабочее пространство вместе с ролью в нем посетителя.
+type WorkspaceMembership struct {
+	Workspace Workspace     `json:"workspace"`
+	Role      WorkspaceRole `json:"role"`
+}
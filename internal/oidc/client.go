@@ -0,0 +1,269 @@
+package oidc
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Значения по умолчанию клиента.
+const (
+	DefaultRequestTimeout = 10 * time.Second // Таймаут запросов к провайдеру
+	DefaultClockSkew      = time.Minute      // Допустимое расхождение часов с провайдером
+)
+
+// DefaultScopes области доступа по умолчанию. Область openid добавляется всегда.
+var DefaultScopes = []string{"openid", "email", "profile"}
+
+// supportedAlgorithms алгоритмы подписи ID токена. Симметричные алгоритмы не принимаются:
+// иначе токен, подписанный секретом клиента или открытым ключом провайдера как секретом, прошел бы проверку.
+var supportedAlgorithms = []string{"RS256", "ES256", "EdDSA"}
+
+// Config параметры клиента, зарегистрированного у провайдера.
+type Config struct {
+	Issuer       string   // Идентификатор провайдера (URL), документ обнаружения загружается относительно него
+	ClientID     string   // Идентификатор клиента
+	ClientSecret string   // Секрет клиента. Пустой для публичного клиента, который защищен только PKCE
+	RedirectURL  string   // Адрес возврата после входа, зарегистрированный у провайдера
+	Scopes       []string // Области доступа. Пустой список - DefaultScopes
+}
+
+// Options опции клиента.
+type Options struct {
+	HTTPClient          *http.Client     // HTTP клиент для запросов к провайдеру
+	JWKSRefreshInterval time.Duration    // Минимальный интервал между загрузками JWKS
+	ClockSkew           time.Duration    // Допустимое расхождение часов при проверке exp и iat
+	Now                 func() time.Time // Источник текущего времени
+}
+
+// IDTokenClaims утверждения ID токена.
+type IDTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce           string `json:"nonce"`
+	Email           string `json:"email"`
+	EmailVerified   bool   `json:"email_verified"`
+	AuthorizedParty string `json:"azp"`
+}
+
+// Client клиент OpenID Connect для потока authorization code с PKCE.
+// Метаданные провайдера загружаются при первом обращении и кешируются,
+// поэтому недоступность провайдера при запуске не мешает работе сервиса.
+type Client struct {
+	cfg  Config
+	opts Options
+
+	mu   sync.Mutex
+	meta *ProviderMetadata
+	keys *remoteKeySet
+}
+
+// NewClient создает клиента OpenID Connect.
+//
+// Параметры:
+//   - cfg: параметры клиента
+//   - opts: функции для настройки опций
+//
+// Возвращает:
+//   - *Client: клиент
+func NewClient(cfg Config, opts ...func(*Options)) *Client {
+	options := Options{
+		HTTPClient:          &http.Client{Timeout: DefaultRequestTimeout},
+		JWKSRefreshInterval: DefaultJWKSRefreshInterval,
+		ClockSkew:           DefaultClockSkew,
+		Now:                 time.Now,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = slices.Clone(DefaultScopes)
+	}
+	if !slices.Contains(cfg.Scopes, "openid") {
+		cfg.Scopes = append([]string{"openid"}, cfg.Scopes...)
+	}
+	return &Client{cfg: cfg, opts: options}
+}
+
+// Issuer возвращает идентификатор провайдера.
+func (c *Client) Issuer() string {
+	return c.cfg.Issuer
+}
+
+// AuthCodeURL формирует адрес страницы входа провайдера.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - state: значение для защиты от CSRF, возвращается провайдером в адрес возврата
+//   - nonce: значение, которое провайдер включит в ID токен
+//   - verifier: code_verifier PKCE, провайдеру передается только его хеш
+//
+// Возвращает:
+//   - string: адрес страницы входа
+//   - error: ErrProviderUnavailable, если метаданные провайдера не загружены
+func (c *Client) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	meta, _, err := c.provider(ctx)
+	if err != nil {
+		return "", err
+	}
+	authURL, err := url.Parse(meta.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: authorization endpoint: %s", ErrProviderUnavailable, err.Error())
+	}
+
+	q := authURL.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", c.cfg.ClientID)
+	q.Set("redirect_uri", c.cfg.RedirectURL)
+	q.Set("scope", strings.Join(c.cfg.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", S256Challenge(verifier))
+	q.Set("code_challenge_method", "S256")
+	authURL.RawQuery = q.Encode()
+	return authURL.String(), nil
+}
+
+// tokenResponse ответ token endpoint.
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange обменивает код авторизации на токены и проверяет ID токен.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - code: код авторизации из адреса возврата
+//   - verifier: code_verifier, использованный в AuthCodeURL
+//   - nonce: nonce, использованный в AuthCodeURL
+//
+// Возвращает:
+//   - *IDTokenClaims: утверждения проверенного ID токена
+//   - error: ErrCodeRejected, ErrInvalidIDToken или ErrProviderUnavailable
+func (c *Client) Exchange(ctx context.Context, code, verifier, nonce string) (*IDTokenClaims, error) {
+	meta, _, err := c.provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.cfg.RedirectURL},
+		"code_verifier": {verifier},
+		"client_id":     {c.cfg.ClientID},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: token endpoint: %s", ErrProviderUnavailable, err.Error())
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.cfg.ClientSecret != "" {
+		// RFC 6749, раздел 2.3.1: идентификатор и секрет кодируются как application/x-www-form-urlencoded.
+		req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+	}
+
+	res, err := c.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: token endpoint: %s", ErrProviderUnavailable, err.Error())
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	var body tokenResponse
+	decodeErr := json.NewDecoder(io.LimitReader(res.Body, maxResponseSize)).Decode(&body)
+	switch {
+	case res.StatusCode == http.StatusBadRequest && body.Error != "":
+		// invalid_grant, invalid_request и т.п. - код использован, истек или не совпал code_verifier.
+		return nil, fmt.Errorf("%w: %s: %s", ErrCodeRejected, body.Error, body.ErrorDescription)
+	case res.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%w: token endpoint: unexpected status %d", ErrProviderUnavailable, res.StatusCode)
+	case decodeErr != nil:
+		return nil, fmt.Errorf("%w: token endpoint: %s", ErrProviderUnavailable, decodeErr.Error())
+	case body.IDToken == "":
+		return nil, fmt.Errorf("%w: token response has no id_token", ErrInvalidIDToken)
+	}
+	return c.VerifyIDToken(ctx, body.IDToken, nonce)
+}
+
+// VerifyIDToken проверяет подпись ID токена ключом из JWKS провайдера и его утверждения:
+// iss, aud (и azp при нескольких получателях), exp, iat и nonce.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - rawIDToken: ID токен
+//   - nonce: ожидаемое значение nonce
+//
+// Возвращает:
+//   - *IDTokenClaims: утверждения токена
+//   - error: ErrInvalidIDToken или ErrProviderUnavailable, если не удалось загрузить ключи
+func (c *Client) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*IDTokenClaims, error) {
+	_, keys, err := c.provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := new(IDTokenClaims)
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(t *jwt.Token) (any, error) {
+		kid, ok := t.Header["kid"].(string)
+		if !ok && t.Header["kid"] != nil {
+			return nil, errors.New("kid is not a string")
+		}
+		return keys.key(ctx, kid)
+	},
+		jwt.WithValidMethods(supportedAlgorithms),
+		jwt.WithIssuer(c.cfg.Issuer),
+		jwt.WithAudience(c.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(c.opts.ClockSkew),
+		jwt.WithTimeFunc(c.opts.Now),
+	)
+	if err != nil {
+		if errors.Is(err, ErrProviderUnavailable) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %s", ErrInvalidIDToken, err.Error())
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: sub is empty", ErrInvalidIDToken)
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 || nonce == "" {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != c.cfg.ClientID {
+		return nil, fmt.Errorf("%w: azp %q is not the client", ErrInvalidIDToken, claims.AuthorizedParty)
+	}
+	return claims, nil
+}
+
+// provider возвращает метаданные и ключи провайдера, загружая их при первом успешном обращении.
+func (c *Client) provider(ctx context.Context) (*ProviderMetadata, *remoteKeySet, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.meta != nil {
+		return c.meta, c.keys, nil
+	}
+	meta, err := Discover(ctx, c.opts.HTTPClient, c.cfg.Issuer)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.meta = meta
+	c.keys = newRemoteKeySet(c.opts.HTTPClient, meta.JWKSURI, c.opts.JWKSRefreshInterval, c.opts.Now)
+	return c.meta, c.keys, nil
+}
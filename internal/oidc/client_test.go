@@ -0,0 +1,267 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/oidc/oidctest"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testClientID    = "shorturl"
+	testRedirectURL = "https://short.example.com/api/oidc/callback"
+)
+
+// newTestClient создает клиента для провайдера.
+func newTestClient(p *oidctest.Provider, opts ...func(*Options)) *Client {
+	return NewClient(Config{
+		Issuer:      p.Issuer(),
+		ClientID:    testClientID,
+		RedirectURL: testRedirectURL,
+	}, opts...)
+}
+
+// login проходит вход у провайдера и возвращает код авторизации.
+func login(t *testing.T, c *Client, p *oidctest.Provider, state, nonce, verifier string) string {
+	t.Helper()
+	authURL, err := c.AuthCodeURL(context.Background(), state, nonce, verifier)
+	require.NoError(t, err)
+	callback, err := p.Authorize(authURL, "user-1", "alice@example.com")
+	require.NoError(t, err)
+	u, err := url.Parse(callback)
+	require.NoError(t, err)
+	assert.Equal(t, state, u.Query().Get("state"))
+	return u.Query().Get("code")
+}
+
+func TestClient_Flow(t *testing.T) {
+	ctx := context.Background()
+	p := oidctest.NewProvider(t, testClientID)
+	c := newTestClient(p)
+
+	verifier, err := RandomString()
+	require.NoError(t, err)
+	authURL, err := c.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	require.NoError(t, err)
+	q, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "openid email profile", q.Query().Get("scope"))
+	assert.Equal(t, S256Challenge(verifier), q.Query().Get("code_challenge"))
+	assert.NotContains(t, authURL, verifier)
+
+	code := login(t, c, p, "state-1", "nonce-1", verifier)
+	claims, err := c.Exchange(ctx, code, verifier, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.Equal(t, p.Issuer(), claims.Issuer)
+	assert.Equal(t, "alice@example.com", claims.Email)
+
+	// Код одноразовый.
+	_, err = c.Exchange(ctx, code, verifier, "nonce-1")
+	require.ErrorIs(t, err, ErrCodeRejected)
+}
+
+func TestClient_ExchangeRejected(t *testing.T) {
+	ctx := context.Background()
+	p := oidctest.NewProvider(t, testClientID)
+	c := newTestClient(p)
+
+	// Перехваченный код бесполезен без code_verifier.
+	code := login(t, c, p, "state", "nonce", "verifier-of-the-legitimate-client-0000000000")
+	_, err := c.Exchange(ctx, code, "verifier-of-the-attacker-000000000000000000000", "nonce")
+	require.ErrorIs(t, err, ErrCodeRejected)
+}
+
+func TestClient_VerifyIDToken(t *testing.T) {
+	now := time.Now()
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":   "user-1",
+			"aud":   testClientID,
+			"exp":   now.Add(time.Minute).Unix(),
+			"iat":   now.Unix(),
+			"nonce": "nonce",
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(p *oidctest.Provider, claims jwt.MapClaims)
+		nonce  string
+		sign   func(p *oidctest.Provider, claims jwt.MapClaims) string
+	}{
+		{
+			name:   "nonce mismatch",
+			modify: func(_ *oidctest.Provider, claims jwt.MapClaims) { claims["nonce"] = "other" },
+		},
+		{
+			name:   "empty expected nonce",
+			modify: func(_ *oidctest.Provider, claims jwt.MapClaims) { claims["nonce"] = "" },
+			nonce:  "-",
+		},
+		{
+			name:   "foreign audience",
+			modify: func(_ *oidctest.Provider, claims jwt.MapClaims) { claims["aud"] = "other-client" },
+		},
+		{
+			name: "multiple audiences without azp",
+			modify: func(_ *oidctest.Provider, claims jwt.MapClaims) {
+				claims["aud"] = []string{testClientID, "other-client"}
+			},
+		},
+		{
+			name:   "foreign issuer",
+			modify: func(_ *oidctest.Provider, claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" },
+		},
+		{
+			name: "expired",
+			modify: func(_ *oidctest.Provider, claims jwt.MapClaims) {
+				claims["exp"] = now.Add(-DefaultClockSkew - time.Minute).Unix()
+			},
+		},
+		{
+			name:   "without exp",
+			modify: func(_ *oidctest.Provider, claims jwt.MapClaims) { delete(claims, "exp") },
+		},
+		{
+			name:   "without sub",
+			modify: func(_ *oidctest.Provider, claims jwt.MapClaims) { delete(claims, "sub") },
+		},
+		{
+			name: "hs256 signed with client id",
+			sign: func(_ *oidctest.Provider, claims jwt.MapClaims) string {
+				signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testClientID))
+				require.NoError(t, err)
+				return signed
+			},
+		},
+		{
+			name: "alg none",
+			sign: func(_ *oidctest.Provider, claims jwt.MapClaims) string {
+				signed, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims).
+					SignedString(jwt.UnsafeAllowNoneSignatureType)
+				require.NoError(t, err)
+				return signed
+			},
+		},
+		{
+			name: "signed by another provider",
+			sign: func(_ *oidctest.Provider, claims jwt.MapClaims) string {
+				return oidctest.NewProvider(t, testClientID).SignIDToken(claims)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := oidctest.NewProvider(t, testClientID)
+			claims := validClaims()
+			claims["iss"] = p.Issuer()
+			if tt.modify != nil {
+				tt.modify(p, claims)
+			}
+			sign := p.SignIDToken
+			if tt.sign != nil {
+				sign = func(claims jwt.MapClaims) string { return tt.sign(p, claims) }
+			}
+			nonce := "nonce"
+			if tt.nonce == "-" {
+				nonce = ""
+			}
+
+			c := newTestClient(p)
+			_, err := c.VerifyIDToken(context.Background(), sign(claims), nonce)
+			require.ErrorIs(t, err, ErrInvalidIDToken)
+		})
+	}
+}
+
+func TestClient_KeyRotation(t *testing.T) {
+	ctx := context.Background()
+	p := oidctest.NewProvider(t, testClientID)
+	c := newTestClient(p)
+
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   p.Issuer(),
+			"sub":   "user-1",
+			"aud":   testClientID,
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"iat":   time.Now().Unix(),
+			"nonce": "nonce",
+		}
+	}
+	_, err := c.VerifyIDToken(ctx, p.SignIDToken(claims()), "nonce")
+	require.NoError(t, err)
+	require.Equal(t, 1, p.JWKSHits())
+
+	// Токен нового ключа загружает JWKS заново, но не чаще интервала обновления.
+	p.RotateKey(t)
+	_, err = c.VerifyIDToken(ctx, p.SignIDToken(claims()), "nonce")
+	require.ErrorIs(t, err, ErrInvalidIDToken)
+	assert.Equal(t, 1, p.JWKSHits())
+
+	c = newTestClient(p, func(o *Options) { o.JWKSRefreshInterval = 0 })
+	_, err = c.VerifyIDToken(ctx, p.SignIDToken(claims()), "nonce")
+	require.NoError(t, err)
+	p.RotateKey(t)
+	_, err = c.VerifyIDToken(ctx, p.SignIDToken(claims()), "nonce")
+	require.NoError(t, err)
+	assert.Equal(t, 3, p.JWKSHits())
+}
+
+func TestDiscover(t *testing.T) {
+	tests := []struct {
+		name     string
+		document func(issuer string) string
+	}{
+		{
+			name: "issuer mismatch",
+			document: func(string) string {
+				return `{"issuer":"https://evil.example.com","authorization_endpoint":"a","token_endpoint":"t",` +
+					`"jwks_uri":"j"}`
+			},
+		},
+		{
+			name: "missing endpoints",
+			document: func(issuer string) string {
+				return `{"issuer":"` + issuer + `"}`
+			},
+		},
+		{
+			name: "no pkce s256",
+			document: func(issuer string) string {
+				return `{"issuer":"` + issuer + `","authorization_endpoint":"a","token_endpoint":"t",` +
+					`"jwks_uri":"j","code_challenge_methods_supported":["plain"]}`
+			},
+		},
+		{
+			name:     "not json",
+			document: func(string) string { return "<html>" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var issuer string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(tt.document(issuer)))
+			}))
+			defer srv.Close()
+			issuer = srv.URL
+
+			_, err := Discover(context.Background(), srv.Client(), issuer)
+			require.ErrorIs(t, err, ErrProviderUnavailable)
+		})
+	}
+
+	_, err := Discover(context.Background(), http.DefaultClient, "http://127.0.0.1:1")
+	require.ErrorIs(t, err, ErrProviderUnavailable)
+}
@@ -0,0 +1,79 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// discoveryPath путь документа обнаружения относительно issuer (OpenID Connect Discovery 1.0).
+const discoveryPath = "/.well-known/openid-configuration"
+
+// maxResponseSize ограничение размера ответов провайдера.
+const maxResponseSize = 1 << 20
+
+// ProviderMetadata метаданные провайдера из документа обнаружения.
+type ProviderMetadata struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	CodeChallengeMethods  []string `json:"code_challenge_methods_supported"`
+}
+
+// Discover загружает метаданные провайдера из {issuer}/.well-known/openid-configuration.
+// Issuer в документе должен совпадать с запрошенным, иначе токены другого провайдера
+// могли бы выдаваться за токены доверенного.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - client: HTTP клиент
+//   - issuer: идентификатор провайдера (URL)
+//
+// Возвращает:
+//   - *ProviderMetadata: метаданные провайдера
+//   - error: ErrProviderUnavailable при ошибке загрузки или некорректном документе
+func Discover(ctx context.Context, client *http.Client, issuer string) (*ProviderMetadata, error) {
+	var meta ProviderMetadata
+	if err := getJSON(ctx, client, strings.TrimSuffix(issuer, "/")+discoveryPath, &meta); err != nil {
+		return nil, fmt.Errorf("%w: discovery: %s", ErrProviderUnavailable, err.Error())
+	}
+	if meta.Issuer != issuer {
+		return nil, fmt.Errorf("%w: discovery: issuer %q does not match %q",
+			ErrProviderUnavailable, meta.Issuer, issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: discovery: required endpoints are missing", ErrProviderUnavailable)
+	}
+	// Провайдер, явно перечисливший методы PKCE без S256, не проверит code_verifier.
+	if len(meta.CodeChallengeMethods) > 0 && !slices.Contains(meta.CodeChallengeMethods, "S256") {
+		return nil, fmt.Errorf("%w: discovery: provider does not support PKCE S256", ErrProviderUnavailable)
+	}
+	return &meta, nil
+}
+
+// getJSON выполняет GET запрос и разбирает JSON ответ.
+func getJSON(ctx context.Context, client *http.Client, url string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("get %s: %w", url, err)
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("get %s: unexpected status %d", url, res.StatusCode)
+	}
+	if err = json.NewDecoder(io.LimitReader(res.Body, maxResponseSize)).Decode(dst); err != nil {
+		return fmt.Errorf("decode %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package oidc
+
+import "errors"
+
+// ErrProviderUnavailable провайдер не ответил или ответил некорректно (обнаружение, JWKS, обмен кода).
+// ErrCodeRejected провайдер отклонил код авторизации или code_verifier.
+// ErrInvalidIDToken ID токен не прошел проверку подписи или утверждений.
+var (
+	ErrProviderUnavailable = errors.New("[oidc]: identity provider unavailable")
+	ErrCodeRejected        = errors.New("[oidc]: authorization code rejected")
+	ErrInvalidIDToken      = errors.New("[oidc]: invalid id token")
+)
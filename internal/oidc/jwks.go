@@ -0,0 +1,174 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultJWKSRefreshInterval минимальный интервал между загрузками JWKS.
+// Неизвестный kid приводит к повторной загрузке ключей, но не чаще этого интервала,
+// чтобы поддельные токены не превращали сервис в источник нагрузки на провайдера.
+const DefaultJWKSRefreshInterval = time.Minute
+
+// p256CoordinateSize размер координаты точки кривой P-256 в байтах.
+const p256CoordinateSize = 32
+
+// jsonWebKey открытый ключ JWKS провайдера.
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+}
+
+// remoteKeySet кеш открытых ключей провайдера, загружаемых по jwks_uri.
+type remoteKeySet struct {
+	client          *http.Client
+	uri             string
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// newRemoteKeySet создает кеш ключей провайдера.
+func newRemoteKeySet(
+	client *http.Client,
+	uri string,
+	refreshInterval time.Duration,
+	now func() time.Time,
+) *remoteKeySet {
+	return &remoteKeySet{client: client, uri: uri, refreshInterval: refreshInterval, now: now}
+}
+
+// key возвращает открытый ключ по kid, при необходимости загружая JWKS заново.
+func (s *remoteKeySet) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if k, ok := s.keys[kid]; ok {
+		return k, nil
+	}
+	if !s.fetchedAt.IsZero() && s.now().Sub(s.fetchedAt) < s.refreshInterval {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidIDToken, kid)
+	}
+	if err := s.refresh(ctx); err != nil {
+		return nil, err
+	}
+	if k, ok := s.keys[kid]; ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidIDToken, kid)
+}
+
+// refresh загружает JWKS. Ключи неподдерживаемых типов и ключи шифрования пропускаются.
+func (s *remoteKeySet) refresh(ctx context.Context) error {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(ctx, s.client, s.uri, &set); err != nil {
+		return fmt.Errorf("%w: jwks: %s", ErrProviderUnavailable, err.Error())
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		pub, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.KeyID] = pub
+	}
+	s.keys = keys
+	s.fetchedAt = s.now()
+	return nil
+}
+
+// publicKey преобразует JWK в открытый ключ RSA, ECDSA P-256 или ed25519.
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("rsa exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Curve != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if x.BitLen() > 8*p256CoordinateSize || y.BitLen() > 8*p256CoordinateSize {
+			return nil, errors.New("ec coordinate is too large")
+		}
+		// Точку проверяем через ecdh: ключ не на кривой нельзя использовать для проверки подписи.
+		point := make([]byte, 1, 1+2*p256CoordinateSize)
+		point[0] = 4 // несжатая форма точки
+		point = append(point, x.FillBytes(make([]byte, p256CoordinateSize))...)
+		point = append(point, y.FillBytes(make([]byte, p256CoordinateSize))...)
+		if _, err = ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("invalid ec point: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("decode x: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 public key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+}
+
+// decodeBigInt декодирует целое число base64url.
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("decode big int: %w", err)
+	}
+	if len(b) == 0 {
+		return nil, errors.New("decode big int: empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,254 @@
+// Package oidctest содержит локального провайдера OpenID Connect на httptest для тестов.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// IDTokenLifetime срок действия выдаваемых ID токенов.
+const IDTokenLifetime = 5 * time.Minute
+
+// grant выданный, но еще не обмененный код авторизации.
+type grant struct {
+	clientID    string
+	redirectURI string
+	nonce       string
+	challenge   string
+	subject     string
+	email       string
+}
+
+// Provider локальный провайдер OpenID Connect: документ обнаружения, JWKS,
+// страница входа (Authorize) и token endpoint с проверкой PKCE S256.
+type Provider struct {
+	ClientID string
+	// ModifyClaims, если задан, изменяет утверждения ID токена перед подписью.
+	ModifyClaims func(claims jwt.MapClaims)
+
+	server *httptest.Server
+
+	mu       sync.Mutex
+	key      *rsa.PrivateKey
+	kid      string
+	codes    map[string]grant
+	jwksHits int
+}
+
+// NewProvider запускает провайдера. Сервер останавливается по завершении теста.
+//
+// Параметры:
+//   - t: тест
+//   - clientID: идентификатор зарегистрированного клиента
+//
+// Возвращает:
+//   - *Provider: провайдер
+func NewProvider(t testing.TB, clientID string) *Provider {
+	t.Helper()
+	p := &Provider{ClientID: clientID, codes: make(map[string]grant)}
+	p.RotateKey(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET /jwks", p.jwks)
+	mux.HandleFunc("POST /token", p.token)
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// Issuer возвращает идентификатор провайдера.
+func (p *Provider) Issuer() string {
+	return p.server.URL
+}
+
+// JWKSHits возвращает количество запросов JWKS.
+func (p *Provider) JWKSHits() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.jwksHits
+}
+
+// RotateKey заменяет ключ подписи. Прежний ключ сразу убирается из JWKS.
+//
+// Параметры:
+//   - t: тест
+func (p *Provider) RotateKey(t testing.TB) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate provider key: %v", err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.key = key
+	p.kid = fmt.Sprintf("key-%d", time.Now().UnixNano())
+}
+
+// Authorize имитирует вход пользователя на странице провайдера.
+//
+// Параметры:
+//   - authURL: адрес страницы входа, сформированный клиентом
+//   - subject: идентификатор пользователя у провайдера (sub)
+//   - email: адрес пользователя
+//
+// Возвращает:
+//   - string: адрес возврата с параметрами code и state
+//   - error: ошибка, если запрос клиента некорректен
+func (p *Provider) Authorize(authURL, subject, email string) (string, error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", fmt.Errorf("parse auth url: %w", err)
+	}
+	q := u.Query()
+	switch {
+	case q.Get("response_type") != "code":
+		return "", errors.New("response_type must be code")
+	case q.Get("client_id") != p.ClientID:
+		return "", errors.New("unknown client_id")
+	case q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "":
+		return "", errors.New("pkce S256 is required")
+	case q.Get("redirect_uri") == "":
+		return "", errors.New("redirect_uri is required")
+	}
+
+	code := rand.Text()
+	p.mu.Lock()
+	p.codes[code] = grant{
+		clientID:    q.Get("client_id"),
+		redirectURI: q.Get("redirect_uri"),
+		nonce:       q.Get("nonce"),
+		challenge:   q.Get("code_challenge"),
+		subject:     subject,
+		email:       email,
+	}
+	p.mu.Unlock()
+
+	callback, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		return "", fmt.Errorf("parse redirect_uri: %w", err)
+	}
+	cq := callback.Query()
+	cq.Set("code", code)
+	cq.Set("state", q.Get("state"))
+	callback.RawQuery = cq.Encode()
+	return callback.String(), nil
+}
+
+// SignIDToken подписывает произвольные утверждения текущим ключом провайдера.
+//
+// Параметры:
+//   - claims: утверждения
+//
+// Возвращает:
+//   - string: ID токен
+func (p *Provider) SignIDToken(claims jwt.MapClaims) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = p.kid
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
+
+// discovery отдает документ обнаружения.
+func (p *Provider) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                           p.Issuer(),
+		"authorization_endpoint":           p.Issuer() + "/authorize",
+		"token_endpoint":                   p.Issuer() + "/token",
+		"jwks_uri":                         p.Issuer() + "/jwks",
+		"code_challenge_methods_supported": []string{"S256"},
+	})
+}
+
+// jwks отдает открытый ключ подписи.
+func (p *Provider) jwks(w http.ResponseWriter, _ *http.Request) {
+	p.mu.Lock()
+	p.jwksHits++
+	pub := p.key.PublicKey
+	kid := p.kid
+	p.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}}})
+}
+
+// token обменивает код авторизации на ID токен. Код одноразовый.
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	p.mu.Lock()
+	g, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case r.PostForm.Get("grant_type") != "authorization_code":
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	case !ok || g.clientID != r.PostForm.Get("client_id") || g.redirectURI != r.PostForm.Get("redirect_uri"):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	case base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_grant",
+			"error_description": "code_verifier does not match code_challenge",
+		})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            p.Issuer(),
+		"sub":            g.subject,
+		"aud":            p.ClientID,
+		"exp":            now.Add(IDTokenLifetime).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          g.nonce,
+		"email":          g.email,
+		"email_verified": true,
+	}
+	if p.ModifyClaims != nil {
+		p.ModifyClaims(claims)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": rand.Text(),
+		"token_type":   "Bearer",
+		"expires_in":   int(IDTokenLifetime.Seconds()),
+		"id_token":     p.SignIDToken(claims),
+	})
+}
+
+// writeJSON отправляет JSON ответ.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
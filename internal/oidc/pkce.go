@@ -0,0 +1,38 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// randomBytesLength длина случайных значений state, nonce и code_verifier до кодирования.
+// 32 байта дают 43 символа base64url - минимальную длину code_verifier по RFC 7636.
+const randomBytesLength = 32
+
+// RandomString возвращает криптографически случайную строку base64url без дополнения.
+// Подходит для state, nonce и code_verifier.
+//
+// Возвращает:
+//   - string: случайная строка из 43 символов
+//   - error: ошибка источника случайных чисел
+func RandomString() (string, error) {
+	b := make([]byte, randomBytesLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate random string: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// S256Challenge вычисляет code_challenge метода S256 для code_verifier (RFC 7636, раздел 4.2).
+//
+// Параметры:
+//   - verifier: code_verifier
+//
+// Возвращает:
+//   - string: code_challenge
+func S256Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
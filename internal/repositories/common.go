@@ -13,12 +13,46 @@ type BatchResult[T any] struct {
 
 // BatchCreateArg содержит данные для создания короткого URL.
 type BatchCreateArg struct {
-	ShortIdentifier string // Короткий идентификатор URL
-	URL             string // Оригинальный URL
-	VisitorUUID     string // Идентификатор посетителя
+	ShortIdentifier string   // Короткий идентификатор URL
+	URL             string   // Оригинальный URL
+	VisitorUUID     string   // Идентификатор посетителя
+	Tags            []string // Метки URL
+}
+
+// BatchDeleteArg содержит короткие идентификаторы URL посетителя, которые нужно пометить удаленными.
+type BatchDeleteArg struct {
+	VisitorUUID string   // Идентификатор посетителя
+	ShortIDs    []string // Короткие идентификаторы URL
 }
 
 // BatchCreateShortURLsResult содержит результаты пакетного создания коротких URL.
 type BatchCreateShortURLsResult struct {
 	Results []BatchResult[models.URL] // Результаты для каждого URL
 }
+
+// URLSearchFilter условия поиска ссылок всех посетителей.
+type URLSearchFilter struct {
+	Query        string // Подстрока оригинального URL без учета регистра или точный короткий идентификатор
+	VisitorUUID  string // Владелец ссылок. Пустое значение - любой
+	OnlyDisabled bool   // Только отключенные администратором ссылки
+	Limit        int    // Максимальное количество записей
+	Offset       int    // Количество пропускаемых записей
+}
+
+// AuditFilter условия выборки журнала аудита.
+type AuditFilter struct {
+	Target string // Объект действия. Пустое значение - любой
+	Limit  int    // Максимальное количество записей
+}
+
+// URLUpdate изменения ссылки рабочего пространства. nil поля не изменяются.
+type URLUpdate struct {
+	URL  *string   // Новый оригинальный URL
+	Tags *[]string // Новые метки (пустой слайс удаляет все метки)
+}
+
+// WebhookJobClaim ожидающая доставка, захваченная доставщиком, вместе с ее вебхуком.
+type WebhookJobClaim struct {
+	Job     models.WebhookJob // Ожидающая доставка
+	Webhook models.Webhook    // Вебхук получателя
+}
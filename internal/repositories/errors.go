@@ -4,9 +4,11 @@ import "errors"
 
 // ErrNotFound возвращается, когда запрашиваемая запись не найдена в хранилище
 // ErrDuplicateKey возвращается при попытке создать запись с уже существующим ключом
-// ErrUnknown возвращается при неизвестной ошибке на уровне репозитория.
+// ErrUnknown возвращается при неизвестной ошибке на уровне репозитория
+// ErrLastOwner возвращается при попытке понизить или удалить последнего владельца рабочего пространства.
 var (
 	ErrNotFound     = errors.New("[repository]: record not found")
 	ErrDuplicateKey = errors.New("[repository]: duplicate key")
 	ErrUnknown      = errors.New("[repository]: unknown error")
+	ErrLastOwner    = errors.New("[repository]: workspace must keep at least one owner")
 )
@@ -0,0 +1,122 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/db/memory"
+	"github.com/fsdevblog/shorturl/internal/models"
+)
+
+// apiKeysCollection имя коллекции in-memory хранилища для ключей API.
+const apiKeysCollection = "api_keys"
+
+// APIKeyRepo представляет собой репозиторий ключей API в памяти.
+// Ключи хранятся по префиксу, так как поиск по префиксу выполняется на каждый запрос с ключом.
+type APIKeyRepo struct {
+	keys *memory.MStorage
+	mu   sync.Mutex
+}
+
+// NewAPIKeyRepo создает новый экземпляр репозитория ключей API.
+//
+// Параметры:
+//   - store: экземпляр хранилища в памяти
+//
+// Возвращает:
+//   - *APIKeyRepo: инициализированный репозиторий
+func NewAPIKeyRepo(store *db.MemoryStorage) *APIKeyRepo {
+	return &APIKeyRepo{keys: store.Collection(apiKeysCollection)}
+}
+
+// Create сохраняет новый ключ API.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - k: данные ключа
+//
+// Возвращает:
+//   - *models.APIKey: созданная запись
+//   - error: ошибка создания (преобразованная через convertErrorType)
+func (r *APIKeyRepo) Create(ctx context.Context, k *models.APIKey) (*models.APIKey, error) {
+	m := *k
+	m.CreatedAt = time.Now().UTC()
+	if err := memory.Set[models.APIKey](ctx, m.Prefix, &m, r.keys); err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", convertErrorType(err))
+	}
+	return &m, nil
+}
+
+// GetAllByVisitorUUID получает все ключи посетителя, включая отозванные.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - []models.APIKey: найденные записи в порядке создания
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (r *APIKeyRepo) GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.APIKey, error) {
+	keys, err := memory.FilterAll[models.APIKey](ctx, r.keys, func(k models.APIKey) bool {
+		return k.VisitorUUID == visitorUUID
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api keys by visitor uuid %s: %w", visitorUUID, convertErrorType(err))
+	}
+	slices.SortFunc(keys, func(a, b models.APIKey) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return keys, nil
+}
+
+// GetByPrefix получает ключ по его открытому префиксу.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - prefix: открытый префикс ключа
+//
+// Возвращает:
+//   - *models.APIKey: найденная запись
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (r *APIKeyRepo) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	k, err := memory.Get[models.APIKey](ctx, prefix, r.keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key %s: %w", prefix, convertErrorType(err))
+	}
+	return k, nil
+}
+
+// Revoke отзывает действующий ключ посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - id: идентификатор ключа
+//   - visitorUUID: идентификатор посетителя
+//   - at: момент отзыва
+//
+// Возвращает:
+//   - error: repositories.ErrNotFound, если действующего ключа нет
+func (r *APIKeyRepo) Revoke(ctx context.Context, id string, visitorUUID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found, err := memory.FilterAll[models.APIKey](ctx, r.keys, func(k models.APIKey) bool {
+		return k.ID == id && k.VisitorUUID == visitorUUID && !k.Revoked()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get api key %s: %w", id, convertErrorType(err))
+	}
+	if len(found) == 0 {
+		return fmt.Errorf("failed to revoke api key %s: %w", id, convertErrorType(memory.ErrNotFound))
+	}
+	k := found[0]
+	k.RevokedAt = &at
+	if err = memory.Set(ctx, k.Prefix, &k, r.keys, memory.WithOverwrite()); err != nil {
+		return fmt.Errorf("failed to revoke api key %s: %w", id, convertErrorType(err))
+	}
+	return nil
+}
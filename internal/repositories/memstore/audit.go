@@ -0,0 +1,76 @@
+package memstore
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/db/memory"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+)
+
+// auditCollection имя коллекции in-memory хранилища для журнала аудита.
+const auditCollection = "audit_log"
+
+// AuditRepo представляет собой репозиторий журнала аудита в памяти.
+type AuditRepo struct {
+	entries *memory.MStorage
+}
+
+// NewAuditRepo создает новый экземпляр репозитория журнала аудита.
+//
+// Параметры:
+//   - store: экземпляр хранилища в памяти
+//
+// Возвращает:
+//   - *AuditRepo: инициализированный репозиторий
+func NewAuditRepo(store *db.MemoryStorage) *AuditRepo {
+	return &AuditRepo{entries: store.Collection(auditCollection)}
+}
+
+// Create добавляет запись в журнал.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - e: данные записи
+//
+// Возвращает:
+//   - *models.AuditEntry: созданная запись
+//   - error: ошибка создания (преобразованная через convertErrorType)
+func (r *AuditRepo) Create(ctx context.Context, e *models.AuditEntry) (*models.AuditEntry, error) {
+	m := *e
+	m.CreatedAt = time.Now().UTC()
+	if err := memory.Set[models.AuditEntry](ctx, m.ID, &m, r.entries); err != nil {
+		return nil, fmt.Errorf("failed to create audit entry: %w", convertErrorType(err))
+	}
+	return &m, nil
+}
+
+// List получает записи журнала от новых к старым, как и sql.AuditRepo.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - filter: условия выборки
+//
+// Возвращает:
+//   - []models.AuditEntry: найденные записи
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (r *AuditRepo) List(ctx context.Context, filter repositories.AuditFilter) ([]models.AuditEntry, error) {
+	entries, err := memory.FilterAll[models.AuditEntry](ctx, r.entries, func(e models.AuditEntry) bool {
+		return filter.Target == "" || e.Target == filter.Target
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", convertErrorType(err))
+	}
+	slices.SortFunc(entries, func(a, b models.AuditEntry) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	if len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
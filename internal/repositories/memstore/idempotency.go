@@ -0,0 +1,148 @@
+package memstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/db/memory"
+	"github.com/fsdevblog/shorturl/internal/models"
+)
+
+// idempotencyKeysCollection имя коллекции in-memory хранилища для ключей идемпотентности.
+const idempotencyKeysCollection = "idempotency_keys"
+
+// IdempotencyRepo представляет собой репозиторий ключей идемпотентности в памяти.
+type IdempotencyRepo struct {
+	keys *memory.MStorage
+	mu   sync.Mutex
+	now  func() time.Time
+}
+
+// NewIdempotencyRepo создает новый экземпляр репозитория ключей идемпотентности.
+//
+// Параметры:
+//   - store: экземпляр хранилища в памяти
+//
+// Возвращает:
+//   - *IdempotencyRepo: инициализированный репозиторий
+func NewIdempotencyRepo(store *db.MemoryStorage) *IdempotencyRepo {
+	return &IdempotencyRepo{
+		keys: store.Collection(idempotencyKeysCollection),
+		now:  time.Now,
+	}
+}
+
+// idempotencyStorageKey ключ записи в хранилище.
+func idempotencyStorageKey(visitorUUID, key string) string {
+	return visitorUUID + ":" + key
+}
+
+// Reserve сохраняет запись об обрабатываемом запросе, если для ключа посетителя нет неистекшей записи.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - r: запись с ключом, отпечатком и сроком действия
+//
+// Возвращает:
+//   - *models.IdempotencyRecord: сохраненная или уже существующая запись
+//   - bool: true, если запись сохранена
+//   - error: ошибка сохранения (преобразованная через convertErrorType)
+func (i *IdempotencyRepo) Reserve(
+	ctx context.Context,
+	r *models.IdempotencyRecord,
+) (*models.IdempotencyRecord, bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	storageKey := idempotencyStorageKey(r.VisitorUUID, r.Key)
+	existing, err := memory.Get[models.IdempotencyRecord](ctx, storageKey, i.keys)
+	switch {
+	case err == nil && existing.ExpiresAt.After(i.now()):
+		return existing, false, nil
+	case err != nil && !errors.Is(err, memory.ErrNotFound):
+		return nil, false, fmt.Errorf("failed to get idempotency key: %w", convertErrorType(err))
+	}
+
+	m := *r
+	m.StatusCode, m.ContentType, m.Body = 0, "", nil
+	m.CreatedAt = i.now().UTC()
+	if err = memory.Set(ctx, storageKey, &m, i.keys, memory.WithOverwrite()); err != nil {
+		return nil, false, fmt.Errorf("failed to reserve idempotency key: %w", convertErrorType(err))
+	}
+	return &m, true, nil
+}
+
+// Complete сохраняет ответ на запрос с ключом посетителя и продлевает запись до r.ExpiresAt.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - r: запись с ключом, ответом и сроком хранения ответа
+//
+// Возвращает:
+//   - error: ошибка сохранения (преобразованная через convertErrorType)
+func (i *IdempotencyRepo) Complete(ctx context.Context, r *models.IdempotencyRecord) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	storageKey := idempotencyStorageKey(r.VisitorUUID, r.Key)
+	m, err := memory.Get[models.IdempotencyRecord](ctx, storageKey, i.keys)
+	if err != nil {
+		return fmt.Errorf("failed to get idempotency key: %w", convertErrorType(err))
+	}
+	m.StatusCode, m.ContentType, m.Body, m.ExpiresAt = r.StatusCode, r.ContentType, r.Body, r.ExpiresAt
+	if err = memory.Set(ctx, storageKey, m, i.keys, memory.WithOverwrite()); err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", convertErrorType(err))
+	}
+	return nil
+}
+
+// Delete удаляет запись ключа посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//   - key: ключ идемпотентности
+//
+// Возвращает:
+//   - error: ошибка удаления (преобразованная через convertErrorType)
+func (i *IdempotencyRepo) Delete(ctx context.Context, visitorUUID string, key string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	err := i.keys.Delete(ctx, idempotencyStorageKey(visitorUUID, key))
+	if err != nil && !errors.Is(err, memory.ErrNotFound) {
+		return fmt.Errorf("failed to delete idempotency key: %w", convertErrorType(err))
+	}
+	return nil
+}
+
+// DeleteExpired удаляет записи, срок действия которых истек к моменту before.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - before: момент времени
+//
+// Возвращает:
+//   - int64: количество удаленных записей
+//   - error: ошибка удаления (преобразованная через convertErrorType)
+func (i *IdempotencyRepo) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	expired, err := memory.FilterAll[models.IdempotencyRecord](ctx, i.keys, func(r models.IdempotencyRecord) bool {
+		return !r.ExpiresAt.After(before)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get expired idempotency keys: %w", convertErrorType(err))
+	}
+	for _, r := range expired {
+		if delErr := i.keys.Delete(ctx, idempotencyStorageKey(r.VisitorUUID, r.Key)); delErr != nil {
+			return 0, fmt.Errorf("failed to delete idempotency key: %w", convertErrorType(delErr))
+		}
+	}
+	return int64(len(expired)), nil
+}
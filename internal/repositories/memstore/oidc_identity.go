@@ -0,0 +1,94 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/db/memory"
+	"github.com/fsdevblog/shorturl/internal/models"
+)
+
+// oidcIdentitiesCollection имя коллекции in-memory хранилища для учетных записей OpenID Connect.
+const oidcIdentitiesCollection = "oidc_identities"
+
+// OIDCIdentityRepo представляет собой репозиторий учетных записей OpenID Connect в памяти.
+// Записи хранятся по паре провайдер и идентификатор пользователя у него.
+type OIDCIdentityRepo struct {
+	identities *memory.MStorage
+}
+
+// NewOIDCIdentityRepo создает новый экземпляр репозитория учетных записей OpenID Connect.
+//
+// Параметры:
+//   - store: экземпляр хранилища в памяти
+//
+// Возвращает:
+//   - *OIDCIdentityRepo: инициализированный репозиторий
+func NewOIDCIdentityRepo(store *db.MemoryStorage) *OIDCIdentityRepo {
+	return &OIDCIdentityRepo{identities: store.Collection(oidcIdentitiesCollection)}
+}
+
+// Create сохраняет новую учетную запись.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - i: данные учетной записи
+//
+// Возвращает:
+//   - *models.OIDCIdentity: созданная запись
+//   - error: repositories.ErrDuplicateKey, если учетная запись уже связана (преобразованная через convertErrorType)
+func (r *OIDCIdentityRepo) Create(ctx context.Context, i *models.OIDCIdentity) (*models.OIDCIdentity, error) {
+	m := *i
+	m.CreatedAt = time.Now().UTC()
+	if err := memory.Set[models.OIDCIdentity](ctx, oidcIdentityKey(m.Issuer, m.Subject), &m, r.identities); err != nil {
+		return nil, fmt.Errorf("failed to create oidc identity %s: %w", m.Subject, convertErrorType(err))
+	}
+	return &m, nil
+}
+
+// Get получает учетную запись по провайдеру и идентификатору пользователя у него.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - issuer: идентификатор провайдера
+//   - subject: идентификатор пользователя у провайдера
+//
+// Возвращает:
+//   - *models.OIDCIdentity: найденная запись
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (r *OIDCIdentityRepo) Get(ctx context.Context, issuer, subject string) (*models.OIDCIdentity, error) {
+	i, err := memory.Get[models.OIDCIdentity](ctx, oidcIdentityKey(issuer, subject), r.identities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get oidc identity %s: %w", subject, convertErrorType(err))
+	}
+	return i, nil
+}
+
+// GetByUserID получает учетную запись, связанную с UUID посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - userID: UUID посетителя
+//
+// Возвращает:
+//   - *models.OIDCIdentity: найденная запись
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (r *OIDCIdentityRepo) GetByUserID(ctx context.Context, userID string) (*models.OIDCIdentity, error) {
+	found, err := memory.FilterAll[models.OIDCIdentity](ctx, r.identities, func(i models.OIDCIdentity) bool {
+		return i.UserID == userID
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get oidc identity of user %s: %w", userID, convertErrorType(err))
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("failed to get oidc identity of user %s: %w", userID, convertErrorType(memory.ErrNotFound))
+	}
+	return &found[0], nil
+}
+
+// oidcIdentityKey формирует ключ записи. Нулевой байт не встречается в URL провайдера.
+func oidcIdentityKey(issuer, subject string) string {
+	return issuer + "\x00" + subject
+}
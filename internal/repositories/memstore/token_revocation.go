@@ -0,0 +1,171 @@
+package memstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/db/memory"
+	"github.com/fsdevblog/shorturl/internal/models"
+)
+
+// Имена коллекций in-memory хранилища для отзывов токенов и сессий посетителей.
+const (
+	revokedTokensCollection      = "revoked_tokens"
+	sessionRevocationsCollection = "session_revocations"
+)
+
+// TokenRevocationRepo представляет собой репозиторий отозванных токенов посетителей в памяти.
+// Отозванные токены хранятся по jti, отзывы сессий - по UUID посетителя.
+type TokenRevocationRepo struct {
+	tokens   *memory.MStorage
+	sessions *memory.MStorage
+	mu       sync.Mutex
+	now      func() time.Time
+}
+
+// NewTokenRevocationRepo создает новый экземпляр репозитория отозванных токенов.
+//
+// Параметры:
+//   - store: экземпляр хранилища в памяти
+//
+// Возвращает:
+//   - *TokenRevocationRepo: инициализированный репозиторий
+func NewTokenRevocationRepo(store *db.MemoryStorage) *TokenRevocationRepo {
+	return &TokenRevocationRepo{
+		tokens:   store.Collection(revokedTokensCollection),
+		sessions: store.Collection(sessionRevocationsCollection),
+		now:      time.Now,
+	}
+}
+
+// RevokeToken сохраняет отзыв токена. Повторный отзыв того же токена не является ошибкой.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - t: отозванный токен
+//
+// Возвращает:
+//   - error: ошибка сохранения (преобразованная через convertErrorType)
+func (r *TokenRevocationRepo) RevokeToken(ctx context.Context, t *models.RevokedToken) error {
+	m := *t
+	m.CreatedAt = r.now().UTC()
+	err := memory.Set(ctx, m.JTI, &m, r.tokens)
+	if err != nil && !errors.Is(err, memory.ErrDuplicateKey) {
+		return fmt.Errorf("failed to revoke token %s: %w", m.JTI, convertErrorType(err))
+	}
+	return nil
+}
+
+// IsTokenRevoked проверяет, отозван ли токен.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - jti: идентификатор токена
+//
+// Возвращает:
+//   - bool: true, если токен отозван
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (r *TokenRevocationRepo) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	_, err := memory.Get[models.RevokedToken](ctx, jti, r.tokens)
+	switch {
+	case errors.Is(err, memory.ErrNotFound):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("failed to get revoked token %s: %w", jti, convertErrorType(err))
+	}
+	return true, nil
+}
+
+// RevokeSessions сохраняет отзыв всех сессий посетителя. Из нескольких отзывов действует самый поздний.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - s: отзыв сессий
+//
+// Возвращает:
+//   - error: ошибка сохранения (преобразованная через convertErrorType)
+func (r *TokenRevocationRepo) RevokeSessions(ctx context.Context, s *models.SessionRevocation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m := *s
+	existing, err := memory.Get[models.SessionRevocation](ctx, m.VisitorUUID, r.sessions)
+	switch {
+	case err == nil:
+		if existing.RevokedBefore.After(m.RevokedBefore) {
+			m.RevokedBefore = existing.RevokedBefore
+		}
+		if existing.ExpiresAt.After(m.ExpiresAt) {
+			m.ExpiresAt = existing.ExpiresAt
+		}
+	case !errors.Is(err, memory.ErrNotFound):
+		return fmt.Errorf("failed to get sessions revocation of %s: %w", m.VisitorUUID, convertErrorType(err))
+	}
+	if err = memory.Set(ctx, m.VisitorUUID, &m, r.sessions, memory.WithOverwrite()); err != nil {
+		return fmt.Errorf("failed to revoke sessions of %s: %w", m.VisitorUUID, convertErrorType(err))
+	}
+	return nil
+}
+
+// GetSessionRevocation получает отзыв сессий посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - *models.SessionRevocation: найденная запись
+//   - error: repositories.ErrNotFound, если сессии посетителя не отзывались (преобразованная через convertErrorType)
+func (r *TokenRevocationRepo) GetSessionRevocation(
+	ctx context.Context,
+	visitorUUID string,
+) (*models.SessionRevocation, error) {
+	s, err := memory.Get[models.SessionRevocation](ctx, visitorUUID, r.sessions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions revocation of %s: %w", visitorUUID, convertErrorType(err))
+	}
+	return s, nil
+}
+
+// DeleteExpired удаляет отзывы токенов и сессий, срок действия которых истек к моменту before.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - before: момент времени
+//
+// Возвращает:
+//   - int64: количество удаленных записей
+//   - error: ошибка удаления (преобразованная через convertErrorType)
+func (r *TokenRevocationRepo) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tokens, err := memory.FilterAll[models.RevokedToken](ctx, r.tokens, func(t models.RevokedToken) bool {
+		return !t.ExpiresAt.After(before)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get expired revoked tokens: %w", convertErrorType(err))
+	}
+	for _, t := range tokens {
+		if delErr := r.tokens.Delete(ctx, t.JTI); delErr != nil {
+			return 0, fmt.Errorf("failed to delete revoked token: %w", convertErrorType(delErr))
+		}
+	}
+
+	sessions, err := memory.FilterAll[models.SessionRevocation](ctx, r.sessions, func(s models.SessionRevocation) bool {
+		return !s.ExpiresAt.After(before)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get expired sessions revocations: %w", convertErrorType(err))
+	}
+	for _, s := range sessions {
+		if delErr := r.sessions.Delete(ctx, s.VisitorUUID); delErr != nil {
+			return 0, fmt.Errorf("failed to delete sessions revocation: %w", convertErrorType(delErr))
+		}
+	}
+	return int64(len(tokens) + len(sessions)), nil
+}
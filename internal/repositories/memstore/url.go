@@ -1,10 +1,12 @@
 package memstore
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -34,6 +36,8 @@ func NewURLRepo(store *db.MemoryStorage) *URLRepo {
 }
 
 // BatchCreate создает несколько URL записей одновременно.
+// Результаты возвращаются в порядке аргументов. Для уже существующей записи, как и в sql.URLRepo,
+// возвращается она сама вместе с ошибкой repositories.ErrDuplicateKey.
 //
 // Параметры:
 //   - ctx: контекст выполнения
@@ -46,26 +50,24 @@ func (u *URLRepo) BatchCreate(
 	ctx context.Context,
 	mURLs []repositories.BatchCreateArg,
 ) (*repositories.BatchCreateShortURLsResult, error) {
-	var collection = make(map[string]*models.URL, len(mURLs))
-	for _, m := range mURLs {
-		collection[m.ShortIdentifier] = &models.URL{
+	var result = make([]repositories.BatchResult[models.URL], len(mURLs))
+	now := time.Now().UTC()
+	for i, m := range mURLs {
+		value := models.URL{
+			CreatedAt:       now,
+			UpdatedAt:       now,
 			URL:             m.URL,
 			ShortIdentifier: m.ShortIdentifier,
 			VisitorUUID:     m.VisitorUUID,
+			Tags:            m.Tags,
 		}
-	}
-	br := memory.BatchSet[models.URL](ctx, collection, u.s.MStorage)
-
-	var result = make([]repositories.BatchResult[models.URL], len(br))
-	for i, r := range br {
-		result[i] = repositories.BatchResult[models.URL]{
-			Value: models.URL{
-				URL:             collection[r.Key].URL,
-				ShortIdentifier: collection[r.Key].ShortIdentifier,
-				VisitorUUID:     collection[r.Key].VisitorUUID,
-			},
-			Err: convertErrorType(r.Err),
+		err := memory.Set[models.URL](ctx, m.ShortIdentifier, &value, u.s.MStorage)
+		if errors.Is(err, memory.ErrDuplicateKey) {
+			if existing, getErr := memory.Get[models.URL](ctx, m.ShortIdentifier, u.s.MStorage); getErr == nil {
+				value = *existing
+			}
 		}
+		result[i] = repositories.BatchResult[models.URL]{Value: value, Err: convertErrorType(err)}
 	}
 
 	return &repositories.BatchCreateShortURLsResult{
@@ -73,20 +75,34 @@ func (u *URLRepo) BatchCreate(
 	}, nil
 }
 
-// Create создает новую URL запись.
+// Create создает новую URL запись. Ссылка рабочего пространства (задан WorkspaceID),
+// как и в sql.URLRepo, уникальна по URL в пределах пространства.
 //
 // Параметры:
 //   - ctx: контекст выполнения
 //   - sURL: данные URL для создания
 //
 // Возвращает:
-//   - *models.URL: созданная запись
+//   - *models.URL: созданная или уже существующая запись
 //   - bool: флаг успешного создания
 //   - error: ошибка создания (преобразованная через convertErrorType)
 func (u *URLRepo) Create(ctx context.Context, sURL *models.URL) (*models.URL, bool, error) {
-	if err := memory.Set[models.URL](ctx, sURL.ShortIdentifier, sURL, u.s.MStorage); err != nil {
+	m := *sURL
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now().UTC()
+		m.UpdatedAt = m.CreatedAt
+	}
+	if m.WorkspaceID != "" {
+		return u.createInWorkspace(ctx, &m)
+	}
+	if err := memory.Set[models.URL](ctx, m.ShortIdentifier, &m, u.s.MStorage); err != nil {
 		if errors.Is(err, memory.ErrDuplicateKey) {
-			return nil, false, nil
+			// Как и в sql.URLRepo, для существующей записи возвращаем её саму.
+			existing, getErr := u.GetByShortIdentifier(ctx, sURL.ShortIdentifier)
+			if getErr != nil {
+				return nil, false, getErr
+			}
+			return existing, false, nil
 		}
 
 		return nil, false, fmt.Errorf(
@@ -94,7 +110,7 @@ func (u *URLRepo) Create(ctx context.Context, sURL *models.URL) (*models.URL, bo
 			convertErrorType(err),
 		)
 	}
-	return sURL, true, nil
+	return &m, true, nil
 }
 
 // GetByShortIdentifier получает URL по короткому идентификатору.
@@ -117,7 +133,32 @@ func (u *URLRepo) GetByShortIdentifier(ctx context.Context, shortID string) (*mo
 	return url, nil
 }
 
-// GetAllByVisitorUUID получает все URL для указанного посетителя.
+// GetByShortIdentifiers получает URL по нескольким коротким идентификаторам.
+// Отсутствующие идентификаторы пропускаются.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - shortIDs: короткие идентификаторы URL
+//
+// Возвращает:
+//   - []models.URL: найденные записи, включая удаленные
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (u *URLRepo) GetByShortIdentifiers(ctx context.Context, shortIDs []string) ([]models.URL, error) {
+	urls := make([]models.URL, 0, len(shortIDs))
+	for _, shortID := range shortIDs {
+		m, err := u.GetByShortIdentifier(ctx, shortID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		urls = append(urls, *m)
+	}
+	return urls, nil
+}
+
+// GetAllByVisitorUUID получает все личные URL указанного посетителя, без ссылок рабочих пространств.
 //
 // Параметры:
 //   - ctx: контекст выполнения
@@ -128,7 +169,7 @@ func (u *URLRepo) GetByShortIdentifier(ctx context.Context, shortID string) (*mo
 //   - error: ошибка поиска (преобразованная через convertErrorType)
 func (u *URLRepo) GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.URL, error) {
 	data, err := memory.FilterAll[models.URL](ctx, u.s.MStorage, func(val models.URL) bool {
-		if val.VisitorUUID == "" {
+		if val.VisitorUUID == "" || val.WorkspaceID != "" {
 			return false
 		}
 		return val.VisitorUUID == visitorUUID
@@ -142,6 +183,58 @@ func (u *URLRepo) GetAllByVisitorUUID(ctx context.Context, visitorUUID string) (
 	return data, nil
 }
 
+// GetByURLVisitorUUID получает личную запись посетителя по оригинальному URL.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - rawURL: оригинальный URL
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - *models.URL: найденная запись
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (u *URLRepo) GetByURLVisitorUUID(ctx context.Context, rawURL string, visitorUUID string) (*models.URL, error) {
+	data, err := memory.FilterAll[models.URL](ctx, u.s.MStorage, func(val models.URL) bool {
+		return val.URL == rawURL && val.VisitorUUID == visitorUUID && val.WorkspaceID == ""
+	})
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to get record by url %s and visitor uuid %s: %w",
+			rawURL, visitorUUID, convertErrorType(err),
+		)
+	}
+	if len(data) == 0 {
+		return nil, repositories.ErrNotFound
+	}
+	return &data[0], nil
+}
+
+// EachByVisitorUUID вызывает fn для каждой личной записи посетителя, включая удаленные, в стабильном порядке.
+// Записи копируются из хранилища до обхода, чтобы медленный fn не блокировал запись.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//   - fn: функция, вызываемая для каждой записи; ошибка прекращает обход
+//
+// Возвращает:
+//   - error: ошибка fn или ошибка чтения (преобразованная через convertErrorType)
+func (u *URLRepo) EachByVisitorUUID(ctx context.Context, visitorUUID string, fn func(models.URL) error) error {
+	data, err := u.GetAllByVisitorUUID(ctx, visitorUUID)
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(data, func(a, b models.URL) int {
+		return cmp.Or(cmp.Compare(a.ID, b.ID), cmp.Compare(a.ShortIdentifier, b.ShortIdentifier))
+	})
+	for _, m := range data {
+		if fnErr := fn(m); fnErr != nil {
+			return fnErr
+		}
+	}
+	return nil
+}
+
 // GetByURL получает запись по оригинальному URL.
 //
 // Параметры:
@@ -187,7 +280,7 @@ func (u *URLRepo) GetAll(ctx context.Context) ([]models.URL, error) {
 	return urls, nil
 }
 
-// DeleteByShortIDsVisitorUUID помечает URL записи как удаленные для указанного посетителя.
+// DeleteByShortIDsVisitorUUID помечает личные URL записи указанного посетителя как удаленные.
 //
 // Параметры:
 //   - ctx: контекст выполнения
@@ -195,31 +288,404 @@ func (u *URLRepo) GetAll(ctx context.Context) ([]models.URL, error) {
 //   - shortIDs: список коротких идентификаторов для удаления
 //
 // Возвращает:
+//   - []string: идентификаторы, помеченные удаленными этим вызовом
+//   - error: ошибка удаления (преобразованная через convertErrorType)
+func (u *URLRepo) DeleteByShortIDsVisitorUUID(
+	ctx context.Context,
+	visitorUUID string,
+	shortIDs []string,
+) ([]string, error) {
+	deleted, err := u.BatchDelete(ctx, []repositories.BatchDeleteArg{{VisitorUUID: visitorUUID, ShortIDs: shortIDs}})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(deleted))
+	for i := range deleted {
+		ids[i] = deleted[i].ShortIdentifier
+	}
+	return ids, nil
+}
+
+// BatchDelete помечает удаленными личные URL нескольких посетителей.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - args: идентификаторы URL, сгруппированные по посетителям
+//
+// Возвращает:
+//   - []models.URL: записи, помеченные удаленными этим вызовом
 //   - error: ошибка удаления (преобразованная через convertErrorType)
-func (u *URLRepo) DeleteByShortIDsVisitorUUID(ctx context.Context, visitorUUID string, shortIDs []string) (err error) { //nolint:nonamedreturns,lll
+func (u *URLRepo) BatchDelete(ctx context.Context, args []repositories.BatchDeleteArg) ([]models.URL, error) {
 	u.mu.Lock()
 	defer u.mu.Unlock()
 
+	wanted := make(map[string]map[string]struct{}, len(args))
+	for _, arg := range args {
+		ids, ok := wanted[arg.VisitorUUID]
+		if !ok {
+			ids = make(map[string]struct{}, len(arg.ShortIDs))
+			wanted[arg.VisitorUUID] = ids
+		}
+		for _, shortID := range arg.ShortIDs {
+			ids[shortID] = struct{}{}
+		}
+	}
+
 	data, err := memory.FilterAll[models.URL](ctx, u.s.MStorage, func(val models.URL) bool {
-		if val.VisitorUUID == "" {
+		if val.VisitorUUID == "" || val.WorkspaceID != "" || val.DeletedAt != nil {
 			return false
 		}
-		return val.VisitorUUID == visitorUUID && slices.Contains(shortIDs, val.ShortIdentifier)
+		_, ok := wanted[val.VisitorUUID][val.ShortIdentifier]
+		return ok
 	})
 	if err != nil {
-		return convertErrorType(err)
+		return nil, convertErrorType(err)
 	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
 	now := time.Now().UTC()
-	var batchMap = make(map[string]*models.URL, len(data))
+	batchMap := make(map[string]*models.URL, len(data))
 	for i := range data {
 		data[i].DeletedAt = &now
 		batchMap[data[i].ShortIdentifier] = &data[i]
 	}
-	res := memory.BatchSet[models.URL](ctx, batchMap, u.s.MStorage, memory.WithOverwrite())
-	for _, re := range res {
+	for _, re := range memory.BatchSet[models.URL](ctx, batchMap, u.s.MStorage, memory.WithOverwrite()) {
+		if re.Err != nil {
+			err = errors.Join(err, convertErrorType(re.Err))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Stats подсчитывает количество неудаленных URL и уникальных посетителей за один проход по хранилищу.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//
+// Возвращает:
+//   - *models.Stats: статистика
+//   - error: ошибка подсчета (преобразованная через convertErrorType)
+func (u *URLRepo) Stats(ctx context.Context) (*models.Stats, error) {
+	var stats models.Stats
+	visitors := make(map[string]struct{})
+	err := memory.ForEach[models.URL](ctx, u.s.MStorage, func(val models.URL) {
+		if val.DeletedAt == nil {
+			stats.URLs++
+		}
+		if val.VisitorUUID != "" {
+			visitors[val.VisitorUUID] = struct{}{}
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count stats: %w", convertErrorType(err))
+	}
+	stats.Users = len(visitors)
+	return &stats, nil
+}
+
+// ReassignVisitor передает записи одного посетителя другому.
+// Записи с URL, который у получателя уже есть, остаются у прежнего посетителя, как и в sql.URLRepo.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - fromUUID: идентификатор прежнего посетителя
+//   - toUUID: идентификатор нового посетителя
+//
+// Возвращает:
+//   - int: количество переданных записей
+//   - error: ошибка обновления (преобразованная через convertErrorType)
+func (u *URLRepo) ReassignVisitor(ctx context.Context, fromUUID string, toUUID string) (int, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if fromUUID == "" || fromUUID == toUUID {
+		return 0, nil
+	}
+	owned := make(map[string]struct{})
+	var moving []models.URL
+	err := memory.ForEach[models.URL](ctx, u.s.MStorage, func(val models.URL) {
+		if val.WorkspaceID != "" {
+			return
+		}
+		switch val.VisitorUUID {
+		case toUUID:
+			owned[val.URL] = struct{}{}
+		case fromUUID:
+			moving = append(moving, val)
+		}
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get records by visitor uuid %s: %w", fromUUID, convertErrorType(err))
+	}
+
+	now := time.Now().UTC()
+	batchMap := make(map[string]*models.URL, len(moving))
+	for i := range moving {
+		if _, ok := owned[moving[i].URL]; ok {
+			continue
+		}
+		moving[i].VisitorUUID = toUUID
+		moving[i].UpdatedAt = now
+		batchMap[moving[i].ShortIdentifier] = &moving[i]
+	}
+	for _, re := range memory.BatchSet[models.URL](ctx, batchMap, u.s.MStorage, memory.WithOverwrite()) {
+		if re.Err != nil {
+			err = errors.Join(err, convertErrorType(re.Err))
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(batchMap), nil
+}
+
+// Search ищет ссылки всех посетителей, включая удаленные и отключенные, от новых к старым, как и sql.URLRepo.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - filter: условия поиска
+//
+// Возвращает:
+//   - []models.URL: найденные записи
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (u *URLRepo) Search(ctx context.Context, filter repositories.URLSearchFilter) ([]models.URL, error) {
+	query := strings.ToLower(filter.Query)
+	data, err := memory.FilterAll[models.URL](ctx, u.s.MStorage, func(val models.URL) bool {
+		if query != "" && !strings.Contains(strings.ToLower(val.URL), query) && val.ShortIdentifier != filter.Query {
+			return false
+		}
+		if filter.VisitorUUID != "" && val.VisitorUUID != filter.VisitorUUID {
+			return false
+		}
+		return !filter.OnlyDisabled || val.DisabledAt != nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search records: %w", convertErrorType(err))
+	}
+	slices.SortFunc(data, func(a, b models.URL) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(a.ShortIdentifier, b.ShortIdentifier))
+	})
+	if filter.Offset >= len(data) {
+		return nil, nil
+	}
+	data = data[filter.Offset:]
+	if len(data) > filter.Limit {
+		data = data[:filter.Limit]
+	}
+	return data, nil
+}
+
+// Disable отключает ссылку. Для уже отключенной ссылки обновляются причина и момент отключения.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - shortID: короткий идентификатор URL
+//   - reason: причина отключения
+//   - legal: отключение по требованию закона
+//
+// Возвращает:
+//   - *models.URL: обновленная запись
+//   - error: ошибка обновления (преобразованная через convertErrorType)
+func (u *URLRepo) Disable(ctx context.Context, shortID string, reason string, legal bool) (*models.URL, error) {
+	return u.update(ctx, shortID, func(m *models.URL, now time.Time) error {
+		m.DisabledAt = &now
+		m.DisabledReason = reason
+		m.DisabledLegal = legal
+		return nil
+	})
+}
+
+// Enable снимает отключение ссылки.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - shortID: короткий идентификатор URL
+//
+// Возвращает:
+//   - *models.URL: обновленная запись
+//   - error: ошибка обновления (преобразованная через convertErrorType)
+func (u *URLRepo) Enable(ctx context.Context, shortID string) (*models.URL, error) {
+	return u.update(ctx, shortID, func(m *models.URL, _ time.Time) error {
+		m.DisabledAt = nil
+		m.DisabledReason = ""
+		m.DisabledLegal = false
+		return nil
+	})
+}
+
+// DisableByVisitorUUID отключает все действующие ссылки посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//   - reason: причина отключения
+//
+// Возвращает:
+//   - int: количество отключенных записей
+//   - error: ошибка обновления (преобразованная через convertErrorType)
+func (u *URLRepo) DisableByVisitorUUID(ctx context.Context, visitorUUID string, reason string) (int, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	data, err := memory.FilterAll[models.URL](ctx, u.s.MStorage, func(val models.URL) bool {
+		return val.VisitorUUID == visitorUUID && val.DisabledAt == nil && val.DeletedAt == nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get records by visitor uuid %s: %w", visitorUUID, convertErrorType(err))
+	}
+
+	now := time.Now().UTC()
+	batchMap := make(map[string]*models.URL, len(data))
+	for i := range data {
+		data[i].DisabledAt = &now
+		data[i].DisabledReason = reason
+		data[i].DisabledLegal = false
+		data[i].UpdatedAt = now
+		batchMap[data[i].ShortIdentifier] = &data[i]
+	}
+	for _, re := range memory.BatchSet[models.URL](ctx, batchMap, u.s.MStorage, memory.WithOverwrite()) {
 		if re.Err != nil {
 			err = errors.Join(err, convertErrorType(re.Err))
 		}
 	}
-	return err
+	if err != nil {
+		return 0, err
+	}
+	return len(batchMap), nil
+}
+
+// GetAllByWorkspaceID получает все ссылки рабочего пространства, включая удаленные.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - workspaceID: идентификатор рабочего пространства
+//
+// Возвращает:
+//   - []models.URL: найденные записи в порядке создания
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (u *URLRepo) GetAllByWorkspaceID(ctx context.Context, workspaceID string) ([]models.URL, error) {
+	data, err := memory.FilterAll[models.URL](ctx, u.s.MStorage, func(val models.URL) bool {
+		return val.WorkspaceID == workspaceID
+	})
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to get records by workspace id %s: %w",
+			workspaceID, convertErrorType(err),
+		)
+	}
+	slices.SortFunc(data, func(a, b models.URL) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ShortIdentifier, b.ShortIdentifier))
+	})
+	return data, nil
+}
+
+// UpdateInWorkspace изменяет неудаленную ссылку рабочего пространства.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - workspaceID: идентификатор рабочего пространства
+//   - shortID: короткий идентификатор URL
+//   - upd: изменения
+//
+// Возвращает:
+//   - *models.URL: обновленная запись
+//   - error: repositories.ErrNotFound, если ссылки нет в пространстве,
+//     repositories.ErrDuplicateKey, если в пространстве уже есть ссылка на новый URL
+func (u *URLRepo) UpdateInWorkspace(
+	ctx context.Context,
+	workspaceID string,
+	shortID string,
+	upd repositories.URLUpdate,
+) (*models.URL, error) {
+	return u.update(ctx, shortID, func(m *models.URL, _ time.Time) error {
+		if m.WorkspaceID != workspaceID || m.DeletedAt != nil {
+			return repositories.ErrNotFound
+		}
+		if upd.URL != nil && *upd.URL != m.URL {
+			taken, err := memory.FilterAll[models.URL](ctx, u.s.MStorage, func(val models.URL) bool {
+				return val.WorkspaceID == workspaceID && val.URL == *upd.URL
+			})
+			if err != nil {
+				return convertErrorType(err)
+			}
+			if len(taken) > 0 {
+				return repositories.ErrDuplicateKey
+			}
+			m.URL = *upd.URL
+		}
+		if upd.Tags != nil {
+			m.Tags = *upd.Tags
+		}
+		return nil
+	})
+}
+
+// DeleteInWorkspace помечает удаленной ссылку рабочего пространства.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - workspaceID: идентификатор рабочего пространства
+//   - shortID: короткий идентификатор URL
+//
+// Возвращает:
+//   - *models.URL: удаленная запись
+//   - error: repositories.ErrNotFound, если неудаленной ссылки нет в пространстве
+func (u *URLRepo) DeleteInWorkspace(ctx context.Context, workspaceID string, shortID string) (*models.URL, error) {
+	return u.update(ctx, shortID, func(m *models.URL, now time.Time) error {
+		if m.WorkspaceID != workspaceID || m.DeletedAt != nil {
+			return repositories.ErrNotFound
+		}
+		m.DeletedAt = &now
+		return nil
+	})
+}
+
+// createInWorkspace создает ссылку рабочего пространства или возвращает существующую ссылку
+// пространства на тот же URL. Занятый другой ссылкой короткий идентификатор - repositories.ErrDuplicateKey.
+func (u *URLRepo) createInWorkspace(ctx context.Context, m *models.URL) (*models.URL, bool, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	existing, err := memory.FilterAll[models.URL](ctx, u.s.MStorage, func(val models.URL) bool {
+		return val.WorkspaceID == m.WorkspaceID && val.URL == m.URL
+	})
+	if err != nil {
+		return nil, false, convertErrorType(err)
+	}
+	if len(existing) > 0 {
+		return &existing[0], false, nil
+	}
+	if setErr := memory.Set[models.URL](ctx, m.ShortIdentifier, m, u.s.MStorage); setErr != nil {
+		return nil, false, fmt.Errorf("failed to create record: %w", convertErrorType(setErr))
+	}
+	return m, true, nil
+}
+
+// update изменяет запись под блокировкой репозитория и сохраняет её. Ошибка fn отменяет изменение.
+func (u *URLRepo) update(
+	ctx context.Context,
+	shortID string,
+	fn func(m *models.URL, now time.Time) error,
+) (*models.URL, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	m, err := u.GetByShortIdentifier(ctx, shortID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if fnErr := fn(m, now); fnErr != nil {
+		return nil, fnErr
+	}
+	m.UpdatedAt = now
+	if setErr := memory.Set[models.URL](ctx, shortID, m, u.s.MStorage, memory.WithOverwrite()); setErr != nil {
+		return nil, fmt.Errorf("failed to update record %s: %w", shortID, convertErrorType(setErr))
+	}
+	return m, nil
 }
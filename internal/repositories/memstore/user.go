@@ -0,0 +1,98 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/db/memory"
+	"github.com/fsdevblog/shorturl/internal/models"
+)
+
+// usersCollection имя коллекции in-memory хранилища для пользователей.
+const usersCollection = "users"
+
+// UserRepo представляет собой репозиторий пользователей в памяти.
+// Пользователи хранятся по адресу электронной почты, так как он уникален и нужен при входе.
+type UserRepo struct {
+	users *memory.MStorage
+	mu    sync.Mutex
+}
+
+// NewUserRepo создает новый экземпляр репозитория пользователей.
+//
+// Параметры:
+//   - store: экземпляр хранилища в памяти
+//
+// Возвращает:
+//   - *UserRepo: инициализированный репозиторий
+func NewUserRepo(store *db.MemoryStorage) *UserRepo {
+	return &UserRepo{users: store.Collection(usersCollection)}
+}
+
+// Create сохраняет нового пользователя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - u: данные пользователя
+//
+// Возвращает:
+//   - *models.User: созданная запись
+//   - error: repositories.ErrDuplicateKey, если адрес или идентификатор заняты (преобразованная через convertErrorType)
+func (r *UserRepo) Create(ctx context.Context, u *models.User) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Идентификатор, как и в sql.UserRepo, должен быть уникален.
+	if _, err := r.GetByID(ctx, u.ID); err == nil {
+		return nil, fmt.Errorf("failed to create user %s: %w", u.ID, convertErrorType(memory.ErrDuplicateKey))
+	}
+
+	m := *u
+	m.CreatedAt = time.Now().UTC()
+	if err := memory.Set[models.User](ctx, m.Email, &m, r.users); err != nil {
+		return nil, fmt.Errorf("failed to create user %s: %w", m.ID, convertErrorType(err))
+	}
+	return &m, nil
+}
+
+// GetByEmail получает пользователя по адресу электронной почты.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - email: адрес в нижнем регистре
+//
+// Возвращает:
+//   - *models.User: найденная запись
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (r *UserRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	u, err := memory.Get[models.User](ctx, email, r.users)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by email: %w", convertErrorType(err))
+	}
+	return u, nil
+}
+
+// GetByID получает пользователя по идентификатору.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - id: идентификатор пользователя
+//
+// Возвращает:
+//   - *models.User: найденная запись
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (r *UserRepo) GetByID(ctx context.Context, id string) (*models.User, error) {
+	found, err := memory.FilterAll[models.User](ctx, r.users, func(u models.User) bool {
+		return u.ID == id
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user %s: %w", id, convertErrorType(err))
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("failed to get user %s: %w", id, convertErrorType(memory.ErrNotFound))
+	}
+	return &found[0], nil
+}
@@ -0,0 +1,81 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/db/memory"
+	"github.com/fsdevblog/shorturl/internal/models"
+)
+
+// visitorBansCollection имя коллекции in-memory хранилища для блокировок посетителей.
+const visitorBansCollection = "visitor_bans"
+
+// VisitorBanRepo представляет собой репозиторий блокировок посетителей в памяти.
+// Блокировки хранятся по UUID посетителя.
+type VisitorBanRepo struct {
+	bans *memory.MStorage
+}
+
+// NewVisitorBanRepo создает новый экземпляр репозитория блокировок посетителей.
+//
+// Параметры:
+//   - store: экземпляр хранилища в памяти
+//
+// Возвращает:
+//   - *VisitorBanRepo: инициализированный репозиторий
+func NewVisitorBanRepo(store *db.MemoryStorage) *VisitorBanRepo {
+	return &VisitorBanRepo{bans: store.Collection(visitorBansCollection)}
+}
+
+// Create сохраняет блокировку посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - b: данные блокировки
+//
+// Возвращает:
+//   - *models.VisitorBan: созданная запись
+//   - error: repositories.ErrDuplicateKey, если посетитель уже заблокирован (преобразованная через convertErrorType)
+func (r *VisitorBanRepo) Create(ctx context.Context, b *models.VisitorBan) (*models.VisitorBan, error) {
+	m := *b
+	m.CreatedAt = time.Now().UTC()
+	if err := memory.Set[models.VisitorBan](ctx, m.VisitorUUID, &m, r.bans); err != nil {
+		return nil, fmt.Errorf("failed to ban visitor %s: %w", m.VisitorUUID, convertErrorType(err))
+	}
+	return &m, nil
+}
+
+// Get получает блокировку посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - *models.VisitorBan: найденная запись
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (r *VisitorBanRepo) Get(ctx context.Context, visitorUUID string) (*models.VisitorBan, error) {
+	b, err := memory.Get[models.VisitorBan](ctx, visitorUUID, r.bans)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ban of visitor %s: %w", visitorUUID, convertErrorType(err))
+	}
+	return b, nil
+}
+
+// Delete снимает блокировку посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - error: repositories.ErrNotFound, если посетитель не заблокирован (преобразованная через convertErrorType)
+func (r *VisitorBanRepo) Delete(ctx context.Context, visitorUUID string) error {
+	if err := r.bans.Delete(ctx, visitorUUID); err != nil {
+		return fmt.Errorf("failed to unban visitor %s: %w", visitorUUID, convertErrorType(err))
+	}
+	return nil
+}
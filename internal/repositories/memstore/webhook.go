@@ -0,0 +1,368 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/db/memory"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+)
+
+// Имена коллекций in-memory хранилища для вебхуков.
+const (
+	webhooksCollection          = "webhooks"
+	webhookDeliveriesCollection = "webhook_deliveries"
+	webhookJobsCollection       = "webhook_jobs"
+)
+
+// WebhookRepo представляет собой репозиторий для работы с вебхуками в памяти.
+type WebhookRepo struct {
+	mu         sync.Mutex // Делает захват ожидающих доставок атомарным
+	hooks      *memory.MStorage
+	deliveries *memory.MStorage
+	jobs       *memory.MStorage
+}
+
+// NewWebhookRepo создает новый экземпляр репозитория вебхуков.
+//
+// Параметры:
+//   - store: экземпляр хранилища в памяти
+//
+// Возвращает:
+//   - *WebhookRepo: инициализированный репозиторий
+func NewWebhookRepo(store *db.MemoryStorage) *WebhookRepo {
+	return &WebhookRepo{
+		hooks:      store.Collection(webhooksCollection),
+		deliveries: store.Collection(webhookDeliveriesCollection),
+		jobs:       store.Collection(webhookJobsCollection),
+	}
+}
+
+// Create создает новый вебхук.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - w: данные вебхука
+//
+// Возвращает:
+//   - *models.Webhook: созданная запись
+//   - error: ошибка создания (преобразованная через convertErrorType)
+func (r *WebhookRepo) Create(ctx context.Context, w *models.Webhook) (*models.Webhook, error) {
+	m := *w
+	m.CreatedAt = time.Now().UTC()
+	if err := memory.Set[models.Webhook](ctx, m.ID, &m, r.hooks); err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", convertErrorType(err))
+	}
+	return &m, nil
+}
+
+// GetAllByVisitorUUID получает все вебхуки посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - []models.Webhook: найденные записи
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (r *WebhookRepo) GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.Webhook, error) {
+	hooks, err := memory.FilterAll[models.Webhook](ctx, r.hooks, func(w models.Webhook) bool {
+		return w.VisitorUUID == visitorUUID
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks by visitor uuid %s: %w", visitorUUID, convertErrorType(err))
+	}
+	slices.SortFunc(hooks, func(a, b models.Webhook) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return hooks, nil
+}
+
+// GetByIDVisitorUUID получает вебхук посетителя по идентификатору.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - id: идентификатор вебхука
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - *models.Webhook: найденная запись
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (r *WebhookRepo) GetByIDVisitorUUID(ctx context.Context, id string, visitorUUID string) (*models.Webhook, error) {
+	w, err := memory.Get[models.Webhook](ctx, id, r.hooks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook %s: %w", id, convertErrorType(err))
+	}
+	if w.VisitorUUID != visitorUUID {
+		return nil, fmt.Errorf("failed to get webhook %s: %w", id, convertErrorType(memory.ErrNotFound))
+	}
+	return w, nil
+}
+
+// Delete удаляет вебхук посетителя вместе с историей и ожидающими доставками.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - id: идентификатор вебхука
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - error: repositories.ErrNotFound если вебхук не найден
+func (r *WebhookRepo) Delete(ctx context.Context, id string, visitorUUID string) error {
+	if _, err := r.GetByIDVisitorUUID(ctx, id, visitorUUID); err != nil {
+		return err
+	}
+	if err := r.hooks.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete webhook %s: %w", id, convertErrorType(err))
+	}
+
+	deliveries, err := memory.FilterAll[models.WebhookDelivery](ctx, r.deliveries, func(d models.WebhookDelivery) bool {
+		return d.WebhookID == id
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get webhook %s deliveries: %w", id, convertErrorType(err))
+	}
+	for _, d := range deliveries {
+		if delErr := r.deliveries.Delete(ctx, d.ID); delErr != nil {
+			return fmt.Errorf("failed to delete webhook delivery %s: %w", d.ID, convertErrorType(delErr))
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	jobs, err := memory.FilterAll[models.WebhookJob](ctx, r.jobs, func(j models.WebhookJob) bool {
+		return j.WebhookID == id
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get webhook %s jobs: %w", id, convertErrorType(err))
+	}
+	for _, j := range jobs {
+		if delErr := r.jobs.Delete(ctx, j.ID); delErr != nil {
+			return fmt.Errorf("failed to delete webhook job %s: %w", j.ID, convertErrorType(delErr))
+		}
+	}
+	return nil
+}
+
+// CreateDelivery сохраняет попытку доставки события.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - d: данные попытки доставки
+//
+// Возвращает:
+//   - error: ошибка сохранения (преобразованная через convertErrorType)
+func (r *WebhookRepo) CreateDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	m := *d
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now().UTC()
+	}
+	if err := memory.Set[models.WebhookDelivery](ctx, m.ID, &m, r.deliveries); err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", convertErrorType(err))
+	}
+	return nil
+}
+
+// GetDeliveriesByWebhookID получает последние попытки доставки для вебхука.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - webhookID: идентификатор вебхука
+//   - limit: максимальное количество записей
+//
+// Возвращает:
+//   - []models.WebhookDelivery: попытки доставки, от новых к старым
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (r *WebhookRepo) GetDeliveriesByWebhookID(
+	ctx context.Context,
+	webhookID string,
+	limit int,
+) ([]models.WebhookDelivery, error) {
+	deliveries, err := memory.FilterAll[models.WebhookDelivery](ctx, r.deliveries, func(d models.WebhookDelivery) bool {
+		return d.WebhookID == webhookID
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook %s deliveries: %w", webhookID, convertErrorType(err))
+	}
+	slices.SortFunc(deliveries, func(a, b models.WebhookDelivery) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// DeleteDeliveriesBefore удаляет попытки доставки, созданные раньше before.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - before: момент времени
+//
+// Возвращает:
+//   - int64: количество удаленных записей
+//   - error: ошибка удаления (преобразованная через convertErrorType)
+func (r *WebhookRepo) DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	old, err := memory.FilterAll[models.WebhookDelivery](ctx, r.deliveries, func(d models.WebhookDelivery) bool {
+		return d.CreatedAt.Before(before)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get old webhook deliveries: %w", convertErrorType(err))
+	}
+	for _, d := range old {
+		if delErr := r.deliveries.Delete(ctx, d.ID); delErr != nil {
+			return 0, fmt.Errorf("failed to delete webhook delivery: %w", convertErrorType(delErr))
+		}
+	}
+	return int64(len(old)), nil
+}
+
+// GetVisitorUUIDs получает идентификаторы посетителей, у которых есть вебхуки.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//
+// Возвращает:
+//   - []string: идентификаторы посетителей
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (r *WebhookRepo) GetVisitorUUIDs(ctx context.Context) ([]string, error) {
+	hooks, err := memory.FilterAll[models.Webhook](ctx, r.hooks, func(models.Webhook) bool { return true })
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %w", convertErrorType(err))
+	}
+	visitors := make([]string, 0, len(hooks))
+	for _, h := range hooks {
+		visitors = append(visitors, h.VisitorUUID)
+	}
+	slices.Sort(visitors)
+	return slices.Compact(visitors), nil
+}
+
+// CreateJobs сохраняет ожидающие доставки.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - jobs: ожидающие доставки
+//
+// Возвращает:
+//   - error: ошибка сохранения (преобразованная через convertErrorType)
+func (r *WebhookRepo) CreateJobs(ctx context.Context, jobs []models.WebhookJob) error {
+	for _, j := range jobs {
+		m := j
+		if m.CreatedAt.IsZero() {
+			m.CreatedAt = time.Now().UTC()
+		}
+		if err := memory.Set[models.WebhookJob](ctx, m.ID, &m, r.jobs); err != nil {
+			return fmt.Errorf("failed to create webhook job: %w", convertErrorType(err))
+		}
+	}
+	return nil
+}
+
+// ClaimDueJobs захватывает ожидающие доставки, время попытки которых наступило, и откладывает
+// их следующую попытку на lease. Если доставщик не завершит попытку, доставка снова станет
+// доступной по истечении lease.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - now: текущее время
+//   - lease: на сколько откладывается следующая попытка захваченных доставок
+//   - limit: максимальное количество записей
+//
+// Возвращает:
+//   - []repositories.WebhookJobClaim: захваченные доставки вместе с вебхуками
+//   - error: ошибка захвата (преобразованная через convertErrorType)
+func (r *WebhookRepo) ClaimDueJobs(
+	ctx context.Context,
+	now time.Time,
+	lease time.Duration,
+	limit int,
+) ([]repositories.WebhookJobClaim, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	due, err := memory.FilterAll[models.WebhookJob](ctx, r.jobs, func(j models.WebhookJob) bool {
+		return !j.NextAttemptAt.After(now)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due webhook jobs: %w", convertErrorType(err))
+	}
+	slices.SortFunc(due, func(a, b models.WebhookJob) int {
+		return a.NextAttemptAt.Compare(b.NextAttemptAt)
+	})
+
+	var claims []repositories.WebhookJobClaim
+	for _, j := range due {
+		if len(claims) == limit {
+			break
+		}
+		hook, getErr := memory.Get[models.Webhook](ctx, j.WebhookID, r.hooks)
+		if getErr != nil {
+			if errors.Is(getErr, memory.ErrNotFound) {
+				// Доставка создана одновременно с удалением вебхука.
+				if delErr := r.jobs.Delete(ctx, j.ID); delErr != nil && !errors.Is(delErr, memory.ErrNotFound) {
+					return nil, fmt.Errorf("failed to delete webhook job %s: %w", j.ID, convertErrorType(delErr))
+				}
+				continue
+			}
+			return nil, fmt.Errorf("failed to get webhook %s: %w", j.WebhookID, convertErrorType(getErr))
+		}
+		j.NextAttemptAt = now.Add(lease)
+		if setErr := memory.Set[models.WebhookJob](ctx, j.ID, &j, r.jobs, memory.WithOverwrite()); setErr != nil {
+			return nil, fmt.Errorf("failed to claim webhook job %s: %w", j.ID, convertErrorType(setErr))
+		}
+		claims = append(claims, repositories.WebhookJobClaim{Job: j, Webhook: *hook})
+	}
+	return claims, nil
+}
+
+// RescheduleJob сохраняет количество выполненных попыток доставки и время следующей попытки.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - id: идентификатор ожидающей доставки
+//   - attempt: количество выполненных попыток
+//   - nextAttemptAt: время следующей попытки
+//
+// Возвращает:
+//   - error: repositories.ErrNotFound если доставка не найдена (например, вебхук удален)
+func (r *WebhookRepo) RescheduleJob(ctx context.Context, id string, attempt int, nextAttemptAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	j, err := memory.Get[models.WebhookJob](ctx, id, r.jobs)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook job %s: %w", id, convertErrorType(err))
+	}
+	j.Attempt = attempt
+	j.NextAttemptAt = nextAttemptAt
+	if err = memory.Set[models.WebhookJob](ctx, id, j, r.jobs, memory.WithOverwrite()); err != nil {
+		return fmt.Errorf("failed to reschedule webhook job %s: %w", id, convertErrorType(err))
+	}
+	return nil
+}
+
+// DeleteJob удаляет ожидающую доставку после успеха или последней попытки.
+// Отсутствие записи (например, вебхук уже удален) ошибкой не считается.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - id: идентификатор ожидающей доставки
+//
+// Возвращает:
+//   - error: ошибка удаления (преобразованная через convertErrorType)
+func (r *WebhookRepo) DeleteJob(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.jobs.Delete(ctx, id); err != nil && !errors.Is(err, memory.ErrNotFound) {
+		return fmt.Errorf("failed to delete webhook job %s: %w", id, convertErrorType(err))
+	}
+	return nil
+}
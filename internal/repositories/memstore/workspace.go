@@ -0,0 +1,250 @@
+package memstore
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/db/memory"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+)
+
+// Имена коллекций in-memory хранилища для рабочих пространств.
+const (
+	workspacesCollection       = "workspaces"
+	workspaceMembersCollection = "workspace_members"
+)
+
+// WorkspaceRepo представляет собой репозиторий рабочих пространств и их участников в памяти.
+// Пространства хранятся по идентификатору, участники - по паре (пространство, посетитель).
+type WorkspaceRepo struct {
+	workspaces *memory.MStorage
+	members    *memory.MStorage
+	// Упорядочивает изменения участников, чтобы проверка последнего владельца и запись были атомарны.
+	mu sync.Mutex
+}
+
+// NewWorkspaceRepo создает новый экземпляр репозитория рабочих пространств.
+//
+// Параметры:
+//   - store: экземпляр хранилища в памяти
+//
+// Возвращает:
+//   - *WorkspaceRepo: инициализированный репозиторий
+func NewWorkspaceRepo(store *db.MemoryStorage) *WorkspaceRepo {
+	return &WorkspaceRepo{
+		workspaces: store.Collection(workspacesCollection),
+		members:    store.Collection(workspaceMembersCollection),
+	}
+}
+
+// Create сохраняет рабочее пространство и делает его создателя владельцем.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - w: данные рабочего пространства
+//
+// Возвращает:
+//   - *models.Workspace: созданная запись
+//   - error: ошибка создания (преобразованная через convertErrorType)
+func (r *WorkspaceRepo) Create(ctx context.Context, w *models.Workspace) (*models.Workspace, error) {
+	m := *w
+	m.CreatedAt = time.Now().UTC()
+	if err := memory.Set[models.Workspace](ctx, m.ID, &m, r.workspaces); err != nil {
+		return nil, fmt.Errorf("failed to create workspace %s: %w", m.ID, convertErrorType(err))
+	}
+	owner := models.WorkspaceMember{WorkspaceID: m.ID, VisitorUUID: m.CreatedBy, Role: models.WorkspaceRoleOwner}
+	if _, err := r.SetMember(ctx, &owner); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Get получает рабочее пространство.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - id: идентификатор рабочего пространства
+//
+// Возвращает:
+//   - *models.Workspace: найденная запись
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (r *WorkspaceRepo) Get(ctx context.Context, id string) (*models.Workspace, error) {
+	w, err := memory.Get[models.Workspace](ctx, id, r.workspaces)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace %s: %w", id, convertErrorType(err))
+	}
+	return w, nil
+}
+
+// ListByMember получает рабочие пространства, в которых состоит посетитель, вместе с его ролью.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - []models.WorkspaceMembership: пространства в порядке создания
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (r *WorkspaceRepo) ListByMember(ctx context.Context, visitorUUID string) ([]models.WorkspaceMembership, error) {
+	members, err := memory.FilterAll[models.WorkspaceMember](ctx, r.members, func(m models.WorkspaceMember) bool {
+		return m.VisitorUUID == visitorUUID
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspaces of visitor %s: %w", visitorUUID, convertErrorType(err))
+	}
+	list := make([]models.WorkspaceMembership, 0, len(members))
+	for _, m := range members {
+		w, getErr := r.Get(ctx, m.WorkspaceID)
+		if getErr != nil {
+			return nil, getErr
+		}
+		list = append(list, models.WorkspaceMembership{Workspace: *w, Role: m.Role})
+	}
+	slices.SortFunc(list, func(a, b models.WorkspaceMembership) int {
+		return cmp.Or(a.Workspace.CreatedAt.Compare(b.Workspace.CreatedAt), cmp.Compare(a.Workspace.ID, b.Workspace.ID))
+	})
+	return list, nil
+}
+
+// GetMember получает участника рабочего пространства.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - workspaceID: идентификатор рабочего пространства
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - *models.WorkspaceMember: найденная запись
+//   - error: repositories.ErrNotFound, если посетитель не участник (преобразованная через convertErrorType)
+func (r *WorkspaceRepo) GetMember(
+	ctx context.Context,
+	workspaceID string,
+	visitorUUID string,
+) (*models.WorkspaceMember, error) {
+	m, err := memory.Get[models.WorkspaceMember](ctx, workspaceMemberKey(workspaceID, visitorUUID), r.members)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to get member %s of workspace %s: %w",
+			visitorUUID, workspaceID, convertErrorType(err),
+		)
+	}
+	return m, nil
+}
+
+// ListMembers получает участников рабочего пространства.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - workspaceID: идентификатор рабочего пространства
+//
+// Возвращает:
+//   - []models.WorkspaceMember: участники в порядке добавления
+//   - error: ошибка поиска (преобразованная через convertErrorType)
+func (r *WorkspaceRepo) ListMembers(ctx context.Context, workspaceID string) ([]models.WorkspaceMember, error) {
+	members, err := memory.FilterAll[models.WorkspaceMember](ctx, r.members, func(m models.WorkspaceMember) bool {
+		return m.WorkspaceID == workspaceID
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get members of workspace %s: %w", workspaceID, convertErrorType(err))
+	}
+	slices.SortFunc(members, func(a, b models.WorkspaceMember) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.VisitorUUID, b.VisitorUUID))
+	})
+	return members, nil
+}
+
+// SetMember добавляет участника рабочего пространства или меняет роль существующего.
+// Последнего владельца понизить нельзя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - member: участник и его роль
+//
+// Возвращает:
+//   - *models.WorkspaceMember: сохраненная запись
+//   - error: repositories.ErrLastOwner при понижении последнего владельца,
+//     ошибка сохранения (преобразованная через convertErrorType)
+func (r *WorkspaceRepo) SetMember(
+	ctx context.Context,
+	member *models.WorkspaceMember,
+) (*models.WorkspaceMember, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m := *member
+	m.CreatedAt = time.Now().UTC()
+	if existing, err := r.GetMember(ctx, m.WorkspaceID, m.VisitorUUID); err == nil {
+		m.CreatedAt = existing.CreatedAt
+		if existing.Role == models.WorkspaceRoleOwner && m.Role != models.WorkspaceRoleOwner {
+			if ownerErr := r.keepOwner(ctx, m.WorkspaceID, m.VisitorUUID); ownerErr != nil {
+				return nil, ownerErr
+			}
+		}
+	}
+	key := workspaceMemberKey(m.WorkspaceID, m.VisitorUUID)
+	if err := memory.Set[models.WorkspaceMember](ctx, key, &m, r.members, memory.WithOverwrite()); err != nil {
+		return nil, fmt.Errorf(
+			"failed to set member %s of workspace %s: %w",
+			m.VisitorUUID, m.WorkspaceID, convertErrorType(err),
+		)
+	}
+	return &m, nil
+}
+
+// DeleteMember удаляет участника рабочего пространства. Последнего владельца удалить нельзя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - workspaceID: идентификатор рабочего пространства
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - error: repositories.ErrNotFound, если посетитель не участник (преобразованная через convertErrorType),
+//     repositories.ErrLastOwner при удалении последнего владельца
+func (r *WorkspaceRepo) DeleteMember(ctx context.Context, workspaceID string, visitorUUID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, err := r.GetMember(ctx, workspaceID, visitorUUID)
+	if err != nil {
+		return err
+	}
+	if existing.Role == models.WorkspaceRoleOwner {
+		if ownerErr := r.keepOwner(ctx, workspaceID, visitorUUID); ownerErr != nil {
+			return ownerErr
+		}
+	}
+	if err = r.members.Delete(ctx, workspaceMemberKey(workspaceID, visitorUUID)); err != nil {
+		return fmt.Errorf(
+			"failed to delete member %s of workspace %s: %w",
+			visitorUUID, workspaceID, convertErrorType(err),
+		)
+	}
+	return nil
+}
+
+// keepOwner проверяет, что кроме владельца visitorUUID в пространстве есть другой владелец.
+// Вызывается под r.mu.
+func (r *WorkspaceRepo) keepOwner(ctx context.Context, workspaceID string, visitorUUID string) error {
+	owners, err := memory.FilterAll[models.WorkspaceMember](ctx, r.members, func(m models.WorkspaceMember) bool {
+		return m.WorkspaceID == workspaceID && m.VisitorUUID != visitorUUID && m.Role == models.WorkspaceRoleOwner
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get owners of workspace %s: %w", workspaceID, convertErrorType(err))
+	}
+	if len(owners) == 0 {
+		return fmt.Errorf("%w: member %s of workspace %s", repositories.ErrLastOwner, visitorUUID, workspaceID)
+	}
+	return nil
+}
+
+// workspaceMemberKey возвращает ключ участника в коллекции участников.
+func workspaceMemberKey(workspaceID string, visitorUUID string) string {
+	return workspaceID + "/" + visitorUUID
+}
@@ -0,0 +1,132 @@
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// APIKeyRepo представляет собой репозиторий ключей API в PostgreSQL.
+type APIKeyRepo struct {
+	conn *pgxpool.Pool
+}
+
+// NewAPIKeyRepo создает новый экземпляр репозитория ключей API.
+//
+// Параметры:
+//   - conn: пул подключений к PostgreSQL
+//
+// Возвращает:
+//   - *APIKeyRepo: инициализированный репозиторий
+func NewAPIKeyRepo(conn *pgxpool.Pool) *APIKeyRepo {
+	return &APIKeyRepo{conn: conn}
+}
+
+const createAPIKeyQuery = `-- createAPIKey
+INSERT INTO api_keys (id, visitor_uuid, name, prefix, hash) VALUES ($1, $2, $3, $4, $5)
+RETURNING created_at;
+`
+
+// Create сохраняет новый ключ API.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - k: данные ключа
+//
+// Возвращает:
+//   - *models.APIKey: созданная запись
+//   - error: ошибка создания (преобразованная через convertErrType)
+func (r *APIKeyRepo) Create(ctx context.Context, k *models.APIKey) (*models.APIKey, error) {
+	m := *k
+	err := r.conn.QueryRow(ctx, createAPIKeyQuery, m.ID, m.VisitorUUID, m.Name, m.Prefix, m.Hash).Scan(&m.CreatedAt)
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return &m, nil
+}
+
+const getAPIKeysByVisitorUUIDQuery = `-- getAPIKeysByVisitorUUID
+SELECT id, created_at, visitor_uuid, name, prefix, hash, revoked_at
+FROM api_keys WHERE visitor_uuid = $1 ORDER BY created_at;
+`
+
+// GetAllByVisitorUUID получает все ключи посетителя, включая отозванные.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - []models.APIKey: найденные записи в порядке создания
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (r *APIKeyRepo) GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.APIKey, error) {
+	rows, qErr := r.conn.Query(ctx, getAPIKeysByVisitorUUIDQuery, visitorUUID)
+	if qErr != nil {
+		return nil, convertErrType(qErr)
+	}
+	keys, err := pgx.CollectRows(rows, scanAPIKey)
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return keys, nil
+}
+
+const getAPIKeyByPrefixQuery = `-- getAPIKeyByPrefix
+SELECT id, created_at, visitor_uuid, name, prefix, hash, revoked_at FROM api_keys WHERE prefix = $1;
+`
+
+// GetByPrefix получает ключ по его открытому префиксу.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - prefix: открытый префикс ключа
+//
+// Возвращает:
+//   - *models.APIKey: найденная запись
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (r *APIKeyRepo) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	rows, qErr := r.conn.Query(ctx, getAPIKeyByPrefixQuery, prefix)
+	if qErr != nil {
+		return nil, convertErrType(qErr)
+	}
+	k, err := pgx.CollectExactlyOneRow(rows, scanAPIKey)
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return &k, nil
+}
+
+const revokeAPIKeyQuery = `-- revokeAPIKey
+UPDATE api_keys SET revoked_at = $3 WHERE id = $1 AND visitor_uuid = $2 AND revoked_at IS NULL;
+`
+
+// Revoke отзывает действующий ключ посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - id: идентификатор ключа
+//   - visitorUUID: идентификатор посетителя
+//   - at: момент отзыва
+//
+// Возвращает:
+//   - error: repositories.ErrNotFound, если действующего ключа нет
+func (r *APIKeyRepo) Revoke(ctx context.Context, id string, visitorUUID string, at time.Time) error {
+	tag, err := r.conn.Exec(ctx, revokeAPIKeyQuery, id, visitorUUID, at)
+	if err != nil {
+		return convertErrType(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return convertErrType(pgx.ErrNoRows)
+	}
+	return nil
+}
+
+// scanAPIKey читает ключ API из строки результата.
+func scanAPIKey(row pgx.CollectableRow) (models.APIKey, error) {
+	var k models.APIKey
+	err := row.Scan(&k.ID, &k.CreatedAt, &k.VisitorUUID, &k.Name, &k.Prefix, &k.Hash, &k.RevokedAt)
+	return k, err //nolint:wrapcheck
+}
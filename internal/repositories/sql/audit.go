@@ -0,0 +1,81 @@
+package sql
+
+import (
+	"context"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AuditRepo представляет собой репозиторий журнала аудита в PostgreSQL.
+type AuditRepo struct {
+	conn *pgxpool.Pool
+}
+
+// NewAuditRepo создает новый экземпляр репозитория журнала аудита.
+//
+// Параметры:
+//   - conn: пул подключений к PostgreSQL
+//
+// Возвращает:
+//   - *AuditRepo: инициализированный репозиторий
+func NewAuditRepo(conn *pgxpool.Pool) *AuditRepo {
+	return &AuditRepo{conn: conn}
+}
+
+const createAuditEntryQuery = `-- createAuditEntry
+INSERT INTO audit_log (id, actor_uuid, action, target, reason) VALUES ($1, $2, $3, $4, $5) RETURNING created_at;
+`
+
+// Create добавляет запись в журнал.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - e: данные записи
+//
+// Возвращает:
+//   - *models.AuditEntry: созданная запись
+//   - error: ошибка создания (преобразованная через convertErrType)
+func (r *AuditRepo) Create(ctx context.Context, e *models.AuditEntry) (*models.AuditEntry, error) {
+	m := *e
+	err := r.conn.QueryRow(ctx, createAuditEntryQuery, m.ID, m.ActorUUID, m.Action, m.Target, m.Reason).
+		Scan(&m.CreatedAt)
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return &m, nil
+}
+
+const listAuditEntriesQuery = `-- listAuditEntries
+SELECT id, created_at, actor_uuid, action, target, reason FROM audit_log
+WHERE $1 = '' OR target = $1
+ORDER BY created_at DESC, id
+LIMIT $2;
+`
+
+// List получает записи журнала от новых к старым.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - filter: условия выборки
+//
+// Возвращает:
+//   - []models.AuditEntry: найденные записи
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (r *AuditRepo) List(ctx context.Context, filter repositories.AuditFilter) ([]models.AuditEntry, error) {
+	rows, qErr := r.conn.Query(ctx, listAuditEntriesQuery, filter.Target, filter.Limit)
+	if qErr != nil {
+		return nil, convertErrType(qErr)
+	}
+	entries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.AuditEntry, error) {
+		var e models.AuditEntry
+		err := row.Scan(&e.ID, &e.CreatedAt, &e.ActorUUID, &e.Action, &e.Target, &e.Reason)
+		return e, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return entries, nil
+}
@@ -2,6 +2,7 @@
 //
 // Все методы репозитория преобразуют ошибки PostgreSQL в общие ошибки уровня репозитория
 // с помощью convertErrType:
+//   - pgx.ErrNoRows -> repositories.ErrNotFound
 //   - uniqueViolationCode (23505) -> repositories.ErrDuplicateKey
 //   - другие ошибки -> repositories.ErrUnknown
 package sql
@@ -6,6 +6,7 @@ import (
 
 	"github.com/fsdevblog/shorturl/internal/repositories"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
@@ -22,6 +23,7 @@ const (
 //   - error: преобразованная ошибка или nil, если входная ошибка nil
 //
 // Преобразования ошибок:
+//   - pgx.ErrNoRows -> repositories.ErrNotFound
 //   - uniqueViolationCode (23505) -> repositories.ErrDuplicateKey
 //   - другие ошибки -> repositories.ErrUnknown
 func convertErrType(err error) error {
@@ -29,6 +31,10 @@ func convertErrType(err error) error {
 		return nil
 	}
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: %s", repositories.ErrNotFound, err.Error())
+	}
+
 	var pgErr *pgconn.PgError
 	if errors.As(err, &pgErr) {
 		errType := repositories.ErrUnknown
@@ -0,0 +1,151 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// IdempotencyRepo представляет собой репозиторий ключей идемпотентности в PostgreSQL.
+type IdempotencyRepo struct {
+	conn *pgxpool.Pool
+}
+
+// NewIdempotencyRepo создает новый экземпляр репозитория ключей идемпотентности.
+//
+// Параметры:
+//   - conn: пул подключений к PostgreSQL
+//
+// Возвращает:
+//   - *IdempotencyRepo: инициализированный репозиторий
+func NewIdempotencyRepo(conn *pgxpool.Pool) *IdempotencyRepo {
+	return &IdempotencyRepo{conn: conn}
+}
+
+const reserveIdempotencyKeyQuery = `-- reserveIdempotencyKey
+INSERT INTO idempotency_keys (visitor_uuid, key, fingerprint, expires_at)
+	VALUES ($1, $2, $3, $4)
+ON CONFLICT (visitor_uuid, key) DO UPDATE SET
+	fingerprint = EXCLUDED.fingerprint,
+	status_code = 0,
+	content_type = '',
+	body = NULL,
+	created_at = NOW(),
+	expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= NOW()
+RETURNING created_at;
+`
+
+const getIdempotencyKeyQuery = `-- getIdempotencyKey
+SELECT visitor_uuid, key, fingerprint, status_code, content_type, body, created_at, expires_at
+FROM idempotency_keys WHERE visitor_uuid = $1 AND key = $2;
+`
+
+// Reserve сохраняет запись об обрабатываемом запросе, если для ключа посетителя нет неистекшей записи.
+// Истекшая запись заменяется одним запросом.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - r: запись с ключом, отпечатком и сроком действия
+//
+// Возвращает:
+//   - *models.IdempotencyRecord: сохраненная или уже существующая запись
+//   - bool: true, если запись сохранена
+//   - error: ошибка сохранения (преобразованная через convertErrType)
+func (i *IdempotencyRepo) Reserve(
+	ctx context.Context,
+	r *models.IdempotencyRecord,
+) (*models.IdempotencyRecord, bool, error) {
+	m := *r
+	row := i.conn.QueryRow(ctx, reserveIdempotencyKeyQuery, m.VisitorUUID, m.Key, m.Fingerprint, m.ExpiresAt)
+	err := row.Scan(&m.CreatedAt)
+	if err == nil {
+		return &m, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, convertErrType(err)
+	}
+
+	var existing models.IdempotencyRecord
+	err = i.conn.QueryRow(ctx, getIdempotencyKeyQuery, m.VisitorUUID, m.Key).Scan(
+		&existing.VisitorUUID,
+		&existing.Key,
+		&existing.Fingerprint,
+		&existing.StatusCode,
+		&existing.ContentType,
+		&existing.Body,
+		&existing.CreatedAt,
+		&existing.ExpiresAt,
+	)
+	if err != nil {
+		return nil, false, convertErrType(err)
+	}
+	return &existing, false, nil
+}
+
+const completeIdempotencyKeyQuery = `-- completeIdempotencyKey
+UPDATE idempotency_keys SET status_code = $3, content_type = $4, body = $5, expires_at = $6
+WHERE visitor_uuid = $1 AND key = $2;
+`
+
+// Complete сохраняет ответ на запрос с ключом посетителя и продлевает запись до r.ExpiresAt.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - r: запись с ключом, ответом и сроком хранения ответа
+//
+// Возвращает:
+//   - error: ошибка сохранения (преобразованная через convertErrType)
+func (i *IdempotencyRepo) Complete(ctx context.Context, r *models.IdempotencyRecord) error {
+	_, err := i.conn.Exec(ctx, completeIdempotencyKeyQuery,
+		r.VisitorUUID, r.Key, r.StatusCode, r.ContentType, r.Body, r.ExpiresAt)
+	if err != nil {
+		return convertErrType(err)
+	}
+	return nil
+}
+
+const deleteIdempotencyKeyQuery = `-- deleteIdempotencyKey
+DELETE FROM idempotency_keys WHERE visitor_uuid = $1 AND key = $2;
+`
+
+// Delete удаляет запись ключа посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//   - key: ключ идемпотентности
+//
+// Возвращает:
+//   - error: ошибка удаления (преобразованная через convertErrType)
+func (i *IdempotencyRepo) Delete(ctx context.Context, visitorUUID string, key string) error {
+	if _, err := i.conn.Exec(ctx, deleteIdempotencyKeyQuery, visitorUUID, key); err != nil {
+		return convertErrType(err)
+	}
+	return nil
+}
+
+const deleteExpiredIdempotencyKeysQuery = `-- deleteExpiredIdempotencyKeys
+DELETE FROM idempotency_keys WHERE expires_at <= $1;
+`
+
+// DeleteExpired удаляет записи, срок действия которых истек к моменту before.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - before: момент времени
+//
+// Возвращает:
+//   - int64: количество удаленных записей
+//   - error: ошибка удаления (преобразованная через convertErrType)
+func (i *IdempotencyRepo) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := i.conn.Exec(ctx, deleteExpiredIdempotencyKeysQuery, before)
+	if err != nil {
+		return 0, convertErrType(err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,99 @@
+package sql
+
+import (
+	"context"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OIDCIdentityRepo представляет собой репозиторий учетных записей OpenID Connect в PostgreSQL.
+type OIDCIdentityRepo struct {
+	conn *pgxpool.Pool
+}
+
+// NewOIDCIdentityRepo создает новый экземпляр репозитория учетных записей OpenID Connect.
+//
+// Параметры:
+//   - conn: пул подключений к PostgreSQL
+//
+// Возвращает:
+//   - *OIDCIdentityRepo: инициализированный репозиторий
+func NewOIDCIdentityRepo(conn *pgxpool.Pool) *OIDCIdentityRepo {
+	return &OIDCIdentityRepo{conn: conn}
+}
+
+const createOIDCIdentityQuery = `-- createOIDCIdentity
+INSERT INTO oidc_identities (issuer, subject, user_id, email) VALUES ($1, $2, $3, $4) RETURNING created_at;
+`
+
+// Create сохраняет новую учетную запись.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - i: данные учетной записи
+//
+// Возвращает:
+//   - *models.OIDCIdentity: созданная запись
+//   - error: repositories.ErrDuplicateKey, если учетная запись уже связана (преобразованная через convertErrType)
+func (r *OIDCIdentityRepo) Create(ctx context.Context, i *models.OIDCIdentity) (*models.OIDCIdentity, error) {
+	m := *i
+	err := r.conn.QueryRow(ctx, createOIDCIdentityQuery, m.Issuer, m.Subject, m.UserID, m.Email).Scan(&m.CreatedAt)
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return &m, nil
+}
+
+const getOIDCIdentityQuery = `-- getOIDCIdentity
+SELECT issuer, subject, user_id, email, created_at FROM oidc_identities WHERE issuer = $1 AND subject = $2;
+`
+
+// Get получает учетную запись по провайдеру и идентификатору пользователя у него.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - issuer: идентификатор провайдера
+//   - subject: идентификатор пользователя у провайдера
+//
+// Возвращает:
+//   - *models.OIDCIdentity: найденная запись
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (r *OIDCIdentityRepo) Get(ctx context.Context, issuer, subject string) (*models.OIDCIdentity, error) {
+	return r.getOne(ctx, getOIDCIdentityQuery, issuer, subject)
+}
+
+const getOIDCIdentityByUserIDQuery = `-- getOIDCIdentityByUserID
+SELECT issuer, subject, user_id, email, created_at FROM oidc_identities WHERE user_id = $1 LIMIT 1;
+`
+
+// GetByUserID получает учетную запись, связанную с UUID посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - userID: UUID посетителя
+//
+// Возвращает:
+//   - *models.OIDCIdentity: найденная запись
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (r *OIDCIdentityRepo) GetByUserID(ctx context.Context, userID string) (*models.OIDCIdentity, error) {
+	return r.getOne(ctx, getOIDCIdentityByUserIDQuery, userID)
+}
+
+// getOne выполняет запрос, возвращающий не более одной учетной записи.
+func (r *OIDCIdentityRepo) getOne(ctx context.Context, query string, args ...any) (*models.OIDCIdentity, error) {
+	rows, qErr := r.conn.Query(ctx, query, args...)
+	if qErr != nil {
+		return nil, convertErrType(qErr)
+	}
+	i, err := pgx.CollectExactlyOneRow(rows, func(row pgx.CollectableRow) (models.OIDCIdentity, error) {
+		var i models.OIDCIdentity
+		err := row.Scan(&i.Issuer, &i.Subject, &i.UserID, &i.Email, &i.CreatedAt)
+		return i, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return &i, nil
+}
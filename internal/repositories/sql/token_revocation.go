@@ -0,0 +1,144 @@
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TokenRevocationRepo представляет собой репозиторий отозванных токенов посетителей в PostgreSQL.
+type TokenRevocationRepo struct {
+	conn *pgxpool.Pool
+}
+
+// NewTokenRevocationRepo создает новый экземпляр репозитория отозванных токенов.
+//
+// Параметры:
+//   - conn: пул подключений к PostgreSQL
+//
+// Возвращает:
+//   - *TokenRevocationRepo: инициализированный репозиторий
+func NewTokenRevocationRepo(conn *pgxpool.Pool) *TokenRevocationRepo {
+	return &TokenRevocationRepo{conn: conn}
+}
+
+const revokeTokenQuery = `-- revokeToken
+INSERT INTO revoked_tokens (jti, visitor_uuid, expires_at) VALUES ($1, $2, $3)
+ON CONFLICT (jti) DO NOTHING;
+`
+
+// RevokeToken сохраняет отзыв токена. Повторный отзыв того же токена не является ошибкой.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - t: отозванный токен
+//
+// Возвращает:
+//   - error: ошибка сохранения (преобразованная через convertErrType)
+func (r *TokenRevocationRepo) RevokeToken(ctx context.Context, t *models.RevokedToken) error {
+	if _, err := r.conn.Exec(ctx, revokeTokenQuery, t.JTI, t.VisitorUUID, t.ExpiresAt); err != nil {
+		return convertErrType(err)
+	}
+	return nil
+}
+
+const isTokenRevokedQuery = `-- isTokenRevoked
+SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1);
+`
+
+// IsTokenRevoked проверяет, отозван ли токен.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - jti: идентификатор токена
+//
+// Возвращает:
+//   - bool: true, если токен отозван
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (r *TokenRevocationRepo) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+	if err := r.conn.QueryRow(ctx, isTokenRevokedQuery, jti).Scan(&revoked); err != nil {
+		return false, convertErrType(err)
+	}
+	return revoked, nil
+}
+
+const revokeSessionsQuery = `-- revokeSessions
+INSERT INTO session_revocations (visitor_uuid, revoked_before, expires_at) VALUES ($1, $2, $3)
+ON CONFLICT (visitor_uuid) DO UPDATE SET
+	revoked_before = GREATEST(session_revocations.revoked_before, EXCLUDED.revoked_before),
+	expires_at = GREATEST(session_revocations.expires_at, EXCLUDED.expires_at);
+`
+
+// RevokeSessions сохраняет отзыв всех сессий посетителя. Из нескольких отзывов действует самый поздний.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - s: отзыв сессий
+//
+// Возвращает:
+//   - error: ошибка сохранения (преобразованная через convertErrType)
+func (r *TokenRevocationRepo) RevokeSessions(ctx context.Context, s *models.SessionRevocation) error {
+	if _, err := r.conn.Exec(ctx, revokeSessionsQuery, s.VisitorUUID, s.RevokedBefore, s.ExpiresAt); err != nil {
+		return convertErrType(err)
+	}
+	return nil
+}
+
+const getSessionRevocationQuery = `-- getSessionRevocation
+SELECT visitor_uuid, revoked_before, expires_at FROM session_revocations WHERE visitor_uuid = $1;
+`
+
+// GetSessionRevocation получает отзыв сессий посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - *models.SessionRevocation: найденная запись
+//   - error: repositories.ErrNotFound, если сессии посетителя не отзывались (преобразованная через convertErrType)
+func (r *TokenRevocationRepo) GetSessionRevocation(
+	ctx context.Context,
+	visitorUUID string,
+) (*models.SessionRevocation, error) {
+	rows, qErr := r.conn.Query(ctx, getSessionRevocationQuery, visitorUUID)
+	if qErr != nil {
+		return nil, convertErrType(qErr)
+	}
+	s, err := pgx.CollectExactlyOneRow(rows, func(row pgx.CollectableRow) (models.SessionRevocation, error) {
+		var s models.SessionRevocation
+		err := row.Scan(&s.VisitorUUID, &s.RevokedBefore, &s.ExpiresAt)
+		return s, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return &s, nil
+}
+
+const deleteExpiredRevocationsQuery = `-- deleteExpiredRevocations
+WITH t AS (DELETE FROM revoked_tokens WHERE expires_at <= $1 RETURNING 1),
+	s AS (DELETE FROM session_revocations WHERE expires_at <= $1 RETURNING 1)
+SELECT (SELECT COUNT(*) FROM t) + (SELECT COUNT(*) FROM s);
+`
+
+// DeleteExpired удаляет отзывы токенов и сессий, срок действия которых истек к моменту before.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - before: момент времени
+//
+// Возвращает:
+//   - int64: количество удаленных записей
+//   - error: ошибка удаления (преобразованная через convertErrType)
+func (r *TokenRevocationRepo) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	if err := r.conn.QueryRow(ctx, deleteExpiredRevocationsQuery, before).Scan(&deleted); err != nil {
+		return 0, convertErrType(err)
+	}
+	return deleted, nil
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"sync"
+	"slices"
 
 	"github.com/fsdevblog/shorturl/internal/repositories"
 	"github.com/jackc/pgx/v5"
@@ -33,11 +33,11 @@ func NewURLRepo(conn *pgxpool.Pool) *URLRepo {
 
 const batchCreateURLQuery = `-- batchCreateURLs
 INSERT INTO urls 
-	(short_identifier, url, visitor_uuid) 
-VALUES ($1, $2, $3)
-ON CONFLICT (url, visitor_uuid) 
+	(short_identifier, url, visitor_uuid, tags) 
+VALUES ($1, $2, $3, COALESCE($4::text[], '{}'))
+ON CONFLICT (url, visitor_uuid) WHERE workspace_id IS NULL
 	DO UPDATE SET updated_at = NOW()
-RETURNING id, created_at, updated_at, short_identifier, url, visitor_uuid, xmax = 0 AS inserted;
+RETURNING id, created_at, updated_at, short_identifier, url, visitor_uuid, tags, xmax = 0 AS inserted;
 `
 
 // BatchCreate создает несколько URL записей одновременно.
@@ -56,7 +56,7 @@ func (u *URLRepo) BatchCreate(
 	batch := new(pgx.Batch)
 
 	for _, arg := range args {
-		vals := []interface{}{arg.ShortIdentifier, arg.URL, arg.VisitorUUID}
+		vals := []interface{}{arg.ShortIdentifier, arg.URL, arg.VisitorUUID, arg.Tags}
 		batch.Queue(batchCreateURLQuery, vals...)
 	}
 	bResults := u.conn.SendBatch(ctx, batch)
@@ -71,6 +71,7 @@ func (u *URLRepo) BatchCreate(
 			&m.Value.ShortIdentifier,
 			&m.Value.URL,
 			&m.Value.VisitorUUID,
+			&m.Value.Tags,
 			&inserted,
 		)
 		if err != nil {
@@ -87,15 +88,24 @@ func (u *URLRepo) BatchCreate(
 	return &repositories.BatchCreateShortURLsResult{Results: ret}, nil
 }
 
+const createWorkspaceURLQuery = `-- createWorkspaceURL
+INSERT INTO urls (short_identifier, url, visitor_uuid, tags, workspace_id) 
+	VALUES ($1, $2, $3, COALESCE($4::text[], '{}'), $5) 
+ON CONFLICT (workspace_id, url) WHERE workspace_id IS NOT NULL
+	DO UPDATE SET updated_at = NOW()
+RETURNING id, created_at, updated_at, short_identifier, url, visitor_uuid, tags, xmax = 0 AS inserted;
+`
+
 const createURLQuery = `-- createURL
-INSERT INTO urls (short_identifier, url, visitor_uuid) 
-	VALUES ($1, $2, $3) 
-ON CONFLICT (url, visitor_uuid) 
+INSERT INTO urls (short_identifier, url, visitor_uuid, tags) 
+	VALUES ($1, $2, $3, COALESCE($4::text[], '{}')) 
+ON CONFLICT (url, visitor_uuid) WHERE workspace_id IS NULL
 	DO UPDATE SET updated_at = NOW()
-RETURNING id, created_at, updated_at, short_identifier, url, visitor_uuid, xmax = 0 AS inserted;
+RETURNING id, created_at, updated_at, short_identifier, url, visitor_uuid, tags, xmax = 0 AS inserted;
 `
 
-// Create создает новую URL запись.
+// Create создает новую URL запись. Личная ссылка уникальна по URL в пределах посетителя,
+// ссылка рабочего пространства (задан WorkspaceID) - в пределах пространства.
 //
 // Параметры:
 //   - ctx: контекст выполнения
@@ -106,19 +116,33 @@ RETURNING id, created_at, updated_at, short_identifier, url, visitor_uuid, xmax
 //   - bool: флаг успешного создания (true если создана новая запись, false если обновлена существующая)
 //   - error: ошибка создания (преобразованная через convertErrType)
 func (u *URLRepo) Create(ctx context.Context, modelURL *models.URL) (*models.URL, bool, error) {
-	row := u.conn.QueryRow(ctx, createURLQuery, modelURL.ShortIdentifier, modelURL.URL, modelURL.VisitorUUID)
+	var row pgx.Row
+	if modelURL.WorkspaceID == "" {
+		row = u.conn.QueryRow(
+			ctx, createURLQuery, modelURL.ShortIdentifier, modelURL.URL, modelURL.VisitorUUID, modelURL.Tags,
+		)
+	} else {
+		row = u.conn.QueryRow(ctx, createWorkspaceURLQuery,
+			modelURL.ShortIdentifier, modelURL.URL, modelURL.VisitorUUID, modelURL.Tags, modelURL.WorkspaceID,
+		)
+	}
 
 	var m models.URL
 	var inserted bool
-	scanErr := row.Scan(&m.ID, &m.CreatedAt, &m.UpdatedAt, &m.ShortIdentifier, &m.URL, &m.VisitorUUID, &inserted)
+	scanErr := row.Scan(
+		&m.ID, &m.CreatedAt, &m.UpdatedAt, &m.ShortIdentifier, &m.URL, &m.VisitorUUID, &m.Tags, &inserted,
+	)
 	if scanErr != nil {
 		return nil, false, convertErrType(scanErr)
 	}
+	m.WorkspaceID = modelURL.WorkspaceID
 	return &m, inserted, nil
 }
 
 const getByShortIdentifierQuery = `-- getByShortIdentifier
-SELECT id, short_identifier, url, visitor_uuid, deleted_at FROM urls WHERE short_identifier = $1;
+SELECT id, created_at, updated_at, deleted_at, short_identifier, url, visitor_uuid, tags,
+	disabled_at, disabled_reason, disabled_legal, COALESCE(workspace_id::text, '')
+FROM urls WHERE short_identifier = $1;
 `
 
 // GetByShortIdentifier получает URL по короткому идентификатору.
@@ -131,20 +155,49 @@ SELECT id, short_identifier, url, visitor_uuid, deleted_at FROM urls WHERE short
 //   - *models.URL: найденная запись
 //   - error: ошибка поиска (преобразованная через convertErrType)
 func (u *URLRepo) GetByShortIdentifier(ctx context.Context, shortID string) (*models.URL, error) {
-	row := u.conn.QueryRow(ctx, getByShortIdentifierQuery, shortID)
-	var m models.URL
-	scanErr := row.Scan(&m.ID, &m.ShortIdentifier, &m.URL, &m.VisitorUUID, &m.DeletedAt)
+	m, scanErr := scanModeratedURL(u.conn.QueryRow(ctx, getByShortIdentifierQuery, shortID))
 	if scanErr != nil {
 		return nil, convertErrType(scanErr)
 	}
 	return &m, nil
 }
 
+const getByShortIdentifiersQuery = `-- getByShortIdentifiers
+SELECT id, short_identifier, url, visitor_uuid, deleted_at, disabled_at
+FROM urls WHERE short_identifier = ANY($1);
+`
+
+// GetByShortIdentifiers получает URL по нескольким коротким идентификаторам одним запросом.
+// Отсутствующие идентификаторы пропускаются, порядок записей не гарантируется.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - shortIDs: короткие идентификаторы URL
+//
+// Возвращает:
+//   - []models.URL: найденные записи, включая удаленные
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (u *URLRepo) GetByShortIdentifiers(ctx context.Context, shortIDs []string) ([]models.URL, error) {
+	rows, qErr := u.conn.Query(ctx, getByShortIdentifiersQuery, shortIDs)
+	if qErr != nil {
+		return nil, convertErrType(qErr)
+	}
+	urls, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.URL, error) {
+		var m models.URL
+		scanErr := row.Scan(&m.ID, &m.ShortIdentifier, &m.URL, &m.VisitorUUID, &m.DeletedAt, &m.DisabledAt)
+		return m, scanErr
+	})
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return urls, nil
+}
+
 const getAllByVisitorUUIDQuery = `-- getAllByVisitorUUID
-SELECT id, short_identifier, url, visitor_uuid FROM urls WHERE visitor_uuid = $1;
+SELECT id, short_identifier, url, visitor_uuid FROM urls WHERE visitor_uuid = $1 AND workspace_id IS NULL;
 `
 
-// GetAllByVisitorUUID получает все URL для указанного посетителя.
+// GetAllByVisitorUUID получает все личные URL указанного посетителя, без ссылок рабочих пространств.
 //
 // Параметры:
 //   - ctx: контекст выполнения
@@ -176,6 +229,70 @@ func (u *URLRepo) GetAllByVisitorUUID(ctx context.Context, visitorUUID string) (
 	return urls, nil
 }
 
+const getByURLVisitorUUIDQuery = `-- getByURLVisitorUUID
+SELECT id, created_at, updated_at, deleted_at, short_identifier, url, visitor_uuid, tags,
+	disabled_at, disabled_reason, disabled_legal, COALESCE(workspace_id::text, '')
+FROM urls WHERE url = $1 AND visitor_uuid = $2 AND workspace_id IS NULL;
+`
+
+// GetByURLVisitorUUID получает личную запись посетителя по оригинальному URL.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - rawURL: оригинальный URL
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - *models.URL: найденная запись
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (u *URLRepo) GetByURLVisitorUUID(ctx context.Context, rawURL string, visitorUUID string) (*models.URL, error) {
+	m, scanErr := scanModeratedURL(u.conn.QueryRow(ctx, getByURLVisitorUUIDQuery, rawURL, visitorUUID))
+	if scanErr != nil {
+		return nil, convertErrType(scanErr)
+	}
+	return &m, nil
+}
+
+const eachByVisitorUUIDQuery = `-- eachByVisitorUUID
+SELECT id, created_at, updated_at, deleted_at, short_identifier, url, visitor_uuid, tags
+FROM urls WHERE visitor_uuid = $1 AND workspace_id IS NULL ORDER BY id;
+`
+
+// EachByVisitorUUID вызывает fn для каждой личной записи посетителя, включая удаленные.
+// Записи читаются из курсора по одной и целиком в памяти не хранятся.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//   - fn: функция, вызываемая для каждой записи; ошибка прекращает обход
+//
+// Возвращает:
+//   - error: ошибка fn или ошибка чтения (преобразованная через convertErrType)
+func (u *URLRepo) EachByVisitorUUID(ctx context.Context, visitorUUID string, fn func(models.URL) error) error {
+	rows, qErr := u.conn.Query(ctx, eachByVisitorUUIDQuery, visitorUUID)
+	if qErr != nil {
+		return convertErrType(qErr)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m models.URL
+		if err := rows.Scan(
+			&m.ID, &m.CreatedAt, &m.UpdatedAt, &m.DeletedAt, &m.ShortIdentifier, &m.URL, &m.VisitorUUID, &m.Tags,
+		); err != nil {
+			return convertErrType(err)
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+
+	if rErr := rows.Err(); rErr != nil {
+		return convertErrType(rErr)
+	}
+	return nil
+}
+
 const getByURLQuery = `-- getByURL
 SELECT id, short_identifier, url, visitor_uuid FROM urls WHERE url = $1;
 `
@@ -231,12 +348,41 @@ func (u *URLRepo) GetAll(ctx context.Context) ([]models.URL, error) {
 	return urls, nil
 }
 
-const markAsDeletedByShortIDVisitorUUIDQuery = `-- markAsDeletedByShortIDVisitorUUID
-UPDATE urls SET deleted_at = NOW() WHERE short_identifier = $1 AND visitor_uuid = $2;
+const getStatsQuery = `-- getStats
+SELECT
+	COUNT(*) FILTER (WHERE deleted_at IS NULL),
+	COUNT(DISTINCT visitor_uuid) FILTER (WHERE visitor_uuid <> '')
+FROM urls;
+`
+
+// Stats подсчитывает количество неудаленных URL и уникальных посетителей одним запросом.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//
+// Возвращает:
+//   - *models.Stats: статистика
+//   - error: ошибка подсчета (преобразованная через convertErrType)
+func (u *URLRepo) Stats(ctx context.Context) (*models.Stats, error) {
+	var stats models.Stats
+	if err := u.conn.QueryRow(ctx, getStatsQuery).Scan(&stats.URLs, &stats.Users); err != nil {
+		return nil, convertErrType(err)
+	}
+	return &stats, nil
+}
+
+// deleteChunkSize количество идентификаторов в одном запросе DeleteByShortIDsVisitorUUID.
+const deleteChunkSize = 1000
+
+const markAsDeletedByShortIDsVisitorUUIDQuery = `-- markAsDeletedByShortIDsVisitorUUID
+UPDATE urls SET deleted_at = NOW()
+WHERE visitor_uuid = $1 AND short_identifier = ANY($2) AND deleted_at IS NULL AND workspace_id IS NULL
+RETURNING short_identifier;
 `
 
-// DeleteByShortIDsVisitorUUID помечает URL записи как удаленные для указанного посетителя.
-// Операция выполняется в транзакции батчами по 100 записей.
+// DeleteByShortIDsVisitorUUID помечает личные URL записи указанного посетителя как удаленные.
+// Каждая порция из deleteChunkSize идентификаторов удаляется одним запросом,
+// все порции выполняются последовательно в одной транзакции.
 //
 // Параметры:
 //   - ctx: контекст выполнения
@@ -244,100 +390,347 @@ UPDATE urls SET deleted_at = NOW() WHERE short_identifier = $1 AND visitor_uuid
 //   - shortIDs: список коротких идентификаторов для удаления
 //
 // Возвращает:
+//   - []string: идентификаторы, помеченные удаленными этим вызовом
 //   - error: ошибка удаления (преобразованная через convertErrType)
-func (u *URLRepo) DeleteByShortIDsVisitorUUID(ctx context.Context, visitorUUID string, shortIDs []string) (err error) {
+func (u *URLRepo) DeleteByShortIDsVisitorUUID(
+	ctx context.Context,
+	visitorUUID string,
+	shortIDs []string,
+) (deleted []string, err error) { //nolint:nonamedreturns
 	tx, txErr := u.conn.Begin(ctx)
 	if txErr != nil {
-		return convertErrType(txErr)
+		return nil, convertErrType(txErr)
 	}
-
 	defer func() {
-		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
-			if !errors.Is(rollbackErr, pgx.ErrTxClosed) {
-				err = convertErrType(rollbackErr)
-			}
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+			err = errors.Join(err, convertErrType(rollbackErr))
 		}
 	}()
 
-	batchFlatInCh := make(chan error, len(shortIDs))
+	for chunk := range slices.Chunk(shortIDs, deleteChunkSize) {
+		rows, qErr := tx.Query(ctx, markAsDeletedByShortIDsVisitorUUIDQuery, visitorUUID, chunk)
+		if qErr != nil {
+			return nil, convertErrType(qErr)
+		}
+		ids, collectErr := pgx.CollectRows(rows, pgx.RowTo[string])
+		if collectErr != nil {
+			return nil, convertErrType(collectErr)
+		}
+		deleted = append(deleted, ids...)
+	}
 
-	const batchSize = 100
+	if commitErr := tx.Commit(ctx); commitErr != nil {
+		return nil, convertErrType(fmt.Errorf("commit error: %w", commitErr))
+	}
+	return deleted, nil
+}
 
-	lenShortIDs := len(shortIDs)
-	wg := new(sync.WaitGroup)
+// batchDeleteChunkSize количество пар (посетитель, идентификатор) в одном запросе BatchDelete.
+const batchDeleteChunkSize = 1000
+
+const batchDeleteQuery = `-- batchDelete
+UPDATE urls u SET deleted_at = NOW()
+FROM unnest($1::text[], $2::text[]) AS d(visitor_uuid, short_identifier)
+WHERE u.visitor_uuid = d.visitor_uuid AND u.short_identifier = d.short_identifier
+  AND u.deleted_at IS NULL AND u.workspace_id IS NULL
+RETURNING u.id, u.short_identifier, u.url, u.visitor_uuid, u.deleted_at;
+`
+
+// BatchDelete помечает удаленными личные URL нескольких посетителей.
+// Пары (посетитель, идентификатор) передаются массивами, поэтому каждая порция
+// из batchDeleteChunkSize пар удаляется одним запросом. Все порции выполняются в одной транзакции.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - args: идентификаторы URL, сгруппированные по посетителям
+//
+// Возвращает:
+//   - []models.URL: записи, помеченные удаленными этим вызовом
+//   - error: ошибка удаления (преобразованная через convertErrType)
+func (u *URLRepo) BatchDelete(
+	ctx context.Context,
+	args []repositories.BatchDeleteArg,
+) (deleted []models.URL, err error) { //nolint:nonamedreturns
+	tx, txErr := u.conn.Begin(ctx)
+	if txErr != nil {
+		return nil, convertErrType(txErr)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+			err = errors.Join(err, convertErrType(rollbackErr))
+		}
+	}()
 
-	// разделяем запросы на батчи
-	for i := 0; i < lenShortIDs; i += batchSize {
-		end := i + batchSize
-		if end > lenShortIDs {
-			end = lenShortIDs
+	visitors := make([]string, 0, batchDeleteChunkSize)
+	shortIDs := make([]string, 0, batchDeleteChunkSize)
+	flush := func() error {
+		if len(shortIDs) == 0 {
+			return nil
 		}
-		wg.Add(1)
-		go markAsDeletedBatchFn(ctx, markAsDeletedBatchFnArgs{
-			ids:         shortIDs[i:end],
-			visitorUUID: visitorUUID,
-			flatInCh:    batchFlatInCh,
-			wg:          wg,
-			tx:          tx,
-		})
-	}
-	wg.Wait()
-
-	close(batchFlatInCh)
-
-	for batchErr := range batchFlatInCh {
-		if batchErr != nil {
-			if err != nil {
-				err = errors.Join(err, convertErrType(batchErr))
-			} else {
-				err = convertErrType(batchErr)
+		rows, qErr := tx.Query(ctx, batchDeleteQuery, visitors, shortIDs)
+		if qErr != nil {
+			return convertErrType(qErr)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var m models.URL
+			if scanErr := rows.Scan(&m.ID, &m.ShortIdentifier, &m.URL, &m.VisitorUUID, &m.DeletedAt); scanErr != nil {
+				return convertErrType(scanErr)
 			}
-			return err
+			deleted = append(deleted, m)
 		}
+		visitors, shortIDs = visitors[:0], shortIDs[:0]
+		return convertErrType(rows.Err())
+	}
+
+	for _, arg := range args {
+		for _, shortID := range arg.ShortIDs {
+			visitors = append(visitors, arg.VisitorUUID)
+			shortIDs = append(shortIDs, shortID)
+			if len(shortIDs) < batchDeleteChunkSize {
+				continue
+			}
+			if flushErr := flush(); flushErr != nil {
+				return nil, flushErr
+			}
+		}
+	}
+	if flushErr := flush(); flushErr != nil {
+		return nil, flushErr
 	}
 
 	if commitErr := tx.Commit(ctx); commitErr != nil {
-		return convertErrType(fmt.Errorf("commit error: %w", commitErr))
+		return nil, convertErrType(fmt.Errorf("commit error: %w", commitErr))
 	}
+	return deleted, nil
+}
 
-	return err
+const reassignVisitorQuery = `-- reassignVisitor
+UPDATE urls u SET visitor_uuid = $2, updated_at = NOW()
+WHERE u.visitor_uuid = $1 AND u.workspace_id IS NULL
+  AND NOT EXISTS (SELECT 1 FROM urls o WHERE o.visitor_uuid = $2 AND o.url = u.url AND o.workspace_id IS NULL);
+`
+
+// ReassignVisitor передает личные записи одного посетителя другому.
+// Записи с URL, который у получателя уже есть, остаются у прежнего посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - fromUUID: идентификатор прежнего посетителя
+//   - toUUID: идентификатор нового посетителя
+//
+// Возвращает:
+//   - int: количество переданных записей
+//   - error: ошибка обновления (преобразованная через convertErrType)
+func (u *URLRepo) ReassignVisitor(ctx context.Context, fromUUID string, toUUID string) (int, error) {
+	tag, err := u.conn.Exec(ctx, reassignVisitorQuery, fromUUID, toUUID)
+	if err != nil {
+		return 0, convertErrType(err)
+	}
+	return int(tag.RowsAffected()), nil
 }
 
-// markAsDeletedBatchFnArgs содержит аргументы для функции markAsDeletedBatchFn.
-type markAsDeletedBatchFnArgs struct {
-	ids         []string
-	visitorUUID string
-	flatInCh    chan error
-	wg          *sync.WaitGroup
-	tx          pgx.Tx
+const searchURLsQuery = `-- searchURLs
+SELECT id, created_at, updated_at, deleted_at, short_identifier, url, visitor_uuid, tags,
+	disabled_at, disabled_reason, disabled_legal, COALESCE(workspace_id::text, '')
+FROM urls
+WHERE ($1 = '' OR strpos(lower(url), lower($1)) > 0 OR short_identifier = $1)
+  AND ($2 = '' OR visitor_uuid = $2)
+  AND (NOT $3 OR disabled_at IS NOT NULL)
+ORDER BY id DESC
+LIMIT $4 OFFSET $5;
+`
+
+// Search ищет ссылки всех посетителей, включая удаленные и отключенные, от новых к старым.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - filter: условия поиска
+//
+// Возвращает:
+//   - []models.URL: найденные записи
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (u *URLRepo) Search(ctx context.Context, filter repositories.URLSearchFilter) ([]models.URL, error) {
+	rows, qErr := u.conn.Query(ctx, searchURLsQuery,
+		filter.Query, filter.VisitorUUID, filter.OnlyDisabled, filter.Limit, filter.Offset,
+	)
+	if qErr != nil {
+		return nil, convertErrType(qErr)
+	}
+	urls, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.URL, error) {
+		return scanModeratedURL(row)
+	})
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return urls, nil
 }
 
-// markAsDeletedBatchFn обрабатывает батч запросов на удаление URL.
+const disableURLQuery = `-- disableURL
+UPDATE urls SET disabled_at = NOW(), disabled_reason = $2, disabled_legal = $3, updated_at = NOW()
+WHERE short_identifier = $1
+RETURNING id, created_at, updated_at, deleted_at, short_identifier, url, visitor_uuid, tags,
+	disabled_at, disabled_reason, disabled_legal, COALESCE(workspace_id::text, '');
+`
+
+// Disable отключает ссылку. Для уже отключенной ссылки обновляются причина и момент отключения.
 //
 // Параметры:
 //   - ctx: контекст выполнения
-//   - args: аргументы для обработки батча
-func markAsDeletedBatchFn(ctx context.Context, args markAsDeletedBatchFnArgs) {
-	defer args.wg.Done()
+//   - shortID: короткий идентификатор URL
+//   - reason: причина отключения
+//   - legal: отключение по требованию закона
+//
+// Возвращает:
+//   - *models.URL: обновленная запись
+//   - error: ошибка обновления (преобразованная через convertErrType)
+func (u *URLRepo) Disable(ctx context.Context, shortID string, reason string, legal bool) (*models.URL, error) {
+	m, err := scanModeratedURL(u.conn.QueryRow(ctx, disableURLQuery, shortID, reason, legal))
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return &m, nil
+}
 
-	batch := new(pgx.Batch)
-	for _, shortID := range args.ids {
-		batch.Queue(markAsDeletedByShortIDVisitorUUIDQuery, shortID, args.visitorUUID)
+const enableURLQuery = `-- enableURL
+UPDATE urls SET disabled_at = NULL, disabled_reason = '', disabled_legal = FALSE, updated_at = NOW()
+WHERE short_identifier = $1
+RETURNING id, created_at, updated_at, deleted_at, short_identifier, url, visitor_uuid, tags,
+	disabled_at, disabled_reason, disabled_legal, COALESCE(workspace_id::text, '');
+`
+
+// Enable снимает отключение ссылки.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - shortID: короткий идентификатор URL
+//
+// Возвращает:
+//   - *models.URL: обновленная запись
+//   - error: ошибка обновления (преобразованная через convertErrType)
+func (u *URLRepo) Enable(ctx context.Context, shortID string) (*models.URL, error) {
+	m, err := scanModeratedURL(u.conn.QueryRow(ctx, enableURLQuery, shortID))
+	if err != nil {
+		return nil, convertErrType(err)
 	}
+	return &m, nil
+}
 
-	bResults := args.tx.SendBatch(ctx, batch)
+const disableByVisitorUUIDQuery = `-- disableByVisitorUUID
+UPDATE urls SET disabled_at = NOW(), disabled_reason = $2, disabled_legal = FALSE, updated_at = NOW()
+WHERE visitor_uuid = $1 AND disabled_at IS NULL AND deleted_at IS NULL;
+`
 
-	for range args.ids {
-		_, execErr := bResults.Exec()
-		if execErr != nil {
-			args.flatInCh <- convertErrType(execErr)
-			return
-		}
+// DisableByVisitorUUID отключает все действующие ссылки посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//   - reason: причина отключения
+//
+// Возвращает:
+//   - int: количество отключенных записей
+//   - error: ошибка обновления (преобразованная через convertErrType)
+func (u *URLRepo) DisableByVisitorUUID(ctx context.Context, visitorUUID string, reason string) (int, error) {
+	tag, err := u.conn.Exec(ctx, disableByVisitorUUIDQuery, visitorUUID, reason)
+	if err != nil {
+		return 0, convertErrType(err)
 	}
+	return int(tag.RowsAffected()), nil
+}
+
+const getAllByWorkspaceIDQuery = `-- getAllByWorkspaceID
+SELECT id, created_at, updated_at, deleted_at, short_identifier, url, visitor_uuid, tags,
+	disabled_at, disabled_reason, disabled_legal, COALESCE(workspace_id::text, '')
+FROM urls WHERE workspace_id = $1 ORDER BY id;
+`
 
-	if closeErr := bResults.Close(); closeErr != nil {
-		args.flatInCh <- convertErrType(closeErr)
-		return
+// GetAllByWorkspaceID получает все ссылки рабочего пространства, включая удаленные.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - workspaceID: идентификатор рабочего пространства
+//
+// Возвращает:
+//   - []models.URL: найденные записи в порядке создания
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (u *URLRepo) GetAllByWorkspaceID(ctx context.Context, workspaceID string) ([]models.URL, error) {
+	rows, qErr := u.conn.Query(ctx, getAllByWorkspaceIDQuery, workspaceID)
+	if qErr != nil {
+		return nil, convertErrType(qErr)
+	}
+	urls, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.URL, error) {
+		return scanModeratedURL(row)
+	})
+	if err != nil {
+		return nil, convertErrType(err)
 	}
-	args.flatInCh <- nil
+	return urls, nil
+}
+
+const updateInWorkspaceQuery = `-- updateInWorkspace
+UPDATE urls SET url = COALESCE($3, url), tags = COALESCE($4::text[], tags), updated_at = NOW()
+WHERE workspace_id = $1 AND short_identifier = $2 AND deleted_at IS NULL
+RETURNING id, created_at, updated_at, deleted_at, short_identifier, url, visitor_uuid, tags,
+	disabled_at, disabled_reason, disabled_legal, COALESCE(workspace_id::text, '');
+`
+
+// UpdateInWorkspace изменяет неудаленную ссылку рабочего пространства.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - workspaceID: идентификатор рабочего пространства
+//   - shortID: короткий идентификатор URL
+//   - upd: изменения
+//
+// Возвращает:
+//   - *models.URL: обновленная запись
+//   - error: repositories.ErrNotFound, если ссылки нет в пространстве,
+//     repositories.ErrDuplicateKey, если в пространстве уже есть ссылка на новый URL
+func (u *URLRepo) UpdateInWorkspace(
+	ctx context.Context,
+	workspaceID string,
+	shortID string,
+	upd repositories.URLUpdate,
+) (*models.URL, error) {
+	m, err := scanModeratedURL(u.conn.QueryRow(ctx, updateInWorkspaceQuery, workspaceID, shortID, upd.URL, upd.Tags))
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return &m, nil
+}
+
+const deleteInWorkspaceQuery = `-- deleteInWorkspace
+UPDATE urls SET deleted_at = NOW()
+WHERE workspace_id = $1 AND short_identifier = $2 AND deleted_at IS NULL
+RETURNING id, created_at, updated_at, deleted_at, short_identifier, url, visitor_uuid, tags,
+	disabled_at, disabled_reason, disabled_legal, COALESCE(workspace_id::text, '');
+`
+
+// DeleteInWorkspace помечает удаленной ссылку рабочего пространства.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - workspaceID: идентификатор рабочего пространства
+//   - shortID: короткий идентификатор URL
+//
+// Возвращает:
+//   - *models.URL: удаленная запись
+//   - error: repositories.ErrNotFound, если неудаленной ссылки нет в пространстве
+func (u *URLRepo) DeleteInWorkspace(ctx context.Context, workspaceID string, shortID string) (*models.URL, error) {
+	m, err := scanModeratedURL(u.conn.QueryRow(ctx, deleteInWorkspaceQuery, workspaceID, shortID))
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return &m, nil
+}
+
+// scanModeratedURL читает запись со всеми полями, включая состояние отключения и рабочее пространство.
+func scanModeratedURL(row pgx.Row) (models.URL, error) {
+	var m models.URL
+	err := row.Scan(
+		&m.ID, &m.CreatedAt, &m.UpdatedAt, &m.DeletedAt, &m.ShortIdentifier, &m.URL, &m.VisitorUUID, &m.Tags,
+		&m.DisabledAt, &m.DisabledReason, &m.DisabledLegal, &m.WorkspaceID,
+	)
+	return m, err //nolint:wrapcheck
 }
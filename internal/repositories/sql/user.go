@@ -0,0 +1,97 @@
+package sql
+
+import (
+	"context"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UserRepo представляет собой репозиторий пользователей в PostgreSQL.
+type UserRepo struct {
+	conn *pgxpool.Pool
+}
+
+// NewUserRepo создает новый экземпляр репозитория пользователей.
+//
+// Параметры:
+//   - conn: пул подключений к PostgreSQL
+//
+// Возвращает:
+//   - *UserRepo: инициализированный репозиторий
+func NewUserRepo(conn *pgxpool.Pool) *UserRepo {
+	return &UserRepo{conn: conn}
+}
+
+const createUserQuery = `-- createUser
+INSERT INTO users (id, email, password_hash) VALUES ($1, $2, $3) RETURNING created_at;
+`
+
+// Create сохраняет нового пользователя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - u: данные пользователя
+//
+// Возвращает:
+//   - *models.User: созданная запись
+//   - error: repositories.ErrDuplicateKey, если адрес или идентификатор заняты (преобразованная через convertErrType)
+func (r *UserRepo) Create(ctx context.Context, u *models.User) (*models.User, error) {
+	m := *u
+	if err := r.conn.QueryRow(ctx, createUserQuery, m.ID, m.Email, m.PasswordHash).Scan(&m.CreatedAt); err != nil {
+		return nil, convertErrType(err)
+	}
+	return &m, nil
+}
+
+const getUserByEmailQuery = `-- getUserByEmail
+SELECT id, created_at, email, password_hash FROM users WHERE email = $1;
+`
+
+// GetByEmail получает пользователя по адресу электронной почты.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - email: адрес в нижнем регистре
+//
+// Возвращает:
+//   - *models.User: найденная запись
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (r *UserRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.getOne(ctx, getUserByEmailQuery, email)
+}
+
+const getUserByIDQuery = `-- getUserByID
+SELECT id, created_at, email, password_hash FROM users WHERE id = $1;
+`
+
+// GetByID получает пользователя по идентификатору.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - id: идентификатор пользователя
+//
+// Возвращает:
+//   - *models.User: найденная запись
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (r *UserRepo) GetByID(ctx context.Context, id string) (*models.User, error) {
+	return r.getOne(ctx, getUserByIDQuery, id)
+}
+
+// getOne выполняет запрос, возвращающий не более одного пользователя.
+func (r *UserRepo) getOne(ctx context.Context, query string, arg string) (*models.User, error) {
+	rows, qErr := r.conn.Query(ctx, query, arg)
+	if qErr != nil {
+		return nil, convertErrType(qErr)
+	}
+	u, err := pgx.CollectExactlyOneRow(rows, func(row pgx.CollectableRow) (models.User, error) {
+		var u models.User
+		err := row.Scan(&u.ID, &u.CreatedAt, &u.Email, &u.PasswordHash)
+		return u, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return &u, nil
+}
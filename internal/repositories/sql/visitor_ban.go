@@ -0,0 +1,100 @@
+package sql
+
+import (
+	"context"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// VisitorBanRepo представляет собой репозиторий блокировок посетителей в PostgreSQL.
+type VisitorBanRepo struct {
+	conn *pgxpool.Pool
+}
+
+// NewVisitorBanRepo создает новый экземпляр репозитория блокировок посетителей.
+//
+// Параметры:
+//   - conn: пул подключений к PostgreSQL
+//
+// Возвращает:
+//   - *VisitorBanRepo: инициализированный репозиторий
+func NewVisitorBanRepo(conn *pgxpool.Pool) *VisitorBanRepo {
+	return &VisitorBanRepo{conn: conn}
+}
+
+const createVisitorBanQuery = `-- createVisitorBan
+INSERT INTO visitor_bans (visitor_uuid, reason, banned_by) VALUES ($1, $2, $3) RETURNING created_at;
+`
+
+// Create сохраняет блокировку посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - b: данные блокировки
+//
+// Возвращает:
+//   - *models.VisitorBan: созданная запись
+//   - error: repositories.ErrDuplicateKey, если посетитель уже заблокирован (преобразованная через convertErrType)
+func (r *VisitorBanRepo) Create(ctx context.Context, b *models.VisitorBan) (*models.VisitorBan, error) {
+	m := *b
+	err := r.conn.QueryRow(ctx, createVisitorBanQuery, m.VisitorUUID, m.Reason, m.BannedBy).Scan(&m.CreatedAt)
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return &m, nil
+}
+
+const getVisitorBanQuery = `-- getVisitorBan
+SELECT visitor_uuid, reason, banned_by, created_at FROM visitor_bans WHERE visitor_uuid = $1;
+`
+
+// Get получает блокировку посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - *models.VisitorBan: найденная запись
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (r *VisitorBanRepo) Get(ctx context.Context, visitorUUID string) (*models.VisitorBan, error) {
+	rows, qErr := r.conn.Query(ctx, getVisitorBanQuery, visitorUUID)
+	if qErr != nil {
+		return nil, convertErrType(qErr)
+	}
+	b, err := pgx.CollectExactlyOneRow(rows, func(row pgx.CollectableRow) (models.VisitorBan, error) {
+		var b models.VisitorBan
+		err := row.Scan(&b.VisitorUUID, &b.Reason, &b.BannedBy, &b.CreatedAt)
+		return b, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return &b, nil
+}
+
+const deleteVisitorBanQuery = `-- deleteVisitorBan
+DELETE FROM visitor_bans WHERE visitor_uuid = $1;
+`
+
+// Delete снимает блокировку посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - error: repositories.ErrNotFound, если посетитель не заблокирован (преобразованная через convertErrType)
+func (r *VisitorBanRepo) Delete(ctx context.Context, visitorUUID string) error {
+	tag, err := r.conn.Exec(ctx, deleteVisitorBanQuery, visitorUUID)
+	if err != nil {
+		return convertErrType(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repositories.ErrNotFound
+	}
+	return nil
+}
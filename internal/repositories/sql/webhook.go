@@ -0,0 +1,392 @@
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WebhookRepo представляет собой репозиторий для работы с вебхуками в PostgreSQL.
+type WebhookRepo struct {
+	conn *pgxpool.Pool
+}
+
+// NewWebhookRepo создает новый экземпляр репозитория вебхуков.
+//
+// Параметры:
+//   - conn: пул подключений к PostgreSQL
+//
+// Возвращает:
+//   - *WebhookRepo: инициализированный репозиторий
+func NewWebhookRepo(conn *pgxpool.Pool) *WebhookRepo {
+	return &WebhookRepo{conn: conn}
+}
+
+const createWebhookQuery = `-- createWebhook
+INSERT INTO webhooks (id, visitor_uuid, url, secret, events)
+	VALUES ($1, $2, $3, $4, $5)
+RETURNING created_at;
+`
+
+// Create создает новый вебхук.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - w: данные вебхука
+//
+// Возвращает:
+//   - *models.Webhook: созданная запись
+//   - error: ошибка создания (преобразованная через convertErrType)
+func (r *WebhookRepo) Create(ctx context.Context, w *models.Webhook) (*models.Webhook, error) {
+	m := *w
+	row := r.conn.QueryRow(ctx, createWebhookQuery, m.ID, m.VisitorUUID, m.URL, m.Secret, m.Events)
+	if err := row.Scan(&m.CreatedAt); err != nil {
+		return nil, convertErrType(err)
+	}
+	return &m, nil
+}
+
+const getWebhooksByVisitorUUIDQuery = `-- getWebhooksByVisitorUUID
+SELECT id, created_at, visitor_uuid, url, secret, events FROM webhooks
+WHERE visitor_uuid = $1
+ORDER BY created_at;
+`
+
+// GetAllByVisitorUUID получает все вебхуки посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - []models.Webhook: найденные записи
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (r *WebhookRepo) GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.Webhook, error) {
+	rows, qErr := r.conn.Query(ctx, getWebhooksByVisitorUUIDQuery, visitorUUID)
+	if qErr != nil {
+		return nil, convertErrType(qErr)
+	}
+	defer rows.Close()
+
+	var hooks []models.Webhook
+	for rows.Next() {
+		var m models.Webhook
+		if err := rows.Scan(&m.ID, &m.CreatedAt, &m.VisitorUUID, &m.URL, &m.Secret, &m.Events); err != nil {
+			return nil, convertErrType(err)
+		}
+		hooks = append(hooks, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, convertErrType(err)
+	}
+	return hooks, nil
+}
+
+const getWebhookByIDVisitorUUIDQuery = `-- getWebhookByIDVisitorUUID
+SELECT id, created_at, visitor_uuid, url, secret, events FROM webhooks
+WHERE id = $1 AND visitor_uuid = $2;
+`
+
+// GetByIDVisitorUUID получает вебхук посетителя по идентификатору.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - id: идентификатор вебхука
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - *models.Webhook: найденная запись
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (r *WebhookRepo) GetByIDVisitorUUID(ctx context.Context, id string, visitorUUID string) (*models.Webhook, error) {
+	row := r.conn.QueryRow(ctx, getWebhookByIDVisitorUUIDQuery, id, visitorUUID)
+	var m models.Webhook
+	if err := row.Scan(&m.ID, &m.CreatedAt, &m.VisitorUUID, &m.URL, &m.Secret, &m.Events); err != nil {
+		return nil, convertErrType(err)
+	}
+	return &m, nil
+}
+
+const deleteWebhookQuery = `-- deleteWebhook
+DELETE FROM webhooks WHERE id = $1 AND visitor_uuid = $2;
+`
+
+// Delete удаляет вебхук посетителя вместе с историей и ожидающими доставками.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - id: идентификатор вебхука
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - error: repositories.ErrNotFound если вебхук не найден
+func (r *WebhookRepo) Delete(ctx context.Context, id string, visitorUUID string) error {
+	tag, err := r.conn.Exec(ctx, deleteWebhookQuery, id, visitorUUID)
+	if err != nil {
+		return convertErrType(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repositories.ErrNotFound
+	}
+	return nil
+}
+
+const createWebhookDeliveryQuery = `-- createWebhookDelivery
+INSERT INTO webhook_deliveries
+	(id, webhook_id, event_id, event_type, attempt, status_code, error, success, dead_letter, payload)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+`
+
+// CreateDelivery сохраняет попытку доставки события.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - d: данные попытки доставки
+//
+// Возвращает:
+//   - error: ошибка сохранения (преобразованная через convertErrType)
+func (r *WebhookRepo) CreateDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	_, err := r.conn.Exec(ctx, createWebhookDeliveryQuery,
+		d.ID, d.WebhookID, d.EventID, d.EventType, d.Attempt, d.StatusCode, d.Error, d.Success, d.DeadLetter,
+		[]byte(d.Payload),
+	)
+	if err != nil {
+		return convertErrType(err)
+	}
+	return nil
+}
+
+const getWebhookDeliveriesQuery = `-- getWebhookDeliveries
+SELECT id, created_at, webhook_id, event_id, event_type, attempt, status_code, error, success, dead_letter, payload
+FROM webhook_deliveries
+WHERE webhook_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+`
+
+// GetDeliveriesByWebhookID получает последние попытки доставки для вебхука.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - webhookID: идентификатор вебхука
+//   - limit: максимальное количество записей
+//
+// Возвращает:
+//   - []models.WebhookDelivery: попытки доставки, от новых к старым
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (r *WebhookRepo) GetDeliveriesByWebhookID(
+	ctx context.Context,
+	webhookID string,
+	limit int,
+) ([]models.WebhookDelivery, error) {
+	rows, qErr := r.conn.Query(ctx, getWebhookDeliveriesQuery, webhookID, limit)
+	if qErr != nil {
+		return nil, convertErrType(qErr)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		var payload []byte
+		err := rows.Scan(&d.ID, &d.CreatedAt, &d.WebhookID, &d.EventID, &d.EventType, &d.Attempt,
+			&d.StatusCode, &d.Error, &d.Success, &d.DeadLetter, &payload)
+		if err != nil {
+			return nil, convertErrType(err)
+		}
+		d.Payload = payload
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, convertErrType(err)
+	}
+	return deliveries, nil
+}
+
+const deleteWebhookDeliveriesBeforeQuery = `-- deleteWebhookDeliveriesBefore
+DELETE FROM webhook_deliveries WHERE created_at < $1;
+`
+
+// DeleteDeliveriesBefore удаляет попытки доставки, созданные раньше before.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - before: момент времени
+//
+// Возвращает:
+//   - int64: количество удаленных записей
+//   - error: ошибка удаления (преобразованная через convertErrType)
+func (r *WebhookRepo) DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.conn.Exec(ctx, deleteWebhookDeliveriesBeforeQuery, before)
+	if err != nil {
+		return 0, convertErrType(err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+const getWebhookVisitorsQuery = `-- getWebhookVisitors
+SELECT DISTINCT visitor_uuid FROM webhooks;
+`
+
+// GetVisitorUUIDs получает идентификаторы посетителей, у которых есть вебхуки.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//
+// Возвращает:
+//   - []string: идентификаторы посетителей
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (r *WebhookRepo) GetVisitorUUIDs(ctx context.Context) ([]string, error) {
+	rows, qErr := r.conn.Query(ctx, getWebhookVisitorsQuery)
+	if qErr != nil {
+		return nil, convertErrType(qErr)
+	}
+	defer rows.Close()
+
+	var visitors []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, convertErrType(err)
+		}
+		visitors = append(visitors, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, convertErrType(err)
+	}
+	return visitors, nil
+}
+
+const createWebhookJobQuery = `-- createWebhookJob
+INSERT INTO webhook_jobs (id, webhook_id, event_id, event_type, attempt, next_attempt_at, payload)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+`
+
+// CreateJobs сохраняет ожидающие доставки одним пакетом.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - jobs: ожидающие доставки
+//
+// Возвращает:
+//   - error: ошибка сохранения (преобразованная через convertErrType)
+func (r *WebhookRepo) CreateJobs(ctx context.Context, jobs []models.WebhookJob) error {
+	batch := new(pgx.Batch)
+	for _, j := range jobs {
+		batch.Queue(createWebhookJobQuery,
+			j.ID, j.WebhookID, j.EventID, j.EventType, j.Attempt, j.NextAttemptAt, []byte(j.Payload))
+	}
+	if err := r.conn.SendBatch(ctx, batch).Close(); err != nil {
+		return convertErrType(err)
+	}
+	return nil
+}
+
+const claimDueWebhookJobsQuery = `-- claimDueWebhookJobs
+WITH due AS (
+	SELECT id FROM webhook_jobs
+	WHERE next_attempt_at <= $1
+	ORDER BY next_attempt_at
+	LIMIT $3
+	FOR UPDATE SKIP LOCKED
+)
+UPDATE webhook_jobs j SET next_attempt_at = $2
+FROM due, webhooks w
+WHERE j.id = due.id AND w.id = j.webhook_id
+RETURNING j.id, j.created_at, j.webhook_id, j.event_id, j.event_type, j.attempt, j.next_attempt_at, j.payload,
+	w.id, w.created_at, w.visitor_uuid, w.url, w.secret, w.events;
+`
+
+// ClaimDueJobs захватывает ожидающие доставки, время попытки которых наступило, и откладывает
+// их следующую попытку на lease. Если доставщик не завершит попытку (например, из-за остановки
+// приложения), доставка снова станет доступной по истечении lease. Захваченные одним экземпляром
+// приложения записи пропускаются другими.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - now: текущее время
+//   - lease: на сколько откладывается следующая попытка захваченных доставок
+//   - limit: максимальное количество записей
+//
+// Возвращает:
+//   - []repositories.WebhookJobClaim: захваченные доставки вместе с вебхуками
+//   - error: ошибка захвата (преобразованная через convertErrType)
+func (r *WebhookRepo) ClaimDueJobs(
+	ctx context.Context,
+	now time.Time,
+	lease time.Duration,
+	limit int,
+) ([]repositories.WebhookJobClaim, error) {
+	rows, qErr := r.conn.Query(ctx, claimDueWebhookJobsQuery, now, now.Add(lease), limit)
+	if qErr != nil {
+		return nil, convertErrType(qErr)
+	}
+	defer rows.Close()
+
+	var claims []repositories.WebhookJobClaim
+	for rows.Next() {
+		var c repositories.WebhookJobClaim
+		var payload []byte
+		err := rows.Scan(&c.Job.ID, &c.Job.CreatedAt, &c.Job.WebhookID, &c.Job.EventID, &c.Job.EventType,
+			&c.Job.Attempt, &c.Job.NextAttemptAt, &payload,
+			&c.Webhook.ID, &c.Webhook.CreatedAt, &c.Webhook.VisitorUUID, &c.Webhook.URL, &c.Webhook.Secret,
+			&c.Webhook.Events)
+		if err != nil {
+			return nil, convertErrType(err)
+		}
+		c.Job.Payload = payload
+		claims = append(claims, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, convertErrType(err)
+	}
+	return claims, nil
+}
+
+const rescheduleWebhookJobQuery = `-- rescheduleWebhookJob
+UPDATE webhook_jobs SET attempt = $2, next_attempt_at = $3 WHERE id = $1;
+`
+
+// RescheduleJob сохраняет количество выполненных попыток доставки и время следующей попытки.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - id: идентификатор ожидающей доставки
+//   - attempt: количество выполненных попыток
+//   - nextAttemptAt: время следующей попытки
+//
+// Возвращает:
+//   - error: repositories.ErrNotFound если доставка не найдена (например, вебхук удален)
+func (r *WebhookRepo) RescheduleJob(ctx context.Context, id string, attempt int, nextAttemptAt time.Time) error {
+	tag, err := r.conn.Exec(ctx, rescheduleWebhookJobQuery, id, attempt, nextAttemptAt)
+	if err != nil {
+		return convertErrType(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repositories.ErrNotFound
+	}
+	return nil
+}
+
+const deleteWebhookJobQuery = `-- deleteWebhookJob
+DELETE FROM webhook_jobs WHERE id = $1;
+`
+
+// DeleteJob удаляет ожидающую доставку после успеха или последней попытки.
+// Отсутствие записи (например, вебхук уже удален) ошибкой не считается.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - id: идентификатор ожидающей доставки
+//
+// Возвращает:
+//   - error: ошибка удаления (преобразованная через convertErrType)
+func (r *WebhookRepo) DeleteJob(ctx context.Context, id string) error {
+	if _, err := r.conn.Exec(ctx, deleteWebhookJobQuery, id); err != nil {
+		return convertErrType(err)
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WorkspaceRepo представляет собой репозиторий рабочих пространств и их участников в PostgreSQL.
+type WorkspaceRepo struct {
+	conn *pgxpool.Pool
+}
+
+// NewWorkspaceRepo создает новый экземпляр репозитория рабочих пространств.
+//
+// Параметры:
+//   - conn: пул подключений к PostgreSQL
+//
+// Возвращает:
+//   - *WorkspaceRepo: инициализированный репозиторий
+func NewWorkspaceRepo(conn *pgxpool.Pool) *WorkspaceRepo {
+	return &WorkspaceRepo{conn: conn}
+}
+
+const createWorkspaceQuery = `-- createWorkspace
+INSERT INTO workspaces (id, name, created_by) VALUES ($1, $2, $3) RETURNING created_at;
+`
+
+const setWorkspaceMemberQuery = `-- setWorkspaceMember
+INSERT INTO workspace_members (workspace_id, visitor_uuid, role) VALUES ($1, $2, $3)
+ON CONFLICT (workspace_id, visitor_uuid) DO UPDATE SET role = EXCLUDED.role
+RETURNING created_at;
+`
+
+// Create сохраняет рабочее пространство и делает его создателя владельцем в одной транзакции.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - w: данные рабочего пространства
+//
+// Возвращает:
+//   - *models.Workspace: созданная запись
+//   - error: ошибка создания (преобразованная через convertErrType)
+func (r *WorkspaceRepo) Create(
+	ctx context.Context,
+	w *models.Workspace,
+) (_ *models.Workspace, err error) { //nolint:nonamedreturns
+	tx, txErr := r.conn.Begin(ctx)
+	if txErr != nil {
+		return nil, convertErrType(txErr)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+			err = errors.Join(err, convertErrType(rollbackErr))
+		}
+	}()
+
+	m := *w
+	if scanErr := tx.QueryRow(ctx, createWorkspaceQuery, m.ID, m.Name, m.CreatedBy).Scan(&m.CreatedAt); scanErr != nil {
+		return nil, convertErrType(scanErr)
+	}
+	if _, execErr := tx.Exec(ctx, setWorkspaceMemberQuery, m.ID, m.CreatedBy, models.WorkspaceRoleOwner); execErr != nil {
+		return nil, convertErrType(execErr)
+	}
+
+	if commitErr := tx.Commit(ctx); commitErr != nil {
+		return nil, convertErrType(fmt.Errorf("commit error: %w", commitErr))
+	}
+	return &m, nil
+}
+
+const getWorkspaceQuery = `-- getWorkspace
+SELECT id, name, created_by, created_at FROM workspaces WHERE id = $1;
+`
+
+// Get получает рабочее пространство.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - id: идентификатор рабочего пространства
+//
+// Возвращает:
+//   - *models.Workspace: найденная запись
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (r *WorkspaceRepo) Get(ctx context.Context, id string) (*models.Workspace, error) {
+	var w models.Workspace
+	err := r.conn.QueryRow(ctx, getWorkspaceQuery, id).Scan(&w.ID, &w.Name, &w.CreatedBy, &w.CreatedAt)
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return &w, nil
+}
+
+const listWorkspacesByMemberQuery = `-- listWorkspacesByMember
+SELECT w.id, w.name, w.created_by, w.created_at, m.role
+FROM workspace_members m JOIN workspaces w ON w.id = m.workspace_id
+WHERE m.visitor_uuid = $1
+ORDER BY w.created_at, w.id;
+`
+
+// ListByMember получает рабочие пространства, в которых состоит посетитель, вместе с его ролью.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - []models.WorkspaceMembership: пространства в порядке создания
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (r *WorkspaceRepo) ListByMember(ctx context.Context, visitorUUID string) ([]models.WorkspaceMembership, error) {
+	rows, qErr := r.conn.Query(ctx, listWorkspacesByMemberQuery, visitorUUID)
+	if qErr != nil {
+		return nil, convertErrType(qErr)
+	}
+	list, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.WorkspaceMembership, error) {
+		var m models.WorkspaceMembership
+		err := row.Scan(&m.Workspace.ID, &m.Workspace.Name, &m.Workspace.CreatedBy, &m.Workspace.CreatedAt, &m.Role)
+		return m, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return list, nil
+}
+
+const getWorkspaceMemberQuery = `-- getWorkspaceMember
+SELECT workspace_id, visitor_uuid, role, created_at FROM workspace_members
+WHERE workspace_id = $1 AND visitor_uuid = $2;
+`
+
+// GetMember получает участника рабочего пространства.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - workspaceID: идентификатор рабочего пространства
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - *models.WorkspaceMember: найденная запись
+//   - error: repositories.ErrNotFound, если посетитель не участник (преобразованная через convertErrType)
+func (r *WorkspaceRepo) GetMember(
+	ctx context.Context,
+	workspaceID string,
+	visitorUUID string,
+) (*models.WorkspaceMember, error) {
+	var m models.WorkspaceMember
+	err := r.conn.QueryRow(ctx, getWorkspaceMemberQuery, workspaceID, visitorUUID).
+		Scan(&m.WorkspaceID, &m.VisitorUUID, &m.Role, &m.CreatedAt)
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return &m, nil
+}
+
+const listWorkspaceMembersQuery = `-- listWorkspaceMembers
+SELECT workspace_id, visitor_uuid, role, created_at FROM workspace_members
+WHERE workspace_id = $1
+ORDER BY created_at, visitor_uuid;
+`
+
+// ListMembers получает участников рабочего пространства.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - workspaceID: идентификатор рабочего пространства
+//
+// Возвращает:
+//   - []models.WorkspaceMember: участники в порядке добавления
+//   - error: ошибка поиска (преобразованная через convertErrType)
+func (r *WorkspaceRepo) ListMembers(ctx context.Context, workspaceID string) ([]models.WorkspaceMember, error) {
+	rows, qErr := r.conn.Query(ctx, listWorkspaceMembersQuery, workspaceID)
+	if qErr != nil {
+		return nil, convertErrType(qErr)
+	}
+	members, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.WorkspaceMember, error) {
+		var m models.WorkspaceMember
+		err := row.Scan(&m.WorkspaceID, &m.VisitorUUID, &m.Role, &m.CreatedAt)
+		return m, err //nolint:wrapcheck
+	})
+	if err != nil {
+		return nil, convertErrType(err)
+	}
+	return members, nil
+}
+
+const lockWorkspaceQuery = `-- lockWorkspace
+SELECT id FROM workspaces WHERE id = $1 FOR UPDATE;
+`
+
+const isLastWorkspaceOwnerQuery = `-- isLastWorkspaceOwner
+SELECT m.role = 'owner' AND NOT EXISTS (
+    SELECT 1 FROM workspace_members o
+    WHERE o.workspace_id = m.workspace_id AND o.visitor_uuid <> m.visitor_uuid AND o.role = 'owner'
+) FROM workspace_members m WHERE m.workspace_id = $1 AND m.visitor_uuid = $2;
+`
+
+// SetMember добавляет участника рабочего пространства или меняет роль существующего.
+// Последнего владельца понизить нельзя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - member: участник и его роль
+//
+// Возвращает:
+//   - *models.WorkspaceMember: сохраненная запись
+//   - error: repositories.ErrLastOwner при понижении последнего владельца,
+//     ошибка сохранения (преобразованная через convertErrType)
+func (r *WorkspaceRepo) SetMember(
+	ctx context.Context,
+	member *models.WorkspaceMember,
+) (*models.WorkspaceMember, error) {
+	m := *member
+	demote := m.Role != models.WorkspaceRoleOwner
+	err := r.changeMember(ctx, m.WorkspaceID, m.VisitorUUID, demote, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, setWorkspaceMemberQuery, m.WorkspaceID, m.VisitorUUID, m.Role).Scan(&m.CreatedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+const deleteWorkspaceMemberQuery = `-- deleteWorkspaceMember
+DELETE FROM workspace_members WHERE workspace_id = $1 AND visitor_uuid = $2;
+`
+
+// DeleteMember удаляет участника рабочего пространства. Последнего владельца удалить нельзя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - workspaceID: идентификатор рабочего пространства
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - error: repositories.ErrNotFound, если посетитель не участник (преобразованная через convertErrType),
+//     repositories.ErrLastOwner при удалении последнего владельца
+func (r *WorkspaceRepo) DeleteMember(ctx context.Context, workspaceID string, visitorUUID string) error {
+	return r.changeMember(ctx, workspaceID, visitorUUID, true, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, deleteWorkspaceMemberQuery, workspaceID, visitorUUID)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+		if tag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+		return nil
+	})
+}
+
+// changeMember выполняет изменение участника fn в транзакции, заблокировав строку рабочего пространства.
+// Блокировка упорядочивает изменения участников одного пространства, поэтому проверка последнего
+// владельца не устаревает до записи. Если demote, а visitorUUID - последний владелец,
+// fn не вызывается и возвращается repositories.ErrLastOwner.
+func (r *WorkspaceRepo) changeMember(
+	ctx context.Context,
+	workspaceID string,
+	visitorUUID string,
+	demote bool,
+	fn func(tx pgx.Tx) error,
+) (err error) { //nolint:nonamedreturns
+	tx, txErr := r.conn.Begin(ctx)
+	if txErr != nil {
+		return convertErrType(txErr)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+			err = errors.Join(err, convertErrType(rollbackErr))
+		}
+	}()
+
+	var lockedID string
+	if lockErr := tx.QueryRow(ctx, lockWorkspaceQuery, workspaceID).Scan(&lockedID); lockErr != nil {
+		return convertErrType(lockErr)
+	}
+	if demote {
+		var lastOwner bool
+		scanErr := tx.QueryRow(ctx, isLastWorkspaceOwnerQuery, workspaceID, visitorUUID).Scan(&lastOwner)
+		if scanErr != nil && !errors.Is(scanErr, pgx.ErrNoRows) {
+			return convertErrType(scanErr)
+		}
+		if lastOwner {
+			return fmt.Errorf("%w: member %s of workspace %s", repositories.ErrLastOwner, visitorUUID, workspaceID)
+		}
+	}
+	if fnErr := fn(tx); fnErr != nil {
+		return convertErrType(fnErr)
+	}
+
+	if commitErr := tx.Commit(ctx); commitErr != nil {
+		return convertErrType(fmt.Errorf("commit error: %w", commitErr))
+	}
+	return nil
+}
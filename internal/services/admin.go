@@ -0,0 +1,304 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/google/uuid"
+)
+
+// Ограничения запросов администратора.
+const (
+	DefaultAdminListLimit     = 50
+	MaxAdminListLimit         = 500
+	MaxModerationReasonLength = 500
+)
+
+// BanResult результат блокировки посетителя.
+type BanResult struct {
+	Ban          *models.VisitorBan
+	DisabledURLs int // Количество ссылок посетителя, отключенных вместе с блокировкой
+}
+
+// AdminService модерирует ссылки и посетителей. Каждое изменение записывается в журнал аудита
+// с UUID администратора, поэтому модерация не требует прямого доступа к базе.
+type AdminService struct {
+	urls  URLRepository
+	bans  VisitorBanRepository
+	audit AuditRepository
+}
+
+// NewAdminService создает новый экземпляр сервиса администратора.
+//
+// Параметры:
+//   - urls: репозиторий ссылок
+//   - bans: репозиторий блокировок посетителей
+//   - audit: репозиторий журнала аудита
+//
+// Возвращает:
+//   - *AdminService: инициализированный сервис
+func NewAdminService(urls URLRepository, bans VisitorBanRepository, audit AuditRepository) *AdminService {
+	return &AdminService{urls: urls, bans: bans, audit: audit}
+}
+
+// SearchURLs ищет ссылки всех посетителей, включая удаленные и отключенные.
+// Лимит по умолчанию DefaultAdminListLimit, больше MaxAdminListLimit не отдается.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - filter: условия поиска
+//
+// Возвращает:
+//   - []models.URL: найденные ссылки от новых к старым
+//   - error: ErrUnknown при ошибке
+func (s *AdminService) SearchURLs(
+	ctx context.Context,
+	filter repositories.URLSearchFilter,
+) (_ []models.URL, err error) {
+	ctx, span := startSpan(ctx, "AdminService.SearchURLs")
+	defer func() { finishSpan(span, err) }()
+
+	filter.Limit = adminListLimit(filter.Limit)
+	filter.Offset = max(filter.Offset, 0)
+	urls, err := s.urls.Search(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: search urls: %s", ErrUnknown, err.Error())
+	}
+	return urls, nil
+}
+
+// DisableURL отключает ссылку. Переход по ней отвечает 451, если legal, иначе 410, и показывает причину.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - actorUUID: UUID администратора
+//   - shortID: короткий идентификатор ссылки
+//   - reason: причина отключения
+//   - legal: отключение по требованию закона
+//
+// Возвращает:
+//   - *models.URL: отключенная ссылка
+//   - error: ErrInvalidArgument без причины, ErrRecordNotFound, если ссылки нет, ErrUnknown при других ошибках
+func (s *AdminService) DisableURL(
+	ctx context.Context,
+	actorUUID string,
+	shortID string,
+	reason string,
+	legal bool,
+) (_ *models.URL, err error) {
+	ctx, span := startSpan(ctx, "AdminService.DisableURL")
+	defer func() { finishSpan(span, err) }()
+
+	reason, err = moderationReason(reason)
+	if err != nil {
+		return nil, err
+	}
+	sURL, err := s.urls.Disable(ctx, shortID, reason, legal)
+	if err != nil {
+		return nil, convertRepoError(err, "disable url")
+	}
+	if err = s.record(ctx, actorUUID, models.AuditURLDisabled, shortID, reason); err != nil {
+		return nil, err
+	}
+	return sURL, nil
+}
+
+// EnableURL снимает отключение ссылки.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - actorUUID: UUID администратора
+//   - shortID: короткий идентификатор ссылки
+//
+// Возвращает:
+//   - *models.URL: включенная ссылка
+//   - error: ErrRecordNotFound, если ссылки нет, ErrUnknown при других ошибках
+func (s *AdminService) EnableURL(ctx context.Context, actorUUID string, shortID string) (_ *models.URL, err error) {
+	ctx, span := startSpan(ctx, "AdminService.EnableURL")
+	defer func() { finishSpan(span, err) }()
+
+	sURL, err := s.urls.Enable(ctx, shortID)
+	if err != nil {
+		return nil, convertRepoError(err, "enable url")
+	}
+	if err = s.record(ctx, actorUUID, models.AuditURLEnabled, shortID, ""); err != nil {
+		return nil, err
+	}
+	return sURL, nil
+}
+
+// BanVisitor блокирует посетителя. Если disableLinks, все его действующие ссылки
+// отключаются с той же причиной (переход отвечает 410).
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - actorUUID: UUID администратора
+//   - visitorUUID: UUID блокируемого посетителя
+//   - reason: причина блокировки
+//   - disableLinks: отключить ссылки посетителя
+//
+// Возвращает:
+//   - *BanResult: блокировка и количество отключенных ссылок
+//   - error: ErrInvalidArgument при некорректном UUID, без причины или при блокировке самого себя,
+//     ErrDuplicateKey, если посетитель уже заблокирован, ErrUnknown при других ошибках
+func (s *AdminService) BanVisitor(
+	ctx context.Context,
+	actorUUID string,
+	visitorUUID string,
+	reason string,
+	disableLinks bool,
+) (_ *BanResult, err error) {
+	ctx, span := startSpan(ctx, "AdminService.BanVisitor")
+	defer func() { finishSpan(span, err) }()
+
+	if uuid.Validate(visitorUUID) != nil {
+		return nil, fmt.Errorf("%w: visitor uuid is not a uuid", ErrInvalidArgument)
+	}
+	if visitorUUID == actorUUID {
+		return nil, fmt.Errorf("%w: cannot ban yourself", ErrInvalidArgument)
+	}
+	reason, err = moderationReason(reason)
+	if err != nil {
+		return nil, err
+	}
+
+	ban, err := s.bans.Create(ctx, &models.VisitorBan{VisitorUUID: visitorUUID, Reason: reason, BannedBy: actorUUID})
+	if err != nil {
+		return nil, convertRepoError(err, "ban visitor")
+	}
+	res := &BanResult{Ban: ban}
+	if disableLinks {
+		if res.DisabledURLs, err = s.urls.DisableByVisitorUUID(ctx, visitorUUID, reason); err != nil {
+			return nil, fmt.Errorf("%w: disable urls of banned visitor: %s", ErrUnknown, err.Error())
+		}
+	}
+	if err = s.record(ctx, actorUUID, models.AuditVisitorBanned, visitorUUID, reason); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// UnbanVisitor снимает блокировку посетителя. Отключенные при блокировке ссылки остаются отключенными.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - actorUUID: UUID администратора
+//   - visitorUUID: UUID посетителя
+//
+// Возвращает:
+//   - error: ErrRecordNotFound, если посетитель не заблокирован, ErrUnknown при других ошибках
+func (s *AdminService) UnbanVisitor(ctx context.Context, actorUUID string, visitorUUID string) (err error) {
+	ctx, span := startSpan(ctx, "AdminService.UnbanVisitor")
+	defer func() { finishSpan(span, err) }()
+
+	if err := s.bans.Delete(ctx, visitorUUID); err != nil {
+		return convertRepoError(err, "unban visitor")
+	}
+	return s.record(ctx, actorUUID, models.AuditVisitorUnbanned, visitorUUID, "")
+}
+
+// IsBanned проверяет, заблокирован ли посетитель.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: UUID посетителя
+//
+// Возвращает:
+//   - bool: true, если посетитель заблокирован
+//   - error: ErrUnknown при ошибке
+func (s *AdminService) IsBanned(ctx context.Context, visitorUUID string) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "AdminService.IsBanned")
+	defer func() { finishSpan(span, err) }()
+
+	if _, err := s.bans.Get(ctx, visitorUUID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("%w: get visitor ban: %s", ErrUnknown, err.Error())
+	}
+	return true, nil
+}
+
+// AuditLog возвращает записи журнала аудита от новых к старым.
+// Лимит по умолчанию DefaultAdminListLimit, больше MaxAdminListLimit не отдается.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - filter: условия выборки
+//
+// Возвращает:
+//   - []models.AuditEntry: записи журнала
+//   - error: ErrUnknown при ошибке
+func (s *AdminService) AuditLog(
+	ctx context.Context,
+	filter repositories.AuditFilter,
+) (_ []models.AuditEntry, err error) {
+	ctx, span := startSpan(ctx, "AdminService.AuditLog")
+	defer func() { finishSpan(span, err) }()
+
+	filter.Limit = adminListLimit(filter.Limit)
+	entries, err := s.audit.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: list audit entries: %s", ErrUnknown, err.Error())
+	}
+	return entries, nil
+}
+
+// record добавляет действие администратора в журнал аудита. К этому моменту действие уже выполнено,
+// поэтому ошибка записи возвращается как ErrUnknown, чтобы администратор повторил запрос.
+func (s *AdminService) record(
+	ctx context.Context,
+	actorUUID string,
+	action models.AuditAction,
+	target string,
+	reason string,
+) error {
+	_, err := s.audit.Create(ctx, &models.AuditEntry{
+		ID:        uuid.NewString(),
+		ActorUUID: actorUUID,
+		Action:    action,
+		Target:    target,
+		Reason:    reason,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: record audit entry %s: %s", ErrUnknown, action, err.Error())
+	}
+	return nil
+}
+
+// moderationReason проверяет причину модерации и убирает пробелы по краям.
+func moderationReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return "", fmt.Errorf("%w: reason is required", ErrInvalidArgument)
+	}
+	if utf8.RuneCountInString(reason) > MaxModerationReasonLength {
+		return "", fmt.Errorf("%w: reason is longer than %d characters", ErrInvalidArgument, MaxModerationReasonLength)
+	}
+	return reason, nil
+}
+
+// adminListLimit приводит лимит выборки к допустимому диапазону.
+func adminListLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultAdminListLimit
+	}
+	return min(limit, MaxAdminListLimit)
+}
+
+// convertRepoError преобразует ошибку репозитория в ошибку сервиса: ErrRecordNotFound, ErrDuplicateKey или ErrUnknown.
+func convertRepoError(err error, op string) error {
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		return ErrRecordNotFound
+	case errors.Is(err, repositories.ErrDuplicateKey):
+		return ErrDuplicateKey
+	default:
+		return fmt.Errorf("%w: %s: %s", ErrUnknown, op, err.Error())
+	}
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/fsdevblog/shorturl/internal/repositories/memstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAdminUUID   = "1c2d3e4f-5a6b-4c7d-8e9f-0a1b2c3d4e5f"
+	testBannedUUID  = "9f8e7d6c-5b4a-4392-8170-6f5e4d3c2b1a"
+	testOtherUUID   = "2b3c4d5e-6f70-4182-93a4-b5c6d7e8f901"
+	testAdminReason = "phishing"
+)
+
+func newTestAdminService(t *testing.T) (*AdminService, *memstore.URLRepo) {
+	t.Helper()
+	store := db.NewMemStorage()
+	urls := memstore.NewURLRepo(store)
+	seed := []models.URL{
+		{ShortIdentifier: "banned01", URL: "https://Example.com/a", VisitorUUID: testBannedUUID},
+		{ShortIdentifier: "banned02", URL: "https://example.org/b", VisitorUUID: testBannedUUID},
+		{ShortIdentifier: "other001", URL: "https://other.com/c", VisitorUUID: testOtherUUID},
+	}
+	for i := range seed {
+		_, _, err := urls.Create(context.Background(), &seed[i])
+		require.NoError(t, err)
+	}
+	return NewAdminService(urls, memstore.NewVisitorBanRepo(store), memstore.NewAuditRepo(store)), urls
+}
+
+func TestAdminService_DisableURL(t *testing.T) {
+	ctx := context.Background()
+	svc, urls := newTestAdminService(t)
+
+	_, err := svc.DisableURL(ctx, testAdminUUID, "other001", "  ", false)
+	require.ErrorIs(t, err, ErrInvalidArgument)
+	_, err = svc.DisableURL(ctx, testAdminUUID, "other001", strings.Repeat("я", MaxModerationReasonLength+1), false)
+	require.ErrorIs(t, err, ErrInvalidArgument)
+	_, err = svc.DisableURL(ctx, testAdminUUID, "unknown1", testAdminReason, false)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	sURL, err := svc.DisableURL(ctx, testAdminUUID, "other001", " court order ", true)
+	require.NoError(t, err)
+	assert.Equal(t, models.URLStateDisabled, sURL.State())
+	assert.Equal(t, "court order", sURL.DisabledReason)
+	assert.True(t, sURL.DisabledLegal)
+
+	// Отключенная ссылка не считается переходом, но видна в поиске администратора.
+	stored, err := urls.GetByShortIdentifier(ctx, "other001")
+	require.NoError(t, err)
+	assert.Equal(t, models.URLStateDisabled, stored.State())
+	found, err := svc.SearchURLs(ctx, repositories.URLSearchFilter{OnlyDisabled: true})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "other001", found[0].ShortIdentifier)
+
+	sURL, err = svc.EnableURL(ctx, testAdminUUID, "other001")
+	require.NoError(t, err)
+	assert.Equal(t, models.URLStateActive, sURL.State())
+	assert.Empty(t, sURL.DisabledReason)
+
+	entries, err := svc.AuditLog(ctx, repositories.AuditFilter{Target: "other001"})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, models.AuditURLEnabled, entries[0].Action)
+	assert.Equal(t, models.AuditURLDisabled, entries[1].Action)
+	assert.Equal(t, "court order", entries[1].Reason)
+	assert.Equal(t, testAdminUUID, entries[1].ActorUUID)
+}
+
+func TestAdminService_BanVisitor(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestAdminService(t)
+
+	_, err := svc.BanVisitor(ctx, testAdminUUID, "not-a-uuid", testAdminReason, false)
+	require.ErrorIs(t, err, ErrInvalidArgument)
+	_, err = svc.BanVisitor(ctx, testAdminUUID, testAdminUUID, testAdminReason, false)
+	require.ErrorIs(t, err, ErrInvalidArgument)
+	_, err = svc.BanVisitor(ctx, testAdminUUID, testBannedUUID, "", false)
+	require.ErrorIs(t, err, ErrInvalidArgument)
+
+	res, err := svc.BanVisitor(ctx, testAdminUUID, testBannedUUID, testAdminReason, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.DisabledURLs)
+	assert.Equal(t, testAdminUUID, res.Ban.BannedBy)
+
+	_, err = svc.BanVisitor(ctx, testAdminUUID, testBannedUUID, testAdminReason, false)
+	require.ErrorIs(t, err, ErrDuplicateKey)
+
+	banned, err := svc.IsBanned(ctx, testBannedUUID)
+	require.NoError(t, err)
+	assert.True(t, banned)
+	banned, err = svc.IsBanned(ctx, testOtherUUID)
+	require.NoError(t, err)
+	assert.False(t, banned)
+
+	found, err := svc.SearchURLs(ctx, repositories.URLSearchFilter{VisitorUUID: testBannedUUID})
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	for _, sURL := range found {
+		assert.Equal(t, models.URLStateDisabled, sURL.State())
+		assert.False(t, sURL.DisabledLegal)
+	}
+
+	// Снятие блокировки не включает ссылки обратно.
+	require.NoError(t, svc.UnbanVisitor(ctx, testAdminUUID, testBannedUUID))
+	require.ErrorIs(t, svc.UnbanVisitor(ctx, testAdminUUID, testBannedUUID), ErrRecordNotFound)
+	banned, err = svc.IsBanned(ctx, testBannedUUID)
+	require.NoError(t, err)
+	assert.False(t, banned)
+	found, err = svc.SearchURLs(ctx, repositories.URLSearchFilter{OnlyDisabled: true})
+	require.NoError(t, err)
+	assert.Len(t, found, 2)
+
+	entries, err := svc.AuditLog(ctx, repositories.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, models.AuditVisitorUnbanned, entries[0].Action)
+	assert.Equal(t, models.AuditVisitorBanned, entries[1].Action)
+}
+
+func TestAdminService_SearchURLs(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestAdminService(t)
+
+	tests := []struct {
+		name   string
+		filter repositories.URLSearchFilter
+		want   int
+	}{
+		{name: "all", want: 3},
+		{name: "url substring ignores case", filter: repositories.URLSearchFilter{Query: "EXAMPLE"}, want: 2},
+		{name: "short id", filter: repositories.URLSearchFilter{Query: "other001"}, want: 1},
+		{name: "visitor", filter: repositories.URLSearchFilter{VisitorUUID: testOtherUUID}, want: 1},
+		{name: "page", filter: repositories.URLSearchFilter{Limit: 2, Offset: 2}, want: 1},
+		{name: "negative offset", filter: repositories.URLSearchFilter{Offset: -1}, want: 3},
+		{name: "nothing", filter: repositories.URLSearchFilter{Query: "missing"}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := svc.SearchURLs(ctx, tt.filter)
+			require.NoError(t, err)
+			assert.Len(t, found, tt.want)
+		})
+	}
+}
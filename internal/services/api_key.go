@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/google/uuid"
+)
+
+// Формат ключа API: surl_<id>_<secret>, где surl_<id> - открытый префикс,
+// по которому ключ находится в хранилище и опознается в списке ключей.
+const (
+	APIKeyTokenPrefix   = "surl_"
+	MaxAPIKeyNameLength = 255
+	apiKeyIDBytes       = 6
+	apiKeySecretBytes   = 32
+)
+
+// APIKeyServiceOptions опции сервиса ключей API.
+type APIKeyServiceOptions struct {
+	Now func() time.Time // Источник времени (для тестов)
+}
+
+// APIKeyService выпускает, отзывает и проверяет ключи API посетителей.
+// Ключ API позволяет скриптам действовать от имени посетителя без cookie.
+type APIKeyService struct {
+	repo APIKeyRepository
+	opts APIKeyServiceOptions
+}
+
+// NewAPIKeyService создает новый экземпляр сервиса ключей API.
+//
+// Параметры:
+//   - repo: репозиторий ключей API
+//   - opts: функции для настройки опций
+//
+// Возвращает:
+//   - *APIKeyService: инициализированный сервис
+func NewAPIKeyService(repo APIKeyRepository, opts ...func(*APIKeyServiceOptions)) *APIKeyService {
+	options := APIKeyServiceOptions{Now: time.Now}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &APIKeyService{repo: repo, opts: options}
+}
+
+// Create выпускает новый ключ API посетителя. В хранилище сохраняется только хеш ключа.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//   - name: произвольное название ключа
+//
+// Возвращает:
+//   - *models.APIKey: созданный ключ
+//   - string: ключ целиком; повторно получить его нельзя
+//   - error: ErrInvalidArgument при слишком длинном названии, ErrUnknown при других ошибках
+func (s *APIKeyService) Create(
+	ctx context.Context,
+	visitorUUID string,
+	name string,
+) (_ *models.APIKey, _ string, err error) {
+	ctx, span := startSpan(ctx, "APIKeyService.Create")
+	defer func() { finishSpan(span, err) }()
+
+	if utf8.RuneCountInString(name) > MaxAPIKeyNameLength {
+		return nil, "", fmt.Errorf("%w: name is longer than %d characters", ErrInvalidArgument, MaxAPIKeyNameLength)
+	}
+
+	id, idErr := randomHex(apiKeyIDBytes)
+	secret, secretErr := randomHex(apiKeySecretBytes)
+	if err := errors.Join(idErr, secretErr); err != nil {
+		return nil, "", fmt.Errorf("%w: generate api key: %s", ErrUnknown, err.Error())
+	}
+	prefix := APIKeyTokenPrefix + id
+	token := prefix + "_" + secret
+
+	key, err := s.repo.Create(ctx, &models.APIKey{
+		ID:          uuid.NewString(),
+		VisitorUUID: visitorUUID,
+		Name:        name,
+		Prefix:      prefix,
+		Hash:        hashAPIKey(token),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: create api key: %s", ErrUnknown, err.Error())
+	}
+	return key, token, nil
+}
+
+// GetAllByVisitorUUID возвращает ключи посетителя, включая отозванные.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - []models.APIKey: ключи в порядке создания
+//   - error: ErrUnknown при ошибке
+func (s *APIKeyService) GetAllByVisitorUUID(ctx context.Context, visitorUUID string) (_ []models.APIKey, err error) {
+	ctx, span := startSpan(ctx, "APIKeyService.GetAllByVisitorUUID")
+	defer func() { finishSpan(span, err) }()
+
+	keys, err := s.repo.GetAllByVisitorUUID(ctx, visitorUUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: get api keys: %s", ErrUnknown, err.Error())
+	}
+	return keys, nil
+}
+
+// Revoke отзывает ключ посетителя. Отозванный ключ больше не принимается.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//   - id: идентификатор ключа
+//
+// Возвращает:
+//   - error: ErrRecordNotFound, если действующего ключа нет, ErrUnknown при других ошибках
+func (s *APIKeyService) Revoke(ctx context.Context, visitorUUID string, id string) (err error) {
+	ctx, span := startSpan(ctx, "APIKeyService.Revoke")
+	defer func() { finishSpan(span, err) }()
+
+	if err := s.repo.Revoke(ctx, id, visitorUUID, s.opts.Now().UTC()); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrRecordNotFound
+		}
+		return fmt.Errorf("%w: revoke api key: %s", ErrUnknown, err.Error())
+	}
+	return nil
+}
+
+// Authenticate проверяет ключ API и возвращает UUID посетителя, которому он выдан.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - token: ключ целиком
+//
+// Возвращает:
+//   - string: UUID посетителя
+//   - error: ErrInvalidAPIKey, если ключ неизвестен, отозван или не совпал, ErrUnknown при других ошибках
+func (s *APIKeyService) Authenticate(ctx context.Context, token string) (_ string, err error) {
+	ctx, span := startSpan(ctx, "APIKeyService.Authenticate")
+	defer func() { finishSpan(span, err) }()
+
+	id, _, ok := strings.Cut(strings.TrimPrefix(token, APIKeyTokenPrefix), "_")
+	if !ok || !strings.HasPrefix(token, APIKeyTokenPrefix) || id == "" {
+		return "", ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.GetByPrefix(ctx, APIKeyTokenPrefix+id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return "", ErrInvalidAPIKey
+		}
+		return "", fmt.Errorf("%w: get api key: %s", ErrUnknown, err.Error())
+	}
+	if key.Revoked() || subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashAPIKey(token))) != 1 {
+		return "", ErrInvalidAPIKey
+	}
+	return key.VisitorUUID, nil
+}
+
+// hashAPIKey возвращает SHA-256 ключа в hex. Ключ содержит 256 случайных бит,
+// поэтому медленная хеш-функция для него не нужна.
+func hashAPIKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex возвращает n случайных байт в hex.
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("read random: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/repositories/memstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAPIKeyVisitor = "5b9e2f0c-7a1d-4c3e-9f8b-6d5c4b3a2e1f"
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	svc := NewAPIKeyService(memstore.NewAPIKeyRepo(db.NewMemStorage()))
+
+	key, token, err := svc.Create(ctx, testAPIKeyVisitor, "ci")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, key.Prefix+"_"))
+	assert.NotContains(t, key.Hash, token)
+
+	visitorUUID, err := svc.Authenticate(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, testAPIKeyVisitor, visitorUUID)
+
+	invalid := []string{
+		"",
+		"surl_",
+		"surl_unknown_secret",
+		strings.TrimPrefix(token, APIKeyTokenPrefix),
+		key.Prefix + "_" + strings.Repeat("0", 64),
+	}
+	for _, tok := range invalid {
+		_, authErr := svc.Authenticate(ctx, tok)
+		require.ErrorIs(t, authErr, ErrInvalidAPIKey, "token %q", tok)
+	}
+
+	// Чужой посетитель не может отозвать ключ.
+	require.ErrorIs(t, svc.Revoke(ctx, "other-visitor", key.ID), ErrRecordNotFound)
+
+	require.NoError(t, svc.Revoke(ctx, testAPIKeyVisitor, key.ID))
+	_, err = svc.Authenticate(ctx, token)
+	require.ErrorIs(t, err, ErrInvalidAPIKey)
+	require.ErrorIs(t, svc.Revoke(ctx, testAPIKeyVisitor, key.ID), ErrRecordNotFound)
+
+	_, _, err = svc.Create(ctx, testAPIKeyVisitor, strings.Repeat("я", MaxAPIKeyNameLength+1))
+	require.ErrorIs(t, err, ErrInvalidArgument)
+
+	keys, err := svc.GetAllByVisitorUUID(ctx, testAPIKeyVisitor)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.True(t, keys[0].Revoked())
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/db/memory"
+	"github.com/fsdevblog/shorturl/internal/models"
+)
+
+// maxBackupLineSize максимальный размер одной записи файла бэкапа.
+const maxBackupLineSize = 16 << 20
+
+// Backuper сохраняет данные хранилища в файл при остановке и восстанавливает их при запуске.
+type Backuper interface {
+	// Backup сохраняет данные в файл path.
+	Backup(ctx context.Context, path string) error
+	// RestoreBackup восстанавливает данные из файла path. Отсутствующий файл означает пустое хранилище.
+	RestoreBackup(ctx context.Context, path string) error
+}
+
+// backupRecord строка файла бэкапа in-memory хранилища. Collection пуста для ссылок,
+// которые хранятся во встроенном хранилище db.MemoryStorage.
+type backupRecord struct {
+	Collection string          `json:"collection"`
+	Key        string          `json:"key"`
+	Value      json.RawMessage `json:"value"`
+}
+
+// MemoryBackupService сохраняет в файл и восстанавливает все данные in-memory хранилища: ссылки
+// и именованные коллекции (пользователи, ключи API, рабочие пространства, блокировки, вебхуки и т.д.).
+// Записи копируются в сериализованном виде, поэтому связи между ними (владелец ссылки, участники
+// пространства) переживают перезапуск без изменений.
+type MemoryBackupService struct {
+	store *db.MemoryStorage
+}
+
+// NewMemoryBackupService создает сервис бэкапа in-memory хранилища.
+//
+// Параметры:
+//   - store: in-memory хранилище
+//
+// Возвращает:
+//   - *MemoryBackupService: новый экземпляр сервиса
+func NewMemoryBackupService(store *db.MemoryStorage) *MemoryBackupService {
+	return &MemoryBackupService{store: store}
+}
+
+// Backup сохраняет все записи хранилища в файл, по одной записи backupRecord в строке.
+// Файл сначала пишется во временный и затем переименовывается, поэтому сбой во время записи
+// не портит предыдущий бэкап.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - path: путь к файлу бэкапа
+//
+// Возвращает:
+//   - error: ошибка создания бэкапа
+func (b *MemoryBackupService) Backup(ctx context.Context, path string) (err error) {
+	tmpPath := path + ".tmp"
+	backupFile, backupFileErr := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if backupFileErr != nil {
+		return fmt.Errorf("open backup file: %w", backupFileErr)
+	}
+	defer func() {
+		if errClose := backupFile.Close(); errClose != nil && !errors.Is(errClose, os.ErrClosed) {
+			err = errors.Join(err, fmt.Errorf("close backup file: %w", errClose))
+		}
+		if err != nil {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	w := bufio.NewWriter(backupFile)
+	enc := json.NewEncoder(w)
+	writeCollection := func(name string, collection *memory.MStorage) error {
+		return collection.ForEachRaw(ctx, func(key string, value []byte) error {
+			if encErr := enc.Encode(backupRecord{Collection: name, Key: key, Value: value}); encErr != nil {
+				return fmt.Errorf("write record %s of collection %q: %w", key, name, encErr)
+			}
+			return nil
+		})
+	}
+
+	if err = writeCollection("", b.store.MStorage); err != nil {
+		return err
+	}
+	for _, name := range b.store.CollectionNames() {
+		if err = writeCollection(name, b.store.Collection(name)); err != nil {
+			return err
+		}
+	}
+	if err = w.Flush(); err != nil {
+		return fmt.Errorf("write backup file: %w", err)
+	}
+	if err = backupFile.Close(); err != nil {
+		return fmt.Errorf("close backup file: %w", err)
+	}
+	if err = os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("replace backup file: %w", err)
+	}
+	return nil
+}
+
+// RestoreBackup восстанавливает записи хранилища из файла бэкапа.
+// Поддерживается и формат прежних версий, в котором каждая строка - ссылка models.URL.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - path: путь к файлу бэкапа
+//
+// Возвращает:
+//   - error: ошибка восстановления
+func (b *MemoryBackupService) RestoreBackup(ctx context.Context, path string) (err error) {
+	file, fileErr := os.OpenFile(path, os.O_RDONLY|os.O_CREATE, 0644)
+	if fileErr != nil {
+		return fmt.Errorf("open backup file: %w", fileErr)
+	}
+	defer func() {
+		if errClose := file.Close(); errClose != nil {
+			err = fmt.Errorf("close backup file: %w", errClose)
+		}
+	}()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxBackupLineSize)
+	for scanner.Scan() {
+		var record backupRecord
+		if jsonErr := json.Unmarshal(scanner.Bytes(), &record); jsonErr != nil {
+			return fmt.Errorf("unmarshal record: %w", jsonErr)
+		}
+		if record.Key == "" {
+			// Формат прежних версий: строка целиком - ссылка встроенного хранилища.
+			var url models.URL
+			if jsonErr := json.Unmarshal(scanner.Bytes(), &url); jsonErr != nil || url.ShortIdentifier == "" {
+				return fmt.Errorf("unmarshal record: unknown record %s", scanner.Text())
+			}
+			record = backupRecord{Key: url.ShortIdentifier, Value: scanner.Bytes()}
+		}
+
+		collection := b.store.MStorage
+		if record.Collection != "" {
+			collection = b.store.Collection(record.Collection)
+		}
+		if setErr := collection.SetRaw(ctx, record.Key, record.Value); setErr != nil {
+			return fmt.Errorf("restore record %s of collection %q: %w", record.Key, record.Collection, setErr)
+		}
+	}
+	if scanErr := scanner.Err(); scanErr != nil {
+		return fmt.Errorf("read backup file: %w", scanErr)
+	}
+	return nil
+}
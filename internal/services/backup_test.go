@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBackupService_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "backup.json")
+	visitor := uuid.NewString()
+
+	before, err := Factory(db.NewMemStorage(), ServiceTypeInMemory)
+	require.NoError(t, err)
+	user, err := before.UserService.Register(ctx, visitor, "user@example.com", "correct horse")
+	require.NoError(t, err)
+	link, _, err := before.URLService.Create(ctx, user.ID, "https://example.com")
+	require.NoError(t, err)
+	_, _, err = before.APIKeyService.Create(ctx, user.ID, "ci")
+	require.NoError(t, err)
+	ws, err := before.WorkspaceService.Create(ctx, user.ID, "Marketing")
+	require.NoError(t, err)
+	require.NoError(t, before.BackupService.Backup(ctx, path))
+
+	// Сервисы создаются до восстановления, как при запуске приложения.
+	after, err := Factory(db.NewMemStorage(), ServiceTypeInMemory)
+	require.NoError(t, err)
+	require.NoError(t, after.BackupService.RestoreBackup(ctx, path))
+
+	res, err := after.UserService.Login(ctx, uuid.NewString(), "user@example.com", "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, res.User.ID)
+
+	urls, err := after.URLService.GetAllByVisitorUUID(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	assert.Equal(t, link.ShortIdentifier, urls[0].ShortIdentifier)
+
+	keys, err := after.APIKeyService.GetAllByVisitorUUID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+
+	workspaces, err := after.WorkspaceService.List(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, workspaces, 1)
+	assert.Equal(t, ws.ID, workspaces[0].Workspace.ID)
+}
+
+func TestMemoryBackupService_RestoreLegacyFormat(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "backup.json")
+	visitor := uuid.NewString()
+	legacy := `{"shortIdentifier":"abcdefgh","url":"https://example.com","visitorUUID":"` + visitor + `"}` + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(legacy), 0o600))
+
+	svc, err := Factory(db.NewMemStorage(), ServiceTypeInMemory)
+	require.NoError(t, err)
+	require.NoError(t, svc.BackupService.RestoreBackup(ctx, path))
+
+	m, err := svc.URLService.GetByShortIdentifier(ctx, "abcdefgh")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", m.URL)
+	assert.Equal(t, visitor, m.VisitorUUID)
+}
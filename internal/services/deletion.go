@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/google/uuid"
+)
+
+// Параметры очереди удаления по умолчанию.
+const (
+	DefaultDeletionQueueSize     = 1024                   // Количество задач, ожидающих обработки
+	DefaultDeletionBatchSize     = 1000                   // Размер пакета, при котором он удаляется сразу
+	DefaultDeletionFlushInterval = 100 * time.Millisecond // Максимальное время ожидания пакета
+	DefaultDeletionJobTTL        = time.Hour              // Время хранения завершенных задач
+	deletionFlushTimeout         = 30 * time.Second       // Таймаут удаления одного пакета
+)
+
+// deletionJobFailedMessage сообщение об ошибке задачи, отдаваемое клиенту.
+const deletionJobFailedMessage = "failed to delete urls"
+
+// DeletionServiceOptions опции очереди удаления.
+type DeletionServiceOptions struct {
+	QueueSize     int              // Количество задач, ожидающих обработки
+	BatchSize     int              // Количество идентификаторов, после которого пакет удаляется сразу
+	FlushInterval time.Duration    // Максимальное время ожидания пакета
+	JobTTL        time.Duration    // Время хранения завершенных задач
+	OnError       func(err error)  // Обработчик ошибок удаления
+	Now           func() time.Time // Источник времени (для тестов)
+}
+
+// deletionTask задача удаления в очереди.
+type deletionTask struct {
+	jobID       string
+	visitorUUID string
+	shortIDs    []string
+}
+
+// DeletionService очередь фонового удаления ссылок.
+// Запросы на удаление от разных посетителей накапливаются и удаляются общими пакетами
+// (fan-in) одним вызовом BatchDeleter. Состояние каждого запроса доступно по идентификатору задачи.
+// При остановке очередь перестает принимать задачи и дорабатывает уже принятые.
+type DeletionService struct {
+	deleter BatchDeleter
+	opts    DeletionServiceOptions
+	tasks   chan deletionTask
+
+	mu     sync.RWMutex
+	jobs   map[string]*models.DeletionJob
+	closed bool
+}
+
+// NewDeletionService создает новую очередь удаления.
+//
+// Параметры:
+//   - deleter: исполнитель пакетного удаления
+//   - opts: функции для настройки опций
+//
+// Возвращает:
+//   - *DeletionService: инициализированный сервис
+func NewDeletionService(deleter BatchDeleter, opts ...func(*DeletionServiceOptions)) *DeletionService {
+	options := DeletionServiceOptions{
+		QueueSize:     DefaultDeletionQueueSize,
+		BatchSize:     DefaultDeletionBatchSize,
+		FlushInterval: DefaultDeletionFlushInterval,
+		JobTTL:        DefaultDeletionJobTTL,
+		OnError:       func(error) {},
+		Now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &DeletionService{
+		deleter: deleter,
+		opts:    options,
+		tasks:   make(chan deletionTask, max(options.QueueSize, 1)),
+		jobs:    make(map[string]*models.DeletionJob),
+	}
+}
+
+// Enqueue ставит удаление ссылок посетителя в очередь.
+// Повторяющиеся идентификаторы удаляются один раз.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//   - shortIDs: короткие идентификаторы ссылок
+//
+// Возвращает:
+//   - *models.DeletionJob: созданная задача
+//   - error: ErrInvalidArgument, ErrDeletionQueueFull или ErrDeletionQueueClosed
+func (s *DeletionService) Enqueue(
+	ctx context.Context,
+	visitorUUID string,
+	shortIDs []string,
+) (_ *models.DeletionJob, err error) {
+	_, span := startSpan(ctx, "DeletionService.Enqueue")
+	defer func() { finishSpan(span, err) }()
+
+	ids := slices.Compact(slices.Sorted(slices.Values(shortIDs)))
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: no short ids", ErrInvalidArgument)
+	}
+
+	job := &models.DeletionJob{
+		ID:          uuid.NewString(),
+		VisitorUUID: visitorUUID,
+		Status:      models.DeletionJobPending,
+		Requested:   len(ids),
+		CreatedAt:   s.opts.Now().UTC(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, ErrDeletionQueueClosed
+	}
+	select {
+	case s.tasks <- deletionTask{jobID: job.ID, visitorUUID: visitorUUID, shortIDs: ids}:
+	default:
+		return nil, ErrDeletionQueueFull
+	}
+	s.jobs[job.ID] = job
+	res := *job
+	return &res, nil
+}
+
+// Job возвращает задачу удаления посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//   - id: идентификатор задачи
+//
+// Возвращает:
+//   - *models.DeletionJob: задача
+//   - error: ErrRecordNotFound, если задачи нет, она принадлежит другому посетителю или уже забыта
+func (s *DeletionService) Job(_ context.Context, visitorUUID string, id string) (*models.DeletionJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[id]
+	if !ok || job.VisitorUUID != visitorUUID {
+		return nil, ErrRecordNotFound
+	}
+	res := *job
+	return &res, nil
+}
+
+// Run обрабатывает очередь до отмены контекста.
+// Пакет удаляется, как только в нем набирается BatchSize идентификаторов, либо раз в FlushInterval.
+// После отмены контекста очередь закрывается для новых задач, а принятые задачи выполняются
+// до конца, поэтому вызывающему следует дождаться возврата из Run перед остановкой хранилища.
+//
+// Параметры:
+//   - ctx: контекст работы сервиса
+func (s *DeletionService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+	// Удаление не прерываем вместе с ctx: начатый пакет должен завершиться.
+	flushCtx := context.WithoutCancel(ctx)
+	var batch []deletionTask
+	var size int
+	for {
+		select {
+		case <-ctx.Done():
+			s.drain(flushCtx, batch, size)
+			return
+		case task := <-s.tasks:
+			batch = append(batch, task)
+			size += len(task.shortIDs)
+			if size >= s.opts.BatchSize {
+				s.flush(flushCtx, batch)
+				batch, size = nil, 0
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				s.flush(flushCtx, batch)
+				batch, size = nil, 0
+			}
+			s.evictFinished()
+		}
+	}
+}
+
+// drain закрывает очередь и выполняет все принятые задачи.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - batch: накопленный, но еще не выполненный пакет
+//   - size: количество идентификаторов в пакете
+func (s *DeletionService) drain(ctx context.Context, batch []deletionTask, size int) {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	// После закрытия задачи в канал не добавляются, поэтому чтение без блокировки опустошает очередь.
+	for {
+		select {
+		case task := <-s.tasks:
+			batch = append(batch, task)
+			size += len(task.shortIDs)
+			if size >= s.opts.BatchSize {
+				s.flush(ctx, batch)
+				batch, size = nil, 0
+			}
+		default:
+			if len(batch) > 0 {
+				s.flush(ctx, batch)
+			}
+			return
+		}
+	}
+}
+
+// flush удаляет ссылки пакета задач одним вызовом и обновляет состояние задач.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - batch: задачи пакета
+func (s *DeletionService) flush(ctx context.Context, batch []deletionTask) {
+	ctx, cancel := context.WithTimeout(ctx, deletionFlushTimeout)
+	defer cancel()
+
+	s.updateJobs(batch, func(job *models.DeletionJob, _ deletionTask) {
+		job.Status = models.DeletionJobRunning
+	})
+
+	// Задачи одного посетителя объединяются в один аргумент.
+	var args []repositories.BatchDeleteArg
+	argIdx := make(map[string]int)
+	for _, task := range batch {
+		i, ok := argIdx[task.visitorUUID]
+		if !ok {
+			i = len(args)
+			argIdx[task.visitorUUID] = i
+			args = append(args, repositories.BatchDeleteArg{VisitorUUID: task.visitorUUID})
+		}
+		args[i].ShortIDs = append(args[i].ShortIDs, task.shortIDs...)
+	}
+
+	spanCtx, span := startSpan(ctx, "DeletionService.flush")
+	deleted, err := s.deleter.BatchMarkAsDeleted(spanCtx, args)
+	finishSpan(span, err)
+
+	finishedAt := s.opts.Now().UTC()
+	if err != nil {
+		s.opts.OnError(fmt.Errorf("delete urls of %d jobs: %w", len(batch), err))
+		s.updateJobs(batch, func(job *models.DeletionJob, _ deletionTask) {
+			job.Status = models.DeletionJobFailed
+			job.Error = deletionJobFailedMessage
+			job.FinishedAt = &finishedAt
+		})
+		return
+	}
+
+	deletedIDs := make(map[string]map[string]struct{}, len(args))
+	for _, m := range deleted {
+		if deletedIDs[m.VisitorUUID] == nil {
+			deletedIDs[m.VisitorUUID] = make(map[string]struct{})
+		}
+		deletedIDs[m.VisitorUUID][m.ShortIdentifier] = struct{}{}
+	}
+	s.updateJobs(batch, func(job *models.DeletionJob, task deletionTask) {
+		for _, shortID := range task.shortIDs {
+			if _, ok := deletedIDs[task.visitorUUID][shortID]; ok {
+				job.Deleted++
+			}
+		}
+		job.Status = models.DeletionJobCompleted
+		job.FinishedAt = &finishedAt
+	})
+}
+
+// updateJobs изменяет задачи пакета под блокировкой.
+//
+// Параметры:
+//   - batch: задачи пакета
+//   - fn: функция изменения задачи
+func (s *DeletionService) updateJobs(batch []deletionTask, fn func(job *models.DeletionJob, task deletionTask)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, task := range batch {
+		if job, ok := s.jobs[task.jobID]; ok {
+			fn(job, task)
+		}
+	}
+}
+
+// evictFinished удаляет задачи, завершенные раньше JobTTL.
+func (s *DeletionService) evictFinished() {
+	threshold := s.opts.Now().Add(-s.opts.JobTTL)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, job := range s.jobs {
+		if job.Finished() && job.FinishedAt.Before(threshold) {
+			delete(s.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/fsdevblog/shorturl/internal/repositories/memstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingDeleter оборачивает BatchDeleter и запоминает аргументы вызовов.
+type recordingDeleter struct {
+	BatchDeleter
+	mu    sync.Mutex
+	calls [][]repositories.BatchDeleteArg
+}
+
+func (d *recordingDeleter) BatchMarkAsDeleted(
+	ctx context.Context,
+	args []repositories.BatchDeleteArg,
+) ([]models.URL, error) {
+	d.mu.Lock()
+	d.calls = append(d.calls, args)
+	d.mu.Unlock()
+	return d.BatchDeleter.BatchMarkAsDeleted(ctx, args)
+}
+
+func (d *recordingDeleter) callCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.calls)
+}
+
+// newDeletionFixture создает сервис URL поверх in-memory хранилища со ссылками посетителей.
+func newDeletionFixture(t *testing.T, urls map[string][]string) *recordingDeleter {
+	t.Helper()
+	repo := memstore.NewURLRepo(db.NewMemStorage())
+	for visitorUUID, shortIDs := range urls {
+		for _, shortID := range shortIDs {
+			_, _, err := repo.Create(t.Context(), &models.URL{
+				ShortIdentifier: shortID,
+				URL:             "https://" + shortID + ".com",
+				VisitorUUID:     visitorUUID,
+			})
+			require.NoError(t, err)
+		}
+	}
+	return &recordingDeleter{BatchDeleter: NewURLService(repo)}
+}
+
+func TestDeletionService_FanIn(t *testing.T) {
+	deleter := newDeletionFixture(t, map[string][]string{
+		"visitor-1": {"aaa", "bbb"},
+		"visitor-2": {"ccc"},
+	})
+	svc := NewDeletionService(deleter, func(o *DeletionServiceOptions) {
+		o.FlushInterval = 20 * time.Millisecond
+	})
+
+	// Задачи ставятся до запуска обработчика, поэтому попадают в один пакет.
+	job1, err := svc.Enqueue(t.Context(), "visitor-1", []string{"aaa", "bbb", "aaa", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, models.DeletionJobPending, job1.Status)
+	assert.Equal(t, 3, job1.Requested)
+	// Чужие ссылки не удаляются.
+	job2, err := svc.Enqueue(t.Context(), "visitor-2", []string{"ccc", "aaa"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	go svc.Run(ctx)
+
+	require.Eventually(t, func() bool {
+		j, jobErr := svc.Job(t.Context(), "visitor-2", job2.ID)
+		return jobErr == nil && j.Finished()
+	}, time.Second, 5*time.Millisecond)
+
+	got1, err := svc.Job(t.Context(), "visitor-1", job1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeletionJobCompleted, got1.Status)
+	assert.Equal(t, 2, got1.Deleted)
+	assert.NotNil(t, got1.FinishedAt)
+
+	got2, err := svc.Job(t.Context(), "visitor-2", job2.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got2.Deleted)
+
+	assert.Equal(t, 1, deleter.callCount())
+
+	_, err = svc.Job(t.Context(), "visitor-2", job1.ID)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestDeletionService_DrainOnShutdown(t *testing.T) {
+	deleter := newDeletionFixture(t, map[string][]string{"visitor-1": {"aaa", "bbb", "ccc"}})
+	svc := NewDeletionService(deleter, func(o *DeletionServiceOptions) {
+		o.FlushInterval = time.Hour
+		o.BatchSize = 2
+	})
+
+	var jobs []*models.DeletionJob
+	for _, shortID := range []string{"aaa", "bbb", "ccc"} {
+		job, err := svc.Enqueue(t.Context(), "visitor-1", []string{shortID})
+		require.NoError(t, err)
+		jobs = append(jobs, job)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		svc.Run(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after drain")
+	}
+
+	for _, job := range jobs {
+		got, err := svc.Job(t.Context(), "visitor-1", job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.DeletionJobCompleted, got.Status)
+		assert.Equal(t, 1, got.Deleted)
+	}
+
+	_, err := svc.Enqueue(t.Context(), "visitor-1", []string{"aaa"})
+	require.ErrorIs(t, err, ErrDeletionQueueClosed)
+}
+
+func TestDeletionService_QueueFull(t *testing.T) {
+	svc := NewDeletionService(newDeletionFixture(t, nil), func(o *DeletionServiceOptions) {
+		o.QueueSize = 1
+	})
+
+	_, err := svc.Enqueue(t.Context(), "visitor-1", []string{"aaa"})
+	require.NoError(t, err)
+	_, err = svc.Enqueue(t.Context(), "visitor-1", []string{"bbb"})
+	require.ErrorIs(t, err, ErrDeletionQueueFull)
+	_, err = svc.Enqueue(t.Context(), "visitor-1", nil)
+	require.ErrorIs(t, err, ErrInvalidArgument)
+}
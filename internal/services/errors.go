@@ -5,8 +5,35 @@ import "errors"
 // ErrUnknown возвращается при неизвестной ошибке.
 // ErrRecordNotFound возвращается, когда запрашиваемая запись не существует.
 // ErrDuplicateKey возвращается при попытке создать дублирующуюся запись.
+// ErrInvalidArgument возвращается при некорректных входных данных.
+// ErrForbidden возвращается, когда роли участника рабочего пространства недостаточно для действия.
+// ErrAliasTaken возвращается, когда пользовательский короткий идентификатор занят другой ссылкой.
+// ErrIdempotencyKeyReused возвращается, когда ключ идемпотентности использован с другим запросом.
+// ErrIdempotencyInProgress возвращается, когда запрос с тем же ключом идемпотентности еще обрабатывается.
+// ErrDeletionQueueFull возвращается, когда очередь удаления переполнена.
+// ErrDeletionQueueClosed возвращается, когда очередь удаления остановлена (сервис завершает работу).
+// ErrInvalidAPIKey возвращается, когда ключ API неизвестен, отозван или не совпадает.
+// ErrEmailTaken возвращается при регистрации на адрес, который уже занят.
+// ErrInvalidCredentials возвращается, когда адрес или пароль не подошли.
+// ErrIdentityProviderUnavailable возвращается, когда провайдер OpenID Connect недоступен.
 var (
-	ErrUnknown        = errors.New("[service]: unknown error")
-	ErrRecordNotFound = errors.New("[service]: record not found")
-	ErrDuplicateKey   = errors.New("[service]: duplicate key")
+	ErrUnknown         = errors.New("[service]: unknown error")
+	ErrRecordNotFound  = errors.New("[service]: record not found")
+	ErrDuplicateKey    = errors.New("[service]: duplicate key")
+	ErrInvalidArgument = errors.New("[service]: invalid argument")
+	ErrForbidden       = errors.New("[service]: forbidden")
+	ErrAliasTaken      = errors.New("[service]: alias taken")
+
+	ErrIdempotencyKeyReused  = errors.New("[service]: idempotency key reused with different request")
+	ErrIdempotencyInProgress = errors.New("[service]: idempotent request in progress")
+
+	ErrDeletionQueueFull   = errors.New("[service]: deletion queue is full")
+	ErrDeletionQueueClosed = errors.New("[service]: deletion queue is closed")
+
+	ErrInvalidAPIKey = errors.New("[service]: invalid api key")
+
+	ErrEmailTaken         = errors.New("[service]: email taken")
+	ErrInvalidCredentials = errors.New("[service]: invalid credentials")
+
+	ErrIdentityProviderUnavailable = errors.New("[service]: identity provider unavailable")
 )
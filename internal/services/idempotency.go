@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+)
+
+// Параметры ключей идемпотентности по умолчанию.
+const (
+	DefaultIdempotencyTTL = 24 * time.Hour
+	// DefaultIdempotencyLockTTL время, на которое ключ резервируется на время обработки запроса.
+	// Если процесс завершился, не сохранив ответ и не освободив ключ, повтор возможен по его истечении.
+	DefaultIdempotencyLockTTL         = time.Minute
+	defaultIdempotencyCleanupInterval = 10 * time.Minute
+)
+
+// IdempotencyServiceOptions опции сервиса ключей идемпотентности.
+type IdempotencyServiceOptions struct {
+	TTL             time.Duration    // Время хранения ответа
+	LockTTL         time.Duration    // Время резервирования ключа на время обработки запроса
+	CleanupInterval time.Duration    // Период удаления истекших записей
+	OnError         func(err error)  // Обработчик ошибок фоновой очистки
+	Now             func() time.Time // Источник времени (для тестов)
+}
+
+// IdempotencyService хранит ответы на запросы с заголовком Idempotency-Key,
+// чтобы повтор запроса возвращал исходный ответ вместо повторного выполнения.
+type IdempotencyService struct {
+	repo IdempotencyRepository
+	opts IdempotencyServiceOptions
+}
+
+// NewIdempotencyService создает новый экземпляр сервиса ключей идемпотентности.
+//
+// Параметры:
+//   - repo: репозиторий ключей идемпотентности
+//   - opts: функции для настройки опций
+//
+// Возвращает:
+//   - *IdempotencyService: инициализированный сервис
+func NewIdempotencyService(repo IdempotencyRepository, opts ...func(*IdempotencyServiceOptions)) *IdempotencyService {
+	options := IdempotencyServiceOptions{
+		TTL:             DefaultIdempotencyTTL,
+		LockTTL:         DefaultIdempotencyLockTTL,
+		CleanupInterval: defaultIdempotencyCleanupInterval,
+		OnError:         func(error) {},
+		Now:             time.Now,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &IdempotencyService{repo: repo, opts: options}
+}
+
+// Begin начинает обработку запроса с ключом посетителя. Ключ резервируется на LockTTL:
+// если ответ не будет сохранен за это время, запрос можно будет повторить.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//   - key: ключ идемпотентности
+//   - fingerprint: отпечаток запроса
+//
+// Возвращает:
+//   - *models.IdempotencyRecord: сохраненный ответ, если запрос уже выполнен; nil, если запрос нужно выполнить
+//   - error: ErrIdempotencyKeyReused, ErrIdempotencyInProgress или ErrUnknown
+func (s *IdempotencyService) Begin(
+	ctx context.Context,
+	visitorUUID string,
+	key string,
+	fingerprint string,
+) (_ *models.IdempotencyRecord, err error) {
+	ctx, span := startSpan(ctx, "IdempotencyService.Begin")
+	defer func() { finishSpan(span, err) }()
+
+	record, reserved, err := s.repo.Reserve(ctx, &models.IdempotencyRecord{
+		Key:         key,
+		VisitorUUID: visitorUUID,
+		Fingerprint: fingerprint,
+		ExpiresAt:   s.opts.Now().Add(s.opts.LockTTL).UTC(),
+	})
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("%w: reserve idempotency key: %s", ErrUnknown, err.Error())
+	case reserved:
+		return nil, nil //nolint:nilnil
+	case record.Fingerprint != fingerprint:
+		return nil, ErrIdempotencyKeyReused
+	case record.InProgress():
+		return nil, ErrIdempotencyInProgress
+	}
+	return record, nil
+}
+
+// Complete сохраняет ответ на запрос, начатый Begin, на время TTL.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//   - key: ключ идемпотентности
+//   - status: HTTP статус ответа
+//   - contentType: тип содержимого ответа
+//   - body: тело ответа
+//
+// Возвращает:
+//   - error: ErrUnknown при ошибке сохранения
+func (s *IdempotencyService) Complete(
+	ctx context.Context,
+	visitorUUID string,
+	key string,
+	status int,
+	contentType string,
+	body []byte,
+) (err error) {
+	ctx, span := startSpan(ctx, "IdempotencyService.Complete")
+	defer func() { finishSpan(span, err) }()
+
+	err = s.repo.Complete(ctx, &models.IdempotencyRecord{
+		Key:         key,
+		VisitorUUID: visitorUUID,
+		StatusCode:  status,
+		ContentType: contentType,
+		Body:        body,
+		ExpiresAt:   s.opts.Now().Add(s.opts.TTL).UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: complete idempotency key: %s", ErrUnknown, err.Error())
+	}
+	return nil
+}
+
+// Release освобождает ключ запроса, начатого Begin, без сохранения ответа,
+// чтобы клиент мог повторить запрос (например, после внутренней ошибки).
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//   - key: ключ идемпотентности
+//
+// Возвращает:
+//   - error: ErrUnknown при ошибке удаления
+func (s *IdempotencyService) Release(ctx context.Context, visitorUUID string, key string) (err error) {
+	ctx, span := startSpan(ctx, "IdempotencyService.Release")
+	defer func() { finishSpan(span, err) }()
+
+	if err := s.repo.Delete(ctx, visitorUUID, key); err != nil {
+		return fmt.Errorf("%w: release idempotency key: %s", ErrUnknown, err.Error())
+	}
+	return nil
+}
+
+// Run периодически удаляет истекшие записи до отмены контекста.
+//
+// Параметры:
+//   - ctx: контекст работы сервиса
+func (s *IdempotencyService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.repo.DeleteExpired(ctx, s.opts.Now()); err != nil {
+				s.opts.OnError(fmt.Errorf("delete expired idempotency keys: %w", err))
+			}
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// noopURLMetrics пустой сборщик метрик, используемый по умолчанию.
+type noopURLMetrics struct{}
+
+func (noopURLMetrics) RedirectHit()         {}
+func (noopURLMetrics) RedirectMiss()        {}
+func (noopURLMetrics) CreateConflict()      {}
+func (noopURLMetrics) ObserveBatchSize(int) {}
+
+// noopRepoObserver пустой сборщик метрик репозитория, используемый по умолчанию.
+type noopRepoObserver struct{}
+
+func (noopRepoObserver) ObserveRepoOp(string, string, time.Duration, error) {}
+
+// instrumentedURLRepo обертка над URLRepository, открывающая спан на каждую операцию
+// и замеряющая время её выполнения.
+type instrumentedURLRepo struct {
+	repo     URLRepository
+	backend  string
+	observer RepoObserver
+}
+
+// newInstrumentedURLRepo оборачивает репозиторий для трассировки и сбора метрик.
+//
+// Параметры:
+//   - repo: исходный репозиторий
+//   - backend: тип хранилища, попадающий в атрибуты спана и метку метрики
+//   - observer: сборщик метрик (может быть nil)
+//
+// Возвращает:
+//   - URLRepository: репозиторий с трассировкой и замером операций
+func newInstrumentedURLRepo(repo URLRepository, backend ServiceType, observer RepoObserver) URLRepository {
+	if observer == nil {
+		observer = noopRepoObserver{}
+	}
+	return &instrumentedURLRepo{repo: repo, backend: string(backend), observer: observer}
+}
+
+// start открывает спан операции op. Возвращенную функцию нужно вызвать с результатом операции.
+// Отсутствие записи является штатным результатом и не считается ошибкой.
+func (r *instrumentedURLRepo) start(ctx context.Context, op string) (context.Context, func(err error)) {
+	start := time.Now()
+	ctx, span := startSpan(ctx, "URLRepository."+op, attribute.String("repository.backend", r.backend))
+	return ctx, func(err error) {
+		if errors.Is(err, repositories.ErrNotFound) {
+			err = nil
+		}
+		finishSpan(span, err)
+		r.observer.ObserveRepoOp(r.backend, op, time.Since(start), err)
+	}
+}
+
+func (r *instrumentedURLRepo) BatchCreate(
+	ctx context.Context,
+	mURLs []repositories.BatchCreateArg,
+) (*repositories.BatchCreateShortURLsResult, error) {
+	ctx, done := r.start(ctx, "BatchCreate")
+	res, err := r.repo.BatchCreate(ctx, mURLs)
+	done(err)
+	return res, err //nolint:wrapcheck
+}
+
+func (r *instrumentedURLRepo) Create(ctx context.Context, mURL *models.URL) (*models.URL, bool, error) {
+	ctx, done := r.start(ctx, "Create")
+	m, isUniq, err := r.repo.Create(ctx, mURL)
+	done(err)
+	return m, isUniq, err //nolint:wrapcheck
+}
+
+func (r *instrumentedURLRepo) GetByShortIdentifier(ctx context.Context, shortID string) (*models.URL, error) {
+	ctx, done := r.start(ctx, "GetByShortIdentifier")
+	m, err := r.repo.GetByShortIdentifier(ctx, shortID)
+	done(err)
+	return m, err //nolint:wrapcheck
+}
+
+func (r *instrumentedURLRepo) GetByShortIdentifiers(ctx context.Context, shortIDs []string) ([]models.URL, error) {
+	ctx, done := r.start(ctx, "GetByShortIdentifiers")
+	urls, err := r.repo.GetByShortIdentifiers(ctx, shortIDs)
+	done(err)
+	return urls, err //nolint:wrapcheck
+}
+
+func (r *instrumentedURLRepo) GetByURL(ctx context.Context, rawURL string) (*models.URL, error) {
+	ctx, done := r.start(ctx, "GetByURL")
+	m, err := r.repo.GetByURL(ctx, rawURL)
+	done(err)
+	return m, err //nolint:wrapcheck
+}
+
+func (r *instrumentedURLRepo) GetByURLVisitorUUID(
+	ctx context.Context,
+	rawURL string,
+	visitorUUID string,
+) (*models.URL, error) {
+	ctx, done := r.start(ctx, "GetByURLVisitorUUID")
+	m, err := r.repo.GetByURLVisitorUUID(ctx, rawURL, visitorUUID)
+	done(err)
+	return m, err //nolint:wrapcheck
+}
+
+func (r *instrumentedURLRepo) GetAll(ctx context.Context) ([]models.URL, error) {
+	ctx, done := r.start(ctx, "GetAll")
+	urls, err := r.repo.GetAll(ctx)
+	done(err)
+	return urls, err //nolint:wrapcheck
+}
+
+func (r *instrumentedURLRepo) GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.URL, error) {
+	ctx, done := r.start(ctx, "GetAllByVisitorUUID")
+	urls, err := r.repo.GetAllByVisitorUUID(ctx, visitorUUID)
+	done(err)
+	return urls, err //nolint:wrapcheck
+}
+
+func (r *instrumentedURLRepo) EachByVisitorUUID(
+	ctx context.Context,
+	visitorUUID string,
+	fn func(models.URL) error,
+) error {
+	ctx, done := r.start(ctx, "EachByVisitorUUID")
+	err := r.repo.EachByVisitorUUID(ctx, visitorUUID, fn)
+	done(err)
+	return err //nolint:wrapcheck
+}
+
+func (r *instrumentedURLRepo) DeleteByShortIDsVisitorUUID(
+	ctx context.Context,
+	visitorUUID string,
+	shortIDs []string,
+) ([]string, error) {
+	ctx, done := r.start(ctx, "DeleteByShortIDsVisitorUUID")
+	deleted, err := r.repo.DeleteByShortIDsVisitorUUID(ctx, visitorUUID, shortIDs)
+	done(err)
+	return deleted, err //nolint:wrapcheck
+}
+
+func (r *instrumentedURLRepo) BatchDelete(
+	ctx context.Context,
+	args []repositories.BatchDeleteArg,
+) ([]models.URL, error) {
+	ctx, done := r.start(ctx, "BatchDelete")
+	deleted, err := r.repo.BatchDelete(ctx, args)
+	done(err)
+	return deleted, err //nolint:wrapcheck
+}
+
+func (r *instrumentedURLRepo) Stats(ctx context.Context) (*models.Stats, error) {
+	ctx, done := r.start(ctx, "Stats")
+	stats, err := r.repo.Stats(ctx)
+	done(err)
+	return stats, err //nolint:wrapcheck
+}
+
+func (r *instrumentedURLRepo) ReassignVisitor(ctx context.Context, fromUUID string, toUUID string) (int, error) {
+	ctx, done := r.start(ctx, "ReassignVisitor")
+	n, err := r.repo.ReassignVisitor(ctx, fromUUID, toUUID)
+	done(err)
+	return n, err //nolint:wrapcheck
+}
+
+func (r *instrumentedURLRepo) Search(
+	ctx context.Context,
+	filter repositories.URLSearchFilter,
+) ([]models.URL, error) {
+	ctx, done := r.start(ctx, "Search")
+	urls, err := r.repo.Search(ctx, filter)
+	done(err)
+	return urls, err //nolint:wrapcheck
+}
+
+func (r *instrumentedURLRepo) Disable(
+	ctx context.Context,
+	shortID string,
+	reason string,
+	legal bool,
+) (*models.URL, error) {
+	ctx, done := r.start(ctx, "Disable")
+	m, err := r.repo.Disable(ctx, shortID, reason, legal)
+	done(err)
+	return m, err //nolint:wrapcheck
+}
+
+func (r *instrumentedURLRepo) Enable(ctx context.Context, shortID string) (*models.URL, error) {
+	ctx, done := r.start(ctx, "Enable")
+	m, err := r.repo.Enable(ctx, shortID)
+	done(err)
+	return m, err //nolint:wrapcheck
+}
+
+func (r *instrumentedURLRepo) DisableByVisitorUUID(
+	ctx context.Context,
+	visitorUUID string,
+	reason string,
+) (int, error) {
+	ctx, done := r.start(ctx, "DisableByVisitorUUID")
+	n, err := r.repo.DisableByVisitorUUID(ctx, visitorUUID, reason)
+	done(err)
+	return n, err //nolint:wrapcheck
+}
+
+func (r *instrumentedURLRepo) GetAllByWorkspaceID(ctx context.Context, workspaceID string) ([]models.URL, error) {
+	ctx, done := r.start(ctx, "GetAllByWorkspaceID")
+	urls, err := r.repo.GetAllByWorkspaceID(ctx, workspaceID)
+	done(err)
+	return urls, err //nolint:wrapcheck
+}
+
+func (r *instrumentedURLRepo) UpdateInWorkspace(
+	ctx context.Context,
+	workspaceID string,
+	shortID string,
+	upd repositories.URLUpdate,
+) (*models.URL, error) {
+	ctx, done := r.start(ctx, "UpdateInWorkspace")
+	m, err := r.repo.UpdateInWorkspace(ctx, workspaceID, shortID, upd)
+	done(err)
+	return m, err //nolint:wrapcheck
+}
+
+func (r *instrumentedURLRepo) DeleteInWorkspace(
+	ctx context.Context,
+	workspaceID string,
+	shortID string,
+) (*models.URL, error) {
+	ctx, done := r.start(ctx, "DeleteInWorkspace")
+	m, err := r.repo.DeleteInWorkspace(ctx, workspaceID, shortID)
+	done(err)
+	return m, err //nolint:wrapcheck
+}
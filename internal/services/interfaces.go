@@ -2,8 +2,11 @@ package services
 
 import (
 	"context"
+	"time"
 
+	"github.com/fsdevblog/shorturl/internal/events"
 	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/oidc"
 	"github.com/fsdevblog/shorturl/internal/repositories"
 )
 
@@ -18,15 +21,239 @@ type URLRepository interface {
 
 	// Create вычисляет хеш короткой ссылки и создает запись в хранилище.
 	// Возвращает два значения: bool отвечает за уникальность созданной записи, 2 ошибку.
+	// Ссылка с WorkspaceID уникальна по URL в пределах рабочего пространства, а не посетителя.
 	Create(ctx context.Context, mURL *models.URL) (*models.URL, bool, error)
 	// GetByShortIdentifier находит в хранилище запись по заданному хешу ссылки
 	GetByShortIdentifier(ctx context.Context, shortID string) (*models.URL, error)
+	// GetByShortIdentifiers находит записи по нескольким хешам ссылок, пропуская отсутствующие
+	GetByShortIdentifiers(ctx context.Context, shortIDs []string) ([]models.URL, error)
 	// GetByURL находит запись в хранилище по заданной ссылке
 	GetByURL(ctx context.Context, rawURL string) (*models.URL, error)
 	// GetAll возвращает все записи в бд. Сразу пачкой.
 	GetAll(ctx context.Context) ([]models.URL, error)
-	// GetAllByVisitorUUID возвращает записи связанные с visitorUUID.
+	// GetByURLVisitorUUID находит запись посетителя по заданной ссылке
+	GetByURLVisitorUUID(ctx context.Context, rawURL string, visitorUUID string) (*models.URL, error)
+	// GetAllByVisitorUUID возвращает личные записи, связанные с visitorUUID (без ссылок рабочих пространств).
 	GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.URL, error)
-	// DeleteByShortIDsVisitorUUID помечает записи как удаленные.
-	DeleteByShortIDsVisitorUUID(ctx context.Context, visitorUUID string, shortIDs []string) error
+	// EachByVisitorUUID обходит записи связанные с visitorUUID, не загружая их в память целиком.
+	EachByVisitorUUID(ctx context.Context, visitorUUID string, fn func(models.URL) error) error
+	// DeleteByShortIDsVisitorUUID помечает записи как удаленные. Возвращает идентификаторы удаленных записей.
+	DeleteByShortIDsVisitorUUID(ctx context.Context, visitorUUID string, shortIDs []string) ([]string, error)
+	// BatchDelete помечает удаленными URL нескольких посетителей. Возвращает помеченные этим вызовом записи.
+	BatchDelete(ctx context.Context, args []repositories.BatchDeleteArg) ([]models.URL, error)
+	// Stats возвращает количество неудаленных URL и уникальных посетителей.
+	Stats(ctx context.Context) (*models.Stats, error)
+	// ReassignVisitor передает записи посетителя fromUUID посетителю toUUID, пропуская URL, которые у него уже есть.
+	ReassignVisitor(ctx context.Context, fromUUID string, toUUID string) (int, error)
+	// Search ищет записи всех посетителей, включая удаленные и отключенные, от новых к старым.
+	Search(ctx context.Context, filter repositories.URLSearchFilter) ([]models.URL, error)
+	// Disable отключает ссылку с указанной причиной. Возвращает repositories.ErrNotFound, если ссылки нет.
+	Disable(ctx context.Context, shortID string, reason string, legal bool) (*models.URL, error)
+	// Enable снимает отключение ссылки. Возвращает repositories.ErrNotFound, если ссылки нет.
+	Enable(ctx context.Context, shortID string) (*models.URL, error)
+	// DisableByVisitorUUID отключает все действующие ссылки посетителя. Возвращает количество отключенных ссылок.
+	DisableByVisitorUUID(ctx context.Context, visitorUUID string, reason string) (int, error)
+	// GetAllByWorkspaceID возвращает записи рабочего пространства, включая удаленные.
+	GetAllByWorkspaceID(ctx context.Context, workspaceID string) ([]models.URL, error)
+	// UpdateInWorkspace изменяет неудаленную ссылку пространства. Возвращает repositories.ErrNotFound,
+	// если ссылки в пространстве нет, и repositories.ErrDuplicateKey, если новый URL в нем уже сокращен.
+	UpdateInWorkspace(
+		ctx context.Context,
+		workspaceID string,
+		shortID string,
+		upd repositories.URLUpdate,
+	) (*models.URL, error)
+	// DeleteInWorkspace помечает удаленной ссылку пространства. Возвращает repositories.ErrNotFound,
+	// если неудаленной ссылки в пространстве нет.
+	DeleteInWorkspace(ctx context.Context, workspaceID string, shortID string) (*models.URL, error)
+}
+
+// URLMetrics описывает сборщик прикладных метрик сервиса URL.
+type URLMetrics interface {
+	// RedirectHit учитывает переход по существующей ссылке.
+	RedirectHit()
+	// RedirectMiss учитывает переход по несуществующей, удаленной или отключенной ссылке.
+	RedirectMiss()
+	// CreateConflict учитывает попытку повторно сократить URL.
+	CreateConflict()
+	// ObserveBatchSize учитывает размер пакета при пакетном создании.
+	ObserveBatchSize(n int)
+}
+
+// RepoObserver описывает сборщик метрик операций репозитория.
+type RepoObserver interface {
+	// ObserveRepoOp учитывает время выполнения операции op в хранилище backend.
+	ObserveRepoOp(backend, op string, d time.Duration, err error)
+}
+
+// BatchDeleter описывает пакетное удаление ссылок нескольких посетителей.
+type BatchDeleter interface {
+	// BatchMarkAsDeleted помечает URL удаленными и возвращает фактически удаленные записи.
+	BatchMarkAsDeleted(ctx context.Context, args []repositories.BatchDeleteArg) ([]models.URL, error)
+}
+
+// EventPublisher описывает получателя событий о ссылках.
+type EventPublisher interface {
+	// Publish отправляет событие. Реализация не должна блокировать вызывающего.
+	Publish(e events.Event)
+}
+
+// EventSource описывает источник событий о ссылках всех посетителей.
+type EventSource interface {
+	// SubscribeAllBuffered подписывает на все события с буфером size. События сверх буфера
+	// отбрасываются и учитываются в Subscription.Dropped. Подписку необходимо закрыть.
+	SubscribeAllBuffered(size int) *events.Subscription
+}
+
+// WebhookRepository описывает репозиторий вебхуков.
+type WebhookRepository interface {
+	// Create создает вебхук.
+	Create(ctx context.Context, w *models.Webhook) (*models.Webhook, error)
+	// GetAllByVisitorUUID возвращает вебхуки посетителя.
+	GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.Webhook, error)
+	// GetByIDVisitorUUID возвращает вебхук посетителя по идентификатору.
+	GetByIDVisitorUUID(ctx context.Context, id string, visitorUUID string) (*models.Webhook, error)
+	// GetVisitorUUIDs возвращает идентификаторы посетителей, у которых есть вебхуки.
+	GetVisitorUUIDs(ctx context.Context) ([]string, error)
+	// Delete удаляет вебхук посетителя вместе с историей и ожидающими доставками.
+	Delete(ctx context.Context, id string, visitorUUID string) error
+	// CreateDelivery сохраняет попытку доставки.
+	CreateDelivery(ctx context.Context, d *models.WebhookDelivery) error
+	// GetDeliveriesByWebhookID возвращает последние попытки доставки, от новых к старым.
+	GetDeliveriesByWebhookID(ctx context.Context, webhookID string, limit int) ([]models.WebhookDelivery, error)
+	// DeleteDeliveriesBefore удаляет попытки доставки, созданные раньше before.
+	DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error)
+	// CreateJobs сохраняет ожидающие доставки.
+	CreateJobs(ctx context.Context, jobs []models.WebhookJob) error
+	// ClaimDueJobs захватывает до limit ожидающих доставок, время попытки которых наступило к now,
+	// и откладывает их следующую попытку на lease.
+	ClaimDueJobs(
+		ctx context.Context,
+		now time.Time,
+		lease time.Duration,
+		limit int,
+	) ([]repositories.WebhookJobClaim, error)
+	// RescheduleJob сохраняет количество выполненных попыток и время следующей попытки.
+	// Возвращает repositories.ErrNotFound, если доставки уже нет.
+	RescheduleJob(ctx context.Context, id string, attempt int, nextAttemptAt time.Time) error
+	// DeleteJob удаляет ожидающую доставку. Отсутствие записи ошибкой не считается.
+	DeleteJob(ctx context.Context, id string) error
+}
+
+// WebhookMetrics описывает сборщик метрик доставки вебхуков.
+type WebhookMetrics interface {
+	// WebhookEventsDropped учитывает события, отброшенные из-за переполнения очереди вебхуков.
+	WebhookEventsDropped(n uint64)
+}
+
+// APIKeyRepository описывает репозиторий ключей API.
+type APIKeyRepository interface {
+	// Create сохраняет новый ключ.
+	Create(ctx context.Context, k *models.APIKey) (*models.APIKey, error)
+	// GetAllByVisitorUUID возвращает ключи посетителя, включая отозванные, в порядке создания.
+	GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.APIKey, error)
+	// GetByPrefix возвращает ключ по его открытому префиксу.
+	GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	// Revoke отзывает действующий ключ посетителя. Возвращает repositories.ErrNotFound, если такого ключа нет.
+	Revoke(ctx context.Context, id string, visitorUUID string, at time.Time) error
+}
+
+// IdempotencyRepository описывает репозиторий ключей идемпотентности.
+type IdempotencyRepository interface {
+	// Reserve сохраняет запись об обрабатываемом запросе, если для ключа посетителя нет неистекшей записи.
+	// Иначе возвращает существующую запись и false.
+	Reserve(ctx context.Context, r *models.IdempotencyRecord) (*models.IdempotencyRecord, bool, error)
+	// Complete сохраняет ответ на запрос с ключом посетителя и продлевает запись до r.ExpiresAt.
+	Complete(ctx context.Context, r *models.IdempotencyRecord) error
+	// Delete удаляет запись ключа посетителя.
+	Delete(ctx context.Context, visitorUUID, key string) error
+	// DeleteExpired удаляет записи, срок действия которых истек к моменту before.
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// TokenRevocationRepository описывает репозиторий отозванных токенов посетителей.
+type TokenRevocationRepository interface {
+	// RevokeToken сохраняет отзыв токена. Повторный отзыв того же токена не является ошибкой.
+	RevokeToken(ctx context.Context, t *models.RevokedToken) error
+	// IsTokenRevoked проверяет, отозван ли токен с идентификатором jti.
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	// RevokeSessions сохраняет отзыв всех сессий посетителя. Из нескольких отзывов действует самый поздний.
+	RevokeSessions(ctx context.Context, r *models.SessionRevocation) error
+	// GetSessionRevocation возвращает отзыв сессий посетителя или repositories.ErrNotFound.
+	GetSessionRevocation(ctx context.Context, visitorUUID string) (*models.SessionRevocation, error)
+	// DeleteExpired удаляет отзывы, срок действия которых истек к моменту before.
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// UserRepository описывает репозиторий зарегистрированных пользователей.
+type UserRepository interface {
+	// Create сохраняет пользователя. Возвращает repositories.ErrDuplicateKey, если адрес занят.
+	Create(ctx context.Context, u *models.User) (*models.User, error)
+	// GetByEmail находит пользователя по адресу электронной почты.
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	// GetByID находит пользователя по идентификатору.
+	GetByID(ctx context.Context, id string) (*models.User, error)
+}
+
+// OIDCIdentityRepository описывает репозиторий учетных записей провайдера OpenID Connect.
+type OIDCIdentityRepository interface {
+	// Create связывает пользователя провайдера с UUID посетителя.
+	// Возвращает repositories.ErrDuplicateKey, если пользователь уже связан.
+	Create(ctx context.Context, i *models.OIDCIdentity) (*models.OIDCIdentity, error)
+	// Get находит учетную запись по провайдеру и идентификатору пользователя у него.
+	Get(ctx context.Context, issuer, subject string) (*models.OIDCIdentity, error)
+	// GetByUserID находит учетную запись, связанную с UUID посетителя.
+	GetByUserID(ctx context.Context, userID string) (*models.OIDCIdentity, error)
+}
+
+// VisitorBanRepository описывает репозиторий блокировок посетителей.
+type VisitorBanRepository interface {
+	// Create блокирует посетителя. Возвращает repositories.ErrDuplicateKey, если посетитель уже заблокирован.
+	Create(ctx context.Context, b *models.VisitorBan) (*models.VisitorBan, error)
+	// Get находит блокировку посетителя.
+	Get(ctx context.Context, visitorUUID string) (*models.VisitorBan, error)
+	// Delete снимает блокировку. Возвращает repositories.ErrNotFound, если посетитель не заблокирован.
+	Delete(ctx context.Context, visitorUUID string) error
+}
+
+// AuditRepository описывает репозиторий журнала аудита.
+type AuditRepository interface {
+	// Create добавляет запись в журнал.
+	Create(ctx context.Context, e *models.AuditEntry) (*models.AuditEntry, error)
+	// List возвращает записи журнала от новых к старым.
+	List(ctx context.Context, filter repositories.AuditFilter) ([]models.AuditEntry, error)
+}
+
+// WorkspaceRepository описывает репозиторий рабочих пространств и их участников.
+type WorkspaceRepository interface {
+	// Create сохраняет пространство и делает его создателя (CreatedBy) владельцем.
+	Create(ctx context.Context, w *models.Workspace) (*models.Workspace, error)
+	// Get находит пространство по идентификатору.
+	Get(ctx context.Context, id string) (*models.Workspace, error)
+	// ListByMember возвращает пространства, в которых состоит посетитель, вместе с его ролью.
+	ListByMember(ctx context.Context, visitorUUID string) ([]models.WorkspaceMembership, error)
+	// GetMember находит участника. Возвращает repositories.ErrNotFound, если посетитель не участник.
+	GetMember(ctx context.Context, workspaceID string, visitorUUID string) (*models.WorkspaceMember, error)
+	// ListMembers возвращает участников пространства.
+	ListMembers(ctx context.Context, workspaceID string) ([]models.WorkspaceMember, error)
+	// SetMember добавляет участника или меняет роль существующего. Понижение последнего владельца
+	// атомарно отклоняется с repositories.ErrLastOwner.
+	SetMember(ctx context.Context, member *models.WorkspaceMember) (*models.WorkspaceMember, error)
+	// DeleteMember удаляет участника. Возвращает repositories.ErrNotFound, если посетитель не участник,
+	// и repositories.ErrLastOwner при удалении последнего владельца (проверка атомарна с удалением).
+	DeleteMember(ctx context.Context, workspaceID string, visitorUUID string) error
+}
+
+// OIDCClient описывает клиента провайдера OpenID Connect для потока authorization code с PKCE.
+type OIDCClient interface {
+	// AuthCodeURL формирует адрес страницы входа провайдера.
+	AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error)
+	// Exchange обменивает код авторизации на проверенный ID токен.
+	Exchange(ctx context.Context, code, verifier, nonce string) (*oidc.IDTokenClaims, error)
+}
+
+// VisitorMerger описывает передачу ссылок анонимного посетителя аккаунту.
+type VisitorMerger interface {
+	// MergeVisitor передает ссылки посетителя fromUUID посетителю toUUID. Возвращает количество переданных ссылок.
+	MergeVisitor(ctx context.Context, fromUUID string, toUUID string) (int, error)
 }
@@ -7,8 +7,11 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	events "github.com/fsdevblog/shorturl/internal/events"
 	models "github.com/fsdevblog/shorturl/internal/models"
+	oidc "github.com/fsdevblog/shorturl/internal/oidc"
 	repositories "github.com/fsdevblog/shorturl/internal/repositories"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -51,6 +54,21 @@ func (mr *MockURLRepositoryMockRecorder) BatchCreate(ctx, mURLs interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchCreate", reflect.TypeOf((*MockURLRepository)(nil).BatchCreate), ctx, mURLs)
 }
 
+// BatchDelete mocks base method.
+func (m *MockURLRepository) BatchDelete(ctx context.Context, args []repositories.BatchDeleteArg) ([]models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchDelete", ctx, args)
+	ret0, _ := ret[0].([]models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchDelete indicates an expected call of BatchDelete.
+func (mr *MockURLRepositoryMockRecorder) BatchDelete(ctx, args interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchDelete", reflect.TypeOf((*MockURLRepository)(nil).BatchDelete), ctx, args)
+}
+
 // Create mocks base method.
 func (m *MockURLRepository) Create(ctx context.Context, mURL *models.URL) (*models.URL, bool, error) {
 	m.ctrl.T.Helper()
@@ -68,11 +86,12 @@ func (mr *MockURLRepositoryMockRecorder) Create(ctx, mURL interface{}) *gomock.C
 }
 
 // DeleteByShortIDsVisitorUUID mocks base method.
-func (m *MockURLRepository) DeleteByShortIDsVisitorUUID(ctx context.Context, visitorUUID string, shortIDs []string) error {
+func (m *MockURLRepository) DeleteByShortIDsVisitorUUID(ctx context.Context, visitorUUID string, shortIDs []string) ([]string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeleteByShortIDsVisitorUUID", ctx, visitorUUID, shortIDs)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // DeleteByShortIDsVisitorUUID indicates an expected call of DeleteByShortIDsVisitorUUID.
@@ -81,6 +100,80 @@ func (mr *MockURLRepositoryMockRecorder) DeleteByShortIDsVisitorUUID(ctx, visito
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByShortIDsVisitorUUID", reflect.TypeOf((*MockURLRepository)(nil).DeleteByShortIDsVisitorUUID), ctx, visitorUUID, shortIDs)
 }
 
+// DeleteInWorkspace mocks base method.
+func (m *MockURLRepository) DeleteInWorkspace(ctx context.Context, workspaceID, shortID string) (*models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteInWorkspace", ctx, workspaceID, shortID)
+	ret0, _ := ret[0].(*models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteInWorkspace indicates an expected call of DeleteInWorkspace.
+func (mr *MockURLRepositoryMockRecorder) DeleteInWorkspace(ctx, workspaceID, shortID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteInWorkspace", reflect.TypeOf((*MockURLRepository)(nil).DeleteInWorkspace), ctx, workspaceID, shortID)
+}
+
+// Disable mocks base method.
+func (m *MockURLRepository) Disable(ctx context.Context, shortID, reason string, legal bool) (*models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disable", ctx, shortID, reason, legal)
+	ret0, _ := ret[0].(*models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Disable indicates an expected call of Disable.
+func (mr *MockURLRepositoryMockRecorder) Disable(ctx, shortID, reason, legal interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockURLRepository)(nil).Disable), ctx, shortID, reason, legal)
+}
+
+// DisableByVisitorUUID mocks base method.
+func (m *MockURLRepository) DisableByVisitorUUID(ctx context.Context, visitorUUID, reason string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableByVisitorUUID", ctx, visitorUUID, reason)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableByVisitorUUID indicates an expected call of DisableByVisitorUUID.
+func (mr *MockURLRepositoryMockRecorder) DisableByVisitorUUID(ctx, visitorUUID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableByVisitorUUID", reflect.TypeOf((*MockURLRepository)(nil).DisableByVisitorUUID), ctx, visitorUUID, reason)
+}
+
+// EachByVisitorUUID mocks base method.
+func (m *MockURLRepository) EachByVisitorUUID(ctx context.Context, visitorUUID string, fn func(models.URL) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EachByVisitorUUID", ctx, visitorUUID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EachByVisitorUUID indicates an expected call of EachByVisitorUUID.
+func (mr *MockURLRepositoryMockRecorder) EachByVisitorUUID(ctx, visitorUUID, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EachByVisitorUUID", reflect.TypeOf((*MockURLRepository)(nil).EachByVisitorUUID), ctx, visitorUUID, fn)
+}
+
+// Enable mocks base method.
+func (m *MockURLRepository) Enable(ctx context.Context, shortID string) (*models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enable", ctx, shortID)
+	ret0, _ := ret[0].(*models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enable indicates an expected call of Enable.
+func (mr *MockURLRepositoryMockRecorder) Enable(ctx, shortID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockURLRepository)(nil).Enable), ctx, shortID)
+}
+
 // GetAll mocks base method.
 func (m *MockURLRepository) GetAll(ctx context.Context) ([]models.URL, error) {
 	m.ctrl.T.Helper()
@@ -111,6 +204,21 @@ func (mr *MockURLRepositoryMockRecorder) GetAllByVisitorUUID(ctx, visitorUUID in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByVisitorUUID", reflect.TypeOf((*MockURLRepository)(nil).GetAllByVisitorUUID), ctx, visitorUUID)
 }
 
+// GetAllByWorkspaceID mocks base method.
+func (m *MockURLRepository) GetAllByWorkspaceID(ctx context.Context, workspaceID string) ([]models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByWorkspaceID", ctx, workspaceID)
+	ret0, _ := ret[0].([]models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByWorkspaceID indicates an expected call of GetAllByWorkspaceID.
+func (mr *MockURLRepositoryMockRecorder) GetAllByWorkspaceID(ctx, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByWorkspaceID", reflect.TypeOf((*MockURLRepository)(nil).GetAllByWorkspaceID), ctx, workspaceID)
+}
+
 // GetByShortIdentifier mocks base method.
 func (m *MockURLRepository) GetByShortIdentifier(ctx context.Context, shortID string) (*models.URL, error) {
 	m.ctrl.T.Helper()
@@ -126,6 +234,21 @@ func (mr *MockURLRepositoryMockRecorder) GetByShortIdentifier(ctx, shortID inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByShortIdentifier", reflect.TypeOf((*MockURLRepository)(nil).GetByShortIdentifier), ctx, shortID)
 }
 
+// GetByShortIdentifiers mocks base method.
+func (m *MockURLRepository) GetByShortIdentifiers(ctx context.Context, shortIDs []string) ([]models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByShortIdentifiers", ctx, shortIDs)
+	ret0, _ := ret[0].([]models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByShortIdentifiers indicates an expected call of GetByShortIdentifiers.
+func (mr *MockURLRepositoryMockRecorder) GetByShortIdentifiers(ctx, shortIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByShortIdentifiers", reflect.TypeOf((*MockURLRepository)(nil).GetByShortIdentifiers), ctx, shortIDs)
+}
+
 // GetByURL mocks base method.
 func (m *MockURLRepository) GetByURL(ctx context.Context, rawURL string) (*models.URL, error) {
 	m.ctrl.T.Helper()
@@ -140,3 +263,1261 @@ func (mr *MockURLRepositoryMockRecorder) GetByURL(ctx, rawURL interface{}) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByURL", reflect.TypeOf((*MockURLRepository)(nil).GetByURL), ctx, rawURL)
 }
+
+// GetByURLVisitorUUID mocks base method.
+func (m *MockURLRepository) GetByURLVisitorUUID(ctx context.Context, rawURL, visitorUUID string) (*models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByURLVisitorUUID", ctx, rawURL, visitorUUID)
+	ret0, _ := ret[0].(*models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByURLVisitorUUID indicates an expected call of GetByURLVisitorUUID.
+func (mr *MockURLRepositoryMockRecorder) GetByURLVisitorUUID(ctx, rawURL, visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByURLVisitorUUID", reflect.TypeOf((*MockURLRepository)(nil).GetByURLVisitorUUID), ctx, rawURL, visitorUUID)
+}
+
+// ReassignVisitor mocks base method.
+func (m *MockURLRepository) ReassignVisitor(ctx context.Context, fromUUID, toUUID string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReassignVisitor", ctx, fromUUID, toUUID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReassignVisitor indicates an expected call of ReassignVisitor.
+func (mr *MockURLRepositoryMockRecorder) ReassignVisitor(ctx, fromUUID, toUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReassignVisitor", reflect.TypeOf((*MockURLRepository)(nil).ReassignVisitor), ctx, fromUUID, toUUID)
+}
+
+// Search mocks base method.
+func (m *MockURLRepository) Search(ctx context.Context, filter repositories.URLSearchFilter) ([]models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, filter)
+	ret0, _ := ret[0].([]models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockURLRepositoryMockRecorder) Search(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockURLRepository)(nil).Search), ctx, filter)
+}
+
+// Stats mocks base method.
+func (m *MockURLRepository) Stats(ctx context.Context) (*models.Stats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats", ctx)
+	ret0, _ := ret[0].(*models.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockURLRepositoryMockRecorder) Stats(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockURLRepository)(nil).Stats), ctx)
+}
+
+// UpdateInWorkspace mocks base method.
+func (m *MockURLRepository) UpdateInWorkspace(ctx context.Context, workspaceID, shortID string, upd repositories.URLUpdate) (*models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateInWorkspace", ctx, workspaceID, shortID, upd)
+	ret0, _ := ret[0].(*models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateInWorkspace indicates an expected call of UpdateInWorkspace.
+func (mr *MockURLRepositoryMockRecorder) UpdateInWorkspace(ctx, workspaceID, shortID, upd interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInWorkspace", reflect.TypeOf((*MockURLRepository)(nil).UpdateInWorkspace), ctx, workspaceID, shortID, upd)
+}
+
+// MockURLMetrics is a mock of URLMetrics interface.
+type MockURLMetrics struct {
+	ctrl     *gomock.Controller
+	recorder *MockURLMetricsMockRecorder
+}
+
+// MockURLMetricsMockRecorder is the mock recorder for MockURLMetrics.
+type MockURLMetricsMockRecorder struct {
+	mock *MockURLMetrics
+}
+
+// NewMockURLMetrics creates a new mock instance.
+func NewMockURLMetrics(ctrl *gomock.Controller) *MockURLMetrics {
+	mock := &MockURLMetrics{ctrl: ctrl}
+	mock.recorder = &MockURLMetricsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockURLMetrics) EXPECT() *MockURLMetricsMockRecorder {
+	return m.recorder
+}
+
+// CreateConflict mocks base method.
+func (m *MockURLMetrics) CreateConflict() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CreateConflict")
+}
+
+// CreateConflict indicates an expected call of CreateConflict.
+func (mr *MockURLMetricsMockRecorder) CreateConflict() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateConflict", reflect.TypeOf((*MockURLMetrics)(nil).CreateConflict))
+}
+
+// ObserveBatchSize mocks base method.
+func (m *MockURLMetrics) ObserveBatchSize(n int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ObserveBatchSize", n)
+}
+
+// ObserveBatchSize indicates an expected call of ObserveBatchSize.
+func (mr *MockURLMetricsMockRecorder) ObserveBatchSize(n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObserveBatchSize", reflect.TypeOf((*MockURLMetrics)(nil).ObserveBatchSize), n)
+}
+
+// RedirectHit mocks base method.
+func (m *MockURLMetrics) RedirectHit() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RedirectHit")
+}
+
+// RedirectHit indicates an expected call of RedirectHit.
+func (mr *MockURLMetricsMockRecorder) RedirectHit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedirectHit", reflect.TypeOf((*MockURLMetrics)(nil).RedirectHit))
+}
+
+// RedirectMiss mocks base method.
+func (m *MockURLMetrics) RedirectMiss() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RedirectMiss")
+}
+
+// RedirectMiss indicates an expected call of RedirectMiss.
+func (mr *MockURLMetricsMockRecorder) RedirectMiss() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedirectMiss", reflect.TypeOf((*MockURLMetrics)(nil).RedirectMiss))
+}
+
+// MockRepoObserver is a mock of RepoObserver interface.
+type MockRepoObserver struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepoObserverMockRecorder
+}
+
+// MockRepoObserverMockRecorder is the mock recorder for MockRepoObserver.
+type MockRepoObserverMockRecorder struct {
+	mock *MockRepoObserver
+}
+
+// NewMockRepoObserver creates a new mock instance.
+func NewMockRepoObserver(ctrl *gomock.Controller) *MockRepoObserver {
+	mock := &MockRepoObserver{ctrl: ctrl}
+	mock.recorder = &MockRepoObserverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepoObserver) EXPECT() *MockRepoObserverMockRecorder {
+	return m.recorder
+}
+
+// ObserveRepoOp mocks base method.
+func (m *MockRepoObserver) ObserveRepoOp(backend, op string, d time.Duration, err error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ObserveRepoOp", backend, op, d, err)
+}
+
+// ObserveRepoOp indicates an expected call of ObserveRepoOp.
+func (mr *MockRepoObserverMockRecorder) ObserveRepoOp(backend, op, d, err interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObserveRepoOp", reflect.TypeOf((*MockRepoObserver)(nil).ObserveRepoOp), backend, op, d, err)
+}
+
+// MockBatchDeleter is a mock of BatchDeleter interface.
+type MockBatchDeleter struct {
+	ctrl     *gomock.Controller
+	recorder *MockBatchDeleterMockRecorder
+}
+
+// MockBatchDeleterMockRecorder is the mock recorder for MockBatchDeleter.
+type MockBatchDeleterMockRecorder struct {
+	mock *MockBatchDeleter
+}
+
+// NewMockBatchDeleter creates a new mock instance.
+func NewMockBatchDeleter(ctrl *gomock.Controller) *MockBatchDeleter {
+	mock := &MockBatchDeleter{ctrl: ctrl}
+	mock.recorder = &MockBatchDeleterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBatchDeleter) EXPECT() *MockBatchDeleterMockRecorder {
+	return m.recorder
+}
+
+// BatchMarkAsDeleted mocks base method.
+func (m *MockBatchDeleter) BatchMarkAsDeleted(ctx context.Context, args []repositories.BatchDeleteArg) ([]models.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchMarkAsDeleted", ctx, args)
+	ret0, _ := ret[0].([]models.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchMarkAsDeleted indicates an expected call of BatchMarkAsDeleted.
+func (mr *MockBatchDeleterMockRecorder) BatchMarkAsDeleted(ctx, args interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchMarkAsDeleted", reflect.TypeOf((*MockBatchDeleter)(nil).BatchMarkAsDeleted), ctx, args)
+}
+
+// MockEventPublisher is a mock of EventPublisher interface.
+type MockEventPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventPublisherMockRecorder
+}
+
+// MockEventPublisherMockRecorder is the mock recorder for MockEventPublisher.
+type MockEventPublisherMockRecorder struct {
+	mock *MockEventPublisher
+}
+
+// NewMockEventPublisher creates a new mock instance.
+func NewMockEventPublisher(ctrl *gomock.Controller) *MockEventPublisher {
+	mock := &MockEventPublisher{ctrl: ctrl}
+	mock.recorder = &MockEventPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventPublisher) EXPECT() *MockEventPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockEventPublisher) Publish(e events.Event) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Publish", e)
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockEventPublisherMockRecorder) Publish(e interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockEventPublisher)(nil).Publish), e)
+}
+
+// MockEventSource is a mock of EventSource interface.
+type MockEventSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventSourceMockRecorder
+}
+
+// MockEventSourceMockRecorder is the mock recorder for MockEventSource.
+type MockEventSourceMockRecorder struct {
+	mock *MockEventSource
+}
+
+// NewMockEventSource creates a new mock instance.
+func NewMockEventSource(ctrl *gomock.Controller) *MockEventSource {
+	mock := &MockEventSource{ctrl: ctrl}
+	mock.recorder = &MockEventSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventSource) EXPECT() *MockEventSourceMockRecorder {
+	return m.recorder
+}
+
+// SubscribeAllBuffered mocks base method.
+func (m *MockEventSource) SubscribeAllBuffered(size int) *events.Subscription {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeAllBuffered", size)
+	ret0, _ := ret[0].(*events.Subscription)
+	return ret0
+}
+
+// SubscribeAllBuffered indicates an expected call of SubscribeAllBuffered.
+func (mr *MockEventSourceMockRecorder) SubscribeAllBuffered(size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeAllBuffered", reflect.TypeOf((*MockEventSource)(nil).SubscribeAllBuffered), size)
+}
+
+// MockWebhookRepository is a mock of WebhookRepository interface.
+type MockWebhookRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookRepositoryMockRecorder
+}
+
+// MockWebhookRepositoryMockRecorder is the mock recorder for MockWebhookRepository.
+type MockWebhookRepositoryMockRecorder struct {
+	mock *MockWebhookRepository
+}
+
+// NewMockWebhookRepository creates a new mock instance.
+func NewMockWebhookRepository(ctrl *gomock.Controller) *MockWebhookRepository {
+	mock := &MockWebhookRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhookRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookRepository) EXPECT() *MockWebhookRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimDueJobs mocks base method.
+func (m *MockWebhookRepository) ClaimDueJobs(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]repositories.WebhookJobClaim, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueJobs", ctx, now, lease, limit)
+	ret0, _ := ret[0].([]repositories.WebhookJobClaim)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueJobs indicates an expected call of ClaimDueJobs.
+func (mr *MockWebhookRepositoryMockRecorder) ClaimDueJobs(ctx, now, lease, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueJobs", reflect.TypeOf((*MockWebhookRepository)(nil).ClaimDueJobs), ctx, now, lease, limit)
+}
+
+// Create mocks base method.
+func (m *MockWebhookRepository) Create(ctx context.Context, w *models.Webhook) (*models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, w)
+	ret0, _ := ret[0].(*models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWebhookRepositoryMockRecorder) Create(ctx, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWebhookRepository)(nil).Create), ctx, w)
+}
+
+// CreateDelivery mocks base method.
+func (m *MockWebhookRepository) CreateDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDelivery", ctx, d)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDelivery indicates an expected call of CreateDelivery.
+func (mr *MockWebhookRepositoryMockRecorder) CreateDelivery(ctx, d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDelivery", reflect.TypeOf((*MockWebhookRepository)(nil).CreateDelivery), ctx, d)
+}
+
+// CreateJobs mocks base method.
+func (m *MockWebhookRepository) CreateJobs(ctx context.Context, jobs []models.WebhookJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJobs", ctx, jobs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateJobs indicates an expected call of CreateJobs.
+func (mr *MockWebhookRepositoryMockRecorder) CreateJobs(ctx, jobs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJobs", reflect.TypeOf((*MockWebhookRepository)(nil).CreateJobs), ctx, jobs)
+}
+
+// Delete mocks base method.
+func (m *MockWebhookRepository) Delete(ctx context.Context, id, visitorUUID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, visitorUUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockWebhookRepositoryMockRecorder) Delete(ctx, id, visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockWebhookRepository)(nil).Delete), ctx, id, visitorUUID)
+}
+
+// DeleteDeliveriesBefore mocks base method.
+func (m *MockWebhookRepository) DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDeliveriesBefore", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteDeliveriesBefore indicates an expected call of DeleteDeliveriesBefore.
+func (mr *MockWebhookRepositoryMockRecorder) DeleteDeliveriesBefore(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDeliveriesBefore", reflect.TypeOf((*MockWebhookRepository)(nil).DeleteDeliveriesBefore), ctx, before)
+}
+
+// DeleteJob mocks base method.
+func (m *MockWebhookRepository) DeleteJob(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteJob", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteJob indicates an expected call of DeleteJob.
+func (mr *MockWebhookRepositoryMockRecorder) DeleteJob(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteJob", reflect.TypeOf((*MockWebhookRepository)(nil).DeleteJob), ctx, id)
+}
+
+// GetAllByVisitorUUID mocks base method.
+func (m *MockWebhookRepository) GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByVisitorUUID", ctx, visitorUUID)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByVisitorUUID indicates an expected call of GetAllByVisitorUUID.
+func (mr *MockWebhookRepositoryMockRecorder) GetAllByVisitorUUID(ctx, visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByVisitorUUID", reflect.TypeOf((*MockWebhookRepository)(nil).GetAllByVisitorUUID), ctx, visitorUUID)
+}
+
+// GetByIDVisitorUUID mocks base method.
+func (m *MockWebhookRepository) GetByIDVisitorUUID(ctx context.Context, id, visitorUUID string) (*models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDVisitorUUID", ctx, id, visitorUUID)
+	ret0, _ := ret[0].(*models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDVisitorUUID indicates an expected call of GetByIDVisitorUUID.
+func (mr *MockWebhookRepositoryMockRecorder) GetByIDVisitorUUID(ctx, id, visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDVisitorUUID", reflect.TypeOf((*MockWebhookRepository)(nil).GetByIDVisitorUUID), ctx, id, visitorUUID)
+}
+
+// GetDeliveriesByWebhookID mocks base method.
+func (m *MockWebhookRepository) GetDeliveriesByWebhookID(ctx context.Context, webhookID string, limit int) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveriesByWebhookID", ctx, webhookID, limit)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveriesByWebhookID indicates an expected call of GetDeliveriesByWebhookID.
+func (mr *MockWebhookRepositoryMockRecorder) GetDeliveriesByWebhookID(ctx, webhookID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveriesByWebhookID", reflect.TypeOf((*MockWebhookRepository)(nil).GetDeliveriesByWebhookID), ctx, webhookID, limit)
+}
+
+// GetVisitorUUIDs mocks base method.
+func (m *MockWebhookRepository) GetVisitorUUIDs(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVisitorUUIDs", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVisitorUUIDs indicates an expected call of GetVisitorUUIDs.
+func (mr *MockWebhookRepositoryMockRecorder) GetVisitorUUIDs(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVisitorUUIDs", reflect.TypeOf((*MockWebhookRepository)(nil).GetVisitorUUIDs), ctx)
+}
+
+// RescheduleJob mocks base method.
+func (m *MockWebhookRepository) RescheduleJob(ctx context.Context, id string, attempt int, nextAttemptAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RescheduleJob", ctx, id, attempt, nextAttemptAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RescheduleJob indicates an expected call of RescheduleJob.
+func (mr *MockWebhookRepositoryMockRecorder) RescheduleJob(ctx, id, attempt, nextAttemptAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RescheduleJob", reflect.TypeOf((*MockWebhookRepository)(nil).RescheduleJob), ctx, id, attempt, nextAttemptAt)
+}
+
+// MockWebhookMetrics is a mock of WebhookMetrics interface.
+type MockWebhookMetrics struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookMetricsMockRecorder
+}
+
+// MockWebhookMetricsMockRecorder is the mock recorder for MockWebhookMetrics.
+type MockWebhookMetricsMockRecorder struct {
+	mock *MockWebhookMetrics
+}
+
+// NewMockWebhookMetrics creates a new mock instance.
+func NewMockWebhookMetrics(ctrl *gomock.Controller) *MockWebhookMetrics {
+	mock := &MockWebhookMetrics{ctrl: ctrl}
+	mock.recorder = &MockWebhookMetricsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookMetrics) EXPECT() *MockWebhookMetricsMockRecorder {
+	return m.recorder
+}
+
+// WebhookEventsDropped mocks base method.
+func (m *MockWebhookMetrics) WebhookEventsDropped(n uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "WebhookEventsDropped", n)
+}
+
+// WebhookEventsDropped indicates an expected call of WebhookEventsDropped.
+func (mr *MockWebhookMetricsMockRecorder) WebhookEventsDropped(n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WebhookEventsDropped", reflect.TypeOf((*MockWebhookMetrics)(nil).WebhookEventsDropped), n)
+}
+
+// MockAPIKeyRepository is a mock of APIKeyRepository interface.
+type MockAPIKeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyRepositoryMockRecorder
+}
+
+// MockAPIKeyRepositoryMockRecorder is the mock recorder for MockAPIKeyRepository.
+type MockAPIKeyRepositoryMockRecorder struct {
+	mock *MockAPIKeyRepository
+}
+
+// NewMockAPIKeyRepository creates a new mock instance.
+func NewMockAPIKeyRepository(ctrl *gomock.Controller) *MockAPIKeyRepository {
+	mock := &MockAPIKeyRepository{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyRepository) EXPECT() *MockAPIKeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAPIKeyRepository) Create(ctx context.Context, k *models.APIKey) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, k)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAPIKeyRepositoryMockRecorder) Create(ctx, k interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAPIKeyRepository)(nil).Create), ctx, k)
+}
+
+// GetAllByVisitorUUID mocks base method.
+func (m *MockAPIKeyRepository) GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByVisitorUUID", ctx, visitorUUID)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByVisitorUUID indicates an expected call of GetAllByVisitorUUID.
+func (mr *MockAPIKeyRepositoryMockRecorder) GetAllByVisitorUUID(ctx, visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByVisitorUUID", reflect.TypeOf((*MockAPIKeyRepository)(nil).GetAllByVisitorUUID), ctx, visitorUUID)
+}
+
+// GetByPrefix mocks base method.
+func (m *MockAPIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByPrefix", ctx, prefix)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByPrefix indicates an expected call of GetByPrefix.
+func (mr *MockAPIKeyRepositoryMockRecorder) GetByPrefix(ctx, prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByPrefix", reflect.TypeOf((*MockAPIKeyRepository)(nil).GetByPrefix), ctx, prefix)
+}
+
+// Revoke mocks base method.
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, id, visitorUUID string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id, visitorUUID, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockAPIKeyRepositoryMockRecorder) Revoke(ctx, id, visitorUUID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockAPIKeyRepository)(nil).Revoke), ctx, id, visitorUUID, at)
+}
+
+// MockIdempotencyRepository is a mock of IdempotencyRepository interface.
+type MockIdempotencyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyRepositoryMockRecorder
+}
+
+// MockIdempotencyRepositoryMockRecorder is the mock recorder for MockIdempotencyRepository.
+type MockIdempotencyRepositoryMockRecorder struct {
+	mock *MockIdempotencyRepository
+}
+
+// NewMockIdempotencyRepository creates a new mock instance.
+func NewMockIdempotencyRepository(ctrl *gomock.Controller) *MockIdempotencyRepository {
+	mock := &MockIdempotencyRepository{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyRepository) EXPECT() *MockIdempotencyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Complete mocks base method.
+func (m *MockIdempotencyRepository) Complete(ctx context.Context, r *models.IdempotencyRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockIdempotencyRepositoryMockRecorder) Complete(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockIdempotencyRepository)(nil).Complete), ctx, r)
+}
+
+// Delete mocks base method.
+func (m *MockIdempotencyRepository) Delete(ctx context.Context, visitorUUID, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, visitorUUID, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIdempotencyRepositoryMockRecorder) Delete(ctx, visitorUUID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIdempotencyRepository)(nil).Delete), ctx, visitorUUID, key)
+}
+
+// DeleteExpired mocks base method.
+func (m *MockIdempotencyRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockIdempotencyRepositoryMockRecorder) DeleteExpired(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockIdempotencyRepository)(nil).DeleteExpired), ctx, before)
+}
+
+// Reserve mocks base method.
+func (m *MockIdempotencyRepository) Reserve(ctx context.Context, r *models.IdempotencyRecord) (*models.IdempotencyRecord, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reserve", ctx, r)
+	ret0, _ := ret[0].(*models.IdempotencyRecord)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Reserve indicates an expected call of Reserve.
+func (mr *MockIdempotencyRepositoryMockRecorder) Reserve(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reserve", reflect.TypeOf((*MockIdempotencyRepository)(nil).Reserve), ctx, r)
+}
+
+// MockTokenRevocationRepository is a mock of TokenRevocationRepository interface.
+type MockTokenRevocationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenRevocationRepositoryMockRecorder
+}
+
+// MockTokenRevocationRepositoryMockRecorder is the mock recorder for MockTokenRevocationRepository.
+type MockTokenRevocationRepositoryMockRecorder struct {
+	mock *MockTokenRevocationRepository
+}
+
+// NewMockTokenRevocationRepository creates a new mock instance.
+func NewMockTokenRevocationRepository(ctrl *gomock.Controller) *MockTokenRevocationRepository {
+	mock := &MockTokenRevocationRepository{ctrl: ctrl}
+	mock.recorder = &MockTokenRevocationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenRevocationRepository) EXPECT() *MockTokenRevocationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteExpired mocks base method.
+func (m *MockTokenRevocationRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockTokenRevocationRepositoryMockRecorder) DeleteExpired(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockTokenRevocationRepository)(nil).DeleteExpired), ctx, before)
+}
+
+// GetSessionRevocation mocks base method.
+func (m *MockTokenRevocationRepository) GetSessionRevocation(ctx context.Context, visitorUUID string) (*models.SessionRevocation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionRevocation", ctx, visitorUUID)
+	ret0, _ := ret[0].(*models.SessionRevocation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionRevocation indicates an expected call of GetSessionRevocation.
+func (mr *MockTokenRevocationRepositoryMockRecorder) GetSessionRevocation(ctx, visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionRevocation", reflect.TypeOf((*MockTokenRevocationRepository)(nil).GetSessionRevocation), ctx, visitorUUID)
+}
+
+// IsTokenRevoked mocks base method.
+func (m *MockTokenRevocationRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTokenRevoked", ctx, jti)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsTokenRevoked indicates an expected call of IsTokenRevoked.
+func (mr *MockTokenRevocationRepositoryMockRecorder) IsTokenRevoked(ctx, jti interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTokenRevoked", reflect.TypeOf((*MockTokenRevocationRepository)(nil).IsTokenRevoked), ctx, jti)
+}
+
+// RevokeSessions mocks base method.
+func (m *MockTokenRevocationRepository) RevokeSessions(ctx context.Context, r *models.SessionRevocation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSessions", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSessions indicates an expected call of RevokeSessions.
+func (mr *MockTokenRevocationRepositoryMockRecorder) RevokeSessions(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessions", reflect.TypeOf((*MockTokenRevocationRepository)(nil).RevokeSessions), ctx, r)
+}
+
+// RevokeToken mocks base method.
+func (m *MockTokenRevocationRepository) RevokeToken(ctx context.Context, t *models.RevokedToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeToken", ctx, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MockTokenRevocationRepositoryMockRecorder) RevokeToken(ctx, t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockTokenRevocationRepository)(nil).RevokeToken), ctx, t)
+}
+
+// MockUserRepository is a mock of UserRepository interface.
+type MockUserRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserRepositoryMockRecorder
+}
+
+// MockUserRepositoryMockRecorder is the mock recorder for MockUserRepository.
+type MockUserRepositoryMockRecorder struct {
+	mock *MockUserRepository
+}
+
+// NewMockUserRepository creates a new mock instance.
+func NewMockUserRepository(ctrl *gomock.Controller) *MockUserRepository {
+	mock := &MockUserRepository{ctrl: ctrl}
+	mock.recorder = &MockUserRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockUserRepository) Create(ctx context.Context, u *models.User) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, u)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockUserRepositoryMockRecorder) Create(ctx, u interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserRepository)(nil).Create), ctx, u)
+}
+
+// GetByEmail mocks base method.
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByEmail", ctx, email)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByEmail indicates an expected call of GetByEmail.
+func (mr *MockUserRepositoryMockRecorder) GetByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmail", reflect.TypeOf((*MockUserRepository)(nil).GetByEmail), ctx, email)
+}
+
+// GetByID mocks base method.
+func (m *MockUserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockUserRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockUserRepository)(nil).GetByID), ctx, id)
+}
+
+// MockOIDCIdentityRepository is a mock of OIDCIdentityRepository interface.
+type MockOIDCIdentityRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCIdentityRepositoryMockRecorder
+}
+
+// MockOIDCIdentityRepositoryMockRecorder is the mock recorder for MockOIDCIdentityRepository.
+type MockOIDCIdentityRepositoryMockRecorder struct {
+	mock *MockOIDCIdentityRepository
+}
+
+// NewMockOIDCIdentityRepository creates a new mock instance.
+func NewMockOIDCIdentityRepository(ctrl *gomock.Controller) *MockOIDCIdentityRepository {
+	mock := &MockOIDCIdentityRepository{ctrl: ctrl}
+	mock.recorder = &MockOIDCIdentityRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDCIdentityRepository) EXPECT() *MockOIDCIdentityRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockOIDCIdentityRepository) Create(ctx context.Context, i *models.OIDCIdentity) (*models.OIDCIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, i)
+	ret0, _ := ret[0].(*models.OIDCIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockOIDCIdentityRepositoryMockRecorder) Create(ctx, i interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOIDCIdentityRepository)(nil).Create), ctx, i)
+}
+
+// Get mocks base method.
+func (m *MockOIDCIdentityRepository) Get(ctx context.Context, issuer, subject string) (*models.OIDCIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, issuer, subject)
+	ret0, _ := ret[0].(*models.OIDCIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockOIDCIdentityRepositoryMockRecorder) Get(ctx, issuer, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOIDCIdentityRepository)(nil).Get), ctx, issuer, subject)
+}
+
+// GetByUserID mocks base method.
+func (m *MockOIDCIdentityRepository) GetByUserID(ctx context.Context, userID string) (*models.OIDCIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserID", ctx, userID)
+	ret0, _ := ret[0].(*models.OIDCIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserID indicates an expected call of GetByUserID.
+func (mr *MockOIDCIdentityRepositoryMockRecorder) GetByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockOIDCIdentityRepository)(nil).GetByUserID), ctx, userID)
+}
+
+// MockVisitorBanRepository is a mock of VisitorBanRepository interface.
+type MockVisitorBanRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockVisitorBanRepositoryMockRecorder
+}
+
+// MockVisitorBanRepositoryMockRecorder is the mock recorder for MockVisitorBanRepository.
+type MockVisitorBanRepositoryMockRecorder struct {
+	mock *MockVisitorBanRepository
+}
+
+// NewMockVisitorBanRepository creates a new mock instance.
+func NewMockVisitorBanRepository(ctrl *gomock.Controller) *MockVisitorBanRepository {
+	mock := &MockVisitorBanRepository{ctrl: ctrl}
+	mock.recorder = &MockVisitorBanRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVisitorBanRepository) EXPECT() *MockVisitorBanRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockVisitorBanRepository) Create(ctx context.Context, b *models.VisitorBan) (*models.VisitorBan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, b)
+	ret0, _ := ret[0].(*models.VisitorBan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockVisitorBanRepositoryMockRecorder) Create(ctx, b interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockVisitorBanRepository)(nil).Create), ctx, b)
+}
+
+// Delete mocks base method.
+func (m *MockVisitorBanRepository) Delete(ctx context.Context, visitorUUID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, visitorUUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockVisitorBanRepositoryMockRecorder) Delete(ctx, visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockVisitorBanRepository)(nil).Delete), ctx, visitorUUID)
+}
+
+// Get mocks base method.
+func (m *MockVisitorBanRepository) Get(ctx context.Context, visitorUUID string) (*models.VisitorBan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, visitorUUID)
+	ret0, _ := ret[0].(*models.VisitorBan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockVisitorBanRepositoryMockRecorder) Get(ctx, visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockVisitorBanRepository)(nil).Get), ctx, visitorUUID)
+}
+
+// MockAuditRepository is a mock of AuditRepository interface.
+type MockAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepositoryMockRecorder
+}
+
+// MockAuditRepositoryMockRecorder is the mock recorder for MockAuditRepository.
+type MockAuditRepositoryMockRecorder struct {
+	mock *MockAuditRepository
+}
+
+// NewMockAuditRepository creates a new mock instance.
+func NewMockAuditRepository(ctrl *gomock.Controller) *MockAuditRepository {
+	mock := &MockAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepository) EXPECT() *MockAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAuditRepository) Create(ctx context.Context, e *models.AuditEntry) (*models.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, e)
+	ret0, _ := ret[0].(*models.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAuditRepositoryMockRecorder) Create(ctx, e interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAuditRepository)(nil).Create), ctx, e)
+}
+
+// List mocks base method.
+func (m *MockAuditRepository) List(ctx context.Context, filter repositories.AuditFilter) ([]models.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]models.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAuditRepositoryMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAuditRepository)(nil).List), ctx, filter)
+}
+
+// MockWorkspaceRepository is a mock of WorkspaceRepository interface.
+type MockWorkspaceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceRepositoryMockRecorder
+}
+
+// MockWorkspaceRepositoryMockRecorder is the mock recorder for MockWorkspaceRepository.
+type MockWorkspaceRepositoryMockRecorder struct {
+	mock *MockWorkspaceRepository
+}
+
+// NewMockWorkspaceRepository creates a new mock instance.
+func NewMockWorkspaceRepository(ctrl *gomock.Controller) *MockWorkspaceRepository {
+	mock := &MockWorkspaceRepository{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWorkspaceRepository) EXPECT() *MockWorkspaceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockWorkspaceRepository) Create(ctx context.Context, w *models.Workspace) (*models.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, w)
+	ret0, _ := ret[0].(*models.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWorkspaceRepositoryMockRecorder) Create(ctx, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWorkspaceRepository)(nil).Create), ctx, w)
+}
+
+// DeleteMember mocks base method.
+func (m *MockWorkspaceRepository) DeleteMember(ctx context.Context, workspaceID, visitorUUID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMember", ctx, workspaceID, visitorUUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMember indicates an expected call of DeleteMember.
+func (mr *MockWorkspaceRepositoryMockRecorder) DeleteMember(ctx, workspaceID, visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMember", reflect.TypeOf((*MockWorkspaceRepository)(nil).DeleteMember), ctx, workspaceID, visitorUUID)
+}
+
+// Get mocks base method.
+func (m *MockWorkspaceRepository) Get(ctx context.Context, id string) (*models.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*models.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockWorkspaceRepositoryMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockWorkspaceRepository)(nil).Get), ctx, id)
+}
+
+// GetMember mocks base method.
+func (m *MockWorkspaceRepository) GetMember(ctx context.Context, workspaceID, visitorUUID string) (*models.WorkspaceMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMember", ctx, workspaceID, visitorUUID)
+	ret0, _ := ret[0].(*models.WorkspaceMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMember indicates an expected call of GetMember.
+func (mr *MockWorkspaceRepositoryMockRecorder) GetMember(ctx, workspaceID, visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMember", reflect.TypeOf((*MockWorkspaceRepository)(nil).GetMember), ctx, workspaceID, visitorUUID)
+}
+
+// ListByMember mocks base method.
+func (m *MockWorkspaceRepository) ListByMember(ctx context.Context, visitorUUID string) ([]models.WorkspaceMembership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByMember", ctx, visitorUUID)
+	ret0, _ := ret[0].([]models.WorkspaceMembership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByMember indicates an expected call of ListByMember.
+func (mr *MockWorkspaceRepositoryMockRecorder) ListByMember(ctx, visitorUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByMember", reflect.TypeOf((*MockWorkspaceRepository)(nil).ListByMember), ctx, visitorUUID)
+}
+
+// ListMembers mocks base method.
+func (m *MockWorkspaceRepository) ListMembers(ctx context.Context, workspaceID string) ([]models.WorkspaceMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembers", ctx, workspaceID)
+	ret0, _ := ret[0].([]models.WorkspaceMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMembers indicates an expected call of ListMembers.
+func (mr *MockWorkspaceRepositoryMockRecorder) ListMembers(ctx, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembers", reflect.TypeOf((*MockWorkspaceRepository)(nil).ListMembers), ctx, workspaceID)
+}
+
+// SetMember mocks base method.
+func (m *MockWorkspaceRepository) SetMember(ctx context.Context, member *models.WorkspaceMember) (*models.WorkspaceMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMember", ctx, member)
+	ret0, _ := ret[0].(*models.WorkspaceMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetMember indicates an expected call of SetMember.
+func (mr *MockWorkspaceRepositoryMockRecorder) SetMember(ctx, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMember", reflect.TypeOf((*MockWorkspaceRepository)(nil).SetMember), ctx, member)
+}
+
+// MockOIDCClient is a mock of OIDCClient interface.
+type MockOIDCClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCClientMockRecorder
+}
+
+// MockOIDCClientMockRecorder is the mock recorder for MockOIDCClient.
+type MockOIDCClientMockRecorder struct {
+	mock *MockOIDCClient
+}
+
+// NewMockOIDCClient creates a new mock instance.
+func NewMockOIDCClient(ctrl *gomock.Controller) *MockOIDCClient {
+	mock := &MockOIDCClient{ctrl: ctrl}
+	mock.recorder = &MockOIDCClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDCClient) EXPECT() *MockOIDCClientMockRecorder {
+	return m.recorder
+}
+
+// AuthCodeURL mocks base method.
+func (m *MockOIDCClient) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthCodeURL", ctx, state, nonce, verifier)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthCodeURL indicates an expected call of AuthCodeURL.
+func (mr *MockOIDCClientMockRecorder) AuthCodeURL(ctx, state, nonce, verifier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthCodeURL", reflect.TypeOf((*MockOIDCClient)(nil).AuthCodeURL), ctx, state, nonce, verifier)
+}
+
+// Exchange mocks base method.
+func (m *MockOIDCClient) Exchange(ctx context.Context, code, verifier, nonce string) (*oidc.IDTokenClaims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exchange", ctx, code, verifier, nonce)
+	ret0, _ := ret[0].(*oidc.IDTokenClaims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exchange indicates an expected call of Exchange.
+func (mr *MockOIDCClientMockRecorder) Exchange(ctx, code, verifier, nonce interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exchange", reflect.TypeOf((*MockOIDCClient)(nil).Exchange), ctx, code, verifier, nonce)
+}
+
+// MockVisitorMerger is a mock of VisitorMerger interface.
+type MockVisitorMerger struct {
+	ctrl     *gomock.Controller
+	recorder *MockVisitorMergerMockRecorder
+}
+
+// MockVisitorMergerMockRecorder is the mock recorder for MockVisitorMerger.
+type MockVisitorMergerMockRecorder struct {
+	mock *MockVisitorMerger
+}
+
+// NewMockVisitorMerger creates a new mock instance.
+func NewMockVisitorMerger(ctrl *gomock.Controller) *MockVisitorMerger {
+	mock := &MockVisitorMerger{ctrl: ctrl}
+	mock.recorder = &MockVisitorMergerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVisitorMerger) EXPECT() *MockVisitorMergerMockRecorder {
+	return m.recorder
+}
+
+// MergeVisitor mocks base method.
+func (m *MockVisitorMerger) MergeVisitor(ctx context.Context, fromUUID, toUUID string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeVisitor", ctx, fromUUID, toUUID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeVisitor indicates an expected call of MergeVisitor.
+func (mr *MockVisitorMergerMockRecorder) MergeVisitor(ctx, fromUUID, toUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeVisitor", reflect.TypeOf((*MockVisitorMerger)(nil).MergeVisitor), ctx, fromUUID, toUUID)
+}
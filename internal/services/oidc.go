@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/oidc"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/google/uuid"
+)
+
+// OIDCLoginResult результат входа через провайдера OpenID Connect.
+type OIDCLoginResult struct {
+	Identity   *models.OIDCIdentity
+	MergedURLs int // Количество ссылок анонимного посетителя, переданных аккаунту
+}
+
+// OIDCService выполняет вход через внешнего провайдера OpenID Connect.
+// Пользователь провайдера связывается с постоянным UUID посетителя, поэтому
+// его ссылки доступны на любом устройстве после входа.
+type OIDCService struct {
+	client     OIDCClient
+	identities OIDCIdentityRepository
+	users      UserRepository
+	merger     VisitorMerger
+}
+
+// NewOIDCService создает новый экземпляр сервиса входа через OpenID Connect.
+//
+// Параметры:
+//   - client: клиент провайдера
+//   - identities: репозиторий учетных записей провайдера
+//   - users: репозиторий пользователей с паролем, чтобы не передавать их ссылки при входе
+//   - merger: получатель ссылок анонимного посетителя при входе
+//
+// Возвращает:
+//   - *OIDCService: инициализированный сервис
+func NewOIDCService(
+	client OIDCClient,
+	identities OIDCIdentityRepository,
+	users UserRepository,
+	merger VisitorMerger,
+) *OIDCService {
+	return &OIDCService{client: client, identities: identities, users: users, merger: merger}
+}
+
+// AuthCodeURL формирует адрес страницы входа провайдера.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - state: значение для защиты от CSRF
+//   - nonce: значение, которое провайдер включит в ID токен
+//   - verifier: code_verifier PKCE
+//
+// Возвращает:
+//   - string: адрес страницы входа
+//   - error: ErrIdentityProviderUnavailable, если провайдер недоступен
+func (s *OIDCService) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	authURL, err := s.client.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", convertOIDCError(err)
+	}
+	return authURL, nil
+}
+
+// Login обменивает код авторизации на ID токен и определяет UUID пользователя провайдера.
+// При первом входе анонимного посетителя его UUID становится UUID пользователя, и ссылки
+// остаются на месте. При последующих входах ссылки анонимного посетителя передаются пользователю.
+// Ссылки посетителя, который сам является аккаунтом, не передаются.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: UUID текущего посетителя
+//   - code: код авторизации
+//   - verifier: code_verifier PKCE
+//   - nonce: nonce, переданный провайдеру
+//
+// Возвращает:
+//   - *OIDCLoginResult: учетная запись и количество переданных ссылок
+//   - error: ErrInvalidCredentials, если код или ID токен не приняты,
+//     ErrIdentityProviderUnavailable, если провайдер недоступен
+func (s *OIDCService) Login(
+	ctx context.Context,
+	visitorUUID string,
+	code string,
+	verifier string,
+	nonce string,
+) (_ *OIDCLoginResult, err error) {
+	ctx, span := startSpan(ctx, "OIDCService.Login")
+	defer func() { finishSpan(span, err) }()
+
+	claims, err := s.client.Exchange(ctx, code, verifier, nonce)
+	if err != nil {
+		return nil, convertOIDCError(err)
+	}
+
+	anonymous, err := s.isAnonymous(ctx, visitorUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := s.identities.Get(ctx, claims.Issuer, claims.Subject)
+	if errors.Is(err, repositories.ErrNotFound) {
+		identity, err = s.createIdentity(ctx, claims, visitorUUID, anonymous)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: get oidc identity: %s", ErrUnknown, err.Error())
+	}
+
+	result := &OIDCLoginResult{Identity: identity}
+	if !anonymous || visitorUUID == identity.UserID {
+		return result, nil
+	}
+	if result.MergedURLs, err = s.merger.MergeVisitor(ctx, visitorUUID, identity.UserID); err != nil {
+		return nil, fmt.Errorf("merge visitor: %w", err)
+	}
+	return result, nil
+}
+
+// createIdentity связывает пользователя провайдера с UUID посетителя. Анонимный посетитель
+// отдает свой UUID, иначе выдается новый. Если параллельный вход уже создал запись, возвращает ее.
+func (s *OIDCService) createIdentity(
+	ctx context.Context,
+	claims *oidc.IDTokenClaims,
+	visitorUUID string,
+	anonymous bool,
+) (*models.OIDCIdentity, error) {
+	userID := visitorUUID
+	if !anonymous {
+		userID = uuid.NewString()
+	}
+	identity, err := s.identities.Create(ctx, &models.OIDCIdentity{
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+		UserID:  userID,
+		Email:   claims.Email,
+	})
+	if errors.Is(err, repositories.ErrDuplicateKey) {
+		return s.identities.Get(ctx, claims.Issuer, claims.Subject) //nolint:wrapcheck
+	}
+	return identity, err //nolint:wrapcheck
+}
+
+// isAnonymous сообщает, что посетитель не является аккаунтом с паролем или пользователем провайдера.
+func (s *OIDCService) isAnonymous(ctx context.Context, visitorUUID string) (bool, error) {
+	if visitorUUID == "" {
+		return false, nil
+	}
+	account, err := isAccount(ctx, s.users, s.identities, visitorUUID)
+	if err != nil {
+		return false, err
+	}
+	return !account, nil
+}
+
+// convertOIDCError преобразует ошибки клиента OpenID Connect в ошибки сервиса.
+func convertOIDCError(err error) error {
+	switch {
+	case errors.Is(err, oidc.ErrCodeRejected), errors.Is(err, oidc.ErrInvalidIDToken):
+		return fmt.Errorf("%w: %s", ErrInvalidCredentials, err.Error())
+	case errors.Is(err, oidc.ErrProviderUnavailable):
+		return fmt.Errorf("%w: %s", ErrIdentityProviderUnavailable, err.Error())
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknown, err.Error())
+	}
+}
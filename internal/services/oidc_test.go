@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/oidc"
+	"github.com/fsdevblog/shorturl/internal/oidc/oidctest"
+	"github.com/fsdevblog/shorturl/internal/repositories/memstore"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testOIDCClientID = "shorturl"
+
+// oidcTestEnv сервисы, работающие с общим хранилищем и локальным провайдером.
+type oidcTestEnv struct {
+	provider *oidctest.Provider
+	oidc     *OIDCService
+	users    *UserService
+	urls     *URLService
+}
+
+func newOIDCTestEnv(t *testing.T) *oidcTestEnv {
+	t.Helper()
+	provider := oidctest.NewProvider(t, testOIDCClientID)
+	client := oidc.NewClient(oidc.Config{
+		Issuer:      provider.Issuer(),
+		ClientID:    testOIDCClientID,
+		RedirectURL: "https://short.example.com/api/oidc/callback",
+	})
+
+	store := db.NewMemStorage()
+	urlService := NewURLService(memstore.NewURLRepo(store))
+	return &oidcTestEnv{
+		provider: provider,
+		oidc: NewOIDCService(
+			client, memstore.NewOIDCIdentityRepo(store), memstore.NewUserRepo(store), urlService,
+		),
+		users: NewUserService(memstore.NewUserRepo(store), urlService, func(o *UserServiceOptions) {
+			o.BcryptCost = bcrypt.MinCost
+			o.Identities = memstore.NewOIDCIdentityRepo(store)
+		}),
+		urls: urlService,
+	}
+}
+
+// login проходит вход пользователя subject у провайдера от имени посетителя.
+func (e *oidcTestEnv) login(t *testing.T, visitorUUID, subject string) (*OIDCLoginResult, error) {
+	t.Helper()
+	ctx := context.Background()
+	verifier, err := oidc.RandomString()
+	require.NoError(t, err)
+	authURL, err := e.oidc.AuthCodeURL(ctx, "state", "nonce", verifier)
+	require.NoError(t, err)
+	callback, err := e.provider.Authorize(authURL, subject, subject+"@example.com")
+	require.NoError(t, err)
+	u, err := url.Parse(callback)
+	require.NoError(t, err)
+	return e.oidc.Login(ctx, visitorUUID, u.Query().Get("code"), verifier, "nonce")
+}
+
+func TestOIDCService_Login(t *testing.T) {
+	ctx := context.Background()
+	env := newOIDCTestEnv(t)
+
+	// Первый вход: анонимный посетитель становится пользователем вместе со своими ссылками.
+	laptop := uuid.NewString()
+	_, _, err := env.urls.Create(ctx, laptop, "https://example.com/laptop")
+	require.NoError(t, err)
+	res, err := env.login(t, laptop, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, laptop, res.Identity.UserID)
+	assert.Equal(t, env.provider.Issuer(), res.Identity.Issuer)
+	assert.Zero(t, res.MergedURLs)
+
+	// Вход с другого устройства дает тот же UUID и передает ему ссылки устройства.
+	phone := uuid.NewString()
+	_, _, err = env.urls.Create(ctx, phone, "https://example.com/phone")
+	require.NoError(t, err)
+	res, err = env.login(t, phone, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, laptop, res.Identity.UserID)
+	assert.Equal(t, 1, res.MergedURLs)
+	aliceURLs, err := env.urls.GetAllByVisitorUUID(ctx, laptop)
+	require.NoError(t, err)
+	assert.Len(t, aliceURLs, 2)
+
+	// Вход другого пользователя на устройстве alice не забирает ее ссылки.
+	res, err = env.login(t, laptop, "bob")
+	require.NoError(t, err)
+	assert.NotEqual(t, laptop, res.Identity.UserID)
+	assert.Zero(t, res.MergedURLs)
+	aliceURLs, err = env.urls.GetAllByVisitorUUID(ctx, laptop)
+	require.NoError(t, err)
+	assert.Len(t, aliceURLs, 2)
+
+	// Аккаунт с паролем тоже не отдает свой UUID и ссылки.
+	account, err := env.users.Register(ctx, uuid.NewString(), "carol@example.com", "correct horse")
+	require.NoError(t, err)
+	res, err = env.login(t, account.ID, "carol")
+	require.NoError(t, err)
+	assert.NotEqual(t, account.ID, res.Identity.UserID)
+	assert.Zero(t, res.MergedURLs)
+}
+
+func TestUserService_OIDCAccount(t *testing.T) {
+	ctx := context.Background()
+	env := newOIDCTestEnv(t)
+
+	laptop := uuid.NewString()
+	_, _, err := env.urls.Create(ctx, laptop, "https://example.com/laptop")
+	require.NoError(t, err)
+	res, err := env.login(t, laptop, "alice")
+	require.NoError(t, err)
+	require.Equal(t, laptop, res.Identity.UserID)
+
+	// Регистрация на устройстве пользователя провайдера не занимает его UUID.
+	dave, err := env.users.Register(ctx, laptop, "dave@example.com", "correct horse")
+	require.NoError(t, err)
+	assert.NotEqual(t, laptop, dave.ID)
+
+	// Вход с паролем на том же устройстве не забирает ссылки пользователя провайдера.
+	login, err := env.users.Login(ctx, laptop, "dave@example.com", "correct horse")
+	require.NoError(t, err)
+	assert.Zero(t, login.MergedURLs)
+	aliceURLs, err := env.urls.GetAllByVisitorUUID(ctx, laptop)
+	require.NoError(t, err)
+	assert.Len(t, aliceURLs, 1)
+}
+
+func TestOIDCService_LoginErrors(t *testing.T) {
+	ctx := context.Background()
+	env := newOIDCTestEnv(t)
+
+	_, err := env.oidc.Login(ctx, uuid.NewString(), "unknown-code", "verifier", "nonce")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	env.provider.ModifyClaims = func(claims jwt.MapClaims) { claims["aud"] = "other-client" }
+	_, err = env.login(t, uuid.NewString(), "alice")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	down := NewOIDCService(
+		oidc.NewClient(oidc.Config{Issuer: "http://127.0.0.1:1", ClientID: testOIDCClientID}),
+		nil, nil, nil,
+	)
+	_, err = down.AuthCodeURL(ctx, "state", "nonce", "verifier")
+	require.ErrorIs(t, err, ErrIdentityProviderUnavailable)
+}
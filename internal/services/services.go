@@ -3,10 +3,12 @@ package services
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/events"
 	"github.com/fsdevblog/shorturl/internal/repositories/memstore"
 	"github.com/fsdevblog/shorturl/internal/repositories/sql"
 )
@@ -23,29 +25,70 @@ const (
 
 // Services объединяет все сервисы приложения.
 type Services struct {
-	URLService  *URLService  // Сервис для работы с URL
-	PingService *PingService // Сервис для проверки соединения
+	URLService     *URLService     // Сервис для работы с URL
+	PingService    *PingService    // Сервис для проверки соединения
+	EventsHub      *events.Hub     // Шина событий о ссылках
+	WebhookService *WebhookService // Сервис вебхуков
+
+	IdempotencyService *IdempotencyService // Сервис ключей идемпотентности
+	DeletionService    *DeletionService    // Очередь фонового удаления ссылок
+	APIKeyService      *APIKeyService      // Сервис ключей API
+	UserService        *UserService        // Сервис зарегистрированных пользователей
+	OIDCService        *OIDCService        // Сервис входа через OpenID Connect (nil, если не задан OIDCClient)
+	AdminService       *AdminService       // Модерация ссылок и посетителей
+	WorkspaceService   *WorkspaceService   // Рабочие пространства и ссылки команд
+
+	TokenRevocationService *TokenRevocationService // Отзыв токенов посетителей
+	BackupService          Backuper                // Бэкап хранилища в файл при остановке
+}
+
+// ServiceMetrics объединяет сборщики метрик сервисного слоя.
+type ServiceMetrics interface {
+	URLMetrics
+	RepoObserver
+	WebhookMetrics
+}
+
+// FactoryOptions опции фабрики сервисов.
+type FactoryOptions struct {
+	BaseURL        string          // Базовый адрес коротких ссылок
+	OnWebhookError func(err error) // Обработчик внутренних ошибок доставки вебхуков
+	Metrics        ServiceMetrics  // Сборщик метрик (если nil, метрики не собираются)
+	IdempotencyTTL time.Duration   // Время хранения ответов на запросы с Idempotency-Key (0 - по умолчанию)
+	OnError        func(err error) // Обработчик ошибок фоновых задач сервисов
+	OIDCClient     OIDCClient      // Клиент провайдера OpenID Connect (если nil, вход через OIDC отключен)
+	RevocationTTL  time.Duration   // Время хранения отзыва токена посетителя (0 - по умолчанию)
 }
 
 // Factory создает набор сервисов в зависимости от указанного типа.
 //
 // Параметры:
-//   - conn: соединение с хранилищем данных
+//   - conn: соединение с хранилищем данных (*pgxpool.Pool или *db.MemoryStorage)
 //   - sType: тип сервисов (ServiceTypePostgres или ServiceTypeInMemory)
+//   - opts: функции для настройки опций
 //
 // Возвращает:
 //   - *Services: инициализированные сервисы
 //   - error: ошибка создания сервисов
-func Factory(conn any, sType ServiceType) (*Services, error) {
+func Factory(conn any, sType ServiceType, opts ...func(*FactoryOptions)) (*Services, error) {
+	var options FactoryOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	switch sType {
 	case ServiceTypePostgres:
 		pool, ok := conn.(*pgxpool.Pool)
 		if !ok {
 			return nil, errors.New("invalid connection type. expected *pgxpool.Pool")
 		}
-		return getSQLServices(pool), nil
+		return getSQLServices(pool, &options), nil
 	case ServiceTypeInMemory:
-		return getInMemoryServices(), nil
+		store, ok := conn.(*db.MemoryStorage)
+		if !ok {
+			store = db.NewMemStorage()
+		}
+		return getInMemoryServices(store, &options), nil
 	default:
 		return nil, fmt.Errorf("unknown service type: %s", sType)
 	}
@@ -55,26 +98,156 @@ func Factory(conn any, sType ServiceType) (*Services, error) {
 //
 // Параметры:
 //   - conn: пул подключений к PostgreSQL
+//   - options: опции фабрики
 //
 // Возвращает:
 //   - *Services: сервисы с PostgreSQL реализацией
-func getSQLServices(conn *pgxpool.Pool) *Services {
-	urlRepo := sql.NewURLRepo(conn)
-	return &Services{
-		URLService:  NewURLService(urlRepo),
-		PingService: NewPingService(conn),
+func getSQLServices(conn *pgxpool.Pool, options *FactoryOptions) *Services {
+	hub := events.NewHub()
+	urlService := newURLService(sql.NewURLRepo(conn), ServiceTypePostgres, hub, options)
+	services := &Services{
+		URLService:     urlService,
+		PingService:    NewPingService(conn),
+		EventsHub:      hub,
+		WebhookService: NewWebhookService(sql.NewWebhookRepo(conn), hub, webhookOptions(options)),
+
+		IdempotencyService: NewIdempotencyService(sql.NewIdempotencyRepo(conn), idempotencyOptions(options)),
+		DeletionService:    NewDeletionService(urlService, deletionOptions(options)),
+		APIKeyService:      NewAPIKeyService(sql.NewAPIKeyRepo(conn)),
+		UserService: NewUserService(sql.NewUserRepo(conn), urlService, func(o *UserServiceOptions) {
+			o.Identities = sql.NewOIDCIdentityRepo(conn)
+		}),
+		AdminService: NewAdminService(
+			newInstrumentedURLRepo(sql.NewURLRepo(conn), ServiceTypePostgres, repoObserver(options)),
+			sql.NewVisitorBanRepo(conn),
+			sql.NewAuditRepo(conn),
+		),
+		WorkspaceService: NewWorkspaceService(
+			sql.NewWorkspaceRepo(conn),
+			newInstrumentedURLRepo(sql.NewURLRepo(conn), ServiceTypePostgres, repoObserver(options)),
+		),
+		TokenRevocationService: NewTokenRevocationService(
+			sql.NewTokenRevocationRepo(conn), revocationOptions(options),
+		),
+		BackupService: urlService,
 	}
+	if options.OIDCClient != nil {
+		services.OIDCService = NewOIDCService(
+			options.OIDCClient, sql.NewOIDCIdentityRepo(conn), sql.NewUserRepo(conn), urlService,
+		)
+	}
+	return services
 }
 
 // getInMemoryServices создает сервисы для работы с in-memory хранилищем.
 //
+// Параметры:
+//   - store: in-memory хранилище
+//   - options: опции фабрики
+//
 // Возвращает:
 //   - *Services: сервисы с in-memory реализацией
-func getInMemoryServices() *Services {
-	store := db.NewMemStorage()
-	urlRepo := memstore.NewURLRepo(store)
-	return &Services{
-		URLService:  NewURLService(urlRepo),
-		PingService: NewPingService(store),
+func getInMemoryServices(store *db.MemoryStorage, options *FactoryOptions) *Services {
+	hub := events.NewHub()
+	urlService := newURLService(memstore.NewURLRepo(store), ServiceTypeInMemory, hub, options)
+	services := &Services{
+		URLService:     urlService,
+		PingService:    NewPingService(store),
+		EventsHub:      hub,
+		WebhookService: NewWebhookService(memstore.NewWebhookRepo(store), hub, webhookOptions(options)),
+
+		IdempotencyService: NewIdempotencyService(memstore.NewIdempotencyRepo(store), idempotencyOptions(options)),
+		DeletionService:    NewDeletionService(urlService, deletionOptions(options)),
+		APIKeyService:      NewAPIKeyService(memstore.NewAPIKeyRepo(store)),
+		UserService: NewUserService(memstore.NewUserRepo(store), urlService, func(o *UserServiceOptions) {
+			o.Identities = memstore.NewOIDCIdentityRepo(store)
+		}),
+		AdminService: NewAdminService(
+			newInstrumentedURLRepo(memstore.NewURLRepo(store), ServiceTypeInMemory, repoObserver(options)),
+			memstore.NewVisitorBanRepo(store),
+			memstore.NewAuditRepo(store),
+		),
+		WorkspaceService: NewWorkspaceService(
+			memstore.NewWorkspaceRepo(store),
+			newInstrumentedURLRepo(memstore.NewURLRepo(store), ServiceTypeInMemory, repoObserver(options)),
+		),
+		TokenRevocationService: NewTokenRevocationService(
+			memstore.NewTokenRevocationRepo(store), revocationOptions(options),
+		),
+		BackupService: NewMemoryBackupService(store),
+	}
+	if options.OIDCClient != nil {
+		services.OIDCService = NewOIDCService(
+			options.OIDCClient, memstore.NewOIDCIdentityRepo(store), memstore.NewUserRepo(store), urlService,
+		)
+	}
+	return services
+}
+
+// newURLService создает сервис URL с трассировкой репозитория, подключая события и, если заданы, метрики.
+func newURLService(
+	urlRepo URLRepository,
+	sType ServiceType,
+	hub *events.Hub,
+	options *FactoryOptions,
+) *URLService {
+	opts := []func(*URLServiceOptions){WithEventPublisher(hub)}
+	if options.Metrics != nil {
+		opts = append(opts, WithURLMetrics(options.Metrics))
+	}
+	return NewURLService(newInstrumentedURLRepo(urlRepo, sType, repoObserver(options)), opts...)
+}
+
+// repoObserver возвращает сборщик метрик репозитория из опций фабрики или nil, если метрики не собираются.
+func repoObserver(options *FactoryOptions) RepoObserver {
+	if options.Metrics == nil {
+		return nil
+	}
+	return options.Metrics
+}
+
+// webhookOptions переносит опции фабрики в опции сервиса вебхуков.
+func webhookOptions(options *FactoryOptions) func(*WebhookServiceOptions) {
+	return func(o *WebhookServiceOptions) {
+		o.BaseURL = options.BaseURL
+		if options.OnWebhookError != nil {
+			o.OnError = options.OnWebhookError
+		}
+		if options.Metrics != nil {
+			o.Metrics = options.Metrics
+		}
+	}
+}
+
+// idempotencyOptions переносит опции фабрики в опции сервиса ключей идемпотентности.
+func idempotencyOptions(options *FactoryOptions) func(*IdempotencyServiceOptions) {
+	return func(o *IdempotencyServiceOptions) {
+		if options.IdempotencyTTL > 0 {
+			o.TTL = options.IdempotencyTTL
+		}
+		if options.OnError != nil {
+			o.OnError = options.OnError
+		}
+	}
+}
+
+// deletionOptions переносит опции фабрики в опции очереди удаления.
+func deletionOptions(options *FactoryOptions) func(*DeletionServiceOptions) {
+	return func(o *DeletionServiceOptions) {
+		if options.OnError != nil {
+			o.OnError = options.OnError
+		}
+	}
+}
+
+// revocationOptions переносит опции фабрики в опции сервиса отзыва токенов.
+func revocationOptions(options *FactoryOptions) func(*TokenRevocationServiceOptions) {
+	return func(o *TokenRevocationServiceOptions) {
+		if options.RevocationTTL > 0 {
+			o.TTL = options.RevocationTTL
+		}
+		if options.OnError != nil {
+			o.OnError = options.OnError
+		}
 	}
 }
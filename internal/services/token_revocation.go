@@ -0,0 +1,288 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+)
+
+// Параметры отзыва токенов по умолчанию.
+const (
+	// DefaultTokenRevocationTTL время хранения отзыва: срок действия токена посетителя
+	// и время продления истекшего токена по умолчанию (24 часа и 7 суток).
+	DefaultTokenRevocationTTL = 8 * 24 * time.Hour
+	// DefaultRevocationCacheTTL время, в течение которого экземпляр может не заметить отзыв,
+	// сделанный другим экземпляром. Отзывы, сделанные этим экземпляром, действуют сразу.
+	DefaultRevocationCacheTTL  = 30 * time.Second
+	DefaultRevocationCacheSize = 10000
+
+	defaultRevocationCleanupInterval = 10 * time.Minute
+)
+
+// TokenRevocationServiceOptions опции сервиса отзыва токенов.
+type TokenRevocationServiceOptions struct {
+	// TTL время хранения отзыва. Должно быть не меньше времени, в течение которого принимается
+	// токен посетителя: его срока действия и времени продления истекшего токена.
+	TTL             time.Duration
+	CacheTTL        time.Duration    // Время хранения результата проверки в кеше процесса
+	CacheSize       int              // Наибольшее количество записей каждого кеша
+	CleanupInterval time.Duration    // Период удаления истекших отзывов
+	OnError         func(err error)  // Обработчик ошибок фоновой очистки
+	Now             func() time.Time // Источник времени (для тестов)
+}
+
+// TokenRevocationService отзывает токены посетителей до истечения их срока действия:
+// один токен при выходе или все токены посетителя при выходе со всех устройств.
+// Результаты проверок кешируются в процессе, чтобы проверка на каждом запросе,
+// включая переходы по ссылкам, не обращалась к хранилищу.
+type TokenRevocationService struct {
+	repo     TokenRevocationRepository
+	opts     TokenRevocationServiceOptions
+	tokens   *ttlCache[bool]      // Отозван ли токен, по jti
+	sessions *ttlCache[time.Time] // Момент отзыва сессий посетителя (нулевой, если не отзывались), по UUID
+}
+
+// NewTokenRevocationService создает новый экземпляр сервиса отзыва токенов.
+//
+// Параметры:
+//   - repo: репозиторий отозванных токенов
+//   - opts: функции для настройки опций
+//
+// Возвращает:
+//   - *TokenRevocationService: инициализированный сервис
+func NewTokenRevocationService(
+	repo TokenRevocationRepository,
+	opts ...func(*TokenRevocationServiceOptions),
+) *TokenRevocationService {
+	options := TokenRevocationServiceOptions{
+		TTL:             DefaultTokenRevocationTTL,
+		CacheTTL:        DefaultRevocationCacheTTL,
+		CacheSize:       DefaultRevocationCacheSize,
+		CleanupInterval: defaultRevocationCleanupInterval,
+		OnError:         func(error) {},
+		Now:             time.Now,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &TokenRevocationService{
+		repo:     repo,
+		opts:     options,
+		tokens:   newTTLCache[bool](options.CacheSize),
+		sessions: newTTLCache[time.Time](options.CacheSize),
+	}
+}
+
+// Revoke отзывает токен посетителя или сессию целиком. Идентификаторы токенов (jti) и сессий (sid)
+// случайны и хранятся вместе: отзыв сессии отклоняет все токены, выпущенные при ее продлении.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: UUID посетителя
+//   - jti: идентификатор токена или сессии
+//
+// Возвращает:
+//   - error: ErrInvalidArgument без идентификатора, ErrUnknown при ошибке сохранения
+func (s *TokenRevocationService) Revoke(ctx context.Context, visitorUUID string, jti string) (err error) {
+	ctx, span := startSpan(ctx, "TokenRevocationService.Revoke")
+	defer func() { finishSpan(span, err) }()
+
+	if jti == "" {
+		return fmt.Errorf("%w: token has no jti", ErrInvalidArgument)
+	}
+	now := s.opts.Now()
+	expiresAt := now.Add(s.opts.TTL).UTC()
+	err = s.repo.RevokeToken(ctx, &models.RevokedToken{JTI: jti, VisitorUUID: visitorUUID, ExpiresAt: expiresAt})
+	if err != nil {
+		return fmt.Errorf("%w: revoke token: %s", ErrUnknown, err.Error())
+	}
+	s.tokens.set(jti, true, expiresAt, now)
+	return nil
+}
+
+// RevokeAll отзывает все токены посетителя, выпущенные до текущего момента.
+// Время выпуска токена хранится с точностью до секунды, поэтому токены, выпущенные
+// в ту же секунду после отзыва, тоже считаются отозванными.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: UUID посетителя
+//
+// Возвращает:
+//   - error: ErrUnknown при ошибке сохранения
+func (s *TokenRevocationService) RevokeAll(ctx context.Context, visitorUUID string) (err error) {
+	ctx, span := startSpan(ctx, "TokenRevocationService.RevokeAll")
+	defer func() { finishSpan(span, err) }()
+
+	now := s.opts.Now()
+	revokedBefore := now.Truncate(time.Second).Add(time.Second).UTC()
+	err = s.repo.RevokeSessions(ctx, &models.SessionRevocation{
+		VisitorUUID:   visitorUUID,
+		RevokedBefore: revokedBefore,
+		ExpiresAt:     now.Add(s.opts.TTL).UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: revoke sessions: %s", ErrUnknown, err.Error())
+	}
+	s.sessions.set(visitorUUID, revokedBefore, now.Add(s.opts.CacheTTL), now)
+	return nil
+}
+
+// IsRevoked проверяет, отозван ли токен: по идентификатору, отзывом его сессии
+// или отзывом всех сессий посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: UUID посетителя из токена
+//   - jti: идентификатор токена (пустой у токенов, выпущенных до появления отзыва)
+//   - sessionID: идентификатор сессии (пустой у токенов, выпущенных до появления сессий)
+//   - issuedAt: время выпуска токена (нулевое, если не указано)
+//
+// Возвращает:
+//   - bool: true, если токен отозван
+//   - error: ErrUnknown при ошибке чтения
+func (s *TokenRevocationService) IsRevoked(
+	ctx context.Context,
+	visitorUUID string,
+	jti string,
+	sessionID string,
+	issuedAt time.Time,
+) (bool, error) {
+	now := s.opts.Now()
+	for _, id := range []string{jti, sessionID} {
+		if id == "" {
+			continue
+		}
+		revoked, err := s.isTokenRevoked(ctx, id, now)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+	revokedBefore, err := s.sessionsRevokedBefore(ctx, visitorUUID, now)
+	if err != nil {
+		return false, err
+	}
+	return issuedAt.Before(revokedBefore), nil
+}
+
+// Run периодически удаляет истекшие отзывы до отмены контекста.
+//
+// Параметры:
+//   - ctx: контекст работы сервиса
+func (s *TokenRevocationService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.repo.DeleteExpired(ctx, s.opts.Now()); err != nil {
+				s.opts.OnError(fmt.Errorf("delete expired token revocations: %w", err))
+			}
+		}
+	}
+}
+
+// isTokenRevoked проверяет отзыв токена по jti, сначала в кеше.
+// Отзыв окончателен, поэтому положительный результат кешируется на все время хранения отзыва.
+func (s *TokenRevocationService) isTokenRevoked(ctx context.Context, jti string, now time.Time) (_ bool, err error) {
+	if revoked, ok := s.tokens.get(jti, now); ok {
+		return revoked, nil
+	}
+
+	ctx, span := startSpan(ctx, "TokenRevocationService.isTokenRevoked")
+	defer func() { finishSpan(span, err) }()
+
+	revoked, err := s.repo.IsTokenRevoked(ctx, jti)
+	if err != nil {
+		return false, fmt.Errorf("%w: check token revocation: %s", ErrUnknown, err.Error())
+	}
+	until := now.Add(s.opts.CacheTTL)
+	if revoked {
+		until = now.Add(s.opts.TTL)
+	}
+	s.tokens.set(jti, revoked, until, now)
+	return revoked, nil
+}
+
+// sessionsRevokedBefore возвращает момент отзыва всех сессий посетителя или нулевое время, сначала из кеша.
+func (s *TokenRevocationService) sessionsRevokedBefore(
+	ctx context.Context,
+	visitorUUID string,
+	now time.Time,
+) (_ time.Time, err error) {
+	if revokedBefore, ok := s.sessions.get(visitorUUID, now); ok {
+		return revokedBefore, nil
+	}
+
+	ctx, span := startSpan(ctx, "TokenRevocationService.sessionsRevokedBefore")
+	defer func() { finishSpan(span, err) }()
+
+	var revokedBefore time.Time
+	r, err := s.repo.GetSessionRevocation(ctx, visitorUUID)
+	switch {
+	case err == nil:
+		revokedBefore = r.RevokedBefore
+	case !errors.Is(err, repositories.ErrNotFound):
+		return time.Time{}, fmt.Errorf("%w: check sessions revocation: %s", ErrUnknown, err.Error())
+	}
+	s.sessions.set(visitorUUID, revokedBefore, now.Add(s.opts.CacheTTL), now)
+	return revokedBefore, nil
+}
+
+// ttlCache кеш значений с временем жизни записей, ограниченный по количеству записей.
+// Когда кеш заполнен, из него удаляются истекшие записи, а если таких нет - все записи.
+type ttlCache[V any] struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]ttlCacheEntry[V]
+}
+
+// ttlCacheEntry запись ttlCache.
+type ttlCacheEntry[V any] struct {
+	value V
+	until time.Time
+}
+
+// newTTLCache создает кеш не более чем на size записей.
+func newTTLCache[V any](size int) *ttlCache[V] {
+	return &ttlCache[V]{size: size, entries: make(map[string]ttlCacheEntry[V])}
+}
+
+// get возвращает неистекшее к моменту now значение по ключу.
+func (c *ttlCache[V]) get(key string, now time.Time) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || !now.Before(e.until) {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// set сохраняет значение по ключу до момента until.
+func (c *ttlCache[V]) set(key string, value V, until time.Time, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.size {
+		for k, e := range c.entries {
+			if !now.Before(e.until) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= c.size {
+			clear(c.entries)
+		}
+	}
+	c.entries[key] = ttlCacheEntry[V]{value: value, until: until}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/repositories/memstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRevocations создает сервис отзыва токенов с управляемым временем.
+func newTestRevocations(t *testing.T) (*TokenRevocationService, *memstore.TokenRevocationRepo, *time.Time) {
+	t.Helper()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := memstore.NewTokenRevocationRepo(db.NewMemStorage())
+	svc := NewTokenRevocationService(repo, func(o *TokenRevocationServiceOptions) {
+		o.TTL = time.Hour
+		o.CacheTTL = time.Minute
+		o.Now = func() time.Time { return now }
+	})
+	return svc, repo, &now
+}
+
+func TestTokenRevocationService_Revoke(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, now := newTestRevocations(t)
+	issuedAt := now.Add(-time.Minute)
+
+	revoked, err := svc.IsRevoked(ctx, testOwnerUUID, "jti-1", "", issuedAt)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.ErrorIs(t, svc.Revoke(ctx, testOwnerUUID, ""), ErrInvalidArgument)
+	require.NoError(t, svc.Revoke(ctx, testOwnerUUID, "jti-1"))
+	require.NoError(t, svc.Revoke(ctx, testOwnerUUID, "jti-1"))
+
+	// Отзыв, сделанный этим экземпляром, действует сразу, несмотря на закешированный отрицательный результат.
+	revoked, err = svc.IsRevoked(ctx, testOwnerUUID, "jti-1", "", issuedAt)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, err = svc.IsRevoked(ctx, testOwnerUUID, "jti-2", "", issuedAt)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// Другой экземпляр с тем же хранилищем видит отзыв.
+	other := NewTokenRevocationService(repo)
+	revoked, err = other.IsRevoked(ctx, testOwnerUUID, "jti-1", "", issuedAt)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestTokenRevocationService_RevokeSession(t *testing.T) {
+	ctx := context.Background()
+	svc, _, now := newTestRevocations(t)
+	issuedAt := now.Add(-time.Minute)
+
+	require.NoError(t, svc.Revoke(ctx, testOwnerUUID, "sid-1"))
+
+	tests := []struct {
+		name      string
+		jti       string
+		sessionID string
+		want      bool
+	}{
+		{name: "token of revoked session", jti: "jti-1", sessionID: "sid-1", want: true},
+		{name: "refreshed token of revoked session", jti: "jti-2", sessionID: "sid-1", want: true},
+		{name: "other session", jti: "jti-3", sessionID: "sid-2", want: false},
+		{name: "token without session", jti: "jti-4", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revoked, err := svc.IsRevoked(ctx, testOwnerUUID, tt.jti, tt.sessionID, issuedAt)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, revoked)
+		})
+	}
+}
+
+func TestTokenRevocationService_RevokeAll(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, now := newTestRevocations(t)
+	before := now.Add(-time.Minute)
+
+	require.NoError(t, svc.RevokeAll(ctx, testOwnerUUID))
+
+	tests := []struct {
+		name     string
+		visitor  string
+		jti      string
+		issuedAt time.Time
+		want     bool
+	}{
+		{name: "issued before", visitor: testOwnerUUID, jti: "jti-1", issuedAt: before, want: true},
+		{name: "without jti and iat", visitor: testOwnerUUID, want: true},
+		{name: "same second", visitor: testOwnerUUID, jti: "jti-2", issuedAt: *now, want: true},
+		{name: "issued after", visitor: testOwnerUUID, jti: "jti-3", issuedAt: now.Add(time.Second), want: false},
+		{name: "other visitor", visitor: testEditorUUID, jti: "jti-4", issuedAt: before, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revoked, err := svc.IsRevoked(ctx, tt.visitor, tt.jti, "", tt.issuedAt)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, revoked)
+		})
+	}
+
+	// Отзыв, сделанный другим экземпляром, замечается после истечения кеша.
+	other := NewTokenRevocationService(repo, func(o *TokenRevocationServiceOptions) {
+		o.Now = func() time.Time { return *now }
+	})
+	require.NoError(t, other.RevokeAll(ctx, testEditorUUID))
+	revoked, err := svc.IsRevoked(ctx, testEditorUUID, "jti-4", "", before)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+	*now = now.Add(2 * time.Minute)
+	revoked, err = svc.IsRevoked(ctx, testEditorUUID, "jti-4", "", before)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestTokenRevocationService_DeleteExpired(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, now := newTestRevocations(t)
+
+	require.NoError(t, svc.Revoke(ctx, testOwnerUUID, "jti-1"))
+	require.NoError(t, svc.RevokeAll(ctx, testOwnerUUID))
+
+	deleted, err := repo.DeleteExpired(ctx, now.Add(time.Hour-time.Second))
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+	deleted, err = repo.DeleteExpired(ctx, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, deleted)
+
+	revoked, err := repo.IsTokenRevoked(ctx, "jti-1")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName имя трейсера сервисного слоя.
+const tracerName = "github.com/fsdevblog/shorturl/internal/services"
+
+// startSpan открывает дочерний спан сервисного слоя.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...)) //nolint:spancheck
+}
+
+// finishSpan завершает спан, отмечая ошибку. Как и в слое репозитория,
+// отсутствие записи (ErrRecordNotFound) является штатным результатом и не считается ошибкой.
+func finishSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, ErrRecordNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/repositories/memstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestServices_SpanStatus(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer func() {
+		otel.SetTracerProvider(prev)
+		_ = tp.Shutdown(context.Background())
+	}()
+
+	ctx := context.Background()
+	store := db.NewMemStorage()
+	urls := NewURLService(memstore.NewURLRepo(store))
+	users := NewUserService(memstore.NewUserRepo(store), urls)
+
+	tests := []struct {
+		name       string
+		call       func() error
+		wantStatus codes.Code
+	}{
+		{
+			name: "success",
+			call: func() error {
+				_, _, err := urls.Create(ctx, uuid.NewString(), "https://example.com")
+				return err
+			},
+			wantStatus: codes.Unset,
+		},
+		{
+			name: "invalid argument is recorded",
+			call: func() error {
+				_, err := users.Register(ctx, uuid.NewString(), "not an email", "correct horse")
+				return err
+			},
+			wantStatus: codes.Error,
+		},
+		{
+			name: "not found is not an error",
+			call: func() error {
+				_, err := urls.GetByShortIdentifier(ctx, "missing")
+				return err
+			},
+			wantStatus: codes.Unset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+			callErr := tt.call()
+
+			var serviceSpan *tracetest.SpanStub
+			for _, span := range exporter.GetSpans() {
+				if span.InstrumentationScope.Name == tracerName {
+					serviceSpan = &span
+				}
+			}
+			require.NotNil(t, serviceSpan)
+			assert.Equal(t, tt.wantStatus, serviceSpan.Status.Code)
+			if tt.wantStatus == codes.Error {
+				require.Error(t, callErr)
+				assert.Equal(t, callErr.Error(), serviceSpan.Status.Description)
+				assert.NotEmpty(t, serviceSpan.Events, "ошибка записана событием спана")
+			}
+		})
+	}
+}
@@ -9,25 +9,58 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"time"
 
+	"github.com/fsdevblog/shorturl/internal/events"
 	"github.com/fsdevblog/shorturl/internal/models"
 	"github.com/fsdevblog/shorturl/internal/repositories"
 )
 
 // URLService Сервис работает с базой данных в контексте таблицы `urls`.
 type URLService struct {
-	urlRepo URLRepository
+	urlRepo   URLRepository
+	publisher EventPublisher
+	metrics   URLMetrics
+}
+
+// URLServiceOptions опции сервиса URL.
+type URLServiceOptions struct {
+	Publisher EventPublisher // Получатель событий о ссылках (может быть nil)
+	Metrics   URLMetrics     // Сборщик метрик (может быть nil)
+}
+
+// WithEventPublisher задает получателя событий о создании, удалении и посещении ссылок.
+func WithEventPublisher(p EventPublisher) func(*URLServiceOptions) {
+	return func(o *URLServiceOptions) {
+		o.Publisher = p
+	}
+}
+
+// WithURLMetrics задает сборщик метрик переходов, конфликтов и размеров пакетов.
+func WithURLMetrics(m URLMetrics) func(*URLServiceOptions) {
+	return func(o *URLServiceOptions) {
+		o.Metrics = m
+	}
 }
 
 // NewURLService создает новый экземпляр сервиса URL.
 //
 // Параметры:
 //   - urlRepo: репозиторий для работы с URL
+//   - opts: функции для настройки опций
 //
 // Возвращает:
 //   - *URLService: инициализированный сервис
-func NewURLService(urlRepo URLRepository) *URLService {
-	return &URLService{urlRepo: urlRepo}
+func NewURLService(urlRepo URLRepository, opts ...func(*URLServiceOptions)) *URLService {
+	options := URLServiceOptions{Metrics: noopURLMetrics{}}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.Metrics == nil {
+		options.Metrics = noopURLMetrics{}
+	}
+	return &URLService{urlRepo: urlRepo, publisher: options.Publisher, metrics: options.Metrics}
 }
 
 // GetAllByVisitorUUID получает все URL для указанного посетителя.
@@ -39,7 +72,10 @@ func NewURLService(urlRepo URLRepository) *URLService {
 // Возвращает:
 //   - []models.URL: список URL
 //   - error: ошибка получения данных
-func (u *URLService) GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.URL, error) {
+func (u *URLService) GetAllByVisitorUUID(ctx context.Context, visitorUUID string) (_ []models.URL, err error) {
+	ctx, span := startSpan(ctx, "URLService.GetAllByVisitorUUID")
+	defer func() { finishSpan(span, err) }()
+
 	urls, err := u.urlRepo.GetAllByVisitorUUID(ctx, visitorUUID)
 	if err != nil {
 		return nil, fmt.Errorf("get by visitor uuid: %w", err)
@@ -47,6 +83,25 @@ func (u *URLService) GetAllByVisitorUUID(ctx context.Context, visitorUUID string
 	return urls, nil
 }
 
+// Stats возвращает агрегированную статистику сервиса.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//
+// Возвращает:
+//   - *models.Stats: количество неудаленных URL и уникальных посетителей
+//   - error: ErrUnknown при ошибке подсчета
+func (u *URLService) Stats(ctx context.Context) (_ *models.Stats, err error) {
+	ctx, span := startSpan(ctx, "URLService.Stats")
+	defer func() { finishSpan(span, err) }()
+
+	stats, err := u.urlRepo.Stats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: get stats: %s", ErrUnknown, err.Error())
+	}
+	return stats, nil
+}
+
 // GetByShortIdentifier получает URL по короткому идентификатору.
 //
 // Параметры:
@@ -56,7 +111,10 @@ func (u *URLService) GetAllByVisitorUUID(ctx context.Context, visitorUUID string
 // Возвращает:
 //   - *models.URL: найденный URL
 //   - error: ErrRecordNotFound если не найден, ErrUnknown при других ошибках
-func (u *URLService) GetByShortIdentifier(ctx context.Context, shortID string) (*models.URL, error) {
+func (u *URLService) GetByShortIdentifier(ctx context.Context, shortID string) (_ *models.URL, err error) {
+	ctx, span := startSpan(ctx, "URLService.GetByShortIdentifier")
+	defer func() { finishSpan(span, err) }()
+
 	sURL, err := u.urlRepo.GetByShortIdentifier(ctx, shortID)
 	if err != nil {
 		if errors.Is(err, repositories.ErrNotFound) {
@@ -67,6 +125,37 @@ func (u *URLService) GetByShortIdentifier(ctx context.Context, shortID string) (
 	return sURL, nil
 }
 
+// Visit получает URL по короткому идентификатору для перехода по нему.
+// В отличие от GetByShortIdentifier, публикует событие events.TypeURLClicked,
+// если ссылка не удалена и не отключена, и учитывает переход в метриках.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - shortID: короткий идентификатор URL
+//
+// Возвращает:
+//   - *models.URL: найденный URL
+//   - error: ErrRecordNotFound если не найден, ErrUnknown при других ошибках
+func (u *URLService) Visit(ctx context.Context, shortID string) (_ *models.URL, err error) {
+	ctx, span := startSpan(ctx, "URLService.Visit")
+	defer func() { finishSpan(span, err) }()
+
+	sURL, err := u.GetByShortIdentifier(ctx, shortID)
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			u.metrics.RedirectMiss()
+		}
+		return nil, err
+	}
+	if sURL.State() != models.URLStateActive {
+		u.metrics.RedirectMiss()
+		return sURL, nil
+	}
+	u.metrics.RedirectHit()
+	u.publish(events.TypeURLClicked, sURL)
+	return sURL, nil
+}
+
 // BatchCreate создает несколько URL одновременно.
 //
 // Параметры:
@@ -81,7 +170,12 @@ func (u *URLService) BatchCreate(
 	ctx context.Context,
 	visitorUUID string,
 	rawURLs []string,
-) (*BatchCreateShortURLsResponse, error) {
+) (_ *BatchCreateShortURLsResponse, err error) {
+	ctx, span := startSpan(ctx, "URLService.BatchCreate")
+	defer func() { finishSpan(span, err) }()
+
+	u.metrics.ObserveBatchSize(len(rawURLs))
+
 	var args = make([]repositories.BatchCreateArg, len(rawURLs))
 	for i, rawURL := range rawURLs {
 		arg := repositories.BatchCreateArg{
@@ -103,23 +197,32 @@ func (u *URLService) BatchCreate(
 		var err = result.Err
 		if result.Err != nil && errors.Is(result.Err, repositories.ErrDuplicateKey) {
 			err = ErrDuplicateKey
+			u.metrics.CreateConflict()
 		}
 		batchResponse.results[i].Err = err
+		if result.Err == nil {
+			u.publish(events.TypeURLCreated, &result.Value)
+		}
 	}
 	return NewBatchExecResponseURL(batchResponse), nil
 }
 
-// GetByURL получает URL по оригинальному адресу.
+// GetByURL получает URL посетителя по оригинальному адресу.
+// Ссылки других посетителей не возвращаются.
 //
 // Параметры:
 //   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
 //   - rawURL: оригинальный URL
 //
 // Возвращает:
-//   - *models.URL: найденный URL
+//   - *models.URL: найденный URL, в том числе удаленный
 //   - error: ErrRecordNotFound если не найден, ErrUnknown при других ошибках
-func (u *URLService) GetByURL(ctx context.Context, rawURL string) (*models.URL, error) {
-	res, err := u.urlRepo.GetByURL(ctx, rawURL)
+func (u *URLService) GetByURL(ctx context.Context, visitorUUID string, rawURL string) (_ *models.URL, err error) {
+	ctx, span := startSpan(ctx, "URLService.GetByURL")
+	defer func() { finishSpan(span, err) }()
+
+	res, err := u.urlRepo.GetByURLVisitorUUID(ctx, rawURL, visitorUUID)
 
 	if err != nil {
 		if errors.Is(err, repositories.ErrNotFound) {
@@ -130,6 +233,51 @@ func (u *URLService) GetByURL(ctx context.Context, rawURL string) (*models.URL,
 	return res, nil
 }
 
+// ResolveResult результат разрешения короткого идентификатора.
+type ResolveResult struct {
+	ShortID string
+	State   models.URLState
+	URL     *models.URL // nil для models.URLStateUnknown
+}
+
+// Resolve разрешает несколько коротких идентификаторов одним запросом к хранилищу.
+// Переходы при этом не учитываются.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - shortIDs: короткие идентификаторы
+//
+// Возвращает:
+//   - []ResolveResult: результаты в порядке shortIDs, повторяющиеся идентификаторы повторяются
+//   - error: ErrUnknown при ошибке
+func (u *URLService) Resolve(ctx context.Context, shortIDs []string) (_ []ResolveResult, err error) {
+	ctx, span := startSpan(ctx, "URLService.Resolve")
+	defer func() { finishSpan(span, err) }()
+
+	results := make([]ResolveResult, len(shortIDs))
+	if len(shortIDs) == 0 {
+		return results, nil
+	}
+
+	found, err := u.urlRepo.GetByShortIdentifiers(ctx, slices.Compact(slices.Sorted(slices.Values(shortIDs))))
+	if err != nil {
+		return nil, fmt.Errorf("%w: resolve: %s", ErrUnknown, err.Error())
+	}
+	byShortID := make(map[string]*models.URL, len(found))
+	for i := range found {
+		byShortID[found[i].ShortIdentifier] = &found[i]
+	}
+
+	for i, shortID := range shortIDs {
+		results[i] = ResolveResult{ShortID: shortID, State: models.URLStateUnknown}
+		if m, ok := byShortID[shortID]; ok {
+			results[i].State = m.State()
+			results[i].URL = m
+		}
+	}
+	return results, nil
+}
+
 // Create создает новый URL.
 //
 // Параметры:
@@ -141,7 +289,10 @@ func (u *URLService) GetByURL(ctx context.Context, rawURL string) (*models.URL,
 //   - *models.URL: созданный URL
 //   - bool: true если создан новый, false если обновлен существующий
 //   - error: ErrUnknown при ошибке
-func (u *URLService) Create(ctx context.Context, visitorUUID string, rawURL string) (*models.URL, bool, error) {
+func (u *URLService) Create(ctx context.Context, visitorUUID string, rawURL string) (_ *models.URL, _ bool, err error) {
+	ctx, span := startSpan(ctx, "URLService.Create")
+	defer func() { finishSpan(span, err) }()
+
 	var sURL = models.URL{
 		URL:             rawURL,
 		ShortIdentifier: generateShortID(rawURL, models.ShortIdentifierLength, visitorUUID),
@@ -151,6 +302,11 @@ func (u *URLService) Create(ctx context.Context, visitorUUID string, rawURL stri
 	if createErr != nil {
 		return nil, false, fmt.Errorf("%w: create: %s", ErrUnknown, createErr.Error())
 	}
+	if isUniq {
+		u.publish(events.TypeURLCreated, m)
+	} else {
+		u.metrics.CreateConflict()
+	}
 	return m, isUniq, nil
 }
 
@@ -242,20 +398,126 @@ func (u *URLService) RestoreBackup(ctx context.Context, path string) (err error)
 	return nil
 }
 
+// DeleteResult результат удаления ссылок посетителя.
+type DeleteResult struct {
+	Deleted  []string // Идентификаторы, помеченные удаленными
+	NotFound []string // Идентификаторы, которых нет, которые принадлежат другому посетителю или уже удалены
+}
+
 // MarkAsDeleted помечает URL как удаленные.
+// События events.TypeURLDeleted публикуются только для фактически удаленных ссылок.
 //
 // Параметры:
 //   - ctx: контекст выполнения
-//   - shortIDs: список коротких идентификаторов
+//   - shortIDs: список коротких идентификаторов (повторы учитываются один раз)
 //   - visitorUUID: идентификатор посетителя
 //
 // Возвращает:
+//   - *DeleteResult: удаленные и не найденные идентификаторы в порядке запроса
 //   - error: ошибка удаления
-func (u *URLService) MarkAsDeleted(ctx context.Context, shortIDs []string, visitorUUID string) error {
-	if err := u.urlRepo.DeleteByShortIDsVisitorUUID(ctx, visitorUUID, shortIDs); err != nil {
-		return fmt.Errorf("delete by short ids %+v, visitor uuid: %s: %w", shortIDs, visitorUUID, err)
+func (u *URLService) MarkAsDeleted(
+	ctx context.Context,
+	shortIDs []string,
+	visitorUUID string,
+) (_ *DeleteResult, err error) {
+	ctx, span := startSpan(ctx, "URLService.MarkAsDeleted")
+	defer func() { finishSpan(span, err) }()
+
+	ids := make([]string, 0, len(shortIDs))
+	seen := make(map[string]struct{}, len(shortIDs))
+	for _, shortID := range shortIDs {
+		if _, ok := seen[shortID]; !ok {
+			seen[shortID] = struct{}{}
+			ids = append(ids, shortID)
+		}
 	}
-	return nil
+
+	deleted, err := u.urlRepo.DeleteByShortIDsVisitorUUID(ctx, visitorUUID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("delete by short ids %+v, visitor uuid: %s: %w", ids, visitorUUID, err)
+	}
+
+	deletedSet := make(map[string]struct{}, len(deleted))
+	for _, shortID := range deleted {
+		deletedSet[shortID] = struct{}{}
+	}
+	res := &DeleteResult{Deleted: make([]string, 0, len(deleted)), NotFound: []string{}}
+	for _, shortID := range ids {
+		if _, ok := deletedSet[shortID]; !ok {
+			res.NotFound = append(res.NotFound, shortID)
+			continue
+		}
+		res.Deleted = append(res.Deleted, shortID)
+		u.publish(events.TypeURLDeleted, &models.URL{ShortIdentifier: shortID, VisitorUUID: visitorUUID})
+	}
+	return res, nil
+}
+
+// BatchMarkAsDeleted помечает удаленными URL нескольких посетителей за один вызов репозитория.
+// События events.TypeURLDeleted публикуются только для фактически удаленных записей.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - args: идентификаторы URL, сгруппированные по посетителям
+//
+// Возвращает:
+//   - []models.URL: записи, помеченные удаленными
+//   - error: ошибка удаления
+func (u *URLService) BatchMarkAsDeleted(
+	ctx context.Context,
+	args []repositories.BatchDeleteArg,
+) (_ []models.URL, err error) {
+	ctx, span := startSpan(ctx, "URLService.BatchMarkAsDeleted")
+	defer func() { finishSpan(span, err) }()
+
+	deleted, err := u.urlRepo.BatchDelete(ctx, args)
+	if err != nil {
+		return nil, fmt.Errorf("%w: batch delete: %s", ErrUnknown, err.Error())
+	}
+	for i := range deleted {
+		u.publish(events.TypeURLDeleted, &deleted[i])
+	}
+	return deleted, nil
+}
+
+// MergeVisitor передает ссылки посетителя fromUUID посетителю toUUID.
+// Ссылки на URL, который у toUUID уже есть, остаются у fromUUID.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - fromUUID: идентификатор анонимного посетителя
+//   - toUUID: идентификатор аккаунта
+//
+// Возвращает:
+//   - int: количество переданных ссылок
+//   - error: ErrUnknown при ошибке
+func (u *URLService) MergeVisitor(ctx context.Context, fromUUID string, toUUID string) (_ int, err error) {
+	ctx, span := startSpan(ctx, "URLService.MergeVisitor")
+	defer func() { finishSpan(span, err) }()
+
+	n, err := u.urlRepo.ReassignVisitor(ctx, fromUUID, toUUID)
+	if err != nil {
+		return 0, fmt.Errorf("%w: reassign visitor: %s", ErrUnknown, err.Error())
+	}
+	return n, nil
+}
+
+// publish отправляет событие о ссылке, если задан получатель событий.
+//
+// Параметры:
+//   - t: тип события
+//   - m: ссылка, к которой относится событие
+func (u *URLService) publish(t events.Type, m *models.URL) {
+	if u.publisher == nil || m == nil {
+		return
+	}
+	u.publisher.Publish(events.Event{
+		Type:            t,
+		VisitorUUID:     m.VisitorUUID,
+		ShortIdentifier: m.ShortIdentifier,
+		URL:             m.URL,
+		OccurredAt:      time.Now().UTC(),
+	})
 }
 
 // generateShortID генерирует короткий идентификатор для URL.
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/fsdevblog/shorturl/internal/events"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+)
+
+// Ограничения меток импортируемых URL.
+const (
+	MaxURLTags   = 20 // Максимальное количество меток одного URL
+	MaxTagLength = 64 // Максимальная длина метки
+)
+
+// aliasPattern допустимые символы пользовательского короткого идентификатора.
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// reservedAliases идентификаторы, совпадающие со статическими сегментами маршрутов на уровнях,
+// где маршрутизатор принимает короткий идентификатор: /, /api/ и /api/v2/.
+// Сегменты, не проходящие aliasPattern (например, .well-known), сюда не входят.
+//
+//nolint:gochecknoglobals
+var reservedAliases = []string{
+	"api", "v2", "debug", "metrics", "ping", "info",
+	"shorten", "resolve", "lookup", "register", "login", "logout", "oidc",
+	"user", "jobs", "workspaces", "admin", "internal",
+}
+
+// ImportRow строка импорта ссылок.
+type ImportRow struct {
+	URL   string   // Оригинальный URL
+	Alias string   // Пользовательский короткий идентификатор (если пуст, генерируется)
+	Tags  []string // Метки URL
+}
+
+// ValidateAlias проверяет пользовательский короткий идентификатор.
+//
+// Параметры:
+//   - alias: короткий идентификатор
+//
+// Возвращает:
+//   - error: ErrInvalidArgument, если идентификатор недопустим
+func ValidateAlias(alias string) error {
+	switch {
+	case len(alias) > models.MaxAliasLength:
+		return fmt.Errorf("%w: alias is longer than %d characters", ErrInvalidArgument, models.MaxAliasLength)
+	case !aliasPattern.MatchString(alias):
+		return fmt.Errorf("%w: alias may contain only letters, digits, '-' and '_'", ErrInvalidArgument)
+	case slices.Contains(reservedAliases, strings.ToLower(alias)):
+		return fmt.Errorf("%w: alias %q is reserved", ErrInvalidArgument, alias)
+	}
+	return nil
+}
+
+// IsShortIdentifier проверяет, может ли строка быть коротким идентификатором ссылки:
+// сгенерированным (models.ShortIdentifierLength символов) или пользовательским (см. ValidateAlias).
+// Используется, чтобы не обращаться к хранилищу за заведомо несуществующими ссылками.
+//
+// Параметры:
+//   - id: короткий идентификатор
+//
+// Возвращает:
+//   - bool: true, если идентификатор допустим
+func IsShortIdentifier(id string) bool {
+	return len(id) <= models.MaxAliasLength && aliasPattern.MatchString(id)
+}
+
+// NormalizeTags удаляет пустые и повторяющиеся метки и проверяет ограничения.
+//
+// Параметры:
+//   - tags: метки
+//
+// Возвращает:
+//   - []string: метки без пробелов по краям, в исходном порядке
+//   - error: ErrInvalidArgument при нарушении ограничений
+func NormalizeTags(tags []string) ([]string, error) {
+	var result []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || slices.Contains(result, tag) {
+			continue
+		}
+		if len(tag) > MaxTagLength {
+			return nil, fmt.Errorf("%w: tag is longer than %d characters", ErrInvalidArgument, MaxTagLength)
+		}
+		result = append(result, tag)
+	}
+	if len(result) > MaxURLTags {
+		return nil, fmt.Errorf("%w: more than %d tags", ErrInvalidArgument, MaxURLTags)
+	}
+	return result, nil
+}
+
+// Import импортирует ссылки посетителя с необязательными алиасами и метками.
+// Подряд идущие строки без алиаса сохраняются одним пакетом, строки с алиасом - по одной,
+// т.к. занятый алиас не должен прерывать сохранение остальных строк.
+// Результаты возвращаются в порядке строк. Ошибки строк:
+//   - ErrDuplicateKey: URL уже сокращен посетителем, возвращается существующая запись
+//   - ErrAliasTaken: алиас занят другой ссылкой
+//   - ErrInvalidArgument: недопустимый алиас или метки
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//   - rows: импортируемые строки
+//
+// Возвращает:
+//   - *BatchCreateShortURLsResponse: результаты по строкам
+//   - error: ErrUnknown при ошибке пакетного сохранения
+func (u *URLService) Import(
+	ctx context.Context,
+	visitorUUID string,
+	rows []ImportRow,
+) (_ *BatchCreateShortURLsResponse, err error) {
+	ctx, span := startSpan(ctx, "URLService.Import")
+	defer func() { finishSpan(span, err) }()
+
+	response := NewBatchExecResponse[models.URL](len(rows))
+	var args []repositories.BatchCreateArg
+	var argRows []int
+
+	for i, row := range rows {
+		tags, tagsErr := NormalizeTags(row.Tags)
+		if tagsErr != nil {
+			response.Set(BatchResponseItem[models.URL]{Err: tagsErr}, i)
+			continue
+		}
+		if row.Alias == "" {
+			args = append(args, repositories.BatchCreateArg{
+				ShortIdentifier: generateShortID(row.URL, models.ShortIdentifierLength, visitorUUID),
+				URL:             row.URL,
+				VisitorUUID:     visitorUUID,
+				Tags:            tags,
+			})
+			argRows = append(argRows, i)
+			continue
+		}
+		// Сохраняем накопленный пакет, чтобы повторы URL определялись в порядке строк.
+		if err := u.importBatch(ctx, response, args, argRows); err != nil {
+			return nil, err
+		}
+		args, argRows = args[:0], argRows[:0]
+
+		m, err := u.importWithAlias(ctx, visitorUUID, row.URL, row.Alias, tags)
+		response.Set(BatchResponseItem[models.URL]{Item: m, Err: err}, i)
+	}
+
+	if err := u.importBatch(ctx, response, args, argRows); err != nil {
+		return nil, err
+	}
+	return NewBatchExecResponseURL(response), nil
+}
+
+// importBatch сохраняет строки импорта без алиаса одним пакетом.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - response: результаты импорта
+//   - args: аргументы пакетного создания
+//   - argRows: индексы строк импорта для каждого аргумента
+//
+// Возвращает:
+//   - error: ErrUnknown при ошибке пакетного сохранения
+func (u *URLService) importBatch(
+	ctx context.Context,
+	response *BatchExecResponse[models.URL],
+	args []repositories.BatchCreateArg,
+	argRows []int,
+) error {
+	if len(args) == 0 {
+		return nil
+	}
+	batchResults, batchErr := u.urlRepo.BatchCreate(ctx, args)
+	if batchErr != nil {
+		return fmt.Errorf("%w: import: %s", ErrUnknown, batchErr.Error())
+	}
+	for j, result := range batchResults.Results {
+		err := result.Err
+		switch {
+		case err == nil:
+			u.publish(events.TypeURLCreated, &result.Value)
+		case errors.Is(err, repositories.ErrDuplicateKey):
+			err = ErrDuplicateKey
+			u.metrics.CreateConflict()
+		}
+		response.Set(BatchResponseItem[models.URL]{Item: result.Value, Err: err}, argRows[j])
+	}
+	return nil
+}
+
+// importWithAlias сохраняет ссылку с пользовательским коротким идентификатором.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//   - rawURL: оригинальный URL
+//   - alias: короткий идентификатор
+//   - tags: метки
+//
+// Возвращает:
+//   - models.URL: созданная или существующая запись посетителя
+//   - error: ErrDuplicateKey, ErrAliasTaken, ErrInvalidArgument или ErrUnknown
+func (u *URLService) importWithAlias(
+	ctx context.Context,
+	visitorUUID string,
+	rawURL string,
+	alias string,
+	tags []string,
+) (models.URL, error) {
+	if err := ValidateAlias(alias); err != nil {
+		return models.URL{}, err
+	}
+
+	existing, err := u.urlRepo.GetByURLVisitorUUID(ctx, rawURL, visitorUUID)
+	if err == nil {
+		u.metrics.CreateConflict()
+		return *existing, ErrDuplicateKey
+	}
+	if !errors.Is(err, repositories.ErrNotFound) {
+		return models.URL{}, fmt.Errorf("%w: get by url: %s", ErrUnknown, err.Error())
+	}
+
+	if _, err = u.urlRepo.GetByShortIdentifier(ctx, alias); err == nil {
+		return models.URL{}, ErrAliasTaken
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		return models.URL{}, fmt.Errorf("%w: get by alias: %s", ErrUnknown, err.Error())
+	}
+
+	m, isUniq, err := u.urlRepo.Create(ctx, &models.URL{
+		URL:             rawURL,
+		ShortIdentifier: alias,
+		VisitorUUID:     visitorUUID,
+		Tags:            tags,
+	})
+	if err != nil {
+		// Алиас заняли между проверкой и вставкой.
+		if errors.Is(err, repositories.ErrDuplicateKey) {
+			return models.URL{}, ErrAliasTaken
+		}
+		return models.URL{}, fmt.Errorf("%w: create: %s", ErrUnknown, err.Error())
+	}
+	if !isUniq {
+		if m.URL != rawURL || m.VisitorUUID != visitorUUID {
+			return models.URL{}, ErrAliasTaken
+		}
+		u.metrics.CreateConflict()
+		return *m, ErrDuplicateKey
+	}
+	u.publish(events.TypeURLCreated, m)
+	return *m, nil
+}
+
+// ExportByVisitorUUID вызывает fn для каждой ссылки посетителя, включая удаленные,
+// не загружая их в память целиком.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//   - fn: функция, вызываемая для каждой ссылки; ошибка прекращает обход
+//
+// Возвращает:
+//   - error: ошибка fn или ошибка чтения из хранилища
+func (u *URLService) ExportByVisitorUUID(
+	ctx context.Context,
+	visitorUUID string,
+	fn func(models.URL) error,
+) (err error) {
+	ctx, span := startSpan(ctx, "URLService.ExportByVisitorUUID")
+	defer func() { finishSpan(span, err) }()
+
+	if err := u.urlRepo.EachByVisitorUUID(ctx, visitorUUID, fn); err != nil {
+		return fmt.Errorf("export by visitor uuid: %w", err)
+	}
+	return nil
+}
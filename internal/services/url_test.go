@@ -3,12 +3,19 @@ package services
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/events"
 
 	"github.com/fsdevblog/shorturl/internal/models"
 	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/fsdevblog/shorturl/internal/repositories/memstore"
 	"github.com/fsdevblog/shorturl/internal/services/mocks"
 
 	"github.com/golang/mock/gomock"
@@ -60,3 +67,190 @@ func BenchmarkURLService_BatchCreate_Different_Sizes(b *testing.B) {
 		})
 	}
 }
+
+// fakeURLMetrics сборщик метрик, запоминающий вызовы.
+type fakeURLMetrics struct {
+	hits, misses, conflicts int
+	batchSizes              []int
+}
+
+func (f *fakeURLMetrics) RedirectHit()           { f.hits++ }
+func (f *fakeURLMetrics) RedirectMiss()          { f.misses++ }
+func (f *fakeURLMetrics) CreateConflict()        { f.conflicts++ }
+func (f *fakeURLMetrics) ObserveBatchSize(n int) { f.batchSizes = append(f.batchSizes, n) }
+
+func TestURLService_Metrics(t *testing.T) {
+	m := &fakeURLMetrics{}
+	service := NewURLService(memstore.NewURLRepo(db.NewMemStorage()), WithURLMetrics(m))
+	ctx := t.Context()
+	visitorUUID := "test-visitor-uuid"
+
+	created, isNew, err := service.Create(ctx, visitorUUID, "https://example.com")
+	require.NoError(t, err)
+	require.True(t, isNew)
+
+	_, isNew, err = service.Create(ctx, visitorUUID, "https://example.com")
+	require.NoError(t, err)
+	require.False(t, isNew)
+
+	_, err = service.BatchCreate(ctx, visitorUUID, []string{"https://example.com", "https://example.org"})
+	require.NoError(t, err)
+
+	_, err = service.Visit(ctx, created.ShortIdentifier)
+	require.NoError(t, err)
+	_, err = service.Visit(ctx, "notexist")
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	assert.Equal(t, 1, m.hits)
+	assert.Equal(t, 1, m.misses)
+	assert.Equal(t, 2, m.conflicts)
+	assert.Equal(t, []int{2}, m.batchSizes)
+}
+
+func TestURLService_Import(t *testing.T) {
+	service := NewURLService(memstore.NewURLRepo(db.NewMemStorage()))
+	ctx := t.Context()
+	visitorUUID := "test-visitor-uuid"
+
+	_, err := service.Import(ctx, "other-visitor", []ImportRow{{URL: "https://other.com", Alias: "taken"}})
+	require.NoError(t, err)
+
+	tooManyTags := make([]string, MaxURLTags+1)
+	for i := range tooManyTags {
+		tooManyTags[i] = fmt.Sprintf("tag%d", i)
+	}
+
+	res, err := service.Import(ctx, visitorUUID, []ImportRow{
+		{URL: "https://example.com", Tags: []string{" a ", "b", "a", ""}},
+		{URL: "https://example.org", Alias: "my-link"},
+		{URL: "https://example.com", Alias: "again"},
+		{URL: "https://example.net", Alias: "taken"},
+		{URL: "https://example.net", Alias: "api"},
+		{URL: "https://example.net", Tags: tooManyTags},
+		{URL: "https://example.com"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 7, res.Len())
+
+	var items []models.URL
+	var errs []error
+	res.ReadResponse(func(_ int, m models.URL, err error) {
+		items = append(items, m)
+		errs = append(errs, err)
+	})
+
+	require.NoError(t, errs[0])
+	assert.Equal(t, []string{"a", "b"}, items[0].Tags)
+	require.NoError(t, errs[1])
+	assert.Equal(t, "my-link", items[1].ShortIdentifier)
+	require.ErrorIs(t, errs[2], ErrDuplicateKey)
+	assert.Equal(t, items[0].ShortIdentifier, items[2].ShortIdentifier, "дубликат возвращает существующую ссылку")
+	require.ErrorIs(t, errs[3], ErrAliasTaken)
+	require.ErrorIs(t, errs[4], ErrInvalidArgument)
+	require.ErrorIs(t, errs[5], ErrInvalidArgument)
+	require.ErrorIs(t, errs[6], ErrDuplicateKey)
+
+	var exported []string
+	err = service.ExportByVisitorUUID(ctx, visitorUUID, func(m models.URL) error {
+		exported = append(exported, m.URL)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"https://example.com", "https://example.org"}, exported)
+}
+
+func TestURLService_MarkAsDeleted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := memstore.NewURLRepo(db.NewMemStorage())
+	publisher := mocks.NewMockEventPublisher(ctrl)
+
+	for _, m := range []models.URL{
+		{ShortIdentifier: "own1", URL: "https://a.com/1", VisitorUUID: "visitor-1"},
+		{ShortIdentifier: "own2", URL: "https://a.com/2", VisitorUUID: "visitor-1"},
+		{ShortIdentifier: "foreign", URL: "https://a.com/3", VisitorUUID: "visitor-2"},
+	} {
+		_, _, err := repo.Create(t.Context(), &m)
+		require.NoError(t, err)
+	}
+	service := NewURLService(repo, WithEventPublisher(publisher))
+
+	// События публикуются только для фактически удаленных ссылок.
+	var published []string
+	publisher.EXPECT().Publish(gomock.Any()).Do(func(e events.Event) {
+		assert.Equal(t, events.TypeURLDeleted, e.Type)
+		published = append(published, e.ShortIdentifier)
+	}).Times(2)
+
+	res, err := service.MarkAsDeleted(t.Context(), []string{"own1", "missing", "foreign", "own2", "own1"}, "visitor-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"own1", "own2"}, res.Deleted)
+	assert.Equal(t, []string{"missing", "foreign"}, res.NotFound)
+	assert.Equal(t, []string{"own1", "own2"}, published)
+
+	// Повторное удаление ничего не удаляет.
+	res, err = service.MarkAsDeleted(t.Context(), []string{"own1"}, "visitor-1")
+	require.NoError(t, err)
+	assert.Empty(t, res.Deleted)
+	assert.Equal(t, []string{"own1"}, res.NotFound)
+}
+
+func TestURLService_Resolve(t *testing.T) {
+	repo := memstore.NewURLRepo(db.NewMemStorage())
+	for _, m := range []models.URL{
+		{ShortIdentifier: "active", URL: "https://a.com/1", VisitorUUID: "visitor-1"},
+		{ShortIdentifier: "deleted", URL: "https://a.com/2", VisitorUUID: "visitor-1"},
+	} {
+		_, _, err := repo.Create(t.Context(), &m)
+		require.NoError(t, err)
+	}
+	service := NewURLService(repo)
+	_, err := service.MarkAsDeleted(t.Context(), []string{"deleted"}, "visitor-1")
+	require.NoError(t, err)
+
+	res, err := service.Resolve(t.Context(), []string{"deleted", "missing", "active", "active"})
+	require.NoError(t, err)
+	require.Len(t, res, 4)
+
+	var states []models.URLState
+	for i, r := range res {
+		states = append(states, r.State)
+		assert.Equal(t, []string{"deleted", "missing", "active", "active"}[i], r.ShortID)
+	}
+	assert.Equal(t, []models.URLState{
+		models.URLStateDeleted, models.URLStateUnknown, models.URLStateActive, models.URLStateActive,
+	}, states)
+	assert.Nil(t, res[1].URL)
+	assert.Equal(t, "https://a.com/1", res[2].URL.URL)
+}
+
+func TestValidateAlias(t *testing.T) {
+	tests := []struct {
+		alias   string
+		wantErr bool
+	}{
+		{alias: "promo"},
+		{alias: "a"},
+		{alias: "my_link-2"},
+		{alias: strings.Repeat("a", models.MaxAliasLength)},
+		{alias: strings.Repeat("a", models.MaxAliasLength+1), wantErr: true},
+		{alias: "bad alias", wantErr: true},
+		{alias: "", wantErr: true},
+		{alias: "API", wantErr: true},
+		{alias: "info", wantErr: true},
+		{alias: "resolve", wantErr: true},
+		{alias: "lookup", wantErr: true},
+		{alias: ".well-known", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alias, func(t *testing.T) {
+			err := ValidateAlias(tt.alias)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidArgument)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, IsShortIdentifier(tt.alias))
+		})
+	}
+	assert.True(t, IsShortIdentifier(generateShortID("https://example.com", models.ShortIdentifierLength, "")))
+}
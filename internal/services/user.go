@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"sync"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Ограничения учетных данных. Пароль ограничен 72 байтами, так как bcrypt не учитывает байты сверх этого.
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+	MaxEmailLength    = 254
+)
+
+// UserServiceOptions опции сервиса пользователей.
+type UserServiceOptions struct {
+	BcryptCost int // Сложность bcrypt (по умолчанию bcrypt.DefaultCost)
+	// Identities учетные записи провайдера OpenID Connect. Их UUID, как и UUID пользователей
+	// с паролем, считаются аккаунтами: ссылки аккаунта не передаются при входе и его UUID
+	// не занимается при регистрации. nil - учитываются только пользователи с паролем.
+	Identities OIDCIdentityRepository
+}
+
+// LoginResult результат входа в аккаунт.
+type LoginResult struct {
+	User       *models.User
+	MergedURLs int // Количество ссылок анонимного посетителя, переданных аккаунту
+}
+
+// UserService регистрирует пользователей и проверяет их учетные данные.
+// Идентификатор пользователя используется как UUID посетителя, поэтому ссылки аккаунта
+// доступны с любого устройства после входа.
+type UserService struct {
+	repo   UserRepository
+	merger VisitorMerger
+	opts   UserServiceOptions
+
+	dummyOnce sync.Once
+	dummyHash []byte
+}
+
+// NewUserService создает новый экземпляр сервиса пользователей.
+//
+// Параметры:
+//   - repo: репозиторий пользователей
+//   - merger: получатель ссылок анонимного посетителя при входе
+//   - opts: функции для настройки опций
+//
+// Возвращает:
+//   - *UserService: инициализированный сервис
+func NewUserService(repo UserRepository, merger VisitorMerger, opts ...func(*UserServiceOptions)) *UserService {
+	options := UserServiceOptions{BcryptCost: bcrypt.DefaultCost}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &UserService{repo: repo, merger: merger, opts: options}
+}
+
+// Register создает аккаунт. Если текущий посетитель анонимный, аккаунт получает его UUID,
+// и созданные им ссылки сразу принадлежат аккаунту.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: UUID текущего посетителя
+//   - email: адрес электронной почты
+//   - password: пароль
+//
+// Возвращает:
+//   - *models.User: созданный пользователь
+//   - error: ErrInvalidArgument при некорректных данных, ErrEmailTaken, если адрес занят,
+//     ErrUnknown при других ошибках
+func (s *UserService) Register(
+	ctx context.Context,
+	visitorUUID string,
+	email string,
+	password string,
+) (_ *models.User, err error) {
+	ctx, span := startSpan(ctx, "UserService.Register")
+	defer func() { finishSpan(span, err) }()
+
+	email, err = normalizeEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if l := len(password); l < MinPasswordLength || l > MaxPasswordLength {
+		return nil, fmt.Errorf("%w: password must be %d to %d bytes long",
+			ErrInvalidArgument, MinPasswordLength, MaxPasswordLength)
+	}
+
+	hash, hashErr := bcrypt.GenerateFromPassword([]byte(password), s.opts.BcryptCost)
+	if hashErr != nil {
+		return nil, fmt.Errorf("%w: hash password: %s", ErrUnknown, hashErr.Error())
+	}
+
+	id, idErr := s.accountID(ctx, visitorUUID)
+	if idErr != nil {
+		return nil, idErr
+	}
+	user, err := s.repo.Create(ctx, &models.User{ID: id, Email: email, PasswordHash: string(hash)})
+	if err != nil {
+		if errors.Is(err, repositories.ErrDuplicateKey) {
+			return nil, ErrEmailTaken
+		}
+		return nil, fmt.Errorf("%w: create user: %s", ErrUnknown, err.Error())
+	}
+	return user, nil
+}
+
+// Login проверяет учетные данные и передает аккаунту ссылки текущего анонимного посетителя.
+// Ссылки другого аккаунта, под которым посетитель вошел ранее, не передаются.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: UUID текущего посетителя
+//   - email: адрес электронной почты
+//   - password: пароль
+//
+// Возвращает:
+//   - *LoginResult: пользователь и количество переданных ссылок
+//   - error: ErrInvalidCredentials, если адрес или пароль не подошли, ErrUnknown при других ошибках
+func (s *UserService) Login(
+	ctx context.Context,
+	visitorUUID string,
+	email string,
+	password string,
+) (_ *LoginResult, err error) {
+	ctx, span := startSpan(ctx, "UserService.Login")
+	defer func() { finishSpan(span, err) }()
+
+	email, err = normalizeEmail(email)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			return nil, fmt.Errorf("%w: get user: %s", ErrUnknown, err.Error())
+		}
+		// Сравниваем с заглушкой, чтобы время ответа не выдавало наличие аккаунта.
+		_ = bcrypt.CompareHashAndPassword(s.dummy(), []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	result := &LoginResult{User: user}
+	if visitorUUID == "" || visitorUUID == user.ID {
+		return result, nil
+	}
+	account, err := isAccount(ctx, s.repo, s.opts.Identities, visitorUUID)
+	if err != nil {
+		return nil, err
+	}
+	if account {
+		return result, nil
+	}
+	if result.MergedURLs, err = s.merger.MergeVisitor(ctx, visitorUUID, user.ID); err != nil {
+		return nil, fmt.Errorf("merge visitor: %w", err)
+	}
+	return result, nil
+}
+
+// accountID выбирает идентификатор нового аккаунта: UUID анонимного посетителя
+// или новый UUID, если посетитель уже вошел в другой аккаунт (в том числе через провайдера).
+func (s *UserService) accountID(ctx context.Context, visitorUUID string) (string, error) {
+	if visitorUUID == "" {
+		return uuid.NewString(), nil
+	}
+	account, err := isAccount(ctx, s.repo, s.opts.Identities, visitorUUID)
+	if err != nil {
+		return "", err
+	}
+	if account {
+		return uuid.NewString(), nil
+	}
+	return visitorUUID, nil
+}
+
+// isAccount сообщает, что UUID посетителя принадлежит аккаунту: пользователю с паролем
+// или, если identities задан, пользователю провайдера OpenID Connect.
+func isAccount(
+	ctx context.Context,
+	users UserRepository,
+	identities OIDCIdentityRepository,
+	visitorUUID string,
+) (bool, error) {
+	if _, err := users.GetByID(ctx, visitorUUID); err == nil {
+		return true, nil
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		return false, fmt.Errorf("%w: get user: %s", ErrUnknown, err.Error())
+	}
+	if identities == nil {
+		return false, nil
+	}
+	if _, err := identities.GetByUserID(ctx, visitorUUID); err == nil {
+		return true, nil
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		return false, fmt.Errorf("%w: get oidc identity: %s", ErrUnknown, err.Error())
+	}
+	return false, nil
+}
+
+// dummy возвращает хеш заглушки с той же сложностью, что и у настоящих паролей.
+func (s *UserService) dummy() []byte {
+	s.dummyOnce.Do(func() {
+		s.dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), s.opts.BcryptCost)
+	})
+	return s.dummyHash
+}
+
+// normalizeEmail проверяет адрес и приводит его к нижнему регистру.
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if len(email) > MaxEmailLength {
+		return "", fmt.Errorf("%w: email is longer than %d characters", ErrInvalidArgument, MaxEmailLength)
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", fmt.Errorf("%w: invalid email", ErrInvalidArgument)
+	}
+	return email, nil
+}
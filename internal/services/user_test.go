@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/repositories/memstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestUserService() (*UserService, *URLService) {
+	store := db.NewMemStorage()
+	urlService := NewURLService(memstore.NewURLRepo(store))
+	users := NewUserService(memstore.NewUserRepo(store), urlService, func(o *UserServiceOptions) {
+		o.BcryptCost = bcrypt.MinCost
+	})
+	return users, urlService
+}
+
+func TestUserService_Register(t *testing.T) {
+	ctx := context.Background()
+	users, _ := newTestUserService()
+	visitor := uuid.NewString()
+
+	user, err := users.Register(ctx, visitor, " Alice@Example.com ", "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, visitor, user.ID, "анонимный посетитель становится аккаунтом")
+	assert.Equal(t, "alice@example.com", user.Email)
+	assert.NotContains(t, user.PasswordHash, "correct horse")
+
+	_, err = users.Register(ctx, uuid.NewString(), "alice@example.com", "another password")
+	require.ErrorIs(t, err, ErrEmailTaken)
+
+	// Посетитель, уже вошедший в аккаунт, регистрирует второй аккаунт с новым UUID.
+	second, err := users.Register(ctx, visitor, "bob@example.com", "correct horse")
+	require.NoError(t, err)
+	assert.NotEqual(t, visitor, second.ID)
+
+	for _, tt := range []struct{ email, password string }{
+		{"not an email", "correct horse"},
+		{"Carol <carol@example.com>", "correct horse"},
+		{"carol@example.com", "short"},
+		{"carol@example.com", string(make([]byte, MaxPasswordLength+1))},
+	} {
+		_, err = users.Register(ctx, uuid.NewString(), tt.email, tt.password)
+		require.ErrorIs(t, err, ErrInvalidArgument, "%q / %d bytes", tt.email, len(tt.password))
+	}
+}
+
+func TestUserService_Login(t *testing.T) {
+	ctx := context.Background()
+	users, urls := newTestUserService()
+	account := uuid.NewString()
+	_, err := users.Register(ctx, account, "alice@example.com", "correct horse")
+	require.NoError(t, err)
+	_, _, err = urls.Create(ctx, account, "https://example.com/shared")
+	require.NoError(t, err)
+
+	anonymous := uuid.NewString()
+	_, _, err = urls.Create(ctx, anonymous, "https://example.com/new")
+	require.NoError(t, err)
+	_, _, err = urls.Create(ctx, anonymous, "https://example.com/shared")
+	require.NoError(t, err)
+
+	_, err = users.Login(ctx, anonymous, "alice@example.com", "wrong password")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = users.Login(ctx, anonymous, "nobody@example.com", "correct horse")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	res, err := users.Login(ctx, anonymous, "ALICE@example.com", "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, account, res.User.ID)
+	assert.Equal(t, 1, res.MergedURLs, "URL, который у аккаунта уже есть, не передается")
+
+	accountURLs, err := urls.GetAllByVisitorUUID(ctx, account)
+	require.NoError(t, err)
+	assert.Len(t, accountURLs, 2)
+	left, err := urls.GetAllByVisitorUUID(ctx, anonymous)
+	require.NoError(t, err)
+	require.Len(t, left, 1)
+	assert.Equal(t, "https://example.com/shared", left[0].URL)
+
+	// Вход из другого аккаунта не забирает его ссылки.
+	other, err := users.Register(ctx, uuid.NewString(), "bob@example.com", "correct horse")
+	require.NoError(t, err)
+	_, _, err = urls.Create(ctx, other.ID, "https://example.com/bob")
+	require.NoError(t, err)
+	res, err = users.Login(ctx, other.ID, "alice@example.com", "correct horse")
+	require.NoError(t, err)
+	assert.Zero(t, res.MergedURLs)
+	bobURLs, err := urls.GetAllByVisitorUUID(ctx, other.ID)
+	require.NoError(t, err)
+	assert.Len(t, bobURLs, 1)
+}
@@ -0,0 +1,659 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/events"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/fsdevblog/shorturl/internal/urlvalidate"
+	"github.com/google/uuid"
+)
+
+// Заголовки запроса доставки вебхука.
+const (
+	WebhookHeaderID        = "X-Webhook-Id"        // Идентификатор вебхука
+	WebhookHeaderEvent     = "X-Webhook-Event"     // Тип события
+	WebhookHeaderDelivery  = "X-Webhook-Delivery"  // Идентификатор события (одинаков для всех попыток)
+	WebhookHeaderTimestamp = "X-Webhook-Timestamp" // Unix время подписи
+	WebhookHeaderSignature = "X-Webhook-Signature" // Подпись вида sha256=<hex>
+)
+
+// Параметры доставки вебхуков по умолчанию.
+const (
+	defaultWebhookMaxAttempts    = 5
+	defaultWebhookBaseBackoff    = time.Second
+	defaultWebhookMaxBackoff     = time.Minute
+	defaultWebhookWorkers        = 4
+	defaultWebhookQueueSize      = 1024
+	defaultWebhookPollInterval   = time.Second
+	defaultWebhookJobLease       = time.Minute
+	defaultWebhookRequestTimeout = 5 * time.Second
+	// defaultWebhookVisitorsRefreshInterval период обновления кэша посетителей с вебхуками.
+	defaultWebhookVisitorsRefreshInterval = 30 * time.Second
+	// DefaultWebhookDeliveryTTL время хранения истории доставок вебхуков.
+	DefaultWebhookDeliveryTTL     = 30 * 24 * time.Hour
+	defaultWebhookCleanupInterval = 10 * time.Minute
+	webhookSecretBytes            = 32
+	webhookResponseDrainLimit     = 1 << 10 // сколько байт тела ответа дочитываем для переиспользования соединения
+)
+
+// webhookEventTypes типы событий, на которые можно подписать вебхук.
+var webhookEventTypes = map[string]struct{}{ //nolint:gochecknoglobals
+	string(events.TypeURLCreated): {},
+	string(events.TypeURLDeleted): {},
+	string(events.TypeURLClicked): {},
+}
+
+// WebhookServiceOptions опции сервиса вебхуков.
+type WebhookServiceOptions struct {
+	BaseURL         string           // Базовый адрес коротких ссылок для поля short_url
+	HTTPClient      *http.Client     // HTTP клиент доставки (по умолчанию без редиректов и внутренних адресов)
+	MaxAttempts     int              // Максимальное количество попыток доставки
+	BaseBackoff     time.Duration    // Задержка перед второй попыткой, далее удваивается
+	MaxBackoff      time.Duration    // Максимальная задержка между попытками
+	Workers         int              // Количество параллельных доставщиков
+	QueueSize       int              // Размер очереди событий, события сверх него отбрасываются
+	PollInterval    time.Duration    // Период опроса доставок, время попытки которых наступило
+	JobLease        time.Duration    // Через сколько прерванная попытка доставки будет повторена
+	DeliveryTTL     time.Duration    // Время хранения истории доставок
+	CleanupInterval time.Duration    // Период удаления истории доставок старше DeliveryTTL
+	OnError         func(err error)  // Обработчик ошибок, не связанных с получателем (например, ошибки БД)
+	Metrics         WebhookMetrics   // Сборщик метрик (по умолчанию метрики не собираются)
+	Now             func() time.Time // Источник времени (для тестов)
+	// VisitorsRefreshInterval период перечитывания посетителей с вебхуками. Вебхук, созданный
+	// другим экземпляром приложения, начинает получать события этого экземпляра не позже, чем через этот период.
+	VisitorsRefreshInterval time.Duration
+	// AllowPrivateTargets разрешает вебхуки на loopback, частные и link-local адреса (для тестов).
+	AllowPrivateTargets bool
+}
+
+// WebhookService управляет вебхуками посетителей и доставляет им события ссылок.
+// Доставки сохраняются в репозитории до успеха или исчерпания попыток, поэтому повторы
+// переживают перезапуск приложения.
+type WebhookService struct {
+	repo     WebhookRepository
+	source   EventSource
+	opts     WebhookServiceOptions
+	visitors *webhookVisitors
+	wake     chan struct{} // Сигнал доставщикам о новых доставках
+}
+
+// noopWebhookMetrics пустой сборщик метрик, используемый по умолчанию.
+type noopWebhookMetrics struct{}
+
+func (noopWebhookMetrics) WebhookEventsDropped(uint64) {}
+
+// NewWebhookService создает новый экземпляр сервиса вебхуков.
+//
+// Параметры:
+//   - repo: репозиторий вебхуков
+//   - source: источник событий
+//   - opts: функции для настройки опций
+//
+// Возвращает:
+//   - *WebhookService: инициализированный сервис
+func NewWebhookService(
+	repo WebhookRepository,
+	source EventSource,
+	opts ...func(*WebhookServiceOptions),
+) *WebhookService {
+	options := WebhookServiceOptions{
+		MaxAttempts:             defaultWebhookMaxAttempts,
+		BaseBackoff:             defaultWebhookBaseBackoff,
+		MaxBackoff:              defaultWebhookMaxBackoff,
+		Workers:                 defaultWebhookWorkers,
+		QueueSize:               defaultWebhookQueueSize,
+		PollInterval:            defaultWebhookPollInterval,
+		JobLease:                defaultWebhookJobLease,
+		DeliveryTTL:             DefaultWebhookDeliveryTTL,
+		CleanupInterval:         defaultWebhookCleanupInterval,
+		OnError:                 func(error) {},
+		Metrics:                 noopWebhookMetrics{},
+		Now:                     time.Now,
+		VisitorsRefreshInterval: defaultWebhookVisitorsRefreshInterval,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.HTTPClient == nil {
+		options.HTTPClient = newWebhookHTTPClient(options.AllowPrivateTargets)
+	}
+	return &WebhookService{
+		repo:     repo,
+		source:   source,
+		opts:     options,
+		visitors: newWebhookVisitors(),
+		wake:     make(chan struct{}, options.Workers),
+	}
+}
+
+// Register регистрирует новый вебхук посетителя. Секрет для подписи генерируется автоматически.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//   - rawURL: адрес получателя
+//   - eventTypes: типы событий
+//
+// Возвращает:
+//   - *models.Webhook: созданный вебхук (включая секрет)
+//   - error: ErrInvalidArgument при неизвестном типе события или адресе во внутренней сети
+//     (оборачивает urlvalidate.ErrPrivateAddress), ErrUnknown при других ошибках
+func (s *WebhookService) Register(
+	ctx context.Context,
+	visitorUUID string,
+	rawURL string,
+	eventTypes []string,
+) (*models.Webhook, error) {
+	if err := s.validateTarget(rawURL); err != nil {
+		return nil, err
+	}
+	if len(eventTypes) == 0 {
+		return nil, fmt.Errorf("%w: at least one event type is required", ErrInvalidArgument)
+	}
+	uniq := make([]string, 0, len(eventTypes))
+	seen := make(map[string]struct{}, len(eventTypes))
+	for _, t := range eventTypes {
+		if _, ok := webhookEventTypes[t]; !ok {
+			return nil, fmt.Errorf("%w: unknown event type `%s`", ErrInvalidArgument, t)
+		}
+		if _, dup := seen[t]; !dup {
+			seen[t] = struct{}{}
+			uniq = append(uniq, t)
+		}
+	}
+
+	secret := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("%w: generate webhook secret: %s", ErrUnknown, err.Error())
+	}
+
+	hook, err := s.repo.Create(ctx, &models.Webhook{
+		ID:          uuid.NewString(),
+		VisitorUUID: visitorUUID,
+		URL:         rawURL,
+		Secret:      hex.EncodeToString(secret),
+		Events:      uniq,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: create webhook: %s", ErrUnknown, err.Error())
+	}
+	s.visitors.add(visitorUUID)
+	return hook, nil
+}
+
+// validateTarget отклоняет адреса получателя во внутренней сети, если они не разрешены опциями.
+func (s *WebhookService) validateTarget(rawURL string) error {
+	if s.opts.AllowPrivateTargets {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: invalid webhook URL", ErrInvalidArgument)
+	}
+	if err = urlvalidate.ValidatePublicHost(u); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidArgument, err)
+	}
+	return nil
+}
+
+// GetAllByVisitorUUID возвращает вебхуки посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - []models.Webhook: вебхуки посетителя
+//   - error: ошибка получения данных
+func (s *WebhookService) GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.Webhook, error) {
+	hooks, err := s.repo.GetAllByVisitorUUID(ctx, visitorUUID)
+	if err != nil {
+		return nil, fmt.Errorf("get webhooks by visitor uuid: %w", err)
+	}
+	return hooks, nil
+}
+
+// Delete удаляет вебхук посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - id: идентификатор вебхука
+//   - visitorUUID: идентификатор посетителя
+//
+// Возвращает:
+//   - error: ErrRecordNotFound если вебхук не найден, ErrUnknown при других ошибках
+func (s *WebhookService) Delete(ctx context.Context, id string, visitorUUID string) error {
+	if err := s.repo.Delete(ctx, id, visitorUUID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return fmt.Errorf("webhook `%s`: %w", id, ErrRecordNotFound)
+		}
+		return fmt.Errorf("%w: delete webhook: %s", ErrUnknown, err.Error())
+	}
+	return nil
+}
+
+// Deliveries возвращает последние попытки доставки для вебхука посетителя.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - id: идентификатор вебхука
+//   - visitorUUID: идентификатор посетителя
+//   - limit: максимальное количество записей
+//
+// Возвращает:
+//   - []models.WebhookDelivery: попытки доставки, от новых к старым
+//   - error: ErrRecordNotFound если вебхук не найден, ErrUnknown при других ошибках
+func (s *WebhookService) Deliveries(
+	ctx context.Context,
+	id string,
+	visitorUUID string,
+	limit int,
+) ([]models.WebhookDelivery, error) {
+	if _, err := s.repo.GetByIDVisitorUUID(ctx, id, visitorUUID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, fmt.Errorf("webhook `%s`: %w", id, ErrRecordNotFound)
+		}
+		return nil, fmt.Errorf("%w: get webhook: %s", ErrUnknown, err.Error())
+	}
+	deliveries, err := s.repo.GetDeliveriesByWebhookID(ctx, id, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: get webhook deliveries: %s", ErrUnknown, err.Error())
+	}
+	return deliveries, nil
+}
+
+// Run подписывается на события и сохраняет их доставки на зарегистрированные вебхуки, запускает
+// доставщиков, а также периодически удаляет историю доставок старше DeliveryTTL. Блокируется до отмены контекста.
+// События, которые не помещаются в очередь размера QueueSize, отбрасываются и учитываются в метриках.
+// Сохраненные доставки не теряются: после перезапуска доставщики продолжают их попытки.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+func (s *WebhookService) Run(ctx context.Context) {
+	sub := s.source.SubscribeAllBuffered(s.opts.QueueSize)
+	defer sub.Close()
+	s.refreshVisitors(ctx)
+
+	cleanup := time.NewTicker(s.opts.CleanupInterval)
+	defer cleanup.Stop()
+	refresh := time.NewTicker(s.opts.VisitorsRefreshInterval)
+	defer refresh.Stop()
+
+	done := make(chan struct{})
+	for range s.opts.Workers {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			s.worker(ctx)
+		}()
+	}
+	defer func() {
+		for range s.opts.Workers {
+			<-done
+		}
+	}()
+
+	var dropped uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if n := sub.Dropped(); n > dropped {
+				s.opts.Metrics.WebhookEventsDropped(n - dropped)
+				dropped = n
+			}
+			s.enqueue(ctx, ev)
+		case <-refresh.C:
+			s.refreshVisitors(ctx)
+		case <-cleanup.C:
+			before := s.opts.Now().Add(-s.opts.DeliveryTTL)
+			if _, err := s.repo.DeleteDeliveriesBefore(ctx, before); err != nil {
+				s.opts.OnError(fmt.Errorf("webhooks: delete old deliveries: %w", err))
+			}
+		}
+	}
+}
+
+// refreshVisitors перечитывает посетителей, у которых есть вебхуки.
+func (s *WebhookService) refreshVisitors(ctx context.Context) {
+	if err := s.visitors.refresh(ctx, s.repo); err != nil {
+		s.opts.OnError(fmt.Errorf("webhooks: %w", err))
+	}
+}
+
+// enqueue сохраняет доставки события на все подходящие вебхуки владельца ссылки и будит доставщиков.
+// События посетителей без вебхуков отбрасываются без обращения к репозиторию.
+func (s *WebhookService) enqueue(ctx context.Context, ev events.Event) {
+	if !s.visitors.has(ev.VisitorUUID) {
+		return
+	}
+	hooks, err := s.repo.GetAllByVisitorUUID(ctx, ev.VisitorUUID)
+	if err != nil {
+		s.opts.OnError(fmt.Errorf("webhooks: get webhooks for visitor %s: %w", ev.VisitorUUID, err))
+		return
+	}
+
+	eventID := uuid.NewString()
+	now := s.opts.Now().UTC()
+	var payload []byte
+	var jobs []models.WebhookJob
+	for _, hook := range hooks {
+		if !hook.HasEvent(string(ev.Type)) {
+			continue
+		}
+		if payload == nil {
+			payload, err = s.buildPayload(eventID, ev)
+			if err != nil {
+				s.opts.OnError(fmt.Errorf("webhooks: build payload: %w", err))
+				return
+			}
+		}
+		jobs = append(jobs, models.WebhookJob{
+			ID:            uuid.NewString(),
+			CreatedAt:     now,
+			WebhookID:     hook.ID,
+			EventID:       eventID,
+			EventType:     string(ev.Type),
+			NextAttemptAt: now,
+			Payload:       payload,
+		})
+	}
+	if len(jobs) == 0 {
+		return
+	}
+	if err = s.repo.CreateJobs(ctx, jobs); err != nil {
+		s.opts.OnError(fmt.Errorf("webhooks: save deliveries for visitor %s: %w", ev.VisitorUUID, err))
+		return
+	}
+	for range jobs {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// webhookPayload тело запроса доставки вебхука.
+type webhookPayload struct {
+	ID         string             `json:"id"`
+	Type       string             `json:"type"`
+	OccurredAt time.Time          `json:"occurred_at"`
+	Data       webhookPayloadData `json:"data"`
+}
+
+// webhookPayloadData данные ссылки в теле вебхука.
+type webhookPayloadData struct {
+	ShortIdentifier string `json:"short_identifier"`
+	ShortURL        string `json:"short_url,omitempty"`
+	OriginalURL     string `json:"original_url,omitempty"`
+}
+
+// buildPayload формирует тело запроса доставки.
+func (s *WebhookService) buildPayload(eventID string, ev events.Event) ([]byte, error) {
+	data := webhookPayloadData{
+		ShortIdentifier: ev.ShortIdentifier,
+		OriginalURL:     ev.URL,
+	}
+	if s.opts.BaseURL != "" {
+		data.ShortURL = s.opts.BaseURL + "/" + ev.ShortIdentifier
+	}
+	b, err := json.Marshal(webhookPayload{
+		ID:         eventID,
+		Type:       string(ev.Type),
+		OccurredAt: ev.OccurredAt,
+		Data:       data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal webhook payload: %w", err)
+	}
+	return b, nil
+}
+
+// worker захватывает и выполняет доставки, время попытки которых наступило. Если таких нет,
+// ждет новых доставок или следующего опроса, поэтому задержка перед повтором не занимает доставщика.
+func (s *WebhookService) worker(ctx context.Context) {
+	poll := time.NewTicker(s.opts.PollInterval)
+	defer poll.Stop()
+
+	for {
+		claims, err := s.repo.ClaimDueJobs(ctx, s.opts.Now().UTC(), s.opts.JobLease, 1)
+		if err != nil && ctx.Err() == nil {
+			s.opts.OnError(fmt.Errorf("webhooks: claim due deliveries: %w", err))
+		}
+		for _, c := range claims {
+			s.deliver(ctx, c)
+		}
+		if len(claims) > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-poll.C:
+		}
+	}
+}
+
+// deliver выполняет очередную попытку доставки и сохраняет ее. После успеха или последней попытки
+// (она помечается как dead letter) доставка удаляется, иначе следующая попытка назначается
+// с экспоненциальной задержкой.
+func (s *WebhookService) deliver(ctx context.Context, c repositories.WebhookJobClaim) {
+	job := c.Job
+	attempt := job.Attempt + 1
+	statusCode, sendErr := s.send(ctx, c.Webhook, job)
+	if ctx.Err() != nil {
+		// Остановка сервиса: попытка прервана не по вине получателя, не сохраняем её.
+		// Доставка будет повторена по истечении JobLease, в том числе после перезапуска.
+		return
+	}
+
+	delivery := models.WebhookDelivery{
+		ID:         uuid.NewString(),
+		CreatedAt:  s.opts.Now().UTC(),
+		WebhookID:  job.WebhookID,
+		EventID:    job.EventID,
+		EventType:  job.EventType,
+		Attempt:    attempt,
+		StatusCode: statusCode,
+		Success:    sendErr == nil,
+		DeadLetter: sendErr != nil && attempt >= s.opts.MaxAttempts,
+		Payload:    job.Payload,
+	}
+	if sendErr != nil {
+		delivery.Error = sendErr.Error()
+	}
+	if err := s.repo.CreateDelivery(ctx, &delivery); err != nil {
+		s.opts.OnError(fmt.Errorf("webhooks: save delivery for webhook %s: %w", job.WebhookID, err))
+	}
+
+	if sendErr == nil || delivery.DeadLetter {
+		if err := s.repo.DeleteJob(ctx, job.ID); err != nil {
+			s.opts.OnError(fmt.Errorf("webhooks: delete delivery job %s: %w", job.ID, err))
+		}
+		return
+	}
+	next := s.opts.Now().UTC().Add(s.exponentialBackoff(attempt))
+	if err := s.repo.RescheduleJob(ctx, job.ID, attempt, next); err != nil &&
+		!errors.Is(err, repositories.ErrNotFound) {
+		s.opts.OnError(fmt.Errorf("webhooks: reschedule delivery job %s: %w", job.ID, err))
+	}
+}
+
+// send выполняет одну попытку доставки.
+//
+// Возвращает:
+//   - int: HTTP статус ответа получателя (0 если ответ не получен)
+//   - error: ошибка доставки, в т.ч. при статусе ответа отличном от 2xx
+func (s *WebhookService) send(ctx context.Context, hook models.Webhook, job models.WebhookJob) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(job.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	ts := s.opts.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookHeaderID, hook.ID)
+	req.Header.Set(WebhookHeaderEvent, job.EventType)
+	req.Header.Set(WebhookHeaderDelivery, job.EventID)
+	req.Header.Set(WebhookHeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(WebhookHeaderSignature, SignWebhookPayload(hook.Secret, ts, job.Payload))
+
+	res, err := s.opts.HTTPClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	// Тело ответа не сохраняется: иначе вебхук позволял бы читать ответы внутренних сервисов.
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, webhookResponseDrainLimit))
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return res.StatusCode, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// newWebhookHTTPClient создает HTTP клиент доставки вебхуков. Редиректы не выполняются
+// (ответ 3xx считается неудачной доставкой). Если не allowPrivate, соединение с непубличным
+// адресом отклоняется после разрешения имени, поэтому DNS не позволяет обойти проверку при регистрации.
+func newWebhookHTTPClient(allowPrivate bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:errcheck,forcetypeassert
+	if !allowPrivate {
+		// Через прокси проверялся бы адрес прокси, а не получателя.
+		transport.Proxy = nil
+		dialer := &net.Dialer{Timeout: defaultWebhookRequestTimeout, Control: publicAddrControl}
+		transport.DialContext = dialer.DialContext
+	}
+	return &http.Client{
+		Timeout:   defaultWebhookRequestTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// publicAddrControl отклоняет соединение с непубличным адресом (см. urlvalidate.IsPublicAddr).
+func publicAddrControl(_ string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("parse dial address %s: %w", address, err)
+	}
+	if !urlvalidate.IsPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("dial %s: %w", address, urlvalidate.ErrPrivateAddress)
+	}
+	return nil
+}
+
+// exponentialBackoff вычисляет задержку перед следующей попыткой.
+func (s *WebhookService) exponentialBackoff(attempt int) time.Duration {
+	d := s.opts.BaseBackoff << (attempt - 1)
+	if d <= 0 || d > s.opts.MaxBackoff {
+		return s.opts.MaxBackoff
+	}
+	return d
+}
+
+// webhookVisitors кэш посетителей, у которых есть вебхуки. Пока кэш не загружен,
+// считается, что вебхуки могут быть у любого посетителя.
+type webhookVisitors struct {
+	mu     sync.RWMutex
+	known  map[string]struct{}
+	added  map[string]struct{} // Добавленные во время обновления, которое может их не увидеть
+	loaded bool
+}
+
+// newWebhookVisitors создает незагруженный кэш посетителей.
+func newWebhookVisitors() *webhookVisitors {
+	return &webhookVisitors{
+		known: make(map[string]struct{}),
+		added: make(map[string]struct{}),
+	}
+}
+
+// has проверяет, могут ли у посетителя быть вебхуки.
+func (v *webhookVisitors) has(visitorUUID string) bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if !v.loaded {
+		return true
+	}
+	_, ok := v.known[visitorUUID]
+	return ok
+}
+
+// add добавляет посетителя, зарегистрировавшего вебхук. Удаленные вебхуки из кэша не исключаются
+// до следующего обновления: лишний посетитель стоит только лишнего запроса к репозиторию.
+func (v *webhookVisitors) add(visitorUUID string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.known[visitorUUID] = struct{}{}
+	v.added[visitorUUID] = struct{}{}
+}
+
+// refresh заменяет кэш посетителями из репозитория.
+func (v *webhookVisitors) refresh(ctx context.Context, repo WebhookRepository) error {
+	v.mu.Lock()
+	clear(v.added)
+	v.mu.Unlock()
+
+	visitors, err := repo.GetVisitorUUIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("get webhook visitors: %w", err)
+	}
+	known := make(map[string]struct{}, len(visitors))
+	for _, visitorUUID := range visitors {
+		known[visitorUUID] = struct{}{}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for visitorUUID := range v.added {
+		known[visitorUUID] = struct{}{}
+	}
+	v.known = known
+	v.loaded = true
+	return nil
+}
+
+// SignWebhookPayload вычисляет подпись тела вебхука.
+// Подписывается строка `<timestamp>.<body>` алгоритмом HMAC-SHA256 с секретом вебхука.
+//
+// Параметры:
+//   - secret: секрет вебхука
+//   - timestamp: unix время, переданное в заголовке WebhookHeaderTimestamp
+//   - body: тело запроса
+//
+// Возвращает:
+//   - string: значение заголовка WebhookHeaderSignature вида sha256=<hex>
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,513 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/db/memory"
+	"github.com/fsdevblog/shorturl/internal/events"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories/memstore"
+	"github.com/fsdevblog/shorturl/internal/urlvalidate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testWebhookVisitor = "1c0a5fa4-5a5e-4a43-a3c1-2b0d5d0c2a11"
+
+// receivedWebhook запрос, полученный тестовым получателем.
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+// webhookReceiver тестовый получатель вебхуков, отвечающий заданной последовательностью статусов.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	calls    atomic.Int32
+	received []receivedWebhook
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	n := int(r.calls.Add(1))
+
+	r.mu.Lock()
+	r.received = append(r.received, receivedWebhook{header: req.Header.Clone(), body: body})
+	status := http.StatusOK
+	if n <= len(r.statuses) {
+		status = r.statuses[n-1]
+	}
+	r.mu.Unlock()
+
+	w.WriteHeader(status)
+}
+
+func (r *webhookReceiver) first() receivedWebhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.received[0]
+}
+
+// testWebhookOptions короткие задержки доставки для тестов.
+func testWebhookOptions(o *WebhookServiceOptions) {
+	o.BaseBackoff = time.Millisecond
+	o.MaxBackoff = 5 * time.Millisecond
+	o.PollInterval = time.Millisecond
+	o.Workers = 1
+	// Тестовые получатели слушают loopback.
+	o.AllowPrivateTargets = true
+}
+
+// runWebhookService запускает сервис и возвращает функцию его остановки.
+func runWebhookService(t *testing.T, svc *WebhookService, hub *events.Hub) func() {
+	t.Helper()
+
+	subscribers := hub.Subscribers()
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool { return hub.Subscribers() == subscribers+1 }, time.Second, time.Millisecond)
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// startWebhookService запускает сервис вебхуков поверх in-memory репозитория.
+func startWebhookService(
+	t *testing.T,
+	opts ...func(*WebhookServiceOptions),
+) (*WebhookService, *memstore.WebhookRepo, *events.Hub) {
+	t.Helper()
+
+	hub := events.NewHub()
+	repo := memstore.NewWebhookRepo(db.NewMemStorage())
+	svc := NewWebhookService(repo, hub, append([]func(*WebhookServiceOptions){testWebhookOptions}, opts...)...)
+
+	stop := runWebhookService(t, svc, hub)
+	t.Cleanup(func() {
+		stop()
+		hub.Close()
+	})
+	return svc, repo, hub
+}
+
+// visitorKnown проверяет, что загруженный кэш сервиса знает о вебхуках посетителя.
+func visitorKnown(svc *WebhookService, visitorUUID string) bool {
+	svc.visitors.mu.RLock()
+	defer svc.visitors.mu.RUnlock()
+
+	_, ok := svc.visitors.known[visitorUUID]
+	return svc.visitors.loaded && ok
+}
+
+func TestWebhookService_DeliverSigned(t *testing.T) {
+	receiver := &webhookReceiver{}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	svc, _, hub := startWebhookService(t, func(o *WebhookServiceOptions) {
+		o.BaseURL = "http://short.test"
+	})
+
+	hook, err := svc.Register(t.Context(), testWebhookVisitor, srv.URL, []string{string(events.TypeURLCreated)})
+	require.NoError(t, err)
+	require.NotEmpty(t, hook.Secret)
+
+	// Событие другого типа и событие другого посетителя не доставляются.
+	hub.Publish(events.Event{Type: events.TypeURLClicked, VisitorUUID: testWebhookVisitor, ShortIdentifier: "skip0001"})
+	hub.Publish(events.Event{Type: events.TypeURLCreated, VisitorUUID: "other", ShortIdentifier: "skip0002"})
+	hub.Publish(events.Event{
+		Type:            events.TypeURLCreated,
+		VisitorUUID:     testWebhookVisitor,
+		ShortIdentifier: "abcdefgh",
+		URL:             "https://example.com",
+	})
+
+	require.Eventually(t, func() bool { return receiver.calls.Load() == 1 }, time.Second, time.Millisecond)
+	got := receiver.first()
+
+	ts, tsErr := strconv.ParseInt(got.header.Get(WebhookHeaderTimestamp), 10, 64)
+	require.NoError(t, tsErr)
+	assert.Equal(t, SignWebhookPayload(hook.Secret, ts, got.body), got.header.Get(WebhookHeaderSignature))
+	assert.NotEqual(t, SignWebhookPayload("wrong secret", ts, got.body), got.header.Get(WebhookHeaderSignature))
+	assert.Equal(t, hook.ID, got.header.Get(WebhookHeaderID))
+	assert.Equal(t, string(events.TypeURLCreated), got.header.Get(WebhookHeaderEvent))
+
+	var payload webhookPayload
+	require.NoError(t, json.Unmarshal(got.body, &payload))
+	assert.Equal(t, got.header.Get(WebhookHeaderDelivery), payload.ID)
+	assert.Equal(t, "abcdefgh", payload.Data.ShortIdentifier)
+	assert.Equal(t, "http://short.test/abcdefgh", payload.Data.ShortURL)
+	assert.Equal(t, "https://example.com", payload.Data.OriginalURL)
+
+	var deliveries []models.WebhookDelivery
+	require.Eventually(t, func() bool {
+		deliveries, err = svc.Deliveries(t.Context(), hook.ID, testWebhookVisitor, 10)
+		return err == nil && len(deliveries) == 1
+	}, time.Second, time.Millisecond)
+	assert.True(t, deliveries[0].Success)
+	assert.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+	assert.Equal(t, 1, deliveries[0].Attempt)
+}
+
+func TestWebhookService_RetryThenSuccess(t *testing.T) {
+	receiver := &webhookReceiver{statuses: []int{http.StatusInternalServerError, http.StatusBadGateway}}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	svc, _, hub := startWebhookService(t)
+
+	hook, err := svc.Register(t.Context(), testWebhookVisitor, srv.URL, []string{string(events.TypeURLDeleted)})
+	require.NoError(t, err)
+
+	hub.Publish(events.Event{Type: events.TypeURLDeleted, VisitorUUID: testWebhookVisitor, ShortIdentifier: "abcdefgh"})
+
+	var deliveries []models.WebhookDelivery
+	require.Eventually(t, func() bool {
+		deliveries, err = svc.Deliveries(t.Context(), hook.ID, testWebhookVisitor, 10)
+		return err == nil && len(deliveries) == 3
+	}, time.Second, time.Millisecond)
+
+	// Попытки отсортированы от новых к старым.
+	assert.True(t, deliveries[0].Success)
+	assert.Equal(t, 3, deliveries[0].Attempt)
+	for _, d := range deliveries[1:] {
+		assert.False(t, d.Success)
+		assert.False(t, d.DeadLetter)
+		assert.NotEmpty(t, d.Error)
+		assert.Equal(t, deliveries[0].EventID, d.EventID)
+	}
+	assert.Equal(t, int32(3), receiver.calls.Load())
+}
+
+func TestWebhookService_DeadLetter(t *testing.T) {
+	receiver := &webhookReceiver{statuses: []int{
+		http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable,
+	}}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	svc, _, hub := startWebhookService(t, func(o *WebhookServiceOptions) {
+		o.MaxAttempts = 3
+	})
+
+	hook, err := svc.Register(t.Context(), testWebhookVisitor, srv.URL, []string{string(events.TypeURLClicked)})
+	require.NoError(t, err)
+
+	hub.Publish(events.Event{Type: events.TypeURLClicked, VisitorUUID: testWebhookVisitor, ShortIdentifier: "abcdefgh"})
+
+	var deliveries []models.WebhookDelivery
+	require.Eventually(t, func() bool {
+		deliveries, err = svc.Deliveries(t.Context(), hook.ID, testWebhookVisitor, 10)
+		return err == nil && len(deliveries) == 3 && deliveries[0].DeadLetter
+	}, time.Second, time.Millisecond)
+
+	for _, d := range deliveries {
+		assert.False(t, d.Success)
+		assert.Equal(t, http.StatusServiceUnavailable, d.StatusCode)
+	}
+	assert.Equal(t, 3, deliveries[0].Attempt)
+
+	// Больше попыток не будет.
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(3), receiver.calls.Load())
+}
+
+// blockingWebhookRepo задерживает первое чтение вебхуков посетителя, пока тест не разрешит продолжить,
+// и считает такие чтения.
+type blockingWebhookRepo struct {
+	*memstore.WebhookRepo
+	entered chan struct{}
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (r *blockingWebhookRepo) GetAllByVisitorUUID(ctx context.Context, visitorUUID string) ([]models.Webhook, error) {
+	if r.calls.Add(1) == 1 {
+		r.entered <- struct{}{}
+		select {
+		case <-r.release:
+		case <-ctx.Done():
+		}
+	}
+	return r.WebhookRepo.GetAllByVisitorUUID(ctx, visitorUUID) //nolint:wrapcheck
+}
+
+// droppedCounter тестовый сборщик метрик вебхуков.
+type droppedCounter struct {
+	n atomic.Uint64
+}
+
+func (c *droppedCounter) WebhookEventsDropped(n uint64) {
+	c.n.Add(n)
+}
+
+func TestWebhookService_QueueOverflow(t *testing.T) {
+	receiver := &webhookReceiver{}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	hub := events.NewHub()
+	defer hub.Close()
+	repo := &blockingWebhookRepo{
+		WebhookRepo: memstore.NewWebhookRepo(db.NewMemStorage()),
+		entered:     make(chan struct{}),
+		release:     make(chan struct{}),
+	}
+	dropped := &droppedCounter{}
+	svc := NewWebhookService(repo, hub, testWebhookOptions, func(o *WebhookServiceOptions) {
+		o.QueueSize = 2
+		o.Metrics = dropped
+	})
+	_, err := svc.Register(t.Context(), testWebhookVisitor, srv.URL, []string{string(events.TypeURLClicked)})
+	require.NoError(t, err)
+	defer runWebhookService(t, svc, hub)()
+
+	clicked := events.Event{Type: events.TypeURLClicked, VisitorUUID: testWebhookVisitor, ShortIdentifier: "abcdefgh"}
+	hub.Publish(clicked)
+	<-repo.entered
+
+	// Пока первое событие обрабатывается, в очередь помещаются только два следующих.
+	for range 5 {
+		hub.Publish(clicked)
+	}
+	close(repo.release)
+
+	require.Eventually(t, func() bool { return receiver.calls.Load() == 3 }, time.Second, time.Millisecond)
+	require.Eventually(t, func() bool { return dropped.n.Load() == 3 }, time.Second, time.Millisecond)
+
+	// События посетителей без вебхуков не требуют чтения вебхуков.
+	hub.Publish(events.Event{Type: events.TypeURLClicked, VisitorUUID: "other", ShortIdentifier: "abcdefgh"})
+	hub.Publish(clicked)
+	require.Eventually(t, func() bool { return receiver.calls.Load() == 4 }, time.Second, time.Millisecond)
+	assert.Equal(t, int32(4), repo.calls.Load())
+}
+
+func TestWebhookService_RetryAfterRestart(t *testing.T) {
+	receiver := &webhookReceiver{statuses: []int{http.StatusInternalServerError}}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	hub := events.NewHub()
+	defer hub.Close()
+	store := db.NewMemStorage()
+	repo := memstore.NewWebhookRepo(store)
+	hourBackoff := func(o *WebhookServiceOptions) {
+		o.BaseBackoff = time.Hour
+		o.MaxBackoff = time.Hour
+	}
+
+	first := NewWebhookService(repo, hub, testWebhookOptions, hourBackoff)
+	stop := runWebhookService(t, first, hub)
+	hook, err := first.Register(t.Context(), testWebhookVisitor, srv.URL, []string{string(events.TypeURLCreated)})
+	require.NoError(t, err)
+	hub.Publish(events.Event{Type: events.TypeURLCreated, VisitorUUID: testWebhookVisitor, ShortIdentifier: "abcdefgh"})
+
+	// Повтор через час сохранен в репозитории, доставщик его не ждет.
+	require.Eventually(t, func() bool {
+		jobs, jobsErr := memory.FilterAll[models.WebhookJob](t.Context(), store.Collection("webhook_jobs"),
+			func(j models.WebhookJob) bool { return j.Attempt == 1 })
+		return jobsErr == nil && len(jobs) == 1 && jobs[0].NextAttemptAt.After(time.Now().Add(time.Minute))
+	}, time.Second, time.Millisecond)
+	stop()
+
+	// После перезапуска повтор выполняется, когда наступает его время.
+	second := NewWebhookService(repo, hub, testWebhookOptions, hourBackoff, func(o *WebhookServiceOptions) {
+		o.Now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	})
+	defer runWebhookService(t, second, hub)()
+
+	var deliveries []models.WebhookDelivery
+	require.Eventually(t, func() bool {
+		deliveries, err = second.Deliveries(t.Context(), hook.ID, testWebhookVisitor, 10)
+		return err == nil && len(deliveries) == 2
+	}, time.Second, time.Millisecond)
+	assert.True(t, deliveries[0].Success)
+	assert.Equal(t, 2, deliveries[0].Attempt)
+	assert.Equal(t, deliveries[1].EventID, deliveries[0].EventID)
+	require.Eventually(t, func() bool {
+		claims, claimErr := repo.ClaimDueJobs(t.Context(), time.Now().Add(24*time.Hour), time.Minute, 10)
+		return claimErr == nil && len(claims) == 0
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(2), receiver.calls.Load())
+}
+
+func TestWebhookService_DeletesOldDeliveries(t *testing.T) {
+	now := time.Now().UTC()
+	_, repo, _ := startWebhookService(t, func(o *WebhookServiceOptions) {
+		o.DeliveryTTL = time.Hour
+		o.CleanupInterval = time.Millisecond
+	})
+
+	const hookID = "0e9b8f0c-9f2a-4c55-8c77-0f6f0b8f3a01"
+	for i, createdAt := range []time.Time{now.Add(-2 * time.Hour), now.Add(-time.Minute)} {
+		require.NoError(t, repo.CreateDelivery(t.Context(), &models.WebhookDelivery{
+			ID:        strconv.Itoa(i),
+			CreatedAt: createdAt,
+			WebhookID: hookID,
+			Payload:   []byte(`{}`),
+		}))
+	}
+
+	require.Eventually(t, func() bool {
+		deliveries, err := repo.GetDeliveriesByWebhookID(t.Context(), hookID, 10)
+		return err == nil && len(deliveries) == 1 && deliveries[0].ID == "1"
+	}, time.Second, time.Millisecond)
+}
+
+func TestWebhookService_Register(t *testing.T) {
+	svc := NewWebhookService(memstore.NewWebhookRepo(db.NewMemStorage()), events.NewHub())
+
+	_, err := svc.Register(t.Context(), testWebhookVisitor, "http://example.com", []string{"url.unknown"})
+	require.ErrorIs(t, err, ErrInvalidArgument)
+
+	_, err = svc.Register(t.Context(), testWebhookVisitor, "http://example.com", nil)
+	require.ErrorIs(t, err, ErrInvalidArgument)
+
+	hook, err := svc.Register(t.Context(), testWebhookVisitor, "http://example.com", []string{
+		string(events.TypeURLCreated), string(events.TypeURLCreated),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{string(events.TypeURLCreated)}, hook.Events)
+
+	_, err = svc.Deliveries(t.Context(), hook.ID, "other", 10)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	require.ErrorIs(t, svc.Delete(t.Context(), hook.ID, "other"), ErrRecordNotFound)
+	require.NoError(t, svc.Delete(t.Context(), hook.ID, testWebhookVisitor))
+	require.ErrorIs(t, svc.Delete(t.Context(), hook.ID, testWebhookVisitor), ErrRecordNotFound)
+}
+
+func TestWebhookService_RegisterPrivateTarget(t *testing.T) {
+	svc := NewWebhookService(memstore.NewWebhookRepo(db.NewMemStorage()), events.NewHub())
+	created := []string{string(events.TypeURLCreated)}
+
+	for _, target := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://api.localhost/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.1/hook",
+		"http://172.16.0.1/hook",
+		"http://192.168.1.1/hook",
+		"http://100.64.0.1/hook",
+		"http://0.0.0.0/hook",
+		"http://[::1]/hook",
+		"http://[fe80::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+	} {
+		t.Run(target, func(t *testing.T) {
+			_, err := svc.Register(t.Context(), testWebhookVisitor, target, created)
+			require.ErrorIs(t, err, ErrInvalidArgument)
+			require.ErrorIs(t, err, urlvalidate.ErrPrivateAddress)
+		})
+	}
+
+	_, err := svc.Register(t.Context(), testWebhookVisitor, "https://93.184.216.34/hook", created)
+	require.NoError(t, err)
+}
+
+func TestWebhookService_DialGuard(t *testing.T) {
+	receiver := &webhookReceiver{}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	svc, repo, hub := startWebhookService(t, func(o *WebhookServiceOptions) {
+		o.AllowPrivateTargets = false
+		o.MaxAttempts = 1
+		o.VisitorsRefreshInterval = time.Millisecond
+	})
+
+	// Имя, прошедшее проверку при регистрации, позже может разрешиться во внутренний адрес:
+	// такой вебхук сохраняем в обход Register.
+	hook, err := repo.Create(t.Context(), &models.Webhook{
+		ID:          "5b0c7e1e-9f0a-4f7e-8d52-0c8f4a7e6b11",
+		VisitorUUID: testWebhookVisitor,
+		URL:         srv.URL,
+		Secret:      "secret",
+		Events:      []string{string(events.TypeURLCreated)},
+	})
+	require.NoError(t, err)
+	// Вебхук, сохраненный в обход сервиса, становится известен при обновлении кэша посетителей.
+	require.Eventually(t, func() bool { return visitorKnown(svc, testWebhookVisitor) }, time.Second, time.Millisecond)
+
+	hub.Publish(events.Event{Type: events.TypeURLCreated, VisitorUUID: testWebhookVisitor, ShortIdentifier: "abcdefgh"})
+
+	var deliveries []models.WebhookDelivery
+	require.Eventually(t, func() bool {
+		deliveries, err = svc.Deliveries(t.Context(), hook.ID, testWebhookVisitor, 10)
+		return err == nil && len(deliveries) == 1
+	}, time.Second, time.Millisecond)
+	assert.True(t, deliveries[0].DeadLetter)
+	assert.Zero(t, deliveries[0].StatusCode)
+	assert.Contains(t, deliveries[0].Error, urlvalidate.ErrPrivateAddress.Error())
+	assert.Zero(t, receiver.calls.Load())
+}
+
+func TestWebhookService_ResponseNotExposed(t *testing.T) {
+	internal := &webhookReceiver{}
+	internalSrv := httptest.NewServer(internal)
+	defer internalSrv.Close()
+
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+	}{
+		{
+			name: "redirect is not followed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, internalSrv.URL, http.StatusFound)
+			},
+			wantStatus: http.StatusFound,
+		},
+		{
+			name: "body is not stored",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = io.WriteString(w, "internal secret")
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			svc, _, hub := startWebhookService(t, func(o *WebhookServiceOptions) {
+				o.MaxAttempts = 1
+			})
+			hook, err := svc.Register(t.Context(), testWebhookVisitor, srv.URL, []string{string(events.TypeURLCreated)})
+			require.NoError(t, err)
+
+			hub.Publish(events.Event{Type: events.TypeURLCreated, VisitorUUID: testWebhookVisitor, ShortIdentifier: "abcdefgh"})
+
+			var deliveries []models.WebhookDelivery
+			require.Eventually(t, func() bool {
+				deliveries, err = svc.Deliveries(t.Context(), hook.ID, testWebhookVisitor, 10)
+				return err == nil && len(deliveries) == 1
+			}, time.Second, time.Millisecond)
+			assert.False(t, deliveries[0].Success)
+			assert.Equal(t, tt.wantStatus, deliveries[0].StatusCode)
+			assert.NotContains(t, deliveries[0].Error, "internal secret")
+			assert.Zero(t, internal.calls.Load())
+		})
+	}
+}
@@ -0,0 +1,396 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/google/uuid"
+)
+
+// MaxWorkspaceNameLength максимальная длина названия рабочего пространства.
+const MaxWorkspaceNameLength = 255
+
+// workspaceShortIDAttempts количество попыток подобрать свободный короткий идентификатор ссылки пространства.
+// Идентификатор может быть занят, если ссылку пространства изменили и её прежний URL сокращают снова.
+const workspaceShortIDAttempts = 3
+
+// WorkspaceDetails рабочее пространство с участниками и ролью запросившего посетителя.
+type WorkspaceDetails struct {
+	Workspace *models.Workspace
+	Role      models.WorkspaceRole
+	Members   []models.WorkspaceMember
+}
+
+// WorkspaceService управляет рабочими пространствами и ссылками команд.
+// Ссылки пространства видят все участники, а создают, изменяют и удаляют участники с ролью editor или owner.
+// Для посетителей вне пространства оно не существует: на любой запрос возвращается ErrRecordNotFound.
+type WorkspaceService struct {
+	workspaces WorkspaceRepository
+	urls       URLRepository
+}
+
+// NewWorkspaceService создает новый экземпляр сервиса рабочих пространств.
+//
+// Параметры:
+//   - workspaces: репозиторий рабочих пространств
+//   - urls: репозиторий ссылок
+//
+// Возвращает:
+//   - *WorkspaceService: инициализированный сервис
+func NewWorkspaceService(workspaces WorkspaceRepository, urls URLRepository) *WorkspaceService {
+	return &WorkspaceService{workspaces: workspaces, urls: urls}
+}
+
+// Create создает рабочее пространство. Создатель становится его владельцем.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: UUID создателя
+//   - name: название пространства
+//
+// Возвращает:
+//   - *models.Workspace: созданное пространство
+//   - error: ErrInvalidArgument без названия или при слишком длинном названии, ErrUnknown при других ошибках
+func (s *WorkspaceService) Create(
+	ctx context.Context,
+	visitorUUID string,
+	name string,
+) (_ *models.Workspace, err error) {
+	ctx, span := startSpan(ctx, "WorkspaceService.Create")
+	defer func() { finishSpan(span, err) }()
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidArgument)
+	}
+	if utf8.RuneCountInString(name) > MaxWorkspaceNameLength {
+		return nil, fmt.Errorf("%w: name is longer than %d characters", ErrInvalidArgument, MaxWorkspaceNameLength)
+	}
+	w, err := s.workspaces.Create(ctx, &models.Workspace{ID: uuid.NewString(), Name: name, CreatedBy: visitorUUID})
+	if err != nil {
+		return nil, fmt.Errorf("%w: create workspace: %s", ErrUnknown, err.Error())
+	}
+	return w, nil
+}
+
+// List возвращает рабочие пространства посетителя вместе с его ролью в каждом.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: UUID посетителя
+//
+// Возвращает:
+//   - []models.WorkspaceMembership: пространства в порядке создания
+//   - error: ErrUnknown при ошибке
+func (s *WorkspaceService) List(ctx context.Context, visitorUUID string) (_ []models.WorkspaceMembership, err error) {
+	ctx, span := startSpan(ctx, "WorkspaceService.List")
+	defer func() { finishSpan(span, err) }()
+
+	list, err := s.workspaces.ListByMember(ctx, visitorUUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: list workspaces: %s", ErrUnknown, err.Error())
+	}
+	return list, nil
+}
+
+// Get возвращает рабочее пространство с участниками. Доступно любому участнику.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - visitorUUID: UUID посетителя
+//   - workspaceID: идентификатор пространства
+//
+// Возвращает:
+//   - *WorkspaceDetails: пространство, роль посетителя и участники
+//   - error: ErrRecordNotFound, если посетитель не участник, ErrUnknown при других ошибках
+func (s *WorkspaceService) Get(
+	ctx context.Context,
+	visitorUUID string,
+	workspaceID string,
+) (_ *WorkspaceDetails, err error) {
+	ctx, span := startSpan(ctx, "WorkspaceService.Get")
+	defer func() { finishSpan(span, err) }()
+
+	member, err := s.authorize(ctx, workspaceID, visitorUUID, nil)
+	if err != nil {
+		return nil, err
+	}
+	w, err := s.workspaces.Get(ctx, workspaceID)
+	if err != nil {
+		return nil, convertRepoError(err, "get workspace")
+	}
+	members, err := s.workspaces.ListMembers(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: list workspace members: %s", ErrUnknown, err.Error())
+	}
+	return &WorkspaceDetails{Workspace: w, Role: member.Role, Members: members}, nil
+}
+
+// SetMember добавляет участника рабочего пространства или меняет его роль. Доступно владельцам.
+// В пространстве всегда остается хотя бы один владелец.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - actorUUID: UUID владельца, выполняющего действие
+//   - workspaceID: идентификатор пространства
+//   - visitorUUID: UUID участника
+//   - role: роль участника
+//
+// Возвращает:
+//   - *models.WorkspaceMember: сохраненный участник
+//   - error: ErrRecordNotFound, если actorUUID не участник, ErrForbidden, если он не владелец,
+//     ErrInvalidArgument при некорректных UUID и роли или при понижении последнего владельца,
+//     ErrUnknown при других ошибках
+func (s *WorkspaceService) SetMember(
+	ctx context.Context,
+	actorUUID string,
+	workspaceID string,
+	visitorUUID string,
+	role models.WorkspaceRole,
+) (_ *models.WorkspaceMember, err error) {
+	ctx, span := startSpan(ctx, "WorkspaceService.SetMember")
+	defer func() { finishSpan(span, err) }()
+
+	if _, err := s.authorize(ctx, workspaceID, actorUUID, models.WorkspaceRole.CanManageMembers); err != nil {
+		return nil, err
+	}
+	if uuid.Validate(visitorUUID) != nil {
+		return nil, fmt.Errorf("%w: visitor uuid is not a uuid", ErrInvalidArgument)
+	}
+	if !role.Valid() {
+		return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidArgument, role)
+	}
+
+	m, err := s.workspaces.SetMember(ctx, &models.WorkspaceMember{
+		WorkspaceID: workspaceID,
+		VisitorUUID: visitorUUID,
+		Role:        role,
+	})
+	if err != nil {
+		return nil, convertMemberError(err, "set workspace member")
+	}
+	return m, nil
+}
+
+// RemoveMember удаляет участника рабочего пространства. Владелец может удалить любого участника,
+// остальные - только себя (покинуть пространство). Последний владелец покинуть пространство не может.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - actorUUID: UUID посетителя, выполняющего действие
+//   - workspaceID: идентификатор пространства
+//   - visitorUUID: UUID удаляемого участника
+//
+// Возвращает:
+//   - error: ErrRecordNotFound, если actorUUID или visitorUUID не участник, ErrForbidden, если
+//     не владелец удаляет другого участника, ErrInvalidArgument при удалении последнего владельца,
+//     ErrUnknown при других ошибках
+func (s *WorkspaceService) RemoveMember(
+	ctx context.Context,
+	actorUUID string,
+	workspaceID string,
+	visitorUUID string,
+) (err error) {
+	ctx, span := startSpan(ctx, "WorkspaceService.RemoveMember")
+	defer func() { finishSpan(span, err) }()
+
+	allow := models.WorkspaceRole.CanManageMembers
+	if actorUUID == visitorUUID {
+		allow = nil
+	}
+	if _, err := s.authorize(ctx, workspaceID, actorUUID, allow); err != nil {
+		return err
+	}
+	if err := s.workspaces.DeleteMember(ctx, workspaceID, visitorUUID); err != nil {
+		return convertMemberError(err, "delete workspace member")
+	}
+	return nil
+}
+
+// CreateURL сокращает URL в рабочем пространстве. Доступно владельцам и редакторам.
+// Для URL, уже сокращенного в пространстве, возвращается существующая ссылка.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - actorUUID: UUID участника, он сохраняется как автор ссылки
+//   - workspaceID: идентификатор пространства
+//   - rawURL: оригинальный URL
+//
+// Возвращает:
+//   - *models.URL: созданная или существующая ссылка
+//   - bool: true, если ссылка создана
+//   - error: ErrRecordNotFound, если посетитель не участник, ErrForbidden для наблюдателя,
+//     ErrUnknown при других ошибках
+func (s *WorkspaceService) CreateURL(
+	ctx context.Context,
+	actorUUID string,
+	workspaceID string,
+	rawURL string,
+) (_ *models.URL, _ bool, err error) {
+	ctx, span := startSpan(ctx, "WorkspaceService.CreateURL")
+	defer func() { finishSpan(span, err) }()
+
+	if _, err := s.authorize(ctx, workspaceID, actorUUID, models.WorkspaceRole.CanEditURLs); err != nil {
+		return nil, false, err
+	}
+	for attempt := range workspaceShortIDAttempts {
+		seed := workspaceID
+		if attempt > 0 {
+			seed += strconv.Itoa(attempt)
+		}
+		var m *models.URL
+		var created bool
+		m, created, err = s.urls.Create(ctx, &models.URL{
+			URL:             rawURL,
+			ShortIdentifier: generateShortID(rawURL, models.ShortIdentifierLength, seed),
+			VisitorUUID:     actorUUID,
+			WorkspaceID:     workspaceID,
+		})
+		if err == nil {
+			return m, created, nil
+		}
+		if !errors.Is(err, repositories.ErrDuplicateKey) {
+			break
+		}
+	}
+	return nil, false, fmt.Errorf("%w: create workspace url: %s", ErrUnknown, err.Error())
+}
+
+// ListURLs возвращает ссылки рабочего пространства, включая удаленные. Доступно любому участнику.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - actorUUID: UUID участника
+//   - workspaceID: идентификатор пространства
+//
+// Возвращает:
+//   - []models.URL: ссылки пространства в порядке создания
+//   - error: ErrRecordNotFound, если посетитель не участник, ErrUnknown при других ошибках
+func (s *WorkspaceService) ListURLs(
+	ctx context.Context,
+	actorUUID string,
+	workspaceID string,
+) (_ []models.URL, err error) {
+	ctx, span := startSpan(ctx, "WorkspaceService.ListURLs")
+	defer func() { finishSpan(span, err) }()
+
+	if _, err := s.authorize(ctx, workspaceID, actorUUID, nil); err != nil {
+		return nil, err
+	}
+	urls, err := s.urls.GetAllByWorkspaceID(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: list workspace urls: %s", ErrUnknown, err.Error())
+	}
+	return urls, nil
+}
+
+// UpdateURL изменяет оригинальный URL и (или) метки ссылки рабочего пространства.
+// Короткий идентификатор сохраняется. Доступно владельцам и редакторам.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - actorUUID: UUID участника
+//   - workspaceID: идентификатор пространства
+//   - shortID: короткий идентификатор ссылки
+//   - upd: изменения; URL должен быть проверен вызывающей стороной
+//
+// Возвращает:
+//   - *models.URL: измененная ссылка
+//   - error: ErrRecordNotFound, если посетитель не участник или ссылки нет в пространстве,
+//     ErrForbidden для наблюдателя, ErrInvalidArgument при недопустимых метках,
+//     ErrDuplicateKey, если новый URL в пространстве уже сокращен, ErrUnknown при других ошибках
+func (s *WorkspaceService) UpdateURL(
+	ctx context.Context,
+	actorUUID string,
+	workspaceID string,
+	shortID string,
+	upd repositories.URLUpdate,
+) (_ *models.URL, err error) {
+	ctx, span := startSpan(ctx, "WorkspaceService.UpdateURL")
+	defer func() { finishSpan(span, err) }()
+
+	if _, err := s.authorize(ctx, workspaceID, actorUUID, models.WorkspaceRole.CanEditURLs); err != nil {
+		return nil, err
+	}
+	if upd.Tags != nil {
+		tags, err := NormalizeTags(*upd.Tags)
+		if err != nil {
+			return nil, err
+		}
+		if tags == nil {
+			tags = []string{}
+		}
+		upd.Tags = &tags
+	}
+	m, err := s.urls.UpdateInWorkspace(ctx, workspaceID, shortID, upd)
+	if err != nil {
+		return nil, convertRepoError(err, "update workspace url")
+	}
+	return m, nil
+}
+
+// DeleteURL помечает удаленной ссылку рабочего пространства, кем бы из участников она ни была создана.
+// Доступно владельцам и редакторам.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - actorUUID: UUID участника
+//   - workspaceID: идентификатор пространства
+//   - shortID: короткий идентификатор ссылки
+//
+// Возвращает:
+//   - error: ErrRecordNotFound, если посетитель не участник или неудаленной ссылки нет в пространстве,
+//     ErrForbidden для наблюдателя, ErrUnknown при других ошибках
+func (s *WorkspaceService) DeleteURL(
+	ctx context.Context,
+	actorUUID string,
+	workspaceID string,
+	shortID string,
+) (err error) {
+	ctx, span := startSpan(ctx, "WorkspaceService.DeleteURL")
+	defer func() { finishSpan(span, err) }()
+
+	if _, err := s.authorize(ctx, workspaceID, actorUUID, models.WorkspaceRole.CanEditURLs); err != nil {
+		return err
+	}
+	if _, err := s.urls.DeleteInWorkspace(ctx, workspaceID, shortID); err != nil {
+		return convertRepoError(err, "delete workspace url")
+	}
+	return nil
+}
+
+// authorize проверяет, что посетитель участник пространства, а его роль удовлетворяет allow
+// (nil - подходит любая роль). Не участнику возвращается ErrRecordNotFound, чтобы не раскрывать пространство.
+func (s *WorkspaceService) authorize(
+	ctx context.Context,
+	workspaceID string,
+	visitorUUID string,
+	allow func(models.WorkspaceRole) bool,
+) (*models.WorkspaceMember, error) {
+	if uuid.Validate(workspaceID) != nil {
+		return nil, ErrRecordNotFound
+	}
+	member, err := s.workspaces.GetMember(ctx, workspaceID, visitorUUID)
+	if err != nil {
+		return nil, convertRepoError(err, "get workspace member")
+	}
+	if allow != nil && !allow(member.Role) {
+		return nil, fmt.Errorf("%w: role %s is not allowed", ErrForbidden, member.Role)
+	}
+	return member, nil
+}
+
+// convertMemberError преобразует ошибку изменения участника. Отказ репозитория понизить или удалить
+// последнего владельца (проверка выполняется атомарно с записью) становится ErrInvalidArgument.
+func convertMemberError(err error, op string) error {
+	if errors.Is(err, repositories.ErrLastOwner) {
+		return fmt.Errorf("%w: workspace must keep at least one owner", ErrInvalidArgument)
+	}
+	return convertRepoError(err, op)
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/models"
+	"github.com/fsdevblog/shorturl/internal/repositories"
+	"github.com/fsdevblog/shorturl/internal/repositories/memstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testOwnerUUID  = "0a1b2c3d-4e5f-4607-8819-2a3b4c5d6e7f"
+	testEditorUUID = "1b2c3d4e-5f60-4718-892a-3b4c5d6e7f80"
+	testViewerUUID = "2c3d4e5f-6071-4829-9a3b-4c5d6e7f8091"
+)
+
+// newTestWorkspace создает сервис и пространство с владельцем, редактором и наблюдателем.
+func newTestWorkspace(t *testing.T) (*WorkspaceService, *memstore.URLRepo, string) {
+	t.Helper()
+	ctx := context.Background()
+	store := db.NewMemStorage()
+	urls := memstore.NewURLRepo(store)
+	svc := NewWorkspaceService(memstore.NewWorkspaceRepo(store), urls)
+
+	ws, err := svc.Create(ctx, testOwnerUUID, " Marketing ")
+	require.NoError(t, err)
+	require.Equal(t, "Marketing", ws.Name)
+	_, err = svc.SetMember(ctx, testOwnerUUID, ws.ID, testEditorUUID, models.WorkspaceRoleEditor)
+	require.NoError(t, err)
+	_, err = svc.SetMember(ctx, testOwnerUUID, ws.ID, testViewerUUID, models.WorkspaceRoleViewer)
+	require.NoError(t, err)
+	return svc, urls, ws.ID
+}
+
+func TestWorkspaceService_Create(t *testing.T) {
+	ctx := context.Background()
+	svc := NewWorkspaceService(
+		memstore.NewWorkspaceRepo(db.NewMemStorage()), memstore.NewURLRepo(db.NewMemStorage()),
+	)
+
+	_, err := svc.Create(ctx, testOwnerUUID, "   ")
+	require.ErrorIs(t, err, ErrInvalidArgument)
+	_, err = svc.Create(ctx, testOwnerUUID, strings.Repeat("я", MaxWorkspaceNameLength+1))
+	require.ErrorIs(t, err, ErrInvalidArgument)
+
+	ws, err := svc.Create(ctx, testOwnerUUID, "Marketing")
+	require.NoError(t, err)
+	assert.Equal(t, testOwnerUUID, ws.CreatedBy)
+
+	list, err := svc.List(ctx, testOwnerUUID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, models.WorkspaceRoleOwner, list[0].Role)
+
+	list, err = svc.List(ctx, testViewerUUID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestWorkspaceService_Members(t *testing.T) {
+	ctx := context.Background()
+	svc, _, wsID := newTestWorkspace(t)
+
+	details, err := svc.Get(ctx, testViewerUUID, wsID)
+	require.NoError(t, err)
+	assert.Equal(t, models.WorkspaceRoleViewer, details.Role)
+	assert.Len(t, details.Members, 3)
+
+	// Для посторонних пространство не существует, в том числе при некорректном идентификаторе.
+	outsider := uuid.NewString()
+	_, err = svc.Get(ctx, outsider, wsID)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+	_, err = svc.Get(ctx, testOwnerUUID, "not-a-uuid")
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	_, err = svc.SetMember(ctx, testEditorUUID, wsID, outsider, models.WorkspaceRoleViewer)
+	require.ErrorIs(t, err, ErrForbidden)
+	_, err = svc.SetMember(ctx, testOwnerUUID, wsID, "nobody", models.WorkspaceRoleViewer)
+	require.ErrorIs(t, err, ErrInvalidArgument)
+	_, err = svc.SetMember(ctx, testOwnerUUID, wsID, outsider, "admin")
+	require.ErrorIs(t, err, ErrInvalidArgument)
+
+	// Последнего владельца нельзя понизить или удалить, второго - можно.
+	_, err = svc.SetMember(ctx, testOwnerUUID, wsID, testOwnerUUID, models.WorkspaceRoleEditor)
+	require.ErrorIs(t, err, ErrInvalidArgument)
+	require.ErrorIs(t, svc.RemoveMember(ctx, testOwnerUUID, wsID, testOwnerUUID), ErrInvalidArgument)
+	m, err := svc.SetMember(ctx, testOwnerUUID, wsID, testEditorUUID, models.WorkspaceRoleOwner)
+	require.NoError(t, err)
+	assert.Equal(t, models.WorkspaceRoleOwner, m.Role)
+	require.NoError(t, svc.RemoveMember(ctx, testOwnerUUID, wsID, testOwnerUUID))
+
+	// Не владелец может только покинуть пространство.
+	require.ErrorIs(t, svc.RemoveMember(ctx, testViewerUUID, wsID, testEditorUUID), ErrForbidden)
+	require.NoError(t, svc.RemoveMember(ctx, testViewerUUID, wsID, testViewerUUID))
+	require.ErrorIs(t, svc.RemoveMember(ctx, testEditorUUID, wsID, testViewerUUID), ErrRecordNotFound)
+
+	details, err = svc.Get(ctx, testEditorUUID, wsID)
+	require.NoError(t, err)
+	assert.Len(t, details.Members, 1)
+}
+
+// TestWorkspaceService_LastOwnerConcurrent проверяет, что два владельца, одновременно понижающие
+// себя и покидающие пространство, не оставляют его без владельца.
+func TestWorkspaceService_LastOwnerConcurrent(t *testing.T) {
+	ctx := context.Background()
+	for range 50 {
+		svc, _, wsID := newTestWorkspace(t)
+		_, err := svc.SetMember(ctx, testOwnerUUID, wsID, testEditorUUID, models.WorkspaceRoleOwner)
+		require.NoError(t, err)
+
+		errs := make([]error, 2)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, errs[0] = svc.SetMember(ctx, testOwnerUUID, wsID, testOwnerUUID, models.WorkspaceRoleEditor)
+		}()
+		go func() {
+			defer wg.Done()
+			errs[1] = svc.RemoveMember(ctx, testEditorUUID, wsID, testEditorUUID)
+		}()
+		wg.Wait()
+
+		failed := 0
+		for _, err = range errs {
+			if err != nil {
+				require.ErrorIs(t, err, ErrInvalidArgument)
+				failed++
+			}
+		}
+		require.Equal(t, 1, failed, "ровно одно изменение должно быть отклонено")
+
+		details, err := svc.Get(ctx, testViewerUUID, wsID)
+		require.NoError(t, err)
+		owners := 0
+		for _, m := range details.Members {
+			if m.Role == models.WorkspaceRoleOwner {
+				owners++
+			}
+		}
+		assert.Equal(t, 1, owners)
+	}
+}
+
+func TestWorkspaceService_URLs(t *testing.T) {
+	ctx := context.Background()
+	svc, urls, wsID := newTestWorkspace(t)
+
+	_, _, err := svc.CreateURL(ctx, testViewerUUID, wsID, "https://example.com")
+	require.ErrorIs(t, err, ErrForbidden)
+	_, _, err = svc.CreateURL(ctx, uuid.NewString(), wsID, "https://example.com")
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	created, isNew, err := svc.CreateURL(ctx, testEditorUUID, wsID, "https://example.com")
+	require.NoError(t, err)
+	assert.True(t, isNew)
+	assert.Equal(t, wsID, created.WorkspaceID)
+	assert.Equal(t, testEditorUUID, created.VisitorUUID)
+
+	// Тот же URL в пространстве не дублируется, кто бы из участников его ни сокращал.
+	existing, isNew, err := svc.CreateURL(ctx, testOwnerUUID, wsID, "https://example.com")
+	require.NoError(t, err)
+	assert.False(t, isNew)
+	assert.Equal(t, created.ShortIdentifier, existing.ShortIdentifier)
+
+	// Ссылки пространства не попадают в личные ссылки автора и не мешают ему сократить тот же URL для себя.
+	personal, err := urls.GetAllByVisitorUUID(ctx, testEditorUUID)
+	require.NoError(t, err)
+	assert.Empty(t, personal)
+	own, isNew, err := urls.Create(ctx, &models.URL{
+		URL:             "https://example.com",
+		ShortIdentifier: generateShortID("https://example.com", models.ShortIdentifierLength, ""),
+		VisitorUUID:     testEditorUUID,
+	})
+	require.NoError(t, err)
+	assert.True(t, isNew)
+	assert.NotEqual(t, created.ShortIdentifier, own.ShortIdentifier)
+
+	list, err := svc.ListURLs(ctx, testViewerUUID, wsID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	// Редактирование сохраняет короткий идентификатор, а прежний URL можно сократить заново.
+	newURL := "https://example.org"
+	tags := []string{" promo", "promo", ""}
+	_, err = svc.UpdateURL(ctx, testViewerUUID, wsID, created.ShortIdentifier, repositories.URLUpdate{URL: &newURL})
+	require.ErrorIs(t, err, ErrForbidden)
+	updated, err := svc.UpdateURL(ctx, testOwnerUUID, wsID, created.ShortIdentifier, repositories.URLUpdate{
+		URL:  &newURL,
+		Tags: &tags,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, created.ShortIdentifier, updated.ShortIdentifier)
+	assert.Equal(t, newURL, updated.URL)
+	assert.Equal(t, []string{"promo"}, updated.Tags)
+
+	again, isNew, err := svc.CreateURL(ctx, testEditorUUID, wsID, "https://example.com")
+	require.NoError(t, err)
+	assert.True(t, isNew)
+	assert.NotEqual(t, created.ShortIdentifier, again.ShortIdentifier)
+
+	_, err = svc.UpdateURL(ctx, testEditorUUID, wsID, again.ShortIdentifier, repositories.URLUpdate{URL: &newURL})
+	require.ErrorIs(t, err, ErrDuplicateKey)
+	_, err = svc.UpdateURL(ctx, testEditorUUID, wsID, own.ShortIdentifier, repositories.URLUpdate{Tags: &tags})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	// Через пространство удаляются только его ссылки, личные ссылки участников недоступны.
+	require.ErrorIs(t, svc.DeleteURL(ctx, testViewerUUID, wsID, again.ShortIdentifier), ErrForbidden)
+	require.NoError(t, svc.DeleteURL(ctx, testEditorUUID, wsID, updated.ShortIdentifier))
+	require.ErrorIs(t, svc.DeleteURL(ctx, testEditorUUID, wsID, updated.ShortIdentifier), ErrRecordNotFound)
+	require.ErrorIs(t, svc.DeleteURL(ctx, testEditorUUID, wsID, own.ShortIdentifier), ErrRecordNotFound)
+
+	list, err = svc.ListURLs(ctx, testViewerUUID, wsID)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, models.URLStateDeleted, list[0].State())
+}
@@ -0,0 +1,186 @@
+package tokens
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAsymmetricKeys создает ключи всех поддерживаемых алгоритмов.
+func testAsymmetricKeys(t *testing.T) (ed25519.PrivateKey, *rsa.PrivateKey) {
+	t.Helper()
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, MinRSAKeyBits)
+	require.NoError(t, err)
+	return edKey, rsaKey
+}
+
+func TestKeyring_Asymmetric(t *testing.T) {
+	edKey, rsaKey := testAsymmetricKeys(t)
+	tests := []struct {
+		name    string
+		key     crypto.Signer
+		wantAlg string
+	}{
+		{name: "ed25519", key: edKey, wantAlg: "EdDSA"},
+		{name: "rsa", key: rsaKey, wantAlg: "RS256"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyring, err := NewKeyring("k1", Key{ID: "k1", PrivateKey: tt.key})
+			require.NoError(t, err)
+
+			issued, err := keyring.GenerateVisitorJWT(testVisitorUUID, time.Hour)
+			require.NoError(t, err)
+			token, err := keyring.ValidateVisitorJWT(issued)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAlg, token.Header["alg"])
+			assert.Equal(t, testVisitorUUID, token.Claims.(*VisitorClaims).UUID) //nolint:errcheck
+
+			// Сторонний сервис проверяет токен только открытым ключом из JWKS.
+			set := keyring.JWKS()
+			require.Len(t, set.Keys, 1)
+			assert.Equal(t, tt.wantAlg, set.Keys[0].Algorithm)
+			_, err = jwt.ParseWithClaims(issued, new(VisitorClaims), func(*jwt.Token) (any, error) {
+				return publicKeyFromJWK(t, set.Keys[0]), nil
+			}, jwt.WithValidMethods([]string{tt.wantAlg}))
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestKeyring_AlgorithmConfusion(t *testing.T) {
+	edKey, rsaKey := testAsymmetricKeys(t)
+	keyring, err := NewKeyring("ed",
+		Key{ID: "ed", PrivateKey: edKey},
+		Key{ID: "rsa", PrivateKey: rsaKey},
+		Key{ID: "hmac", Secret: []byte("secret"), ValidUntil: time.Now().Add(time.Hour)},
+	)
+	require.NoError(t, err)
+
+	rsaPublicDER, err := x509.MarshalPKIXPublicKey(rsaKey.Public())
+	require.NoError(t, err)
+	rsaPublicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: rsaPublicDER})
+	_, otherEdKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		method jwt.SigningMethod
+		kid    string
+		key    any
+	}{
+		{
+			name:   "hs256 signed with rsa public key pem",
+			method: jwt.SigningMethodHS256,
+			kid:    "rsa",
+			key:    rsaPublicPEM,
+		},
+		{
+			name:   "hs256 signed with ed25519 public key",
+			method: jwt.SigningMethodHS256,
+			kid:    "ed",
+			key:    []byte(edKey.Public().(ed25519.PublicKey)), //nolint:errcheck
+		},
+		{
+			name:   "rs256 for ed25519 key",
+			method: jwt.SigningMethodRS256,
+			kid:    "ed",
+			key:    rsaKey,
+		},
+		{
+			name:   "eddsa for hmac key",
+			method: jwt.SigningMethodEdDSA,
+			kid:    "hmac",
+			key:    otherEdKey,
+		},
+		{
+			name:   "none",
+			method: jwt.SigningMethodNone,
+			kid:    "ed",
+			key:    jwt.UnsafeAllowNoneSignatureType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := jwt.NewWithClaims(tt.method, VisitorClaims{UUID: testVisitorUUID})
+			token.Header["kid"] = tt.kid
+			signed, signErr := token.SignedString(tt.key)
+			require.NoError(t, signErr)
+
+			_, err = keyring.ValidateVisitorJWT(signed)
+			require.ErrorIs(t, err, ErrSigningMethodMismatch)
+		})
+	}
+}
+
+func TestParsePrivateKeyPEM(t *testing.T) {
+	edKey, rsaKey := testAsymmetricKeys(t)
+	edPKCS8, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+	rsaPKCS8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		block   *pem.Block
+		want    crypto.Signer
+		wantErr bool
+	}{
+		{name: "ed25519 pkcs8", block: &pem.Block{Type: "PRIVATE KEY", Bytes: edPKCS8}, want: edKey},
+		{name: "rsa pkcs8", block: &pem.Block{Type: "PRIVATE KEY", Bytes: rsaPKCS8}, want: rsaKey},
+		{
+			name:  "rsa pkcs1",
+			block: &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)},
+			want:  rsaKey,
+		},
+		{name: "public key", block: &pem.Block{Type: "PUBLIC KEY", Bytes: edPKCS8}, wantErr: true},
+		{name: "broken", block: &pem.Block{Type: "PRIVATE KEY", Bytes: []byte("broken")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, parseErr := ParsePrivateKeyPEM(pem.EncodeToMemory(tt.block))
+			if tt.wantErr {
+				require.Error(t, parseErr)
+				return
+			}
+			require.NoError(t, parseErr)
+			assert.True(t, tt.want.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(got.Public())) //nolint:errcheck
+		})
+	}
+
+	_, err = ParsePrivateKeyPEM([]byte("not a pem"))
+	require.Error(t, err)
+}
+
+// publicKeyFromJWK восстанавливает открытый ключ из JWK, как это сделал бы сторонний сервис.
+func publicKeyFromJWK(t *testing.T, jwk JWK) crypto.PublicKey {
+	t.Helper()
+	decode := func(s string) []byte {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		require.NoError(t, err)
+		return b
+	}
+	if jwk.KeyType == "OKP" {
+		return ed25519.PublicKey(decode(jwk.X))
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(decode(jwk.N)),
+		E: int(new(big.Int).SetBytes(decode(jwk.E)).Int64()),
+	}
+}
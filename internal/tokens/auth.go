@@ -2,19 +2,35 @@ package tokens
 
 import (
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
 
+// RoleAdmin роль администратора: модерация ссылок и посетителей через /api/admin.
+const RoleAdmin = "admin"
+
 // VisitorClaims представляет данные JWT токена посетителя.
+// Каждый токен получает уникальный идентификатор (jti) и время выпуска (iat),
+// по которым он может быть отозван до истечения срока действия.
+// Идентификатор сессии (sid) сохраняется при продлении токена, поэтому отзыв сессии
+// действует и на токены, выпущенные при ее продлении.
 type VisitorClaims struct {
 	jwt.RegisteredClaims
-	UUID string
+	UUID      string
+	Roles     []string `json:"roles,omitempty"` // Роли посетителя. Анонимные посетители ролей не имеют
+	SessionID string   `json:"sid,omitempty"`   // Идентификатор сессии. Пустой у токенов, выпущенных до его появления
+}
+
+// HasRole проверяет, есть ли у посетителя роль role.
+func (c *VisitorClaims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
 }
 
-// GenerateVisitorJWT создает JWT токен для посетителя.
+// GenerateVisitorJWT создает JWT токен для посетителя, подписанный единственным ключом DefaultKeyID.
 //
 // Параметры:
 //   - uuid: уникальный идентификатор посетителя
@@ -25,30 +41,95 @@ type VisitorClaims struct {
 //   - string: сгенерированный JWT токен
 //   - error: ошибка генерации токена
 func GenerateVisitorJWT(uuid string, expire time.Duration, key []byte) (string, error) {
+	return NewStaticKeyring(key).GenerateVisitorJWT(uuid, expire)
+}
+
+// ValidateVisitorJWT проверяет JWT токен посетителя, подписанный единственным ключом DefaultKeyID.
+//
+// Параметры:
+//   - tokenString: JWT токен в виде строки
+//   - key: ключ для проверки подписи
+//   - opts: дополнительные опции разбора, например jwt.WithLeeway для приема недавно истекших токенов
+//
+// Возвращает:
+//   - *jwt.Token: проверенный токен
+//   - error: ошибка проверки (ErrTokenExpired если истек срок действия)
+func ValidateVisitorJWT(tokenString string, key []byte, opts ...jwt.ParserOption) (*jwt.Token, error) {
+	return NewStaticKeyring(key).ValidateVisitorJWT(tokenString, opts...)
+}
+
+// GenerateVisitorJWT создает JWT токен новой сессии посетителя, подписанный активным ключом.
+// Идентификатор ключа передается в заголовке kid.
+//
+// Параметры:
+//   - uuid: уникальный идентификатор посетителя
+//   - expire: срок действия токена
+//   - roles: роли посетителя, например RoleAdmin
+//
+// Возвращает:
+//   - string: сгенерированный JWT токен
+//   - error: ошибка генерации токена
+func (k *Keyring) GenerateVisitorJWT(uuid string, expire time.Duration, roles ...string) (string, error) {
+	return k.GenerateVisitorSessionJWT(uuid, NewSessionID(), expire, roles...)
+}
+
+// NewSessionID возвращает случайный идентификатор новой сессии посетителя (sid).
+func NewSessionID() string {
+	return newTokenID()
+}
+
+// GenerateVisitorSessionJWT создает JWT токен посетителя в рамках сессии sessionID,
+// например при продлении токена. Пустой sessionID начинает новую сессию (см. NewSessionID).
+//
+// Параметры:
+//   - uuid: уникальный идентификатор посетителя
+//   - sessionID: идентификатор сессии
+//   - expire: срок действия токена
+//   - roles: роли посетителя, например RoleAdmin
+//
+// Возвращает:
+//   - string: сгенерированный JWT токен
+//   - error: ошибка генерации токена
+func (k *Keyring) GenerateVisitorSessionJWT(
+	uuid string,
+	sessionID string,
+	expire time.Duration,
+	roles ...string,
+) (string, error) {
+	if sessionID == "" {
+		sessionID = NewSessionID()
+	}
+	now := time.Now()
 	visitorClaims := VisitorClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expire)),
+			ID:        newTokenID(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expire)),
 		},
-		UUID: uuid,
+		UUID:      uuid,
+		Roles:     roles,
+		SessionID: sessionID,
 	}
-	token, err := generateJWT(visitorClaims, key)
+	token, err := generateJWT(visitorClaims, k.active)
 	if err != nil {
 		return "", fmt.Errorf("generating visitor jwt token: %w", err)
 	}
 	return token, nil
 }
 
-// ValidateVisitorJWT проверяет JWT токен посетителя.
+// ValidateVisitorJWT проверяет JWT токен посетителя ключом, указанным в заголовке kid.
+// Токены без kid проверяются ключом DefaultKeyID.
 //
 // Параметры:
 //   - tokenString: JWT токен в виде строки
-//   - key: ключ для проверки подписи
+//   - opts: дополнительные опции разбора, например jwt.WithLeeway для приема недавно истекших токенов
 //
 // Возвращает:
 //   - *jwt.Token: проверенный токен
-//   - error: ошибка проверки (ErrTokenExpired если истек срок действия)
-func ValidateVisitorJWT(tokenString string, key []byte) (*jwt.Token, error) {
-	token, err := validateJWT(tokenString, new(VisitorClaims), key)
+//   - error: ошибка проверки (ErrTokenExpired если истек срок действия,
+//     ErrUnknownKey или ErrKeyRetired, если ключ неизвестен или выведен из оборота)
+func (k *Keyring) ValidateVisitorJWT(tokenString string, opts ...jwt.ParserOption) (*jwt.Token, error) {
+	token, err := validateJWT(tokenString, new(VisitorClaims), k, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("validating visitor jwt token: %w", err)
 	}
@@ -60,18 +141,24 @@ func ValidateVisitorJWT(tokenString string, key []byte) (*jwt.Token, error) {
 	return token, nil
 }
 
+// newTokenID возвращает случайный идентификатор токена (jti) или сессии (sid).
+func newTokenID() string {
+	return uuid.NewString()
+}
+
 // generateJWT создает JWT токен с указанными данными.
 //
 // Параметры:
 //   - claims: данные для включения в токен
-//   - key: ключ для подписи
+//   - key: ключ для подписи; его идентификатор попадает в заголовок kid
 //
 // Возвращает:
 //   - string: сгенерированный JWT токен
 //   - error: ошибка генерации
-func generateJWT(claims jwt.Claims, key []byte) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(key)
+func generateJWT(claims jwt.Claims, key Key) (string, error) {
+	token := jwt.NewWithClaims(key.signingMethod(), claims)
+	token.Header["kid"] = key.ID
+	tokenString, err := token.SignedString(key.signingKey())
 	if err != nil {
 		return "", fmt.Errorf("generating jwt token: %w", err)
 	}
@@ -84,15 +171,34 @@ func generateJWT(claims jwt.Claims, key []byte) (string, error) {
 // Параметры:
 //   - tokenString: JWT токен в виде строки
 //   - claims: структура для разбора данных токена
-//   - key: ключ для проверки подписи
+//   - keyring: набор ключей для проверки подписи
+//   - opts: опции разбора
 //
 // Возвращает:
 //   - *jwt.Token: проверенный токен
 //   - error: ошибка проверки
-func validateJWT(tokenString string, claims jwt.Claims, key []byte) (*jwt.Token, error) {
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(_ *jwt.Token) (any, error) {
-		return key, nil
-	})
+func validateJWT(
+	tokenString string,
+	claims jwt.Claims,
+	keyring *Keyring,
+	opts ...jwt.ParserOption,
+) (*jwt.Token, error) {
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (any, error) {
+		kid, ok := t.Header["kid"].(string)
+		if !ok && t.Header["kid"] != nil {
+			return nil, fmt.Errorf("%w: kid is not a string", ErrUnknownKey)
+		}
+		key, err := keyring.lookup(kid)
+		if err != nil {
+			return nil, err
+		}
+		// Алгоритм задается ключом: иначе токен с alg HS256, подписанный открытым ключом
+		// RSA или ed25519 как секретом, прошел бы проверку.
+		if t.Method.Alg() != key.signingMethod().Alg() {
+			return nil, fmt.Errorf("%w: %s for key %q", ErrSigningMethodMismatch, t.Method.Alg(), key.ID)
+		}
+		return key.verificationKey(), nil
+	}, opts...)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -101,9 +207,5 @@ func validateJWT(tokenString string, claims jwt.Claims, key []byte) (*jwt.Token,
 		return nil, fmt.Errorf("parsing jwt token `%s`: %w", tokenString, err)
 	}
 
-	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-		return nil, errors.New("unexpected signing method")
-	}
-
 	return token, nil
 }
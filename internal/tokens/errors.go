@@ -3,6 +3,15 @@ package tokens
 import "errors"
 
 // ErrTokenExpired токен просрочен.
+// ErrUnknownKey токен подписан ключом, которого нет в наборе.
+// ErrKeyRetired токен подписан ключом, срок приема которого истек.
+// ErrSigningMethodMismatch алгоритм токена не соответствует алгоритму ключа из kid.
+// ErrUnsupportedKey тип закрытого ключа не поддерживается.
 var (
 	ErrTokenExpired = errors.New("token expired")
+	ErrUnknownKey   = errors.New("unknown signing key")
+	ErrKeyRetired   = errors.New("signing key retired")
+
+	ErrSigningMethodMismatch = errors.New("signing method does not match key")
+	ErrUnsupportedKey        = errors.New("unsupported private key type")
 )
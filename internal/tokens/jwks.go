@@ -0,0 +1,60 @@
+package tokens
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+// JWK открытый ключ в формате JSON Web Key (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`           // Тип ключа: OKP или RSA
+	KeyID     string `json:"kid"`           // Идентификатор ключа из заголовка kid токенов
+	Use       string `json:"use"`           // Назначение ключа, всегда sig
+	Algorithm string `json:"alg"`           // Алгоритм подписи: EdDSA или RS256
+	Curve     string `json:"crv,omitempty"` // Кривая OKP ключа: Ed25519
+	X         string `json:"x,omitempty"`   // Открытый ключ ed25519
+	N         string `json:"n,omitempty"`   // Модуль RSA
+	E         string `json:"e,omitempty"`   // Открытая экспонента RSA
+}
+
+// JWKSet набор открытых ключей (RFC 7517, раздел 5).
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS возвращает открытые ключи набора для проверки токенов другими сервисами.
+// Секреты HMAC не публикуются, выведенные из оборота ключи публикуются до их ValidUntil.
+//
+// Возвращает:
+//   - JWKSet: открытые ключи, упорядоченные по идентификатору
+func (k *Keyring) JWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0, len(k.keys))}
+	now := k.now()
+	for _, key := range k.keys {
+		if !key.ValidUntil.IsZero() && now.After(key.ValidUntil) {
+			continue
+		}
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.signingMethod().Alg()}
+		switch pub := key.verificationKey().(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	slices.SortFunc(set.Keys, func(a, b JWK) int {
+		return strings.Compare(a.KeyID, b.KeyID)
+	})
+	return set
+}
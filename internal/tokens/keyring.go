@@ -0,0 +1,161 @@
+package tokens
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultKeyID идентификатор ключа для токенов без заголовка kid,
+// выпущенных до появления ротации ключей, и ключа NewStaticKeyring.
+const DefaultKeyID = "default"
+
+// MinRSAKeyBits минимальная длина ключа RSA.
+const MinRSAKeyBits = 2048
+
+// Key ключ подписи токенов. Задается либо секрет HMAC (HS256), либо закрытый ключ
+// ed25519 (EdDSA) или RSA (RS256). Алгоритм определяется ключом, а не заголовком alg токена.
+type Key struct {
+	ID         string        // Идентификатор ключа, передается в заголовке kid
+	Secret     []byte        // Секрет HMAC
+	PrivateKey crypto.Signer // Закрытый ключ ed25519.PrivateKey или *rsa.PrivateKey
+	// Момент, после которого выведенный из оборота ключ больше не принимается.
+	// Нулевое значение - ключ принимается без ограничения срока.
+	ValidUntil time.Time
+}
+
+// Keyring набор ключей подписи токенов. Новые токены подписываются активным ключом,
+// а проверяются ключом, указанным в заголовке kid, в том числе выведенным из оборота до его ValidUntil.
+// Это позволяет сменить секрет, не разлогинивая посетителей.
+type Keyring struct {
+	active Key
+	keys   map[string]Key
+	now    func() time.Time
+}
+
+// NewKeyring создает набор ключей.
+//
+// Параметры:
+//   - activeID: идентификатор ключа для подписи новых токенов
+//   - keys: все ключи набора, включая активный
+//
+// Возвращает:
+//   - *Keyring: набор ключей
+//   - error: ошибка, если ключи пусты или некорректны, повторяются, активный ключ не найден
+//     или выведен из оборота
+func NewKeyring(activeID string, keys ...Key) (*Keyring, error) {
+	kr := &Keyring{keys: make(map[string]Key, len(keys)), now: time.Now}
+	for _, k := range keys {
+		if k.ID == "" {
+			return nil, errors.New("keyring: key id is empty")
+		}
+		if err := k.validate(); err != nil {
+			return nil, fmt.Errorf("keyring: key %q: %w", k.ID, err)
+		}
+		if _, ok := kr.keys[k.ID]; ok {
+			return nil, fmt.Errorf("keyring: duplicate key %q", k.ID)
+		}
+		kr.keys[k.ID] = k
+	}
+
+	active, ok := kr.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("keyring: active key %q not found", activeID)
+	}
+	if !active.ValidUntil.IsZero() {
+		return nil, fmt.Errorf("keyring: active key %q must not have valid until", activeID)
+	}
+	kr.active = active
+	return kr, nil
+}
+
+// NewStaticKeyring создает набор из одного ключа DefaultKeyID.
+//
+// Параметры:
+//   - secret: секрет HMAC
+//
+// Возвращает:
+//   - *Keyring: набор ключей
+func NewStaticKeyring(secret []byte) *Keyring {
+	k := Key{ID: DefaultKeyID, Secret: secret}
+	return &Keyring{active: k, keys: map[string]Key{k.ID: k}, now: time.Now}
+}
+
+// ActiveKeyID возвращает идентификатор ключа, которым подписываются новые токены.
+func (k *Keyring) ActiveKeyID() string {
+	return k.active.ID
+}
+
+// lookup возвращает ключ проверки подписи по kid. Пустой kid соответствует DefaultKeyID.
+func (k *Keyring) lookup(kid string) (Key, error) {
+	if kid == "" {
+		kid = DefaultKeyID
+	}
+	key, ok := k.keys[kid]
+	if !ok {
+		return Key{}, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	if !key.ValidUntil.IsZero() && k.now().After(key.ValidUntil) {
+		return Key{}, fmt.Errorf("%w: %q", ErrKeyRetired, kid)
+	}
+	return key, nil
+}
+
+// validate проверяет, что у ключа задан ровно один поддерживаемый материал подписи.
+func (key Key) validate() error {
+	if key.PrivateKey == nil {
+		if len(key.Secret) == 0 {
+			return errors.New("neither secret nor private key is set")
+		}
+		return nil
+	}
+	if len(key.Secret) != 0 {
+		return errors.New("both secret and private key are set")
+	}
+	switch pk := key.PrivateKey.(type) {
+	case ed25519.PrivateKey:
+		if len(pk) != ed25519.PrivateKeySize {
+			return errors.New("invalid ed25519 private key size")
+		}
+	case *rsa.PrivateKey:
+		if pk.N.BitLen() < MinRSAKeyBits {
+			return fmt.Errorf("rsa key must be at least %d bits", MinRSAKeyBits)
+		}
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedKey, key.PrivateKey)
+	}
+	return nil
+}
+
+// signingMethod возвращает алгоритм подписи, соответствующий ключу.
+func (key Key) signingMethod() jwt.SigningMethod {
+	switch key.PrivateKey.(type) {
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// signingKey возвращает материал для подписи токена.
+func (key Key) signingKey() any {
+	if key.PrivateKey != nil {
+		return key.PrivateKey
+	}
+	return key.Secret
+}
+
+// verificationKey возвращает материал для проверки подписи токена.
+func (key Key) verificationKey() any {
+	if key.PrivateKey != nil {
+		return key.PrivateKey.Public()
+	}
+	return key.Secret
+}
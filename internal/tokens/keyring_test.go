@@ -0,0 +1,165 @@
+package tokens
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testVisitorUUID = "9c1b6a2e-4f3d-4e8a-b7c6-5d4e3f2a1b0c"
+
+func TestKeyring_Rotation(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	oldSecret := []byte("old secret")
+
+	// Токен без kid, выпущенный до ротации ключей.
+	legacy, err := GenerateVisitorJWT(testVisitorUUID, time.Hour, oldSecret)
+	require.NoError(t, err)
+	legacyToken, _, err := jwt.NewParser().ParseUnverified(legacy, &VisitorClaims{})
+	require.NoError(t, err)
+	delete(legacyToken.Header, "kid")
+	legacy, err = legacyToken.SignedString(oldSecret)
+	require.NoError(t, err)
+
+	oldKeyring := NewStaticKeyring(oldSecret)
+	issuedByOld, err := oldKeyring.GenerateVisitorJWT(testVisitorUUID, time.Hour)
+	require.NoError(t, err)
+
+	keyring, err := NewKeyring("2025-06",
+		Key{ID: "2025-06", Secret: []byte("new secret")},
+		Key{ID: DefaultKeyID, Secret: oldSecret, ValidUntil: now.Add(time.Hour)},
+	)
+	require.NoError(t, err)
+	keyring.now = func() time.Time { return now }
+
+	issued, err := keyring.GenerateVisitorJWT(testVisitorUUID, time.Hour)
+	require.NoError(t, err)
+	token, err := keyring.ValidateVisitorJWT(issued)
+	require.NoError(t, err)
+	assert.Equal(t, "2025-06", token.Header["kid"])
+	assert.Equal(t, testVisitorUUID, token.Claims.(*VisitorClaims).UUID) //nolint:errcheck
+
+	// Токены прежнего ключа принимаются до ValidUntil.
+	for _, tok := range []string{legacy, issuedByOld} {
+		_, err = keyring.ValidateVisitorJWT(tok)
+		require.NoError(t, err)
+	}
+
+	// Новые токены не принимаются прежним набором ключей.
+	_, err = oldKeyring.ValidateVisitorJWT(issued)
+	require.ErrorIs(t, err, ErrUnknownKey)
+
+	keyring.now = func() time.Time { return now.Add(2 * time.Hour) }
+	for _, tok := range []string{legacy, issuedByOld} {
+		_, err = keyring.ValidateVisitorJWT(tok)
+		require.ErrorIs(t, err, ErrKeyRetired)
+	}
+	_, err = keyring.ValidateVisitorJWT(issued)
+	require.NoError(t, err)
+}
+
+func TestKeyring_Roles(t *testing.T) {
+	keyring := NewStaticKeyring([]byte("secret"))
+
+	for _, roles := range [][]string{nil, {RoleAdmin}} {
+		issued, err := keyring.GenerateVisitorJWT(testVisitorUUID, time.Hour, roles...)
+		require.NoError(t, err)
+		token, err := keyring.ValidateVisitorJWT(issued)
+		require.NoError(t, err)
+		claims := token.Claims.(*VisitorClaims) //nolint:errcheck
+		assert.Equal(t, roles, claims.Roles)
+		assert.Equal(t, len(roles) > 0, claims.HasRole(RoleAdmin))
+	}
+}
+
+func TestKeyring_SessionID(t *testing.T) {
+	keyring := NewStaticKeyring([]byte("secret"))
+	claimsOf := func(issued string) *VisitorClaims {
+		token, err := keyring.ValidateVisitorJWT(issued)
+		require.NoError(t, err)
+		return token.Claims.(*VisitorClaims) //nolint:errcheck
+	}
+
+	first, err := keyring.GenerateVisitorJWT(testVisitorUUID, time.Hour)
+	require.NoError(t, err)
+	started := claimsOf(first)
+	require.NotEmpty(t, started.SessionID)
+
+	refreshed, err := keyring.GenerateVisitorSessionJWT(testVisitorUUID, started.SessionID, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, started.SessionID, claimsOf(refreshed).SessionID)
+	assert.NotEqual(t, started.ID, claimsOf(refreshed).ID)
+
+	other, err := keyring.GenerateVisitorJWT(testVisitorUUID, time.Hour)
+	require.NoError(t, err)
+	assert.NotEqual(t, started.SessionID, claimsOf(other).SessionID)
+}
+
+func TestKeyring_ValidateForeignKid(t *testing.T) {
+	keyring, err := NewKeyring("a", Key{ID: "a", Secret: []byte("secret a")})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		kid  any
+	}{
+		{name: "unknown kid", kid: "b"},
+		{name: "kid is not a string", kid: 42},
+		{name: "no kid without default key", kid: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, VisitorClaims{UUID: testVisitorUUID})
+			if tt.kid != nil {
+				token.Header["kid"] = tt.kid
+			}
+			signed, signErr := token.SignedString([]byte("secret a"))
+			require.NoError(t, signErr)
+
+			_, err = keyring.ValidateVisitorJWT(signed)
+			require.ErrorIs(t, err, ErrUnknownKey)
+		})
+	}
+}
+
+func TestNewKeyring_Invalid(t *testing.T) {
+	secret := []byte("secret")
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	weakRSAKey, err := rsa.GenerateKey(rand.Reader, 1024) //nolint:gosec // проверяем отказ от слабого ключа
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		activeID string
+		keys     []Key
+	}{
+		{name: "no keys", activeID: "a"},
+		{name: "empty id", activeID: "", keys: []Key{{Secret: secret}}},
+		{name: "empty secret", activeID: "a", keys: []Key{{ID: "a"}}},
+		{name: "duplicate", activeID: "a", keys: []Key{{ID: "a", Secret: secret}, {ID: "a", Secret: secret}}},
+		{name: "active not found", activeID: "b", keys: []Key{{ID: "a", Secret: secret}}},
+		{name: "active retired", activeID: "a", keys: []Key{{ID: "a", Secret: secret, ValidUntil: time.Now()}}},
+		{name: "secret and private key", activeID: "a", keys: []Key{{ID: "a", Secret: secret, PrivateKey: edKey}}},
+		{name: "weak rsa key", activeID: "a", keys: []Key{{ID: "a", PrivateKey: weakRSAKey}}},
+		{name: "unsupported key", activeID: "a", keys: []Key{{ID: "a", PrivateKey: ecKey}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, keyringErr := NewKeyring(tt.activeID, tt.keys...)
+			require.Error(t, keyringErr)
+		})
+	}
+}
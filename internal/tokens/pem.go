@@ -0,0 +1,68 @@
+package tokens
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ParsePrivateKeyPEM разбирает закрытый ключ ed25519 или RSA в формате PEM.
+// Поддерживаются блоки "PRIVATE KEY" (PKCS #8) и "RSA PRIVATE KEY" (PKCS #1).
+//
+// Параметры:
+//   - data: содержимое PEM
+//
+// Возвращает:
+//   - crypto.Signer: ed25519.PrivateKey или *rsa.PrivateKey
+//   - error: ошибка разбора или ErrUnsupportedKey для ключей других типов
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("parse private key: no pem block found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse private key: %w", err)
+		}
+		return key, nil
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse private key: %w", err)
+		}
+		switch k := key.(type) {
+		case ed25519.PrivateKey:
+			return k, nil
+		case *rsa.PrivateKey:
+			return k, nil
+		default:
+			return nil, fmt.Errorf("parse private key: %w: %T", ErrUnsupportedKey, key)
+		}
+	default:
+		return nil, fmt.Errorf("parse private key: %w: pem block %q", ErrUnsupportedKey, block.Type)
+	}
+}
+
+// LoadPrivateKeyPEM читает закрытый ключ ed25519 или RSA из файла PEM.
+//
+// Параметры:
+//   - path: путь к файлу
+//
+// Возвращает:
+//   - crypto.Signer: ed25519.PrivateKey или *rsa.PrivateKey
+//   - error: ошибка чтения или разбора
+func LoadPrivateKeyPEM(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load private key: %w", err)
+	}
+	return ParsePrivateKeyPEM(data)
+}
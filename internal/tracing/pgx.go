@@ -0,0 +1,110 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// pgxTracerName имя трейсера запросов к PostgreSQL.
+const pgxTracerName = "github.com/fsdevblog/shorturl/internal/db"
+
+// Имена спанов для запросов без комментария-имени.
+const (
+	defaultQuerySpanName = "db.query"
+	batchSpanName        = "db.batch"
+)
+
+// PgxTracer создает спаны для запросов pgx. Имя спана берется из комментария
+// в первой строке запроса (например `-- createURL`), что совпадает с именами констант запросов.
+// Реализует pgx.QueryTracer и pgx.BatchTracer.
+type PgxTracer struct{}
+
+// NewPgxTracer создает трейсер запросов pgx.
+//
+// Возвращает:
+//   - *PgxTracer: трейсер для pgx.ConnConfig.Tracer
+func NewPgxTracer() *PgxTracer {
+	return &PgxTracer{}
+}
+
+// TraceQueryStart реализует pgx.QueryTracer.
+func (t *PgxTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	name := QueryName(data.SQL)
+	ctx, _ = otel.Tracer(pgxTracerName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(name),
+			semconv.DBQueryText(data.SQL),
+		),
+	)
+	return ctx
+}
+
+// TraceQueryEnd реализует pgx.QueryTracer.
+func (t *PgxTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	endSpan(trace.SpanFromContext(ctx), data.Err)
+}
+
+// TraceBatchStart реализует pgx.BatchTracer.
+func (t *PgxTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	name := batchSpanName
+	if data.Batch != nil && len(data.Batch.QueuedQueries) > 0 {
+		name = batchSpanName + " " + QueryName(data.Batch.QueuedQueries[0].SQL)
+	}
+	ctx, _ = otel.Tracer(pgxTracerName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL),
+	)
+	return ctx
+}
+
+// TraceBatchQuery реализует pgx.BatchTracer. Ошибки отдельных запросов пакета записываются событиями.
+func (t *PgxTracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	if data.Err != nil {
+		trace.SpanFromContext(ctx).RecordError(data.Err, trace.WithAttributes(
+			semconv.DBOperationName(QueryName(data.SQL)),
+		))
+	}
+}
+
+// TraceBatchEnd реализует pgx.BatchTracer.
+func (t *PgxTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	endSpan(trace.SpanFromContext(ctx), data.Err)
+}
+
+// endSpan завершает спан, отмечая ошибку.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// QueryName возвращает имя запроса из комментария в его первой строке.
+// Для запросов без комментария возвращает "db.query".
+//
+// Параметры:
+//   - sql: текст запроса
+//
+// Возвращает:
+//   - string: имя запроса
+func QueryName(sql string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(sql), "\n")
+	name, ok := strings.CutPrefix(line, "--")
+	if !ok {
+		return defaultQuerySpanName
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return defaultQuerySpanName
+	}
+	return name
+}
@@ -0,0 +1,69 @@
+package tracing_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fsdevblog/shorturl/internal/config"
+	"github.com/fsdevblog/shorturl/internal/controllers"
+	"github.com/fsdevblog/shorturl/internal/db"
+	"github.com/fsdevblog/shorturl/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTracePropagation(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() { _ = tp.Shutdown(t.Context()) }()
+
+	svc, err := services.Factory(db.NewMemStorage(), services.ServiceTypeInMemory)
+	require.NoError(t, err)
+
+	core, logs := observer.New(zap.InfoLevel)
+	router := controllers.SetupRouter(controllers.RouterParams{
+		URLService: svc.URLService,
+		AppConf:    config.Config{BaseURL: "http://test.com", VisitorJWTSecret: "secret"},
+		Logger:     zap.New(core),
+	})
+
+	const parentTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url":"https://example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("traceparent", "00-"+parentTraceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	spans := exporter.GetSpans()
+	byName := make(map[string]tracetest.SpanStub, len(spans))
+	for _, s := range spans {
+		assert.Equal(t, parentTraceID, s.SpanContext.TraceID().String(), "span %s", s.Name)
+		byName[s.Name] = s
+	}
+
+	httpSpan, ok := byName["POST /api/shorten"]
+	require.True(t, ok, "http span not found in %v", byName)
+	assert.Equal(t, "00f067aa0ba902b7", httpSpan.Parent.SpanID().String())
+
+	serviceSpan, ok := byName["URLService.Create"]
+	require.True(t, ok)
+	assert.Equal(t, httpSpan.SpanContext.SpanID(), serviceSpan.Parent.SpanID())
+
+	repoSpan, ok := byName["URLRepository.Create"]
+	require.True(t, ok)
+	assert.Equal(t, serviceSpan.SpanContext.SpanID(), repoSpan.Parent.SpanID())
+
+	entries := logs.FilterField(zap.String("trace_id", parentTraceID)).All()
+	assert.Len(t, entries, 1, "trace_id should be in request log")
+}
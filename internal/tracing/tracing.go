@@ -0,0 +1,181 @@
+// Package tracing настраивает OpenTelemetry трассировку приложения.
+//
+// Провайдер регистрируется глобально (otel.SetTracerProvider), поэтому слои приложения
+// создают спаны через otel.Tracer без явной передачи провайдера. Для распространения
+// контекста используется W3C Trace Context (заголовок traceparent).
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// ExporterType тип экспортера спанов.
+type ExporterType string
+
+// Поддерживаемые экспортеры. При ExporterNone спаны создаются (идентификаторы попадают в логи
+// и передаются дальше), но никуда не отправляются.
+const (
+	ExporterNone   ExporterType = ""
+	ExporterStdout ExporterType = "stdout"
+	ExporterFile   ExporterType = "file"
+	ExporterOTLP   ExporterType = "otlp"
+)
+
+// DefaultServiceName имя сервиса в ресурсе трассировки по умолчанию.
+const DefaultServiceName = "shorturl"
+
+// Options опции трассировки.
+type Options struct {
+	ServiceName    string       // Имя сервиса
+	ServiceVersion string       // Версия сервиса
+	Exporter       ExporterType // Тип экспортера
+	FilePath       string       // Файл для ExporterFile
+	OTLPEndpoint   string       // Адрес OTLP/HTTP коллектора, например http://localhost:4318
+	SampleRatio    float64      // Доля сэмплируемых трасс [0, 1]; решение родителя имеет приоритет
+	Writer         io.Writer    // Приемник для ExporterStdout (по умолчанию os.Stdout)
+}
+
+// Provider провайдер трассировки приложения.
+type Provider struct {
+	tp      *sdktrace.TracerProvider
+	closers []io.Closer
+}
+
+// New создает провайдер трассировки и регистрирует его глобально вместе с W3C пропагатором.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//   - opts: функции для настройки опций
+//
+// Возвращает:
+//   - *Provider: провайдер трассировки
+//   - error: ошибка создания экспортера или некорректные опции
+func New(ctx context.Context, opts ...func(*Options)) (*Provider, error) {
+	options := Options{
+		ServiceName: DefaultServiceName,
+		SampleRatio: 1,
+		Writer:      os.Stdout,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.SampleRatio < 0 || options.SampleRatio > 1 {
+		return nil, fmt.Errorf("tracing: sample ratio must be in [0, 1], got %v", options.SampleRatio)
+	}
+
+	p := &Provider{}
+	res := resource.NewSchemaless(
+		semconv.ServiceName(options.ServiceName),
+		semconv.ServiceVersion(options.ServiceVersion),
+	)
+	tpOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(options.SampleRatio))),
+	}
+
+	exporter, err := p.newExporter(ctx, &options)
+	if err != nil {
+		return nil, err
+	}
+	if exporter != nil {
+		tpOpts = append(tpOpts, sdktrace.WithBatcher(exporter))
+	}
+
+	p.tp = sdktrace.NewTracerProvider(tpOpts...)
+	otel.SetTracerProvider(p.tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return p, nil
+}
+
+// newExporter создает экспортер согласно опциям. Для ExporterNone возвращает nil.
+func (p *Provider) newExporter(ctx context.Context, options *Options) (sdktrace.SpanExporter, error) {
+	switch options.Exporter {
+	case ExporterNone:
+		return nil, nil //nolint:nilnil
+	case ExporterStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(options.Writer))
+		if err != nil {
+			return nil, fmt.Errorf("tracing: create stdout exporter: %w", err)
+		}
+		return exp, nil
+	case ExporterFile:
+		if options.FilePath == "" {
+			return nil, errors.New("tracing: file path is required for file exporter")
+		}
+		f, err := os.OpenFile(options.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) //nolint:mnd
+		if err != nil {
+			return nil, fmt.Errorf("tracing: open trace file: %w", err)
+		}
+		p.closers = append(p.closers, f)
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			return nil, fmt.Errorf("tracing: create file exporter: %w", err)
+		}
+		return exp, nil
+	case ExporterOTLP:
+		if options.OTLPEndpoint == "" {
+			return nil, errors.New("tracing: endpoint is required for otlp exporter")
+		}
+		exp, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(options.OTLPEndpoint))
+		if err != nil {
+			return nil, fmt.Errorf("tracing: create otlp exporter: %w", err)
+		}
+		return exp, nil
+	default:
+		return nil, fmt.Errorf("tracing: unknown exporter `%s`", options.Exporter)
+	}
+}
+
+// Shutdown отправляет накопленные спаны и освобождает ресурсы экспортера.
+//
+// Параметры:
+//   - ctx: контекст, ограничивающий время отправки
+//
+// Возвращает:
+//   - error: ошибка отправки или закрытия файла
+func (p *Provider) Shutdown(ctx context.Context) error {
+	err := p.tp.Shutdown(ctx)
+	for _, c := range p.closers {
+		err = errors.Join(err, c.Close())
+	}
+	if err != nil {
+		return fmt.Errorf("tracing shutdown: %w", err)
+	}
+	return nil
+}
+
+// ZapFields возвращает поля лога с идентификаторами трассы и спана из контекста.
+// Если в контексте нет валидного спана, возвращает nil.
+//
+// Параметры:
+//   - ctx: контекст выполнения
+//
+// Возвращает:
+//   - []zap.Field: поля trace_id и span_id
+func ZapFields(ctx context.Context) []zap.Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []zap.Field{
+		zap.String("trace_id", sc.TraceID().String()),
+		zap.String("span_id", sc.SpanID().String()),
+	}
+}
@@ -0,0 +1,101 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func TestQueryName(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{name: "named query", sql: "-- createURL\nINSERT INTO urls VALUES ($1);", want: "createURL"},
+		{name: "leading whitespace", sql: "\n\t-- getStats\nSELECT 1;", want: "getStats"},
+		{name: "without comment", sql: "SELECT 1;", want: "db.query"},
+		{name: "empty comment", sql: "--\nSELECT 1;", want: "db.query"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, QueryName(tt.sql))
+		})
+	}
+}
+
+func TestNew_FileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	p, err := New(t.Context(), func(o *Options) {
+		o.Exporter = ExporterFile
+		o.FilePath = path
+	})
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "file-span")
+	span.End()
+	require.NoError(t, p.Shutdown(t.Context()))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"Name":"file-span"`)
+}
+
+func TestNew_OTLPExporter(t *testing.T) {
+	// Заглушка локального коллектора: принимает OTLP/HTTP экспорт.
+	var received atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/v1/traces" {
+			received.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	p, err := New(t.Context(), func(o *Options) {
+		o.Exporter = ExporterOTLP
+		o.OTLPEndpoint = collector.URL
+	})
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "otlp-span")
+	span.End()
+	require.NoError(t, p.Shutdown(t.Context()))
+
+	assert.Positive(t, received.Load())
+}
+
+func TestNew_InvalidOptions(t *testing.T) {
+	_, err := New(t.Context(), func(o *Options) { o.Exporter = "unknown" })
+	require.Error(t, err)
+
+	_, err = New(t.Context(), func(o *Options) { o.SampleRatio = 2 })
+	require.Error(t, err)
+
+	_, err = New(t.Context(), func(o *Options) { o.Exporter = ExporterOTLP })
+	require.Error(t, err)
+}
+
+func TestZapFields(t *testing.T) {
+	assert.Nil(t, ZapFields(context.Background()))
+
+	p, err := New(t.Context())
+	require.NoError(t, err)
+	defer func() { _ = p.Shutdown(context.Background()) }()
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "span")
+	defer span.End()
+
+	fields := ZapFields(ctx)
+	require.Len(t, fields, 2)
+	assert.Equal(t, span.SpanContext().TraceID().String(), fields[0].String)
+	assert.Equal(t, span.SpanContext().SpanID().String(), fields[1].String)
+}
@@ -0,0 +1,101 @@
+// Package urlvalidate содержит общую проверку оригинальных URL для HTTP и gRPC API.
+package urlvalidate
+
+import (
+	"errors"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// hostnameRegex регулярное выражение для проверки hostname в соответствии с RFC 1123.
+// Исключает корневые доменные имена (без зоны).
+var hostnameRegex = regexp.MustCompile(`^([a-zA-Z0-9](-?[a-zA-Z0-9])*\.)+([a-zA-Z0-9](-?[a-zA-Z0-9])*)$`)
+
+// Validate проверяет корректность URL.
+//
+// Параметры:
+//   - rawURL: URL для проверки
+//
+// Возвращает:
+//   - *url.URL: распарсенный URL
+//   - error: ошибка валидации
+//
+// Правила валидации:
+//   - URL должен иметь схему http или https
+//   - URL должен содержать хост
+//   - Hostname должен соответствовать RFC 1123 или быть localhost
+func Validate(rawURL string) (*url.URL, error) {
+	parsedURL, err := url.ParseRequestURI(rawURL)
+
+	if err != nil {
+		return nil, errors.New("invalid URL format")
+	}
+
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return nil, errors.New("URL must have http or https scheme")
+	}
+
+	if parsedURL.Host == "" {
+		return nil, errors.New("URL must have a host")
+	}
+
+	if parsedURL.Hostname() != "localhost" && !hostnameRegex.MatchString(parsedURL.Hostname()) {
+		return nil, errors.New("invalid hostname")
+	}
+
+	return parsedURL, nil
+}
+
+// ErrPrivateAddress ошибка адреса, указывающего во внутреннюю сеть.
+var ErrPrivateAddress = errors.New("URL must not point to a loopback, private or link-local address")
+
+// nonPublicPrefixes диапазоны, не покрытые методами netip.Addr: "эта" сеть 0.0.0.0/8
+// и разделяемое адресное пространство операторов 100.64.0.0/10 (RFC 6598).
+var nonPublicPrefixes = []netip.Prefix{ //nolint:gochecknoglobals
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// IsPublicAddr проверяет, что адрес не является loopback, частным, link-local, multicast или неопределенным.
+//
+// Параметры:
+//   - addr: IP адрес
+//
+// Возвращает:
+//   - bool: true, если адрес публичный
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidatePublicHost проверяет, что хост URL не указывает во внутреннюю сеть.
+// Проверяются только IP адреса и имена localhost: доменные имена проверяются
+// после разрешения при соединении (см. IsPublicAddr).
+//
+// Параметры:
+//   - u: распарсенный URL
+//
+// Возвращает:
+//   - error: ErrPrivateAddress, если хост - localhost или непубличный IP адрес
+func ValidatePublicHost(u *url.URL) error {
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrPrivateAddress
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !IsPublicAddr(addr) {
+		return ErrPrivateAddress
+	}
+	return nil
+}